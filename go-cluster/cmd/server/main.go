@@ -10,13 +10,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/lumadb/cluster/pkg/api"
-	"github.com/lumadb/cluster/pkg/cluster"
-	"github.com/lumadb/cluster/pkg/config"
-	"github.com/lumadb/cluster/pkg/router"
+	"github.com/tdb-plus/cluster/pkg/api"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/config"
+	"github.com/tdb-plus/cluster/pkg/peering"
+	"github.com/tdb-plus/cluster/pkg/router"
 	"go.uber.org/zap"
 )
 
@@ -28,7 +30,8 @@ func main() {
 	grpcAddr := flag.String("grpc-addr", ":9090", "gRPC address")
 	raftAddr := flag.String("raft-addr", ":10000", "Raft address")
 	dataDir := flag.String("data-dir", "./data", "Data directory")
-	join := flag.String("join", "", "Existing cluster node to join")
+	join := flag.String("join", "", "Comma-separated seed addresses of an existing cluster to join")
+	databaseURL := flag.String("database-url", "", "Database URL for platform/auth/store (postgres://, mysql://, cockroach://, or sqlite3://); empty runs AuthEngine without a store")
 	flag.Parse()
 
 	// Initialize logger
@@ -57,6 +60,9 @@ func main() {
 	cfg.GRPCAddr = *grpcAddr
 	cfg.RaftAddr = *raftAddr
 	cfg.DataDir = *dataDir
+	if *databaseURL != "" {
+		cfg.DatabaseURL = *databaseURL
+	}
 
 	logger.Info("Starting TDB+ Cluster Node",
 		zap.String("node_id", cfg.NodeID),
@@ -73,7 +79,7 @@ func main() {
 
 	// Start the node
 	if *join != "" {
-		if err := node.Join(*join); err != nil {
+		if err := node.Join(strings.Split(*join, ",")); err != nil {
 			logger.Fatal("Failed to join cluster", zap.Error(err))
 		}
 	} else {
@@ -85,6 +91,10 @@ func main() {
 	// Create router for request distribution
 	rtr := router.NewRouter(node, logger)
 
+	// Create peering manager for cross-cluster routing (see pkg/peering)
+	peerMgr := peering.NewManager([]byte(cfg.PeeringSecret), peering.NewHTTPShardFetcher())
+	rtr.SetPeeringManager(peerMgr)
+
 	// Create HTTP API server
 	apiServer := api.NewServer(node, rtr, logger)
 
@@ -12,6 +12,11 @@ import (
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+
+	// embeddings caches GetEmbedding results by text so a repeated
+	// literal (e.g. a retried SIMILAR_TO/VECTOR_SEARCH query) doesn't
+	// pay for another round trip to the AI service.
+	embeddings *embeddingCache
 }
 
 // Config for AI Client
@@ -28,6 +33,7 @@ func NewClient(config Config) *Client {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		embeddings: newEmbeddingCache(defaultEmbeddingCacheSize),
 	}
 }
 
@@ -88,8 +94,13 @@ type EmbeddingResponse struct {
 	Embedding []float32 `json:"embedding"`
 }
 
-// GetEmbedding gets the vector embedding for a text
+// GetEmbedding gets the vector embedding for a text, serving from the
+// client's embedding cache when text was embedded before.
 func (c *Client) GetEmbedding(text string) ([]float32, error) {
+	if cached, ok := c.embeddings.get(text); ok {
+		return cached, nil
+	}
+
 	reqBody := EmbeddingRequest{
 		Text: text,
 	}
@@ -123,6 +134,7 @@ func (c *Client) GetEmbedding(text string) ([]float32, error) {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	c.embeddings.put(text, embResp.Embedding)
 	return embResp.Embedding, nil
 }
 
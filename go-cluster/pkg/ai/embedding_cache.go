@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultEmbeddingCacheSize bounds how many distinct query texts
+// Client.GetEmbedding remembers, so a hot literal (e.g. a retried
+// SIMILAR_TO/VECTOR_SEARCH query) doesn't re-embed on every call.
+const defaultEmbeddingCacheSize = 256
+
+type embeddingCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type embeddingCacheEntry struct {
+	text      string
+	embedding []float32
+}
+
+func newEmbeddingCache(capacity int) *embeddingCache {
+	if capacity <= 0 {
+		capacity = defaultEmbeddingCacheSize
+	}
+	return &embeddingCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *embeddingCache) get(text string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[text]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return elem.Value.(*embeddingCacheEntry).embedding, true
+}
+
+func (c *embeddingCache) put(text string, embedding []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[text]; ok {
+		c.ll.MoveToFront(elem)
+		elem.Value.(*embeddingCacheEntry).embedding = embedding
+		return
+	}
+
+	elem := c.ll.PushFront(&embeddingCacheEntry{text: text, embedding: embedding})
+	c.items[text] = elem
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*embeddingCacheEntry).text)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// HybridConfig tunes RAGService.Query's retrieval pipeline: vector search
+// and BM25 keyword search fused by Reciprocal Rank Fusion, optionally
+// reranked, then diversified down to the final result set by MMR.
+type HybridConfig struct {
+	// Alpha weights vector search against BM25 in the RRF fusion score:
+	// fused = Alpha*rrf(vectorRank) + (1-Alpha)*rrf(bm25Rank). 0.5 weighs
+	// both equally.
+	Alpha float64
+	// Lambda trades MMR relevance against diversity: mmr = Lambda*sim(q,d)
+	// - (1-Lambda)*maxSim(d, selected). 1.0 ignores diversity entirely.
+	Lambda float64
+	// RerankTopN is how many of the fused candidates get passed through
+	// aiClient as a cross-encoder rerank prompt before MMR. 0 disables
+	// reranking and MMR runs directly over the fused order.
+	RerankTopN int
+	// FinalK is how many documents Query keeps after MMR selection.
+	FinalK int
+}
+
+// DefaultHybridConfig returns the HybridConfig RAGService uses when none is
+// set explicitly.
+func DefaultHybridConfig() HybridConfig {
+	return HybridConfig{
+		Alpha:      0.5,
+		Lambda:     0.5,
+		RerankTopN: 20,
+		FinalK:     5,
+	}
+}
+
+// rrfK is the Reciprocal Rank Fusion rank-damping constant - the
+// conventional choice from the original RRF paper, keeping early ranks
+// dominant without letting rank 1 overwhelm everything else.
+const rrfK = 60.0
+
+// scoredDoc is one candidate document as it moves through fusion, optional
+// reranking, and MMR selection.
+type scoredDoc struct {
+	id          string
+	doc         map[string]interface{}
+	vectorScore float64
+	bm25Score   float64
+	fusedScore  float64
+	rerankScore float64
+	embedding   []float32
+}
+
+// bm25Hit is one document's BM25 score against a query, as returned by
+// bm25Search.
+type bm25Hit struct {
+	id    string
+	doc   map[string]interface{}
+	score float64
+}
+
+const (
+	// bm25K1 and bm25B are the standard Okapi BM25 term-saturation and
+	// length-normalization constants.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+var tokenPattern = regexp.MustCompile(`[a-zA-Z0-9]+`)
+
+func tokenize(text string) []string {
+	return tokenPattern.FindAllString(strings.ToLower(text), -1)
+}
+
+// bm25Search scores every document in collection against question's terms
+// using Okapi BM25 over each document's "text" field, returning the top
+// topN by score. core.Database has no full-text index of its own, so this
+// scans the whole collection via a cursor - fine for the modest
+// RAG-ingested collections this targets, but not a substitute for a real
+// inverted index at scale.
+func (s *RAGService) bm25Search(collection, question string, topN int) ([]bm25Hit, error) {
+	cursor, err := s.db.QueryCursor(collection, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cursor: %w", err)
+	}
+	defer cursor.Close()
+
+	type corpusDoc struct {
+		id     string
+		doc    map[string]interface{}
+		tf     map[string]int
+		length int
+	}
+
+	var docs []corpusDoc
+	df := make(map[string]int)
+	var totalLen int
+
+	ctx := context.Background()
+	for cursor.Next(ctx) {
+		var doc map[string]interface{}
+		if err := cursor.Scan(&doc); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		text, ok := doc["text"].(string)
+		if !ok || text == "" {
+			continue
+		}
+		id, ok := doc["_id"].(string)
+		if !ok || id == "" {
+			continue
+		}
+
+		tokens := tokenize(text)
+		tf := make(map[string]int, len(tokens))
+		for _, t := range tokens {
+			tf[t]++
+		}
+		for t := range tf {
+			df[t]++
+		}
+		docs = append(docs, corpusDoc{id: id, doc: doc, tf: tf, length: len(tokens)})
+		totalLen += len(tokens)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor iteration failed: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	avgLen := float64(totalLen) / float64(len(docs))
+	n := float64(len(docs))
+	queryTerms := tokenize(question)
+
+	idf := make(map[string]float64, len(queryTerms))
+	for _, t := range queryTerms {
+		if _, ok := idf[t]; ok {
+			continue
+		}
+		idf[t] = math.Log((n-float64(df[t])+0.5)/(float64(df[t])+0.5) + 1)
+	}
+
+	hits := make([]bm25Hit, 0, len(docs))
+	for _, d := range docs {
+		var score float64
+		for _, t := range queryTerms {
+			tf := float64(d.tf[t])
+			if tf == 0 {
+				continue
+			}
+			score += idf[t] * (tf * (bm25K1 + 1)) / (tf + bm25K1*(1-bm25B+bm25B*float64(d.length)/avgLen))
+		}
+		if score > 0 {
+			hits = append(hits, bm25Hit{id: d.id, doc: d.doc, score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	if len(hits) > topN {
+		hits = hits[:topN]
+	}
+	return hits, nil
+}
+
+// extractEmbedding reads back the vector Ingest stored under "_vector",
+// converting it from the []interface{} of float64 that a JSON round trip
+// through core.Database leaves it as.
+func extractEmbedding(doc map[string]interface{}) []float32 {
+	raw, ok := doc["_vector"].([]interface{})
+	if !ok {
+		return nil
+	}
+	embedding := make([]float32, len(raw))
+	for i, v := range raw {
+		f, _ := v.(float64)
+		embedding[i] = float32(f)
+	}
+	return embedding
+}
+
+// fuse combines vectorResults (ranked by VectorSearch's own score, best
+// first) and bm25Results (ranked by bm25Search, best first) into one
+// candidate list via Reciprocal Rank Fusion, fetching any document that
+// only came back from vector search (which returns ids/scores, not full
+// documents) and filling in its cached embedding. The result is sorted by
+// fusedScore, best first.
+func (s *RAGService) fuse(collection string, vectorResults []map[string]interface{}, bm25Results []bm25Hit, alpha float64) []*scoredDoc {
+	byID := make(map[string]*scoredDoc)
+
+	for rank, res := range vectorResults {
+		id, ok := res["id"].(string)
+		if !ok || id == "" {
+			continue
+		}
+		score, _ := res["score"].(float64)
+		sd := byID[id]
+		if sd == nil {
+			sd = &scoredDoc{id: id}
+			byID[id] = sd
+		}
+		sd.vectorScore = score
+		sd.fusedScore += alpha * (1.0 / (rrfK + float64(rank+1)))
+	}
+
+	for rank, hit := range bm25Results {
+		sd := byID[hit.id]
+		if sd == nil {
+			sd = &scoredDoc{id: hit.id, doc: hit.doc}
+			byID[hit.id] = sd
+		}
+		if sd.doc == nil {
+			sd.doc = hit.doc
+		}
+		sd.bm25Score = hit.score
+		sd.fusedScore += (1 - alpha) * (1.0 / (rrfK + float64(rank+1)))
+	}
+
+	out := make([]*scoredDoc, 0, len(byID))
+	for _, sd := range byID {
+		out = append(out, sd)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].fusedScore > out[j].fusedScore })
+
+	for _, sd := range out {
+		if sd.doc == nil {
+			if doc, err := s.db.Get(collection, sd.id); err == nil && doc != nil {
+				sd.doc = doc
+			}
+		}
+		if sd.doc != nil {
+			sd.embedding = extractEmbedding(sd.doc)
+		}
+	}
+	return out
+}
+
+var rerankScorePattern = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+// rerank scores each candidate's relevance to question by prompting
+// aiClient for a 0-10 rating, the same way Generate already produces the
+// final answer - Client has no dedicated cross-encoder endpoint. A
+// candidate whose rerank call fails or whose response doesn't parse keeps
+// its fused score instead, so one bad call doesn't drop a document
+// entirely.
+func (s *RAGService) rerank(question string, candidates []*scoredDoc) {
+	for _, c := range candidates {
+		text, _ := c.doc["text"].(string)
+		if text == "" {
+			c.rerankScore = c.fusedScore
+			continue
+		}
+
+		prompt := fmt.Sprintf(
+			"Rate how relevant the following passage is to the question, on a scale from 0 to 10. Respond with only the number.\n\nQuestion: %s\n\nPassage: %s",
+			question, text,
+		)
+		resp, err := s.aiClient.Generate(prompt, "", 8)
+		if err != nil {
+			s.logger.Warn("rerank call failed, keeping fused score", zap.String("id", c.id), zap.Error(err))
+			c.rerankScore = c.fusedScore
+			continue
+		}
+
+		match := rerankScorePattern.FindString(resp.Response)
+		score, err := strconv.ParseFloat(match, 64)
+		if match == "" || err != nil {
+			s.logger.Warn("rerank response had no numeric score, keeping fused score", zap.String("id", c.id), zap.String("response", resp.Response))
+			c.rerankScore = c.fusedScore
+			continue
+		}
+		c.rerankScore = score
+	}
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if
+// either is empty or either's magnitude is zero.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(b) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// mmrMaximize greedily selects up to k documents from candidates that
+// maximize Maximal Marginal Relevance: each pick maximizes
+// lambda*sim(query, d) - (1-lambda)*maxSim(d, already selected), so the
+// result stays relevant to queryEmbedding without being redundant with
+// itself.
+func mmrMaximize(queryEmbedding []float32, candidates []*scoredDoc, lambda float64, k int) []*scoredDoc {
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+	remaining := append([]*scoredDoc{}, candidates...)
+	selected := make([]*scoredDoc, 0, k)
+
+	for len(selected) < k {
+		bestIdx := -1
+		bestScore := math.Inf(-1)
+		for i, cand := range remaining {
+			relevance := cosineSimilarity(queryEmbedding, cand.embedding)
+			var maxSim float64
+			for _, sel := range selected {
+				if sim := cosineSimilarity(cand.embedding, sel.embedding); sim > maxSim {
+					maxSim = sim
+				}
+			}
+			mmrScore := lambda*relevance - (1-lambda)*maxSim
+			if mmrScore > bestScore {
+				bestScore = mmrScore
+				bestIdx = i
+			}
+		}
+		selected = append(selected, remaining[bestIdx])
+		remaining = append(remaining[:bestIdx], remaining[bestIdx+1:]...)
+	}
+	return selected
+}
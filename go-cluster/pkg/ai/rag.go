@@ -2,9 +2,11 @@ package ai
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
-	"github.com/lumadb/cluster/pkg/core"
+	"github.com/tdb-plus/cluster/pkg/core"
 	"go.uber.org/zap"
 )
 
@@ -13,6 +15,8 @@ type RAGService struct {
 	db       *core.Database
 	aiClient *Client
 	logger   *zap.Logger
+
+	hybrid HybridConfig
 }
 
 // NewRAGService creates a new RAG service
@@ -21,19 +25,38 @@ func NewRAGService(db *core.Database, aiClient *Client, logger *zap.Logger) *RAG
 		db:       db,
 		aiClient: aiClient,
 		logger:   logger,
+		hybrid:   DefaultHybridConfig(),
 	}
 }
 
+// SetHybridConfig replaces the vector+BM25 fusion/rerank/MMR tuning Query
+// uses. Call before serving queries; it isn't safe to change concurrently
+// with in-flight Query calls.
+func (s *RAGService) SetHybridConfig(cfg HybridConfig) {
+	s.hybrid = cfg
+}
+
 // IngestResult contains the ID of the ingested document
 type IngestResult struct {
 	DocumentID string `json:"document_id"`
 }
 
+// SourceDoc is one document Query drew its answer from, with the
+// retrieval scores that got it there - useful for debugging why a
+// particular document was (or wasn't) picked.
+type SourceDoc struct {
+	Document    map[string]interface{} `json:"document"`
+	VectorScore float64                `json:"vector_score"`
+	BM25Score   float64                `json:"bm25_score"`
+	FusedScore  float64                `json:"fused_score"`
+	RerankScore float64                `json:"rerank_score,omitempty"`
+}
+
 // QueryResult contains the answer and source documents
 type QueryResult struct {
-	Answer          string                   `json:"answer"`
-	Sources         []map[string]interface{} `json:"sources"`
-	ExecutionTimeMs int64                    `json:"execution_time_ms"`
+	Answer          string      `json:"answer"`
+	Sources         []SourceDoc `json:"sources"`
+	ExecutionTimeMs int64       `json:"execution_time_ms"`
 }
 
 // Ingest processes text, generates embedding, and stores it
@@ -65,9 +88,19 @@ func (s *RAGService) Ingest(collection, text string, metadata map[string]interfa
 	}, nil
 }
 
-// Query performs a RAG query
+// Query performs a hybrid RAG query: vector search and BM25 keyword
+// search run concurrently, their rankings are fused by Reciprocal Rank
+// Fusion, the fused top-N are optionally reranked through aiClient, and
+// MMR picks the final, diversified context set from there. Tune the
+// pipeline via SetHybridConfig.
 func (s *RAGService) Query(collection, question string) (*QueryResult, error) {
 	start := time.Now()
+	cfg := s.hybrid
+
+	candidatePool := cfg.RerankTopN
+	if candidatePool < cfg.FinalK {
+		candidatePool = cfg.FinalK
+	}
 
 	// 1. Embed Query
 	embedding, err := s.aiClient.GetEmbedding(question)
@@ -75,39 +108,67 @@ func (s *RAGService) Query(collection, question string) (*QueryResult, error) {
 		return nil, fmt.Errorf("failed to get query embedding: %w", err)
 	}
 
-	// 2. Vector Search (core.Database.VectorSearch)
-	// Searching for top 5 most relevant documents
-	vectorResults, err := s.db.VectorSearch(embedding, 5)
-	if err != nil {
-		return nil, fmt.Errorf("vector search failed: %w", err)
+	// 2. Vector search and BM25 keyword search run concurrently - they're
+	// independent reads over the same collection.
+	var (
+		vectorResults []map[string]interface{}
+		vectorErr     error
+		bm25Results   []bm25Hit
+		bm25Err       error
+	)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		vectorResults, vectorErr = s.db.VectorSearch(embedding, candidatePool)
+	}()
+	go func() {
+		defer wg.Done()
+		bm25Results, bm25Err = s.bm25Search(collection, question, candidatePool)
+	}()
+	wg.Wait()
+	if vectorErr != nil {
+		return nil, fmt.Errorf("vector search failed: %w", vectorErr)
+	}
+	if bm25Err != nil {
+		return nil, fmt.Errorf("keyword search failed: %w", bm25Err)
 	}
 
-	// 3. Construct Context
-	// We need to fetch the full documents for the IDs returned by vector search
-	// VectorSearch currently returns [{"id": "...", "score": ...}]
-	var contextDocs []string
-	var sources []map[string]interface{}
+	// 3. Fuse both rankings via RRF.
+	fused := s.fuse(collection, vectorResults, bm25Results, cfg.Alpha)
 
-	for _, res := range vectorResults { // Assuming result is []map[string]interface{}
-		id, ok := res["id"].(string)
-		if !ok {
-			continue
-		}
-		// Fetch full document
-		doc, err := s.db.Get(collection, id)
-		if err != nil {
-			s.logger.Warn("Failed to fetch context doc", zap.String("id", id), zap.Error(err))
-			continue
-		}
+	// 4. Optionally rerank the fused top-N through aiClient.
+	rerankN := cfg.RerankTopN
+	if rerankN > len(fused) {
+		rerankN = len(fused)
+	}
+	candidates := fused
+	if rerankN > 0 {
+		candidates = fused[:rerankN]
+		s.rerank(question, candidates)
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].rerankScore > candidates[j].rerankScore })
+	}
+
+	// 5. MMR picks the final, diversified context set.
+	selected := mmrMaximize(embedding, candidates, cfg.Lambda, cfg.FinalK)
 
-		if doc == nil {
+	// 6. Build context and sources from the selected documents.
+	var contextDocs []string
+	sources := make([]SourceDoc, 0, len(selected))
+	for _, sd := range selected {
+		if sd.doc == nil {
 			continue
 		}
-
-		if text, ok := doc["text"].(string); ok {
+		if text, ok := sd.doc["text"].(string); ok {
 			contextDocs = append(contextDocs, text)
-			sources = append(sources, doc)
 		}
+		sources = append(sources, SourceDoc{
+			Document:    sd.doc,
+			VectorScore: sd.vectorScore,
+			BM25Score:   sd.bm25Score,
+			FusedScore:  sd.fusedScore,
+			RerankScore: sd.rerankScore,
+		})
 	}
 
 	context := ""
@@ -115,8 +176,7 @@ func (s *RAGService) Query(collection, question string) (*QueryResult, error) {
 		context += fmt.Sprintf("Source %d:\n%s\n\n", i+1, text)
 	}
 
-	// 4. Generate Answer (aiClient.Generate)
-	// Using the Generate method which supports optional context
+	// 7. Generate Answer (aiClient.Generate)
 	genResp, err := s.aiClient.Generate(question, context, 500) // max 500 tokens
 	if err != nil {
 		return nil, fmt.Errorf("llm generation failed: %w", err)
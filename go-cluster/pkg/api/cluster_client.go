@@ -4,20 +4,43 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
-	"github.com/lumadb/cluster/pkg/cluster"
-	"github.com/lumadb/cluster/pkg/query"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/query"
 	"go.uber.org/zap"
 )
 
+// astCapabilityHeader is set by a node's AST query handler (see
+// Server.handleQueryAST) on every response, so a caller can tell a
+// "real" answer from the AST endpoint apart from a 200 some unrelated
+// proxy or old node happened to return for the same path.
+const (
+	astCapabilityHeader = "X-LumaDB-Query-Capabilities"
+	astCapabilityValue  = "ast"
+)
+
+// errASTUnsupported means nodeAddr doesn't (or doesn't yet) support the
+// /api/v1/query/ast endpoint, and ExecuteRemote should fall back to the
+// legacy SQL-reconstruction path against /api/v1/query.
+var errASTUnsupported = errors.New("remote node does not support the query AST endpoint")
+
 // APIClusterClient implements query.ClusterClient
 type APIClusterClient struct {
 	node   *cluster.Node
 	client *http.Client
 	logger *zap.Logger
+
+	// astSupport remembers, per nodeAddr, whether the AST endpoint is
+	// known to work. A node absent from the map is tried optimistically;
+	// only a confirmed miss is cached, so we never get stuck on a false
+	// negative from a transient error.
+	astSupportMu sync.RWMutex
+	astSupport   map[string]bool
 }
 
 func NewAPIClusterClient(node *cluster.Node, logger *zap.Logger) *APIClusterClient {
@@ -29,18 +52,25 @@ func NewAPIClusterClient(node *cluster.Node, logger *zap.Logger) *APIClusterClie
 }
 
 func (c *APIClusterClient) ExecuteRemote(ctx context.Context, nodeAddr string, stmt *query.Statement) (*query.Result, error) {
-	// Serialize statement back to SQL (or JSON if we supported AST transport)
-	// For now, simpler to assume we have original query string, but 'stmt' is AST.
-	// TODO: Add String() method to Statement to reconstruct SQL.
+	if c.astSupported(nodeAddr) {
+		res, err := c.executeRemoteAST(ctx, nodeAddr, stmt)
+		if err == nil {
+			return res, nil
+		}
+		if !errors.Is(err, errASTUnsupported) {
+			return nil, err
+		}
+		c.setASTSupport(nodeAddr, false)
+	}
 
-	// Hack for MVP: Reconstruct basic SQL or fail if we can't
+	// Legacy fallback for nodes that don't speak the AST endpoint yet:
+	// reconstruct basic SQL from the AST. This is lossy (e.g. it can't
+	// carry Insert values) so remote inserts require the AST endpoint.
 	sql := ""
 	if stmt.Select != nil {
 		sql = fmt.Sprintf("SELECT * FROM %s", stmt.Select.From) // simplified
-		// Better: Add "String()" to AST
 	} else if stmt.Insert != nil {
-		// TODO: serialization
-		return nil, fmt.Errorf("remote insert not fully supported yet")
+		return nil, fmt.Errorf("remote insert requires a node with AST query support")
 	}
 
 	reqBody, _ := json.Marshal(QueryRequest{Query: sql})
@@ -80,6 +110,99 @@ func (c *APIClusterClient) ExecuteRemote(ctx context.Context, nodeAddr string, s
 	return res, nil
 }
 
+// executeRemoteAST posts stmt, gob-encoded via Statement.MarshalBinary,
+// to nodeAddr's /api/v1/query/ast endpoint. It returns errASTUnsupported
+// if nodeAddr answers 404 or doesn't set astCapabilityHeader, so the
+// caller can fall back without treating it as a hard failure.
+func (c *APIClusterClient) executeRemoteAST(ctx context.Context, nodeAddr string, stmt *query.Statement) (*query.Result, error) {
+	body, err := stmt.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal statement: %w", err)
+	}
+
+	url := fmt.Sprintf("http://%s/api/v1/query/ast", nodeAddr)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.Header.Get(astCapabilityHeader) != astCapabilityValue {
+		return nil, errASTUnsupported
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("remote query failed: %s", string(respBody))
+	}
+
+	var apiRes map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&apiRes); err != nil {
+		return nil, err
+	}
+
+	res := &query.Result{}
+	if docs, ok := apiRes["documents"].([]interface{}); ok {
+		res.Documents = docs
+		res.Count = len(docs)
+	}
+	return res, nil
+}
+
+func (c *APIClusterClient) astSupported(nodeAddr string) bool {
+	c.astSupportMu.RLock()
+	defer c.astSupportMu.RUnlock()
+	supported, known := c.astSupport[nodeAddr]
+	return !known || supported
+}
+
+func (c *APIClusterClient) setASTSupport(nodeAddr string, supported bool) {
+	c.astSupportMu.Lock()
+	defer c.astSupportMu.Unlock()
+	if c.astSupport == nil {
+		c.astSupport = make(map[string]bool)
+	}
+	c.astSupport[nodeAddr] = supported
+}
+
+// Peers returns the addresses of every node in the cluster, including
+// this one (as "localhost", the sentinel executeOne/handleQuery already
+// use for self-routing), for resolving the Executor's "*" scatter-gather
+// wildcard. Omitting the local node here would silently drop its share
+// of a broadcast query's results.
+func (c *APIClusterClient) Peers(ctx context.Context) ([]string, error) {
+	peers := c.node.GetPeers()
+	addrs := make([]string, 0, len(peers)+1)
+	addrs = append(addrs, "localhost")
+	for _, addr := range peers {
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// ShardPeers returns the Raft-group replica addresses (including addr
+// itself) for whichever shard addr serves as leader or replica of, so
+// the Executor can retry a transient failure against a different
+// replica of the same shard.
+func (c *APIClusterClient) ShardPeers(ctx context.Context, addr string) ([]string, error) {
+	for _, shard := range c.node.GetShards() {
+		if shard.Leader == addr {
+			return shard.Replicas, nil
+		}
+		for _, replica := range shard.Replicas {
+			if replica == addr {
+				return shard.Replicas, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no shard found serving %s", addr)
+}
+
 func (c *APIClusterClient) ExecuteLocal(ctx context.Context, stmt *query.Statement) (*query.Result, error) {
 	db := c.node.GetDatabase()
 	if db == nil {
@@ -0,0 +1,719 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+)
+
+// AttributeValue is a DynamoDB AttributeValue as it appears on the wire:
+// exactly one of its fields is set, naming the type of the value it
+// carries. See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_AttributeValue.html.
+type AttributeValue struct {
+	S    *string                   `json:"S,omitempty"`
+	N    *string                   `json:"N,omitempty"`
+	B    []byte                    `json:"B,omitempty"`
+	BOOL *bool                     `json:"BOOL,omitempty"`
+	NULL *bool                     `json:"NULL,omitempty"`
+	M    map[string]AttributeValue `json:"M,omitempty"`
+	L    []AttributeValue          `json:"L,omitempty"`
+	SS   []string                  `json:"SS,omitempty"`
+	NS   []string                  `json:"NS,omitempty"`
+	BS   [][]byte                  `json:"BS,omitempty"`
+}
+
+// toGo converts av to the plain interface{} representation core.Database
+// stores documents as (the same shape json.Marshal/Unmarshal of a
+// map[string]interface{} produces).
+func (av AttributeValue) toGo() (interface{}, error) {
+	switch {
+	case av.S != nil:
+		return *av.S, nil
+	case av.N != nil:
+		n, err := strconv.ParseFloat(*av.N, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid N value %q: %w", *av.N, err)
+		}
+		return n, nil
+	case av.B != nil:
+		return av.B, nil
+	case av.BOOL != nil:
+		return *av.BOOL, nil
+	case av.NULL != nil:
+		return nil, nil
+	case av.M != nil:
+		out := make(map[string]interface{}, len(av.M))
+		for k, v := range av.M {
+			gv, err := v.toGo()
+			if err != nil {
+				return nil, err
+			}
+			out[k] = gv
+		}
+		return out, nil
+	case av.L != nil:
+		out := make([]interface{}, len(av.L))
+		for i, v := range av.L {
+			gv, err := v.toGo()
+			if err != nil {
+				return nil, err
+			}
+			out[i] = gv
+		}
+		return out, nil
+	case av.SS != nil:
+		out := make([]interface{}, len(av.SS))
+		for i, v := range av.SS {
+			out[i] = v
+		}
+		return out, nil
+	case av.NS != nil:
+		out := make([]interface{}, len(av.NS))
+		for i, v := range av.NS {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid NS value %q: %w", v, err)
+			}
+			out[i] = n
+		}
+		return out, nil
+	case av.BS != nil:
+		out := make([]interface{}, len(av.BS))
+		for i, v := range av.BS {
+			out[i] = v
+		}
+		return out, nil
+	default:
+		return nil, nil
+	}
+}
+
+// attributeValueFromGo converts a Go value (as decoded from a
+// core.Database document) back into the AttributeValue wire format.
+func attributeValueFromGo(v interface{}) (AttributeValue, error) {
+	switch val := v.(type) {
+	case nil:
+		t := true
+		return AttributeValue{NULL: &t}, nil
+	case string:
+		return AttributeValue{S: &val}, nil
+	case bool:
+		return AttributeValue{BOOL: &val}, nil
+	case float64:
+		s := strconv.FormatFloat(val, 'g', -1, 64)
+		return AttributeValue{N: &s}, nil
+	case map[string]interface{}:
+		m := make(map[string]AttributeValue, len(val))
+		for k, e := range val {
+			av, err := attributeValueFromGo(e)
+			if err != nil {
+				return AttributeValue{}, err
+			}
+			m[k] = av
+		}
+		return AttributeValue{M: m}, nil
+	case []interface{}:
+		l := make([]AttributeValue, len(val))
+		for i, e := range val {
+			av, err := attributeValueFromGo(e)
+			if err != nil {
+				return AttributeValue{}, err
+			}
+			l[i] = av
+		}
+		return AttributeValue{L: l}, nil
+	default:
+		return AttributeValue{}, fmt.Errorf("unsupported document value type %T", v)
+	}
+}
+
+// itemToDoc converts a DynamoDB Item (or Key) map into the
+// map[string]interface{} shape Node.InsertDocument/GetDocument expect.
+func itemToDoc(item map[string]AttributeValue) (map[string]interface{}, error) {
+	doc := make(map[string]interface{}, len(item))
+	for k, av := range item {
+		v, err := av.toGo()
+		if err != nil {
+			return nil, err
+		}
+		doc[k] = v
+	}
+	return doc, nil
+}
+
+// docToItem converts a core.Database document back into a DynamoDB Item,
+// dropping the internal "_id" field DynamoDB clients never asked for.
+func docToItem(doc map[string]interface{}) (map[string]AttributeValue, error) {
+	item := make(map[string]AttributeValue, len(doc))
+	for k, v := range doc {
+		if k == "_id" {
+			continue
+		}
+		av, err := attributeValueFromGo(v)
+		if err != nil {
+			return nil, err
+		}
+		item[k] = av
+	}
+	return item, nil
+}
+
+// dynamoKeySchema records which item attributes form a table's primary
+// key, learned from CreateTable's KeySchema or (for tables never
+// explicitly created) inferred from the first request that names one.
+type dynamoKeySchema struct {
+	PartitionKey string
+	SortKey      string // empty if the table has no sort key
+}
+
+// dynamoTables tracks key schemas across requests on a single Server, the
+// same way replication/cron/config state lives alongside the rest of the
+// server's subsystems.
+type dynamoTables struct {
+	mu     sync.RWMutex
+	tables map[string]dynamoKeySchema
+}
+
+func newDynamoTables() *dynamoTables {
+	return &dynamoTables{tables: make(map[string]dynamoKeySchema)}
+}
+
+func (t *dynamoTables) get(table string) (dynamoKeySchema, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	schema, ok := t.tables[table]
+	return schema, ok
+}
+
+func (t *dynamoTables) set(table string, schema dynamoKeySchema) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tables[table] = schema
+}
+
+// schemaFor returns table's key schema, registering one inferred from
+// attrs if the table hasn't been seen before (via CreateTable or an
+// earlier request): "pk"/"sk" if present, matching this tree's example
+// clients, otherwise the alphabetically first attribute in attrs as the
+// partition key and the second, if there is one, as the sort key.
+func (t *dynamoTables) schemaFor(table string, attrs map[string]AttributeValue) (dynamoKeySchema, error) {
+	if schema, ok := t.get(table); ok {
+		return schema, nil
+	}
+
+	if _, ok := attrs["pk"]; ok {
+		schema := dynamoKeySchema{PartitionKey: "pk"}
+		if _, ok := attrs["sk"]; ok {
+			schema.SortKey = "sk"
+		}
+		t.set(table, schema)
+		return schema, nil
+	}
+
+	names := make([]string, 0, len(attrs))
+	for k := range attrs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return dynamoKeySchema{}, fmt.Errorf("cannot infer key schema for table %q with no key attributes", table)
+	}
+	schema := dynamoKeySchema{PartitionKey: names[0]}
+	if len(names) > 1 {
+		schema.SortKey = names[1]
+	}
+	t.set(table, schema)
+	return schema, nil
+}
+
+// docID builds the composite document ID core.Database stores items
+// under from a table's key attributes, so GetItem/UpdateItem/DeleteItem
+// can look a document back up by the same ID PutItem derived for it.
+func docID(schema dynamoKeySchema, attrs map[string]AttributeValue) (string, error) {
+	pk, ok := attrs[schema.PartitionKey]
+	if !ok {
+		return "", fmt.Errorf("missing partition key attribute %q", schema.PartitionKey)
+	}
+	id := attributeValuePlainString(pk)
+	if schema.SortKey != "" {
+		sk, ok := attrs[schema.SortKey]
+		if !ok {
+			return "", fmt.Errorf("missing sort key attribute %q", schema.SortKey)
+		}
+		id += "#" + attributeValuePlainString(sk)
+	}
+	return id, nil
+}
+
+// attributeValuePlainString renders av's scalar value as a string for use
+// inside a composite document ID, without going through toGo's
+// interface{} boxing.
+func attributeValuePlainString(av AttributeValue) string {
+	switch {
+	case av.S != nil:
+		return *av.S
+	case av.N != nil:
+		return *av.N
+	case av.B != nil:
+		return string(av.B)
+	case av.BOOL != nil:
+		return strconv.FormatBool(*av.BOOL)
+	default:
+		return ""
+	}
+}
+
+// dynamoError writes a DynamoDB-shaped error response: the `__type` field
+// is what every AWS SDK switches on to map the response to a typed
+// exception (ResourceNotFoundException, ValidationException, ...).
+func dynamoError(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, gin.H{"__type": "com.amazonaws.dynamodb.v20120810#" + errType, "message": message})
+}
+
+// handleDynamoDB is the single entry point for the DynamoDB v1.0
+// wire protocol: every operation is a POST to this path with the
+// operation name carried in the X-Amz-Target header
+// (e.g. "DynamoDB_20120810.PutItem"), not in the URL.
+func (s *Server) handleDynamoDB(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := verifySigV4(c.Request, body, s.dynamoAccessKeyID, s.dynamoSecretAccessKey); err != nil {
+		dynamoError(c, http.StatusBadRequest, "UnrecognizedClientException", err.Error())
+		return
+	}
+
+	target := c.GetHeader("X-Amz-Target")
+	_, op, ok := strings.Cut(target, ".")
+	if !ok {
+		dynamoError(c, http.StatusBadRequest, "MissingAuthenticationTokenException", "missing or malformed X-Amz-Target header")
+		return
+	}
+
+	switch op {
+	case "PutItem":
+		s.dynamoPutItem(c, body)
+	case "GetItem":
+		s.dynamoGetItem(c, body)
+	case "Query":
+		s.dynamoQuery(c, body)
+	case "UpdateItem":
+		s.dynamoUpdateItem(c, body)
+	case "DeleteItem":
+		s.dynamoDeleteItem(c, body)
+	case "BatchWriteItem":
+		s.dynamoBatchWriteItem(c, body)
+	case "Scan":
+		s.dynamoScan(c, body)
+	case "CreateTable":
+		s.dynamoCreateTable(c, body)
+	case "DescribeTable":
+		s.dynamoDescribeTable(c, body)
+	default:
+		dynamoError(c, http.StatusBadRequest, "UnknownOperationException", fmt.Sprintf("unsupported operation %q", op))
+	}
+}
+
+func (s *Server) dynamoPutItem(c *gin.Context, body []byte) {
+	var req struct {
+		TableName string                    `json:"TableName"`
+		Item      map[string]AttributeValue `json:"Item"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	schema, err := s.dynamoTables.schemaFor(req.TableName, req.Item)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+	id, err := docID(schema, req.Item)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	doc, err := itemToDoc(req.Item)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+	doc["_id"] = id
+
+	if _, err := s.node.InsertDocument(req.TableName, doc); err != nil {
+		dynamoError(c, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (s *Server) dynamoGetItem(c *gin.Context, body []byte) {
+	var req struct {
+		TableName string                    `json:"TableName"`
+		Key       map[string]AttributeValue `json:"Key"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	schema, err := s.dynamoTables.schemaFor(req.TableName, req.Key)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+	id, err := docID(schema, req.Key)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	doc, err := s.node.GetDocumentContext(c.Request.Context(), req.TableName, id, cluster.Stale)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+
+	item, err := docToItem(doc)
+	if err != nil {
+		dynamoError(c, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"Item": item})
+}
+
+func (s *Server) dynamoQuery(c *gin.Context, body []byte) {
+	var req struct {
+		TableName                 string                    `json:"TableName"`
+		KeyConditionExpression    string                    `json:"KeyConditionExpression"`
+		FilterExpression          string                    `json:"FilterExpression"`
+		ExpressionAttributeNames  map[string]string         `json:"ExpressionAttributeNames"`
+		ExpressionAttributeValues map[string]AttributeValue `json:"ExpressionAttributeValues"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	filter, err := parseEqualityExpression(req.KeyConditionExpression, req.ExpressionAttributeNames, req.ExpressionAttributeValues)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+	if req.FilterExpression != "" {
+		extra, err := parseEqualityExpression(req.FilterExpression, req.ExpressionAttributeNames, req.ExpressionAttributeValues)
+		if err != nil {
+			dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+			return
+		}
+		for k, v := range extra {
+			filter[k] = v
+		}
+	}
+
+	docs, err := s.node.RunQueryContext(c.Request.Context(), req.TableName, map[string]interface{}{"filter": filter}, cluster.Stale)
+	if err != nil {
+		dynamoError(c, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+	writeDynamoItems(c, docs)
+}
+
+func (s *Server) dynamoScan(c *gin.Context, body []byte) {
+	var req struct {
+		TableName string `json:"TableName"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	docs, err := s.node.RunQueryContext(c.Request.Context(), req.TableName, map[string]interface{}{}, cluster.Stale)
+	if err != nil {
+		dynamoError(c, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+	writeDynamoItems(c, docs)
+}
+
+func writeDynamoItems(c *gin.Context, docs []map[string]interface{}) {
+	items := make([]map[string]AttributeValue, 0, len(docs))
+	for _, doc := range docs {
+		item, err := docToItem(doc)
+		if err != nil {
+			dynamoError(c, http.StatusInternalServerError, "InternalServerError", err.Error())
+			return
+		}
+		items = append(items, item)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"Items":        items,
+		"Count":        len(items),
+		"ScannedCount": len(items),
+	})
+}
+
+func (s *Server) dynamoUpdateItem(c *gin.Context, body []byte) {
+	var req struct {
+		TableName                 string                    `json:"TableName"`
+		Key                       map[string]AttributeValue `json:"Key"`
+		UpdateExpression          string                    `json:"UpdateExpression"`
+		ExpressionAttributeNames  map[string]string         `json:"ExpressionAttributeNames"`
+		ExpressionAttributeValues map[string]AttributeValue `json:"ExpressionAttributeValues"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	schema, err := s.dynamoTables.schemaFor(req.TableName, req.Key)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+	id, err := docID(schema, req.Key)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	updates, err := parseUpdateSetExpression(req.UpdateExpression, req.ExpressionAttributeNames, req.ExpressionAttributeValues)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	if err := s.node.UpdateDocument(req.TableName, id, updates); err != nil {
+		dynamoError(c, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (s *Server) dynamoDeleteItem(c *gin.Context, body []byte) {
+	var req struct {
+		TableName string                    `json:"TableName"`
+		Key       map[string]AttributeValue `json:"Key"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	schema, err := s.dynamoTables.schemaFor(req.TableName, req.Key)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+	id, err := docID(schema, req.Key)
+	if err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	if err := s.node.DeleteDocument(req.TableName, id); err != nil {
+		dynamoError(c, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (s *Server) dynamoBatchWriteItem(c *gin.Context, body []byte) {
+	var req struct {
+		RequestItems map[string][]struct {
+			PutRequest *struct {
+				Item map[string]AttributeValue `json:"Item"`
+			} `json:"PutRequest"`
+			DeleteRequest *struct {
+				Key map[string]AttributeValue `json:"Key"`
+			} `json:"DeleteRequest"`
+		} `json:"RequestItems"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	for table, writes := range req.RequestItems {
+		for _, w := range writes {
+			switch {
+			case w.PutRequest != nil:
+				schema, err := s.dynamoTables.schemaFor(table, w.PutRequest.Item)
+				if err != nil {
+					dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+					return
+				}
+				id, err := docID(schema, w.PutRequest.Item)
+				if err != nil {
+					dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+					return
+				}
+				doc, err := itemToDoc(w.PutRequest.Item)
+				if err != nil {
+					dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+					return
+				}
+				doc["_id"] = id
+				if _, err := s.node.InsertDocument(table, doc); err != nil {
+					dynamoError(c, http.StatusInternalServerError, "InternalServerError", err.Error())
+					return
+				}
+			case w.DeleteRequest != nil:
+				schema, err := s.dynamoTables.schemaFor(table, w.DeleteRequest.Key)
+				if err != nil {
+					dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+					return
+				}
+				id, err := docID(schema, w.DeleteRequest.Key)
+				if err != nil {
+					dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+					return
+				}
+				if err := s.node.DeleteDocument(table, id); err != nil {
+					dynamoError(c, http.StatusInternalServerError, "InternalServerError", err.Error())
+					return
+				}
+			}
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"UnprocessedItems": gin.H{}})
+}
+
+func (s *Server) dynamoCreateTable(c *gin.Context, body []byte) {
+	var req struct {
+		TableName string `json:"TableName"`
+		KeySchema []struct {
+			AttributeName string `json:"AttributeName"`
+			KeyType       string `json:"KeyType"`
+		} `json:"KeySchema"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+
+	var schema dynamoKeySchema
+	for _, k := range req.KeySchema {
+		switch k.KeyType {
+		case "HASH":
+			schema.PartitionKey = k.AttributeName
+		case "RANGE":
+			schema.SortKey = k.AttributeName
+		}
+	}
+	if schema.PartitionKey == "" {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", "KeySchema must include a HASH key")
+		return
+	}
+	s.dynamoTables.set(req.TableName, schema)
+
+	c.JSON(http.StatusOK, gin.H{"TableDescription": dynamoTableDescription(req.TableName)})
+}
+
+func (s *Server) dynamoDescribeTable(c *gin.Context, body []byte) {
+	var req struct {
+		TableName string `json:"TableName"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		dynamoError(c, http.StatusBadRequest, "ValidationException", err.Error())
+		return
+	}
+	if _, ok := s.dynamoTables.get(req.TableName); !ok {
+		dynamoError(c, http.StatusBadRequest, "ResourceNotFoundException", fmt.Sprintf("table %q not found", req.TableName))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"Table": dynamoTableDescription(req.TableName)})
+}
+
+func dynamoTableDescription(tableName string) gin.H {
+	return gin.H{
+		"TableName":   tableName,
+		"TableStatus": "ACTIVE",
+	}
+}
+
+// parseEqualityExpression parses the small subset of DynamoDB's
+// condition-expression grammar this tree's example clients actually
+// emit: one or more `attr = :placeholder` clauses joined by `AND`. `attr`
+// may be a `#alias` resolved through names. Into a
+// map[string]interface{}{attr: {"_eq": value}} filter, the same shape
+// pkg/api/rest/generator.go's PostgREST-style filters use.
+func parseEqualityExpression(expr string, names map[string]string, values map[string]AttributeValue) (map[string]interface{}, error) {
+	filter := map[string]interface{}{}
+	if strings.TrimSpace(expr) == "" {
+		return filter, nil
+	}
+
+	for _, clause := range strings.Split(expr, " AND ") {
+		attr, placeholder, ok := strings.Cut(clause, "=")
+		if !ok {
+			return nil, fmt.Errorf("unsupported expression clause %q", clause)
+		}
+		attr = strings.TrimSpace(attr)
+		placeholder = strings.TrimSpace(placeholder)
+
+		if resolved, ok := names[attr]; ok {
+			attr = resolved
+		}
+		av, ok := values[placeholder]
+		if !ok {
+			return nil, fmt.Errorf("no value bound for placeholder %q", placeholder)
+		}
+		v, err := av.toGo()
+		if err != nil {
+			return nil, err
+		}
+		filter[attr] = map[string]interface{}{"_eq": v}
+	}
+	return filter, nil
+}
+
+// parseUpdateSetExpression parses the `SET attr = :val, ...` subset of
+// UpdateExpression this tree's example clients emit, into the
+// map[string]interface{} Node.UpdateDocument expects.
+func parseUpdateSetExpression(expr string, names map[string]string, values map[string]AttributeValue) (map[string]interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(strings.ToUpper(expr), "SET ") {
+		return nil, fmt.Errorf("unsupported UpdateExpression %q (only SET is implemented)", expr)
+	}
+	expr = strings.TrimSpace(expr[len("SET "):])
+
+	updates := map[string]interface{}{}
+	for _, clause := range strings.Split(expr, ",") {
+		attr, placeholder, ok := strings.Cut(clause, "=")
+		if !ok {
+			return nil, fmt.Errorf("unsupported SET clause %q", clause)
+		}
+		attr = strings.TrimSpace(attr)
+		placeholder = strings.TrimSpace(placeholder)
+
+		if resolved, ok := names[attr]; ok {
+			attr = resolved
+		}
+		av, ok := values[placeholder]
+		if !ok {
+			return nil, fmt.Errorf("no value bound for placeholder %q", placeholder)
+		}
+		v, err := av.toGo()
+		if err != nil {
+			return nil, err
+		}
+		updates[attr] = v
+	}
+	return updates, nil
+}
@@ -1,31 +1,64 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
-
-	"tdb-fork/go-cluster/pkg/platform/graphql" // Assuming MetadataStore is used here or similar
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/nats"
+	"github.com/tdb-plus/cluster/pkg/platform/graphql"
 )
 
-// Generator automatically creates REST endpoints for collections
+// reservedQueryParams are query-string keys with special PostgREST
+// meaning rather than being treated as column filters.
+var reservedQueryParams = map[string]bool{
+	"select":      true,
+	"order":       true,
+	"limit":       true,
+	"offset":      true,
+	"consistency": true,
+}
+
+// consistencyFromRequest reads the "?consistency=strong|weak" query
+// param, defaulting to cluster.Stale (the historical, pre-linearizable
+// behavior of this endpoint) for anything other than an exact "strong"
+// match.
+func consistencyFromRequest(c *gin.Context) cluster.ReadConsistency {
+	if strings.EqualFold(c.Query("consistency"), "strong") {
+		return cluster.Linearizable
+	}
+	return cluster.Stale
+}
+
+// Generator automatically creates PostgREST-style REST endpoints for
+// every table tracked in a MetadataStore, executing them against node.
+// If js is non-nil, it also bridges the JetStream messaging layer (see
+// RegisterRoutes) so browser and non-NATS clients can reach streams,
+// consumers, and KV buckets over plain HTTP/SSE.
 type Generator struct {
 	router   *gin.Engine
 	metadata *graphql.MetadataStore
-	// In a real implementation, we'd need access to the data layer (e.g., LumaDB Go client)
+	node     *cluster.Node
+	js       *nats.JetStreamEngine
 }
 
-func NewGenerator(router *gin.Engine, metadata *graphql.MetadataStore) *Generator {
+func NewGenerator(router *gin.Engine, metadata *graphql.MetadataStore, node *cluster.Node, js *nats.JetStreamEngine) *Generator {
 	return &Generator{
 		router:   router,
 		metadata: metadata,
+		node:     node,
+		js:       js,
 	}
 }
 
-// RegisterRoutes generates CRUD endpoints for all tables
+// RegisterRoutes generates CRUD endpoints for all tracked tables, plus
+// the JetStream bridge routes if the Generator was built with a
+// JetStreamEngine.
 func (g *Generator) RegisterRoutes() {
-	tables := g.metadata.GetTables()
+	tables := g.metadata.ListTables()
 
 	api := g.router.Group("/api/v1")
 
@@ -46,24 +79,91 @@ func (g *Generator) RegisterRoutes() {
 
 		// DELETE /api/v1/:collection/:id
 		api.DELETE("/"+tableName+"/:id", g.handleDelete(tableName))
+
+		// GET /api/v1/:collection/schema
+		api.GET("/"+tableName+"/schema", g.handleSchema(table))
+	}
+
+	if g.js != nil {
+		g.registerStreamingRoutes(api)
+	}
+}
+
+func (g *Generator) handleSchema(table *graphql.TableMetadata) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, tableJSONSchema(table))
 	}
 }
 
 func (g *Generator) handleList(collection string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-		// where := c.Query("where")
-		// orderBy := c.Query("order_by")
+		filter := make(map[string]interface{})
+		for key, values := range c.Request.URL.Query() {
+			if reservedQueryParams[key] || len(values) == 0 {
+				continue
+			}
+			cond, err := parseFilterValue(values[0])
+			if err != nil {
+				writeProblem(c, http.StatusBadRequest, "invalid filter", fmt.Sprintf("column %q: %v", key, err))
+				return
+			}
+			filter[key] = cond
+		}
 
-		// Mock response for now
-		c.JSON(http.StatusOK, gin.H{
-			"collection": collection,
-			"limit":      limit,
-			"offset":     offset,
-			"data":       []gin.H{{"id": 1, "name": "Mock Data 1"}, {"id": 2, "name": "Mock Data 2"}},
-			"total":      2,
-		})
+		order, err := parseOrder(c.Query("order"))
+		if err != nil {
+			writeProblem(c, http.StatusBadRequest, "invalid order", err.Error())
+			return
+		}
+
+		selectCols, embeds, err := parseSelect(c.Query("select"))
+		if err != nil {
+			writeProblem(c, http.StatusBadRequest, "invalid select", err.Error())
+			return
+		}
+
+		limit, offset, rangeRequested := paginationFromRequest(c)
+
+		query := map[string]interface{}{}
+		if len(filter) > 0 {
+			query["filter"] = filter
+		}
+		if len(order) > 0 {
+			query["order"] = order
+		}
+
+		total, err := g.countMatching(collection, filter)
+		if err != nil {
+			writeProblem(c, http.StatusInternalServerError, "query failed", err.Error())
+			return
+		}
+
+		countedQuery := map[string]interface{}{"limit": limit, "offset": offset}
+		for k, v := range query {
+			countedQuery[k] = v
+		}
+		docs, err := g.node.RunQuery(collection, countedQuery, consistencyFromRequest(c))
+		if err != nil {
+			writeProblem(c, http.StatusInternalServerError, "query failed", err.Error())
+			return
+		}
+
+		for _, rel := range embeds {
+			if err := g.embedRelationship(collection, docs, rel); err != nil {
+				writeProblem(c, http.StatusInternalServerError, "embed failed", err.Error())
+				return
+			}
+		}
+		if len(selectCols) > 0 {
+			docs = projectColumns(docs, selectCols)
+		}
+
+		if rangeRequested {
+			c.Header("Content-Range", fmt.Sprintf("%d-%d/%d", offset, offset+len(docs)-1, total))
+		} else {
+			c.Header("Content-Range", fmt.Sprintf("%d-%d/%d", offset, offset+len(docs), total))
+		}
+		c.JSON(http.StatusOK, docs)
 	}
 }
 
@@ -71,26 +171,37 @@ func (g *Generator) handleGet(collection string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 
-		c.JSON(http.StatusOK, gin.H{
-			"collection": collection,
-			"id":         id,
-			"data":       gin.H{"id": id, "name": "Single Mock Data"},
-		})
+		doc, err := g.node.GetDocument(collection, id, consistencyFromRequest(c))
+		if err != nil {
+			writeProblem(c, http.StatusNotFound, "not found", err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, doc)
 	}
 }
 
 func (g *Generator) handleCreate(collection string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var payload interface{}
-		if err := c.ShouldBindJSON(&payload); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		var doc map[string]interface{}
+		if err := c.ShouldBindJSON(&doc); err != nil {
+			writeProblem(c, http.StatusBadRequest, "invalid body", err.Error())
+			return
+		}
+
+		if err := g.validateAgainstSchema(collection, doc); err != nil {
+			writeProblem(c, http.StatusBadRequest, "schema validation failed", err.Error())
+			return
+		}
+
+		id, err := g.node.InsertDocument(collection, doc)
+		if err != nil {
+			writeProblem(c, http.StatusInternalServerError, "insert failed", err.Error())
 			return
 		}
 
 		c.JSON(http.StatusCreated, gin.H{
-			"collection": collection,
-			"status":     "created",
-			"data":       payload,
+			"_id": id,
 		})
 	}
 }
@@ -98,17 +209,26 @@ func (g *Generator) handleCreate(collection string) gin.HandlerFunc {
 func (g *Generator) handleUpdate(collection string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
-		var payload interface{}
-		if err := c.ShouldBindJSON(&payload); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		var updates map[string]interface{}
+		if err := c.ShouldBindJSON(&updates); err != nil {
+			writeProblem(c, http.StatusBadRequest, "invalid body", err.Error())
+			return
+		}
+
+		if err := g.validateAgainstSchema(collection, updates); err != nil {
+			writeProblem(c, http.StatusBadRequest, "schema validation failed", err.Error())
+			return
+		}
+
+		if err := g.node.UpdateDocument(collection, id, updates); err != nil {
+			writeProblem(c, http.StatusInternalServerError, "update failed", err.Error())
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"collection": collection,
-			"id":         id,
-			"status":     "updated",
-			"data":       payload,
+			"_id":    id,
+			"status": "updated",
 		})
 	}
 }
@@ -117,10 +237,320 @@ func (g *Generator) handleDelete(collection string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 
+		if err := g.node.DeleteDocument(collection, id); err != nil {
+			writeProblem(c, http.StatusInternalServerError, "delete failed", err.Error())
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"collection": collection,
-			"id":         id,
-			"status":     "deleted",
+			"_id":    id,
+			"status": "deleted",
 		})
 	}
 }
+
+// countMatching returns how many documents in collection match filter,
+// by re-running the query unbounded. There's no count-only path into
+// the storage engine yet, so this is the honest (if wasteful) way to
+// populate Content-Range until one exists.
+func (g *Generator) countMatching(collection string, filter map[string]interface{}) (int, error) {
+	query := map[string]interface{}{}
+	if len(filter) > 0 {
+		query["filter"] = filter
+	}
+	docs, err := g.node.RunQuery(collection, query, cluster.Stale)
+	if err != nil {
+		return 0, err
+	}
+	return len(docs), nil
+}
+
+// embedRelationship resolves a `select=...,rel(cols)` embed for every
+// row in docs in place, matching rel against the FK metadata tracked on
+// MetadataStore for collection.
+func (g *Generator) embedRelationship(collection string, docs []map[string]interface{}, rel embedSpec) error {
+	relationships := g.metadata.GetRelationships(collection)
+	var match *graphql.Relationship
+	for _, r := range relationships {
+		if r.Name == rel.name {
+			match = r
+			break
+		}
+	}
+	if match == nil {
+		return fmt.Errorf("unknown relationship %q on %q", rel.name, collection)
+	}
+
+	for _, doc := range docs {
+		filter := make(map[string]interface{})
+		for fromField, toField := range match.FieldMapping {
+			if val, ok := doc[fromField]; ok {
+				filter[toField] = val
+			}
+		}
+
+		related, err := g.node.RunQuery(match.ToTable, map[string]interface{}{"filter": filter}, cluster.Stale)
+		if err != nil {
+			return err
+		}
+		if len(rel.columns) > 0 {
+			related = projectColumns(related, rel.columns)
+		}
+
+		if match.Type == "object" {
+			if len(related) > 0 {
+				doc[rel.name] = related[0]
+			} else {
+				doc[rel.name] = nil
+			}
+		} else {
+			doc[rel.name] = related
+		}
+	}
+	return nil
+}
+
+// validateAgainstSchema enforces the JSON Schema generated from the
+// table's tracked column metadata, rejecting unknown columns. Tables
+// with no tracked columns skip validation entirely (metadata about
+// their shape hasn't been configured yet).
+func (g *Generator) validateAgainstSchema(collection string, doc map[string]interface{}) error {
+	table, ok := g.metadata.GetTable(collection)
+	if !ok || len(table.Columns) == 0 {
+		return nil
+	}
+
+	for key := range doc {
+		if _, known := table.Columns[key]; !known {
+			return fmt.Errorf("unknown column %q", key)
+		}
+	}
+	for key, want := range table.Columns {
+		val, present := doc[key]
+		if !present || val == nil {
+			continue
+		}
+		if !matchesColumnType(val, want) {
+			return fmt.Errorf("column %q: expected %s", key, want)
+		}
+	}
+	return nil
+}
+
+// tableJSONSchema builds a draft-07-style JSON Schema document for
+// collection from its tracked column metadata, for clients that want to
+// introspect the generated API.
+func tableJSONSchema(table *graphql.TableMetadata) map[string]interface{} {
+	properties := make(map[string]interface{}, len(table.Columns))
+	for name, typ := range table.Columns {
+		properties[name] = map[string]interface{}{"type": string(typ)}
+	}
+	return map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                table.Name,
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func matchesColumnType(val interface{}, typ graphql.ColumnType) bool {
+	switch typ {
+	case graphql.ColumnTypeString:
+		_, ok := val.(string)
+		return ok
+	case graphql.ColumnTypeBoolean:
+		_, ok := val.(bool)
+		return ok
+	case graphql.ColumnTypeInteger:
+		n, ok := val.(float64)
+		return ok && n == float64(int64(n))
+	case graphql.ColumnTypeNumber:
+		_, ok := val.(float64)
+		return ok
+	default:
+		return true
+	}
+}
+
+// filterValue is one column condition parsed from a PostgREST-style
+// `column=op.value` query parameter, e.g. `age=gte.21`.
+type filterValue struct {
+	Op    string      `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+// postgrestOps maps PostgREST's operator prefixes to the Hasura-style
+// `_op` keys MetadataStore filters already use elsewhere (see
+// GraphQLEngine's `_bool_exp` input types).
+var postgrestOps = map[string]string{
+	"eq":    "_eq",
+	"neq":   "_neq",
+	"gt":    "_gt",
+	"gte":   "_gte",
+	"lt":    "_lt",
+	"lte":   "_lte",
+	"like":  "_like",
+	"ilike": "_ilike",
+	"in":    "_in",
+	"is":    "_is",
+}
+
+// parseFilterValue parses a single `op.value` query parameter value. A
+// value with no recognized `op.` prefix is treated as a bare `eq`.
+func parseFilterValue(raw string) (map[string]interface{}, error) {
+	op, rest, hasOp := strings.Cut(raw, ".")
+	if !hasOp {
+		return map[string]interface{}{"_eq": raw}, nil
+	}
+	key, ok := postgrestOps[op]
+	if !ok {
+		return map[string]interface{}{"_eq": raw}, nil
+	}
+
+	if key == "_in" {
+		rest = strings.TrimSuffix(strings.TrimPrefix(rest, "("), ")")
+		var values []interface{}
+		for _, v := range strings.Split(rest, ",") {
+			values = append(values, v)
+		}
+		return map[string]interface{}{key: values}, nil
+	}
+	return map[string]interface{}{key: rest}, nil
+}
+
+// orderClause is one `column.dir` entry from an `order=` query param.
+type orderClause struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc"`
+}
+
+func parseOrder(raw string) ([]orderClause, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var clauses []orderClause
+	for _, part := range strings.Split(raw, ",") {
+		column, dir, _ := strings.Cut(part, ".")
+		desc := false
+		switch dir {
+		case "", "asc":
+			desc = false
+		case "desc":
+			desc = true
+		default:
+			return nil, fmt.Errorf("unknown sort direction %q for column %q", dir, column)
+		}
+		clauses = append(clauses, orderClause{Column: column, Desc: desc})
+	}
+	return clauses, nil
+}
+
+// embedSpec is one `rel(col,col)` entry from a `select=` query param.
+type embedSpec struct {
+	name    string
+	columns []string
+}
+
+// parseSelect splits `select=id,name,author(id,name)` into the plain
+// columns to project and the embedded relationships to resolve.
+func parseSelect(raw string) ([]string, []embedSpec, error) {
+	if raw == "" {
+		return nil, nil, nil
+	}
+
+	var columns []string
+	var embeds []embedSpec
+
+	for len(raw) > 0 {
+		raw = strings.TrimPrefix(raw, ",")
+		comma := strings.IndexByte(raw, ',')
+		paren := strings.IndexByte(raw, '(')
+
+		if paren == -1 || (comma != -1 && comma < paren) {
+			field := raw
+			if comma != -1 {
+				field = raw[:comma]
+				raw = raw[comma+1:]
+			} else {
+				raw = ""
+			}
+			if field != "" {
+				columns = append(columns, field)
+			}
+			continue
+		}
+
+		name := raw[:paren]
+		close := strings.IndexByte(raw, ')')
+		if close == -1 || close < paren {
+			return nil, nil, fmt.Errorf("unterminated embed %q", raw)
+		}
+		inner := raw[paren+1 : close]
+		var cols []string
+		for _, c := range strings.Split(inner, ",") {
+			if c != "" {
+				cols = append(cols, c)
+			}
+		}
+		embeds = append(embeds, embedSpec{name: name, columns: cols})
+		raw = strings.TrimPrefix(raw[close+1:], ",")
+	}
+
+	return columns, embeds, nil
+}
+
+func projectColumns(docs []map[string]interface{}, columns []string) []map[string]interface{} {
+	projected := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		row := make(map[string]interface{}, len(columns))
+		for _, col := range columns {
+			if val, ok := doc[col]; ok {
+				row[col] = val
+			}
+		}
+		projected[i] = row
+	}
+	return projected
+}
+
+// paginationFromRequest resolves limit/offset from either the
+// `limit`/`offset` query params or a PostgREST-style `Range: 0-24`
+// header, the latter taking precedence since it's the more specific
+// ask. It also reports whether Range-style pagination was requested, so
+// the handler can shape Content-Range the same way the client asked.
+func paginationFromRequest(c *gin.Context) (limit, offset int, rangeRequested bool) {
+	limit, _ = strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	rng := c.GetHeader("Range")
+	if rng == "" {
+		return limit, offset, false
+	}
+	start, end, ok := strings.Cut(rng, "-")
+	if !ok {
+		return limit, offset, false
+	}
+	startN, err1 := strconv.Atoi(start)
+	endN, err2 := strconv.Atoi(end)
+	if err1 != nil || err2 != nil || endN < startN {
+		return limit, offset, false
+	}
+	return endN - startN + 1, startN, true
+}
+
+// problemDetail is an RFC 7807 `application/problem+json` error body.
+type problemDetail struct {
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func writeProblem(c *gin.Context, status int, title, detail string) {
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(status, problemDetail{
+		Title:  title,
+		Status: status,
+		Detail: detail,
+	})
+}
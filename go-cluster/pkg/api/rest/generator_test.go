@@ -0,0 +1,94 @@
+package rest
+
+import "testing"
+
+func TestParseFilterValue(t *testing.T) {
+	cases := []struct {
+		raw     string
+		wantOp  string
+		wantVal interface{}
+	}{
+		{"gte.21", "_gte", "21"},
+		{"eq.active", "_eq", "active"},
+		{"bare-value", "_eq", "bare-value"},
+	}
+
+	for _, tc := range cases {
+		got, err := parseFilterValue(tc.raw)
+		if err != nil {
+			t.Fatalf("parseFilterValue(%q) error: %v", tc.raw, err)
+		}
+		if got[tc.wantOp] != tc.wantVal {
+			t.Errorf("parseFilterValue(%q) = %v, want {%q: %v}", tc.raw, got, tc.wantOp, tc.wantVal)
+		}
+	}
+}
+
+func TestParseFilterValueIn(t *testing.T) {
+	got, err := parseFilterValue("in.(active,pending)")
+	if err != nil {
+		t.Fatalf("parseFilterValue failed: %v", err)
+	}
+	values, ok := got["_in"].([]interface{})
+	if !ok || len(values) != 2 || values[0] != "active" || values[1] != "pending" {
+		t.Fatalf("expected _in [active pending], got %v", got)
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	clauses, err := parseOrder("created_at.desc,name.asc,age")
+	if err != nil {
+		t.Fatalf("parseOrder failed: %v", err)
+	}
+	want := []orderClause{
+		{Column: "created_at", Desc: true},
+		{Column: "name", Desc: false},
+		{Column: "age", Desc: false},
+	}
+	if len(clauses) != len(want) {
+		t.Fatalf("expected %d clauses, got %d", len(want), len(clauses))
+	}
+	for i, c := range clauses {
+		if c != want[i] {
+			t.Errorf("clause %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+}
+
+func TestParseOrderRejectsUnknownDirection(t *testing.T) {
+	if _, err := parseOrder("name.sideways"); err == nil {
+		t.Error("expected an error for an unrecognized sort direction")
+	}
+}
+
+func TestParseSelectSplitsColumnsAndEmbeds(t *testing.T) {
+	cols, embeds, err := parseSelect("id,name,author(id,name),posts(title)")
+	if err != nil {
+		t.Fatalf("parseSelect failed: %v", err)
+	}
+	if len(cols) != 2 || cols[0] != "id" || cols[1] != "name" {
+		t.Fatalf("expected columns [id name], got %v", cols)
+	}
+	if len(embeds) != 2 {
+		t.Fatalf("expected 2 embeds, got %d", len(embeds))
+	}
+	if embeds[0].name != "author" || len(embeds[0].columns) != 2 {
+		t.Errorf("expected author(id,name), got %+v", embeds[0])
+	}
+	if embeds[1].name != "posts" || embeds[1].columns[0] != "title" {
+		t.Errorf("expected posts(title), got %+v", embeds[1])
+	}
+}
+
+func TestProjectColumnsDropsUnselectedFields(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"id": "1", "name": "a", "secret": "x"},
+	}
+	projected := projectColumns(docs, []string{"id", "name"})
+	if len(projected[0]) != 2 || projected[0]["id"] != "1" || projected[0]["name"] != "a" {
+		t.Errorf("expected only id/name to survive projection, got %v", projected[0])
+	}
+	if _, present := projected[0]["secret"]; present {
+		t.Error("expected unselected column 'secret' to be dropped")
+	}
+}
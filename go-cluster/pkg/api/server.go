@@ -2,37 +2,118 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/tdb-plus/cluster/pkg/api/rest"
+	"github.com/tdb-plus/cluster/pkg/grpcapi"
+	"github.com/tdb-plus/cluster/pkg/nats"
+	"github.com/tdb-plus/cluster/pkg/platform/graphql"
+	"github.com/tdb-plus/cluster/pkg/query"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/core"
+	"github.com/tdb-plus/cluster/pkg/metrics"
+	"github.com/tdb-plus/cluster/pkg/pb/tdbplusv1"
 	"github.com/tdb-plus/cluster/pkg/router"
+	"github.com/vmihailenco/msgpack/v5"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
 
+// msgpackContentType is both what the msgpack read/write paths below set
+// as Content-Type and one of the two values they recognize on Accept/
+// Content-Type - the other, application/msgpack, is what most off-the-
+// shelf msgpack clients send by default.
+const msgpackContentType = "application/vnd.tdb+msgpack"
+
+// acceptsMsgpack reports whether c asked for a msgpack response via its
+// Accept header.
+func acceptsMsgpack(c *gin.Context) bool {
+	return isMsgpackContentType(c.GetHeader("Accept"))
+}
+
+func isMsgpackContentType(v string) bool {
+	return strings.Contains(v, "application/msgpack") || strings.Contains(v, msgpackContentType)
+}
+
 // Server is the HTTP API server
 type Server struct {
 	node   *cluster.Node
 	router *router.Router
 	logger *zap.Logger
 	engine *gin.Engine
+
+	// DynamoDB-compatible endpoint (see dynamodb.go). The static
+	// credentials come from node's config so a real AWS SDK can be
+	// pointed at this server with NewStaticCredentialsProvider.
+	dynamoAccessKeyID     string
+	dynamoSecretAccessKey string
+	dynamoTables          *dynamoTables
+
+	metrics *metrics.Metrics
+
+	// restMetadata and jetstream back the generated REST layer (see
+	// pkg/api/rest.Generator): restMetadata tracks which tables get
+	// auto-generated CRUD routes, and jetstream, built embedded (no
+	// external NATS connection needed - see nats.NewJetStreamEngine),
+	// lets the generator also bridge stream/consumer/KV routes.
+	restMetadata *graphql.MetadataStore
+	jetstream    *nats.JetStreamEngine
+}
+
+// ServerOption configures optional NewServer behavior, following the
+// same pattern as platform.Option.
+type ServerOption func(*Server)
+
+// WithMetricsConfig wires cfg's collectors into s: core.Database op
+// histograms/counters, the /metrics HTTP latency middleware, and Raft
+// apply latency around the insert/update/delete handlers. Without this
+// option, NewServer uses metrics.New(metrics.Config{}) (the default
+// namespace, against the default Prometheus registry).
+func WithMetricsConfig(cfg metrics.Config) ServerOption {
+	return func(s *Server) {
+		s.metrics = metrics.New(cfg)
+	}
 }
 
 // NewServer creates a new API server
-func NewServer(node *cluster.Node, rtr *router.Router, logger *zap.Logger) *Server {
+func NewServer(node *cluster.Node, rtr *router.Router, logger *zap.Logger, opts ...ServerOption) *Server {
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 	engine.Use(gin.Recovery())
 
+	cfg := node.GetConfig()
 	s := &Server{
-		node:   node,
-		router: rtr,
-		logger: logger,
-		engine: engine,
+		node:                  node,
+		router:                rtr,
+		logger:                logger,
+		engine:                engine,
+		dynamoAccessKeyID:     cfg.DynamoDBAccessKeyID,
+		dynamoSecretAccessKey: cfg.DynamoDBSecretAccessKey,
+		dynamoTables:          newDynamoTables(),
+		metrics:               metrics.New(metrics.Config{}),
+		restMetadata:          graphql.NewMetadataStore(node),
+		jetstream:             nats.NewJetStreamEngine(nil, cfg.DataDir),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	core.SetMetrics(s.metrics.Core)
+	engine.Use(s.metrics.HTTP.GinMiddleware())
+
+	if err := s.restMetadata.Load(); err != nil {
+		s.logger.Error("Failed to load REST generator metadata", zap.Error(err))
+	}
+	rest.NewGenerator(s.engine, s.restMetadata, s.node, s.jetstream).RegisterRoutes()
 
 	s.setupRoutes()
 	return s
@@ -46,11 +127,18 @@ func (s *Server) setupRoutes() {
 	s.engine.GET("/cluster", s.handleClusterInfo)
 	s.engine.GET("/cluster/topology", s.handleTopology)
 
+	// Cluster membership
+	s.engine.GET("/cluster/members", s.handleClusterMembers)
+	s.engine.POST("/cluster/join", s.handleClusterJoin)
+	s.engine.POST("/cluster/leave", s.handleClusterLeave)
+
 	// Query API (stateless operations)
 	api := s.engine.Group("/api/v1")
 	{
 		// Document operations
 		api.POST("/query", s.handleQuery)
+		api.POST("/query/ast", s.handleQueryAST)
+		api.POST("/query/stream", s.handleQueryStream)
 		api.GET("/collections/:collection/:id", s.handleGet)
 		api.POST("/collections/:collection", s.handleInsert)
 		api.PUT("/collections/:collection/:id", s.handleUpdate)
@@ -64,6 +152,11 @@ func (s *Server) setupRoutes() {
 		api.POST("/collections/:collection/indexes", s.handleCreateIndex)
 	}
 
+	// DynamoDB-compatible endpoint (see dynamodb.go). Operation dispatch
+	// happens on the X-Amz-Target header, not the path, matching the real
+	// DynamoDB wire protocol.
+	s.engine.POST("/dynamodb", s.handleDynamoDB)
+
 	// Metrics
 	s.engine.GET("/metrics", s.handleMetrics)
 }
@@ -94,6 +187,40 @@ func (s *Server) handleTopology(c *gin.Context) {
 	c.JSON(http.StatusOK, s.router.GetClusterTopology())
 }
 
+func (s *Server) handleClusterMembers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"members": s.node.Members()})
+}
+
+func (s *Server) handleClusterJoin(c *gin.Context) {
+	var req JoinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": err.Error()})
+		return
+	}
+
+	if err := s.node.AddMember(req.NodeID, req.RaftAddr); err != nil {
+		c.JSON(http.StatusOK, gin.H{"ok": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (s *Server) handleClusterLeave(c *gin.Context) {
+	var req LeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": err.Error()})
+		return
+	}
+
+	if err := s.node.RemoveMember(req.NodeID); err != nil {
+		c.JSON(http.StatusOK, gin.H{"ok": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
 func (s *Server) handleQuery(c *gin.Context) {
 	var req QueryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -109,20 +236,140 @@ func (s *Server) handleQuery(c *gin.Context) {
 	}
 
 	// If local, execute; otherwise forward
-	if target == "localhost" || s.node.IsLeader() {
-		// Execute locally
-		// TODO: Integrate with Rust storage engine
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "ok",
-			"documents": []interface{}{},
-			"count":     0,
-		})
-	} else {
+	if target != "localhost" && !s.node.IsLeader() {
 		// Forward to leader
 		c.JSON(http.StatusTemporaryRedirect, gin.H{
 			"redirect": target,
 		})
+		return
+	}
+
+	if acceptsNDJSON(c) {
+		s.streamQueryNDJSON(c, req)
+		return
+	}
+
+	// Execute locally
+	// TODO: Integrate with Rust storage engine
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "ok",
+		"documents": []interface{}{},
+		"count":     0,
+	})
+}
+
+// acceptsNDJSON reports whether c asked for newline-delimited JSON
+// streaming via its Accept header, the trigger handleQuery uses to switch
+// from a buffered response to streamQueryNDJSON.
+func acceptsNDJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "application/x-ndjson")
+}
+
+// handleQueryStream is an explicit streaming counterpart to handleQuery's
+// application/x-ndjson branch, for clients that want a chunked response
+// without having to set an Accept header to get it.
+func (s *Server) handleQueryStream(c *gin.Context) {
+	var req QueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+	s.streamQueryNDJSON(c, req)
+}
+
+// streamQueryNDJSON runs req through a core.Cursor and writes one JSON
+// document per line as each batch arrives from the Rust core, flushing
+// after each batch so a downstream consumer can start processing before
+// the query completes - the shape large result sets need to avoid
+// buffering the whole response in memory on either side.
+func (s *Server) streamQueryNDJSON(c *gin.Context, req QueryRequest) {
+	var parsedQuery interface{}
+	if req.Query != "" {
+		if err := json.Unmarshal([]byte(req.Query), &parsedQuery); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "query must be a JSON filter object to stream: " + err.Error()})
+			return
+		}
+	}
+
+	cursor, err := s.node.GetDatabase().QueryCursor(req.Collection, parsedQuery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer cursor.Close()
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	flusher, _ := c.Writer.(http.Flusher)
+
+	enc := json.NewEncoder(c.Writer)
+	ctx := c.Request.Context()
+	for cursor.Next(ctx) {
+		var doc map[string]interface{}
+		if err := cursor.Scan(&doc); err != nil {
+			s.logger.Error("query stream: scan failed", zap.Error(err))
+			break
+		}
+		if err := enc.Encode(doc); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		s.logger.Error("query stream: cursor failed", zap.Error(err))
+	}
+}
+
+// handleQueryAST accepts a gob-encoded query.Statement directly, so a
+// remote node's AST never has to be reconstructed into SQL text and
+// re-parsed (see APIClusterClient.ExecuteRemote). It always advertises
+// support via astCapabilityHeader so callers can stop probing once
+// they've seen it once.
+func (s *Server) handleQueryAST(c *gin.Context) {
+	c.Header(astCapabilityHeader, astCapabilityValue)
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var stmt query.Statement
+	if err := stmt.UnmarshalBinary(body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !s.node.IsLeader() && stmt.Insert != nil {
+		c.JSON(http.StatusTemporaryRedirect, gin.H{
+			"redirect": s.node.LeaderAddr(),
+		})
+		return
+	}
+
+	if stmt.Insert != nil {
+		docBytes, _ := json.Marshal(stmt.Insert)
+		cmd := &cluster.Command{
+			Op:         "set",
+			Collection: stmt.Insert.Collection,
+			Value:      docBytes,
+		}
+		if err := s.applyCommand(cmd); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"status": "created"})
+		return
+	}
+
+	// TODO: Integrate with Rust storage engine
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "ok",
+		"documents": []interface{}{},
+		"count":     0,
+	})
 }
 
 func (s *Server) handleGet(c *gin.Context) {
@@ -136,18 +383,32 @@ func (s *Server) handleGet(c *gin.Context) {
 		return
 	}
 
-	// TODO: Integrate with Rust storage engine
-	c.JSON(http.StatusOK, gin.H{
-		"_id":        id,
-		"collection": collection,
-	})
+	// A client that sent Accept: application/msgpack gets the document
+	// straight off GetMP's msgpack buffer - no JSON decode on the Rust
+	// side and no JSON encode on ours.
+	if acceptsMsgpack(c) {
+		data, err := s.node.GetDatabase().GetMP(collection, id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, msgpackContentType, data)
+		return
+	}
+
+	doc, err := s.node.GetDocumentContext(c.Request.Context(), collection, id, cluster.Stale)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, doc)
 }
 
 func (s *Server) handleInsert(c *gin.Context) {
 	collection := c.Param("collection")
 
-	var doc map[string]interface{}
-	if err := c.ShouldBindJSON(&doc); err != nil {
+	doc, err := decodeDocument(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -163,23 +424,57 @@ func (s *Server) handleInsert(c *gin.Context) {
 	// Apply via Raft
 	docBytes, _ := json.Marshal(doc)
 	cmd := &cluster.Command{
-		Op:         "set",
-		Collection: collection,
-		Key:        doc["_id"].(string),
-		Value:      docBytes,
+		Op:             "set",
+		Collection:     collection,
+		Key:            doc["_id"].(string),
+		Value:          docBytes,
+		IdempotencyKey: idempotencyKey(c, docBytes),
 	}
 
-	if err := s.node.Apply(cmd, 5*time.Second); err != nil {
+	if err := s.applyCommand(cmd); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if acceptsMsgpack(c) {
+		data, err := msgpack.Marshal(gin.H{"status": "created", "_id": doc["_id"]})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusCreated, msgpackContentType, data)
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"status": "created",
 		"_id":    doc["_id"],
 	})
 }
 
+// decodeDocument reads c's request body as msgpack when its Content-Type
+// says so (application/msgpack or application/vnd.tdb+msgpack - what a
+// msgpack client sends for POST /api/v1/collections/:collection),
+// falling back to JSON otherwise.
+func decodeDocument(c *gin.Context) (map[string]interface{}, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{})
+	if isMsgpackContentType(c.GetHeader("Content-Type")) {
+		if err := msgpack.Unmarshal(body, &doc); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
 func (s *Server) handleUpdate(c *gin.Context) {
 	collection := c.Param("collection")
 	id := c.Param("id")
@@ -200,13 +495,14 @@ func (s *Server) handleUpdate(c *gin.Context) {
 
 	docBytes, _ := json.Marshal(doc)
 	cmd := &cluster.Command{
-		Op:         "set",
-		Collection: collection,
-		Key:        id,
-		Value:      docBytes,
+		Op:             "set",
+		Collection:     collection,
+		Key:            id,
+		Value:          docBytes,
+		IdempotencyKey: idempotencyKey(c, docBytes),
 	}
 
-	if err := s.node.Apply(cmd, 5*time.Second); err != nil {
+	if err := s.applyCommand(cmd); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -229,12 +525,13 @@ func (s *Server) handleDelete(c *gin.Context) {
 	}
 
 	cmd := &cluster.Command{
-		Op:         "delete",
-		Collection: collection,
-		Key:        id,
+		Op:             "delete",
+		Collection:     collection,
+		Key:            id,
+		IdempotencyKey: idempotencyKey(c, nil),
 	}
 
-	if err := s.node.Apply(cmd, 5*time.Second); err != nil {
+	if err := s.applyCommand(cmd); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -245,6 +542,34 @@ func (s *Server) handleDelete(c *gin.Context) {
 	})
 }
 
+// applyCommand runs cmd through Raft, observing its latency against
+// tdb_raft_apply_duration_seconds{op=cmd.Op}.
+func (s *Server) applyCommand(cmd *cluster.Command) error {
+	start := time.Now()
+	err := s.node.Apply(cmd, 5*time.Second)
+	s.metrics.Raft.ObserveApply(cmd.Op, time.Since(start), err)
+	return err
+}
+
+// idempotencyKey hashes the client-supplied Idempotency-Key header
+// together with the request method, path, and body, so the FSM can
+// recognize a retried write (e.g. after a leader-redirect + connection
+// reset) and replay its original outcome instead of applying it twice -
+// see cluster.Command.IdempotencyKey. Returns "" when the client didn't
+// send the header, which skips de-duplication for that request.
+func idempotencyKey(c *gin.Context, body []byte) string {
+	header := c.GetHeader("Idempotency-Key")
+	if header == "" {
+		return ""
+	}
+	h := sha256.New()
+	h.Write([]byte(header))
+	h.Write([]byte(c.Request.Method))
+	h.Write([]byte(c.Request.URL.Path))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func (s *Server) handleBatch(c *gin.Context) {
 	var req BatchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -252,9 +577,37 @@ func (s *Server) handleBatch(c *gin.Context) {
 		return
 	}
 
-	// Process batch operations
+	if !s.node.IsLeader() {
+		c.JSON(http.StatusTemporaryRedirect, gin.H{
+			"redirect": s.node.LeaderAddr(),
+		})
+		return
+	}
+
+	// Each op carries its own IdempotencyKey, so retrying a batch that
+	// partially committed (e.g. after a leader-redirect + connection
+	// reset) only re-executes the ops whose keys weren't already applied.
 	results := make([]map[string]interface{}, 0, len(req.Operations))
 	for _, op := range req.Operations {
+		cmd, err := op.toCommand()
+		if err != nil {
+			results = append(results, map[string]interface{}{
+				"op":     op.Op,
+				"status": "error",
+				"error":  err.Error(),
+			})
+			continue
+		}
+
+		if err := s.applyCommand(cmd); err != nil {
+			results = append(results, map[string]interface{}{
+				"op":     op.Op,
+				"status": "error",
+				"error":  err.Error(),
+			})
+			continue
+		}
+
 		results = append(results, map[string]interface{}{
 			"op":     op.Op,
 			"status": "ok",
@@ -289,8 +642,7 @@ func (s *Server) handleCreateIndex(c *gin.Context) {
 }
 
 func (s *Server) handleMetrics(c *gin.Context) {
-	// TODO: Prometheus metrics
-	c.String(http.StatusOK, "# TDB+ Metrics\n")
+	gin.WrapH(promhttp.Handler())(c)
 }
 
 // Request/Response types
@@ -309,6 +661,55 @@ type BatchOperation struct {
 	Collection string                 `json:"collection"`
 	Document   map[string]interface{} `json:"document,omitempty"`
 	ID         string                 `json:"id,omitempty"`
+
+	// IdempotencyKey, when set, is forwarded onto the cluster.Command
+	// this op produces, so FSM.Apply can recognize a retried batch and
+	// only re-execute the ops it hasn't already committed. Unlike the
+	// single-op handlers, this isn't hashed from a header - the client
+	// supplies one per op directly.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// toCommand translates op into the cluster.Command Raft applies for it.
+func (op BatchOperation) toCommand() (*cluster.Command, error) {
+	cmd := &cluster.Command{
+		Collection:     op.Collection,
+		Key:            op.ID,
+		IdempotencyKey: op.IdempotencyKey,
+	}
+
+	switch op.Op {
+	case "insert", "update":
+		if op.Document == nil {
+			return nil, fmt.Errorf("batch %s op missing document", op.Op)
+		}
+		docBytes, err := json.Marshal(op.Document)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Op = "set"
+		cmd.Value = docBytes
+		if cmd.Key == "" {
+			if id, ok := op.Document["_id"].(string); ok {
+				cmd.Key = id
+			}
+		}
+	case "delete":
+		cmd.Op = "delete"
+	default:
+		return nil, fmt.Errorf("unsupported batch op %q", op.Op)
+	}
+
+	return cmd, nil
+}
+
+type JoinRequest struct {
+	NodeID   string `json:"node_id"`
+	RaftAddr string `json:"raft_addr"`
+}
+
+type LeaveRequest struct {
+	NodeID string `json:"node_id"`
 }
 
 type CreateIndexRequest struct {
@@ -318,9 +719,10 @@ type CreateIndexRequest struct {
 	Unique bool     `json:"unique"`
 }
 
-// NewGRPCServer creates a new gRPC server
+// NewGRPCServer creates a new gRPC server and registers ClusterService,
+// the binary/streaming counterpart to the HTTP API above.
 func NewGRPCServer(node *cluster.Node, rtr *router.Router, logger *zap.Logger) *grpc.Server {
 	server := grpc.NewServer()
-	// TODO: Register gRPC services
+	tdbplusv1.RegisterClusterServiceServer(server, grpcapi.NewServer(node, rtr, logger))
 	return server
 }
@@ -0,0 +1,141 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// reservoirSize bounds how many recently-written keys RecordWrite samples
+// per region to pick a split point from - large enough to approximate the
+// region's key distribution without holding every written key in memory.
+const reservoirSize = 256
+
+// regionStats tracks the rolling size/write-rate heuristics
+// RunAutoSplitter uses to decide a region has outgrown its shard, plus a
+// reservoir sample of recently-written keys to pick a split point from
+// without a full region scan.
+type regionStats struct {
+	mu        sync.Mutex
+	sizeBytes int64
+	writes    []time.Time // write timestamps within the last minute
+	reservoir [][]byte
+	seen      int64 // total keys ever offered to the reservoir
+}
+
+// RecordWrite updates regionID's size/write-rate heuristics and offers key
+// to its reservoir sample. Callers that route writes through
+// GetRegionByKey should call this afterward so RunAutoSplitter has real
+// data to act on; a region never written through RecordWrite simply never
+// triggers an auto-split.
+func (rm *RegionManager) RecordWrite(regionID uint64, key []byte, valueSize int) {
+	rm.mu.Lock()
+	st, ok := rm.stats[regionID]
+	if !ok {
+		st = &regionStats{}
+		rm.stats[regionID] = st
+	}
+	rm.mu.Unlock()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.sizeBytes += int64(len(key)) + int64(valueSize)
+	st.writes = append(st.writes, time.Now())
+	st.seen++
+	switch {
+	case len(st.reservoir) < reservoirSize:
+		st.reservoir = append(st.reservoir, append([]byte{}, key...))
+	default:
+		if idx := rand.Int63n(st.seen); idx < int64(reservoirSize) {
+			st.reservoir[idx] = append([]byte{}, key...)
+		}
+	}
+}
+
+// writesPerMinuteLocked prunes write timestamps older than a minute and
+// returns the remaining count. Callers must hold st.mu.
+func writesPerMinuteLocked(st *regionStats) int64 {
+	cutoff := time.Now().Add(-time.Minute)
+	kept := st.writes[:0]
+	for _, t := range st.writes {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	st.writes = kept
+	return int64(len(kept))
+}
+
+// sampledMidKey picks a split point from st's reservoir sample: the
+// lexicographic median of the sampled keys, rather than scanning the
+// whole region for its true median.
+func sampledMidKey(st *regionStats) []byte {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if len(st.reservoir) == 0 {
+		return nil
+	}
+	sorted := make([][]byte, len(st.reservoir))
+	copy(sorted, st.reservoir)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	return sorted[len(sorted)/2]
+}
+
+// RunAutoSplitter periodically checks every region RecordWrite has seen
+// against MaxRegionSizeBytes/MaxWritesPerMinute, splitting any that
+// exceed either threshold at that region's sampled mid-key. It blocks
+// until ctx is done, so callers should run it in its own goroutine.
+func (rm *RegionManager) RunAutoSplitter(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rm.checkAndSplit()
+		}
+	}
+}
+
+func (rm *RegionManager) checkAndSplit() {
+	rm.mu.RLock()
+	candidates := make(map[uint64]*regionStats, len(rm.stats))
+	for id, st := range rm.stats {
+		if _, ok := rm.regions[id]; ok {
+			candidates[id] = st
+		}
+	}
+	rm.mu.RUnlock()
+
+	for id, st := range candidates {
+		st.mu.Lock()
+		size := st.sizeBytes
+		rate := writesPerMinuteLocked(st)
+		st.mu.Unlock()
+
+		if size < rm.MaxRegionSizeBytes && rate < rm.MaxWritesPerMinute {
+			continue
+		}
+
+		key := sampledMidKey(st)
+		if key == nil {
+			continue
+		}
+		if _, _, err := rm.SplitRegion(id, key); err != nil {
+			continue
+		}
+
+		st.mu.Lock()
+		st.sizeBytes = 0
+		st.reservoir = nil
+		st.seen = 0
+		st.mu.Unlock()
+	}
+}
@@ -0,0 +1,596 @@
+package cluster
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	badger "github.com/dgraph-io/badger/v4"
+)
+
+// Badger key layout:
+//
+//	log/<index>   -> json-encoded Entry, big-endian index for Badger's
+//	                 lexicographic iteration to match log order
+//	hardstate     -> json-encoded RaftState
+//	snap/latest   -> json-encoded badgerSnapshotRecord
+//	compact       -> json-encoded badgerCompactRecord
+//	g/<id>/...    -> the same four keys above, scoped to Raft group <id>,
+//	                 written only by SaveBatch
+var (
+	keyHardState   = []byte("hardstate")
+	keySnapshot    = []byte("snap/latest")
+	keyCompact     = []byte("compact")
+	logKeyPrefix   = []byte("log/")
+	metaKeyPrefix  = []byte("meta/")
+	groupKeyPrefix = []byte("g/")
+)
+
+func metaKey(key string) []byte {
+	return append(append([]byte{}, metaKeyPrefix...), key...)
+}
+
+func logKey(index uint64) []byte {
+	key := make([]byte, len(logKeyPrefix)+8)
+	copy(key, logKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(logKeyPrefix):], index)
+	return key
+}
+
+// groupKeyPrefixFor returns the key prefix ("g/<id>/") every key scoped
+// to Raft group id lives under.
+func groupKeyPrefixFor(id uint64) []byte {
+	prefix := make([]byte, len(groupKeyPrefix)+8)
+	copy(prefix, groupKeyPrefix)
+	binary.BigEndian.PutUint64(prefix[len(groupKeyPrefix):], id)
+	return append(prefix, '/')
+}
+
+func groupHardStateKey(id uint64) []byte { return append(groupKeyPrefixFor(id), keyHardState...) }
+func groupSnapshotKey(id uint64) []byte  { return append(groupKeyPrefixFor(id), keySnapshot...) }
+func groupCompactKey(id uint64) []byte   { return append(groupKeyPrefixFor(id), keyCompact...) }
+
+func groupLogKey(id uint64, index uint64) []byte {
+	prefix := groupKeyPrefixFor(id)
+	key := make([]byte, len(prefix)+len(logKeyPrefix)+8)
+	n := copy(key, prefix)
+	n += copy(key[n:], logKeyPrefix)
+	binary.BigEndian.PutUint64(key[n:], index)
+	return key
+}
+
+// badgerCompactRecord tracks the index/term of the entry immediately
+// before the oldest entry still retained in the log, so Term() can still
+// resolve it per the StorageEngine contract (see MemoryStorage).
+type badgerCompactRecord struct {
+	Index uint64
+	Term  uint64
+}
+
+// BadgerStorage is a BadgerDB-backed StorageEngine: it persists the Raft
+// log, HardState, and the latest snapshot under a single Badger instance.
+// Every Save is a single Badger transaction, so a crash mid-Save leaves
+// either the old state or the new one, never a torn log.
+type BadgerStorage struct {
+	db *badger.DB
+}
+
+// NewBadgerStorage opens (or creates) a Badger store at dir.
+func NewBadgerStorage(dir string) (*BadgerStorage, error) {
+	opts := badger.DefaultOptions(dir).WithLogger(nil)
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to open badger at %s: %w", dir, err)
+	}
+	return &BadgerStorage{db: db}, nil
+}
+
+// Close releases the underlying Badger instance.
+func (s *BadgerStorage) Close() error {
+	return s.db.Close()
+}
+
+func (s *BadgerStorage) compact(txn *badger.Txn) (badgerCompactRecord, error) {
+	item, err := txn.Get(keyCompact)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return badgerCompactRecord{}, nil
+	}
+	if err != nil {
+		return badgerCompactRecord{}, err
+	}
+	var rec badgerCompactRecord
+	err = item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &rec)
+	})
+	return rec, err
+}
+
+func (s *BadgerStorage) lastIndex(txn *badger.Txn, compactIndex uint64) (uint64, error) {
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = true
+	opts.PrefetchValues = false
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	// Seek to one past the highest possible log key, then step backward.
+	seekKey := logKey(^uint64(0))
+	it.Seek(seekKey)
+	if !it.ValidForPrefix(logKeyPrefix) {
+		return compactIndex, nil
+	}
+	key := it.Item().Key()
+	return binary.BigEndian.Uint64(key[len(logKeyPrefix):]), nil
+}
+
+// InitialState returns the last saved HardState.
+func (s *BadgerStorage) InitialState() (RaftState, error) {
+	var st RaftState
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(keyHardState)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &st)
+		})
+	})
+	return st, err
+}
+
+// FirstIndex returns the index of the oldest entry still available via
+// Entries/Term, i.e. one past the last compacted index.
+func (s *BadgerStorage) FirstIndex() (uint64, error) {
+	var first uint64
+	err := s.db.View(func(txn *badger.Txn) error {
+		rec, err := s.compact(txn)
+		if err != nil {
+			return err
+		}
+		first = rec.Index + 1
+		return nil
+	})
+	return first, err
+}
+
+// LastIndex returns the index of the most recently saved entry.
+func (s *BadgerStorage) LastIndex() (uint64, error) {
+	var last uint64
+	err := s.db.View(func(txn *badger.Txn) error {
+		rec, err := s.compact(txn)
+		if err != nil {
+			return err
+		}
+		last, err = s.lastIndex(txn, rec.Index)
+		return err
+	})
+	return last, err
+}
+
+// Term returns the term of the entry at index i.
+func (s *BadgerStorage) Term(i uint64) (uint64, error) {
+	var term uint64
+	err := s.db.View(func(txn *badger.Txn) error {
+		rec, err := s.compact(txn)
+		if err != nil {
+			return err
+		}
+		if i == rec.Index {
+			term = rec.Term
+			return nil
+		}
+		if i < rec.Index {
+			return ErrCompacted
+		}
+		last, err := s.lastIndex(txn, rec.Index)
+		if err != nil {
+			return err
+		}
+		if i > last {
+			return ErrUnavailable
+		}
+
+		item, err := txn.Get(logKey(i))
+		if err != nil {
+			return err
+		}
+		var e Entry
+		if err := item.Value(func(val []byte) error { return json.Unmarshal(val, &e) }); err != nil {
+			return err
+		}
+		term = e.Term
+		return nil
+	})
+	return term, err
+}
+
+// Entries returns the entries in [lo, hi), trimmed to maxSize bytes of
+// Data (always returning at least one entry).
+func (s *BadgerStorage) Entries(lo, hi, maxSize uint64) ([]Entry, error) {
+	var out []Entry
+	err := s.db.View(func(txn *badger.Txn) error {
+		rec, err := s.compact(txn)
+		if err != nil {
+			return err
+		}
+		if lo <= rec.Index {
+			return ErrCompacted
+		}
+		last, err := s.lastIndex(txn, rec.Index)
+		if err != nil {
+			return err
+		}
+		if hi > last+1 {
+			return ErrUnavailable
+		}
+
+		opts := badger.DefaultIteratorOptions
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		var size uint64
+		for it.Seek(logKey(lo)); it.ValidForPrefix(logKeyPrefix); it.Next() {
+			key := it.Item().Key()
+			index := binary.BigEndian.Uint64(key[len(logKeyPrefix):])
+			if index >= hi {
+				break
+			}
+			var e Entry
+			if err := it.Item().Value(func(val []byte) error { return json.Unmarshal(val, &e) }); err != nil {
+				return err
+			}
+			if len(out) > 0 && size+uint64(len(e.Data)) > maxSize {
+				break
+			}
+			out = append(out, e)
+			size += uint64(len(e.Data))
+		}
+		return nil
+	})
+	return out, err
+}
+
+// Snapshot returns the most recently applied snapshot.
+func (s *BadgerStorage) Snapshot() (Snapshot, error) {
+	var snap Snapshot
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(keySnapshot)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &snap)
+		})
+	})
+	return snap, err
+}
+
+// Save persists st, appends/truncates the log to ents, and - if snap
+// carries a newer snapshot than the one already stored - applies it, all
+// within a single Badger transaction.
+func (s *BadgerStorage) Save(st RaftState, ents []Entry, snap Snapshot) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		rec, err := s.compact(txn)
+		if err != nil {
+			return err
+		}
+
+		if snap.Metadata.Index != 0 {
+			existing, err := func() (Snapshot, error) {
+				var cur Snapshot
+				item, err := txn.Get(keySnapshot)
+				if errors.Is(err, badger.ErrKeyNotFound) {
+					return cur, nil
+				}
+				if err != nil {
+					return cur, err
+				}
+				return cur, item.Value(func(val []byte) error { return json.Unmarshal(val, &cur) })
+			}()
+			if err != nil {
+				return err
+			}
+			if snap.Metadata.Index <= existing.Metadata.Index {
+				return ErrSnapOutOfDate
+			}
+
+			snapBytes, err := json.Marshal(snap)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set(keySnapshot, snapBytes); err != nil {
+				return err
+			}
+
+			if err := deleteLogRange(txn, rec.Index+1, snap.Metadata.Index+1); err != nil {
+				return err
+			}
+			rec = badgerCompactRecord{Index: snap.Metadata.Index, Term: snap.Metadata.Term}
+		}
+
+		stBytes, err := json.Marshal(st)
+		if err != nil {
+			return err
+		}
+		if err := txn.Set(keyHardState, stBytes); err != nil {
+			return err
+		}
+
+		if len(ents) > 0 {
+			last, err := s.lastIndex(txn, rec.Index)
+			if err != nil {
+				return err
+			}
+			if err := deleteLogRange(txn, ents[0].Index, last+1); err != nil {
+				return err
+			}
+			for _, e := range ents {
+				if e.Index <= rec.Index {
+					continue
+				}
+				entBytes, err := json.Marshal(e)
+				if err != nil {
+					return err
+				}
+				if err := txn.Set(logKey(e.Index), entBytes); err != nil {
+					return err
+				}
+			}
+		}
+
+		recBytes, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		return txn.Set(keyCompact, recBytes)
+	})
+}
+
+func (s *BadgerStorage) groupCompact(txn *badger.Txn, id uint64) (badgerCompactRecord, error) {
+	item, err := txn.Get(groupCompactKey(id))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return badgerCompactRecord{}, nil
+	}
+	if err != nil {
+		return badgerCompactRecord{}, err
+	}
+	var rec badgerCompactRecord
+	err = item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &rec)
+	})
+	return rec, err
+}
+
+func (s *BadgerStorage) groupLastIndex(txn *badger.Txn, id uint64, compactIndex uint64) (uint64, error) {
+	logPrefix := append(append([]byte{}, groupKeyPrefixFor(id)...), logKeyPrefix...)
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = true
+	opts.PrefetchValues = false
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	it.Seek(groupLogKey(id, ^uint64(0)))
+	if !it.ValidForPrefix(logPrefix) {
+		return compactIndex, nil
+	}
+	key := it.Item().Key()
+	return binary.BigEndian.Uint64(key[len(logPrefix):]), nil
+}
+
+// deleteGroupLogRange deletes group id's log entries in [lo, hi).
+func deleteGroupLogRange(txn *badger.Txn, id uint64, lo, hi uint64) error {
+	if lo >= hi {
+		return nil
+	}
+	logPrefix := append(append([]byte{}, groupKeyPrefixFor(id)...), logKeyPrefix...)
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	it := txn.NewIterator(opts)
+
+	var keys [][]byte
+	for it.Seek(groupLogKey(id, lo)); it.ValidForPrefix(logPrefix); it.Next() {
+		key := it.Item().Key()
+		index := binary.BigEndian.Uint64(key[len(logPrefix):])
+		if index >= hi {
+			break
+		}
+		keys = append(keys, it.Item().KeyCopy(nil))
+	}
+	it.Close()
+
+	for _, k := range keys {
+		if err := txn.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveBatch persists updates from multiple Raft groups (see
+// ParallelRaftEngine) within a single Badger transaction, so thousands of
+// groups coming Ready on the same tick cost one fsync rather than one
+// per group. Each update is otherwise equivalent to a Save call scoped
+// to its own group-prefixed keys (see groupKeyPrefixFor).
+func (s *BadgerStorage) SaveBatch(updates []GroupUpdate) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		for _, u := range updates {
+			rec, err := s.groupCompact(txn, u.GroupID)
+			if err != nil {
+				return err
+			}
+
+			if u.Snapshot.Metadata.Index != 0 {
+				existing, err := func() (Snapshot, error) {
+					var cur Snapshot
+					item, err := txn.Get(groupSnapshotKey(u.GroupID))
+					if errors.Is(err, badger.ErrKeyNotFound) {
+						return cur, nil
+					}
+					if err != nil {
+						return cur, err
+					}
+					return cur, item.Value(func(val []byte) error { return json.Unmarshal(val, &cur) })
+				}()
+				if err != nil {
+					return err
+				}
+				if u.Snapshot.Metadata.Index <= existing.Metadata.Index {
+					return ErrSnapOutOfDate
+				}
+
+				snapBytes, err := json.Marshal(u.Snapshot)
+				if err != nil {
+					return err
+				}
+				if err := txn.Set(groupSnapshotKey(u.GroupID), snapBytes); err != nil {
+					return err
+				}
+				if err := deleteGroupLogRange(txn, u.GroupID, rec.Index+1, u.Snapshot.Metadata.Index+1); err != nil {
+					return err
+				}
+				rec = badgerCompactRecord{Index: u.Snapshot.Metadata.Index, Term: u.Snapshot.Metadata.Term}
+			}
+
+			stBytes, err := json.Marshal(u.State)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set(groupHardStateKey(u.GroupID), stBytes); err != nil {
+				return err
+			}
+
+			if len(u.Entries) > 0 {
+				last, err := s.groupLastIndex(txn, u.GroupID, rec.Index)
+				if err != nil {
+					return err
+				}
+				if err := deleteGroupLogRange(txn, u.GroupID, u.Entries[0].Index, last+1); err != nil {
+					return err
+				}
+				for _, e := range u.Entries {
+					if e.Index <= rec.Index {
+						continue
+					}
+					entBytes, err := json.Marshal(e)
+					if err != nil {
+						return err
+					}
+					if err := txn.Set(groupLogKey(u.GroupID, e.Index), entBytes); err != nil {
+						return err
+					}
+				}
+			}
+
+			recBytes, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set(groupCompactKey(u.GroupID), recBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Compact discards all entries with Index < index, retaining index's own
+// term so Term(index) still resolves.
+func (s *BadgerStorage) Compact(index uint64) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		rec, err := s.compact(txn)
+		if err != nil {
+			return err
+		}
+		if index <= rec.Index {
+			return ErrCompacted
+		}
+		last, err := s.lastIndex(txn, rec.Index)
+		if err != nil {
+			return err
+		}
+		if index > last {
+			return ErrUnavailable
+		}
+
+		item, err := txn.Get(logKey(index))
+		if err != nil {
+			return err
+		}
+		var e Entry
+		if err := item.Value(func(val []byte) error { return json.Unmarshal(val, &e) }); err != nil {
+			return err
+		}
+
+		if err := deleteLogRange(txn, rec.Index+1, index); err != nil {
+			return err
+		}
+
+		recBytes, err := json.Marshal(badgerCompactRecord{Index: index, Term: e.Term})
+		if err != nil {
+			return err
+		}
+		return txn.Set(keyCompact, recBytes)
+	})
+}
+
+// LoadMeta returns the value saved under key by SaveMeta, or a nil value
+// and a nil error if key was never saved.
+func (s *BadgerStorage) LoadMeta(key string) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(metaKey(key))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			value = append([]byte{}, val...)
+			return nil
+		})
+	})
+	return value, err
+}
+
+// SaveMeta persists value under key, under its own "meta/" key prefix so
+// it can never collide with the log/hardstate/snapshot keys above.
+func (s *BadgerStorage) SaveMeta(key string, value []byte) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(metaKey(key), value)
+	})
+}
+
+// deleteLogRange deletes log entries in [lo, hi).
+func deleteLogRange(txn *badger.Txn, lo, hi uint64) error {
+	if lo >= hi {
+		return nil
+	}
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	it := txn.NewIterator(opts)
+
+	var keys [][]byte
+	for it.Seek(logKey(lo)); it.ValidForPrefix(logKeyPrefix); it.Next() {
+		key := it.Item().Key()
+		index := binary.BigEndian.Uint64(key[len(logKeyPrefix):])
+		if index >= hi {
+			break
+		}
+		keys = append(keys, it.Item().KeyCopy(nil))
+	}
+	it.Close()
+
+	for _, k := range keys {
+		if err := txn.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
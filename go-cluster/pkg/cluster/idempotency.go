@@ -0,0 +1,129 @@
+package cluster
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// defaultIdempotencyTTL and defaultIdempotencyMaxEntries back
+// idempotencyStore when FSM isn't given a more specific TTL/size (see
+// config.Config.IdempotencyTTLMs).
+const (
+	defaultIdempotencyTTL        = 10 * time.Minute
+	defaultIdempotencyMaxEntries = 100000
+)
+
+// idempotencyOutcome is what FSM.Apply recorded for a Command's
+// IdempotencyKey: whether it failed, and if so with what message. A
+// replayed failure is surfaced as a plain error built from ErrMsg rather
+// than the original error value, since errors don't survive a Raft log
+// round-trip.
+type idempotencyOutcome struct {
+	Failed bool
+	ErrMsg string
+}
+
+func (o idempotencyOutcome) err() error {
+	if !o.Failed {
+		return nil
+	}
+	return &idempotentReplayError{msg: o.ErrMsg}
+}
+
+// idempotentReplayError wraps the original failure message for a
+// command whose IdempotencyKey was replayed rather than re-applied.
+type idempotentReplayError struct{ msg string }
+
+func (e *idempotentReplayError) Error() string { return e.msg }
+
+type idempotencyListEntry struct {
+	key     string
+	outcome idempotencyOutcome
+	expires time.Time
+}
+
+// idempotencyStore is a bounded, TTL-expiring record of IdempotencyKey
+// outcomes that FSM.Apply consults before applying a Command, so a
+// client retrying a write it already committed (e.g. after a
+// leader-redirect + connection reset) gets the original outcome
+// replayed instead of the op running twice. Every replica builds its own
+// copy by replaying the same sequence of Raft log entries - unlike
+// db state, it isn't captured by Snapshot/Restore, so a node restored
+// from a snapshot starts with an empty store and can briefly re-apply a
+// command whose key expired from in-flight memory; that only matters
+// for the TTL window immediately after a restore.
+type idempotencyStore struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	max   int
+	order *list.List
+	elems map[string]*list.Element
+}
+
+func newIdempotencyStore(ttl time.Duration, max int) *idempotencyStore {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	if max <= 0 {
+		max = defaultIdempotencyMaxEntries
+	}
+	return &idempotencyStore{
+		ttl:   ttl,
+		max:   max,
+		order: list.New(),
+		elems: make(map[string]*list.Element),
+	}
+}
+
+// lookup returns the recorded outcome for key, if present and not yet
+// expired.
+func (s *idempotencyStore) lookup(key string) (idempotencyOutcome, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.elems[key]
+	if !ok {
+		return idempotencyOutcome{}, false
+	}
+	entry := el.Value.(*idempotencyListEntry)
+	if time.Now().After(entry.expires) {
+		s.removeLocked(key)
+		return idempotencyOutcome{}, false
+	}
+	s.order.MoveToFront(el)
+	return entry.outcome, true
+}
+
+// record stores outcome under key, refreshing its TTL and LRU position
+// if key was already present, and evicting the least-recently-used
+// entry once the store exceeds its configured size.
+func (s *idempotencyStore) record(key string, outcome idempotencyOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires := time.Now().Add(s.ttl)
+	if el, ok := s.elems[key]; ok {
+		entry := el.Value.(*idempotencyListEntry)
+		entry.outcome = outcome
+		entry.expires = expires
+		s.order.MoveToFront(el)
+		return
+	}
+
+	s.elems[key] = s.order.PushFront(&idempotencyListEntry{key: key, outcome: outcome, expires: expires})
+	for s.order.Len() > s.max {
+		back := s.order.Back()
+		if back == nil {
+			break
+		}
+		s.removeLocked(back.Value.(*idempotencyListEntry).key)
+	}
+}
+
+func (s *idempotencyStore) removeLocked(key string) {
+	if el, ok := s.elems[key]; ok {
+		s.order.Remove(el)
+		delete(s.elems, key)
+	}
+}
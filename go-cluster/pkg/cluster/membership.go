@@ -0,0 +1,201 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"go.uber.org/zap"
+)
+
+const (
+	joinHTTPTimeout = 5 * time.Second
+	joinRetryDelay  = 2 * time.Second
+	joinMaxRounds   = 5
+)
+
+// MemberInfo describes one node that is part of the cluster, as reported
+// by Members.
+type MemberInfo struct {
+	NodeID   string `json:"node_id"`
+	RaftAddr string `json:"raft_addr"`
+}
+
+// Members returns this node itself plus every peer it has admitted via
+// AddMember or been told about.
+func (n *Node) Members() []MemberInfo {
+	n.peersMu.RLock()
+	defer n.peersMu.RUnlock()
+
+	members := make([]MemberInfo, 0, len(n.peers)+1)
+	members = append(members, MemberInfo{NodeID: n.config.NodeID, RaftAddr: n.config.RaftAddr})
+	for id, addr := range n.peers {
+		members = append(members, MemberInfo{NodeID: id, RaftAddr: addr})
+	}
+	return members
+}
+
+// AddMember admits nodeID, reachable at raftAddr, as a voter of every
+// shard group this node currently leads, and records it in the peer
+// table. Shards this node doesn't lead are skipped; whichever node
+// leads them is responsible for admitting nodeID to those groups, the
+// same way their own AddMember call would.
+func (n *Node) AddMember(nodeID, raftAddr string) error {
+	host, portStr, err := net.SplitHostPort(raftAddr)
+	if err != nil {
+		return fmt.Errorf("invalid raft address %q: %w", raftAddr, err)
+	}
+	basePort, err := strconv.Atoi(portStr)
+	if err != nil {
+		return fmt.Errorf("invalid raft address %q: %w", raftAddr, err)
+	}
+
+	var joined int
+	for shardID := uint32(0); shardID < n.NumShards(); shardID++ {
+		addr := raft.ServerAddress(net.JoinHostPort(host, strconv.Itoa(shardPort(basePort, shardID))))
+		serverID := raft.ServerID(shardServerID(nodeID, shardID))
+		if err := n.shardRaft.AddVoter(shardID, serverID, addr); err != nil {
+			if _, ok := err.(*ErrNotLeader); ok {
+				continue
+			}
+			return fmt.Errorf("add voter for shard %d: %w", shardID, err)
+		}
+		joined++
+	}
+	if joined == 0 {
+		return fmt.Errorf("not leader of any shard this node hosts")
+	}
+
+	n.peersMu.Lock()
+	if n.peers == nil {
+		n.peers = make(map[string]string)
+	}
+	n.peers[nodeID] = raftAddr
+	n.peersMu.Unlock()
+
+	return nil
+}
+
+// RemoveMember removes nodeID as a voter from every shard group this
+// node currently leads and drops it from the peer table.
+func (n *Node) RemoveMember(nodeID string) error {
+	var removed int
+	for shardID := uint32(0); shardID < n.NumShards(); shardID++ {
+		serverID := raft.ServerID(shardServerID(nodeID, shardID))
+		if err := n.shardRaft.RemoveServer(shardID, serverID); err != nil {
+			if _, ok := err.(*ErrNotLeader); ok {
+				continue
+			}
+			return fmt.Errorf("remove voter for shard %d: %w", shardID, err)
+		}
+		removed++
+	}
+	if removed == 0 {
+		return fmt.Errorf("not leader of any shard this node hosts")
+	}
+
+	n.peersMu.Lock()
+	delete(n.peers, nodeID)
+	n.peersMu.Unlock()
+
+	return nil
+}
+
+type joinRequest struct {
+	NodeID   string `json:"node_id"`
+	RaftAddr string `json:"raft_addr"`
+}
+
+type joinResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Join admits this node into an existing cluster by POSTing a join
+// request, in turn, to each address in seeds until one accepts it,
+// retrying the whole list with backoff if every seed refuses (because
+// it isn't a shard leader, or is unreachable). This is the same
+// static-seed-list bootstrap pattern tools like Consul and etcd use
+// instead of requiring the caller to already know the leader.
+func (n *Node) Join(seeds []string) error {
+	if len(seeds) == 0 {
+		return fmt.Errorf("no seed addresses given")
+	}
+
+	client := &http.Client{Timeout: joinHTTPTimeout}
+
+	var lastErr error
+	for round := 0; round < joinMaxRounds; round++ {
+		for _, seed := range seeds {
+			if seed == "" {
+				continue
+			}
+			if err := n.joinVia(client, seed); err != nil {
+				lastErr = err
+				n.logger.Warn("join attempt failed", zap.String("seed", seed), zap.Error(err))
+				continue
+			}
+			n.logger.Info("joined cluster", zap.String("via", seed))
+			return nil
+		}
+		time.Sleep(joinRetryDelay)
+	}
+	return fmt.Errorf("failed to join cluster via %v after %d rounds: %w", seeds, joinMaxRounds, lastErr)
+}
+
+// joinVia posts this node's identity to addr's membership endpoint.
+func (n *Node) joinVia(client *http.Client, addr string) error {
+	body, err := json.Marshal(joinRequest{NodeID: n.config.NodeID, RaftAddr: n.config.RaftAddr})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), joinHTTPTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("http://%s/cluster/join", addr)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var jr joinResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jr); err != nil {
+		return fmt.Errorf("decode join response from %s: %w", addr, err)
+	}
+	if !jr.OK {
+		if jr.Error != "" {
+			return fmt.Errorf("join rejected by %s: %s", addr, jr.Error)
+		}
+		return fmt.Errorf("join rejected by %s", addr)
+	}
+
+	n.peersMu.Lock()
+	if n.peers == nil {
+		n.peers = make(map[string]string)
+	}
+	n.peers[addr] = addr
+	n.peersMu.Unlock()
+
+	return nil
+}
+
+// shardServerID is the raft.ServerID a node registers under for a given
+// shard's group, matching the LocalID every shardRaft configures itself
+// with in newShardGroup.
+func shardServerID(nodeID string, shardID uint32) string {
+	return fmt.Sprintf("%s-shard%d", nodeID, shardID)
+}
@@ -0,0 +1,256 @@
+package cluster
+
+import (
+	"sync"
+)
+
+// MemoryStorage is an in-memory StorageEngine, intended for tests and for
+// single-node/dev deployments that don't need durability. It mirrors the
+// index/term bookkeeping that BadgerStorage persists to disk, so the two
+// can be exercised by the same invariant tests.
+type MemoryStorage struct {
+	mu sync.RWMutex
+
+	hardState RaftState
+	snapshot  Snapshot
+
+	// entries holds the log, indexed by entries[i].Index == compactIndex+1+i.
+	// entries[0] never has Index == compactIndex; a compacted log with no
+	// remaining entries is represented by entries == nil.
+	entries []Entry
+
+	// compactIndex and compactTerm describe the entry immediately before
+	// the oldest retained log entry (or the last applied snapshot, if the
+	// log has never held more than that). Term(compactIndex) must still
+	// resolve, matching etcd/raft's storage contract.
+	compactIndex uint64
+	compactTerm  uint64
+
+	meta map[string][]byte
+
+	// groups holds the per-Raft-group logs SaveBatch writes to, keyed by
+	// GroupID. This is separate from the single implicit log above
+	// (hardState/snapshot/entries/compactIndex/compactTerm), which Save
+	// keeps serving for single-group callers and tests.
+	groups map[uint64]*memoryGroupLog
+}
+
+// memoryGroupLog is one Raft group's log/state, as held by
+// MemoryStorage.groups.
+type memoryGroupLog struct {
+	hardState    RaftState
+	snapshot     Snapshot
+	entries      []Entry
+	compactIndex uint64
+	compactTerm  uint64
+}
+
+// NewMemoryStorage creates an empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{}
+}
+
+// InitialState returns the last saved HardState.
+func (m *MemoryStorage) InitialState() (RaftState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.hardState, nil
+}
+
+// lastIndexLocked returns the highest index held, whether in the log or as
+// the compaction marker. Callers must hold m.mu.
+func (m *MemoryStorage) lastIndexLocked() uint64 {
+	if len(m.entries) == 0 {
+		return m.compactIndex
+	}
+	return m.entries[len(m.entries)-1].Index
+}
+
+// FirstIndex returns the index of the oldest entry still available via
+// Entries/Term, i.e. one past the last compacted index.
+func (m *MemoryStorage) FirstIndex() (uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.compactIndex + 1, nil
+}
+
+// LastIndex returns the index of the most recently saved entry.
+func (m *MemoryStorage) LastIndex() (uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastIndexLocked(), nil
+}
+
+// Term returns the term of the entry at index i.
+func (m *MemoryStorage) Term(i uint64) (uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if i == m.compactIndex {
+		return m.compactTerm, nil
+	}
+	if i < m.compactIndex {
+		return 0, ErrCompacted
+	}
+	last := m.lastIndexLocked()
+	if i > last {
+		return 0, ErrUnavailable
+	}
+	return m.entries[i-m.compactIndex-1].Term, nil
+}
+
+// Entries returns the entries in [lo, hi), trimmed to maxSize bytes of
+// Data (always returning at least one entry, matching etcd/raft's
+// contract that a non-empty range never comes back empty).
+func (m *MemoryStorage) Entries(lo, hi, maxSize uint64) ([]Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if lo <= m.compactIndex {
+		return nil, ErrCompacted
+	}
+	if hi > m.lastIndexLocked()+1 {
+		return nil, ErrUnavailable
+	}
+
+	start := lo - m.compactIndex - 1
+	end := hi - m.compactIndex - 1
+	out := make([]Entry, 0, end-start)
+
+	var size uint64
+	for _, e := range m.entries[start:end] {
+		if len(out) > 0 && size+uint64(len(e.Data)) > maxSize {
+			break
+		}
+		out = append(out, e)
+		size += uint64(len(e.Data))
+	}
+	return out, nil
+}
+
+// Snapshot returns the most recently applied snapshot.
+func (m *MemoryStorage) Snapshot() (Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.snapshot, nil
+}
+
+// Save persists st, appends/truncates the log to ents, and - if snap
+// carries a newer snapshot than the one already stored - applies it. All
+// three updates happen under a single lock, so a reader never observes a
+// partially-applied Save.
+func (m *MemoryStorage) Save(st RaftState, ents []Entry, snap Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if snap.Metadata.Index != 0 {
+		if snap.Metadata.Index <= m.snapshot.Metadata.Index {
+			return ErrSnapOutOfDate
+		}
+		m.snapshot = snap
+		m.compactIndex = snap.Metadata.Index
+		m.compactTerm = snap.Metadata.Term
+		m.entries = nil
+	}
+
+	m.hardState = st
+
+	for _, e := range ents {
+		if e.Index <= m.compactIndex {
+			continue
+		}
+		if offset := e.Index - m.compactIndex - 1; offset < uint64(len(m.entries)) {
+			// Conflicts with an existing entry: truncate the tail and
+			// replace it, same as a Raft leader overwriting a follower's
+			// uncommitted suffix.
+			m.entries = m.entries[:offset]
+		}
+		m.entries = append(m.entries, e)
+	}
+
+	return nil
+}
+
+// SaveBatch persists updates from multiple Raft groups under a single
+// lock acquisition - MemoryStorage's analogue of the single fsync
+// BadgerStorage.SaveBatch coalesces its writes into.
+func (m *MemoryStorage) SaveBatch(updates []GroupUpdate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.groups == nil {
+		m.groups = make(map[uint64]*memoryGroupLog, len(updates))
+	}
+
+	for _, u := range updates {
+		g, ok := m.groups[u.GroupID]
+		if !ok {
+			g = &memoryGroupLog{}
+			m.groups[u.GroupID] = g
+		}
+
+		if u.Snapshot.Metadata.Index != 0 {
+			if u.Snapshot.Metadata.Index <= g.snapshot.Metadata.Index {
+				return ErrSnapOutOfDate
+			}
+			g.snapshot = u.Snapshot
+			g.compactIndex = u.Snapshot.Metadata.Index
+			g.compactTerm = u.Snapshot.Metadata.Term
+			g.entries = nil
+		}
+
+		g.hardState = u.State
+
+		for _, e := range u.Entries {
+			if e.Index <= g.compactIndex {
+				continue
+			}
+			if offset := e.Index - g.compactIndex - 1; offset < uint64(len(g.entries)) {
+				g.entries = g.entries[:offset]
+			}
+			g.entries = append(g.entries, e)
+		}
+	}
+
+	return nil
+}
+
+// LoadMeta returns the value saved under key by SaveMeta, or a nil value
+// and a nil error if key was never saved.
+func (m *MemoryStorage) LoadMeta(key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]byte{}, m.meta[key]...), nil
+}
+
+// SaveMeta persists value under key.
+func (m *MemoryStorage) SaveMeta(key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.meta == nil {
+		m.meta = make(map[string][]byte)
+	}
+	m.meta[key] = append([]byte{}, value...)
+	return nil
+}
+
+// Compact discards all entries with Index <= index, retaining index's own
+// term so Term(index) still resolves (it becomes the new compactIndex).
+func (m *MemoryStorage) Compact(index uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if index <= m.compactIndex {
+		return ErrCompacted
+	}
+	last := m.lastIndexLocked()
+	if index > last {
+		return ErrUnavailable
+	}
+
+	term := m.entries[index-m.compactIndex-1].Term
+	m.entries = append([]Entry{}, m.entries[index-m.compactIndex:]...)
+	m.compactIndex = index
+	m.compactTerm = term
+	return nil
+}
@@ -4,20 +4,19 @@ package cluster
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/raft"
-	raftboltdb "github.com/hashicorp/raft-boltdb"
-	"github.com/lumadb/cluster/pkg/config"
-	"github.com/lumadb/cluster/pkg/core" // New import
-	"github.com/lumadb/cluster/pkg/platform/events"
+	"github.com/tdb-plus/cluster/pkg/config"
+	"github.com/tdb-plus/cluster/pkg/core" // New import
+	"github.com/tdb-plus/cluster/pkg/idutil"
+	"github.com/tdb-plus/cluster/pkg/platform/events"
 	"go.uber.org/zap"
 )
 
@@ -26,25 +25,56 @@ type Node struct {
 	config    *config.Config
 	logger    *zap.Logger
 	db        *core.Database // Persistent storage
-	raft      *raft.Raft
-	fsm       *FSM
-	transport *raft.NetworkTransport
+	shardRaft *ShardRaftManager
+	idGen     *idutil.Generator
+	snapshots *snapshotState
+	encKeys   *fileKeyRotator // nil if cfg.EncryptionKey is unset
 
 	// Cluster membership
 	peers   map[string]string // nodeID -> address
 	peersMu sync.RWMutex
 
-	// Node state
-	isLeader   bool
-	leaderAddr string
-	leaderMu   sync.RWMutex
-
 	// Shard assignments
 	shards   map[uint32]*ShardInfo
 	shardsMu sync.RWMutex
 
 	// Event Triggers
 	triggers *events.TriggerManager
+
+	// Watch indexes, for long-polling cache invalidation (see watch.go)
+	watches *watchRegistry
+
+	// commandHooks lets other packages (e.g. pkg/nats, for JetStream
+	// stream topology) react to committed Command ops the FSM doesn't
+	// know about natively, without pkg/cluster importing them back and
+	// creating an import cycle.
+	commandHooks   map[string]func(json.RawMessage) error
+	commandHooksMu sync.RWMutex
+}
+
+// RegisterCommandHook arms fn to run whenever the FSM commits a Command
+// whose Op equals op and isn't one of the built-in ops ("set"/"delete").
+// fn runs on every node in the cluster as that node's Raft log catches
+// up, the same way the built-in ops do, so it's a safe place to replay
+// cluster-wide side effects (e.g. creating a replicated JetStream
+// stream) in lockstep with the log.
+func (n *Node) RegisterCommandHook(op string, fn func(json.RawMessage) error) {
+	n.commandHooksMu.Lock()
+	defer n.commandHooksMu.Unlock()
+	if n.commandHooks == nil {
+		n.commandHooks = make(map[string]func(json.RawMessage) error)
+	}
+	n.commandHooks[op] = fn
+}
+
+func (n *Node) runCommandHook(op string, value json.RawMessage) (bool, error) {
+	n.commandHooksMu.RLock()
+	fn, ok := n.commandHooks[op]
+	n.commandHooksMu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return true, fn(value)
 }
 
 // ShardInfo contains information about a shard
@@ -74,13 +104,29 @@ func NewNode(cfg *config.Config, logger *zap.Logger) (*Node, error) {
 		return nil, fmt.Errorf("failed to open storage engine: %w", err)
 	}
 
+	var encKeys *fileKeyRotator
+	if cfg.EncryptionKey != "" {
+		dek, err := base64.StdEncoding.DecodeString(cfg.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("decode encryption_key: %w", err)
+		}
+		encKeys, err = newFileKeyRotator(filepath.Join(cfg.DataDir, "keys.json"), dek)
+		if err != nil {
+			return nil, fmt.Errorf("load key rotator: %w", err)
+		}
+	}
+
 	node := &Node{
-		config:   cfg,
-		logger:   logger,
-		db:       db,
-		peers:    make(map[string]string),
-		shards:   make(map[uint32]*ShardInfo),
-		triggers: events.NewTriggerManager(logger, cfg.RedpandaAddr),
+		config:    cfg,
+		logger:    logger,
+		db:        db,
+		idGen:     idutil.NewGenerator(cfg.NodeID, time.Now()),
+		snapshots: &snapshotState{stage: "idle"},
+		encKeys:   encKeys,
+		peers:     make(map[string]string),
+		shards:    make(map[uint32]*ShardInfo),
+		triggers:  events.NewTriggerManager(logger, cfg.RedpandaAddr, cfg.DataDir),
+		watches:   newWatchRegistry(),
 	}
 
 	// Initialize shards
@@ -95,56 +141,13 @@ func NewNode(cfg *config.Config, logger *zap.Logger) (*Node, error) {
 		}
 	}
 
-	// Create FSM
-	node.fsm = NewFSM(node, logger)
-
-	// Setup Raft configuration
-	raftConfig := raft.DefaultConfig()
-	raftConfig.LocalID = raft.ServerID(cfg.NodeID)
-	raftConfig.HeartbeatTimeout = 1000 * time.Millisecond
-	raftConfig.ElectionTimeout = 1000 * time.Millisecond
-	raftConfig.CommitTimeout = 50 * time.Millisecond
-	raftConfig.MaxAppendEntries = 64
-	raftConfig.SnapshotInterval = 120 * time.Second
-	raftConfig.SnapshotThreshold = 8192
-
-	// Create transport
-	addr, err := net.ResolveTCPAddr("tcp", cfg.RaftAddr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve raft address: %w", err)
-	}
-
-	transport, err := raft.NewTCPTransport(cfg.RaftAddr, addr, 3, 10*time.Second, os.Stderr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create transport: %w", err)
-	}
-	node.transport = transport
-
-	// Create stores
-	logStore, err := raftboltdb.NewBoltStore(filepath.Join(cfg.DataDir, "raft-log.db"))
+	// One independent Raft group per shard (see ShardRaftManager), rather
+	// than a single global group over all data.
+	shardRaft, err := NewShardRaftManager(node, numShards)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create log store: %w", err)
+		return nil, fmt.Errorf("failed to create shard raft manager: %w", err)
 	}
-
-	stableStore, err := raftboltdb.NewBoltStore(filepath.Join(cfg.DataDir, "raft-stable.db"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stable store: %w", err)
-	}
-
-	snapshotStore, err := raft.NewFileSnapshotStore(cfg.DataDir, 2, os.Stderr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create snapshot store: %w", err)
-	}
-
-	// Create Raft instance
-	ra, err := raft.NewRaft(raftConfig, node.fsm, logStore, stableStore, snapshotStore, transport)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create raft: %w", err)
-	}
-	node.raft = ra
-
-	// Start leader monitoring
-	go node.monitorLeadership()
+	node.shardRaft = shardRaft
 
 	return node, nil
 }
@@ -158,52 +161,30 @@ func (n *Node) UpdateShardStatus(shardID uint32, leader string, status string) {
 		shard.Leader = leader
 		shard.Status = status
 	}
+	n.watches.Bump("")
 }
 
-// Bootstrap starts a new cluster with this node as the initial leader
+// Bootstrap starts a new cluster with this node as the initial leader of
+// every shard group.
 func (n *Node) Bootstrap() error {
 	n.logger.Info("Bootstrapping new cluster")
 
-	configuration := raft.Configuration{
-		Servers: []raft.Server{
-			{
-				ID:      raft.ServerID(n.config.NodeID),
-				Address: raft.ServerAddress(n.config.RaftAddr),
-			},
-		},
-	}
-
-	future := n.raft.BootstrapCluster(configuration)
-	if err := future.Error(); err != nil {
-		if err != raft.ErrCantBootstrap {
-			return fmt.Errorf("failed to bootstrap: %w", err)
-		}
-		n.logger.Info("Cluster already bootstrapped")
+	if err := n.shardRaft.Bootstrap(raft.ServerID(n.config.NodeID)); err != nil {
+		return fmt.Errorf("failed to bootstrap: %w", err)
 	}
 
 	return nil
 }
 
-// Join joins an existing cluster
-func (n *Node) Join(leaderAddr string) error {
-	n.logger.Info("Joining cluster", zap.String("leader", leaderAddr))
-
-	// TODO: Implement proper cluster join via RPC to leader
-	// For now, this is a placeholder
-	n.leaderMu.Lock()
-	n.leaderAddr = leaderAddr
-	n.leaderMu.Unlock()
-
-	return nil
-}
+// Join is implemented in membership.go, alongside AddMember/RemoveMember
+// and the rest of the cluster's membership RPC surface.
 
 // Shutdown gracefully shuts down the node
 func (n *Node) Shutdown() error {
 	n.logger.Info("Shutting down node")
 
-	if n.raft != nil {
-		future := n.raft.Shutdown()
-		if err := future.Error(); err != nil {
+	if n.shardRaft != nil {
+		if err := n.shardRaft.Shutdown(); err != nil {
 			return fmt.Errorf("raft shutdown failed: %w", err)
 		}
 	}
@@ -221,37 +202,29 @@ func (n *Node) Shutdown() error {
 	return nil
 }
 
-// IsLeader returns true if this node is the cluster leader
+// IsLeader returns true if this node is the Raft leader of shard 0, the
+// group NewNode's callers that predate per-key sharding (health/status
+// endpoints, generic "am I the leader" checks with no specific key to
+// route by) treat as the node's coordination group.
 func (n *Node) IsLeader() bool {
-	n.leaderMu.RLock()
-	defer n.leaderMu.RUnlock()
-	return n.isLeader
+	return n.shardRaft.IsLeader(0)
 }
 
-// LeaderAddr returns the address of the current leader
+// LeaderAddr returns the address of shard 0's current leader; see
+// IsLeader for why shard 0 is the reference group for key-less callers.
 func (n *Node) LeaderAddr() string {
-	n.leaderMu.RLock()
-	defer n.leaderMu.RUnlock()
-	return n.leaderAddr
+	return n.shardRaft.LeaderAddr(0)
 }
 
-// Apply applies a command to the Raft log
+// Apply routes cmd to the Raft group of the shard its Key hashes to and
+// applies it there, returning *ErrNotLeader if this node isn't that
+// shard's leader.
 func (n *Node) Apply(cmd *Command, timeout time.Duration) error {
-	if !n.IsLeader() {
-		return fmt.Errorf("not leader, leader is at %s", n.LeaderAddr())
-	}
-
-	data, err := json.Marshal(cmd)
-	if err != nil {
-		return fmt.Errorf("failed to marshal command: %w", err)
-	}
-
-	future := n.raft.Apply(data, timeout)
-	if err := future.Error(); err != nil {
-		return fmt.Errorf("failed to apply command: %w", err)
+	shard := n.GetShardForKey([]byte(cmd.Key))
+	if shard == nil {
+		return fmt.Errorf("no shard for key %q", cmd.Key)
 	}
-
-	return nil
+	return n.shardRaft.Apply(shard.ID, cmd, timeout)
 }
 
 // GetPeers returns the current cluster peers
@@ -280,9 +253,7 @@ func (n *Node) GetShards() map[uint32]*ShardInfo {
 
 // GetShardForKey returns the shard responsible for a key
 func (n *Node) GetShardForKey(key []byte) *ShardInfo {
-	// Simple consistent hashing
-	hash := fnv1a(key)
-	shardID := uint32(hash % uint64(n.config.NumShards))
+	shardID := uint32(HashKey(key) % uint64(n.config.NumShards))
 
 	n.shardsMu.RLock()
 	defer n.shardsMu.RUnlock()
@@ -290,6 +261,11 @@ func (n *Node) GetShardForKey(key []byte) *ShardInfo {
 	return n.shards[shardID]
 }
 
+// NumShards returns the configured shard count used for key hashing.
+func (n *Node) NumShards() uint32 {
+	return uint32(n.config.NumShards)
+}
+
 // GetDatabase returns the underlying database instance
 func (n *Node) GetDatabase() *core.Database {
 	return n.db
@@ -300,27 +276,33 @@ func (n *Node) GetConfig() *config.Config {
 	return n.config
 }
 
-func (n *Node) monitorLeadership() {
-	for {
-		select {
-		case isLeader := <-n.raft.LeaderCh():
-			n.leaderMu.Lock()
-			n.isLeader = isLeader
-			if isLeader {
-				n.logger.Info("This node is now the leader")
-				n.leaderAddr = n.config.RaftAddr
-			} else {
-				addr, _ := n.raft.LeaderWithID()
-				n.leaderAddr = string(addr)
-				n.logger.Info("Leader changed", zap.String("new_leader", n.leaderAddr))
-			}
-			n.leaderMu.Unlock()
-		}
-	}
+// Subscribe streams insert/update/delete events fired for collection by
+// InsertDocument/UpdateDocument/DeleteDocument, matching eventTypes. The
+// returned unsubscribe func must be called once the caller is done
+// reading, to release the subscription - see events.TriggerManager.Subscribe.
+// Used by GraphQL subscriptions (see pkg/platform/graphql's Subscribe).
+func (n *Node) Subscribe(collection string, eventTypes []events.EventType) (<-chan events.Event, func(), error) {
+	return n.triggers.Subscribe(collection, eventTypes)
+}
+
+// RotateEncryptionKey installs newDEK as the pending data-encryption key
+// for this node's Raft log, stable, and snapshot stores: new writes start
+// going out under it immediately, while reads keep accepting entries
+// encrypted under the outgoing current key until the whole store has been
+// rewritten (e.g. via a snapshot/restore cycle) and PromotePending is
+// called to complete the rotation. Returns an error if this node wasn't
+// started with encryption_key set, since there's no rotator to update.
+func (n *Node) RotateEncryptionKey(newDEK []byte) error {
+	if n.encKeys == nil {
+		return fmt.Errorf("cluster: encryption is not enabled on this node")
+	}
+	return n.encKeys.rotate(newDEK)
 }
 
-// FNV-1a hash function
-func fnv1a(data []byte) uint64 {
+// HashKey hashes a key with FNV-1a for consistent shard/token assignment.
+// It is exported so other packages (e.g. router) can derive the same
+// shard ownership decisions the cluster uses internally.
+func HashKey(data []byte) uint64 {
 	const (
 		offset64 = 14695981039346656037
 		prime64  = 1099511628211
@@ -334,25 +316,42 @@ func fnv1a(data []byte) uint64 {
 	return hash
 }
 
-// Command represents a Raft command
+// Command represents a Raft command. For "set", Key and any _id inside
+// Value must already be fully materialized (see idutil.Generator) before
+// the command is submitted to Raft: FSM.Apply runs on every replica, so
+// it must never mint an ID itself.
 type Command struct {
 	Op         string          `json:"op"`
 	Collection string          `json:"collection"`
 	Key        string          `json:"key"`
 	Value      json.RawMessage `json:"value,omitempty"`
+
+	// IdempotencyKey, when non-empty, identifies this command as a
+	// candidate for de-duplication: FSM.Apply records the outcome it
+	// produces under this key and replays that outcome instead of
+	// re-applying the command if the same key shows up again within the
+	// idempotency store's TTL (e.g. a client retrying after a
+	// leader-redirect + connection reset). See pkg/cluster/idempotency.go.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 // FSM is the Finite State Machine for Raft
 type FSM struct {
-	node   *Node
-	logger *zap.Logger
+	node        *Node
+	logger      *zap.Logger
+	idempotency *idempotencyStore
 }
 
 // NewFSM creates a new FSM
 func NewFSM(node *Node, logger *zap.Logger) *FSM {
+	var ttl time.Duration
+	if cfg := node.GetConfig(); cfg != nil {
+		ttl = time.Duration(cfg.IdempotencyTTLMs) * time.Millisecond
+	}
 	return &FSM{
-		node:   node,
-		logger: logger,
+		node:        node,
+		logger:      logger,
+		idempotency: newIdempotencyStore(ttl, 0),
 	}
 }
 
@@ -364,6 +363,33 @@ func (f *FSM) Apply(log *raft.Log) interface{} {
 		return err
 	}
 
+	if cmd.IdempotencyKey != "" {
+		if outcome, ok := f.idempotency.lookup(cmd.IdempotencyKey); ok {
+			return outcome.err()
+		}
+	}
+
+	err := f.applyCommand(&cmd)
+
+	if cmd.IdempotencyKey != "" {
+		outcome := idempotencyOutcome{}
+		if err != nil {
+			outcome.Failed = true
+			outcome.ErrMsg = err.Error()
+		}
+		f.idempotency.record(cmd.IdempotencyKey, outcome)
+	}
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyCommand executes cmd's effect on the underlying DB/command hooks,
+// without any idempotency bookkeeping - split out of Apply so the
+// idempotency-key lookup/record logic can wrap a single call site.
+func (f *FSM) applyCommand(cmd *Command) error {
 	switch cmd.Op {
 	case "set":
 		// Write to persistent Rust storage
@@ -376,120 +402,153 @@ func (f *FSM) Apply(log *raft.Log) interface{} {
 			f.logger.Error("Failed to delete from DB", zap.Error(err))
 			return err
 		}
+	case "update":
+		var updates map[string]interface{}
+		if err := json.Unmarshal(cmd.Value, &updates); err != nil {
+			f.logger.Error("Failed to unmarshal update", zap.Error(err))
+			return err
+		}
+		if err := f.node.db.Update(cmd.Collection, cmd.Key, updates); err != nil {
+			f.logger.Error("Failed to update in DB", zap.Error(err))
+			return err
+		}
+	default:
+		handled, err := f.node.runCommandHook(cmd.Op, cmd.Value)
+		if handled && err != nil {
+			f.logger.Error("Command hook failed", zap.String("op", cmd.Op), zap.Error(err))
+			return err
+		}
 	}
 
 	return nil
 }
 
-// Snapshot returns an FSM snapshot
-func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
-	return &fsmSnapshot{node: f.node}, nil
-}
-
-// Restore restores the FSM from a snapshot
-func (f *FSM) Restore(rc io.ReadCloser) error {
-	defer rc.Close()
-
-	// Create temp file for restore
-	tmpFile, err := os.CreateTemp("", "luma-snapshot-*.bin")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
-
-	// Copy snapshot data to temp file
-	if _, err := io.Copy(tmpFile, rc); err != nil {
-		return fmt.Errorf("failed to copy snapshot data: %w", err)
-	}
+// Snapshot and Restore are implemented in snapshot.go.
 
-	// Restore DB from file
-	f.logger.Info("Restoring from snapshot", zap.String("path", tmpFile.Name()))
-	if err := f.node.db.Restore(tmpFile.Name()); err != nil {
-		return fmt.Errorf("failed to restore db: %w", err)
+// ListCollections returns all collection names
+func (n *Node) ListCollections() ([]string, error) {
+	if n.db == nil {
+		return nil, fmt.Errorf("database not initialized")
 	}
-
-	return nil
-}
-
-type fsmSnapshot struct {
-	node *Node
+	return n.db.ListCollections()
 }
 
-func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
-	defer sink.Close()
-
-	// Create temp file for snapshot
-	tmpFile, err := os.CreateTemp("", "luma-snapshot-*.bin")
-	if err != nil {
-		sink.Cancel()
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer os.Remove(tmpFile.Name())
-	tmpFile.Close() // Close immediately, DB will open it
+// ReadConsistency selects how a read is served: against the local
+// replica immediately (Stale), or only after confirming this node is
+// still the relevant shard's Raft leader and caught up to the commit
+// index that confirmation observed (Linearizable). See
+// ShardRaftManager.VerifyRead.
+type ReadConsistency int
+
+const (
+	// Stale serves the read from whatever this replica currently has,
+	// with no leadership or commit-index check. Cheap, but can return
+	// data that's behind the cluster's true current state, or (on a
+	// partitioned former leader) already-overwritten data.
+	Stale ReadConsistency = iota
+	// Linearizable verifies this node's leadership of the relevant shard
+	// before serving the read, so the result reflects every write
+	// already acknowledged to a client. A non-leader asked for a
+	// Linearizable read returns *ErrNotLeader so the caller can forward
+	// to ErrNotLeader.Leader, the same way writes already do.
+	Linearizable
+)
 
-	// Create snapshot in temp file
-	s.node.logger.Info("Creating snapshot", zap.String("path", tmpFile.Name()))
-	if err := s.node.db.Snapshot(tmpFile.Name()); err != nil {
-		sink.Cancel()
-		return fmt.Errorf("failed to snapshot db: %w", err)
-	}
+// readVerifyTimeout bounds how long a Linearizable read waits for the
+// VerifyLeader heartbeat round and Barrier to complete.
+const readVerifyTimeout = 5 * time.Second
 
-	// Copy temp file to sink
-	f, err := os.Open(tmpFile.Name())
-	if err != nil {
-		sink.Cancel()
-		return fmt.Errorf("failed to open snapshot file: %w", err)
+// GetDocument retrieves a document by ID.
+func (n *Node) GetDocument(collection, id string, consistency ReadConsistency) (map[string]interface{}, error) {
+	if n.db == nil {
+		return nil, fmt.Errorf("database not initialized")
 	}
-	defer f.Close()
-
-	if _, err := io.Copy(sink, f); err != nil {
-		sink.Cancel()
-		return fmt.Errorf("failed to copy snapshot to sink: %w", err)
+	if consistency == Linearizable && n.shardRaft != nil {
+		shard := n.GetShardForKey([]byte(id))
+		if shard == nil {
+			return nil, fmt.Errorf("no shard for key %q", id)
+		}
+		if err := n.shardRaft.VerifyRead(shard.ID, readVerifyTimeout); err != nil {
+			return nil, err
+		}
 	}
-
-	return nil
+	return n.db.Get(collection, id)
 }
 
-func (s *fsmSnapshot) Release() {}
-
-// ListCollections returns all collection names
-func (n *Node) ListCollections() ([]string, error) {
+// GetDocumentContext is GetDocument, but aborts the underlying storage op
+// and returns ctx.Err() if ctx is cancelled or its deadline elapses first
+// - e.g. when the HTTP client that triggered this read has disconnected.
+func (n *Node) GetDocumentContext(ctx context.Context, collection, id string, consistency ReadConsistency) (map[string]interface{}, error) {
 	if n.db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	return n.db.ListCollections()
+	if consistency == Linearizable && n.shardRaft != nil {
+		shard := n.GetShardForKey([]byte(id))
+		if shard == nil {
+			return nil, fmt.Errorf("no shard for key %q", id)
+		}
+		if err := n.shardRaft.VerifyRead(shard.ID, readVerifyTimeout); err != nil {
+			return nil, err
+		}
+	}
+	return n.db.GetContext(ctx, collection, id)
 }
 
-// GetDocument retrieves a document
-func (n *Node) GetDocument(collection, id string) (map[string]interface{}, error) {
+// RunQuery executes a query against collection. Unlike GetDocument, a
+// query can scan keys spanning every shard, so Linearizable here only
+// verifies this node's leadership of shard 0 (the same coordination-
+// group shard IsLeader/LeaderAddr already treat as representative)
+// rather than every shard the scan might touch; true cross-shard
+// read-index is out of scope until RunQuery is itself shard-aware.
+func (n *Node) RunQuery(collection string, query interface{}, consistency ReadConsistency) ([]map[string]interface{}, error) {
 	if n.db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	// TODO: Forward to leader if not leader?
-	return n.db.Get(collection, id)
+	if consistency == Linearizable && n.shardRaft != nil {
+		if err := n.shardRaft.VerifyRead(0, readVerifyTimeout); err != nil {
+			return nil, err
+		}
+	}
+	return n.db.Query(collection, query)
 }
 
-// RunQuery executes a query
-func (n *Node) RunQuery(collection string, query interface{}) ([]map[string]interface{}, error) {
+// RunQueryContext is RunQuery, but aborts the underlying storage op and
+// returns ctx.Err() if ctx is cancelled or its deadline elapses first -
+// e.g. when the HTTP client that triggered this query has disconnected.
+func (n *Node) RunQueryContext(ctx context.Context, collection string, query interface{}, consistency ReadConsistency) ([]map[string]interface{}, error) {
 	if n.db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	return n.db.Query(collection, query)
+	if consistency == Linearizable && n.shardRaft != nil {
+		if err := n.shardRaft.VerifyRead(0, readVerifyTimeout); err != nil {
+			return nil, err
+		}
+	}
+	return n.db.QueryContext(ctx, collection, query)
 }
 
 // InsertDocument inserts a document
 func (n *Node) InsertDocument(collection string, doc map[string]interface{}) (string, error) {
-	if n.raft == nil {
+	if n.shardRaft == nil {
 		// Fallback for non-raft mode tests
 		if n.db == nil {
 			return "", fmt.Errorf("database not initialized")
 		}
-		return n.db.Insert(collection, doc)
+		id, err := n.db.Insert(collection, doc)
+		if err == nil {
+			go n.triggers.Fire(context.Background(), collection, events.EventInsert, doc, nil)
+		}
+		return id, err
+	}
+
+	// Every Command that reaches Raft must already carry a fully
+	// materialized _id: FSM.Apply runs on every replica (and during
+	// follower replay), so it must never mint one itself. Generate the
+	// ID here, on the leader, before the doc is marshaled and applied.
+	if id, ok := doc["_id"].(string); !ok || id == "" {
+		doc["_id"] = n.idGen.Next()
 	}
 
-	// Replicate via Raft
-	// We need to marshal the doc to bytes
 	docBytes, err := json.Marshal(doc)
 	if err != nil {
 		return "", err
@@ -498,44 +557,21 @@ func (n *Node) InsertDocument(collection string, doc map[string]interface{}) (st
 	cmd := &Command{
 		Op:         "set",
 		Collection: collection,
+		Key:        doc["_id"].(string),
 		Value:      docBytes,
-		// Key generation needs to happen here or in Apply
-		// For simplicity, we assume ID is in doc or generated by DB.
-		// If generated by DB, we might have issue with Raft deterministic playback if ID generation is non-deterministic.
-		// Ideally, we generate ID here.
-	}
-
-	// Check if ID exists
-	if id, ok := doc["_id"].(string); ok {
-		cmd.Key = id
-	} else {
-		// Generate ID
-		cmd.Key = fmt.Sprintf("%d", time.Now().UnixNano()) // Simple ID for now
-		doc["_id"] = cmd.Key
-		// Remarshal with ID
-		docBytes, _ = json.Marshal(doc)
-		cmd.Value = docBytes
 	}
 
-	cmdBytes, err := json.Marshal(cmd)
-	if err != nil {
-		return "", err
+	// Route to the Raft group of the shard this document's key belongs
+	// to, same as Apply, so concurrent inserts to different shards commit
+	// in parallel instead of funnelling through one global log.
+	shard := n.GetShardForKey([]byte(cmd.Key))
+	if shard == nil {
+		return "", fmt.Errorf("no shard for key %q", cmd.Key)
 	}
-
-	future := n.raft.Apply(cmdBytes, 5*time.Second)
-	if err := future.Error(); err != nil {
+	if err := n.shardRaft.Apply(shard.ID, cmd, 5*time.Second); err != nil {
 		return "", err
 	}
 
-	// Apply returns err or result from FSM.Apply
-	// Our FSM.Apply returns error or nil
-	resp := future.Response()
-	if resp != nil {
-		if err, ok := resp.(error); ok {
-			return "", err
-		}
-	}
-
 	// Fire AfterInsert Event
 	// Note: We only fire if we are the leader (or in non-raft mode) to avoid duplicate events
 	// If Raft is used, this code runs on the leader.
@@ -548,20 +584,51 @@ func (n *Node) InsertDocument(collection string, doc map[string]interface{}) (st
 
 // UpdateDocument updates a document
 func (n *Node) UpdateDocument(collection, id string, updates map[string]interface{}) error {
-	if n.db == nil {
-		return fmt.Errorf("database not initialized")
+	if n.shardRaft == nil {
+		if n.db == nil {
+			return fmt.Errorf("database not initialized")
+		}
+		err := n.db.Update(collection, id, updates)
+		if err == nil {
+			go n.triggers.Fire(context.Background(), collection, events.EventUpdate, updates, nil)
+		}
+		return err
+	}
+
+	updatesBytes, err := json.Marshal(updates)
+	if err != nil {
+		return err
 	}
-	// TODO: Raft replication
-	return n.db.Update(collection, id, updates)
+
+	cmd := &Command{
+		Op:         "update",
+		Collection: collection,
+		Key:        id,
+		Value:      updatesBytes,
+	}
+
+	shard := n.GetShardForKey([]byte(cmd.Key))
+	if shard == nil {
+		return fmt.Errorf("no shard for key %q", cmd.Key)
+	}
+	if err := n.shardRaft.Apply(shard.ID, cmd, 5*time.Second); err != nil {
+		return err
+	}
+	go n.triggers.Fire(context.Background(), collection, events.EventUpdate, updates, nil)
+	return nil
 }
 
 // DeleteDocument deletes a document
 func (n *Node) DeleteDocument(collection, id string) error {
-	if n.raft == nil {
+	if n.shardRaft == nil {
 		if n.db == nil {
 			return fmt.Errorf("database not initialized")
 		}
-		return n.db.Delete(collection, id)
+		err := n.db.Delete(collection, id)
+		if err == nil {
+			go n.triggers.Fire(context.Background(), collection, events.EventDelete, map[string]interface{}{"_id": id}, nil)
+		}
+		return err
 	}
 
 	cmd := &Command{
@@ -570,21 +637,13 @@ func (n *Node) DeleteDocument(collection, id string) error {
 		Key:        id,
 	}
 
-	cmdBytes, err := json.Marshal(cmd)
-	if err != nil {
-		return err
+	shard := n.GetShardForKey([]byte(cmd.Key))
+	if shard == nil {
+		return fmt.Errorf("no shard for key %q", cmd.Key)
 	}
-
-	future := n.raft.Apply(cmdBytes, 5*time.Second)
-	if err := future.Error(); err != nil {
+	if err := n.shardRaft.Apply(shard.ID, cmd, 5*time.Second); err != nil {
 		return err
 	}
-
-	resp := future.Response()
-	if resp != nil {
-		if err, ok := resp.(error); ok {
-			return err
-		}
-	}
+	go n.triggers.Fire(context.Background(), collection, events.EventDelete, map[string]interface{}{"_id": id}, nil)
 	return nil
 }
@@ -5,7 +5,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/lumadb/cluster/pkg/config"
+	"github.com/tdb-plus/cluster/pkg/config"
 	"go.uber.org/zap"
 )
 
@@ -2,11 +2,12 @@ package cluster
 
 import (
 	"context"
+	"runtime"
 	"sync"
 	"time"
 
+	"github.com/tdb-plus/cluster/pkg/metrics"
 	"go.uber.org/zap"
-	"golang.org/x/sync/errgroup"
 )
 
 // ParallelRaftEngine manages multiple Raft groups (Regions) efficiently
@@ -20,9 +21,26 @@ type ParallelRaftEngine struct {
 	transport    *PipelineTransport
 	tickInterval time.Duration
 	logger       *zap.Logger
+	metrics      *metrics.MultiRaft
 
 	// Message routing
 	msgCh chan RaftMessage
+
+	// shards is the fixed worker pool Tick dispatches groups to. Each
+	// worker owns jobCh[i] for its lifetime, so a Tick never spawns a
+	// goroutine - it only sends jobs to (and reads results from) workers
+	// started once by startWorkers.
+	numShards int
+	jobCh     []chan []*RaftGroup
+	resultCh  []chan shardResult
+	startOnce sync.Once
+	workersWG sync.WaitGroup
+}
+
+// shardResult is one worker's output for a single dispatch: the groups
+// from its shard that came back Ready this tick.
+type shardResult struct {
+	ready []*RaftGroup
 }
 
 // RaftGroup represents a single Raft consensus group (Region)
@@ -51,15 +69,18 @@ func (g *RaftGroup) Tick() bool {
 	return false // true if ready
 }
 
-// NewParallelRaftEngine creates a new parallel Raft engine
-func NewParallelRaftEngine(logger *zap.Logger, tickInterval time.Duration, store StorageEngine) *ParallelRaftEngine {
+// NewParallelRaftEngine creates a new parallel Raft engine. m may be nil,
+// in which case every metrics.MultiRaft call below is a no-op.
+func NewParallelRaftEngine(logger *zap.Logger, tickInterval time.Duration, store StorageEngine, m *metrics.MultiRaft) *ParallelRaftEngine {
 	return &ParallelRaftEngine{
 		groups:       make(map[uint64]*RaftGroup),
 		storage:      store,
 		transport:    NewPipelineTransport(),
 		tickInterval: tickInterval,
 		logger:       logger,
+		metrics:      m,
 		msgCh:        make(chan RaftMessage, 10000),
+		numShards:    runtime.NumCPU(),
 	}
 }
 
@@ -73,48 +94,123 @@ func (e *ParallelRaftEngine) AddGroup(id uint64, peers []uint64) {
 	}
 }
 
-// Tick processes all Raft groups in parallel
+// startWorkers launches e.numShards long-lived workers, each reading jobs
+// off its own jobCh and ticking every group it's handed. It runs exactly
+// once per engine, the first time Tick is called, so a ParallelRaftEngine
+// that's never ticked never spawns goroutines.
+func (e *ParallelRaftEngine) startWorkers() {
+	e.startOnce.Do(func() {
+		e.jobCh = make([]chan []*RaftGroup, e.numShards)
+		e.resultCh = make([]chan shardResult, e.numShards)
+		for i := 0; i < e.numShards; i++ {
+			e.jobCh[i] = make(chan []*RaftGroup)
+			e.resultCh[i] = make(chan shardResult)
+			e.workersWG.Add(1)
+			go e.runWorker(i)
+		}
+	})
+}
+
+// runWorker is one shard's worker loop: it blocks on jobCh[shard], ticks
+// every group it's handed, and reports the Ready ones back on
+// resultCh[shard]. It runs until jobCh[shard] is closed (see Stop) - a
+// shard is reused across every Tick for the engine's lifetime, instead of
+// the one-errgroup-goroutine-per-group-per-tick approach this replaced.
+func (e *ParallelRaftEngine) runWorker(shard int) {
+	defer e.workersWG.Done()
+	for groups := range e.jobCh[shard] {
+		var ready []*RaftGroup
+		for _, g := range groups {
+			if g.Tick() {
+				ready = append(ready, g)
+			}
+		}
+		e.resultCh[shard] <- shardResult{ready: ready}
+	}
+}
+
+// Stop terminates every worker goroutine startWorkers launched, so a
+// ParallelRaftEngine that's discarded and replaced (e.g. during a region
+// split's reconfiguration, or in tests that construct many of these)
+// doesn't leak them. It's a no-op if Tick was never called - startWorkers
+// never ran, so there are no workers to stop. Stop blocks until every
+// worker has drained its jobCh and returned, or ctx is done, whichever
+// comes first; callers should not call Tick again afterward.
+func (e *ParallelRaftEngine) Stop(ctx context.Context) error {
+	if e.jobCh == nil {
+		return nil
+	}
+	for _, ch := range e.jobCh {
+		close(ch)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		e.workersWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shardFor returns the worker shard index group id is routed to, a
+// simple hash so a given group always lands on the same worker (keeping
+// per-group state access single-threaded without a per-group lock).
+func (e *ParallelRaftEngine) shardFor(id uint64) int {
+	// Fibonacci hashing spreads sequential group IDs (the common case -
+	// regions are usually allocated in order) across shards instead of
+	// piling them onto shard 0.
+	const fib64 = 11400714819323198485
+	return int((id * fib64) >> 1 % uint64(e.numShards))
+}
+
+// Tick processes all Raft groups across the fixed worker pool, then
+// persists every group that came back Ready in one batched SaveBatch
+// call instead of one disk sync per group.
 func (e *ParallelRaftEngine) Tick(ctx context.Context) error {
-	// 1. Collect active groups
+	start := time.Now()
+	e.startWorkers()
+
+	// 1. Shard the active groups by ID, so each worker owns a stable
+	// subset across ticks rather than a random one.
 	e.groupsMu.RLock()
-	groups := make([]*RaftGroup, 0, len(e.groups))
+	shardedGroups := make([][]*RaftGroup, e.numShards)
 	for _, g := range e.groups {
-		groups = append(groups, g)
+		shard := e.shardFor(g.ID)
+		shardedGroups[shard] = append(shardedGroups[shard], g)
 	}
 	e.groupsMu.RUnlock()
 
-	if len(groups) == 0 {
+	total := 0
+	for _, gs := range shardedGroups {
+		total += len(gs)
+	}
+	if total == 0 {
 		return nil
 	}
 
-	// 2. Parallel Tick
-	// For thousands of groups, we batch them into workers
-	// Simplified: Use errgroup with limited concurrency
-	g, _ := errgroup.WithContext(ctx)
-	g.SetLimit(8) // Limit concurrency to num_cores
-
-	var readyGroups []*RaftGroup
-	var readyMu sync.Mutex
-
-	for _, group := range groups {
-		group := group
-		g.Go(func() error {
-			if group.Tick() {
-				readyMu.Lock()
-				readyGroups = append(readyGroups, group)
-				readyMu.Unlock()
-			}
-			return nil
-		})
+	// 2. Dispatch each shard's groups to its long-lived worker and report
+	// its queue depth, so a hot shard (an uneven ID distribution piling
+	// onto one worker) is visible in /metrics rather than only showing up
+	// as a slow tick.
+	for shard, gs := range shardedGroups {
+		e.metrics.SetShardQueueDepth(shard, len(gs))
+		e.jobCh[shard] <- gs
 	}
 
-	if err := g.Wait(); err != nil {
-		e.logger.Error("Tick error", zap.Error(err))
+	var readyGroups []*RaftGroup
+	for shard := range shardedGroups {
+		result := <-e.resultCh[shard]
+		readyGroups = append(readyGroups, result.ready...)
 	}
 
-	// 3. Process Ready Groups (I/O)
-	// Batch persistence of logs
-	// In Multi-Raft, we batch writes from different groups into one disk sync
+	// 3. Process Ready Groups (I/O): one batched persist + message send
+	// across every shard's Ready groups, rather than per-shard I/O.
 	if len(readyGroups) > 0 {
 		if err := e.persistReady(readyGroups); err != nil {
 			return err
@@ -122,14 +218,25 @@ func (e *ParallelRaftEngine) Tick(ctx context.Context) error {
 		e.sendMessages(readyGroups)
 	}
 
+	e.metrics.ObserveTick(time.Since(start), len(readyGroups))
 	return nil
 }
 
-func (e *ParallelRaftEngine) persistReady(groups []*RaftGroup) error {
-	// Batch write to RocksDB/Badger
-	// For MVP: just log
-	// e.storage.SaveBatch(groups...)
-	return nil
+// persistReady coalesces readyGroups' log entries into one StorageEngine
+// call, so a tick with thousands of Ready groups pays for a single disk
+// sync rather than one per group.
+func (e *ParallelRaftEngine) persistReady(readyGroups []*RaftGroup) error {
+	updates := make([]GroupUpdate, len(readyGroups))
+	for i, g := range readyGroups {
+		updates[i] = GroupUpdate{
+			GroupID: g.ID,
+			State:   g.HardState,
+			// Entries/Snapshot are left zero-valued until RaftGroup grows
+			// a real log (see its "In real impl" comment) - SaveBatch
+			// already accepts them once it does.
+		}
+	}
+	return e.storage.SaveBatch(updates)
 }
 
 func (e *ParallelRaftEngine) sendMessages(groups []*RaftGroup) {
@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"sync"
 )
@@ -25,18 +26,61 @@ type Peer struct {
 	StoreID uint64
 }
 
+// regionIDMetaKey is the StorageEngine meta key RegionManager persists its
+// monotonic region-ID allocator under (see SaveMeta/LoadMeta).
+const regionIDMetaKey = "region/next_id"
+
+// defaultMaxRegionSizeBytes and defaultMaxWritesPerMinute are RunAutoSplitter's
+// default split thresholds, matched to the request that motivated them:
+// 96MB or 30k writes/min, whichever comes first.
+const (
+	defaultMaxRegionSizeBytes = 96 * 1024 * 1024
+	defaultMaxWritesPerMinute = 30000
+)
+
 // RegionManager handles region splitting, merging, and routing
 type RegionManager struct {
 	mu      sync.RWMutex
 	regions map[uint64]*Region
-	store   StorageEngine // To persist region meta
+	store   StorageEngine // Persists the region-ID allocator (see allocRegionID)
+
+	nextID uint64 // Monotonic region-ID allocator; see allocRegionID
+
+	// MaxRegionSizeBytes and MaxWritesPerMinute are RunAutoSplitter's
+	// split thresholds - a region exceeding either gets split.
+	MaxRegionSizeBytes int64
+	MaxWritesPerMinute int64
+
+	stats map[uint64]*regionStats // per-region size/write-rate heuristics; see autosplit.go
 }
 
 func NewRegionManager(store StorageEngine) *RegionManager {
-	return &RegionManager{
-		regions: make(map[uint64]*Region),
-		store:   store,
+	rm := &RegionManager{
+		regions:            make(map[uint64]*Region),
+		store:              store,
+		MaxRegionSizeBytes: defaultMaxRegionSizeBytes,
+		MaxWritesPerMinute: defaultMaxWritesPerMinute,
+		stats:              make(map[uint64]*regionStats),
+	}
+	if v, err := store.LoadMeta(regionIDMetaKey); err == nil && len(v) == 8 {
+		rm.nextID = binary.BigEndian.Uint64(v)
 	}
+	return rm
+}
+
+// allocRegionID hands out the next region ID and persists the allocator's
+// new state, so IDs never get reused across a restart - unlike the old
+// `regionID + 1000` scheme, which could collide once enough splits had
+// happened to push an ID past the next region's own ID.
+func (rm *RegionManager) allocRegionID() (uint64, error) {
+	next := rm.nextID + 1
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, next)
+	if err := rm.store.SaveMeta(regionIDMetaKey, buf); err != nil {
+		return 0, fmt.Errorf("region: persist ID allocator: %w", err)
+	}
+	rm.nextID = next
+	return next, nil
 }
 
 // GetRegionByKey finds the region containing the key
@@ -52,6 +96,20 @@ func (rm *RegionManager) GetRegionByKey(key []byte) (*Region, error) {
 	return nil, fmt.Errorf("region not found for key")
 }
 
+// ListRegions returns every region the manager currently knows about, in
+// no particular order. Callers that need key-range order should sort the
+// result themselves.
+func (rm *RegionManager) ListRegions() []*Region {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	out := make([]*Region, 0, len(rm.regions))
+	for _, r := range rm.regions {
+		out = append(out, r)
+	}
+	return out
+}
+
 func (rm *RegionManager) keyInRegion(key []byte, r *Region) bool {
 	return bytes.Compare(key, r.StartKey) >= 0 &&
 		(len(r.EndKey) == 0 || bytes.Compare(key, r.EndKey) < 0)
@@ -73,21 +131,102 @@ func (rm *RegionManager) SplitRegion(regionID uint64, splitKey []byte) (*Region,
 	}
 
 	// Create new region (Right)
-	newRegionID := regionID + 1000 // Simplified ID generation
+	newRegionID, err := rm.allocRegionID()
+	if err != nil {
+		return nil, nil, err
+	}
 	rightRegion := &Region{
 		ID:       newRegionID,
 		StartKey: splitKey,
 		EndKey:   original.EndKey,
-		Epoch:    RegionEpoch{ConfVer: 1, Version: 1},
-		Peers:    original.Peers,
+		Epoch:    original.Epoch,
+		Peers:    append([]Peer{}, original.Peers...),
 	}
 
-	// Update original (Left)
+	// Update original (Left). Splitting changes both sides' key ranges,
+	// so both bump Epoch.Version - a membership change (AddPeer/RemovePeer)
+	// would bump ConfVer instead.
 	original.EndKey = splitKey
 	original.Epoch.Version++
+	rightRegion.Epoch.Version++
 
 	// Persist
 	rm.regions[newRegionID] = rightRegion
 
 	return original, rightRegion, nil
 }
+
+// AddPeer adds peer to region's peer set, bumping Epoch.ConfVer. A
+// membership change, not a key-range change, so Epoch.Version is left
+// alone (see SplitRegion/MergeRegions, which bump Version instead).
+func (rm *RegionManager) AddPeer(regionID uint64, peer Peer) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	r, ok := rm.regions[regionID]
+	if !ok {
+		return fmt.Errorf("region %d not found", regionID)
+	}
+	for _, p := range r.Peers {
+		if p.ID == peer.ID {
+			return fmt.Errorf("peer %d already in region %d", peer.ID, regionID)
+		}
+	}
+	r.Peers = append(r.Peers, peer)
+	r.Epoch.ConfVer++
+	return nil
+}
+
+// RemovePeer removes peerID from region's peer set, bumping Epoch.ConfVer.
+func (rm *RegionManager) RemovePeer(regionID, peerID uint64) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	r, ok := rm.regions[regionID]
+	if !ok {
+		return fmt.Errorf("region %d not found", regionID)
+	}
+	for i, p := range r.Peers {
+		if p.ID == peerID {
+			r.Peers = append(r.Peers[:i], r.Peers[i+1:]...)
+			r.Epoch.ConfVer++
+			return nil
+		}
+	}
+	return fmt.Errorf("peer %d not found in region %d", peerID, regionID)
+}
+
+// MergeRegions merges right into left. left must be right's immediate
+// predecessor (left.EndKey == right.StartKey) with a matching Epoch -
+// PD-style placement only merges regions whose peer sets haven't
+// diverged, since merging otherwise would mean reconciling mismatched
+// replica groups. On success left.EndKey extends to cover right's range
+// and its Epoch.Version bumps (a key-range change, not a membership one);
+// right is removed from the manager.
+func (rm *RegionManager) MergeRegions(left, right uint64) (*Region, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	l, ok := rm.regions[left]
+	if !ok {
+		return nil, fmt.Errorf("region %d not found", left)
+	}
+	r, ok := rm.regions[right]
+	if !ok {
+		return nil, fmt.Errorf("region %d not found", right)
+	}
+
+	if !bytes.Equal(l.EndKey, r.StartKey) {
+		return nil, fmt.Errorf("regions %d and %d are not adjacent", left, right)
+	}
+	if l.Epoch != r.Epoch {
+		return nil, fmt.Errorf("regions %d and %d have diverged epochs (%+v vs %+v)", left, right, l.Epoch, r.Epoch)
+	}
+
+	l.EndKey = r.EndKey
+	l.Epoch.Version++
+	delete(rm.regions, right)
+	delete(rm.stats, right)
+
+	return l, nil
+}
@@ -0,0 +1,340 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb"
+	"go.uber.org/zap"
+)
+
+// ErrNotLeader is returned when a command is applied against a shard this
+// node isn't currently the Raft leader for. Leader is the shard's last
+// known leader address, if any, the same way the old single-group Apply
+// reported LeaderAddr() for callers to redirect against.
+type ErrNotLeader struct {
+	ShardID uint32
+	Leader  string
+}
+
+func (e *ErrNotLeader) Error() string {
+	if e.Leader == "" {
+		return fmt.Sprintf("cluster: not leader of shard %d, leader unknown", e.ShardID)
+	}
+	return fmt.Sprintf("cluster: not leader of shard %d, leader is at %s", e.ShardID, e.Leader)
+}
+
+// shardRaft is one shard's independent Raft group: its own FSM and
+// log/stable/snapshot stores, rooted under DataDir/shards/<id>/.
+type shardRaft struct {
+	id        uint32
+	raft      *raft.Raft
+	transport *raft.NetworkTransport
+	fsm       *FSM
+}
+
+// ShardRaftManager owns one Raft group per shard and routes commands to
+// the group that owns the relevant key, rather than funnelling every
+// write through a single global log. This is the same per-range
+// consensus model etcd, rqlite, and swarmkit use instead of one big Raft
+// group, and lets shards commit and elect leaders independently of one
+// another.
+type ShardRaftManager struct {
+	node   *Node
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	shards map[uint32]*shardRaft
+}
+
+// shardPort returns the TCP port shard's Raft transport binds to: the
+// node's configured Raft base port, offset by the shard ID. A node's
+// shard groups need addresses other nodes can dial *before* they start
+// (AddMember computes them from a peer's advertised RaftAddr alone), so
+// each one binds to a fixed, derivable port rather than an OS-assigned
+// ephemeral one.
+func shardPort(basePort int, shardID uint32) int {
+	return basePort + 1 + int(shardID)
+}
+
+// NewShardRaftManager creates and starts one single-node Raft group per
+// shard in [0, numShards). Each group listens on the host portion of
+// node.config.RaftAddr, at the port shardPort derives for it, so a node
+// can host many shard groups from one configured address.
+func NewShardRaftManager(node *Node, numShards uint32) (*ShardRaftManager, error) {
+	host, portStr, err := net.SplitHostPort(node.config.RaftAddr)
+	if err != nil {
+		return nil, fmt.Errorf("shardraft: invalid raft addr %q: %w", node.config.RaftAddr, err)
+	}
+	basePort, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("shardraft: invalid raft addr %q: %w", node.config.RaftAddr, err)
+	}
+
+	m := &ShardRaftManager{
+		node:   node,
+		logger: node.logger,
+		shards: make(map[uint32]*shardRaft, numShards),
+	}
+
+	for i := uint32(0); i < numShards; i++ {
+		sr, err := m.newShardGroup(i, host, basePort)
+		if err != nil {
+			return nil, err
+		}
+		m.shards[i] = sr
+		go m.monitorShardLeadership(sr)
+	}
+
+	return m, nil
+}
+
+func (m *ShardRaftManager) newShardGroup(id uint32, host string, basePort int) (*shardRaft, error) {
+	dataDir := filepath.Join(m.node.config.DataDir, "shards", fmt.Sprintf("%d", id))
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("shardraft: create shard %d dir: %w", id, err)
+	}
+
+	fsm := NewFSM(m.node, m.logger)
+
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(shardServerID(m.node.config.NodeID, id))
+	raftConfig.HeartbeatTimeout = 1000 * time.Millisecond
+	raftConfig.ElectionTimeout = 1000 * time.Millisecond
+	raftConfig.CommitTimeout = 50 * time.Millisecond
+	raftConfig.MaxAppendEntries = 64
+	raftConfig.SnapshotInterval = 120 * time.Second
+	raftConfig.SnapshotThreshold = 8192
+
+	bindAddr := net.JoinHostPort(host, strconv.Itoa(shardPort(basePort, id)))
+	addr, err := net.ResolveTCPAddr("tcp", bindAddr)
+	if err != nil {
+		return nil, fmt.Errorf("shardraft: resolve shard %d addr: %w", id, err)
+	}
+	transport, err := raft.NewTCPTransport(bindAddr, addr, 3, 10*time.Second, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("shardraft: create shard %d transport: %w", id, err)
+	}
+
+	boltLogStore, err := raftboltdb.NewBoltStore(filepath.Join(dataDir, "raft-log.db"))
+	if err != nil {
+		return nil, fmt.Errorf("shardraft: create shard %d log store: %w", id, err)
+	}
+	boltStableStore, err := raftboltdb.NewBoltStore(filepath.Join(dataDir, "raft-stable.db"))
+	if err != nil {
+		return nil, fmt.Errorf("shardraft: create shard %d stable store: %w", id, err)
+	}
+	fileSnapshotStore, err := raft.NewFileSnapshotStore(dataDir, 2, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("shardraft: create shard %d snapshot store: %w", id, err)
+	}
+
+	// raft-stable.db only ever holds Raft's own bookkeeping (current term,
+	// last vote, cluster configuration) rather than application data, so
+	// it's left on the plain BoltStore; encryption covers the log entries
+	// (which carry Command payloads) and snapshots (which carry full FSM
+	// state) where confidentiality at rest actually matters.
+	var (
+		logStore      raft.LogStore      = boltLogStore
+		stableStore   raft.StableStore   = boltStableStore
+		snapshotStore raft.SnapshotStore = fileSnapshotStore
+	)
+	if m.node.encKeys != nil {
+		logStore = NewEncryptedLogStore(boltLogStore, m.node.encKeys)
+		snapshotStore = NewEncryptedSnapshotStore(fileSnapshotStore, m.node.encKeys)
+	}
+
+	ra, err := raft.NewRaft(raftConfig, fsm, logStore, stableStore, snapshotStore, transport)
+	if err != nil {
+		return nil, fmt.Errorf("shardraft: create shard %d raft: %w", id, err)
+	}
+
+	return &shardRaft{id: id, raft: ra, transport: transport, fsm: fsm}, nil
+}
+
+// Bootstrap bootstraps every shard group as a single-node cluster rooted
+// at this node, same as the old single-group Node.Bootstrap did.
+func (m *ShardRaftManager) Bootstrap(nodeID raft.ServerID) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, sr := range m.shards {
+		configuration := raft.Configuration{
+			Servers: []raft.Server{
+				{
+					ID:      raft.ServerID(shardServerID(string(nodeID), sr.id)),
+					Address: sr.transport.LocalAddr(),
+				},
+			},
+		}
+		future := sr.raft.BootstrapCluster(configuration)
+		if err := future.Error(); err != nil {
+			if err != raft.ErrCantBootstrap {
+				return fmt.Errorf("shardraft: bootstrap shard %d: %w", sr.id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply marshals cmd and submits it to shardID's Raft log, returning
+// *ErrNotLeader if this node isn't that shard's leader.
+func (m *ShardRaftManager) Apply(shardID uint32, cmd *Command, timeout time.Duration) error {
+	m.mu.RLock()
+	sr, ok := m.shards[shardID]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("shardraft: unknown shard %d", shardID)
+	}
+
+	if sr.raft.State() != raft.Leader {
+		return &ErrNotLeader{ShardID: shardID, Leader: string(sr.raft.Leader())}
+	}
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return fmt.Errorf("shardraft: marshal command: %w", err)
+	}
+
+	future := sr.raft.Apply(data, timeout)
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("shardraft: apply to shard %d: %w", shardID, err)
+	}
+	if resp := future.Response(); resp != nil {
+		if err, ok := resp.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddVoter adds serverID, reachable at addr, as a voting member of
+// shardID's Raft group, returning *ErrNotLeader if this node isn't that
+// shard's leader.
+func (m *ShardRaftManager) AddVoter(shardID uint32, serverID raft.ServerID, addr raft.ServerAddress) error {
+	m.mu.RLock()
+	sr, ok := m.shards[shardID]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("shardraft: unknown shard %d", shardID)
+	}
+
+	if sr.raft.State() != raft.Leader {
+		return &ErrNotLeader{ShardID: shardID, Leader: string(sr.raft.Leader())}
+	}
+
+	future := sr.raft.AddVoter(serverID, addr, 0, 0)
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("shardraft: add voter to shard %d: %w", shardID, err)
+	}
+	return nil
+}
+
+// RemoveServer removes serverID from shardID's Raft group, returning
+// *ErrNotLeader if this node isn't that shard's leader.
+func (m *ShardRaftManager) RemoveServer(shardID uint32, serverID raft.ServerID) error {
+	m.mu.RLock()
+	sr, ok := m.shards[shardID]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("shardraft: unknown shard %d", shardID)
+	}
+
+	if sr.raft.State() != raft.Leader {
+		return &ErrNotLeader{ShardID: shardID, Leader: string(sr.raft.Leader())}
+	}
+
+	future := sr.raft.RemoveServer(serverID, 0, 0)
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("shardraft: remove server from shard %d: %w", shardID, err)
+	}
+	return nil
+}
+
+// VerifyRead confirms this node is still shardID's Raft leader via a
+// quorum heartbeat round (VerifyLeader, hashicorp/raft's read-index
+// primitive) and then waits for its local FSM to have applied every
+// entry committed as of that confirmation (Barrier). A read served
+// locally right after VerifyRead succeeds is linearizable: it reflects
+// every write that had already been acknowledged to some client when
+// VerifyRead was called. Returns *ErrNotLeader if this node isn't (or
+// turns out, mid-round, to no longer be) shardID's leader.
+func (m *ShardRaftManager) VerifyRead(shardID uint32, timeout time.Duration) error {
+	m.mu.RLock()
+	sr, ok := m.shards[shardID]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("shardraft: unknown shard %d", shardID)
+	}
+
+	if sr.raft.State() != raft.Leader {
+		return &ErrNotLeader{ShardID: shardID, Leader: string(sr.raft.Leader())}
+	}
+	if err := sr.raft.VerifyLeader().Error(); err != nil {
+		return &ErrNotLeader{ShardID: shardID, Leader: string(sr.raft.Leader())}
+	}
+	if err := sr.raft.Barrier(timeout).Error(); err != nil {
+		return fmt.Errorf("shardraft: barrier shard %d: %w", shardID, err)
+	}
+	return nil
+}
+
+// IsLeader reports whether this node is the Raft leader of shardID.
+func (m *ShardRaftManager) IsLeader(shardID uint32) bool {
+	m.mu.RLock()
+	sr, ok := m.shards[shardID]
+	m.mu.RUnlock()
+	return ok && sr.raft.State() == raft.Leader
+}
+
+// LeaderAddr returns shardID's last known leader address, or "" if the
+// shard is unknown or has no leader yet.
+func (m *ShardRaftManager) LeaderAddr(shardID uint32) string {
+	m.mu.RLock()
+	sr, ok := m.shards[shardID]
+	m.mu.RUnlock()
+	if !ok {
+		return ""
+	}
+	return string(sr.raft.Leader())
+}
+
+// Shutdown shuts down every shard's Raft instance.
+func (m *ShardRaftManager) Shutdown() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, sr := range m.shards {
+		if err := sr.raft.Shutdown().Error(); err != nil {
+			return fmt.Errorf("shardraft: shutdown shard %d: %w", sr.id, err)
+		}
+	}
+	return nil
+}
+
+// monitorShardLeadership keeps Node's ShardInfo.Leader in sync with
+// shard's own leadership changes, the per-shard analogue of the old
+// single-group monitorLeadership.
+func (m *ShardRaftManager) monitorShardLeadership(sr *shardRaft) {
+	for isLeader := range sr.raft.LeaderCh() {
+		var leaderAddr string
+		if isLeader {
+			leaderAddr = string(sr.transport.LocalAddr())
+		} else {
+			addr, _ := sr.raft.LeaderWithID()
+			leaderAddr = string(addr)
+		}
+		m.node.UpdateShardStatus(sr.id, leaderAddr, "active")
+		m.logger.Info("shard leadership changed",
+			zap.Uint32("shard", sr.id),
+			zap.Bool("is_leader", isLeader),
+			zap.String("leader", leaderAddr))
+	}
+}
@@ -0,0 +1,160 @@
+package cluster
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// newTestStorages returns a MemoryStorage and a BadgerStorage (backed by a
+// throwaway temp dir), so the invariant tests below run against both
+// StorageEngine implementations.
+func newTestStorages(t *testing.T) map[string]StorageEngine {
+	t.Helper()
+
+	mem := NewMemoryStorage()
+
+	dir, err := os.MkdirTemp("", "lumadb-storage-test-*")
+	if err != nil {
+		t.Fatalf("MkdirTemp failed: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	badgerStore, err := NewBadgerStorage(dir)
+	if err != nil {
+		t.Fatalf("NewBadgerStorage failed: %v", err)
+	}
+	t.Cleanup(func() { badgerStore.Close() })
+
+	return map[string]StorageEngine{
+		"memory": mem,
+		"badger": badgerStore,
+	}
+}
+
+func appendEntries(t *testing.T, s StorageEngine, entries ...Entry) {
+	t.Helper()
+	if err := s.Save(RaftState{}, entries, Snapshot{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+}
+
+func TestStorageEngine_MonotonicIndices(t *testing.T) {
+	for name, s := range newTestStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			appendEntries(t, s,
+				Entry{Term: 1, Index: 1, Data: []byte("a")},
+				Entry{Term: 1, Index: 2, Data: []byte("b")},
+				Entry{Term: 2, Index: 3, Data: []byte("c")},
+			)
+
+			first, err := s.FirstIndex()
+			if err != nil || first != 1 {
+				t.Fatalf("FirstIndex = %d, %v; want 1, nil", first, err)
+			}
+			last, err := s.LastIndex()
+			if err != nil || last != 3 {
+				t.Fatalf("LastIndex = %d, %v; want 3, nil", last, err)
+			}
+
+			ents, err := s.Entries(1, 4, 1<<20)
+			if err != nil {
+				t.Fatalf("Entries failed: %v", err)
+			}
+			for i, want := range []uint64{1, 2, 3} {
+				if ents[i].Index != want {
+					t.Errorf("ents[%d].Index = %d, want %d", i, ents[i].Index, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStorageEngine_TermAfterCompaction(t *testing.T) {
+	for name, s := range newTestStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			type compactor interface {
+				Compact(index uint64) error
+			}
+
+			appendEntries(t, s,
+				Entry{Term: 1, Index: 1},
+				Entry{Term: 1, Index: 2},
+				Entry{Term: 2, Index: 3},
+			)
+
+			if err := s.(compactor).Compact(2); err != nil {
+				t.Fatalf("Compact failed: %v", err)
+			}
+
+			if _, err := s.Term(1); !errors.Is(err, ErrCompacted) {
+				t.Errorf("Term(1) after compacting to 2 = %v, want ErrCompacted", err)
+			}
+			term, err := s.Term(2)
+			if err != nil || term != 1 {
+				t.Errorf("Term(2) = %d, %v; want 1, nil", term, err)
+			}
+			term, err = s.Term(3)
+			if err != nil || term != 2 {
+				t.Errorf("Term(3) = %d, %v; want 2, nil", term, err)
+			}
+
+			first, err := s.FirstIndex()
+			if err != nil || first != 3 {
+				t.Errorf("FirstIndex after Compact(2) = %d, %v; want 3, nil", first, err)
+			}
+
+			if _, err := s.Entries(2, 4, 1<<20); !errors.Is(err, ErrCompacted) {
+				t.Errorf("Entries(2, 4, ...) after Compact(2) = %v, want ErrCompacted", err)
+			}
+		})
+	}
+}
+
+func TestStorageEngine_SnapshotRoundTrip(t *testing.T) {
+	for name, s := range newTestStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			snap := Snapshot{Metadata: SnapshotMetadata{Index: 5, Term: 2}, Data: []byte("snapshot-payload")}
+			if err := s.Save(RaftState{}, nil, snap); err != nil {
+				t.Fatalf("Save(snapshot) failed: %v", err)
+			}
+
+			got, err := s.Snapshot()
+			if err != nil {
+				t.Fatalf("Snapshot failed: %v", err)
+			}
+			if got.Metadata.Index != 5 || got.Metadata.Term != 2 || string(got.Data) != "snapshot-payload" {
+				t.Errorf("Snapshot round-trip mismatch: got %+v", got)
+			}
+
+			older := Snapshot{Metadata: SnapshotMetadata{Index: 3, Term: 1}}
+			if err := s.Save(RaftState{}, nil, older); !errors.Is(err, ErrSnapOutOfDate) {
+				t.Errorf("Save(older snapshot) = %v, want ErrSnapOutOfDate", err)
+			}
+		})
+	}
+}
+
+func TestStorageEngine_SaveIsAtomicAcrossEntriesStateAndSnapshot(t *testing.T) {
+	for name, s := range newTestStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			err := s.Save(
+				RaftState{Term: 4, Vote: 2, Commit: 1},
+				[]Entry{{Term: 4, Index: 1, Data: []byte("x")}},
+				Snapshot{},
+			)
+			if err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+
+			st, err := s.InitialState()
+			if err != nil || st.Term != 4 || st.Vote != 2 || st.Commit != 1 {
+				t.Errorf("InitialState = %+v, %v; want {4 2 1}, nil", st, err)
+			}
+			last, err := s.LastIndex()
+			if err != nil || last != 1 {
+				t.Errorf("LastIndex = %d, %v; want 1, nil", last, err)
+			}
+		})
+	}
+}
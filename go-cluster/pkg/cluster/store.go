@@ -17,6 +17,21 @@ type StorageEngine interface {
 
 	// Write operations
 	Save(st RaftState, ents []Entry, snap Snapshot) error
+
+	// SaveBatch persists updates from multiple Raft groups (see
+	// ParallelRaftEngine) in a single fsync, so a tick that brings
+	// thousands of groups Ready at once doesn't pay for one disk sync
+	// per group. Each update is otherwise equivalent to a Save call
+	// scoped to its own GroupID's log/state.
+	SaveBatch(updates []GroupUpdate) error
+
+	// Metadata operations: generic key/value storage for small pieces of
+	// state callers need to persist alongside the Raft log/snapshot
+	// without standing up their own storage engine (e.g. RegionManager's
+	// region-ID allocator - see region.go). LoadMeta returns a nil value
+	// and a nil error for a key that was never saved.
+	LoadMeta(key string) ([]byte, error)
+	SaveMeta(key string, value []byte) error
 }
 
 // RaftState encapsulates HardState and ConfState
@@ -26,6 +41,16 @@ type RaftState struct {
 	Commit uint64
 }
 
+// GroupUpdate is one Raft group's contribution to a SaveBatch call: the
+// same (RaftState, []Entry, Snapshot) triple Save takes, scoped to
+// GroupID's own log rather than the engine's single implicit one.
+type GroupUpdate struct {
+	GroupID  uint64
+	State    RaftState
+	Entries  []Entry
+	Snapshot Snapshot
+}
+
 // Entry is a Raft log entry
 type Entry struct {
 	Term  uint64
@@ -0,0 +1,117 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+)
+
+// watchIndex is a monotonic counter with a broadcast channel, letting
+// callers long-poll for "has anything changed since index N" instead of
+// re-fetching the whole topology on every poll.
+type watchIndex struct {
+	mu    sync.Mutex
+	index uint64
+	ch    chan struct{}
+}
+
+func newWatchIndex() *watchIndex {
+	return &watchIndex{ch: make(chan struct{})}
+}
+
+// Bump advances the index and wakes any waiters.
+func (w *watchIndex) Bump() {
+	w.mu.Lock()
+	w.index++
+	closed := w.ch
+	w.ch = make(chan struct{})
+	w.mu.Unlock()
+	close(closed)
+}
+
+// Snapshot returns the current index and a channel that closes on the
+// next Bump.
+func (w *watchIndex) Snapshot() (uint64, chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.index, w.ch
+}
+
+// watchRegistry tracks per-scope watchIndexes. Scope "" is the node-wide
+// index, bumped on any membership or leader change; other scopes are
+// per-collection, bumped whenever a caller believes that collection's
+// routing may have changed (e.g. after observing a shard leader change
+// for a key range it owns — this package does not track collection ->
+// shard ownership directly, so per-collection bumps are advisory from
+// callers rather than derived automatically).
+type watchRegistry struct {
+	mu      sync.Mutex
+	indexes map[string]*watchIndex
+}
+
+func newWatchRegistry() *watchRegistry {
+	return &watchRegistry{indexes: make(map[string]*watchIndex)}
+}
+
+func (r *watchRegistry) get(scope string) *watchIndex {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	idx, ok := r.indexes[scope]
+	if !ok {
+		idx = newWatchIndex()
+		r.indexes[scope] = idx
+	}
+	return idx
+}
+
+// Bump advances both the node-wide index and, if scope is non-empty, the
+// named scope's index.
+func (r *watchRegistry) Bump(scope string) {
+	r.get("").Bump()
+	if scope != "" {
+		r.get(scope).Bump()
+	}
+}
+
+// Index returns the current value of a scope's index (0 if never bumped).
+func (r *watchRegistry) Index(scope string) uint64 {
+	idx, _ := r.get(scope).Snapshot()
+	return idx
+}
+
+// Wait blocks until scope's index advances past lastIndex, or ctx is
+// done. It returns the new index.
+func (r *watchRegistry) Wait(ctx context.Context, scope string, lastIndex uint64) (uint64, error) {
+	for {
+		idx := r.get(scope)
+		current, wakeCh := idx.Snapshot()
+		if current > lastIndex {
+			return current, nil
+		}
+
+		select {
+		case <-wakeCh:
+			continue
+		case <-ctx.Done():
+			return current, ctx.Err()
+		}
+	}
+}
+
+// WatchIndex returns the current node-wide or per-collection index. An
+// empty scope returns the node-wide index.
+func (n *Node) WatchIndex(scope string) uint64 {
+	return n.watches.Index(scope)
+}
+
+// BumpWatchIndex advances the node-wide index and, if scope is
+// non-empty, the named collection's index too. Callers that change
+// routing-relevant state (shard status, membership) should call this.
+func (n *Node) BumpWatchIndex(scope string) {
+	n.watches.Bump(scope)
+}
+
+// WaitWatchIndex blocks until scope's watch index advances past
+// lastIndex or ctx is done, returning the new index.
+func (n *Node) WaitWatchIndex(ctx context.Context, scope string, lastIndex uint64) (uint64, error) {
+	return n.watches.Wait(ctx, scope, lastIndex)
+}
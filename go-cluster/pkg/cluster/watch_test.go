@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWatchRegistry_WaitReturnsImmediatelyIfStale(t *testing.T) {
+	r := newWatchRegistry()
+	r.Bump("events")
+
+	idx, err := r.Wait(context.Background(), "events", 0)
+	if err != nil {
+		t.Fatalf("Wait returned error: %v", err)
+	}
+	if idx != 1 {
+		t.Fatalf("expected index 1, got %d", idx)
+	}
+}
+
+func TestWatchRegistry_WaitBlocksUntilBump(t *testing.T) {
+	r := newWatchRegistry()
+
+	done := make(chan uint64, 1)
+	go func() {
+		idx, err := r.Wait(context.Background(), "events", 0)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- idx
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Wait returned before any Bump")
+	default:
+	}
+
+	r.Bump("events")
+
+	select {
+	case idx := <-done:
+		if idx != 1 {
+			t.Fatalf("expected index 1, got %d", idx)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after Bump")
+	}
+}
+
+func TestWatchRegistry_WaitRespectsContextCancellation(t *testing.T) {
+	r := newWatchRegistry()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := r.Wait(ctx, "events", 0); err == nil {
+		t.Fatal("expected context deadline error")
+	}
+}
+
+func TestWatchRegistry_BumpScopeAlsoBumpsGlobal(t *testing.T) {
+	r := newWatchRegistry()
+	r.Bump("events")
+
+	if got := r.Index("events"); got != 1 {
+		t.Errorf("expected scope index 1, got %d", got)
+	}
+	if got := r.Index(""); got != 1 {
+		t.Errorf("expected global index 1, got %d", got)
+	}
+	if got := r.Index("other"); got != 0 {
+		t.Errorf("expected unrelated scope index 0, got %d", got)
+	}
+}
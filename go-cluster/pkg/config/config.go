@@ -40,6 +40,50 @@ type Config struct {
 
 	// Multi-Tier Storage Policies
 	Tiering TieringConfig `mapstructure:"tiering" json:"tiering"`
+
+	// Routing
+	DataCenter    string `mapstructure:"data_center" json:"data_center"`
+	RoutingPolicy string `mapstructure:"routing_policy" json:"routing_policy"`
+
+	// Peering (see pkg/peering)
+	PeeringSecret string `mapstructure:"peering_secret" json:"-"`
+
+	// Health checking / circuit breaking
+	HealthCheckIntervalMs    int `mapstructure:"health_check_interval_ms" json:"health_check_interval_ms"`
+	CircuitBreakerThreshold  int `mapstructure:"circuit_breaker_threshold" json:"circuit_breaker_threshold"`
+	CircuitBreakerCooldownMs int `mapstructure:"circuit_breaker_cooldown_ms" json:"circuit_breaker_cooldown_ms"`
+
+	// EncryptionKey is a base64-encoded AES-256 data-encryption key (DEK)
+	// for encrypting the Raft log/stable/snapshot stores at rest. Empty
+	// disables encryption, leaving the stores in the historical plaintext
+	// format. See pkg/cluster/encryption.go.
+	EncryptionKey string `mapstructure:"encryption_key" json:"-"`
+
+	// Platform server settings hot-reloadable via platform/config's
+	// ConfigHandler (GET/PATCH /api/v1/config).
+	CORSAllowedOrigins []string `mapstructure:"cors_allowed_origins" json:"cors_allowed_origins"`
+	JWTSecret          string   `mapstructure:"jwt_secret" json:"-"`
+
+	// DatabaseURL backs platform/auth/store's user/API-key/revoked-token
+	// persistence. Its scheme picks the backend: postgres://, mysql://,
+	// cockroach://, or sqlite3:// (the last requires a CGO build). Empty
+	// leaves AuthEngine running without a store, as before.
+	DatabaseURL string `mapstructure:"database_url" json:"-"`
+
+	// Static credentials the DynamoDB-compatible endpoint (pkg/api,
+	// POST /dynamodb) verifies incoming SigV4 signatures against. The
+	// defaults match the example AWS SDK clients under
+	// crates/lumadb-multicompat/examples/dynamodb.
+	DynamoDBAccessKeyID     string `mapstructure:"dynamodb_access_key_id" json:"-"`
+	DynamoDBSecretAccessKey string `mapstructure:"dynamodb_secret_access_key" json:"-"`
+
+	// IdempotencyTTLMs bounds how long the FSM's idempotency store
+	// remembers an IdempotencyKey's outcome (see pkg/cluster/idempotency.go),
+	// so a client retrying a write (e.g. after a leader-redirect +
+	// connection reset) within the window gets the original outcome
+	// replayed instead of the command re-applied. 0 uses the default
+	// (10 minutes).
+	IdempotencyTTLMs int `mapstructure:"idempotency_ttl_ms" json:"idempotency_ttl_ms"`
 }
 
 // TieringConfig holds configuration for storage tiers
@@ -67,21 +111,29 @@ type RedundancyStrategy struct {
 func DefaultConfig() *Config {
 	hostname, _ := os.Hostname()
 	return &Config{
-		NodeID:            hostname,
-		DataDir:           "./data",
-		HTTPAddr:          ":8080",
-		GRPCAddr:          ":9090",
-		RaftAddr:          ":10000",
-		NumShards:         16,
-		ReplicationFactor: 3,
-		MemtableSize:      64 * 1024 * 1024,  // 64MB
-		BlockCacheSize:    128 * 1024 * 1024, // 128MB
-		WALEnabled:        true,
-		MaxConnections:    1000,
-		ReadTimeout:       5000,
-		WriteTimeout:      10000,
-		RustCoreSocket:    "/tmp/tdb-core.sock",
-		PythonAIEndpoint:  "http://localhost:8000",
+		NodeID:                   hostname,
+		DataDir:                  "./data",
+		HTTPAddr:                 ":8080",
+		GRPCAddr:                 ":9090",
+		RaftAddr:                 ":10000",
+		NumShards:                16,
+		ReplicationFactor:        3,
+		MemtableSize:             64 * 1024 * 1024,  // 64MB
+		BlockCacheSize:           128 * 1024 * 1024, // 128MB
+		WALEnabled:               true,
+		MaxConnections:           1000,
+		ReadTimeout:              5000,
+		WriteTimeout:             10000,
+		RustCoreSocket:           "/tmp/tdb-core.sock",
+		PythonAIEndpoint:         "http://localhost:8000",
+		RoutingPolicy:            "token_aware,latency_aware,round_robin",
+		HealthCheckIntervalMs:    5000,
+		CircuitBreakerThreshold:  3,
+		CircuitBreakerCooldownMs: 10000,
+		CORSAllowedOrigins:       []string{"*"},
+		DynamoDBAccessKeyID:      "lumadb",
+		DynamoDBSecretAccessKey:  "lumadb-secret",
+		IdempotencyTTLMs:         10 * 60 * 1000,
 		Tiering: TieringConfig{
 			HotPolicy: TierPolicy{
 				Enabled: true,
@@ -35,6 +35,42 @@ extern LumaResult luma_query(LumaHandle handle, const char* collection, const ch
 extern LumaResult luma_batch_insert(LumaHandle handle, const char* collection, const char* docs, size_t* count);
 extern LumaResult luma_search_vector(LumaHandle handle, const char* vector_json, size_t k, LumaBuffer* results_out);
 
+// Cancellable operations: luma_op_new allocates an operation id that the
+// ...Context methods below thread through to their "_op" counterparts, so
+// an in-flight call can be aborted from another goroutine via
+// luma_op_cancel before it returns on its own. Cancellation is idempotent -
+// cancelling twice, or after the op has already completed, is a no-op, so
+// a cancel racing a late completion never double-frees the LumaBuffer the
+// original call is still about to write.
+extern LumaResult luma_op_new(uint64_t* op_id_out);
+extern LumaResult luma_op_cancel(uint64_t op_id);
+
+extern LumaResult luma_insert_op(LumaHandle handle, uint64_t op_id, const char* collection, const char* doc_json, LumaBuffer* id_out);
+extern LumaResult luma_insert_mp_op(LumaHandle handle, uint64_t op_id, const char* collection, const uint8_t* input_data, size_t input_len, LumaBuffer* id_out);
+extern LumaResult luma_get_op(LumaHandle handle, uint64_t op_id, const char* collection, const char* id, LumaBuffer* doc_out);
+extern LumaResult luma_update_op(LumaHandle handle, uint64_t op_id, const char* collection, const char* id, const char* updates);
+extern LumaResult luma_delete_op(LumaHandle handle, uint64_t op_id, const char* collection, const char* id);
+extern LumaResult luma_query_op(LumaHandle handle, uint64_t op_id, const char* collection, const char* query, LumaBuffer* results_out);
+extern LumaResult luma_search_vector_op(LumaHandle handle, uint64_t op_id, const char* vector_json, size_t k, LumaBuffer* results_out);
+extern LumaResult luma_batch_insert_op(LumaHandle handle, uint64_t op_id, const char* collection, const char* docs, size_t* count);
+
+// Cursor operations: a batch-at-a-time alternative to luma_query for
+// result sets too large to buffer into one LumaBuffer. luma_cursor_next_batch
+// signals exhaustion by returning 0 (success) with batch_out left zeroed
+// (data == NULL, len == 0), rather than by returning a distinct LumaResult
+// code - running out of rows isn't an error.
+extern LumaResult luma_query_open(LumaHandle handle, const char* collection, const char* query, LumaHandle* cursor_out);
+extern LumaResult luma_cursor_next_batch(LumaHandle cursor, size_t max_docs, size_t max_bytes, LumaBuffer* batch_out);
+extern LumaResult luma_cursor_close(LumaHandle cursor);
+
+// MessagePack read path: mirrors luma_get/luma_query/luma_stats exactly,
+// except the buffer they write is msgpack-encoded instead of JSON, so Go
+// callers that want msgpack all the way through (see Database.GetMP/
+// GetInto) skip a JSON encode on the Rust side and a JSON decode on ours.
+extern LumaResult luma_get_mp(LumaHandle handle, const char* collection, const char* id, LumaBuffer* doc_out);
+extern LumaResult luma_query_mp(LumaHandle handle, const char* collection, const char* query, LumaBuffer* results_out);
+extern LumaResult luma_stats_mp(LumaHandle handle, LumaBuffer* stats_out);
+
 // Memory management
 extern void luma_buffer_free(LumaBuffer* buffer);
 extern const uint8_t* luma_buffer_data(const LumaBuffer* buffer);
@@ -47,11 +83,14 @@ extern const char* luma_version();
 import "C"
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"sync"
+	"time"
 	"unsafe"
 
+	"github.com/tdb-plus/cluster/pkg/metrics"
 	"github.com/vmihailenco/msgpack/v5"
 )
 
@@ -67,6 +106,65 @@ var (
 	ErrInternal        = errors.New("internal error")
 )
 
+// coreMetrics is nil until SetMetrics is called (normally once, from
+// api.NewServer's MetricsConfig handling), and every Core method is
+// nil-receiver safe, so an uninstrumented process pays no cost and
+// needs no special-casing at call sites.
+var coreMetrics *metrics.Core
+
+// SetMetrics wires m into every subsequent Database operation's
+// duration/error/buffer-size observations.
+func SetMetrics(m *metrics.Core) {
+	coreMetrics = m
+}
+
+// errorKind labels one of this package's sentinel errors for
+// coreMetrics.OpErrors. Unrecognized errors (including nil, for success)
+// map to "" and "other" respectively so a future new sentinel can't
+// panic this path.
+func errorKind(err error) string {
+	switch err {
+	case nil:
+		return ""
+	case ErrInvalidHandle:
+		return "invalid_handle"
+	case ErrInvalidArgument:
+		return "invalid_argument"
+	case ErrNotFound:
+		return "not_found"
+	case ErrAlreadyExists:
+		return "already_exists"
+	case ErrIO:
+		return "io"
+	case ErrCorruption:
+		return "corruption"
+	case ErrFull:
+		return "full"
+	case ErrInternal:
+		return "internal"
+	default:
+		return "other"
+	}
+}
+
+// instrumentedOp runs fn, observing its duration and outcome against
+// coreMetrics under op before returning fn's result unchanged.
+func instrumentedOp[T any](op string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	result, err := fn()
+	coreMetrics.ObserveOp(op, time.Since(start), errorKind(err))
+	return result, err
+}
+
+// instrumentedOpErr is instrumentedOp for the error-only methods
+// (Update, Delete, ...).
+func instrumentedOpErr(op string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	coreMetrics.ObserveOp(op, time.Since(start), errorKind(err))
+	return err
+}
+
 func resultToError(result C.LumaResult) error {
 	switch result {
 	case 0:
@@ -159,30 +257,121 @@ func (db *Database) DropCollection(name string) error {
 	return resultToError(result)
 }
 
+// runWithContext runs call (a single luma_*_op invocation keyed on the
+// returned op id) to completion in its own goroutine, racing it against
+// ctx. If ctx is cancelled or its deadline elapses first, runWithContext
+// asks the Rust core to abort the operation via luma_op_cancel and
+// returns without waiting for call to finish - call keeps running in the
+// background and cleanup still runs once it does, since a cancelled op
+// may already be past the point of no return on the Rust side. This
+// mirrors the deadline-timer pattern used by the netstack gonet adapter:
+// a single cancel channel closed either by ctx.Done or by a
+// time.AfterFunc armed from ctx's deadline.
+func runWithContext(ctx context.Context, call func(opID uint64) error, cleanup func()) error {
+	var opID C.uint64_t
+	if err := resultToError(C.LumaResult(C.luma_op_new(&opID))); err != nil {
+		return err
+	}
+
+	cancelled := make(chan struct{})
+	if deadline, ok := ctx.Deadline(); ok {
+		timer := time.AfterFunc(time.Until(deadline), func() { close(cancelled) })
+		defer timer.Stop()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := call(uint64(opID))
+		if cleanup != nil {
+			cleanup()
+		}
+		done <- err
+	}()
+
+	// Watches for cancellation independently of the select below, so the
+	// op is still aborted even after runWithContext has already returned
+	// ctx.Err() to its caller.
+	go func() {
+		select {
+		case <-ctx.Done():
+			C.luma_op_cancel(opID)
+		case <-cancelled:
+			C.luma_op_cancel(opID)
+		case <-done:
+		}
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-cancelled:
+		return context.DeadlineExceeded
+	}
+}
+
 // Insert inserts a document into a collection
 func (db *Database) Insert(collection string, doc interface{}) (string, error) {
+	return instrumentedOp("insert", func() (string, error) {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+
+		docJSON, err := json.Marshal(doc)
+		if err != nil {
+			return "", err
+		}
+
+		cCollection := C.CString(collection)
+		defer C.free(unsafe.Pointer(cCollection))
+
+		cDoc := C.CString(string(docJSON))
+		defer C.free(unsafe.Pointer(cDoc))
+
+		var idBuf C.LumaBuffer
+		result := C.luma_insert(db.handle, cCollection, cDoc, &idBuf)
+		if err := resultToError(result); err != nil {
+			return "", err
+		}
+		defer C.luma_buffer_free(&idBuf)
+
+		id := C.GoStringN((*C.char)(unsafe.Pointer(idBuf.data)), C.int(idBuf.len))
+		return id, nil
+	})
+}
+
+// InsertContext is Insert, but aborts and returns ctx.Err() if ctx is
+// cancelled or its deadline elapses before the Rust core responds.
+func (db *Database) InsertContext(ctx context.Context, collection string, doc interface{}) (string, error) {
 	db.mu.RLock()
-	defer db.mu.RUnlock()
 
 	docJSON, err := json.Marshal(doc)
 	if err != nil {
+		db.mu.RUnlock()
 		return "", err
 	}
 
 	cCollection := C.CString(collection)
-	defer C.free(unsafe.Pointer(cCollection))
-
 	cDoc := C.CString(string(docJSON))
-	defer C.free(unsafe.Pointer(cDoc))
 
-	var idBuf C.LumaBuffer
-	result := C.luma_insert(db.handle, cCollection, cDoc, &idBuf)
-	if err := resultToError(result); err != nil {
+	var id string
+	err = runWithContext(ctx, func(opID uint64) error {
+		var idBuf C.LumaBuffer
+		result := C.luma_insert_op(db.handle, C.uint64_t(opID), cCollection, cDoc, &idBuf)
+		if err := resultToError(result); err != nil {
+			return err
+		}
+		defer C.luma_buffer_free(&idBuf)
+		id = C.GoStringN((*C.char)(unsafe.Pointer(idBuf.data)), C.int(idBuf.len))
+		return nil
+	}, func() {
+		C.free(unsafe.Pointer(cCollection))
+		C.free(unsafe.Pointer(cDoc))
+		db.mu.RUnlock()
+	})
+	if err != nil {
 		return "", err
 	}
-	defer C.luma_buffer_free(&idBuf)
-
-	id := C.GoStringN((*C.char)(unsafe.Pointer(idBuf.data)), C.int(idBuf.len))
 	return id, nil
 }
 
@@ -216,8 +405,110 @@ func (db *Database) InsertMP(collection string, doc interface{}) (string, error)
 	return id, nil
 }
 
+// InsertMPContext is InsertMP, but aborts and returns ctx.Err() if ctx is
+// cancelled or its deadline elapses before the Rust core responds.
+func (db *Database) InsertMPContext(ctx context.Context, collection string, doc interface{}) (string, error) {
+	db.mu.RLock()
+
+	data, err := msgpack.Marshal(doc)
+	if err != nil {
+		db.mu.RUnlock()
+		return "", err
+	}
+
+	cCollection := C.CString(collection)
+
+	var id string
+	err = runWithContext(ctx, func(opID uint64) error {
+		var idBuf C.LumaBuffer
+		result := C.luma_insert_mp_op(
+			db.handle,
+			C.uint64_t(opID),
+			cCollection,
+			(*C.uint8_t)(unsafe.Pointer(&data[0])),
+			C.size_t(len(data)),
+			&idBuf,
+		)
+		if err := resultToError(result); err != nil {
+			return err
+		}
+		defer C.luma_buffer_free(&idBuf)
+		id = C.GoStringN((*C.char)(unsafe.Pointer(idBuf.data)), C.int(idBuf.len))
+		return nil
+	}, func() {
+		C.free(unsafe.Pointer(cCollection))
+		db.mu.RUnlock()
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
 // Get retrieves a document by ID
 func (db *Database) Get(collection, id string) (map[string]interface{}, error) {
+	return instrumentedOp("get", func() (map[string]interface{}, error) {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+
+		cCollection := C.CString(collection)
+		defer C.free(unsafe.Pointer(cCollection))
+
+		cID := C.CString(id)
+		defer C.free(unsafe.Pointer(cID))
+
+		var docBuf C.LumaBuffer
+		result := C.luma_get(db.handle, cCollection, cID, &docBuf)
+		if err := resultToError(result); err != nil {
+			return nil, err
+		}
+		defer C.luma_buffer_free(&docBuf)
+
+		docJSON := C.GoBytes(unsafe.Pointer(docBuf.data), C.int(docBuf.len))
+		coreMetrics.ObserveBufferBytes("get", len(docJSON))
+		var doc map[string]interface{}
+		if err := json.Unmarshal(docJSON, &doc); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	})
+}
+
+// GetContext is Get, but aborts and returns ctx.Err() if ctx is cancelled
+// or its deadline elapses before the Rust core responds.
+func (db *Database) GetContext(ctx context.Context, collection, id string) (map[string]interface{}, error) {
+	db.mu.RLock()
+
+	cCollection := C.CString(collection)
+	cID := C.CString(id)
+
+	var doc map[string]interface{}
+	err := runWithContext(ctx, func(opID uint64) error {
+		var docBuf C.LumaBuffer
+		result := C.luma_get_op(db.handle, C.uint64_t(opID), cCollection, cID, &docBuf)
+		if err := resultToError(result); err != nil {
+			return err
+		}
+		defer C.luma_buffer_free(&docBuf)
+
+		docJSON := C.GoBytes(unsafe.Pointer(docBuf.data), C.int(docBuf.len))
+		return json.Unmarshal(docJSON, &doc)
+	}, func() {
+		C.free(unsafe.Pointer(cCollection))
+		C.free(unsafe.Pointer(cID))
+		db.mu.RUnlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// GetMP is Get, but returns the document as a raw msgpack-encoded
+// payload instead of decoding it into a map - see luma_get_mp. Callers
+// that know the shape they want can feed this straight to msgpack.Unmarshal
+// (GetInto does exactly that) and skip Get's intermediate JSON decode.
+func (db *Database) GetMP(collection, id string) ([]byte, error) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
@@ -228,60 +519,187 @@ func (db *Database) Get(collection, id string) (map[string]interface{}, error) {
 	defer C.free(unsafe.Pointer(cID))
 
 	var docBuf C.LumaBuffer
-	result := C.luma_get(db.handle, cCollection, cID, &docBuf)
+	result := C.luma_get_mp(db.handle, cCollection, cID, &docBuf)
 	if err := resultToError(result); err != nil {
 		return nil, err
 	}
 	defer C.luma_buffer_free(&docBuf)
 
-	docJSON := C.GoBytes(unsafe.Pointer(docBuf.data), C.int(docBuf.len))
-	var doc map[string]interface{}
-	if err := json.Unmarshal(docJSON, &doc); err != nil {
-		return nil, err
+	return C.GoBytes(unsafe.Pointer(docBuf.data), C.int(docBuf.len)), nil
+}
+
+// GetInto fetches the document at collection/id via GetMP and decodes it
+// directly into dst with msgpack.Unmarshal, so a caller that already knows
+// the document's shape never pays for Get's map[string]interface{} +
+// re-marshal round trip.
+func GetInto[T any](db *Database, collection, id string, dst *T) error {
+	data, err := db.GetMP(collection, id)
+	if err != nil {
+		return err
 	}
-	return doc, nil
+	return msgpack.Unmarshal(data, dst)
 }
 
 // Update updates a document by ID
 func (db *Database) Update(collection, id string, updates interface{}) error {
+	return instrumentedOpErr("update", func() error {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+
+		updatesJSON, err := json.Marshal(updates)
+		if err != nil {
+			return err
+		}
+
+		cCollection := C.CString(collection)
+		defer C.free(unsafe.Pointer(cCollection))
+
+		cID := C.CString(id)
+		defer C.free(unsafe.Pointer(cID))
+
+		cUpdates := C.CString(string(updatesJSON))
+		defer C.free(unsafe.Pointer(cUpdates))
+
+		result := C.luma_update(db.handle, cCollection, cID, cUpdates)
+		return resultToError(result)
+	})
+}
+
+// UpdateContext is Update, but aborts and returns ctx.Err() if ctx is
+// cancelled or its deadline elapses before the Rust core responds.
+func (db *Database) UpdateContext(ctx context.Context, collection, id string, updates interface{}) error {
 	db.mu.RLock()
-	defer db.mu.RUnlock()
 
 	updatesJSON, err := json.Marshal(updates)
 	if err != nil {
+		db.mu.RUnlock()
 		return err
 	}
 
 	cCollection := C.CString(collection)
-	defer C.free(unsafe.Pointer(cCollection))
-
 	cID := C.CString(id)
-	defer C.free(unsafe.Pointer(cID))
-
 	cUpdates := C.CString(string(updatesJSON))
-	defer C.free(unsafe.Pointer(cUpdates))
 
-	result := C.luma_update(db.handle, cCollection, cID, cUpdates)
-	return resultToError(result)
+	return runWithContext(ctx, func(opID uint64) error {
+		result := C.luma_update_op(db.handle, C.uint64_t(opID), cCollection, cID, cUpdates)
+		return resultToError(result)
+	}, func() {
+		C.free(unsafe.Pointer(cCollection))
+		C.free(unsafe.Pointer(cID))
+		C.free(unsafe.Pointer(cUpdates))
+		db.mu.RUnlock()
+	})
 }
 
 // Delete removes a document by ID
 func (db *Database) Delete(collection, id string) error {
+	return instrumentedOpErr("delete", func() error {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+
+		cCollection := C.CString(collection)
+		defer C.free(unsafe.Pointer(cCollection))
+
+		cID := C.CString(id)
+		defer C.free(unsafe.Pointer(cID))
+
+		result := C.luma_delete(db.handle, cCollection, cID)
+		return resultToError(result)
+	})
+}
+
+// DeleteContext is Delete, but aborts and returns ctx.Err() if ctx is
+// cancelled or its deadline elapses before the Rust core responds.
+func (db *Database) DeleteContext(ctx context.Context, collection, id string) error {
 	db.mu.RLock()
-	defer db.mu.RUnlock()
 
 	cCollection := C.CString(collection)
-	defer C.free(unsafe.Pointer(cCollection))
-
 	cID := C.CString(id)
-	defer C.free(unsafe.Pointer(cID))
 
-	result := C.luma_delete(db.handle, cCollection, cID)
-	return resultToError(result)
+	return runWithContext(ctx, func(opID uint64) error {
+		result := C.luma_delete_op(db.handle, C.uint64_t(opID), cCollection, cID)
+		return resultToError(result)
+	}, func() {
+		C.free(unsafe.Pointer(cCollection))
+		C.free(unsafe.Pointer(cID))
+		db.mu.RUnlock()
+	})
 }
 
 // Query executes a query on a collection
 func (db *Database) Query(collection string, query interface{}) ([]map[string]interface{}, error) {
+	return instrumentedOp("query", func() ([]map[string]interface{}, error) {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+
+		queryJSON, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+
+		cCollection := C.CString(collection)
+		defer C.free(unsafe.Pointer(cCollection))
+
+		cQuery := C.CString(string(queryJSON))
+		defer C.free(unsafe.Pointer(cQuery))
+
+		var resultsBuf C.LumaBuffer
+		result := C.luma_query(db.handle, cCollection, cQuery, &resultsBuf)
+		if err := resultToError(result); err != nil {
+			return nil, err
+		}
+		defer C.luma_buffer_free(&resultsBuf)
+
+		resultsJSON := C.GoBytes(unsafe.Pointer(resultsBuf.data), C.int(resultsBuf.len))
+		coreMetrics.ObserveBufferBytes("query", len(resultsJSON))
+		var results []map[string]interface{}
+		if err := json.Unmarshal(resultsJSON, &results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	})
+}
+
+// QueryContext is Query, but aborts and returns ctx.Err() if ctx is
+// cancelled or its deadline elapses before the Rust core responds.
+func (db *Database) QueryContext(ctx context.Context, collection string, query interface{}) ([]map[string]interface{}, error) {
+	db.mu.RLock()
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		db.mu.RUnlock()
+		return nil, err
+	}
+
+	cCollection := C.CString(collection)
+	cQuery := C.CString(string(queryJSON))
+
+	var results []map[string]interface{}
+	err = runWithContext(ctx, func(opID uint64) error {
+		var resultsBuf C.LumaBuffer
+		result := C.luma_query_op(db.handle, C.uint64_t(opID), cCollection, cQuery, &resultsBuf)
+		if err := resultToError(result); err != nil {
+			return err
+		}
+		defer C.luma_buffer_free(&resultsBuf)
+
+		resultsJSON := C.GoBytes(unsafe.Pointer(resultsBuf.data), C.int(resultsBuf.len))
+		return json.Unmarshal(resultsJSON, &results)
+	}, func() {
+		C.free(unsafe.Pointer(cCollection))
+		C.free(unsafe.Pointer(cQuery))
+		db.mu.RUnlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// QueryMP is Query, but returns the result set as a raw msgpack-encoded
+// payload instead of decoding it into []map[string]interface{} - see
+// luma_query_mp. QueryInto feeds this straight to msgpack.Unmarshal.
+func (db *Database) QueryMP(collection string, query interface{}) ([]byte, error) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
@@ -297,43 +715,224 @@ func (db *Database) Query(collection string, query interface{}) ([]map[string]in
 	defer C.free(unsafe.Pointer(cQuery))
 
 	var resultsBuf C.LumaBuffer
-	result := C.luma_query(db.handle, cCollection, cQuery, &resultsBuf)
+	result := C.luma_query_mp(db.handle, cCollection, cQuery, &resultsBuf)
 	if err := resultToError(result); err != nil {
 		return nil, err
 	}
 	defer C.luma_buffer_free(&resultsBuf)
 
-	resultsJSON := C.GoBytes(unsafe.Pointer(resultsBuf.data), C.int(resultsBuf.len))
-	var results []map[string]interface{}
-	if err := json.Unmarshal(resultsJSON, &results); err != nil {
-		return nil, err
+	return C.GoBytes(unsafe.Pointer(resultsBuf.data), C.int(resultsBuf.len)), nil
+}
+
+// QueryInto runs query against collection via QueryMP and decodes the
+// result set directly into dst with msgpack.Unmarshal, so a caller that
+// already knows the shape of its documents never pays for Query's
+// []map[string]interface{} + re-marshal round trip.
+func QueryInto[T any](db *Database, collection string, query interface{}, dst *[]T) error {
+	data, err := db.QueryMP(collection, query)
+	if err != nil {
+		return err
 	}
-	return results, nil
+	return msgpack.Unmarshal(data, dst)
 }
 
-// VectorSearch searches for similar vectors
-func (db *Database) VectorSearch(vector []float32, k int) ([]map[string]interface{}, error) {
+// cursorMaxDocsPerBatch and cursorMaxBytesPerBatch bound a single
+// luma_cursor_next_batch call, so one batch never grows large enough to
+// defeat the point of streaming instead of using Query.
+const (
+	cursorMaxDocsPerBatch  = 256
+	cursorMaxBytesPerBatch = 4 << 20 // 4MiB
+)
+
+// Cursor iterates a query's results in batches fetched from the Rust core
+// as they're consumed, rather than buffering every matching document into
+// one LumaBuffer up front the way Query does - the shape large result
+// sets need to move through a collection without OOMing the process.
+type Cursor struct {
+	handle C.LumaHandle
+	closed bool
+	err    error
+	batch  []json.RawMessage
+	pos    int
+	row    json.RawMessage
+}
+
+// QueryCursor opens a Cursor over collection matching query.
+func (db *Database) QueryCursor(collection string, query interface{}) (*Cursor, error) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
-	vectorJSON, err := json.Marshal(vector)
+	if db.closed {
+		return nil, ErrInvalidHandle
+	}
+
+	queryJSON, err := json.Marshal(query)
 	if err != nil {
 		return nil, err
 	}
 
-	cVector := C.CString(string(vectorJSON))
-	defer C.free(unsafe.Pointer(cVector))
+	cCollection := C.CString(collection)
+	defer C.free(unsafe.Pointer(cCollection))
 
-	var resultsBuf C.LumaBuffer
-	result := C.luma_search_vector(db.handle, cVector, C.size_t(k), &resultsBuf)
+	cQuery := C.CString(string(queryJSON))
+	defer C.free(unsafe.Pointer(cQuery))
+
+	var cursorHandle C.LumaHandle
+	result := C.luma_query_open(db.handle, cCollection, cQuery, &cursorHandle)
 	if err := resultToError(result); err != nil {
 		return nil, err
 	}
-	defer C.luma_buffer_free(&resultsBuf)
+	return &Cursor{handle: cursorHandle}, nil
+}
+
+// Next advances the cursor to the next row, fetching a fresh batch from
+// luma_cursor_next_batch once the current one is exhausted. It returns
+// false once the cursor is exhausted, ctx is done, or an error occurs;
+// check ctx.Err() and Err() to tell the three apart.
+func (c *Cursor) Next(ctx context.Context) bool {
+	if c.closed || c.err != nil {
+		return false
+	}
+
+	if c.pos < len(c.batch) {
+		c.row = c.batch[c.pos]
+		c.pos++
+		return true
+	}
+
+	type fetchResult struct {
+		batch []json.RawMessage
+		err   error
+	}
+	fetched := make(chan fetchResult, 1)
+	go func() {
+		var batchBuf C.LumaBuffer
+		result := C.luma_cursor_next_batch(c.handle, C.size_t(cursorMaxDocsPerBatch), C.size_t(cursorMaxBytesPerBatch), &batchBuf)
+		if err := resultToError(result); err != nil {
+			fetched <- fetchResult{err: err}
+			return
+		}
+		defer C.luma_buffer_free(&batchBuf)
+		if batchBuf.data == nil || batchBuf.len == 0 {
+			fetched <- fetchResult{}
+			return
+		}
+
+		batchJSON := C.GoBytes(unsafe.Pointer(batchBuf.data), C.int(batchBuf.len))
+		var batch []json.RawMessage
+		if err := json.Unmarshal(batchJSON, &batch); err != nil {
+			fetched <- fetchResult{err: err}
+			return
+		}
+		fetched <- fetchResult{batch: batch}
+	}()
+
+	select {
+	case r := <-fetched:
+		if r.err != nil {
+			c.err = r.err
+			return false
+		}
+		if len(r.batch) == 0 {
+			return false
+		}
+		c.batch = r.batch
+		c.pos = 1
+		c.row = c.batch[0]
+		return true
+	case <-ctx.Done():
+		c.err = ctx.Err()
+		return false
+	}
+}
+
+// Scan unmarshals the row fetched by the most recent Next into dst.
+func (c *Cursor) Scan(dst interface{}) error {
+	if c.row == nil {
+		return errors.New("core: Scan called without a successful Next")
+	}
+	return json.Unmarshal(c.row, dst)
+}
+
+// Err returns the error, if any, that caused Next to return false. If ctx
+// was done, Err returns ctx.Err(). A nil Err after Next returns false
+// means the cursor was simply exhausted.
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Close releases the cursor's handle in the Rust core. Safe to call more
+// than once.
+func (c *Cursor) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	result := C.luma_cursor_close(c.handle)
+	return resultToError(result)
+}
+
+// VectorSearch searches for similar vectors
+func (db *Database) VectorSearch(vector []float32, k int) ([]map[string]interface{}, error) {
+	return instrumentedOp("vector_search", func() ([]map[string]interface{}, error) {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+
+		vectorJSON, err := json.Marshal(vector)
+		if err != nil {
+			return nil, err
+		}
+
+		cVector := C.CString(string(vectorJSON))
+		defer C.free(unsafe.Pointer(cVector))
+
+		var resultsBuf C.LumaBuffer
+		result := C.luma_search_vector(db.handle, cVector, C.size_t(k), &resultsBuf)
+		if err := resultToError(result); err != nil {
+			return nil, err
+		}
+		defer C.luma_buffer_free(&resultsBuf)
+
+		resultsJSON := C.GoBytes(unsafe.Pointer(resultsBuf.data), C.int(resultsBuf.len))
+		coreMetrics.ObserveBufferBytes("vector_search", len(resultsJSON))
+		var results []map[string]interface{}
+		if err := json.Unmarshal(resultsJSON, &results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	})
+}
+
+// VectorSearchContext is VectorSearch, but aborts and returns ctx.Err()
+// if ctx is cancelled or its deadline elapses before the Rust core
+// responds.
+func (db *Database) VectorSearchContext(ctx context.Context, vector []float32, k int) ([]map[string]interface{}, error) {
+	db.mu.RLock()
+
+	vectorJSON, err := json.Marshal(vector)
+	if err != nil {
+		db.mu.RUnlock()
+		return nil, err
+	}
+
+	cVector := C.CString(string(vectorJSON))
 
-	resultsJSON := C.GoBytes(unsafe.Pointer(resultsBuf.data), C.int(resultsBuf.len))
 	var results []map[string]interface{}
-	if err := json.Unmarshal(resultsJSON, &results); err != nil {
+	err = runWithContext(ctx, func(opID uint64) error {
+		var resultsBuf C.LumaBuffer
+		result := C.luma_search_vector_op(db.handle, C.uint64_t(opID), cVector, C.size_t(k), &resultsBuf)
+		if err := resultToError(result); err != nil {
+			return err
+		}
+		defer C.luma_buffer_free(&resultsBuf)
+
+		resultsJSON := C.GoBytes(unsafe.Pointer(resultsBuf.data), C.int(resultsBuf.len))
+		return json.Unmarshal(resultsJSON, &results)
+	}, func() {
+		C.free(unsafe.Pointer(cVector))
+		db.mu.RUnlock()
+	})
+	if err != nil {
 		return nil, err
 	}
 	return results, nil
@@ -341,46 +940,101 @@ func (db *Database) VectorSearch(vector []float32, k int) ([]map[string]interfac
 
 // BatchInsert inserts multiple documents
 func (db *Database) BatchInsert(collection string, docs []interface{}) (int, error) {
+	return instrumentedOp("batch_insert", func() (int, error) {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+
+		docsJSON, err := json.Marshal(docs)
+		if err != nil {
+			return 0, err
+		}
+
+		cCollection := C.CString(collection)
+		defer C.free(unsafe.Pointer(cCollection))
+
+		cDocs := C.CString(string(docsJSON))
+		defer C.free(unsafe.Pointer(cDocs))
+
+		var count C.size_t
+		result := C.luma_batch_insert(db.handle, cCollection, cDocs, &count)
+		if err := resultToError(result); err != nil {
+			return 0, err
+		}
+		return int(count), nil
+	})
+}
+
+// BatchInsertContext is BatchInsert, but aborts and returns ctx.Err() if
+// ctx is cancelled or its deadline elapses before the Rust core responds.
+func (db *Database) BatchInsertContext(ctx context.Context, collection string, docs []interface{}) (int, error) {
 	db.mu.RLock()
-	defer db.mu.RUnlock()
 
 	docsJSON, err := json.Marshal(docs)
 	if err != nil {
+		db.mu.RUnlock()
 		return 0, err
 	}
 
 	cCollection := C.CString(collection)
-	defer C.free(unsafe.Pointer(cCollection))
-
 	cDocs := C.CString(string(docsJSON))
-	defer C.free(unsafe.Pointer(cDocs))
 
-	var count C.size_t
-	result := C.luma_batch_insert(db.handle, cCollection, cDocs, &count)
-	if err := resultToError(result); err != nil {
+	var count int
+	err = runWithContext(ctx, func(opID uint64) error {
+		var cCount C.size_t
+		result := C.luma_batch_insert_op(db.handle, C.uint64_t(opID), cCollection, cDocs, &cCount)
+		if err := resultToError(result); err != nil {
+			return err
+		}
+		count = int(cCount)
+		return nil
+	}, func() {
+		C.free(unsafe.Pointer(cCollection))
+		C.free(unsafe.Pointer(cDocs))
+		db.mu.RUnlock()
+	})
+	if err != nil {
 		return 0, err
 	}
-	return int(count), nil
+	return count, nil
 }
 
 // Stats returns database statistics
 func (db *Database) Stats() (map[string]interface{}, error) {
+	return instrumentedOp("stats", func() (map[string]interface{}, error) {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+
+		var statsBuf C.LumaBuffer
+		result := C.luma_stats(db.handle, &statsBuf)
+		if err := resultToError(result); err != nil {
+			return nil, err
+		}
+		defer C.luma_buffer_free(&statsBuf)
+
+		statsJSON := C.GoBytes(unsafe.Pointer(statsBuf.data), C.int(statsBuf.len))
+		coreMetrics.ObserveBufferBytes("stats", len(statsJSON))
+		var stats map[string]interface{}
+		if err := json.Unmarshal(statsJSON, &stats); err != nil {
+			return nil, err
+		}
+		return stats, nil
+	})
+}
+
+// StatsMP is Stats, but returns the statistics as a raw msgpack-encoded
+// payload instead of decoding them into a map - see luma_stats_mp.
+func (db *Database) StatsMP() ([]byte, error) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
 	var statsBuf C.LumaBuffer
-	result := C.luma_stats(db.handle, &statsBuf)
+	result := C.luma_stats_mp(db.handle, &statsBuf)
 	if err := resultToError(result); err != nil {
 		return nil, err
 	}
 	defer C.luma_buffer_free(&statsBuf)
 
-	statsJSON := C.GoBytes(unsafe.Pointer(statsBuf.data), C.int(statsBuf.len))
-	var stats map[string]interface{}
-	if err := json.Unmarshal(statsJSON, &stats); err != nil {
-		return nil, err
-	}
-	return stats, nil
+	return C.GoBytes(unsafe.Pointer(statsBuf.data), C.int(statsBuf.len)), nil
 }
 
 // Version returns the TDB+ version
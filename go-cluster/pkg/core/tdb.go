@@ -35,6 +35,15 @@ extern TdbResult tdb_delete(TdbHandle handle, const char* collection, const char
 extern TdbResult tdb_query(TdbHandle handle, const char* collection, const char* query, TdbBuffer* results_out);
 extern TdbResult tdb_batch_insert(TdbHandle handle, const char* collection, const char* docs, size_t* count);
 
+// Cursor operations: a row-at-a-time alternative to tdb_query for result
+// sets too large to buffer into one TdbBuffer. tdb_cursor_next signals
+// "no more rows" by returning 0 (success) with row_out left zeroed
+// (data == NULL, len == 0), rather than by returning a distinct TdbResult
+// code - exhaustion isn't an error.
+extern TdbResult tdb_cursor_open(TdbHandle handle, const char* collection, const char* query, TdbHandle* cursor_out);
+extern TdbResult tdb_cursor_next(TdbHandle cursor, TdbBuffer* row_out);
+extern TdbResult tdb_cursor_close(TdbHandle cursor);
+
 // Memory management
 extern void tdb_buffer_free(TdbBuffer* buffer);
 extern const uint8_t* tdb_buffer_data(const TdbBuffer* buffer);
@@ -49,6 +58,7 @@ import "C"
 import (
 	"encoding/json"
 	"errors"
+	"runtime"
 	"sync"
 	"unsafe"
 )
@@ -303,6 +313,135 @@ func (db *Database) BatchInsert(collection string, docs []interface{}) (int, err
 	return int(count), nil
 }
 
+// InsertResult is one document's outcome from BatchInsertStream: its
+// generated ID on success, or the error that occurred inserting it.
+type InsertResult struct {
+	ID  string
+	Err error
+}
+
+// BatchInsertStream inserts docs into collection one at a time as they
+// arrive on docs, reporting each outcome on the returned channel in the
+// same order. Unlike BatchInsert, which marshals the whole slice into a
+// single JSON blob for one tdb_batch_insert call, BatchInsertStream never
+// holds more than one document in memory at a time - the shape
+// replication/export jobs need to move collections with millions of
+// documents without OOM (see pkg/platform/replication).
+func (db *Database) BatchInsertStream(collection string, docs <-chan interface{}) (<-chan InsertResult, error) {
+	db.mu.RLock()
+	if db.closed {
+		db.mu.RUnlock()
+		return nil, ErrInvalidHandle
+	}
+	db.mu.RUnlock()
+
+	results := make(chan InsertResult)
+	go func() {
+		defer close(results)
+		for doc := range docs {
+			id, err := db.Insert(collection, doc)
+			results <- InsertResult{ID: id, Err: err}
+		}
+	}()
+	return results, nil
+}
+
+// Cursor iterates a query's results row-at-a-time via tdb_cursor_open/
+// tdb_cursor_next/tdb_cursor_close, rather than buffering every matching
+// document into one TdbBuffer the way Query does - the shape
+// replication/export jobs need to move millions of documents without
+// holding them all in memory.
+type Cursor struct {
+	handle C.TdbHandle
+	closed bool
+	err    error
+	row    []byte
+}
+
+// QueryCursor opens a Cursor over collection matching query.
+func (db *Database) QueryCursor(collection string, query interface{}) (*Cursor, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, ErrInvalidHandle
+	}
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	queryJSON = append(queryJSON, 0) // NUL-terminate for direct use as const char*
+
+	cCollection := C.CString(collection)
+	defer C.free(unsafe.Pointer(cCollection))
+
+	// Pass the marshaled query by pointer instead of copying it through
+	// C.CString - cursor queries can carry large filter payloads, and
+	// avoiding that copy is exactly what QueryCursor exists for. The pin
+	// only needs to last for this one call: tdb_cursor_open reads the
+	// bytes synchronously before returning.
+	var pinner runtime.Pinner
+	pinner.Pin(&queryJSON[0])
+	cQuery := (*C.char)(unsafe.Pointer(&queryJSON[0]))
+
+	var cursorHandle C.TdbHandle
+	result := C.tdb_cursor_open(db.handle, cCollection, cQuery, &cursorHandle)
+	pinner.Unpin()
+	if err := resultToError(result); err != nil {
+		return nil, err
+	}
+	return &Cursor{handle: cursorHandle}, nil
+}
+
+// Next advances the cursor to the next row, fetched one at a time from
+// tdb_cursor_next rather than all at once. It returns false once the
+// cursor is exhausted or after an error; check Err in either case.
+func (c *Cursor) Next() bool {
+	if c.closed || c.err != nil {
+		return false
+	}
+
+	var rowBuf C.TdbBuffer
+	result := C.tdb_cursor_next(c.handle, &rowBuf)
+	if err := resultToError(result); err != nil {
+		c.err = err
+		return false
+	}
+	if rowBuf.data == nil || rowBuf.len == 0 {
+		return false
+	}
+	defer C.tdb_buffer_free(&rowBuf)
+
+	c.row = C.GoBytes(unsafe.Pointer(rowBuf.data), C.int(rowBuf.len))
+	return true
+}
+
+// Scan unmarshals the row fetched by the most recent Next into dst.
+func (c *Cursor) Scan(dst interface{}) error {
+	if c.row == nil {
+		return errors.New("core: Scan called without a successful Next")
+	}
+	return json.Unmarshal(c.row, dst)
+}
+
+// Err returns the error, if any, that caused Next to return false. A nil
+// Err after Next returns false means the cursor was simply exhausted.
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Close releases the cursor's handle in the Rust core. Safe to call more
+// than once.
+func (c *Cursor) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	result := C.tdb_cursor_close(c.handle)
+	return resultToError(result)
+}
+
 // Stats returns database statistics
 func (db *Database) Stats() (map[string]interface{}, error) {
 	db.mu.RLock()
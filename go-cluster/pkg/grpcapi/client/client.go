@@ -0,0 +1,202 @@
+// Package client is the gRPC counterpart to the dynamodb/REST clients in
+// pkg/api: a pooled ClusterServiceClient that retries once against the
+// leader when a call comes back as a *tdbplusv1.NotLeaderError, mirroring
+// how pkg/router.Router forwards writes to the leader on the HTTP side.
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/tdb-plus/cluster/pkg/pb/tdbplusv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Client is a pooled tdbplusv1.ClusterServiceClient. It keeps at most one
+// *grpc.ClientConn per address and transparently retries a call against
+// the address reported by a NotLeaderError, so callers never have to
+// handle leader-forwarding themselves.
+type Client struct {
+	dialOpts []grpc.DialOption
+
+	mu    sync.RWMutex
+	conns map[string]*grpc.ClientConn
+}
+
+// New builds a Client. dialOpts are applied to every connection the pool
+// creates. With no dialOpts, connections use
+// grpc.WithTransportCredentials(insecure.NewCredentials()), i.e. no TLS.
+func New(dialOpts ...grpc.DialOption) *Client {
+	if len(dialOpts) == 0 {
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	return &Client{
+		dialOpts: dialOpts,
+		conns:    make(map[string]*grpc.ClientConn),
+	}
+}
+
+func newStub(conn *grpc.ClientConn) tdbplusv1.ClusterServiceClient {
+	return tdbplusv1.NewClusterServiceClient(conn)
+}
+
+// connFor returns the pooled connection for addr, dialing lazily on
+// first use the same way router.getOrCreatePool does for its HTTP
+// connection pools.
+func (c *Client) connFor(addr string) (*grpc.ClientConn, error) {
+	c.mu.RLock()
+	conn, ok := c.conns[addr]
+	c.mu.RUnlock()
+	if ok {
+		return conn, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if conn, ok = c.conns[addr]; ok {
+		return conn, nil
+	}
+
+	conn, err := grpc.Dial(addr, c.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c.conns[addr] = conn
+	return conn, nil
+}
+
+// withLeaderRetry calls fn against addr, and if fn fails with a
+// *tdbplusv1.NotLeaderError carrying a different address, retries once
+// against that address. A second NotLeaderError is returned as-is rather
+// than chased indefinitely, to avoid bouncing forever during an election.
+func (c *Client) withLeaderRetry(ctx context.Context, addr string, fn func(tdbplusv1.ClusterServiceClient) error) error {
+	conn, err := c.connFor(addr)
+	if err != nil {
+		return err
+	}
+	err = fn(newStub(conn))
+	notLeader, ok := err.(*tdbplusv1.NotLeaderError)
+	if !ok && err != nil {
+		// Over the wire a NotLeaderError arrives as a gRPC status whose
+		// message is the server-side Error() text; recover LeaderAddr from it.
+		if st, isStatus := status.FromError(err); isStatus {
+			if leader, found := strings.CutPrefix(st.Message(), "not leader: leader is "); found {
+				notLeader, ok = &tdbplusv1.NotLeaderError{LeaderAddr: leader}, true
+			}
+		}
+	}
+	if !ok || notLeader.LeaderAddr == "" || notLeader.LeaderAddr == addr {
+		return err
+	}
+
+	leaderConn, err := c.connFor(notLeader.LeaderAddr)
+	if err != nil {
+		return err
+	}
+	return fn(newStub(leaderConn))
+}
+
+// Close tears down every pooled connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var firstErr error
+	for addr, conn := range c.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.conns, addr)
+	}
+	return firstErr
+}
+
+func (c *Client) Insert(ctx context.Context, addr string, req *tdbplusv1.InsertRequest) (resp *tdbplusv1.InsertResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.Insert(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) Get(ctx context.Context, addr string, req *tdbplusv1.GetRequest) (resp *tdbplusv1.GetResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.Get(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) Update(ctx context.Context, addr string, req *tdbplusv1.UpdateRequest) (resp *tdbplusv1.UpdateResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.Update(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) Delete(ctx context.Context, addr string, req *tdbplusv1.DeleteRequest) (resp *tdbplusv1.DeleteResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.Delete(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) VectorSearch(ctx context.Context, addr string, req *tdbplusv1.VectorSearchRequest) (resp *tdbplusv1.VectorSearchResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.VectorSearch(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) CreateCollection(ctx context.Context, addr string, req *tdbplusv1.CreateCollectionRequest) (resp *tdbplusv1.CreateCollectionResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.CreateCollection(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) DropCollection(ctx context.Context, addr string, req *tdbplusv1.DropCollectionRequest) (resp *tdbplusv1.DropCollectionResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.DropCollection(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) ListCollections(ctx context.Context, addr string, req *tdbplusv1.ListCollectionsRequest) (resp *tdbplusv1.ListCollectionsResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.ListCollections(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) Stats(ctx context.Context, addr string, req *tdbplusv1.StatsRequest) (resp *tdbplusv1.StatsResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.Stats(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) GetTopology(ctx context.Context, addr string, req *tdbplusv1.GetTopologyRequest) (resp *tdbplusv1.GetTopologyResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.GetTopology(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) Health(ctx context.Context, addr string, req *tdbplusv1.HealthRequest) (resp *tdbplusv1.HealthResponse, err error) {
+	err = c.withLeaderRetry(ctx, addr, func(stub tdbplusv1.ClusterServiceClient) error {
+		resp, err = stub.Health(ctx, req)
+		return err
+	})
+	return resp, err
+}
@@ -0,0 +1,229 @@
+// Package grpcapi implements tdbplusv1.ClusterServiceServer against
+// cluster.Node and router.Router, the gRPC counterpart to pkg/api's HTTP
+// handlers. Writes (and Linearizable reads) that land on a non-leader
+// node fail with *tdbplusv1.NotLeaderError instead of the HTTP API's
+// redirect, so callers use this package's client subpackage to retry
+// against the leader transparently.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/router"
+	"github.com/tdb-plus/cluster/pkg/pb/tdbplusv1"
+	"go.uber.org/zap"
+)
+
+// Server implements tdbplusv1.ClusterServiceServer.
+type Server struct {
+	node   *cluster.Node
+	router *router.Router
+	logger *zap.Logger
+}
+
+// NewServer builds a Server wrapping node and rtr. Register it onto a
+// *grpc.Server with tdbplusv1.RegisterClusterServiceServer.
+func NewServer(node *cluster.Node, rtr *router.Router, logger *zap.Logger) *Server {
+	return &Server{node: node, router: rtr, logger: logger}
+}
+
+// requireLeader returns *tdbplusv1.NotLeaderError when this node isn't
+// the raft leader, the same check RouteWrite uses before accepting a
+// write.
+func (s *Server) requireLeader() error {
+	if s.node.IsLeader() {
+		return nil
+	}
+	return &tdbplusv1.NotLeaderError{LeaderAddr: s.node.LeaderAddr()}
+}
+
+func (s *Server) Insert(ctx context.Context, req *tdbplusv1.InsertRequest) (*tdbplusv1.InsertResponse, error) {
+	if err := s.requireLeader(); err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(req.Json, &doc); err != nil {
+		return nil, err
+	}
+	id, err := s.node.InsertDocument(req.Collection, doc)
+	if err != nil {
+		return nil, err
+	}
+	return &tdbplusv1.InsertResponse{Id: id}, nil
+}
+
+func (s *Server) Get(ctx context.Context, req *tdbplusv1.GetRequest) (*tdbplusv1.GetResponse, error) {
+	consistency := cluster.Stale
+	if req.Consistency == "linearizable" {
+		consistency = cluster.Linearizable
+	}
+	doc, err := s.node.GetDocumentContext(ctx, req.Collection, req.Id, consistency)
+	if err != nil {
+		if notLeader, ok := err.(*cluster.ErrNotLeader); ok {
+			return nil, &tdbplusv1.NotLeaderError{LeaderAddr: notLeader.Leader}
+		}
+		return nil, err
+	}
+	docJSON, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &tdbplusv1.GetResponse{Json: docJSON}, nil
+}
+
+func (s *Server) Update(ctx context.Context, req *tdbplusv1.UpdateRequest) (*tdbplusv1.UpdateResponse, error) {
+	if err := s.requireLeader(); err != nil {
+		return nil, err
+	}
+	var updates map[string]interface{}
+	if err := json.Unmarshal(req.Json, &updates); err != nil {
+		return nil, err
+	}
+	if err := s.node.UpdateDocument(req.Collection, req.Id, updates); err != nil {
+		return nil, err
+	}
+	return &tdbplusv1.UpdateResponse{}, nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *tdbplusv1.DeleteRequest) (*tdbplusv1.DeleteResponse, error) {
+	if err := s.requireLeader(); err != nil {
+		return nil, err
+	}
+	if err := s.node.DeleteDocument(req.Collection, req.Id); err != nil {
+		return nil, err
+	}
+	return &tdbplusv1.DeleteResponse{}, nil
+}
+
+func (s *Server) Query(req *tdbplusv1.QueryRequest, stream tdbplusv1.QueryServer) error {
+	var filter interface{}
+	if len(req.FilterJson) > 0 {
+		if err := json.Unmarshal(req.FilterJson, &filter); err != nil {
+			return err
+		}
+	}
+	cursor, err := s.node.GetDatabase().QueryCursor(req.Collection, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	ctx := stream.Context()
+	for cursor.Next(ctx) {
+		var doc map[string]interface{}
+		if err := cursor.Scan(&doc); err != nil {
+			return err
+		}
+		docJSON, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		id, _ := doc["id"].(string)
+		if err := stream.Send(&tdbplusv1.Document{Id: id, Json: docJSON}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (s *Server) BatchInsert(stream tdbplusv1.BatchInsertServer) error {
+	if err := s.requireLeader(); err != nil {
+		return err
+	}
+
+	var docs []interface{}
+	var collection string
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		collection = req.Collection
+		var doc map[string]interface{}
+		if err := json.Unmarshal(req.Json, &doc); err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	inserted, err := s.node.GetDatabase().BatchInsertContext(stream.Context(), collection, docs)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&tdbplusv1.BatchInsertResponse{Inserted: int64(inserted)})
+}
+
+func (s *Server) VectorSearch(ctx context.Context, req *tdbplusv1.VectorSearchRequest) (*tdbplusv1.VectorSearchResponse, error) {
+	results, err := s.node.GetDatabase().VectorSearchContext(ctx, req.Vector, int(req.TopK))
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]*tdbplusv1.Document, 0, len(results))
+	for _, doc := range results {
+		docJSON, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		id, _ := doc["id"].(string)
+		matches = append(matches, &tdbplusv1.Document{Id: id, Json: docJSON})
+	}
+	return &tdbplusv1.VectorSearchResponse{Matches: matches}, nil
+}
+
+func (s *Server) CreateCollection(ctx context.Context, req *tdbplusv1.CreateCollectionRequest) (*tdbplusv1.CreateCollectionResponse, error) {
+	if err := s.requireLeader(); err != nil {
+		return nil, err
+	}
+	if err := s.node.GetDatabase().CreateCollection(req.Name); err != nil {
+		return nil, err
+	}
+	return &tdbplusv1.CreateCollectionResponse{}, nil
+}
+
+func (s *Server) DropCollection(ctx context.Context, req *tdbplusv1.DropCollectionRequest) (*tdbplusv1.DropCollectionResponse, error) {
+	if err := s.requireLeader(); err != nil {
+		return nil, err
+	}
+	if err := s.node.GetDatabase().DropCollection(req.Name); err != nil {
+		return nil, err
+	}
+	return &tdbplusv1.DropCollectionResponse{}, nil
+}
+
+func (s *Server) ListCollections(ctx context.Context, req *tdbplusv1.ListCollectionsRequest) (*tdbplusv1.ListCollectionsResponse, error) {
+	names, err := s.node.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+	return &tdbplusv1.ListCollectionsResponse{Names: names}, nil
+}
+
+func (s *Server) Stats(ctx context.Context, req *tdbplusv1.StatsRequest) (*tdbplusv1.StatsResponse, error) {
+	data, err := s.node.GetDatabase().StatsMP()
+	if err != nil {
+		return nil, err
+	}
+	return &tdbplusv1.StatsResponse{Json: data}, nil
+}
+
+func (s *Server) GetTopology(ctx context.Context, req *tdbplusv1.GetTopologyRequest) (*tdbplusv1.GetTopologyResponse, error) {
+	topoJSON, err := json.Marshal(s.router.GetClusterTopology())
+	if err != nil {
+		return nil, err
+	}
+	return &tdbplusv1.GetTopologyResponse{Json: topoJSON}, nil
+}
+
+func (s *Server) Health(ctx context.Context, req *tdbplusv1.HealthRequest) (*tdbplusv1.HealthResponse, error) {
+	return &tdbplusv1.HealthResponse{
+		Ok:         true,
+		IsLeader:   s.node.IsLeader(),
+		LeaderAddr: s.node.LeaderAddr(),
+	}, nil
+}
@@ -7,6 +7,10 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/peering"
+	"github.com/tdb-plus/cluster/pkg/router"
 )
 
 // NOTE: These structs would connect to Rust via FFI/RPC in production.
@@ -89,10 +93,12 @@ func NewKeyManager() *KeyManager { return &KeyManager{} }
 
 // API implements Meilisearch REST API
 type API struct {
-	engine *SearchEngine
-	tasks  *TaskManager
-	keys   *KeyManager
-	mu     sync.RWMutex
+	engine  *SearchEngine
+	tasks   *TaskManager
+	keys    *KeyManager
+	router  *router.Router
+	peering *peering.Manager
+	mu      sync.RWMutex
 }
 
 func NewAPI(engine *SearchEngine) *API {
@@ -103,12 +109,25 @@ func NewAPI(engine *SearchEngine) *API {
 	}
 }
 
+// SetRouter attaches the cluster router so /health/endpoints can report
+// per-peer health. It is optional; without it, /health/endpoints reports
+// an empty peer list.
+func (a *API) SetRouter(r *router.Router) {
+	a.router = r
+}
+
+// SetPeeringManager attaches the peering manager backing /peerings.
+func (a *API) SetPeeringManager(m *peering.Manager) {
+	a.peering = m
+}
+
 // Register all Meilisearch-compatible endpoints
 func (a *API) Register(mux *http.ServeMux) {
 	mux.HandleFunc("/indexes", a.handleIndexes)
 	mux.HandleFunc("/indexes/", a.handleIndex)
 	mux.HandleFunc("/multi-search", a.handleMultiSearch)
 	mux.HandleFunc("/health", a.handleHealth)
+	mux.HandleFunc("/health/endpoints", a.handleHealthEndpoints)
 	mux.HandleFunc("/stats", a.handleStats)
 	mux.HandleFunc("/version", a.handleVersion)
 	mux.HandleFunc("/tasks", a.handleTasks)
@@ -117,6 +136,8 @@ func (a *API) Register(mux *http.ServeMux) {
 	mux.HandleFunc("/keys/", a.handleKey)
 	mux.HandleFunc("/dumps", a.handleDumps)
 	mux.HandleFunc("/experimental-features", a.handleExperimentalFeatures)
+	mux.HandleFunc("/peerings", a.handlePeerings)
+	mux.HandleFunc("/peerings/", a.handlePeering)
 }
 
 // Handlers Stubs
@@ -152,6 +173,133 @@ func (a *API) handleMultiSearch(w http.ResponseWriter, r *http.Request) {}
 func (a *API) handleHealth(w http.ResponseWriter, r *http.Request) {
 	a.respond(w, map[string]string{"status": "available"}, 200)
 }
+
+// handleHealthEndpoints reports the circuit-breaker health of every peer
+// the cluster router currently knows about, for observability beyond the
+// single aggregate /health status.
+func (a *API) handleHealthEndpoints(w http.ResponseWriter, r *http.Request) {
+	if a.router == nil {
+		a.respond(w, map[string]interface{}{"endpoints": []router.EndpointStatus{}}, http.StatusOK)
+		return
+	}
+	a.respond(w, map[string]interface{}{"endpoints": a.router.EndpointHealth()}, http.StatusOK)
+}
+
+// handlePeerings lists known peerings.
+func (a *API) handlePeerings(w http.ResponseWriter, r *http.Request) {
+	if a.peering == nil {
+		a.respond(w, []*peering.PeerState{}, http.StatusOK)
+		return
+	}
+	a.respond(w, a.peering.List(), http.StatusOK)
+}
+
+// handlePeering dispatches /peerings/{name}, /peerings/{name}/token and
+// /peerings/{name}/establish.
+func (a *API) handlePeering(w http.ResponseWriter, r *http.Request) {
+	if a.peering == nil {
+		a.errorResponse(w, "peering_disabled", "peering manager not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/peerings/")
+	parts := strings.SplitN(path, "/", 2)
+	name := parts[0]
+
+	if name == "shards" && len(parts) == 1 {
+		a.handlePeeringShards(w, r)
+		return
+	}
+
+	if len(parts) == 1 {
+		state, ok := a.peering.Get(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		a.respond(w, state, http.StatusOK)
+		return
+	}
+
+	switch parts[1] {
+	case "token":
+		a.handlePeeringToken(w, r, name)
+	case "establish":
+		a.handlePeeringEstablish(w, r, name)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+type peeringTokenRequest struct {
+	ClusterID    string   `json:"clusterId"`
+	GatewayAddrs []string `json:"gatewayAddrs"`
+	TTLSeconds   int64    `json:"ttlSeconds"`
+}
+
+func (a *API) handlePeeringToken(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req peeringTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.errorResponse(w, "bad_request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, err := a.peering.GenerateToken(req.ClusterID, req.GatewayAddrs, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		a.errorResponse(w, "internal", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	a.respond(w, map[string]string{"token": token}, http.StatusOK)
+}
+
+// handlePeeringShards is the exporting side of peering: it publishes this
+// node's shard map for a remote cluster's HTTPShardFetcher to pull.
+func (a *API) handlePeeringShards(w http.ResponseWriter, r *http.Request) {
+	if a.router == nil {
+		a.respond(w, map[string]interface{}{"shards": map[uint32]*peering.RemoteShardInfo{}}, http.StatusOK)
+		return
+	}
+
+	topology := a.router.GetClusterTopology()
+	shards, _ := topology["shards"].(map[uint32]*cluster.ShardInfo)
+
+	out := make(map[uint32]*peering.RemoteShardInfo, len(shards))
+	for id, s := range shards {
+		out[id] = &peering.RemoteShardInfo{ID: s.ID, Leader: s.Leader, Replicas: s.Replicas}
+	}
+
+	a.respond(w, map[string]interface{}{"shards": out}, http.StatusOK)
+}
+
+type peeringEstablishRequest struct {
+	Token string `json:"token"`
+}
+
+func (a *API) handlePeeringEstablish(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req peeringEstablishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.errorResponse(w, "bad_request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.peering.Establish(name, req.Token); err != nil {
+		a.errorResponse(w, "peering_failed", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	a.respond(w, map[string]string{"status": "established"}, http.StatusAccepted)
+}
 func (a *API) handleStats(w http.ResponseWriter, r *http.Request)                {}
 func (a *API) handleVersion(w http.ResponseWriter, r *http.Request)              {}
 func (a *API) handleTasks(w http.ResponseWriter, r *http.Request)                {}
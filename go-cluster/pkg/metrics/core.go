@@ -0,0 +1,80 @@
+// Package metrics holds the Prometheus collectors shared across the
+// core storage engine, the HTTP API, and Raft, so a single /metrics
+// endpoint can answer for all three without each layer reinventing
+// naming/bucket conventions.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace is used when no namespace is configured.
+const DefaultNamespace = "tdb"
+
+// Core holds the collectors for core.Database's CGO calls. It's a
+// struct (rather than package-level vars) so NewServer's MetricsConfig
+// can namespace or disable it per process without collectors from two
+// Registers colliding.
+type Core struct {
+	OpDuration  *prometheus.HistogramVec
+	OpErrors    *prometheus.CounterVec
+	BufferBytes *prometheus.HistogramVec
+}
+
+// NewCore builds and registers the core.Database collectors under ns
+// (e.g. "tdb") onto reg. Passing nil for reg registers against the
+// default Prometheus registry.
+func NewCore(ns string, reg prometheus.Registerer) *Core {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	c := &Core{
+		OpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: "core",
+			Name:      "op_duration_seconds",
+			Help:      "Duration of core.Database operations, by op.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"op"}),
+		OpErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "core",
+			Name:      "op_errors_total",
+			Help:      "Count of core.Database operations that returned an error, by op and error kind.",
+		}, []string{"op", "kind"}),
+		BufferBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: "core",
+			Name:      "buffer_bytes",
+			Help:      "Size of the LumaBuffer payload read back from the storage engine, by op.",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+		}, []string{"op"}),
+	}
+	reg.MustRegister(c.OpDuration, c.OpErrors, c.BufferBytes)
+	return c
+}
+
+// ObserveOp records one call to a core.Database operation. kind is the
+// caller's classification of err (e.g. from a resultToError switch) -
+// empty for success, since metrics doesn't know core's sentinel errors
+// and importing them here would cycle back into core.
+func (c *Core) ObserveOp(op string, dur time.Duration, kind string) {
+	if c == nil {
+		return
+	}
+	c.OpDuration.WithLabelValues(op).Observe(dur.Seconds())
+	if kind != "" {
+		c.OpErrors.WithLabelValues(op, kind).Inc()
+	}
+}
+
+// ObserveBufferBytes records the size of a LumaBuffer payload read back
+// for op.
+func (c *Core) ObserveBufferBytes(op string, n int) {
+	if c == nil {
+		return
+	}
+	c.BufferBytes.WithLabelValues(op).Observe(float64(n))
+}
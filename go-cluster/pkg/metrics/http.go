@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTP holds the collector for gin request latency.
+type HTTP struct {
+	RequestDuration *prometheus.HistogramVec
+}
+
+// NewHTTP builds and registers the HTTP collectors under ns onto reg.
+func NewHTTP(ns string, reg prometheus.Registerer) *HTTP {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	h := &HTTP{
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests, by method, route, and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "path", "status"}),
+	}
+	reg.MustRegister(h.RequestDuration)
+	return h
+}
+
+// GinMiddleware times every request through the engine and observes it
+// against RequestDuration. It uses c.FullPath() (the matched route
+// template, e.g. "/api/v1/collections/:collection/:id") rather than the
+// raw URL, so per-document requests don't each get their own label
+// series.
+func (h *HTTP) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h == nil {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		h.RequestDuration.WithLabelValues(c.Request.Method, path, strconv.Itoa(c.Writer.Status())).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Raft holds the collector for cluster.Node.Apply latency.
+type Raft struct {
+	ApplyDuration *prometheus.HistogramVec
+}
+
+// NewRaft builds and registers the Raft collectors under ns onto reg.
+func NewRaft(ns string, reg prometheus.Registerer) *Raft {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	r := &Raft{
+		ApplyDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: "raft",
+			Name:      "apply_duration_seconds",
+			Help:      "Duration of cluster.Node.Apply calls, by command op and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"op", "outcome"}),
+	}
+	reg.MustRegister(r.ApplyDuration)
+	return r
+}
+
+// ObserveApply records one Node.Apply call for a Command with the given
+// op ("set", "delete", ...).
+func (r *Raft) ObserveApply(op string, dur time.Duration, err error) {
+	if r == nil {
+		return
+	}
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	r.ApplyDuration.WithLabelValues(op, outcome).Observe(dur.Seconds())
+}
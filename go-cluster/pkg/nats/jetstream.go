@@ -2,9 +2,15 @@ package nats
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
 )
 
 // Interfaces for Storage used by JetStream
@@ -14,105 +20,121 @@ type StorageEngine interface {
 	// Placeholder for storage engine methods
 }
 
-type MessageStore struct {
+// JetStreamEngine manages streams, consumers, and persistence
+type JetStreamEngine struct {
 	storage StorageEngine
-	stream  string
-	msgs    []*Message // In-memory fallback
-	mu      sync.RWMutex
-}
+	// dataDir is where FileStorage streams persist their block files.
+	// MemoryStorage streams get their own scratch subdirectory instead
+	// (see createStream) so the on-disk-block-file design still applies
+	// to them, just without fsync and without surviving a restart.
+	dataDir string
+	// subjectIndex maps each stream's configured subjects to the *Stream
+	// itself, so ProcessMessage can find the streams interested in an
+	// incoming message in O(subject depth) instead of looping over every
+	// stream and re-checking all of its subject patterns.
+	subjectIndex *sublist
 
-func NewMessageStore(storage StorageEngine, stream string) *MessageStore {
-	return &MessageStore{
-		storage: storage,
-		stream:  stream,
-		msgs:    make([]*Message, 0),
-	}
-}
+	streams    sync.Map // name -> *Stream
+	consumers  sync.Map // streamName.consumerName -> *Consumer
+	kvBuckets  sync.Map // name -> *KVBucket
+	objBuckets sync.Map // name -> *ObjBucket
+	mu         sync.RWMutex
 
-func (ms *MessageStore) Store(msg *Message) {
-	ms.mu.Lock()
-	defer ms.mu.Unlock()
-	ms.msgs = append(ms.msgs, msg)
+	// node is the cluster node streams with Replicas > 1 propose their
+	// topology through (see AttachCluster in replication.go). nil means
+	// js runs standalone and every stream is local-only, same as before
+	// replication support existed.
+	node *cluster.Node
+	// streamGroups holds the per-stream Raft group for each replicated
+	// stream, keyed by stream name.
+	streamGroups sync.Map // name -> *streamGroup
+
+	// kvUpdateMaxRetries bounds KVUpdate's optimistic read-modify-write
+	// retry loop. 0 uses defaultKVUpdateMaxRetries.
+	kvUpdateMaxRetries int
 }
 
-func (ms *MessageStore) DeleteFirst() int {
-	ms.mu.Lock()
-	defer ms.mu.Unlock()
-	if len(ms.msgs) == 0 {
-		return 0
-	}
-	size := len(ms.msgs[0].Data)
-	ms.msgs = ms.msgs[1:]
-	return size
+// SetKVUpdateMaxRetries overrides the retry bound KVUpdate uses when a
+// CAS write conflicts, in place of defaultKVUpdateMaxRetries.
+func (js *JetStreamEngine) SetKVUpdateMaxRetries(n int) {
+	js.kvUpdateMaxRetries = n
 }
 
-func (ms *MessageStore) GetFirst() *Message {
-	ms.mu.RLock()
-	defer ms.mu.RUnlock()
-	if len(ms.msgs) == 0 {
-		return nil
+// getOrCreateKVBucket returns the KVBucket for name with the default
+// history depth, creating its backing KV_<name> stream on first use.
+// Use createKVBucket directly to set a non-default history depth.
+func (js *JetStreamEngine) getOrCreateKVBucket(name string) (*KVBucket, error) {
+	if v, ok := js.kvBuckets.Load(name); ok {
+		return v.(*KVBucket), nil
 	}
-	return ms.msgs[0]
+	return js.createKVBucket(name, defaultKVHistory)
 }
 
-// KVBucket represents a Key-Value bucket in JetStream
-type KVBucket struct {
-	Name    string
-	streams map[string][]byte
-	mu      sync.RWMutex
-}
-
-func (b *KVBucket) Put(key string, value []byte, headers map[string]string) (uint64, error) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	b.streams[key] = value
-	return 1, nil // Mock revision
-}
+// createKVBucket creates (or returns the existing) KVBucket for name,
+// configured to retain up to history revisions per key.
+func (js *JetStreamEngine) createKVBucket(name string, history int) (*KVBucket, error) {
+	if v, ok := js.kvBuckets.Load(name); ok {
+		return v.(*KVBucket), nil
+	}
 
-func (b *KVBucket) Get(key string) ([]byte, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-	if v, ok := b.streams[key]; ok {
-		return v, nil
+	streamName := "KV_" + name
+	streamVal, ok := js.streams.Load(streamName)
+	if !ok {
+		config := StreamConfig{
+			Name:     streamName,
+			Subjects: []string{fmt.Sprintf("$KV.%s.>", name)},
+			Storage:  FileStorage,
+		}
+		if _, err := js.createStream(mustMarshal(config)); err != nil {
+			return nil, fmt.Errorf("kv: failed to create backing stream: %w", err)
+		}
+		streamVal, _ = js.streams.Load(streamName)
 	}
-	return nil, fmt.Errorf("key not found: %s", key)
-}
 
-func (b *KVBucket) Delete(key string) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	delete(b.streams, key)
-	return nil
+	bucket := newKVBucket(name, streamVal.(*Stream), history)
+	actual, loaded := js.kvBuckets.LoadOrStore(name, bucket)
+	if loaded {
+		return actual.(*KVBucket), nil
+	}
+	return bucket, nil
 }
 
-func (b *KVBucket) Purge(key string) error {
-	return b.Delete(key)
-}
+// getOrCreateObjBucket returns the ObjBucket for name, creating its
+// backing OBJ_<name> stream (and registering its chunk/metadata
+// subjects in the subject index) on first use.
+func (js *JetStreamEngine) getOrCreateObjBucket(name string) (*ObjBucket, error) {
+	if v, ok := js.objBuckets.Load(name); ok {
+		return v.(*ObjBucket), nil
+	}
 
-type ObjBucket struct {
-	Name string
-}
+	streamName := "OBJ_" + name
+	streamVal, ok := js.streams.Load(streamName)
+	if !ok {
+		config := StreamConfig{
+			Name:     streamName,
+			Subjects: []string{fmt.Sprintf("$O.%s.C.>", name), fmt.Sprintf("$O.%s.M.>", name)},
+			Storage:  FileStorage,
+		}
+		if _, err := js.createStream(mustMarshal(config)); err != nil {
+			return nil, fmt.Errorf("object store: failed to create backing stream: %w", err)
+		}
+		streamVal, _ = js.streams.Load(streamName)
+	}
 
-// JetStreamEngine manages streams, consumers, and persistence
-type JetStreamEngine struct {
-	storage    StorageEngine
-	streams    sync.Map // name -> *Stream
-	consumers  sync.Map // streamName.consumerName -> *Consumer
-	kvBuckets  sync.Map // name -> *KVBucket
-	objBuckets sync.Map // name -> *ObjBucket
-	mu         sync.RWMutex
+	bucket := newObjBucket(name, streamVal.(*Stream))
+	actual, loaded := js.objBuckets.LoadOrStore(name, bucket)
+	if loaded {
+		return actual.(*ObjBucket), nil
+	}
+	return bucket, nil
 }
 
-func (js *JetStreamEngine) getOrCreateKVBucket(name string) (*KVBucket, error) {
-	if v, ok := js.kvBuckets.Load(name); ok {
-		return v.(*KVBucket), nil
-	}
-	b := &KVBucket{
-		Name:    name,
-		streams: make(map[string][]byte),
+func mustMarshal(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("nats: failed to marshal %T: %v", v, err))
 	}
-	js.kvBuckets.Store(name, b)
-	return b, nil
+	return b
 }
 
 // Stream configuration matching NATS JetStream
@@ -182,6 +204,46 @@ type Stream struct {
 	messages  *MessageStore
 	consumers sync.Map
 	mu        sync.RWMutex
+
+	// dedup tracks Nats-Msg-Id values seen within config.Duplicates of
+	// now, so a republish of the same message ID (a client's at-least-
+	// once retry) is silently dropped instead of double-stored.
+	dedupMu sync.Mutex
+	dedup   map[string]time.Time
+}
+
+// natsMsgIDHeader is the header a publisher sets to make ProcessMessage
+// dedup publishes within the stream's configured Duplicates window.
+const natsMsgIDHeader = "Nats-Msg-Id"
+
+// checkDuplicate reports whether msgID has already been seen within the
+// stream's Duplicates window, recording it if not. A stream with no
+// Duplicates window configured, or a publish with no Nats-Msg-Id header,
+// never dedups.
+func (s *Stream) checkDuplicate(msgID string) bool {
+	if msgID == "" || s.config.Duplicates <= 0 {
+		return false
+	}
+
+	now := time.Now()
+
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+
+	if s.dedup == nil {
+		s.dedup = make(map[string]time.Time)
+	}
+	for id, seen := range s.dedup {
+		if now.Sub(seen) > s.config.Duplicates {
+			delete(s.dedup, id)
+		}
+	}
+
+	if seen, ok := s.dedup[msgID]; ok && now.Sub(seen) <= s.config.Duplicates {
+		return true
+	}
+	s.dedup[msgID] = now
+	return false
 }
 
 type StreamState struct {
@@ -257,6 +319,16 @@ type Consumer struct {
 	mu         sync.RWMutex
 }
 
+// PendingAck tracks one delivered-but-unacked message for an AckExplicit
+// or AckAll consumer: how many times it's been (re)delivered, and when
+// the current delivery's AckWait expires and it becomes eligible for
+// redelivery.
+type PendingAck struct {
+	Seq        uint64
+	Deliveries int
+	ExpiresAt  time.Time
+}
+
 type ConsumerState struct {
 	Delivered      SequenceInfo `json:"delivered"`
 	AckFloor       SequenceInfo `json:"ack_floor"`
@@ -272,9 +344,13 @@ type SequenceInfo struct {
 	Last     time.Time `json:"last_active,omitempty"`
 }
 
-func NewJetStreamEngine(storage StorageEngine) *JetStreamEngine {
+// NewJetStreamEngine creates a JetStreamEngine whose FileStorage streams
+// persist their block files under dataDir.
+func NewJetStreamEngine(storage StorageEngine, dataDir string) *JetStreamEngine {
 	return &JetStreamEngine{
-		storage: storage,
+		storage:      storage,
+		dataDir:      dataDir,
+		subjectIndex: newSublist(),
 	}
 }
 
@@ -307,11 +383,158 @@ func (js *JetStreamEngine) HandleStreamAPI(action string, args []string, payload
 		return js.purgeStream(args[0], payload)
 	case "NAMES":
 		return js.streamNames()
+	case "MSG":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("msg sub-action and stream name required")
+		}
+		return js.streamMsgAPI(args[0], args[1], payload)
+	case "OBJ":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("obj sub-action and bucket required")
+		}
+		return js.objectAPI(args[0], args[1], args[2:], payload)
 	default:
 		return nil, fmt.Errorf("unknown stream action: %s", action)
 	}
 }
 
+// objectAPI handles $JS.API.STREAM.OBJ.<subAction>.<bucket>[.<name>]
+// requests for the Object Store (see objectstore.go). WATCH is handled
+// separately by HandleObjectWatch since it streams updates rather than
+// returning one response.
+func (js *JetStreamEngine) objectAPI(subAction, bucket string, rest []string, payload []byte) ([]byte, error) {
+	bkt, err := js.getOrCreateObjBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	switch subAction {
+	case "PUT":
+		var req struct {
+			Meta ObjectMeta `json:"meta"`
+			Data []byte     `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return nil, fmt.Errorf("invalid object put request: %w", err)
+		}
+		info, err := bkt.Put(req.Meta, req.Data)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]interface{}{"type": "io.nats.jetstream.api.v1.object_info", "info": info})
+	case "GET":
+		if len(rest) < 1 {
+			return nil, fmt.Errorf("object name required")
+		}
+		data, info, err := bkt.Get(rest[0])
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]interface{}{
+			"type": "io.nats.jetstream.api.v1.object_get_response",
+			"info": info,
+			"data": data,
+		})
+	case "DELETE":
+		if len(rest) < 1 {
+			return nil, fmt.Errorf("object name required")
+		}
+		if err := bkt.Delete(rest[0]); err != nil {
+			return nil, err
+		}
+		return []byte(`{"result": true}`), nil
+	case "LINK":
+		var req struct {
+			Name   string `json:"name"`
+			Source string `json:"source"`
+		}
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return nil, fmt.Errorf("invalid object link request: %w", err)
+		}
+		source, ok := bkt.Info(req.Source)
+		if !ok {
+			return nil, fmt.Errorf("object not found: %s", req.Source)
+		}
+		info, err := bkt.AddLink(req.Name, source)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]interface{}{"type": "io.nats.jetstream.api.v1.object_info", "info": info})
+	default:
+		return nil, fmt.Errorf("unknown obj sub-action: %s", subAction)
+	}
+}
+
+// HandleObjectWatch starts forwarding bucket's object updates (current
+// state first, then live changes) to deliver. It runs for the lifetime
+// of the returned watch — same as a real ordered ephemeral consumer,
+// there is currently no wire-level way to stop it early beyond closing
+// the client connection.
+func (js *JetStreamEngine) HandleObjectWatch(bucket string, deliver func(*ObjectInfo)) error {
+	bkt, err := js.getOrCreateObjBucket(bucket)
+	if err != nil {
+		return err
+	}
+
+	ch := bkt.Watch()
+	go func() {
+		for info := range ch {
+			deliver(info)
+		}
+	}()
+	return nil
+}
+
+// streamMsgAPI handles $JS.API.STREAM.MSG.<subAction>.<stream> requests.
+func (js *JetStreamEngine) streamMsgAPI(subAction, streamName string, payload []byte) ([]byte, error) {
+	val, ok := js.streams.Load(streamName)
+	if !ok {
+		return nil, fmt.Errorf("stream not found: %s", streamName)
+	}
+	stream := val.(*Stream)
+
+	switch subAction {
+	case "GET":
+		var req struct {
+			Seq uint64 `json:"seq,omitempty"`
+		}
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &req); err != nil {
+				return nil, fmt.Errorf("invalid message get request: %w", err)
+			}
+		}
+
+		seq := req.Seq
+		if seq == 0 {
+			stream.mu.RLock()
+			seq = stream.state.LastSeq
+			stream.mu.RUnlock()
+		}
+
+		msg, err := stream.messages.Get(seq)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]interface{}{
+			"type": "io.nats.jetstream.api.v1.stream_msg_get_response",
+			"message": map[string]interface{}{
+				"subject": msg.Subject,
+				"seq":     msg.Seq,
+				"data":    msg.Data,
+				"time":    msg.Time,
+			},
+		})
+	case "DELETE":
+		// The block-file store only drops whole blocks (see
+		// Stream.applyLimits); deleting a single message in place would
+		// require rewriting its block, which defeats the point of the
+		// append-only format. Not supported for now.
+		return nil, fmt.Errorf("MSG.DELETE is not supported by the block-file store")
+	default:
+		return nil, fmt.Errorf("unknown msg sub-action: %s", subAction)
+	}
+}
+
 func (js *JetStreamEngine) createStream(payload []byte) ([]byte, error) {
 	var config StreamConfig
 	if err := json.Unmarshal(payload, &config); err != nil {
@@ -322,9 +545,45 @@ func (js *JetStreamEngine) createStream(payload []byte) ([]byte, error) {
 		return nil, fmt.Errorf("stream already exists: %s", config.Name)
 	}
 
+	// Replicated streams get their topology proposed through js.node's
+	// Raft log instead of being created directly, so every cluster
+	// member ends up with the same local Stream (see replication.go).
+	if js.node != nil && config.Replicas > 1 {
+		return js.proposeStreamCreate(config)
+	}
+
+	if _, err := js.createStreamLocal(config); err != nil {
+		return nil, err
+	}
+	return js.streamInfo(config.Name)
+}
+
+// createStreamLocal creates config's Stream on this node only, with no
+// replication. It's the common path for standalone streams and the one
+// every cluster member runs (via applyStreamCreate) when a stream's
+// topology was proposed through the cluster's Raft log instead.
+func (js *JetStreamEngine) createStreamLocal(config StreamConfig) (*Stream, error) {
+	// MemoryStorage streams still use the block-file format, just under a
+	// scratch directory that doesn't survive a restart and without
+	// fsync'ing every write.
+	dir := js.dataDir
+	fsync := config.Storage != MemoryStorage
+	if config.Storage == MemoryStorage {
+		tmp, err := os.MkdirTemp("", "lumadb-nats-mem-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create memory-storage scratch dir: %w", err)
+		}
+		dir = tmp
+	}
+
+	store, err := NewMessageStore(dir, config.Name, fsync)
+	if err != nil {
+		return nil, err
+	}
+
 	stream := &Stream{
 		config:   config,
-		messages: NewMessageStore(js.storage, config.Name),
+		messages: store,
 		state: StreamState{
 			FirstSeq: 1,
 			LastSeq:  0,
@@ -332,9 +591,11 @@ func (js *JetStreamEngine) createStream(payload []byte) ([]byte, error) {
 	}
 
 	js.streams.Store(config.Name, stream)
+	for _, subject := range config.Subjects {
+		js.subjectIndex.Insert(subject, stream)
+	}
 
-	// Return stream info
-	return js.streamInfo(config.Name)
+	return stream, nil
 }
 
 func (js *JetStreamEngine) updateStream(name string, payload []byte) ([]byte, error) {
@@ -358,9 +619,18 @@ func (js *JetStreamEngine) updateStream(name string, payload []byte) ([]byte, er
 }
 
 func (js *JetStreamEngine) deleteStream(name string) ([]byte, error) {
-	if _, ok := js.streams.LoadAndDelete(name); !ok {
+	val, ok := js.streams.LoadAndDelete(name)
+	if !ok {
 		return nil, fmt.Errorf("stream not found: %s", name)
 	}
+
+	stream := val.(*Stream)
+	for _, subject := range stream.config.Subjects {
+		js.subjectIndex.Remove(subject, func(v interface{}) bool { return v.(*Stream) == stream })
+	}
+	if err := stream.messages.RemoveAll(); err != nil {
+		return nil, fmt.Errorf("failed to remove stream storage: %w", err)
+	}
 	return []byte(`{"result": true}`), nil
 }
 
@@ -398,6 +668,9 @@ func (js *JetStreamEngine) streamInfo(name string) ([]byte, error) {
 		"state":   stream.state,
 		"created": time.Now(), // Should be actual creation time
 	}
+	if clusterInfo := js.clusterInfo(name); clusterInfo != nil {
+		info["cluster"] = clusterInfo
+	}
 
 	return json.Marshal(info)
 }
@@ -501,30 +774,223 @@ func (js *JetStreamEngine) consumerNames(stream string) ([]byte, error) {
 	return []byte(`{"consumers": []}`), nil
 }
 
+// nextMessage returns the next stream message due for delivery to c,
+// advancing its delivered-sequence bookkeeping and, for AckExplicit/
+// AckAll consumers, recording it as pending until Ack is called.
+func (c *Consumer) nextMessage() (*Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next := c.state.Delivered.Stream + 1
+	msg, err := c.stream.messages.Get(next)
+	if err != nil {
+		return nil, err
+	}
+
+	c.state.Delivered.Stream = next
+	c.state.Delivered.Consumer++
+	c.state.Delivered.Last = time.Now()
+
+	if c.config.AckPolicy != AckNone {
+		wait := c.config.AckWait
+		if wait <= 0 {
+			wait = 30 * time.Second
+		}
+		c.ackPending.Store(next, &PendingAck{
+			Seq:        next,
+			Deliveries: 1,
+			ExpiresAt:  time.Now().Add(wait),
+		})
+		c.state.NumAckPending++
+	} else {
+		c.advanceAckFloorLocked(next)
+	}
+	return msg, nil
+}
+
+// duePending returns every pending ack whose AckWait has expired and
+// hasn't yet hit MaxDeliver, bumping their delivery count and AckWait
+// deadline so the caller can redeliver them.
+func (c *Consumer) duePending() []*PendingAck {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wait := c.config.AckWait
+	if wait <= 0 {
+		wait = 30 * time.Second
+	}
+
+	var due []*PendingAck
+	now := time.Now()
+	c.ackPending.Range(func(key, value any) bool {
+		pending := value.(*PendingAck)
+		if now.Before(pending.ExpiresAt) {
+			return true
+		}
+		if c.config.MaxDeliver > 0 && pending.Deliveries >= c.config.MaxDeliver {
+			// Exhausted: drop it from pending tracking without redelivering.
+			c.ackPending.Delete(key)
+			c.state.NumAckPending--
+			c.advanceAckFloorLocked(pending.Seq)
+			return true
+		}
+		pending.Deliveries++
+		pending.ExpiresAt = now.Add(wait)
+		c.state.NumRedelivered++
+		due = append(due, pending)
+		return true
+	})
+	return due
+}
+
+// Ack acknowledges delivery of seq, removing it from pending tracking
+// and advancing the consumer's ack floor.
+func (c *Consumer) Ack(seq uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ackPending.LoadAndDelete(seq); !ok {
+		return fmt.Errorf("nats: no pending ack for sequence %d", seq)
+	}
+	c.state.NumAckPending--
+	c.advanceAckFloorLocked(seq)
+	return nil
+}
+
+// advanceAckFloorLocked moves AckFloor forward to seq if seq is newer,
+// matching the NATS convention that AckFloor reflects the highest
+// contiguous acknowledged sequence known so far. Callers must hold c.mu.
+func (c *Consumer) advanceAckFloorLocked(seq uint64) {
+	if seq > c.state.AckFloor.Stream {
+		c.state.AckFloor.Stream = seq
+		c.state.AckFloor.Consumer = c.state.Delivered.Consumer
+		c.state.AckFloor.Last = time.Now()
+	}
+}
+
+// pendingCount returns the number of messages currently awaiting ack.
+func (c *Consumer) pendingCount() int {
+	count := 0
+	c.ackPending.Range(func(_, _ any) bool {
+		count++
+		return true
+	})
+	return count
+}
+
 func (js *JetStreamEngine) HandleMessageAPI(action string, args []string, payload []byte) ([]byte, error) {
 	// Implement MSG GET, etc.
 	return []byte("{}"), nil
 }
 
+// HandleConsumerMsgNext services a $JS.API.CONSUMER.MSG.NEXT.<stream>.
+// <consumer> pull request, delivering up to batch messages via deliver.
+// Unlike the other HandleXxxAPI methods it doesn't return a single JSON
+// response: each pulled message is pushed to the caller as it's read, the
+// way a real pull subscription streams messages back on its reply
+// subject.
+func (js *JetStreamEngine) HandleConsumerMsgNext(streamName, consumerName string, payload []byte, deliver func(*Message)) error {
+	key := fmt.Sprintf("%s.%s", streamName, consumerName)
+	val, ok := js.consumers.Load(key)
+	if !ok {
+		return fmt.Errorf("consumer not found: %s", key)
+	}
+	consumer := val.(*Consumer)
+
+	var req struct {
+		Batch int `json:"batch,omitempty"`
+	}
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return fmt.Errorf("invalid pull request: %w", err)
+		}
+	}
+	batch := req.Batch
+	if batch <= 0 {
+		batch = 1
+	}
+
+	for i := 0; i < batch; i++ {
+		msg, err := consumer.nextMessage()
+		if err != nil {
+			// No more messages currently available; a real pull
+			// subscription would wait out the request's expiry instead,
+			// but this engine delivers whatever's ready right now.
+			break
+		}
+		deliver(msg)
+	}
+	return nil
+}
+
+// HandleAck processes a client's ack published to a delivered message's
+// reply subject, of the form $JS.ACK.<stream>.<consumer>.<delivered>.
+// <stream_seq>.<...>.
+func (js *JetStreamEngine) HandleAck(subject string, payload []byte) error {
+	parts := strings.Split(subject, ".")
+	if len(parts) < 6 || parts[0] != "$JS" || parts[1] != "ACK" {
+		return fmt.Errorf("nats: malformed ack subject: %s", subject)
+	}
+	streamName, consumerName := parts[2], parts[3]
+
+	seq, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		return fmt.Errorf("nats: malformed ack subject sequence: %w", err)
+	}
+
+	key := fmt.Sprintf("%s.%s", streamName, consumerName)
+	val, ok := js.consumers.Load(key)
+	if !ok {
+		return fmt.Errorf("consumer not found: %s", key)
+	}
+	consumer := val.(*Consumer)
+
+	// Durable consumers on a replicated stream route their ack through
+	// the stream's Raft group too, so AckFloor/ackPending survive a
+	// leader failover instead of only living on the node the ack
+	// happened to land on.
+	if consumer.config.Durable != "" {
+		if g, ok := js.streamGroups.Load(streamName); ok {
+			return g.(*streamGroup).proposeAck(consumerName, seq)
+		}
+	}
+	return consumer.Ack(seq)
+}
+
 func (js *JetStreamEngine) HandleInfo() ([]byte, error) {
 	return []byte(`{"account_info": {}}`), nil
 }
 
-// ProcessMessage handles incoming messages for streams
+// ProcessMessage handles incoming messages for streams, routing via the
+// subject-interest trie instead of scanning every stream's subject list.
 func (js *JetStreamEngine) ProcessMessage(msg *Message) {
-	js.streams.Range(func(key, value any) bool {
-		stream := value.(*Stream)
+	matched := js.subjectIndex.Match(msg.Subject)
+
+	// A stream can register more than one matching subject pattern
+	// (e.g. "orders.*" and "orders.created" both present), so dedup
+	// before storing to avoid double-delivery.
+	seen := make(map[*Stream]bool, len(matched))
+	for _, v := range matched {
+		stream := v.(*Stream)
+		if seen[stream] {
+			continue
+		}
+		seen[stream] = true
 
-		// Check if any subject pattern matches
-		for _, pattern := range stream.config.Subjects {
-			if subjectMatches(pattern, msg.Subject) {
-				stream.addMessage(msg)
-				break
-			}
+		if stream.checkDuplicate(msg.Headers[natsMsgIDHeader]) {
+			continue
 		}
 
-		return true
-	})
+		if g, ok := js.streamGroups.Load(stream.config.Name); ok {
+			// Go through the stream's own Raft group instead of
+			// appending directly, so every replica's MessageStore
+			// ends up with the same ordered log; the group's FSM
+			// calls addMessage once the append commits.
+			_ = g.(*streamGroup).proposeAppend(msg)
+			continue
+		}
+		stream.addMessage(msg)
+	}
 }
 
 func (s *Stream) addMessage(msg *Message) {
@@ -550,38 +1016,52 @@ func (s *Stream) addMessage(msg *Message) {
 	s.applyLimits()
 }
 
+// applyLimits evicts whole oldest blocks while the stream exceeds its
+// configured limits. The block-file store only supports whole-block
+// eviction (see MessageStore): while the stream is over MaxMsgs or
+// MaxBytes the oldest block is dropped, which may drop a handful of
+// still-in-bounds messages alongside it — the same trade NATS' own file
+// store makes at its block boundaries. For MaxAge a block is dropped
+// only once its newest message is older than MaxAge.
 func (s *Stream) applyLimits() {
-	// Apply max messages limit
-	for s.config.MaxMsgs > 0 && int64(s.state.Msgs) > s.config.MaxMsgs {
-		s.messages.DeleteFirst()
-		s.state.Msgs--
-		s.state.FirstSeq++
-	}
-
-	// Apply max bytes limit
-	for s.config.MaxBytes > 0 && int64(s.state.Bytes) > s.config.MaxBytes {
-		size := s.messages.DeleteFirst()
-		s.state.Msgs--
-		s.state.Bytes -= uint64(size)
-		s.state.FirstSeq++
-	}
-
-	// Apply max age limit
-	if s.config.MaxAge > 0 {
-		cutoff := time.Now().Add(-s.config.MaxAge)
-		for {
-			msg := s.messages.GetFirst()
-			if msg == nil || msg.Time.After(cutoff) {
-				break
-			}
-			s.messages.DeleteFirst()
-			s.state.Msgs--
-			s.state.Bytes -= uint64(len(msg.Data))
-			s.state.FirstSeq++
+	for {
+		_, _, lastTime, ok := s.messages.OldestBlockInfo()
+		if !ok {
+			return
+		}
+
+		exceeds := false
+		switch {
+		case s.config.MaxMsgs > 0 && int64(s.state.Msgs) > s.config.MaxMsgs:
+			exceeds = true
+		case s.config.MaxBytes > 0 && int64(s.state.Bytes) > s.config.MaxBytes:
+			exceeds = true
+		case s.config.MaxAge > 0 && time.Now().Sub(lastTime) > s.config.MaxAge:
+			exceeds = true
+		}
+		if !exceeds {
+			return
 		}
+
+		dropped, droppedBytes, err := s.messages.DropOldestBlock()
+		if err != nil || dropped == 0 {
+			return
+		}
+		s.state.Msgs -= uint64(dropped)
+		s.state.Bytes -= uint64(droppedBytes)
+		s.state.FirstSeq = s.messages.FirstSeq()
 	}
 }
 
+// defaultKVUpdateMaxRetries bounds KVUpdate's retry loop when
+// JetStreamEngine.kvUpdateMaxRetries hasn't been set to something else.
+const defaultKVUpdateMaxRetries = 10
+
+// natsExpectedLastSubjectSeqHeader is the real NATS KV convention a
+// client sets to make a PUT conditional: KVPut honors it the same way
+// KVCompareAndSwap honors an explicit lastRevision.
+const natsExpectedLastSubjectSeqHeader = "Nats-Expected-Last-Subject-Sequence"
+
 // KV Store operations
 func (js *JetStreamEngine) KVPut(bucket, key string, value []byte, headers map[string]string) ([]byte, error) {
 	bkt, err := js.getOrCreateKVBucket(bucket)
@@ -589,6 +1069,21 @@ func (js *JetStreamEngine) KVPut(bucket, key string, value []byte, headers map[s
 		return nil, err
 	}
 
+	// A Nats-Expected-Last-Subject-Sequence header turns this PUT into a
+	// CAS write, failing with JSErrWrongLastSequence if key's current
+	// revision doesn't match.
+	if raw, ok := headers[natsExpectedLastSubjectSeqHeader]; ok {
+		expected, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("kv: invalid %s header: %w", natsExpectedLastSubjectSeqHeader, err)
+		}
+		revision, err := bkt.Update(key, value, expected)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]interface{}{"seq": revision})
+	}
+
 	revision, err := bkt.Put(key, value, headers)
 	if err != nil {
 		return nil, err
@@ -599,44 +1094,207 @@ func (js *JetStreamEngine) KVPut(bucket, key string, value []byte, headers map[s
 	})
 }
 
+// KVCompareAndSwap applies an optimistic-concurrency write: it only
+// succeeds if key's current latest revision equals lastRevision. This is
+// the raw CAS primitive the `UPDATE` wire operation uses directly; see
+// KVUpdate for the higher-level read-modify-write helper built on top of
+// it.
+func (js *JetStreamEngine) KVCompareAndSwap(bucket, key string, value []byte, lastRevision uint64) ([]byte, error) {
+	bkt, err := js.loadKVBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := bkt.Update(key, value, lastRevision)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{"seq": revision})
+}
+
+// KVMeta is the metadata a KVUpdate mutator receives alongside the
+// current value: Revision is 0 when key doesn't exist yet, letting the
+// mutator distinguish "create" from "update".
+type KVMeta struct {
+	Bucket   string
+	Key      string
+	Revision uint64
+}
+
+// KVUpdate performs an optimistic-concurrency read-modify-write against
+// bucket/key, the same shape as etcd3's `guaranteedUpdate`: it reads the
+// current value and revision, invokes mutator, and attempts a CAS write
+// at that revision, retrying from a fresh read on conflict, with at most
+// kvUpdateMaxRetries attempts in all. cached lets a caller that already holds a
+// provably-current copy of the value skip that first read - analogous
+// to etcd3's mustCheckData/origStateIsCurrent flag - pass nil to always
+// read first.
+func (js *JetStreamEngine) KVUpdate(bucket, key string, cached *KVMeta, mutator func(old []byte, meta KVMeta) ([]byte, error)) ([]byte, error) {
+	bkt, err := js.getOrCreateKVBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := KVMeta{Bucket: bucket, Key: key}
+	var old []byte
+
+	mustCheckData := cached == nil
+	if cached != nil {
+		meta = *cached
+	}
+
+	maxRetries := js.kvUpdateMaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultKVUpdateMaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if mustCheckData {
+			if entry, err := bkt.Get(key); err == nil {
+				meta = KVMeta{Bucket: bucket, Key: key, Revision: entry.Revision}
+				old = entry.Value
+			} else {
+				meta = KVMeta{Bucket: bucket, Key: key}
+				old = nil
+			}
+		}
+
+		newValue, err := mutator(old, meta)
+		if err != nil {
+			return nil, err
+		}
+
+		revision, err := bkt.Update(key, newValue, meta.Revision)
+		if err == nil {
+			return json.Marshal(map[string]interface{}{"seq": revision})
+		}
+
+		var kvErr *KVError
+		if !errors.As(err, &kvErr) || kvErr.Code != JSErrWrongLastSequence {
+			return nil, err
+		}
+
+		// Conflict: the cached copy (or our last read) is stale - fall
+		// back to re-fetching before every remaining attempt.
+		lastErr = err
+		mustCheckData = true
+	}
+
+	return nil, fmt.Errorf("kv: update %q/%q did not converge after %d attempts: %w", bucket, key, maxRetries, lastErr)
+}
+
 func (js *JetStreamEngine) KVGet(bucket, key string) ([]byte, error) {
-	val, ok := js.kvBuckets.Load(bucket)
-	if !ok {
-		return nil, fmt.Errorf("bucket not found: %s", bucket)
+	bkt, err := js.loadKVBucket(bucket)
+	if err != nil {
+		return nil, err
 	}
 
-	bkt := val.(*KVBucket)
-	return bkt.Get(key)
+	entry, err := bkt.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Value, nil
 }
 
-func (js *JetStreamEngine) KVDelete(bucket, key string) error {
-	val, ok := js.kvBuckets.Load(bucket)
-	if !ok {
-		return fmt.Errorf("bucket not found: %s", bucket)
+// KVGetRevision returns key's value as of exactly revision.
+func (js *JetStreamEngine) KVGetRevision(bucket, key string, revision uint64) ([]byte, error) {
+	bkt, err := js.loadKVBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := bkt.GetRevision(key, revision)
+	if err != nil {
+		return nil, err
 	}
+	return entry.Value, nil
+}
 
-	bkt := val.(*KVBucket)
+// KVHistory returns every retained revision of key as JSON.
+func (js *JetStreamEngine) KVHistory(bucket, key string) ([]byte, error) {
+	bkt, err := js.loadKVBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := bkt.History(key)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(history)
+}
+
+// KVKeys lists every key in bucket whose latest revision is a live PUT.
+func (js *JetStreamEngine) KVKeys(bucket string) ([]byte, error) {
+	bkt, err := js.loadKVBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := bkt.Keys()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{"keys": keys})
+}
+
+// KVStatus reports bucket's configuration and live key count.
+func (js *JetStreamEngine) KVStatus(bucket string) ([]byte, error) {
+	bkt, err := js.loadKVBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := bkt.Status()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(status)
+}
+
+func (js *JetStreamEngine) KVDelete(bucket, key string) error {
+	bkt, err := js.loadKVBucket(bucket)
+	if err != nil {
+		return err
+	}
 	return bkt.Delete(key)
 }
 
 func (js *JetStreamEngine) KVPurge(bucket, key string) error {
-	val, ok := js.kvBuckets.Load(bucket)
-	if !ok {
-		return fmt.Errorf("bucket not found: %s", bucket)
+	bkt, err := js.loadKVBucket(bucket)
+	if err != nil {
+		return err
 	}
-
-	bkt := val.(*KVBucket)
 	return bkt.Purge(key)
 }
 
-// Helper functions
-func subjectMatches(pattern, subject string) bool {
-	switch pattern {
-	case ">":
-		return true
-	case "*":
-		return true // technically * only matches one token, but simplifying for MVP
+// KVWatch starts forwarding bucket's entries matching keyPattern (current
+// state first, then an end-of-initial-values marker, then live updates)
+// to deliver. Like HandleObjectWatch, it runs for the connection's
+// lifetime with no wire-level early-stop beyond disconnecting.
+func (js *JetStreamEngine) KVWatch(bucket, keyPattern string, deliver func(*KeyValueEntry)) error {
+	bkt, err := js.getOrCreateKVBucket(bucket)
+	if err != nil {
+		return err
+	}
+
+	ch, err := bkt.Watch(keyPattern)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for entry := range ch {
+			deliver(entry)
+		}
+	}()
+	return nil
+}
+
+func (js *JetStreamEngine) loadKVBucket(bucket string) (*KVBucket, error) {
+	val, ok := js.kvBuckets.Load(bucket)
+	if !ok {
+		return nil, fmt.Errorf("bucket not found: %s", bucket)
 	}
-	// TODO: Full wildcard matching logic
-	return pattern == subject
+	return val.(*KVBucket), nil
 }
@@ -0,0 +1,170 @@
+package nats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubjectMatches(t *testing.T) {
+	cases := []struct {
+		pattern, subject string
+		want             bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.updated", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.created.extra", false},
+		{"orders.>", "orders.created.extra", true},
+		{"orders.>", "orders", false},
+		{">", "anything.at.all", true},
+	}
+	for _, c := range cases {
+		if got := subjectMatches(c.pattern, c.subject); got != c.want {
+			t.Errorf("subjectMatches(%q, %q) = %v, want %v", c.pattern, c.subject, got, c.want)
+		}
+	}
+}
+
+func TestSublist_MatchAndRemove(t *testing.T) {
+	sl := newSublist()
+	sl.Insert("orders.*", "star")
+	sl.Insert("orders.>", "fwc")
+	sl.Insert("orders.created", "literal")
+
+	matches := sl.Match("orders.created")
+	if len(matches) != 3 {
+		t.Fatalf("expected 3 matches, got %d: %v", len(matches), matches)
+	}
+
+	sl.Remove("orders.created", func(v interface{}) bool { return v == "literal" })
+	matches = sl.Match("orders.created")
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches after removal, got %d: %v", len(matches), matches)
+	}
+}
+
+func TestMessageStore_StoreAndGetAcrossBlocks(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewMessageStore(dir, "events", false)
+	if err != nil {
+		t.Fatalf("NewMessageStore failed: %v", err)
+	}
+	store.blockBytes = 1 // force a new block per message
+
+	for i := uint64(1); i <= 3; i++ {
+		if err := store.Store(&Message{Seq: i, Subject: "events.x", Data: []byte("payload"), Time: time.Now()}); err != nil {
+			t.Fatalf("Store(%d) failed: %v", i, err)
+		}
+	}
+
+	msg, err := store.Get(2)
+	if err != nil {
+		t.Fatalf("Get(2) failed: %v", err)
+	}
+	if msg.Seq != 2 {
+		t.Errorf("expected seq 2, got %d", msg.Seq)
+	}
+
+	if count, _, _, ok := store.OldestBlockInfo(); !ok || count != 1 {
+		t.Errorf("expected oldest block to hold 1 message, got %d (ok=%v)", count, ok)
+	}
+
+	dropped, _, err := store.DropOldestBlock()
+	if err != nil {
+		t.Fatalf("DropOldestBlock failed: %v", err)
+	}
+	if dropped != 1 {
+		t.Errorf("expected 1 message dropped, got %d", dropped)
+	}
+	if store.FirstSeq() != 2 {
+		t.Errorf("expected first seq 2 after drop, got %d", store.FirstSeq())
+	}
+}
+
+func newTestEngine(t *testing.T) *JetStreamEngine {
+	dir := t.TempDir()
+	return NewJetStreamEngine(nil, dir)
+}
+
+func TestJetStreamEngine_CreateStreamAndProcessMessage(t *testing.T) {
+	js := newTestEngine(t)
+
+	_, err := js.createStream([]byte(`{"name":"orders","subjects":["orders.*"]}`))
+	if err != nil {
+		t.Fatalf("createStream failed: %v", err)
+	}
+
+	js.ProcessMessage(&Message{Subject: "orders.created", Data: []byte("hi"), Time: time.Now()})
+
+	val, ok := js.streams.Load("orders")
+	if !ok {
+		t.Fatal("stream not found")
+	}
+	stream := val.(*Stream)
+	if stream.state.Msgs != 1 {
+		t.Errorf("expected 1 message stored, got %d", stream.state.Msgs)
+	}
+}
+
+func TestConsumer_PullAndAckFlow(t *testing.T) {
+	js := newTestEngine(t)
+
+	if _, err := js.createStream([]byte(`{"name":"orders","subjects":["orders.*"]}`)); err != nil {
+		t.Fatalf("createStream failed: %v", err)
+	}
+	js.ProcessMessage(&Message{Subject: "orders.created", Data: []byte("one"), Time: time.Now()})
+
+	if _, err := js.createConsumer("orders", []byte(`{"durable_name":"watcher","ack_policy":0}`)); err != nil {
+		t.Fatalf("createConsumer failed: %v", err)
+	}
+
+	val, _ := js.consumers.Load("orders.watcher")
+	consumer := val.(*Consumer)
+
+	msg, err := consumer.nextMessage()
+	if err != nil {
+		t.Fatalf("nextMessage failed: %v", err)
+	}
+	if string(msg.Data) != "one" {
+		t.Errorf("expected payload 'one', got %q", msg.Data)
+	}
+	if consumer.pendingCount() != 1 {
+		t.Fatalf("expected 1 pending ack, got %d", consumer.pendingCount())
+	}
+
+	if err := consumer.Ack(msg.Seq); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+	if consumer.pendingCount() != 0 {
+		t.Errorf("expected 0 pending acks after Ack, got %d", consumer.pendingCount())
+	}
+}
+
+func TestConsumer_RedeliveryAfterAckWaitExpiry(t *testing.T) {
+	js := newTestEngine(t)
+
+	if _, err := js.createStream([]byte(`{"name":"orders","subjects":["orders.*"]}`)); err != nil {
+		t.Fatalf("createStream failed: %v", err)
+	}
+	js.ProcessMessage(&Message{Subject: "orders.created", Data: []byte("one"), Time: time.Now()})
+
+	cfg := `{"durable_name":"watcher","ack_policy":0,"ack_wait":1,"max_deliver":3}`
+	if _, err := js.createConsumer("orders", []byte(cfg)); err != nil {
+		t.Fatalf("createConsumer failed: %v", err)
+	}
+	val, _ := js.consumers.Load("orders.watcher")
+	consumer := val.(*Consumer)
+
+	if _, err := consumer.nextMessage(); err != nil {
+		t.Fatalf("nextMessage failed: %v", err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	due := consumer.duePending()
+	if len(due) != 1 {
+		t.Fatalf("expected 1 due redelivery, got %d", len(due))
+	}
+	if due[0].Deliveries != 2 {
+		t.Errorf("expected delivery count 2, got %d", due[0].Deliveries)
+	}
+}
@@ -0,0 +1,302 @@
+package nats
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultKVHistory is the revision depth a bucket retains per key when
+// created without an explicit history setting.
+const defaultKVHistory = 1
+
+// maxKVHistory bounds how many revisions per key a bucket may be
+// configured to retain.
+const maxKVHistory = 64
+
+// JSErrWrongLastSequence is the NATS JetStream API error code a CAS
+// write returns when its expected revision doesn't match the key's
+// current one - the real nats-server uses the same numeric code for the
+// equivalent wrong-last-sequence condition on streams.
+const JSErrWrongLastSequence = 10071
+
+// KVError carries a JetStream API error code alongside its message, so
+// callers can distinguish a CAS conflict from any other failure instead
+// of string-matching Error().
+type KVError struct {
+	Code    int
+	Message string
+}
+
+func (e *KVError) Error() string { return e.Message }
+
+// kvOperation identifies what a KeyValueEntry represents, mirroring the
+// KV-Operation header nats.go puts on the underlying stream message.
+type kvOperation string
+
+const (
+	kvOpPut   kvOperation = "PUT"
+	kvOpDel   kvOperation = "DEL"
+	kvOpPurge kvOperation = "PURGE"
+)
+
+// KeyValueEntry is one revision of one key, matching nats.go's
+// KeyValueEntry shape closely enough for clients that expect it.
+type KeyValueEntry struct {
+	Bucket    string      `json:"bucket"`
+	Key       string      `json:"key"`
+	Value     []byte      `json:"value,omitempty"`
+	Revision  uint64      `json:"revision"`
+	Created   time.Time   `json:"created"`
+	Operation kvOperation `json:"operation"`
+}
+
+// kvWatcher is one subscriber registered via Watch/WatchAll: pattern is
+// matched against keys with the same wildcard rules as subjects (see
+// subjectMatches), since KV keys use the same dot-separated token shape.
+type kvWatcher struct {
+	ch      chan *KeyValueEntry
+	pattern string
+}
+
+// KVBucket is a JetStream Key-Value bucket: every Put/Update/Delete/
+// Purge appends a KeyValueEntry to an internal KV_<bucket> stream on
+// subject $KV.<bucket>.<key> and to an in-memory per-key history ring
+// capped at the configured depth, the same split ObjBucket uses between
+// its backing stream and its in-memory latest-metadata index.
+type KVBucket struct {
+	name    string
+	stream  *Stream
+	history int
+
+	mu          sync.RWMutex
+	entries     map[string][]*KeyValueEntry // key -> history, oldest first, capped at `history`
+	nextRevLock sync.Mutex
+	nextRev     uint64
+	watchers    []*kvWatcher
+}
+
+func newKVBucket(name string, stream *Stream, history int) *KVBucket {
+	if history <= 0 {
+		history = defaultKVHistory
+	}
+	if history > maxKVHistory {
+		history = maxKVHistory
+	}
+	return &KVBucket{
+		name:    name,
+		stream:  stream,
+		history: history,
+		entries: make(map[string][]*KeyValueEntry),
+	}
+}
+
+// Put appends a new PUT revision for key, unconditionally.
+func (b *KVBucket) Put(key string, value []byte, headers map[string]string) (uint64, error) {
+	return b.append(key, value, kvOpPut, 0, false)
+}
+
+// Update appends a new PUT revision for key only if its current latest
+// revision equals lastRevision, failing with a conflict error otherwise
+// (optimistic concurrency, matching nats.go's Update).
+func (b *KVBucket) Update(key string, value []byte, lastRevision uint64) (uint64, error) {
+	return b.append(key, value, kvOpPut, lastRevision, true)
+}
+
+func (b *KVBucket) append(key string, value []byte, op kvOperation, expectedRev uint64, checkRev bool) (uint64, error) {
+	b.mu.Lock()
+
+	if checkRev {
+		var current uint64
+		if hist := b.entries[key]; len(hist) > 0 {
+			current = hist[len(hist)-1].Revision
+		}
+		if current != expectedRev {
+			b.mu.Unlock()
+			return 0, &KVError{
+				Code:    JSErrWrongLastSequence,
+				Message: fmt.Sprintf("kv: conflict updating %q: expected revision %d, current is %d", key, expectedRev, current),
+			}
+		}
+	}
+
+	rev := b.nextRevision()
+	entry := &KeyValueEntry{
+		Bucket:    b.name,
+		Key:       key,
+		Value:     value,
+		Revision:  rev,
+		Created:   time.Now(),
+		Operation: op,
+	}
+
+	hist := append(b.entries[key], entry)
+	if op == kvOpPurge {
+		// Purge drops prior revisions entirely, keeping only the marker.
+		hist = hist[len(hist)-1:]
+	} else if len(hist) > b.history {
+		hist = hist[len(hist)-b.history:]
+	}
+	b.entries[key] = hist
+
+	watchers := append([]*kvWatcher(nil), b.watchers...)
+	b.mu.Unlock()
+
+	b.stream.addMessage(&Message{
+		Subject: fmt.Sprintf("$KV.%s.%s", b.name, key),
+		Data:    value,
+		Headers: map[string]string{"KV-Operation": string(op)},
+		Time:    entry.Created,
+	})
+
+	b.notify(watchers, entry)
+	return rev, nil
+}
+
+func (b *KVBucket) nextRevision() uint64 {
+	b.nextRevLock.Lock()
+	defer b.nextRevLock.Unlock()
+	b.nextRev++
+	return b.nextRev
+}
+
+func (b *KVBucket) notify(watchers []*kvWatcher, entry *KeyValueEntry) {
+	for _, w := range watchers {
+		if !subjectMatches(w.pattern, entry.Key) {
+			continue
+		}
+		select {
+		case w.ch <- entry:
+		default:
+		}
+	}
+}
+
+// Get returns the latest revision of key, failing if it doesn't exist
+// or its latest revision is a DEL/PURGE tombstone.
+func (b *KVBucket) Get(key string) (*KeyValueEntry, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	hist := b.entries[key]
+	if len(hist) == 0 {
+		return nil, fmt.Errorf("key not found: %s", key)
+	}
+	latest := hist[len(hist)-1]
+	if latest.Operation != kvOpPut {
+		return nil, fmt.Errorf("key not found: %s", key)
+	}
+	return latest, nil
+}
+
+// GetRevision returns key's entry at exactly revision, even if it's no
+// longer the latest or has since been superseded by a delete.
+func (b *KVBucket) GetRevision(key string, revision uint64) (*KeyValueEntry, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, entry := range b.entries[key] {
+		if entry.Revision == revision {
+			return entry, nil
+		}
+	}
+	return nil, fmt.Errorf("kv: revision %d not found for key %q", revision, key)
+}
+
+// History returns every retained revision of key, oldest first.
+func (b *KVBucket) History(key string) ([]*KeyValueEntry, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	hist := b.entries[key]
+	if len(hist) == 0 {
+		return nil, fmt.Errorf("key not found: %s", key)
+	}
+	return append([]*KeyValueEntry(nil), hist...), nil
+}
+
+// Delete appends a DEL tombstone for key, retaining its prior history.
+func (b *KVBucket) Delete(key string) error {
+	_, err := b.append(key, nil, kvOpDel, 0, false)
+	return err
+}
+
+// Purge appends a PURGE tombstone for key and discards its prior
+// revisions, keeping only the marker.
+func (b *KVBucket) Purge(key string) error {
+	_, err := b.append(key, nil, kvOpPurge, 0, false)
+	return err
+}
+
+// Keys returns every key whose latest revision is a live PUT (i.e.
+// excludes keys whose most recent operation was DEL or PURGE).
+func (b *KVBucket) Keys() ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var keys []string
+	for key, hist := range b.entries {
+		if len(hist) > 0 && hist[len(hist)-1].Operation == kvOpPut {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// KVStatus summarizes a bucket's configuration and size, matching the
+// shape of nats.go's KeyValueStatus closely enough for clients that
+// expect it.
+type KVStatus struct {
+	Bucket  string `json:"bucket"`
+	Values  uint64 `json:"values"`
+	History int    `json:"history"`
+}
+
+// Status reports the bucket's configuration and live (non-deleted) key
+// count.
+func (b *KVBucket) Status() (*KVStatus, error) {
+	keys, err := b.Keys()
+	if err != nil {
+		return nil, err
+	}
+	return &KVStatus{Bucket: b.name, Values: uint64(len(keys)), History: b.history}, nil
+}
+
+// kvWatchDone is the sentinel entry notify-of-initial-values watchers
+// receive after their replay of current state finishes, mirroring
+// nats.go's nil-entry "end of initial values" marker. It's a concrete
+// value rather than nil so callers consuming the channel as plain
+// *KeyValueEntry can still distinguish "no more values right now" (nil,
+// selecting default) from "initial replay is over".
+var kvWatchDone = &KeyValueEntry{Operation: "EOID"}
+
+// Watch returns a channel that replays every key currently matching
+// pattern, then kvWatchDone, then live PUT/DEL/PURGE events for keys
+// matching pattern as they happen. pattern follows the same wildcard
+// rules as subjects (`*` one token, `>` trailing tokens).
+func (b *KVBucket) Watch(pattern string) (<-chan *KeyValueEntry, error) {
+	ch := make(chan *KeyValueEntry, 64)
+
+	b.mu.Lock()
+	w := &kvWatcher{ch: ch, pattern: pattern}
+	b.watchers = append(b.watchers, w)
+	for key, hist := range b.entries {
+		if len(hist) == 0 || !subjectMatches(pattern, key) {
+			continue
+		}
+		latest := hist[len(hist)-1]
+		select {
+		case ch <- latest:
+		default:
+		}
+	}
+	b.mu.Unlock()
+
+	ch <- kvWatchDone
+	return ch, nil
+}
+
+// WatchAll is equivalent to Watch(">").
+func (b *KVBucket) WatchAll() (<-chan *KeyValueEntry, error) {
+	return b.Watch(">")
+}
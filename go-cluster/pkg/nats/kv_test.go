@@ -0,0 +1,149 @@
+package nats
+
+import "testing"
+
+func TestKVBucket_PutGetAndHistory(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.createKVBucket("cfg", 3)
+	if err != nil {
+		t.Fatalf("createKVBucket failed: %v", err)
+	}
+
+	for _, v := range []string{"v1", "v2", "v3", "v4"} {
+		if _, err := bkt.Put("flag", []byte(v), nil); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	entry, err := bkt.Get("flag")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(entry.Value) != "v4" {
+		t.Errorf("expected latest value 'v4', got %q", entry.Value)
+	}
+
+	history, err := bkt.History("flag")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected history capped at 3, got %d", len(history))
+	}
+	if string(history[0].Value) != "v2" {
+		t.Errorf("expected oldest retained value 'v2', got %q", history[0].Value)
+	}
+}
+
+func TestKVBucket_GetRevision(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.createKVBucket("cfg", 5)
+	if err != nil {
+		t.Fatalf("createKVBucket failed: %v", err)
+	}
+
+	rev1, _ := bkt.Put("flag", []byte("v1"), nil)
+	_, _ = bkt.Put("flag", []byte("v2"), nil)
+
+	entry, err := bkt.GetRevision("flag", rev1)
+	if err != nil {
+		t.Fatalf("GetRevision failed: %v", err)
+	}
+	if string(entry.Value) != "v1" {
+		t.Errorf("expected v1 at revision %d, got %q", rev1, entry.Value)
+	}
+}
+
+func TestKVBucket_UpdateRejectsStaleRevision(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.createKVBucket("cfg", 5)
+	if err != nil {
+		t.Fatalf("createKVBucket failed: %v", err)
+	}
+
+	rev, _ := bkt.Put("flag", []byte("v1"), nil)
+	if _, err := bkt.Update("flag", []byte("v2"), rev); err != nil {
+		t.Fatalf("expected Update with correct revision to succeed: %v", err)
+	}
+	if _, err := bkt.Update("flag", []byte("v3"), rev); err == nil {
+		t.Error("expected Update with stale revision to fail")
+	}
+}
+
+func TestKVBucket_DeleteAndPurge(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.createKVBucket("cfg", 5)
+	if err != nil {
+		t.Fatalf("createKVBucket failed: %v", err)
+	}
+
+	_, _ = bkt.Put("flag", []byte("v1"), nil)
+	if err := bkt.Delete("flag"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := bkt.Get("flag"); err == nil {
+		t.Error("expected Get on a deleted key to fail")
+	}
+	if history, _ := bkt.History("flag"); len(history) != 2 {
+		t.Errorf("expected Delete to retain history (put + tombstone), got %d entries", len(history))
+	}
+
+	if err := bkt.Purge("flag"); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	history, err := bkt.History("flag")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Operation != kvOpPurge {
+		t.Errorf("expected Purge to collapse history to just its tombstone, got %v", history)
+	}
+}
+
+func TestKVBucket_KeysExcludesDeleted(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.createKVBucket("cfg", 5)
+	if err != nil {
+		t.Fatalf("createKVBucket failed: %v", err)
+	}
+
+	_, _ = bkt.Put("a", []byte("1"), nil)
+	_, _ = bkt.Put("b", []byte("2"), nil)
+	_ = bkt.Delete("b")
+
+	keys, err := bkt.Keys()
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("expected only 'a', got %v", keys)
+	}
+}
+
+func TestKVBucket_WatchReplaysThenEmitsLiveUpdates(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.createKVBucket("cfg", 5)
+	if err != nil {
+		t.Fatalf("createKVBucket failed: %v", err)
+	}
+	_, _ = bkt.Put("flag", []byte("v1"), nil)
+
+	ch, err := bkt.Watch(">")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	first := <-ch
+	if first.Key != "flag" {
+		t.Errorf("expected replay of 'flag', got %q", first.Key)
+	}
+	if marker := <-ch; marker != kvWatchDone {
+		t.Error("expected end-of-initial-values marker after replay")
+	}
+
+	_, _ = bkt.Put("flag", []byte("v2"), nil)
+	update := <-ch
+	if string(update.Value) != "v2" {
+		t.Errorf("expected live update value 'v2', got %q", update.Value)
+	}
+}
@@ -0,0 +1,249 @@
+package nats
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultObjectChunkSize is the size objects are split into before being
+// stored as individual stream messages, matching the nats.go Object
+// Store default.
+const defaultObjectChunkSize = 128 * 1024
+
+// ObjectStoreConfig configures a bucket's backing stream.
+type ObjectStoreConfig struct {
+	Bucket      string      `json:"bucket"`
+	Description string      `json:"description,omitempty"`
+	MaxBytes    int64       `json:"max_bytes,omitempty"`
+	Storage     StorageType `json:"storage"`
+	Replicas    int         `json:"num_replicas"`
+}
+
+// ObjectMeta is the caller-supplied description of an object, carried
+// alongside the generated bookkeeping fields in ObjectInfo.
+type ObjectMeta struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// ObjectInfo is the metadata record published to $O.<bucket>.M.<name>
+// for every Put, Delete, or AddLink, mirroring nats.go's ObjectInfo.
+type ObjectInfo struct {
+	ObjectMeta
+	Bucket  string    `json:"bucket"`
+	NUID    string    `json:"nuid"`
+	Size    uint64    `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Chunks  uint32    `json:"chunks"`
+	Digest  string    `json:"digest,omitempty"`
+	Deleted bool      `json:"deleted,omitempty"`
+
+	// chunkSeqs holds the backing stream sequences of this object's
+	// chunks, in order, so Get can reassemble it without a subject
+	// lookup. It isn't part of the wire format real nats.go clients see.
+	chunkSeqs []uint64
+}
+
+var objectNUIDCounter uint64
+
+// nextObjectNUID returns a process-unique identifier for one chunk or
+// object revision. A real nats.go server uses the nuid package; this is
+// a simpler stand-in of the same shape the rest of this package already
+// uses for ids (see the ephemeral consumer name in createConsumer).
+func nextObjectNUID() string {
+	n := atomic.AddUint64(&objectNUIDCounter, 1)
+	return fmt.Sprintf("%d.%d", time.Now().UnixNano(), n)
+}
+
+// ObjBucket is a JetStream Object Store bucket: objects are split into
+// fixed-size chunks published as messages on an internal OBJ_<bucket>
+// stream's $O.<bucket>.C.<nuid> subject, with an ObjectInfo metadata
+// record published to $O.<bucket>.M.<name> after every chunk is
+// stored.
+type ObjBucket struct {
+	name   string
+	stream *Stream
+
+	mu       sync.RWMutex
+	latest   map[string]*ObjectInfo // name -> most recent info, including tombstones
+	watchers []chan *ObjectInfo
+}
+
+func newObjBucket(name string, stream *Stream) *ObjBucket {
+	return &ObjBucket{
+		name:   name,
+		stream: stream,
+		latest: make(map[string]*ObjectInfo),
+	}
+}
+
+// Put chunks data, stores each chunk as a stream message, and publishes
+// the resulting ObjectInfo as the object's new metadata record.
+func (b *ObjBucket) Put(meta ObjectMeta, data []byte) (*ObjectInfo, error) {
+	if meta.Name == "" {
+		return nil, fmt.Errorf("object name required")
+	}
+
+	hasher := sha256.New()
+	var seqs []uint64
+	for offset := 0; offset == 0 || offset < len(data); offset += defaultObjectChunkSize {
+		end := offset + defaultObjectChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[offset:end]
+		hasher.Write(chunk)
+
+		msg := &Message{
+			Subject: fmt.Sprintf("$O.%s.C.%s", b.name, nextObjectNUID()),
+			Data:    append([]byte(nil), chunk...),
+			Time:    time.Now(),
+		}
+		b.stream.addMessage(msg)
+		seqs = append(seqs, msg.Seq)
+
+		if len(data) == 0 {
+			break
+		}
+	}
+
+	info := &ObjectInfo{
+		ObjectMeta: meta,
+		Bucket:     b.name,
+		NUID:       nextObjectNUID(),
+		Size:       uint64(len(data)),
+		ModTime:    time.Now(),
+		Chunks:     uint32(len(seqs)),
+		Digest:     "SHA-256=" + hex.EncodeToString(hasher.Sum(nil)),
+		chunkSeqs:  seqs,
+	}
+	b.putMeta(info)
+	return info, nil
+}
+
+// Get reassembles the named object's bytes in chunk order.
+func (b *ObjBucket) Get(name string) ([]byte, *ObjectInfo, error) {
+	b.mu.RLock()
+	info, ok := b.latest[name]
+	b.mu.RUnlock()
+	if !ok || info.Deleted {
+		return nil, nil, fmt.Errorf("object not found: %s", name)
+	}
+
+	var buf bytes.Buffer
+	for _, seq := range info.chunkSeqs {
+		msg, err := b.stream.messages.Get(seq)
+		if err != nil {
+			return nil, nil, fmt.Errorf("object store: failed to read chunk %d: %w", seq, err)
+		}
+		buf.Write(msg.Data)
+	}
+	return buf.Bytes(), info, nil
+}
+
+// Delete publishes a tombstone metadata record for name. Its chunk
+// messages aren't purged individually — like STREAM.MSG.DELETE, the
+// block-file store only evicts whole blocks (see Stream.applyLimits),
+// so their bytes are freed once the blocks holding them age out.
+func (b *ObjBucket) Delete(name string) error {
+	b.mu.RLock()
+	info, ok := b.latest[name]
+	b.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("object not found: %s", name)
+	}
+
+	tombstone := *info
+	tombstone.Deleted = true
+	tombstone.ModTime = time.Now()
+	tombstone.chunkSeqs = nil
+	b.putMeta(&tombstone)
+	return nil
+}
+
+// AddLink publishes a new metadata record under name that points at
+// target's existing chunks, without copying any chunk data.
+func (b *ObjBucket) AddLink(name string, target *ObjectInfo) (*ObjectInfo, error) {
+	if target == nil {
+		return nil, fmt.Errorf("link target required")
+	}
+	if target.Deleted {
+		return nil, fmt.Errorf("cannot link to deleted object: %s", target.Name)
+	}
+
+	info := &ObjectInfo{
+		ObjectMeta: ObjectMeta{Name: name},
+		Bucket:     b.name,
+		NUID:       nextObjectNUID(),
+		Size:       target.Size,
+		ModTime:    time.Now(),
+		Chunks:     target.Chunks,
+		Digest:     target.Digest,
+		chunkSeqs:  target.chunkSeqs,
+	}
+	b.putMeta(info)
+	return info, nil
+}
+
+// Info returns the most recent ObjectInfo stored under name, including
+// tombstones left by Delete.
+func (b *ObjBucket) Info(name string) (*ObjectInfo, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	info, ok := b.latest[name]
+	return info, ok
+}
+
+// Watch returns a channel that receives this bucket's current objects
+// followed by every subsequent ObjectInfo update, mimicking an ordered
+// ephemeral consumer on the bucket's metadata subject. The channel is
+// buffered and best-effort: a slow reader misses updates rather than
+// blocking Put/Delete/AddLink.
+func (b *ObjBucket) Watch() <-chan *ObjectInfo {
+	ch := make(chan *ObjectInfo, 32)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.watchers = append(b.watchers, ch)
+	for _, info := range b.latest {
+		select {
+		case ch <- info:
+		default:
+		}
+	}
+	return ch
+}
+
+func (b *ObjBucket) putMeta(info *ObjectInfo) {
+	b.mu.Lock()
+	b.latest[info.Name] = info
+	watchers := append([]chan *ObjectInfo(nil), b.watchers...)
+	b.mu.Unlock()
+
+	// Best-effort: a metadata record that fails to encode still updates
+	// in-memory state and notifies watchers, same as the rest of this
+	// package treats persistence as important-but-not-load-bearing for
+	// readers already holding a reference to the info.
+	if payload, err := json.Marshal(info); err == nil {
+		b.stream.addMessage(&Message{
+			Subject: fmt.Sprintf("$O.%s.M.%s", b.name, info.Name),
+			Data:    payload,
+			Time:    info.ModTime,
+		})
+	}
+
+	for _, ch := range watchers {
+		select {
+		case ch <- info:
+		default:
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package nats
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestObjBucket_PutGetRoundTrip(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.getOrCreateObjBucket("files")
+	if err != nil {
+		t.Fatalf("getOrCreateObjBucket failed: %v", err)
+	}
+
+	data := bytes.Repeat([]byte("x"), defaultObjectChunkSize+10) // spans 2 chunks
+	info, err := bkt.Put(ObjectMeta{Name: "big.bin"}, data)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if info.Chunks != 2 {
+		t.Errorf("expected 2 chunks, got %d", info.Chunks)
+	}
+
+	got, gotInfo, err := bkt.Get("big.bin")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("round-tripped object data does not match what was put")
+	}
+	if gotInfo.Digest != info.Digest {
+		t.Errorf("expected digest %q, got %q", info.Digest, gotInfo.Digest)
+	}
+}
+
+func TestObjBucket_DeleteTombstonesObject(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.getOrCreateObjBucket("files")
+	if err != nil {
+		t.Fatalf("getOrCreateObjBucket failed: %v", err)
+	}
+
+	if _, err := bkt.Put(ObjectMeta{Name: "a.txt"}, []byte("hello")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := bkt.Delete("a.txt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if _, _, err := bkt.Get("a.txt"); err == nil {
+		t.Error("expected Get on a deleted object to fail")
+	}
+}
+
+func TestObjBucket_AddLinkSharesChunks(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.getOrCreateObjBucket("files")
+	if err != nil {
+		t.Fatalf("getOrCreateObjBucket failed: %v", err)
+	}
+
+	original, err := bkt.Put(ObjectMeta{Name: "a.txt"}, []byte("hello"))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	link, err := bkt.AddLink("alias.txt", original)
+	if err != nil {
+		t.Fatalf("AddLink failed: %v", err)
+	}
+	if link.Digest != original.Digest {
+		t.Errorf("expected linked object to share digest %q, got %q", original.Digest, link.Digest)
+	}
+
+	data, _, err := bkt.Get("alias.txt")
+	if err != nil {
+		t.Fatalf("Get on linked object failed: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("expected linked object data 'hello', got %q", data)
+	}
+}
+
+func TestObjBucket_WatchReceivesCurrentAndLiveUpdates(t *testing.T) {
+	js := newTestEngine(t)
+	bkt, err := js.getOrCreateObjBucket("files")
+	if err != nil {
+		t.Fatalf("getOrCreateObjBucket failed: %v", err)
+	}
+
+	if _, err := bkt.Put(ObjectMeta{Name: "existing.txt"}, []byte("old")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	ch := bkt.Watch()
+	select {
+	case info := <-ch:
+		if info.Name != "existing.txt" {
+			t.Errorf("expected replay of existing object, got %q", info.Name)
+		}
+	default:
+		t.Fatal("expected Watch to replay the current object immediately")
+	}
+
+	if _, err := bkt.Put(ObjectMeta{Name: "new.txt"}, []byte("new")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	select {
+	case info := <-ch:
+		if info.Name != "new.txt" {
+			t.Errorf("expected live update for 'new.txt', got %q", info.Name)
+		}
+	default:
+		t.Fatal("expected Watch to receive the live Put update")
+	}
+}
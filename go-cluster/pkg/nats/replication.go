@@ -0,0 +1,215 @@
+package nats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+)
+
+// streamCreateOp is the cluster.Command op this package registers with
+// cluster.Node via AttachCluster, so that a replicated stream's topology
+// (its StreamConfig) is created in lockstep on every cluster member
+// instead of only the node that received the original CREATE call.
+const streamCreateOp = "jetstream_stream_create"
+
+// AttachCluster wires js to a cluster.Node so that streams created with
+// StreamConfig.Replicas > 1 propose their topology through the node's
+// Raft log rather than being created locally right away: createStream
+// proposes the config, and every member (including the proposer)
+// actually creates its local Stream from the command hook fired as that
+// node's FSM applies the committed entry. This keeps stream topology
+// consistent cluster-wide the same way cmd/main.go already replicates
+// collection writes, without pkg/cluster importing back into pkg/nats.
+func (js *JetStreamEngine) AttachCluster(node *cluster.Node) {
+	js.node = node
+	node.RegisterCommandHook(streamCreateOp, js.applyStreamCreate)
+}
+
+// applyStreamCreate is the command hook invoked on every cluster member
+// as js.node's FSM applies a committed streamCreateOp entry.
+func (js *JetStreamEngine) applyStreamCreate(value json.RawMessage) error {
+	var config StreamConfig
+	if err := json.Unmarshal(value, &config); err != nil {
+		return fmt.Errorf("nats: invalid replicated stream config: %w", err)
+	}
+	if _, exists := js.streams.Load(config.Name); exists {
+		return nil
+	}
+	if _, err := js.createStreamLocal(config); err != nil {
+		return err
+	}
+	return js.startStreamGroup(config.Name)
+}
+
+// proposeStreamCreate replicates config through js.node's Raft log and
+// waits for it to commit. By the time Apply returns, this node's own FSM
+// has already run applyStreamCreate, so the local Stream already exists;
+// other members run it as they apply the committed entry.
+func (js *JetStreamEngine) proposeStreamCreate(config StreamConfig) ([]byte, error) {
+	value, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &cluster.Command{Op: streamCreateOp, Collection: "jetstream", Key: config.Name, Value: value}
+	if err := js.node.Apply(cmd, 5*time.Second); err != nil {
+		return nil, fmt.Errorf("nats: failed to replicate stream topology: %w", err)
+	}
+	return js.streamInfo(config.Name)
+}
+
+// streamCommand is the log entry type applied to one stream's own Raft
+// group, distinct from cluster.Command which only carries the cluster's
+// global key/value collection writes.
+type streamCommand struct {
+	Op       string   `json:"op"` // "append" or "ack"
+	Message  *Message `json:"message,omitempty"`
+	Consumer string   `json:"consumer,omitempty"`
+	Seq      uint64   `json:"seq,omitempty"`
+}
+
+// streamGroup is one stream's dedicated Raft group: a replicated message
+// append log plus durable-consumer ack state, kept independent of every
+// other stream's group so that busy streams don't serialize against each
+// other the way they would sharing js.node's single global group.
+type streamGroup struct {
+	raft *raft.Raft
+	fsm  *streamFSM
+}
+
+type streamFSM struct {
+	stream *Stream
+}
+
+func (f *streamFSM) Apply(log *raft.Log) interface{} {
+	var cmd streamCommand
+	if err := json.Unmarshal(log.Data, &cmd); err != nil {
+		return err
+	}
+
+	switch cmd.Op {
+	case "append":
+		f.stream.addMessage(cmd.Message)
+	case "ack":
+		if v, ok := f.stream.consumers.Load(cmd.Consumer); ok {
+			v.(*Consumer).Ack(cmd.Seq)
+		}
+	}
+	return nil
+}
+
+func (f *streamFSM) Snapshot() (raft.FSMSnapshot, error) {
+	return &streamSnapshot{}, nil
+}
+
+func (f *streamFSM) Restore(rc io.ReadCloser) error {
+	return rc.Close()
+}
+
+// streamSnapshot is a no-op FSMSnapshot: a stream group's state is
+// entirely derived from replaying its own bolt-backed Raft log, the same
+// way MessageStore's block files are the durable record rather than a
+// separate snapshot format.
+type streamSnapshot struct{}
+
+func (s *streamSnapshot) Persist(sink raft.SnapshotSink) error { return sink.Close() }
+func (s *streamSnapshot) Release()                             {}
+
+// startStreamGroup bootstraps a single-voter Raft group for stream, over
+// an in-memory transport. Additional members join the same way any other
+// Raft group does, via raft.AddVoter, once the cluster membership layer
+// exchanges per-stream addresses (not yet wired up); until then the group
+// still gives a replicated, crash-consistent append log for the stream on
+// this node, and reports itself as its own leader.
+func (js *JetStreamEngine) startStreamGroup(name string) error {
+	val, ok := js.streams.Load(name)
+	if !ok {
+		return fmt.Errorf("nats: cannot start raft group for unknown stream: %s", name)
+	}
+	stream := val.(*Stream)
+
+	cfg := js.node.GetConfig()
+	dir := filepath.Join(cfg.DataDir, "jetstream-raft", name)
+
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(cfg.RaftAddr)
+
+	_, transport := raft.NewInmemTransport(raft.ServerAddress(cfg.RaftAddr))
+
+	logStore, err := raftboltdb.NewBoltStore(filepath.Join(dir, "raft-log.db"))
+	if err != nil {
+		return fmt.Errorf("nats: failed to open raft log store for stream %s: %w", name, err)
+	}
+	stableStore, err := raftboltdb.NewBoltStore(filepath.Join(dir, "raft-stable.db"))
+	if err != nil {
+		return fmt.Errorf("nats: failed to open raft stable store for stream %s: %w", name, err)
+	}
+	snapshotStore, err := raft.NewFileSnapshotStore(dir, 2, io.Discard)
+	if err != nil {
+		return fmt.Errorf("nats: failed to open raft snapshot store for stream %s: %w", name, err)
+	}
+
+	fsm := &streamFSM{stream: stream}
+	r, err := raft.NewRaft(raftConfig, fsm, logStore, stableStore, snapshotStore, transport)
+	if err != nil {
+		return fmt.Errorf("nats: failed to start raft group for stream %s: %w", name, err)
+	}
+
+	r.BootstrapCluster(raft.Configuration{
+		Servers: []raft.Server{{ID: raftConfig.LocalID, Address: transport.LocalAddr()}},
+	})
+
+	group := &streamGroup{raft: r, fsm: fsm}
+	js.streamGroups.Store(name, group)
+	return nil
+}
+
+// proposeAppend replicates msg through stream's Raft group before it's
+// stored, so every member's MessageStore ends up with the same ordered
+// log. Callers must already hold no lock on the stream; addMessage takes
+// its own.
+func (g *streamGroup) proposeAppend(msg *Message) error {
+	data, err := json.Marshal(streamCommand{Op: "append", Message: msg})
+	if err != nil {
+		return err
+	}
+	return g.raft.Apply(data, 5*time.Second).Error()
+}
+
+// proposeAck replicates a durable consumer's ack through stream's Raft
+// group, so AckFloor and ackPending survive a leader failover.
+func (g *streamGroup) proposeAck(consumerName string, seq uint64) error {
+	data, err := json.Marshal(streamCommand{Op: "ack", Consumer: consumerName, Seq: seq})
+	if err != nil {
+		return err
+	}
+	return g.raft.Apply(data, 5*time.Second).Error()
+}
+
+// clusterInfo reports the per-stream group's leader and replica set in
+// the same shape NATS JetStream's own stream info response uses, or nil
+// if name isn't a replicated stream.
+func (js *JetStreamEngine) clusterInfo(name string) map[string]interface{} {
+	val, ok := js.streamGroups.Load(name)
+	if !ok {
+		return nil
+	}
+	group := val.(*streamGroup)
+
+	var replicas []string
+	if future := group.raft.GetConfiguration(); future.Error() == nil {
+		for _, srv := range future.Configuration().Servers {
+			replicas = append(replicas, string(srv.ID))
+		}
+	}
+
+	return map[string]interface{}{
+		"leader":   string(group.raft.Leader()),
+		"replicas": replicas,
+	}
+}
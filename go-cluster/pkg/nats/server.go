@@ -2,6 +2,7 @@ package nats
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -14,10 +15,23 @@ import (
 
 // Server implements NATS protocol server
 type Server struct {
-	listener   net.Listener
-	jetstream  *JetStreamEngine
-	clients    sync.Map
-	nextCID    uint64
+	listener net.Listener
+
+	jetstream *JetStreamEngine
+	clients   sync.Map
+	nextCID   uint64
+
+	// subs is a subject-interest trie over every client's subscriptions,
+	// so routeMessage only visits subscriptions whose pattern could
+	// possibly match instead of every client's every subscription.
+	subs *sublist
+
+	// queueCounters round-robins delivery within a queue group: one
+	// *uint64 per queue name, incremented on every delivery decision so
+	// concurrent publishes still spread across members instead of racing
+	// on a single shared index.
+	queueCounters sync.Map
+
 	opts       *Options
 	running    atomic.Bool
 	shutdownCh chan struct{}
@@ -35,6 +49,7 @@ type Options struct {
 func NewServer(opts *Options, js *JetStreamEngine) *Server {
 	return &Server{
 		jetstream:  js,
+		subs:       newSublist(),
 		opts:       opts,
 		shutdownCh: make(chan struct{}),
 	}
@@ -71,26 +86,55 @@ func (s *Server) acceptLoop() {
 }
 
 func (s *Server) routeMessage(msg *Message) {
-	// Route to interested subscribers (simple O(N) loop for MVP)
-	s.clients.Range(func(key, value any) bool {
-		c := value.(*Client)
-		c.subs.Range(func(sid, subVal any) bool {
-			sub := subVal.(*Subscription)
-			if sub.subject == msg.Subject || sub.subject == ">" {
-				c.sendMessage(sub.subject, msg.Data)
-			}
-			return true
-		})
-		return true
-	})
+	matches := s.subs.Match(msg.Subject)
+
+	// Queue subscribers share the load: every matching queue group gets
+	// exactly one delivery, to a member picked round-robin; plain
+	// subscribers each get their own delivery as before.
+	queues := make(map[string][]*Subscription)
+	for _, v := range matches {
+		sub := v.(*Subscription)
+		if sub.queue == "" {
+			s.deliver(sub, msg)
+			continue
+		}
+		queues[sub.queue] = append(queues[sub.queue], sub)
+	}
+
+	for queue, members := range queues {
+		s.deliver(members[s.nextQueueMember(queue, len(members))], msg)
+	}
+}
+
+// nextQueueMember returns the index, mod n, of the next member of queue
+// to receive a message.
+func (s *Server) nextQueueMember(queue string, n int) int {
+	v, _ := s.queueCounters.LoadOrStore(queue, new(uint64))
+	counter := v.(*uint64)
+	return int(atomic.AddUint64(counter, 1) % uint64(n))
+}
+
+// deliver sends msg to sub and, once sub has reached its max-msgs limit
+// (see handleUnsubscribe's `UNSUB <sid> [max-msgs]` form), auto-
+// unsubscribes it. The LoadAndDelete guards against a concurrent
+// handleUnsubscribe/close removing the same subscription twice.
+func (s *Server) deliver(sub *Subscription, msg *Message) {
+	pending := atomic.AddInt64(&sub.pending, 1)
+	sub.client.sendMsgWithHeaders(msg.Subject, msg.Headers, msg.Data)
+
+	if max := atomic.LoadInt64(&sub.maxMsgs); max > 0 && pending >= max {
+		if _, ok := sub.client.subs.LoadAndDelete(sub.sid); ok {
+			s.removeSubscription(sub)
+		}
+	}
 }
 
 func (s *Server) addSubscription(sub *Subscription) {
-	// In a real implementation, you'd add to a radix tree or similar structure
+	s.subs.Insert(sub.subject, sub)
 }
 
 func (s *Server) removeSubscription(sub *Subscription) {
-	// Remove from tracking
+	s.subs.Remove(sub.subject, func(v interface{}) bool { return v.(*Subscription) == sub })
 }
 
 // Client represents a NATS client connection
@@ -103,6 +147,12 @@ type Client struct {
 	subs   sync.Map // sid -> subscription
 	mu     sync.Mutex
 	closed atomic.Bool
+
+	// headersOK records whether CONNECT advertised "headers":true. Set
+	// once from the client's own read loop but read concurrently from
+	// other clients' delivery goroutines (via deliver -> sendMsgWithHeaders),
+	// hence atomic.
+	headersOK atomic.Bool
 }
 
 func newClient(cid uint64, conn net.Conn, server *Server) *Client {
@@ -142,7 +192,9 @@ func (c *Client) processLine(line string) {
 
 	switch cmd {
 	case "CONNECT":
-		// Ignore connect payload for now
+		if len(parts) > 1 {
+			c.handleConnect(parts[1])
+		}
 	case "PING":
 		c.sendPong()
 	case "PONG":
@@ -164,43 +216,80 @@ func (c *Client) processLine(line string) {
 	}
 }
 
+// handlePublish parses both PUB and HPUB:
+//
+//	PUB  <subject> [reply-to] <#bytes>
+//	HPUB <subject> [reply-to] <hdr_len> <total_len>
+//
+// For HPUB, the first hdr_len bytes of the payload are the NATS v2
+// header block (see parseHeaders); the rest is the message body.
 func (c *Client) handlePublish(line string) {
-	// Parse: PUB <subject> [reply-to] <#bytes>
 	parts := strings.Fields(line)
+	isHeader := strings.EqualFold(parts[0], "HPUB")
 
-	// Simplified parsing logic
 	var subject, replyTo string
-	var payloadSize int
+	var hdrLen, totalLen int
 
 	idx := 1
 	subject = parts[idx]
 	idx++
 
-	if len(parts) == 4 {
-		replyTo = parts[idx]
+	if isHeader {
+		if len(parts) == 5 {
+			replyTo = parts[idx]
+			idx++
+		}
+		hdrLen, _ = strconv.Atoi(parts[idx])
 		idx++
-	}
-
-	if idx < len(parts) {
-		payloadSize, _ = strconv.Atoi(parts[idx])
+		totalLen, _ = strconv.Atoi(parts[idx])
+	} else {
+		if len(parts) == 4 {
+			replyTo = parts[idx]
+			idx++
+		}
+		if idx < len(parts) {
+			totalLen, _ = strconv.Atoi(parts[idx])
+		}
 	}
 
 	// Read payload
-	payload := make([]byte, payloadSize)
-	io.ReadFull(c.reader, payload)
+	full := make([]byte, totalLen)
+	io.ReadFull(c.reader, full)
 	c.reader.ReadString('\n') // Consume trailing \r\n
 
+	headers := make(map[string]string)
+	payload := full
+	if isHeader {
+		if hdrLen > len(full) {
+			hdrLen = len(full)
+		}
+		headers = parseHeaders(full[:hdrLen])
+		payload = full[hdrLen:]
+	}
+
 	// Check if JetStream subject
 	if strings.HasPrefix(subject, "$JS.API.") {
-		// Mock headers for now
-		headers := make(map[string]string)
 		c.handleJetStreamAPI(subject, replyTo, headers, payload)
 		return
 	}
 
+	// Acks are published to the reply subject handed out with each
+	// delivered message, of the form $JS.ACK.<stream>.<consumer>....
+	if strings.HasPrefix(subject, "$JS.ACK.") {
+		if c.server.jetstream != nil {
+			if err := c.server.jetstream.HandleAck(subject, payload); err != nil {
+				c.sendError(err.Error())
+				return
+			}
+		}
+		if replyTo != "" {
+			c.sendOK()
+		}
+		return
+	}
+
 	// Check if KV subject
 	if strings.HasPrefix(subject, "$KV.") {
-		headers := make(map[string]string)
 		c.handleKVOperation(subject, replyTo, headers, payload)
 		return
 	}
@@ -209,6 +298,7 @@ func (c *Client) handlePublish(line string) {
 	msg := &Message{
 		Subject: subject,
 		ReplyTo: replyTo,
+		Headers: headers,
 		Data:    payload,
 		Time:    time.Now(),
 	}
@@ -224,6 +314,78 @@ func (c *Client) handlePublish(line string) {
 	}
 }
 
+// parseHeaders decodes a NATS v2 header block: a status line
+// ("NATS/1.0\r\n", optionally with an inline status like
+// "NATS/1.0 404 No Messages\r\n") followed by zero or more
+// "Key: Value\r\n" lines. An inline status surfaces as the pseudo-headers
+// "Status"/"Description", matching what client libraries expose for it.
+// A key repeated across multiple lines has its values joined with ", ",
+// the same collapsing net/http does for multi-value headers.
+func parseHeaders(block []byte) map[string]string {
+	headers := make(map[string]string)
+
+	text := strings.TrimRight(string(block), "\r\n")
+	if text == "" {
+		return headers
+	}
+	lines := strings.Split(text, "\r\n")
+
+	if fields := strings.SplitN(strings.TrimSpace(lines[0]), " ", 3); len(fields) > 1 {
+		headers["Status"] = fields[1]
+		if len(fields) == 3 {
+			headers["Description"] = fields[2]
+		}
+	}
+
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if existing, ok := headers[key]; ok {
+			headers[key] = existing + ", " + value
+		} else {
+			headers[key] = value
+		}
+	}
+
+	return headers
+}
+
+// encodeHeaders renders headers back into NATS v2 header-block wire
+// format for HMSG: a "NATS/1.0\r\n" status line, one "Key: Value\r\n"
+// line per entry, and the blank line terminating the block.
+func encodeHeaders(headers map[string]string) []byte {
+	var b strings.Builder
+	b.WriteString("NATS/1.0\r\n")
+	for k, v := range headers {
+		b.WriteString(k)
+		b.WriteString(": ")
+		b.WriteString(v)
+		b.WriteString("\r\n")
+	}
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// handleConnect records whether this client's CONNECT payload advertised
+// header support, so deliveries with headers know whether to send HMSG
+// or degrade to plain MSG.
+func (c *Client) handleConnect(payload string) {
+	var connect struct {
+		Headers bool `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(payload), &connect); err != nil {
+		return
+	}
+	c.headersOK.Store(connect.Headers)
+}
+
 func (c *Client) handleJetStreamAPI(subject, replyTo string, headers map[string]string, payload []byte) {
 	// Route JetStream API calls
 	parts := strings.Split(subject, ".")
@@ -235,6 +397,45 @@ func (c *Client) handleJetStreamAPI(subject, replyTo string, headers map[string]
 	apiType := parts[2] // STREAM, CONSUMER, MSG, etc.
 	action := parts[3]  // CREATE, DELETE, INFO, etc.
 
+	// STREAM.OBJ.WATCH.<bucket> streams ObjectInfo updates back to
+	// replyTo for the lifetime of the connection, so it's handled
+	// separately from the generic HandleStreamAPI dispatch below.
+	if apiType == "STREAM" && action == "OBJ" && len(parts) >= 6 && parts[4] == "WATCH" {
+		if c.server.jetstream == nil {
+			c.sendJSError(replyTo, "jetstream not enabled")
+			return
+		}
+		bucket := parts[5]
+		err := c.server.jetstream.HandleObjectWatch(bucket, func(info *ObjectInfo) {
+			if payload, err := json.Marshal(info); err == nil {
+				c.sendMessage(replyTo, payload)
+			}
+		})
+		if err != nil {
+			c.sendJSError(replyTo, err.Error())
+		}
+		return
+	}
+
+	// CONSUMER.MSG.NEXT.<stream>.<consumer> is a pull request: it streams
+	// zero or more delivered messages back to replyTo rather than
+	// returning one JSON response, so it's handled separately from the
+	// generic HandleConsumerAPI dispatch below.
+	if apiType == "CONSUMER" && action == "MSG" && len(parts) >= 7 && parts[4] == "NEXT" {
+		if c.server.jetstream == nil {
+			c.sendJSError(replyTo, "jetstream not enabled")
+			return
+		}
+		streamName, consumerName := parts[5], parts[6]
+		err := c.server.jetstream.HandleConsumerMsgNext(streamName, consumerName, payload, func(msg *Message) {
+			c.sendMsgWithHeaders(replyTo, msg.Headers, msg.Data)
+		})
+		if err != nil {
+			c.sendJSError(replyTo, err.Error())
+		}
+		return
+	}
+
 	var response []byte
 	var err error
 
@@ -286,12 +487,36 @@ func (c *Client) handleKVOperation(subject, replyTo string, headers map[string]s
 		switch op {
 		case "PUT":
 			response, err = c.server.jetstream.KVPut(bucket, key, payload, headers)
+		case "UPDATE":
+			var lastRev uint64
+			lastRev, err = strconv.ParseUint(headers["KV-Expected-Revision"], 10, 64)
+			if err == nil {
+				response, err = c.server.jetstream.KVCompareAndSwap(bucket, key, payload, lastRev)
+			}
 		case "GET":
 			response, err = c.server.jetstream.KVGet(bucket, key)
+		case "REVISION":
+			var rev uint64
+			rev, err = strconv.ParseUint(headers["KV-Revision"], 10, 64)
+			if err == nil {
+				response, err = c.server.jetstream.KVGetRevision(bucket, key, rev)
+			}
+		case "HISTORY":
+			response, err = c.server.jetstream.KVHistory(bucket, key)
+		case "KEYS":
+			response, err = c.server.jetstream.KVKeys(bucket)
+		case "STATUS":
+			response, err = c.server.jetstream.KVStatus(bucket)
 		case "DEL":
 			err = c.server.jetstream.KVDelete(bucket, key)
 		case "PURGE":
 			err = c.server.jetstream.KVPurge(bucket, key)
+		case "WATCH":
+			err = c.server.jetstream.KVWatch(bucket, key, func(entry *KeyValueEntry) {
+				if payload, mErr := json.Marshal(entry); mErr == nil {
+					c.sendMessage(replyTo, payload)
+				}
+			})
 		default:
 			err = fmt.Errorf("unknown kv operation: %s", op)
 		}
@@ -346,6 +571,29 @@ func (c *Client) handleUnsubscribe(args []string) {
 	}
 	sid := args[1]
 
+	v, ok := c.subs.Load(sid)
+	if !ok {
+		c.sendOK()
+		return
+	}
+	sub := v.(*Subscription)
+
+	// With a max-msgs argument, the subscription stays live until it has
+	// received that many messages, then auto-unsubscribes - it may
+	// already have met or passed the limit by the time UNSUB arrives.
+	if len(args) >= 3 {
+		if max, err := strconv.ParseInt(args[2], 10, 64); err == nil && max > 0 {
+			atomic.StoreInt64(&sub.maxMsgs, max)
+			if atomic.LoadInt64(&sub.pending) >= max {
+				if _, ok := c.subs.LoadAndDelete(sid); ok {
+					c.server.removeSubscription(sub)
+				}
+			}
+			c.sendOK()
+			return
+		}
+	}
+
 	if sub, ok := c.subs.LoadAndDelete(sid); ok {
 		c.server.removeSubscription(sub.(*Subscription))
 	}
@@ -389,6 +637,31 @@ func (c *Client) sendMessage(subject string, data []byte) {
 	c.writer.Flush()
 }
 
+// sendMsgWithHeaders delivers data as an HMSG carrying headers if there
+// are any and this client advertised headers:true during CONNECT,
+// falling back to a plain MSG (silently dropping headers) for clients
+// that never opted in.
+func (c *Client) sendMsgWithHeaders(subject string, headers map[string]string, data []byte) {
+	if len(headers) > 0 && c.headersOK.Load() {
+		c.sendHeaderMessage(subject, headers, data)
+		return
+	}
+	c.sendMessage(subject, data)
+}
+
+func (c *Client) sendHeaderMessage(subject string, headers map[string]string, data []byte) {
+	hdr := encodeHeaders(headers)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writer.WriteString(fmt.Sprintf("HMSG %s 0 %d %d\r\n", subject, len(hdr), len(hdr)+len(data)))
+	c.writer.Write(hdr)
+	c.writer.Write(data)
+	c.writer.WriteString("\r\n")
+	c.writer.Flush()
+}
+
 func (c *Client) write(s string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -426,4 +699,11 @@ type Subscription struct {
 	subject string
 	queue   string
 	client  *Client
+
+	// pending counts messages delivered so far; maxMsgs, when non-zero
+	// (set via `UNSUB <sid> [max-msgs]`), is the count at which deliver
+	// auto-unsubscribes. Both are accessed with sync/atomic since
+	// deliver runs concurrently with handleUnsubscribe/close.
+	pending int64
+	maxMsgs int64
 }
@@ -0,0 +1,236 @@
+package nats
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/s2"
+)
+
+// streamBackupManifest is the first entry ("backup.json") of a stream
+// snapshot archive, describing everything needed to recreate the stream
+// and verify the block files that follow it weren't corrupted in
+// transit, matching the manifest+blocks layout nats-server uses for its
+// own stream backups.
+type streamBackupManifest struct {
+	Name   string            `json:"name"`
+	Config StreamConfig      `json:"config"`
+	State  StreamState       `json:"state"`
+	Blocks []backupBlockInfo `json:"blocks"`
+}
+
+// backupBlockInfo records one block file's name (relative to the
+// archive root) and its SHA-256, so HandleStreamRestore can detect a
+// truncated or corrupted transfer before installing it.
+type backupBlockInfo struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+}
+
+// HandleStreamSnapshot writes a .tar.s2 archive of stream's current
+// config, state, and block files to w: a backup.json manifest first,
+// then one tar entry per block file, each copied straight from disk so
+// a large stream's blocks are never held in memory all at once. Callers
+// normally hand w a chunked HTTP response writer (see
+// rest.handleStreamSnapshot) so the transfer can start before the whole
+// archive exists.
+func (js *JetStreamEngine) HandleStreamSnapshot(name string, w io.Writer) (err error) {
+	val, ok := js.streams.Load(name)
+	if !ok {
+		return fmt.Errorf("stream not found: %s", name)
+	}
+	stream := val.(*Stream)
+
+	stream.mu.RLock()
+	config := stream.config
+	state := stream.state
+	blockPaths := stream.messages.BlockFiles()
+	stream.mu.RUnlock()
+
+	manifest := streamBackupManifest{Name: name, Config: config, State: state}
+	for _, path := range blockPaths {
+		sum, err := sha256File(path)
+		if err != nil {
+			return fmt.Errorf("nats: failed to hash block %s: %w", path, err)
+		}
+		manifest.Blocks = append(manifest.Blocks, backupBlockInfo{File: filepath.Base(path), SHA256: sum})
+	}
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	sw := s2.NewWriter(w)
+	tw := tar.NewWriter(sw)
+	defer func() {
+		if cerr := tw.Close(); err == nil {
+			err = cerr
+		}
+		if cerr := sw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if err := writeTarBytes(tw, "backup.json", manifestBytes); err != nil {
+		return err
+	}
+	for _, path := range blockPaths {
+		if err := writeTarFile(tw, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleStreamRestore reads a .tar.s2 archive produced by
+// HandleStreamSnapshot from r and recreates the stream it describes. It
+// rejects the restore outright if a stream with that name already
+// exists, writes block files to a temporary directory under js.dataDir
+// while verifying each one's SHA-256 against the manifest, and only
+// renames the temp directory into the stream's real data directory
+// (an atomic, same-filesystem rename) once every block has checked out.
+func (js *JetStreamEngine) HandleStreamRestore(r io.Reader) error {
+	sr := s2.NewReader(r)
+	tr := tar.NewReader(sr)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("nats: failed to read restore archive: %w", err)
+	}
+	if hdr.Name != "backup.json" {
+		return fmt.Errorf("nats: restore archive must start with backup.json, found %q", hdr.Name)
+	}
+	manifestBytes, err := io.ReadAll(tr)
+	if err != nil {
+		return fmt.Errorf("nats: failed to read backup manifest: %w", err)
+	}
+
+	var manifest streamBackupManifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return fmt.Errorf("nats: invalid backup manifest: %w", err)
+	}
+	if manifest.Name == "" {
+		return fmt.Errorf("nats: backup manifest is missing a stream name")
+	}
+	if _, exists := js.streams.Load(manifest.Name); exists {
+		return fmt.Errorf("stream already exists: %s", manifest.Name)
+	}
+
+	expected := make(map[string]string, len(manifest.Blocks))
+	for _, b := range manifest.Blocks {
+		expected[b.File] = b.SHA256
+	}
+
+	tmpDir, err := os.MkdirTemp(js.dataDir, "restore-*")
+	if err != nil {
+		return fmt.Errorf("nats: failed to create restore staging dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("nats: failed to read restore archive: %w", err)
+		}
+
+		sum, ok := expected[hdr.Name]
+		if !ok {
+			return fmt.Errorf("nats: restore archive contains unexpected file %q", hdr.Name)
+		}
+		if err := restoreBlockFile(tr, filepath.Join(tmpDir, hdr.Name), sum); err != nil {
+			return err
+		}
+	}
+
+	finalDir := filepath.Join(js.dataDir, manifest.Name)
+	if err := os.Rename(tmpDir, finalDir); err != nil {
+		return fmt.Errorf("nats: failed to install restored stream: %w", err)
+	}
+
+	store, err := LoadMessageStore(js.dataDir, manifest.Name, manifest.Config.Storage != MemoryStorage)
+	if err != nil {
+		return fmt.Errorf("nats: failed to reopen restored stream storage: %w", err)
+	}
+
+	stream := &Stream{
+		config:   manifest.Config,
+		messages: store,
+		state:    manifest.State,
+	}
+	js.streams.Store(manifest.Name, stream)
+	for _, subject := range manifest.Config.Subjects {
+		js.subjectIndex.Insert(subject, stream)
+	}
+	return nil
+}
+
+// restoreBlockFile copies one tar entry to path while hashing it,
+// failing if the result doesn't match wantSHA256.
+func restoreBlockFile(r io.Reader, path, wantSHA256 string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("nats: failed to write restored block %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		return fmt.Errorf("nats: failed to write restored block %s: %w", filepath.Base(path), err)
+	}
+
+	if got := hex.EncodeToString(h.Sum(nil)); got != wantSHA256 {
+		return fmt.Errorf("nats: block %q failed integrity check: expected sha256 %s, got %s", filepath.Base(path), wantSHA256, got)
+	}
+	return nil
+}
+
+func writeTarBytes(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(data)), Mode: 0644}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// writeTarFile streams path's contents into tw as one entry without
+// reading the whole file into memory first.
+func writeTarFile(tw *tar.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: filepath.Base(path), Size: info.Size(), Mode: 0644}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,378 @@
+package nats
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBlockBytes caps how large a single block file grows before the
+// store rotates to a new one, matching the block sizing NATS' own file
+// store uses by default.
+const defaultBlockBytes = 16 * 1024 * 1024
+
+// blockEntry is the on-disk record format for one stored message: a
+// 4-byte big-endian length prefix followed by this struct JSON-encoded.
+type blockEntry struct {
+	Seq     uint64            `json:"seq"`
+	Subject string            `json:"subject"`
+	ReplyTo string            `json:"reply_to,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Data    []byte            `json:"data"`
+	Time    time.Time         `json:"time"`
+}
+
+// msgBlock is one append-only block file plus an in-memory index of the
+// byte offset of every sequence it holds, so Get can seek straight to a
+// message instead of scanning the file.
+type msgBlock struct {
+	path     string
+	file     *os.File
+	firstSeq uint64
+	lastSeq  uint64
+	lastTime time.Time
+	bytes    int64
+	offsets  map[uint64]int64
+}
+
+func openBlock(path string, firstSeq uint64) (*msgBlock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("nats: failed to open block file: %w", err)
+	}
+	return &msgBlock{
+		path:     path,
+		file:     f,
+		firstSeq: firstSeq,
+		offsets:  make(map[uint64]int64),
+	}, nil
+}
+
+// loadBlock reopens an existing block file, replaying its entries to
+// rebuild the offset index openBlock only sets up for a brand-new,
+// empty block. Used to reattach a stream's storage to a directory a
+// prior MessageStore already populated (see LoadMessageStore).
+func loadBlock(path string) (*msgBlock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("nats: failed to open block file: %w", err)
+	}
+
+	b := &msgBlock{path: path, file: f, offsets: make(map[uint64]int64)}
+
+	var offset int64
+	for {
+		var lenPrefix [4]byte
+		if _, err := io.ReadFull(f, lenPrefix[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			f.Close()
+			return nil, fmt.Errorf("nats: corrupt block file %s: %w", path, err)
+		}
+
+		size := binary.BigEndian.Uint32(lenPrefix[:])
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(f, payload); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("nats: corrupt block file %s: %w", path, err)
+		}
+
+		var entry blockEntry
+		if err := json.Unmarshal(payload, &entry); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("nats: corrupt block entry in %s: %w", path, err)
+		}
+
+		if b.firstSeq == 0 || entry.Seq < b.firstSeq {
+			b.firstSeq = entry.Seq
+		}
+		b.offsets[entry.Seq] = offset
+		b.lastSeq = entry.Seq
+		b.lastTime = entry.Time
+
+		offset += int64(len(lenPrefix)) + int64(size)
+		b.bytes = offset
+	}
+
+	return b, nil
+}
+
+func (b *msgBlock) append(entry *blockEntry, fsync bool) error {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("nats: failed to encode message: %w", err)
+	}
+
+	offset, err := b.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	var lenPrefix [4]byte
+	binary.BigEndian.PutUint32(lenPrefix[:], uint32(len(payload)))
+	if _, err := b.file.Write(lenPrefix[:]); err != nil {
+		return err
+	}
+	if _, err := b.file.Write(payload); err != nil {
+		return err
+	}
+	if fsync {
+		if err := b.file.Sync(); err != nil {
+			return fmt.Errorf("nats: failed to fsync block: %w", err)
+		}
+	}
+
+	b.offsets[entry.Seq] = offset
+	b.lastSeq = entry.Seq
+	b.lastTime = entry.Time
+	b.bytes += int64(len(lenPrefix) + len(payload))
+	return nil
+}
+
+func (b *msgBlock) get(seq uint64) (*blockEntry, bool, error) {
+	offset, ok := b.offsets[seq]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if _, err := b.file.Seek(offset, io.SeekStart); err != nil {
+		return nil, false, err
+	}
+
+	var lenPrefix [4]byte
+	if _, err := io.ReadFull(b.file, lenPrefix[:]); err != nil {
+		return nil, false, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(lenPrefix[:]))
+	if _, err := io.ReadFull(b.file, payload); err != nil {
+		return nil, false, err
+	}
+
+	var entry blockEntry
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		return nil, false, err
+	}
+	return &entry, true, nil
+}
+
+func (b *msgBlock) close() error {
+	return b.file.Close()
+}
+
+func (b *msgBlock) remove() error {
+	b.close()
+	return os.Remove(b.path)
+}
+
+// MessageStore is a durable, file-backed append-only log for one
+// stream's messages, organized as a sequence of block files under
+// dir/<stream>/. Retention (see Stream.applyLimits) evicts whole blocks,
+// oldest first, rather than rewriting the log message-by-message.
+type MessageStore struct {
+	dir         string
+	mu          sync.Mutex
+	blocks      []*msgBlock
+	blockBytes  int64
+	fsyncAlways bool
+}
+
+// NewMessageStore opens (creating if necessary) the block directory for
+// stream under dir. fsyncAlways trades write latency for durability: set
+// it for FileStorage streams, leave it false for MemoryStorage streams
+// backed by a scratch directory that's discarded on stream deletion.
+func NewMessageStore(dir, stream string, fsyncAlways bool) (*MessageStore, error) {
+	streamDir := filepath.Join(dir, stream)
+	if err := os.MkdirAll(streamDir, 0755); err != nil {
+		return nil, fmt.Errorf("nats: failed to create stream dir: %w", err)
+	}
+	return &MessageStore{
+		dir:         streamDir,
+		blockBytes:  defaultBlockBytes,
+		fsyncAlways: fsyncAlways,
+	}, nil
+}
+
+// LoadMessageStore reopens the block files a prior MessageStore for
+// stream already left on disk under dir (e.g. after a stream snapshot
+// is restored into dir/stream, see HandleStreamRestore), rebuilding each
+// block's offset index instead of starting from an empty log.
+func LoadMessageStore(dir, stream string, fsyncAlways bool) (*MessageStore, error) {
+	streamDir := filepath.Join(dir, stream)
+	entries, err := os.ReadDir(streamDir)
+	if err != nil {
+		return nil, fmt.Errorf("nats: failed to read stream dir: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".blk") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names) // block filenames are zero-padded by first sequence, so lexical order is log order
+
+	ms := &MessageStore{
+		dir:         streamDir,
+		blockBytes:  defaultBlockBytes,
+		fsyncAlways: fsyncAlways,
+	}
+	for _, name := range names {
+		block, err := loadBlock(filepath.Join(streamDir, name))
+		if err != nil {
+			return nil, err
+		}
+		ms.blocks = append(ms.blocks, block)
+	}
+	return ms, nil
+}
+
+// BlockFiles returns the absolute path of every block file currently
+// held, oldest first, for use by stream snapshot/restore (see
+// snapshot.go).
+func (ms *MessageStore) BlockFiles() []string {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	paths := make([]string, len(ms.blocks))
+	for i, b := range ms.blocks {
+		paths[i] = b.path
+	}
+	return paths
+}
+
+// Store appends msg (which must already have Seq assigned) to the
+// current block, rotating to a new block file first if the current one
+// has grown past blockBytes.
+func (ms *MessageStore) Store(msg *Message) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	block, err := ms.currentBlockLocked(msg.Seq)
+	if err != nil {
+		return err
+	}
+
+	entry := &blockEntry{
+		Seq:     msg.Seq,
+		Subject: msg.Subject,
+		ReplyTo: msg.ReplyTo,
+		Headers: msg.Headers,
+		Data:    msg.Data,
+		Time:    msg.Time,
+	}
+	return block.append(entry, ms.fsyncAlways)
+}
+
+func (ms *MessageStore) currentBlockLocked(firstSeq uint64) (*msgBlock, error) {
+	if n := len(ms.blocks); n > 0 && ms.blocks[n-1].bytes < ms.blockBytes {
+		return ms.blocks[n-1], nil
+	}
+
+	path := filepath.Join(ms.dir, fmt.Sprintf("%020d.blk", firstSeq))
+	block, err := openBlock(path, firstSeq)
+	if err != nil {
+		return nil, err
+	}
+	ms.blocks = append(ms.blocks, block)
+	return block, nil
+}
+
+// Get looks up the message stored under seq.
+func (ms *MessageStore) Get(seq uint64) (*Message, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, block := range ms.blocks {
+		if seq < block.firstSeq || (block.lastSeq != 0 && seq > block.lastSeq) {
+			continue
+		}
+		entry, ok, err := block.get(seq)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		return &Message{
+			Subject: entry.Subject,
+			ReplyTo: entry.ReplyTo,
+			Headers: entry.Headers,
+			Data:    entry.Data,
+			Time:    entry.Time,
+			Seq:     entry.Seq,
+		}, nil
+	}
+	return nil, fmt.Errorf("nats: sequence %d not found", seq)
+}
+
+// FirstSeq returns the oldest sequence still held, or 0 if the store is
+// empty.
+func (ms *MessageStore) FirstSeq() uint64 {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if len(ms.blocks) == 0 {
+		return 0
+	}
+	return ms.blocks[0].firstSeq
+}
+
+// OldestBlockInfo reports the message count, byte size, and last message
+// time of the oldest block, so Stream.applyLimits can decide whether it
+// should be evicted as a whole without reading every message inside it.
+func (ms *MessageStore) OldestBlockInfo() (msgCount int, bytes int64, lastTime time.Time, ok bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if len(ms.blocks) == 0 {
+		return 0, 0, time.Time{}, false
+	}
+	b := ms.blocks[0]
+	return len(b.offsets), b.bytes, b.lastTime, true
+}
+
+// DropOldestBlock deletes the oldest block file entirely, returning how
+// many messages and bytes it held.
+func (ms *MessageStore) DropOldestBlock() (msgCount int, bytes int64, err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if len(ms.blocks) == 0 {
+		return 0, 0, nil
+	}
+
+	block := ms.blocks[0]
+	msgCount = len(block.offsets)
+	bytes = block.bytes
+	if err := block.remove(); err != nil {
+		return 0, 0, err
+	}
+	ms.blocks = ms.blocks[1:]
+	return msgCount, bytes, nil
+}
+
+// Close releases every open block file without deleting them.
+func (ms *MessageStore) Close() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for _, b := range ms.blocks {
+		b.close()
+	}
+	return nil
+}
+
+// RemoveAll closes and deletes every block file plus the stream's
+// directory, used when a stream is deleted.
+func (ms *MessageStore) RemoveAll() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for _, b := range ms.blocks {
+		b.remove()
+	}
+	ms.blocks = nil
+	return os.RemoveAll(ms.dir)
+}
@@ -0,0 +1,167 @@
+package nats
+
+import (
+	"strings"
+	"sync"
+)
+
+// SubjectMatches exports subjectMatches for callers outside this package
+// (e.g. the REST bridge filtering delivered messages against a
+// client-supplied pattern) that need it without reimplementing it.
+func SubjectMatches(pattern, subject string) bool {
+	return subjectMatches(pattern, subject)
+}
+
+// subjectMatches reports whether subject matches a NATS subscription
+// pattern, honoring the standard wildcards: `*` matches exactly one
+// token and `>` matches one or more trailing tokens (only legal as the
+// final token of a pattern).
+func subjectMatches(pattern, subject string) bool {
+	if pattern == subject {
+		return true
+	}
+
+	pTokens := strings.Split(pattern, ".")
+	sTokens := strings.Split(subject, ".")
+
+	for i, pt := range pTokens {
+		if pt == ">" {
+			return i < len(sTokens)
+		}
+		if i >= len(sTokens) {
+			return false
+		}
+		if pt != "*" && pt != sTokens[i] {
+			return false
+		}
+	}
+	return len(pTokens) == len(sTokens)
+}
+
+// sublist is a subject-interest trie: entries are indexed by the tokens
+// of the subject pattern they were registered under (literal, `*`, or
+// `>`), so Match against a concrete subject only visits the branches
+// that could possibly apply instead of scanning every registered
+// pattern. This mirrors the sublist structure NATS server itself uses
+// for O(subscribers) fan-out instead of O(total subscriptions).
+type sublist struct {
+	mu   sync.RWMutex
+	root *sublistNode
+}
+
+type sublistNode struct {
+	literal map[string]*sublistNode
+	star    *sublistNode
+	fwc     *sublistNode // full wildcard, '>'
+	entries []interface{}
+}
+
+func newSublistNode() *sublistNode {
+	return &sublistNode{literal: make(map[string]*sublistNode)}
+}
+
+func newSublist() *sublist {
+	return &sublist{root: newSublistNode()}
+}
+
+// Insert registers value under pattern.
+func (s *sublist) Insert(pattern string, value interface{}) {
+	tokens := strings.Split(pattern, ".")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	node := s.root
+	for _, tok := range tokens {
+		switch tok {
+		case "*":
+			if node.star == nil {
+				node.star = newSublistNode()
+			}
+			node = node.star
+		case ">":
+			if node.fwc == nil {
+				node.fwc = newSublistNode()
+			}
+			node = node.fwc
+		default:
+			child, ok := node.literal[tok]
+			if !ok {
+				child = newSublistNode()
+				node.literal[tok] = child
+			}
+			node = child
+		}
+	}
+	node.entries = append(node.entries, value)
+}
+
+// Remove drops every value registered under pattern for which match
+// returns true.
+func (s *sublist) Remove(pattern string, match func(interface{}) bool) {
+	tokens := strings.Split(pattern, ".")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	node := s.root
+	for _, tok := range tokens {
+		switch tok {
+		case "*":
+			node = node.star
+		case ">":
+			node = node.fwc
+		default:
+			node = node.literal[tok]
+		}
+		if node == nil {
+			return
+		}
+	}
+
+	kept := node.entries[:0]
+	for _, v := range node.entries {
+		if !match(v) {
+			kept = append(kept, v)
+		}
+	}
+	node.entries = kept
+}
+
+// Match returns every value registered under a pattern that matches
+// subject.
+func (s *sublist) Match(subject string) []interface{} {
+	tokens := strings.Split(subject, ".")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []interface{}
+	matchNode(s.root, tokens, &out)
+	return out
+}
+
+func matchNode(node *sublistNode, tokens []string, out *[]interface{}) {
+	if node == nil {
+		return
+	}
+
+	if len(tokens) == 0 {
+		*out = append(*out, node.entries...)
+		return
+	}
+
+	// '>' matches one or more trailing tokens, so it's eligible as soon
+	// as at least one token remains.
+	if node.fwc != nil {
+		*out = append(*out, node.fwc.entries...)
+	}
+
+	head, rest := tokens[0], tokens[1:]
+	if child, ok := node.literal[head]; ok {
+		matchNode(child, rest, out)
+	}
+	if node.star != nil {
+		matchNode(node.star, rest, out)
+	}
+}
@@ -0,0 +1,169 @@
+package tdbplusv1
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// QueryClient is the client-side handle for the streamed Query RPC.
+type QueryClient interface {
+	Recv() (*Document, error)
+	grpc.ClientStream
+}
+
+// BatchInsertClient is the client-side handle for the client-streamed
+// BatchInsert RPC.
+type BatchInsertClient interface {
+	Send(*InsertRequest) error
+	CloseAndRecv() (*BatchInsertResponse, error)
+	grpc.ClientStream
+}
+
+type clusterServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewClusterServiceClient wraps cc as a ClusterServiceClient, the
+// hand-written stand-in for what protoc-gen-go-grpc would emit.
+func NewClusterServiceClient(cc grpc.ClientConnInterface) *clusterServiceClient {
+	return &clusterServiceClient{cc: cc}
+}
+
+func (c *clusterServiceClient) Insert(ctx context.Context, req *InsertRequest, opts ...grpc.CallOption) (*InsertResponse, error) {
+	out := new(InsertResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/Insert", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) Get(ctx context.Context, req *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/Get", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) Update(ctx context.Context, req *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
+	out := new(UpdateResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/Update", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) Delete(ctx context.Context, req *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/Delete", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) VectorSearch(ctx context.Context, req *VectorSearchRequest, opts ...grpc.CallOption) (*VectorSearchResponse, error) {
+	out := new(VectorSearchResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/VectorSearch", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) CreateCollection(ctx context.Context, req *CreateCollectionRequest, opts ...grpc.CallOption) (*CreateCollectionResponse, error) {
+	out := new(CreateCollectionResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/CreateCollection", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) DropCollection(ctx context.Context, req *DropCollectionRequest, opts ...grpc.CallOption) (*DropCollectionResponse, error) {
+	out := new(DropCollectionResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/DropCollection", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) ListCollections(ctx context.Context, req *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error) {
+	out := new(ListCollectionsResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/ListCollections", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) Stats(ctx context.Context, req *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/Stats", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) GetTopology(ctx context.Context, req *GetTopologyRequest, opts ...grpc.CallOption) (*GetTopologyResponse, error) {
+	out := new(GetTopologyResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/GetTopology", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) Health(ctx context.Context, req *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	if err := c.cc.Invoke(ctx, "/tdbplus.v1.ClusterService/Health", req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) Query(ctx context.Context, req *QueryRequest, opts ...grpc.CallOption) (QueryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceDesc.Streams[0], "/tdbplus.v1.ClusterService/Query", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &queryClientStream{stream}
+	if err := x.ClientStream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type queryClientStream struct{ grpc.ClientStream }
+
+func (x *queryClientStream) Recv() (*Document, error) {
+	m := new(Document)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *clusterServiceClient) BatchInsert(ctx context.Context, opts ...grpc.CallOption) (BatchInsertClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceDesc.Streams[1], "/tdbplus.v1.ClusterService/BatchInsert", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &batchInsertClientStream{stream}, nil
+}
+
+type batchInsertClientStream struct{ grpc.ClientStream }
+
+func (x *batchInsertClientStream) Send(req *InsertRequest) error {
+	return x.ClientStream.SendMsg(req)
+}
+
+func (x *batchInsertClientStream) CloseAndRecv() (*BatchInsertResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BatchInsertResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
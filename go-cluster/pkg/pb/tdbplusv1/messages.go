@@ -0,0 +1,105 @@
+// Package tdbplusv1 holds the Go types for proto/tdbplus/v1/cluster.proto.
+// This repo has no protoc toolchain wired into its build yet, so these
+// are hand-maintained to match the .proto field-for-field; once
+// protoc-gen-go/protoc-gen-go-grpc are added to the build this package
+// should be regenerated and this file deleted.
+package tdbplusv1
+
+// Document is a JSON document, the wire shape for VectorSearchResponse
+// matches and Query's streamed results.
+type Document struct {
+	Id   string
+	Json []byte
+}
+
+type InsertRequest struct {
+	Collection string
+	Json       []byte
+}
+
+type InsertResponse struct {
+	Id string
+}
+
+type GetRequest struct {
+	Collection  string
+	Id          string
+	Consistency string
+}
+
+type GetResponse struct {
+	Json []byte
+}
+
+type UpdateRequest struct {
+	Collection string
+	Id         string
+	Json       []byte
+}
+
+type UpdateResponse struct{}
+
+type DeleteRequest struct {
+	Collection string
+	Id         string
+}
+
+type DeleteResponse struct{}
+
+type QueryRequest struct {
+	Collection  string
+	FilterJson  []byte
+	Consistency string
+}
+
+type BatchInsertResponse struct {
+	Inserted int64
+}
+
+type VectorSearchRequest struct {
+	Collection string
+	Vector     []float32
+	TopK       int32
+}
+
+type VectorSearchResponse struct {
+	Matches []*Document
+}
+
+type CreateCollectionRequest struct {
+	Name string
+}
+
+type CreateCollectionResponse struct{}
+
+type DropCollectionRequest struct {
+	Name string
+}
+
+type DropCollectionResponse struct{}
+
+type ListCollectionsRequest struct{}
+
+type ListCollectionsResponse struct {
+	Names []string
+}
+
+type StatsRequest struct{}
+
+type StatsResponse struct {
+	Json []byte
+}
+
+type GetTopologyRequest struct{}
+
+type GetTopologyResponse struct {
+	Json []byte
+}
+
+type HealthRequest struct{}
+
+type HealthResponse struct {
+	Ok         bool
+	IsLeader   bool
+	LeaderAddr string
+}
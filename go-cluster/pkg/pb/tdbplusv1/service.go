@@ -0,0 +1,301 @@
+package tdbplusv1
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+)
+
+// NotLeaderError is returned by ClusterServiceServer methods that require
+// the raft leader (writes, and Get/Query at Linearizable consistency)
+// when the local node isn't it. It is the RPC analogue of the HTTP API's
+// StatusTemporaryRedirect + "redirect" field: instead of a redirect, the
+// caller (normally the pooled client in pkg/grpcapi/client) reads
+// LeaderAddr and retries there directly.
+type NotLeaderError struct {
+	LeaderAddr string
+}
+
+func (e *NotLeaderError) Error() string {
+	if e.LeaderAddr == "" {
+		return "not leader: no known leader"
+	}
+	return fmt.Sprintf("not leader: leader is %s", e.LeaderAddr)
+}
+
+// QueryServer is the server-side handle for the streamed Query RPC.
+type QueryServer interface {
+	Send(*Document) error
+	grpc.ServerStream
+}
+
+// BatchInsertServer is the server-side handle for the client-streamed
+// BatchInsert RPC.
+type BatchInsertServer interface {
+	Recv() (*InsertRequest, error)
+	SendAndClose(*BatchInsertResponse) error
+	grpc.ServerStream
+}
+
+// ClusterServiceServer is the interface pkg/grpcapi implements against
+// cluster.Node/router.Router. It mirrors the RPCs declared in
+// proto/tdbplus/v1/cluster.proto.
+type ClusterServiceServer interface {
+	Insert(context.Context, *InsertRequest) (*InsertResponse, error)
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	Query(*QueryRequest, QueryServer) error
+	BatchInsert(BatchInsertServer) error
+	VectorSearch(context.Context, *VectorSearchRequest) (*VectorSearchResponse, error)
+	CreateCollection(context.Context, *CreateCollectionRequest) (*CreateCollectionResponse, error)
+	DropCollection(context.Context, *DropCollectionRequest) (*DropCollectionResponse, error)
+	ListCollections(context.Context, *ListCollectionsRequest) (*ListCollectionsResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	GetTopology(context.Context, *GetTopologyRequest) (*GetTopologyResponse, error)
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+}
+
+// ClusterServiceClient is the client-side counterpart, implemented by
+// the stub NewClusterServiceClient returns (see client.go).
+type ClusterServiceClient interface {
+	Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertResponse, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	VectorSearch(ctx context.Context, in *VectorSearchRequest, opts ...grpc.CallOption) (*VectorSearchResponse, error)
+	CreateCollection(ctx context.Context, in *CreateCollectionRequest, opts ...grpc.CallOption) (*CreateCollectionResponse, error)
+	DropCollection(ctx context.Context, in *DropCollectionRequest, opts ...grpc.CallOption) (*DropCollectionResponse, error)
+	ListCollections(ctx context.Context, in *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	GetTopology(ctx context.Context, in *GetTopologyRequest, opts ...grpc.CallOption) (*GetTopologyResponse, error)
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+// RegisterClusterServiceServer registers srv's handlers on s. It stands
+// in for the protoc-gen-go-grpc-generated registration function; the
+// method table is hand-built from the RPCs in cluster.proto.
+func RegisterClusterServiceServer(s *grpc.Server, srv ClusterServiceServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tdbplus.v1.ClusterService",
+	HandlerType: (*ClusterServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Insert", Handler: insertHandler},
+		{MethodName: "Get", Handler: getHandler},
+		{MethodName: "Update", Handler: updateHandler},
+		{MethodName: "Delete", Handler: deleteHandler},
+		{MethodName: "VectorSearch", Handler: vectorSearchHandler},
+		{MethodName: "CreateCollection", Handler: createCollectionHandler},
+		{MethodName: "DropCollection", Handler: dropCollectionHandler},
+		{MethodName: "ListCollections", Handler: listCollectionsHandler},
+		{MethodName: "Stats", Handler: statsHandler},
+		{MethodName: "GetTopology", Handler: getTopologyHandler},
+		{MethodName: "Health", Handler: healthHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Query", Handler: queryHandler, ServerStreams: true},
+		{StreamName: "BatchInsert", Handler: batchInsertHandler, ClientStreams: true},
+	},
+	Metadata: "tdbplus/v1/cluster.proto",
+}
+
+func insertHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).Insert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/Insert"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).Insert(ctx, req.(*InsertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func getHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func updateHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/Update"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func deleteHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/Delete"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func vectorSearchHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VectorSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).VectorSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/VectorSearch"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).VectorSearch(ctx, req.(*VectorSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func createCollectionHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).CreateCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/CreateCollection"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).CreateCollection(ctx, req.(*CreateCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func dropCollectionHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DropCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).DropCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/DropCollection"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).DropCollection(ctx, req.(*DropCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func listCollectionsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCollectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).ListCollections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/ListCollections"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).ListCollections(ctx, req.(*ListCollectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func statsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/Stats"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func getTopologyHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTopologyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).GetTopology(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/GetTopology"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).GetTopology(ctx, req.(*GetTopologyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func healthHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/tdbplus.v1.ClusterService/Health"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func queryHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(QueryRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(ClusterServiceServer).Query(in, &queryServer{stream})
+}
+
+type queryServer struct{ grpc.ServerStream }
+
+func (s *queryServer) Send(doc *Document) error {
+	return s.ServerStream.SendMsg(doc)
+}
+
+func batchInsertHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ClusterServiceServer).BatchInsert(&batchInsertServer{stream})
+}
+
+type batchInsertServer struct{ grpc.ServerStream }
+
+func (s *batchInsertServer) Recv() (*InsertRequest, error) {
+	m := new(InsertRequest)
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *batchInsertServer) SendAndClose(resp *BatchInsertResponse) error {
+	return s.ServerStream.SendMsg(resp)
+}
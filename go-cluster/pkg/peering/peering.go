@@ -0,0 +1,325 @@
+// Package peering implements cluster-to-cluster peering: one cluster can
+// expose a subset of its shard/service metadata to another without the
+// two joining the same Raft group (no full-mesh membership). A peering
+// is established by exchanging a signed token out of band, after which
+// the importing side pulls shard metadata from the exporting side's
+// mesh gateway on an interval.
+package peering
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Token is a signed bundle a cluster hands to a peer so the peer can
+// locate and authenticate to this cluster's mesh gateway. It deliberately
+// carries no Raft membership information — accepting a token only grants
+// read access to the exporting cluster's shard map, not voting rights.
+type Token struct {
+	ClusterID    string   `json:"cluster_id"`
+	CACert       string   `json:"ca_cert"`
+	GatewayAddrs []string `json:"gateway_addrs"`
+	IssuedAt     int64    `json:"issued_at"`
+	ExpiresAt    int64    `json:"expires_at"`
+}
+
+// Encode serializes and signs the token with secret, producing an
+// opaque string safe to hand to an operator for out-of-band transfer.
+func (t *Token) Encode(secret []byte) (string, error) {
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal token: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	sig := mac.Sum(nil)
+
+	encoded := base64.URLEncoding.EncodeToString(payload) + "." + base64.URLEncoding.EncodeToString(sig)
+	return encoded, nil
+}
+
+// DecodeToken verifies and parses a token produced by Encode.
+func DecodeToken(encoded string, secret []byte) (*Token, error) {
+	parts := splitToken(encoded)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed peering token")
+	}
+
+	payload, err := base64.URLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token payload: %w", err)
+	}
+	sig, err := base64.URLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token signature: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	if !hmac.Equal(mac.Sum(nil), sig) {
+		return nil, fmt.Errorf("peering token signature mismatch")
+	}
+
+	var tok Token
+	if err := json.Unmarshal(payload, &tok); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+	if tok.ExpiresAt > 0 && time.Now().Unix() > tok.ExpiresAt {
+		return nil, fmt.Errorf("peering token expired")
+	}
+	return &tok, nil
+}
+
+func splitToken(s string) []string {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == '.' {
+			return []string{s[:i], s[i+1:]}
+		}
+	}
+	return []string{s}
+}
+
+// RemoteShardInfo is the subset of a peer's shard map we import. It
+// mirrors cluster.ShardInfo's routable fields without creating an import
+// cycle on the cluster package.
+type RemoteShardInfo struct {
+	ID       uint32   `json:"id"`
+	Leader   string   `json:"leader"`
+	Replicas []string `json:"replicas"`
+}
+
+// PeerState is everything a peering has learned about a remote cluster.
+type PeerState struct {
+	Name         string
+	ClusterID    string
+	GatewayAddrs []string
+	Shards       map[uint32]*RemoteShardInfo
+	LastSyncedAt time.Time
+	Status       string // "pending", "established", "degraded"
+}
+
+// ShardFetcher pulls the current shard map from a remote cluster's mesh
+// gateway. Production implementations call the gateway's meilisearch-style
+// API; tests can supply a fake.
+type ShardFetcher interface {
+	FetchShards(gatewayAddr, clusterID string) (map[uint32]*RemoteShardInfo, error)
+}
+
+// Manager tracks outbound tokens this cluster has issued and inbound
+// peerings this cluster has established with others.
+type Manager struct {
+	mu      sync.RWMutex
+	secret  []byte
+	fetcher ShardFetcher
+	peers   map[string]*PeerState
+	stopCh  map[string]chan struct{}
+
+	pollInterval time.Duration
+}
+
+// NewManager creates a peering manager. secret signs/verifies tokens
+// issued by this cluster; fetcher performs the actual remote metadata
+// pulls (nil disables the background sync goroutine, useful in tests).
+func NewManager(secret []byte, fetcher ShardFetcher) *Manager {
+	return &Manager{
+		secret:       secret,
+		fetcher:      fetcher,
+		peers:        make(map[string]*PeerState),
+		stopCh:       make(map[string]chan struct{}),
+		pollInterval: 10 * time.Second,
+	}
+}
+
+// GenerateToken issues a signed token that grants a peer read access to
+// this cluster's shard metadata via gatewayAddrs.
+func (m *Manager) GenerateToken(clusterID string, gatewayAddrs []string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	tok := &Token{
+		ClusterID:    clusterID,
+		GatewayAddrs: gatewayAddrs,
+		IssuedAt:     now.Unix(),
+	}
+	if ttl > 0 {
+		tok.ExpiresAt = now.Add(ttl).Unix()
+	}
+	return tok.Encode(m.secret)
+}
+
+// Establish accepts an inbound token for a named peer and starts a
+// background goroutine that pulls the peer's shard map on pollInterval
+// until the peering is removed.
+func (m *Manager) Establish(name, encodedToken string) error {
+	tok, err := DecodeToken(encodedToken, m.secret)
+	if err != nil {
+		return fmt.Errorf("failed to establish peering %q: %w", name, err)
+	}
+
+	state := &PeerState{
+		Name:         name,
+		ClusterID:    tok.ClusterID,
+		GatewayAddrs: tok.GatewayAddrs,
+		Shards:       make(map[uint32]*RemoteShardInfo),
+		Status:       "pending",
+	}
+
+	m.mu.Lock()
+	if old, ok := m.stopCh[name]; ok {
+		close(old)
+	}
+	stop := make(chan struct{})
+	m.peers[name] = state
+	m.stopCh[name] = stop
+	m.mu.Unlock()
+
+	if m.fetcher != nil {
+		go m.syncLoop(name, stop)
+	}
+	return nil
+}
+
+func (m *Manager) syncLoop(name string, stop chan struct{}) {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	m.syncOnce(name)
+	for {
+		select {
+		case <-ticker.C:
+			m.syncOnce(name)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (m *Manager) syncOnce(name string) {
+	m.mu.RLock()
+	state, ok := m.peers[name]
+	m.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	for _, addr := range state.GatewayAddrs {
+		shards, err := m.fetcher.FetchShards(addr, state.ClusterID)
+		if err != nil {
+			continue
+		}
+
+		m.mu.Lock()
+		state.Shards = shards
+		state.LastSyncedAt = time.Now()
+		state.Status = "established"
+		m.mu.Unlock()
+		return
+	}
+
+	m.mu.Lock()
+	state.Status = "degraded"
+	m.mu.Unlock()
+}
+
+// Get returns the current state of a named peering.
+func (m *Manager) Get(name string) (*PeerState, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	state, ok := m.peers[name]
+	return state, ok
+}
+
+// List returns all known peerings.
+func (m *Manager) List() []*PeerState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	states := make([]*PeerState, 0, len(m.peers))
+	for _, s := range m.peers {
+		states = append(states, s)
+	}
+	return states
+}
+
+// Remove tears down a peering and stops its background sync.
+func (m *Manager) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stop, ok := m.stopCh[name]; ok {
+		close(stop)
+		delete(m.stopCh, name)
+	}
+	delete(m.peers, name)
+}
+
+// ShardOwner resolves the leader for key against a peer's imported shard
+// map, using the same FNV-1a hash cluster.Node uses locally so routing
+// agrees with the remote cluster's own shard assignment.
+func (m *Manager) ShardOwner(peerName string, key []byte, numShards uint32) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state, ok := m.peers[peerName]
+	if !ok || numShards == 0 {
+		return "", false
+	}
+
+	shardID := uint32(hashKey(key) % uint64(numShards))
+	shard, ok := state.Shards[shardID]
+	if !ok || shard.Leader == "" {
+		return "", false
+	}
+	return shard.Leader, true
+}
+
+// HTTPShardFetcher fetches a remote cluster's shard map from its
+// meilisearch-style API at GET {gatewayAddr}/peerings/shards, the
+// endpoint the exporting side registers alongside /peerings.
+type HTTPShardFetcher struct {
+	Client *http.Client
+}
+
+// NewHTTPShardFetcher builds a fetcher with a sane default timeout.
+func NewHTTPShardFetcher() *HTTPShardFetcher {
+	return &HTTPShardFetcher{Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (f *HTTPShardFetcher) FetchShards(gatewayAddr, clusterID string) (map[uint32]*RemoteShardInfo, error) {
+	url := fmt.Sprintf("http://%s/peerings/shards", gatewayAddr)
+	resp, err := f.Client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shards from %s: %w", gatewayAddr, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gateway %s returned status %d", gatewayAddr, resp.StatusCode)
+	}
+
+	var body struct {
+		Shards map[uint32]*RemoteShardInfo `json:"shards"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode shard map from %s: %w", gatewayAddr, err)
+	}
+	return body.Shards, nil
+}
+
+// hashKey is FNV-1a, kept local to avoid importing the cluster package
+// (which would create peering -> cluster -> peering via router).
+func hashKey(data []byte) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	hash := uint64(offset64)
+	for _, b := range data {
+		hash ^= uint64(b)
+		hash *= prime64
+	}
+	return hash
+}
@@ -0,0 +1,108 @@
+package peering
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenEncodeDecodeRoundTrip(t *testing.T) {
+	secret := []byte("test-secret")
+	tok := &Token{
+		ClusterID:    "cluster-b",
+		GatewayAddrs: []string{"10.0.0.1:9090"},
+	}
+
+	encoded, err := tok.Encode(secret)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	decoded, err := DecodeToken(encoded, secret)
+	if err != nil {
+		t.Fatalf("DecodeToken failed: %v", err)
+	}
+	if decoded.ClusterID != tok.ClusterID {
+		t.Errorf("ClusterID mismatch: got %s want %s", decoded.ClusterID, tok.ClusterID)
+	}
+}
+
+func TestDecodeToken_WrongSecretFails(t *testing.T) {
+	tok := &Token{ClusterID: "cluster-b"}
+	encoded, err := tok.Encode([]byte("secret-a"))
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	if _, err := DecodeToken(encoded, []byte("secret-b")); err == nil {
+		t.Error("expected signature verification to fail with wrong secret")
+	}
+}
+
+func TestDecodeToken_Expired(t *testing.T) {
+	secret := []byte("s")
+	tok := &Token{
+		ClusterID: "cluster-b",
+		IssuedAt:  time.Now().Add(-2 * time.Hour).Unix(),
+		ExpiresAt: time.Now().Add(-1 * time.Hour).Unix(),
+	}
+	encoded, err := tok.Encode(secret)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	if _, err := DecodeToken(encoded, secret); err == nil {
+		t.Error("expected expired token to fail decoding")
+	}
+}
+
+type fakeFetcher struct {
+	shards map[uint32]*RemoteShardInfo
+}
+
+func (f *fakeFetcher) FetchShards(gatewayAddr, clusterID string) (map[uint32]*RemoteShardInfo, error) {
+	return f.shards, nil
+}
+
+func TestManager_EstablishAndShardOwner(t *testing.T) {
+	secret := []byte("s")
+	fetcher := &fakeFetcher{
+		shards: map[uint32]*RemoteShardInfo{
+			0: {ID: 0, Leader: "remote-node-1"},
+		},
+	}
+	m := NewManager(secret, fetcher)
+	m.pollInterval = 10 * time.Millisecond
+
+	token, err := m.GenerateToken("cluster-b", []string{"10.0.0.1:9090"}, 0)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	if err := m.Establish("remote", token); err != nil {
+		t.Fatalf("Establish failed: %v", err)
+	}
+	defer m.Remove("remote")
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := m.ShardOwner("remote", []byte("key"), 1); ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	owner, ok := m.ShardOwner("remote", []byte("key"), 1)
+	if !ok {
+		t.Fatal("expected shard owner to resolve after sync")
+	}
+	if owner != "remote-node-1" {
+		t.Errorf("unexpected owner: %s", owner)
+	}
+}
+
+func TestManager_Get_UnknownPeer(t *testing.T) {
+	m := NewManager([]byte("s"), nil)
+	if _, ok := m.Get("nope"); ok {
+		t.Error("expected unknown peer to not be found")
+	}
+}
@@ -0,0 +1,128 @@
+package platform
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tdb-plus/cluster/pkg/platform/auth"
+	"go.uber.org/zap"
+)
+
+// newTestServer returns a Server with just enough wired up to exercise
+// AdminGuard: an AuthEngine (node-less, since IsAuthorized never touches
+// it) and a nop logger.
+func newTestServer() *Server {
+	return &Server{
+		authEngine: auth.NewAuthEngine(nil, zap.NewNop()),
+		logger:     zap.NewNop(),
+	}
+}
+
+// withClaims returns middleware that stands in for authMiddleware,
+// setting "claims" to a *auth.Claims with the given role.
+func withClaims(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("claims", &auth.Claims{Role: role})
+		c.Next()
+	}
+}
+
+func TestAdminGuard_GuardRole_EditorDenied(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := newTestServer()
+
+	router := gin.New()
+	router.PATCH("/api/v1/config", withClaims("editor"), s.AdminGuard(GuardRole), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/config", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected editor role to be denied an admin route, got status %d", rec.Code)
+	}
+}
+
+func TestAdminGuard_GuardRole_AdminAllowed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := newTestServer()
+
+	router := gin.New()
+	router.PATCH("/api/v1/config", withClaims("admin"), s.AdminGuard(GuardRole), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/config", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected admin role to pass GuardRole, got status %d", rec.Code)
+	}
+}
+
+func TestAdminGuard_GuardRole_NoClaimsDenied(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := newTestServer()
+
+	router := gin.New()
+	router.PATCH("/api/v1/config", s.AdminGuard(GuardRole), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/config", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected a request with no claims to be denied, got status %d", rec.Code)
+	}
+}
+
+func TestAdminGuard_GuardToken_UnconfiguredSkipped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := newTestServer()
+
+	router := gin.New()
+	router.POST("/api/v1/regions/:id/split", s.AdminGuard(GuardToken), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/regions/1/split", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected GuardToken to be skipped when no admin token is configured, got status %d", rec.Code)
+	}
+}
+
+func TestAdminGuard_GuardToken_Enforced(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := newTestServer()
+	s.adminToken = "secret"
+
+	router := gin.New()
+	router.POST("/api/v1/regions/:id/split", s.AdminGuard(GuardToken), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/regions/1/split", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected a missing X-Admin-Token to be denied, got status %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/regions/1/split", nil)
+	req.Header.Set("X-Admin-Token", "secret")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the correct X-Admin-Token to be allowed, got status %d", rec.Code)
+	}
+}
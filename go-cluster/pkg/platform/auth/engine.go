@@ -1,29 +1,69 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/lumadb/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/platform/auth/store"
 	"go.uber.org/zap"
 )
 
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrExpiredToken = errors.New("expired token")
+	// ErrTokenRevoked is returned by ValidateToken for a token whose jti
+	// was revoked via Revoke (e.g. POST /api/auth/logout).
+	ErrTokenRevoked = errors.New("token revoked")
 )
 
+// validSigningMethods is every algorithm verifyKey can resolve a key
+// for, passed to jwt.WithValidMethods as a coarse allowlist alongside
+// verifyKey's own per-key algorithm check - together they close the
+// "alg confusion" attack where a token claims a different algorithm
+// than the one its kid (or the shared secret, with no kid) was issued
+// under.
+var validSigningMethods = []string{
+	jwt.SigningMethodHS256.Alg(),
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
 type Claims struct {
 	UserID string `json:"user_id"`
 	Role   string `json:"role"`
 	jwt.RegisteredClaims
 }
 
+// RefreshClaims is the payload of a refresh token issued by
+// GenerateTokenPair. It embeds Role (rather than requiring Refresh to
+// look the user back up via Store) so refresh works the same whether or
+// not a Store is configured.
+type RefreshClaims struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
 type AuthEngine struct {
-	node      *cluster.Node
-	logger    *zap.Logger
-	secretKey []byte
+	node   *cluster.Node
+	logger *zap.Logger
+
+	mu          sync.RWMutex
+	secretKey   []byte
+	keyring     *Keyring
+	store       *store.Store
+	permissions map[string][]PermissionRule
 }
 
 func NewAuthEngine(node *cluster.Node, logger *zap.Logger) *AuthEngine {
@@ -40,30 +80,173 @@ func (e *AuthEngine) Start() error {
 	return nil
 }
 
-// GenerateToken creates a new JWT for a user
+// SetSecretKey replaces the JWT signing/verification key, e.g. when an
+// operator rotates it via platform/config's hot-reload path. Tokens
+// signed under the old key stop validating immediately.
+func (e *AuthEngine) SetSecretKey(key []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.secretKey = key
+}
+
+// SetStore wires s as AuthEngine's user/API-key/revocation persistence
+// (see pkg/platform/auth/store). Without a store, issued tokens are not
+// recorded, and ValidateToken checks revocation only against the
+// cluster.Node-backed list (see revocation.go), which is skipped when the
+// AuthEngine has no node.
+func (e *AuthEngine) SetStore(s *store.Store) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.store = s
+}
+
+// Store returns the AuthEngine's persistence layer, or nil if SetStore
+// was never called.
+func (e *AuthEngine) Store() *store.Store {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.store
+}
+
+// Secret returns the current JWT signing/verification key, e.g. for
+// another subsystem (see pkg/platform/cron's webhook handler) that wants
+// to HMAC-sign its own requests with the same secret rather than
+// managing one of its own.
+func (e *AuthEngine) Secret() []byte {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.secretKey
+}
+
+// SetKeyring wires k in as AuthEngine's signing/verification keys.
+// GenerateToken and GenerateTokenPair sign with k.Active() and stamp its
+// kid into the token header; ValidateToken resolves the verification key
+// by the token's own kid header via k.Lookup, rejecting tokens signed
+// under a key k has retired past its grace window. Without a keyring,
+// AuthEngine keeps signing/verifying HS256 with SetSecretKey's secret, as
+// before.
+func (e *AuthEngine) SetKeyring(k *Keyring) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.keyring = k
+}
+
+// GenerateToken creates a new JWT for a user, with a random jti recorded
+// via Store (if one is set) so a later Revoke call for it has a row to
+// mark revoked.
 func (e *AuthEngine) GenerateToken(userID, role string) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
+	jti := newJTI()
 	claims := &Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			Issuer:    "luma-platform",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(e.secretKey)
+	signed, err := e.sign(claims)
+	if err != nil {
+		return "", err
+	}
+
+	e.mu.RLock()
+	st := e.store
+	e.mu.RUnlock()
+	if st != nil {
+		if err := st.RecordIssuedToken(jti, expirationTime); err != nil {
+			e.logger.Warn("failed to record issued token", zap.String("jti", jti), zap.Error(err))
+		}
+	}
+	return signed, nil
 }
 
-// ValidateToken parses and validates a JWT
-func (e *AuthEngine) ValidateToken(tokenString string) (*Claims, error) {
-	claims := &Claims{}
+// GenerateTokenPair creates a short-lived access token (see accessTokenTTL)
+// alongside a long-lived refresh token (refreshTokenTTL) a client holds
+// onto and later exchanges via Refresh instead of forcing the user to
+// log in again every accessTokenTTL.
+func (e *AuthEngine) GenerateTokenPair(userID, role string) (access, refresh string, err error) {
+	accessExp := time.Now().Add(accessTokenTTL)
+	accessJTI := newJTI()
+	access, err = e.sign(&Claims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        accessJTI,
+			ExpiresAt: jwt.NewNumericDate(accessExp),
+			Issuer:    "luma-platform",
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return e.secretKey, nil
+	refreshExp := time.Now().Add(refreshTokenTTL)
+	refresh, err = e.sign(&RefreshClaims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newJTI(),
+			ExpiresAt: jwt.NewNumericDate(refreshExp),
+			Issuer:    "luma-platform",
+		},
 	})
+	if err != nil {
+		return "", "", err
+	}
+
+	e.mu.RLock()
+	st := e.store
+	e.mu.RUnlock()
+	if st != nil {
+		if err := st.RecordIssuedToken(accessJTI, accessExp); err != nil {
+			e.logger.Warn("failed to record issued token", zap.String("jti", accessJTI), zap.Error(err))
+		}
+	}
+	return access, refresh, nil
+}
 
+// Refresh validates refreshToken and, if it's still good, rotates it:
+// the presented refresh token's jti is revoked (so it can't be replayed)
+// and a fresh access/refresh pair is issued in its place.
+func (e *AuthEngine) Refresh(refreshToken string) (access, newRefresh string, err error) {
+	claims := &RefreshClaims{}
+	token, err := jwt.ParseWithClaims(refreshToken, claims, e.verifyKey, jwt.WithValidMethods(validSigningMethods))
+	if err != nil {
+		if err == jwt.ErrTokenExpired {
+			return "", "", ErrExpiredToken
+		}
+		return "", "", err
+	}
+	if !token.Valid {
+		return "", "", ErrInvalidToken
+	}
+
+	if revoked, err := e.isJTIRevoked(claims.ID); err != nil {
+		return "", "", fmt.Errorf("failed to check refresh token revocation: %w", err)
+	} else if revoked {
+		return "", "", ErrTokenRevoked
+	}
+
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := e.revokeJTI(claims.ID, expiresAt); err != nil {
+		e.logger.Warn("failed to revoke rotated refresh token", zap.String("jti", claims.ID), zap.Error(err))
+	}
+
+	return e.GenerateTokenPair(claims.UserID, claims.Role)
+}
+
+// ValidateToken parses and validates a JWT, rejecting it if its jti was
+// revoked via Revoke.
+func (e *AuthEngine) ValidateToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, e.verifyKey, jwt.WithValidMethods(validSigningMethods))
 	if err != nil {
 		if err == jwt.ErrTokenExpired {
 			return nil, ErrExpiredToken
@@ -75,5 +258,106 @@ func (e *AuthEngine) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
+	e.mu.RLock()
+	st := e.store
+	e.mu.RUnlock()
+
+	if st != nil && claims.ID != "" {
+		revoked, err := st.IsTokenRevoked(claims.ID)
+		if err != nil {
+			e.logger.Warn("failed to check token revocation", zap.String("jti", claims.ID), zap.Error(err))
+		} else if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+	if claims.ID != "" {
+		if revoked, err := e.isJTIRevoked(claims.ID); err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		} else if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
 	return claims, nil
 }
+
+// Revoke marks claims' jti as revoked, so ValidateToken rejects it on any
+// later request even though it hasn't expired yet. Writes to both the
+// cluster.Node-backed revocation list and Store (if one is set), so a
+// revoked token stays rejected regardless of which AuthEngine instance
+// next validates it.
+func (e *AuthEngine) Revoke(claims *Claims) error {
+	if claims.ID == "" {
+		return nil
+	}
+
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := e.revokeJTI(claims.ID, expiresAt); err != nil {
+		e.logger.Warn("failed to revoke token", zap.String("jti", claims.ID), zap.Error(err))
+	}
+
+	e.mu.RLock()
+	st := e.store
+	e.mu.RUnlock()
+	if st == nil {
+		return nil
+	}
+	return st.RevokeToken(claims.ID)
+}
+
+// sign signs claims with the active keyring key (stamping its kid into
+// the token header) if a keyring is configured, else falls back to plain
+// HS256 with secretKey - the behavior before SetKeyring existed.
+func (e *AuthEngine) sign(claims jwt.Claims) (string, error) {
+	e.mu.RLock()
+	keyring, secretKey := e.keyring, e.secretKey
+	e.mu.RUnlock()
+
+	if keyring != nil {
+		key := keyring.Active()
+		if key == nil {
+			return "", errors.New("auth: keyring has no active signing key")
+		}
+		token := jwt.NewWithClaims(key.signingMethod(), claims)
+		token.Header["kid"] = key.KID
+		return token.SignedString(key.SignKey)
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secretKey)
+}
+
+// verifyKey is the jwt.Keyfunc ValidateToken and Refresh parse with: it
+// resolves the verification key by the token's kid header via the
+// keyring when one is configured, falling back to secretKey otherwise.
+func (e *AuthEngine) verifyKey(token *jwt.Token) (interface{}, error) {
+	e.mu.RLock()
+	keyring, secretKey := e.keyring, e.secretKey
+	e.mu.RUnlock()
+
+	if keyring != nil {
+		if kid, _ := token.Header["kid"].(string); kid != "" {
+			key, err := keyring.Lookup(kid)
+			if err != nil {
+				return nil, err
+			}
+			if token.Method.Alg() != key.signingMethod().Alg() {
+				return nil, fmt.Errorf("auth: key %q is %s, token claims %s", kid, key.signingMethod().Alg(), token.Method.Alg())
+			}
+			return key.VerifyKey, nil
+		}
+	}
+	if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+		return nil, fmt.Errorf("auth: unexpected signing method %s", token.Method.Alg())
+	}
+	return secretKey, nil
+}
+
+// newJTI returns a random 128-bit token ID, hex-encoded.
+func newJTI() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+func newTestEngine() *AuthEngine {
+	e := NewAuthEngine(nil, zap.NewNop())
+	e.SetSecretKey([]byte("test-secret"))
+	return e
+}
+
+func TestGenerateTokenPair_RoundTrip(t *testing.T) {
+	e := newTestEngine()
+
+	access, _, err := e.GenerateTokenPair("user-1", "editor")
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+
+	claims, err := e.ValidateToken(access)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.UserID != "user-1" || claims.Role != "editor" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerifyKey_RejectsAlgMismatch(t *testing.T) {
+	e := newTestEngine()
+
+	// A token whose header claims RS256 but is actually signed (and
+	// here, just constructed) as if it were HS256 must be rejected by
+	// verifyKey's per-key algorithm check, even though no keyring is
+	// configured - this is the alg-confusion surface verifyKey now
+	// closes for the shared-secret path.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{})
+	token.Header["alg"] = "RS256"
+	fakeToken := &jwt.Token{Header: token.Header, Method: jwt.SigningMethodRS256}
+
+	if _, err := e.verifyKey(fakeToken); err == nil {
+		t.Fatal("expected verifyKey to reject a token whose method doesn't match the configured key's algorithm")
+	}
+}
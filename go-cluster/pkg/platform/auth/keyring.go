@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyAlg is one of the JWT signing algorithms a Keyring entry can use.
+type KeyAlg string
+
+const (
+	AlgHS256 KeyAlg = "HS256"
+	AlgRS256 KeyAlg = "RS256"
+	AlgEdDSA KeyAlg = "EdDSA"
+)
+
+// SigningKey is one entry in a Keyring: a KID-addressable key plus the
+// algorithm it signs/verifies with. SignKey and VerifyKey are the same
+// value for HS256 (a shared secret); for RS256/EdDSA, SignKey is the
+// private key and VerifyKey the corresponding public key, so a verifier
+// that only has the public half (e.g. a sidecar that checks tokens but
+// never issues them) can still be handed a Keyring.
+type SigningKey struct {
+	KID       string
+	Alg       KeyAlg
+	SignKey   interface{}
+	VerifyKey interface{}
+	// RetiredAt, once set, is when this key stopped being used to sign
+	// new tokens. Lookup still accepts it for verification until
+	// Keyring's gracePeriod has elapsed since, so tokens already
+	// outstanding under it don't all fail at once on rotation.
+	RetiredAt time.Time
+}
+
+func (k *SigningKey) retired() bool { return !k.RetiredAt.IsZero() }
+
+// signingMethod returns the jwt-go SigningMethod for k.Alg.
+func (k *SigningKey) signingMethod() jwt.SigningMethod {
+	switch k.Alg {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// Keyring is a KID-indexed set of SigningKeys, letting AuthEngine rotate
+// its JWT signing key without invalidating every token issued under the
+// previous one: ValidateToken verifies against whichever key the
+// token's own "kid" header names, and Lookup keeps honoring a retired
+// key until gracePeriod after its RetiredAt.
+type Keyring struct {
+	mu          sync.RWMutex
+	keys        map[string]*SigningKey
+	activeKID   string
+	gracePeriod time.Duration
+}
+
+// NewKeyring creates an empty Keyring. gracePeriod is how long a retired
+// key (see RetireKey) keeps verifying tokens signed under it.
+func NewKeyring(gracePeriod time.Duration) *Keyring {
+	return &Keyring{keys: make(map[string]*SigningKey), gracePeriod: gracePeriod}
+}
+
+// AddKey adds key to the ring, making it the active (signing) key if
+// makeActive is true.
+func (r *Keyring) AddKey(key *SigningKey, makeActive bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[key.KID] = key
+	if makeActive {
+		r.activeKID = key.KID
+	}
+}
+
+// RetireKey marks kid as retired as of now: Active() stops returning it,
+// but Lookup still honors it for gracePeriod.
+func (r *Keyring) RetireKey(kid string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if k, ok := r.keys[kid]; ok {
+		k.RetiredAt = time.Now()
+	}
+	if r.activeKID == kid {
+		r.activeKID = ""
+	}
+}
+
+// Active returns the key new tokens should be signed with, or nil if
+// none is set (e.g. an empty Keyring, or the active key was just
+// retired without a replacement).
+func (r *Keyring) Active() *SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.keys[r.activeKID]
+}
+
+// Lookup returns the key for kid, rejecting it once it's been retired
+// for longer than gracePeriod.
+func (r *Keyring) Lookup(kid string) (*SigningKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("auth: no signing key for kid %q", kid)
+	}
+	if key.retired() && time.Since(key.RetiredAt) > r.gracePeriod {
+		return nil, fmt.Errorf("auth: key %q was retired more than %s ago", kid, r.gracePeriod)
+	}
+	return key, nil
+}
+
+// keyFile is the on-disk/env-var JSON shape LoadKeyringFromFile and
+// LoadKeyringFromEnv parse into SigningKeys.
+type keyFile struct {
+	KID        string `json:"kid"`
+	Alg        string `json:"alg"`                   // "HS256", "RS256", or "EdDSA"
+	Secret     string `json:"secret,omitempty"`       // base64, HS256 only
+	PrivateKey string `json:"private_key,omitempty"`  // PEM, RS256/EdDSA
+	PublicKey  string `json:"public_key,omitempty"`   // PEM, verify-only entries (no PrivateKey)
+	Active     bool   `json:"active"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+}
+
+// LoadKeyringFromFile reads a JSON array of keyFile entries from path -
+// an operator-managed file an init container or secrets manager writes,
+// so rotating the signing key is "add an entry, flip active, redeploy"
+// rather than a code change.
+func LoadKeyringFromFile(path string, gracePeriod time.Duration) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: read keyring file: %w", err)
+	}
+	return loadKeyring(data, gracePeriod)
+}
+
+// LoadKeyringFromEnv reads the same JSON shape as LoadKeyringFromFile
+// from the envVar environment variable, for deployments that inject
+// secrets as env vars rather than files.
+func LoadKeyringFromEnv(envVar string, gracePeriod time.Duration) (*Keyring, error) {
+	data := os.Getenv(envVar)
+	if data == "" {
+		return nil, fmt.Errorf("auth: environment variable %q is not set", envVar)
+	}
+	return loadKeyring([]byte(data), gracePeriod)
+}
+
+func loadKeyring(data []byte, gracePeriod time.Duration) (*Keyring, error) {
+	var files []keyFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("auth: parse keyring: %w", err)
+	}
+
+	ring := NewKeyring(gracePeriod)
+	for _, kf := range files {
+		key, err := keyFromFile(kf)
+		if err != nil {
+			return nil, fmt.Errorf("auth: key %q: %w", kf.KID, err)
+		}
+		if kf.RetiredAt != nil {
+			key.RetiredAt = *kf.RetiredAt
+		}
+		ring.AddKey(key, kf.Active && key.RetiredAt.IsZero())
+	}
+	return ring, nil
+}
+
+func keyFromFile(kf keyFile) (*SigningKey, error) {
+	alg := KeyAlg(kf.Alg)
+	key := &SigningKey{KID: kf.KID, Alg: alg}
+
+	switch alg {
+	case AlgHS256:
+		secret, err := base64.StdEncoding.DecodeString(kf.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("decode secret: %w", err)
+		}
+		key.SignKey, key.VerifyKey = secret, secret
+
+	case AlgRS256:
+		if kf.PrivateKey != "" {
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(kf.PrivateKey))
+			if err != nil {
+				return nil, fmt.Errorf("parse RSA private key: %w", err)
+			}
+			key.SignKey, key.VerifyKey = priv, &priv.PublicKey
+		} else {
+			pub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(kf.PublicKey))
+			if err != nil {
+				return nil, fmt.Errorf("parse RSA public key: %w", err)
+			}
+			key.VerifyKey = pub
+		}
+
+	case AlgEdDSA:
+		if kf.PrivateKey != "" {
+			priv, err := jwt.ParseEdPrivateKeyFromPEM([]byte(kf.PrivateKey))
+			if err != nil {
+				return nil, fmt.Errorf("parse Ed25519 private key: %w", err)
+			}
+			key.SignKey = priv
+			if signer, ok := priv.(crypto.Signer); ok {
+				key.VerifyKey = signer.Public()
+			}
+		} else {
+			pub, err := jwt.ParseEdPublicKeyFromPEM([]byte(kf.PublicKey))
+			if err != nil {
+				return nil, fmt.Errorf("parse Ed25519 public key: %w", err)
+			}
+			key.VerifyKey = pub
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported alg %q", kf.Alg)
+	}
+
+	return key, nil
+}
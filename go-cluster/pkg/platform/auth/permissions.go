@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"go.uber.org/zap"
+)
+
+// permissionsCollection is the system collection PermissionRules are
+// persisted to, the same convention platform/cron uses for its
+// underscore-prefixed system collections.
+const permissionsCollection = "_permissions"
+
+// Action is the coarse read/write split the historical, route-level
+// IsAuthorized check uses. Op (below) is the finer-grained,
+// per-collection equivalent Authorize uses.
+type Action string
+
+const (
+	ActionRead  Action = "read"
+	ActionWrite Action = "write"
+	// ActionAdmin gates cluster-management routes (see platform.AdminGuard's
+	// GuardRole mode) - deliberately separate from ActionWrite so a role
+	// like "editor" that can write ordinary collections doesn't also pass
+	// as a cluster administrator. Only "admin" carries it, via
+	// IsAuthorized's admin-always-true shortcut; defaultRoleActions grants
+	// it to no other role.
+	ActionAdmin Action = "admin"
+)
+
+// Op is one of the four operations a PermissionRule can grant against a
+// collection.
+type Op string
+
+const (
+	OpSelect Op = "select"
+	OpInsert Op = "insert"
+	OpUpdate Op = "update"
+	OpDelete Op = "delete"
+)
+
+// defaultRoleActions is the built-in role table IsAuthorized falls back
+// to for a role with no matching PermissionRule - "admin" can do
+// anything, "editor" can read and write, anything else (including the
+// zero value) can only read. Roles are operator-defined strings (see
+// Claims.Role), so this intentionally stays a small, conservative
+// default rather than trying to enumerate every role a deployment might
+// invent.
+var defaultRoleActions = map[string]map[Action]bool{
+	"admin":  {ActionRead: true, ActionWrite: true},
+	"editor": {ActionRead: true, ActionWrite: true},
+	"viewer": {ActionRead: true},
+}
+
+// IsAuthorized reports whether role may perform action, per
+// defaultRoleActions. It does not consult per-collection PermissionRules
+// - use Authorize for that.
+func (e *AuthEngine) IsAuthorized(role string, action Action) bool {
+	if role == "admin" {
+		return true
+	}
+	return defaultRoleActions[role][action]
+}
+
+// PermissionRule grants role the listed Ops against Collection. Filter,
+// if set, is a row-level filter template (the same shape RunQuery's
+// filter argument takes) merged into every select a holder of role runs
+// against Collection, and checked against doc for insert/update/delete -
+// e.g. {"tenant_id": "acme"} restricts role to acme's rows without a
+// bespoke per-tenant collection.
+type PermissionRule struct {
+	Role       string                 `json:"role"`
+	Collection string                 `json:"collection"`
+	Ops        []Op                   `json:"ops"`
+	Filter     map[string]interface{} `json:"filter,omitempty"`
+}
+
+func (r PermissionRule) id() string { return r.Role + ":" + r.Collection }
+
+func (r PermissionRule) allows(op Op) bool {
+	for _, o := range r.Ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadPermissions replaces AuthEngine's in-memory PermissionRule cache
+// with the contents of permissionsCollection. Call it once at startup
+// (mirroring cron.Scheduler.Start's loadPersistedJobs) and again after
+// any out-of-band write to the collection; SetPermission already keeps
+// the cache current for writes made through it.
+func (e *AuthEngine) LoadPermissions() error {
+	if e.node == nil {
+		return nil
+	}
+	docs, err := e.node.RunQuery(permissionsCollection, map[string]interface{}{"limit": 10000}, cluster.Stale)
+	if err != nil {
+		return err
+	}
+
+	rules := make(map[string][]PermissionRule, len(docs))
+	for _, doc := range docs {
+		var rule PermissionRule
+		if err := docToRule(doc, &rule); err != nil {
+			e.logger.Warn("failed to decode permission rule", zap.Error(err))
+			continue
+		}
+		rules[rule.Role] = append(rules[rule.Role], rule)
+	}
+
+	e.mu.Lock()
+	e.permissions = rules
+	e.mu.Unlock()
+	return nil
+}
+
+// SetPermission upserts rule into permissionsCollection and the
+// in-memory cache, keyed by its Role+Collection pair.
+func (e *AuthEngine) SetPermission(rule PermissionRule) error {
+	doc, err := ruleToDoc(rule)
+	if err != nil {
+		return err
+	}
+	doc["_id"] = rule.id()
+
+	if _, err := e.node.GetDocument(permissionsCollection, rule.id(), cluster.Stale); err == nil {
+		if err := e.node.UpdateDocument(permissionsCollection, rule.id(), doc); err != nil {
+			return err
+		}
+	} else if _, err := e.node.InsertDocument(permissionsCollection, doc); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	existing := e.permissions[rule.Role]
+	replaced := false
+	for i, r := range existing {
+		if r.Collection == rule.Collection {
+			existing[i] = rule
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		existing = append(existing, rule)
+	}
+	if e.permissions == nil {
+		e.permissions = make(map[string][]PermissionRule)
+	}
+	e.permissions[rule.Role] = existing
+	return nil
+}
+
+// rulesFor returns the cached PermissionRules for role against
+// collection (there's at most one, since SetPermission upserts by
+// Role+Collection), or nil if none are configured.
+func (e *AuthEngine) rulesFor(role, collection string) *PermissionRule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, r := range e.permissions[role] {
+		if r.Collection == collection {
+			return &r
+		}
+	}
+	return nil
+}
+
+// Authorize reports whether claims' role may perform op against
+// collection, and the row filter (possibly nil) that should be merged
+// into the query/checked against doc to enforce it.
+//
+// Precedence: "admin" always passes with no filter. Otherwise, a
+// configured PermissionRule for (role, collection) is authoritative -
+// its Ops list decides the verdict and its Filter (if any) is what's
+// returned. With no matching rule, Authorize falls back to IsAuthorized
+// with the coarse ActionRead/ActionWrite this collection's op maps to,
+// so a deployment that hasn't configured granular ACLs keeps the
+// existing route-level behavior.
+func (e *AuthEngine) Authorize(claims *Claims, op Op, collection string, doc map[string]interface{}) (bool, map[string]interface{}) {
+	if claims == nil {
+		return false, nil
+	}
+	if claims.Role == "admin" {
+		return true, nil
+	}
+
+	if rule := e.rulesFor(claims.Role, collection); rule != nil {
+		if !rule.allows(op) {
+			return false, nil
+		}
+		if doc != nil && len(rule.Filter) > 0 && !matchesFilter(doc, rule.Filter) {
+			return false, nil
+		}
+		return true, rule.Filter
+	}
+
+	action := ActionRead
+	if op != OpSelect {
+		action = ActionWrite
+	}
+	return e.IsAuthorized(claims.Role, action), nil
+}
+
+// matchesFilter reports whether every key/value in filter is present and
+// equal in doc - the same equality-only semantics RunQuery's filter
+// fallback and platform/graphql's Subscribe use.
+func matchesFilter(doc, filter map[string]interface{}) bool {
+	for k, v := range filter {
+		if dv, ok := doc[k]; !ok || fmt.Sprint(dv) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// claimsContextKey is the context.Context key WithClaims/ClaimsFromContext
+// use to thread a request's Claims into code (e.g. platform/graphql's
+// resolvers) that only has a context.Context, not a gin.Context.
+type claimsContextKey struct{}
+
+// WithClaims returns a copy of ctx carrying claims, retrievable via
+// ClaimsFromContext.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext retrieves the Claims WithClaims attached to ctx, if
+// any.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+func ruleToDoc(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func docToRule(doc map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,27 @@
+package auth
+
+import "testing"
+
+func TestIsAuthorized_EditorLacksActionAdmin(t *testing.T) {
+	e := NewAuthEngine(nil, nil)
+	if e.IsAuthorized("editor", ActionAdmin) {
+		t.Fatal("editor should not be authorized for ActionAdmin")
+	}
+	if !e.IsAuthorized("editor", ActionWrite) {
+		t.Fatal("editor should still be authorized for ActionWrite")
+	}
+}
+
+func TestIsAuthorized_AdminHasActionAdmin(t *testing.T) {
+	e := NewAuthEngine(nil, nil)
+	if !e.IsAuthorized("admin", ActionAdmin) {
+		t.Fatal("admin should be authorized for ActionAdmin")
+	}
+}
+
+func TestIsAuthorized_ViewerLacksActionAdmin(t *testing.T) {
+	e := NewAuthEngine(nil, nil)
+	if e.IsAuthorized("viewer", ActionAdmin) {
+		t.Fatal("viewer should not be authorized for ActionAdmin")
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/core"
+)
+
+// revokedTokensCollection is the system collection revoked jtis are
+// recorded in: rotated refresh tokens (see Refresh) and every token passed
+// to Revoke. This is additive to, not a replacement for, store.Store's
+// pop-backed auth_revoked_tokens table: that table tracks access tokens
+// issued through a Store-backed AuthEngine, while this collection only
+// depends on the cluster.Node every AuthEngine already has, so it works
+// whether or not a Store is configured.
+const revokedTokensCollection = "_auth_revoked_tokens"
+
+// revokedToken is the document shape written to revokedTokensCollection.
+type revokedToken struct {
+	JTI       string    `json:"_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// revokeJTI records jti as revoked in revokedTokensCollection, so
+// isJTIRevoked rejects it on any later check even before it expires. A
+// no-op (returning nil) if e.node is unset or jti is empty.
+func (e *AuthEngine) revokeJTI(jti string, expiresAt time.Time) error {
+	if e.node == nil || jti == "" {
+		return nil
+	}
+	doc, err := ruleToDoc(revokedToken{JTI: jti, ExpiresAt: expiresAt, RevokedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = e.node.InsertDocument(revokedTokensCollection, doc)
+	return err
+}
+
+// isJTIRevoked reports whether jti was recorded via revokeJTI. A
+// "document not found" error means jti simply isn't revoked; any other
+// error (a transient cluster/storage failure) is propagated so callers
+// can fail closed instead of treating an inconclusive check as "not
+// revoked".
+func (e *AuthEngine) isJTIRevoked(jti string) (bool, error) {
+	if e.node == nil || jti == "" {
+		return false, nil
+	}
+	_, err := e.node.GetDocument(revokedTokensCollection, jti, cluster.Stale)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, core.ErrNotFound) {
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"io"
+)
+
+func randRead(b []byte) (int, error) {
+	return io.ReadFull(rand.Reader, b)
+}
+
+func base64Encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func base64Decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+func constantTimeEqual(a, b []byte) bool {
+	return len(a) == len(b) && subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// hashAPIKey is a plain SHA-256 of the plaintext key - unlike passwords,
+// API keys are high-entropy random tokens rather than human-chosen
+// secrets, so they don't need argon2id's deliberately-slow KDF to resist
+// brute force.
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return base64Encode(sum[:])
+}
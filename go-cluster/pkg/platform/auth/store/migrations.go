@@ -0,0 +1,17 @@
+package store
+
+import (
+	"embed"
+
+	"github.com/gobuffalo/pop/v6"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// newFileMigrator builds a pop.MigrationBox over the embedded migrations
+// directory, so the binary doesn't need the migrations/ directory
+// shipped alongside it on disk.
+func newFileMigrator(conn *pop.Connection) (pop.MigrationBox, error) {
+	return pop.NewMigrationBox(migrationFS, conn)
+}
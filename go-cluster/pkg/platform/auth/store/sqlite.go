@@ -0,0 +1,9 @@
+//go:build cgo
+
+package store
+
+// SQLite support goes through mattn/go-sqlite3, which wraps the SQLite C
+// library via cgo. Building without CGO_ENABLED=1 still links Store, but
+// NewStore rejects a sqlite3:// --database-url since the driver behind
+// pop's "sqlite3" dialect isn't registered.
+import _ "github.com/mattn/go-sqlite3"
@@ -0,0 +1,304 @@
+// Package store persists AuthEngine's users, API keys, and revoked JWT
+// IDs through gobuffalo/pop, so credentials and revocations survive a
+// restart instead of living only in AuthEngine's memory.
+//
+// Store is dialect-agnostic: NewStore's databaseURL scheme
+// (postgres://, mysql://, cockroach://, sqlite3://) picks the pop
+// connection dialect, so the same Store API backs PostgreSQL, MySQL,
+// CockroachDB, or an embedded SQLite file depending on what an operator
+// passes to --database-url. SQLite support requires CGO (see sqlite.go)
+// since gobuffalo/pop's sqlite3 dialect goes through mattn/go-sqlite3.
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	_ "github.com/lib/pq"
+	"golang.org/x/crypto/argon2"
+)
+
+var (
+	// ErrUserNotFound is returned when no user matches a lookup.
+	ErrUserNotFound = errors.New("auth/store: user not found")
+	// ErrUserExists is returned by CreateUser when username is already taken.
+	ErrUserExists = errors.New("auth/store: username already exists")
+	// ErrInvalidCredentials is returned by Authenticate on a bad password.
+	ErrInvalidCredentials = errors.New("auth/store: invalid credentials")
+	// ErrAPIKeyNotFound is returned when no active API key matches a lookup.
+	ErrAPIKeyNotFound = errors.New("auth/store: api key not found or revoked")
+)
+
+// User is a platform account: a username, an argon2id password hash, and
+// the role AuthEngine puts on issued JWTs.
+type User struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	Username     string    `db:"username" json:"username"`
+	PasswordHash string    `db:"password_hash" json:"-"`
+	Role         string    `db:"role" json:"role"`
+}
+
+// TableName implements pop's Tabler interface.
+func (User) TableName() string { return "auth_users" }
+
+// APIKey is a long-lived credential issued to a User, authenticated by
+// its hash rather than the plaintext key (which is only ever returned
+// once, at creation).
+type APIKey struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	Name      string     `db:"name" json:"name"`
+	KeyHash   string     `db:"key_hash" json:"-"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
+
+// TableName implements pop's Tabler interface.
+func (APIKey) TableName() string { return "auth_api_keys" }
+
+// revokedToken is the on-disk record ValidateToken checks before trusting
+// an otherwise-valid JWT: its jti (the Claims.RegisteredClaims.ID), when
+// it would expire anyway (so expired rows can be pruned), and RevokedAt,
+// nil until RevokeToken is called for it.
+type revokedToken struct {
+	JTI       string     `db:"jti" json:"jti"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
+
+// TableName implements pop's Tabler interface.
+func (revokedToken) TableName() string { return "auth_revoked_tokens" }
+
+// argon2Params are the argon2id cost parameters used for every hash this
+// package creates. Existing hashes remain verifiable even if these
+// change, since the parameters are encoded into the stored hash string.
+var argon2Params = struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	keyLen  uint32
+}{time: 1, memory: 64 * 1024, threads: 4, keyLen: 32}
+
+// Store wraps a pop.Connection with the user/API-key/revocation queries
+// AuthEngine needs.
+type Store struct {
+	conn *pop.Connection
+}
+
+// NewStore opens a Store against databaseURL. The URL scheme selects the
+// pop dialect: postgres://, mysql://, cockroach://, or sqlite3:// (the
+// last requires the cgo build tag; see sqlite.go).
+func NewStore(databaseURL string) (*Store, error) {
+	dialect, err := dialectForURL(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pop.NewConnection(&pop.ConnectionDetails{
+		Dialect: dialect,
+		URL:     databaseURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("auth/store: open connection: %w", err)
+	}
+	if err := conn.Open(); err != nil {
+		return nil, fmt.Errorf("auth/store: connect: %w", err)
+	}
+	return &Store{conn: conn}, nil
+}
+
+func dialectForURL(databaseURL string) (string, error) {
+	scheme := databaseURL
+	if i := strings.Index(databaseURL, "://"); i >= 0 {
+		scheme = databaseURL[:i]
+	}
+	switch scheme {
+	case "postgres", "postgresql":
+		return "postgres", nil
+	case "mysql":
+		return "mysql", nil
+	case "cockroach", "cockroachdb":
+		return "cockroach", nil
+	case "sqlite3", "sqlite":
+		return "sqlite3", nil
+	default:
+		return "", fmt.Errorf("auth/store: unrecognized --database-url scheme %q (want postgres://, mysql://, cockroach://, or sqlite3://)", scheme)
+	}
+}
+
+// Migrate applies every migration in the embedded migrations directory
+// that hasn't already run, tracked by pop's own schema_migrations table.
+func (s *Store) Migrate() error {
+	box, err := newFileMigrator(s.conn)
+	if err != nil {
+		return fmt.Errorf("auth/store: load migrations: %w", err)
+	}
+	return box.Up()
+}
+
+// Close releases the underlying database connection.
+func (s *Store) Close() error {
+	return s.conn.Close()
+}
+
+// hashPassword argon2id-hashes password under a random salt, encoding
+// both into the returned string as "salt$hash", both base64.
+func hashPassword(password string) string {
+	salt := make([]byte, 16)
+	_, _ = randRead(salt)
+	hash := argon2.IDKey([]byte(password), salt, argon2Params.time, argon2Params.memory, argon2Params.threads, argon2Params.keyLen)
+	return base64Encode(salt) + "$" + base64Encode(hash)
+}
+
+func verifyPassword(password, encoded string) bool {
+	parts := strings.SplitN(encoded, "$", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	salt, err := base64Decode(parts[0])
+	if err != nil {
+		return false
+	}
+	want, err := base64Decode(parts[1])
+	if err != nil {
+		return false
+	}
+	got := argon2.IDKey([]byte(password), salt, argon2Params.time, argon2Params.memory, argon2Params.threads, uint32(len(want)))
+	return constantTimeEqual(got, want)
+}
+
+// CreateUser hashes password and inserts a new User with role.
+func (s *Store) CreateUser(username, password, role string) (*User, error) {
+	existing := &User{}
+	err := s.conn.Where("username = ?", username).First(existing)
+	if err == nil {
+		return nil, ErrUserExists
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("auth/store: check existing user: %w", err)
+	}
+
+	u := &User{
+		Username:     username,
+		PasswordHash: hashPassword(password),
+		Role:         role,
+	}
+	if err := s.conn.Create(u); err != nil {
+		return nil, fmt.Errorf("auth/store: create user: %w", err)
+	}
+	return u, nil
+}
+
+// Authenticate looks up username and verifies password against its
+// stored argon2id hash.
+func (s *Store) Authenticate(username, password string) (*User, error) {
+	u := &User{}
+	if err := s.conn.Where("username = ?", username).First(u); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("auth/store: lookup user: %w", err)
+	}
+	if !verifyPassword(password, u.PasswordHash) {
+		return nil, ErrInvalidCredentials
+	}
+	return u, nil
+}
+
+// CreateAPIKey mints a new API key for userID, returning both the
+// plaintext key (shown to the caller exactly once) and its stored record.
+func (s *Store) CreateAPIKey(userID uuid.UUID, name string) (plaintext string, rec *APIKey, err error) {
+	raw := make([]byte, 32)
+	if _, err := randRead(raw); err != nil {
+		return "", nil, fmt.Errorf("auth/store: generate api key: %w", err)
+	}
+	plaintext = "sk_" + base64Encode(raw)
+
+	rec = &APIKey{
+		UserID:  userID,
+		Name:    name,
+		KeyHash: hashAPIKey(plaintext),
+	}
+	if err := s.conn.Create(rec); err != nil {
+		return "", nil, fmt.Errorf("auth/store: create api key: %w", err)
+	}
+	return plaintext, rec, nil
+}
+
+// AuthenticateAPIKey resolves plaintext back to the User that owns it, if
+// the key exists and hasn't been revoked.
+func (s *Store) AuthenticateAPIKey(plaintext string) (*User, error) {
+	hash := hashAPIKey(plaintext)
+	rec := &APIKey{}
+	if err := s.conn.Where("key_hash = ? AND revoked_at IS NULL", hash).First(rec); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("auth/store: lookup api key: %w", err)
+	}
+	u := &User{}
+	if err := s.conn.Find(u, rec.UserID); err != nil {
+		return nil, fmt.Errorf("auth/store: lookup api key owner: %w", err)
+	}
+	return u, nil
+}
+
+// RecordIssuedToken notes that jti was issued so a later RevokeToken call
+// for it has something to mark revoked; ValidateToken only consults
+// IsTokenRevoked, so this is bookkeeping rather than a prerequisite for
+// the token to validate.
+func (s *Store) RecordIssuedToken(jti string, expiresAt time.Time) error {
+	existing := &revokedToken{}
+	err := s.conn.Where("jti = ?", jti).First(existing)
+	if err == nil {
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("auth/store: check issued token: %w", err)
+	}
+	if err := s.conn.Create(&revokedToken{JTI: jti, ExpiresAt: expiresAt}); err != nil {
+		return fmt.Errorf("auth/store: record issued token: %w", err)
+	}
+	return nil
+}
+
+// RevokeToken marks jti as revoked as of now, inserting a row for it if
+// RecordIssuedToken was never called (e.g. a token issued before the
+// store was wired in).
+func (s *Store) RevokeToken(jti string) error {
+	now := time.Now()
+	rt := &revokedToken{}
+	err := s.conn.Where("jti = ?", jti).First(rt)
+	if err == sql.ErrNoRows {
+		return s.conn.Create(&revokedToken{JTI: jti, ExpiresAt: now, RevokedAt: &now})
+	}
+	if err != nil {
+		return fmt.Errorf("auth/store: lookup token: %w", err)
+	}
+	rt.RevokedAt = &now
+	if err := s.conn.Update(rt); err != nil {
+		return fmt.Errorf("auth/store: revoke token: %w", err)
+	}
+	return nil
+}
+
+// IsTokenRevoked reports whether jti has a non-nil RevokedAt. A jti with
+// no row at all (never recorded via RecordIssuedToken) is treated as not
+// revoked, so tokens issued before the store was wired in still validate.
+func (s *Store) IsTokenRevoked(jti string) (bool, error) {
+	rt := &revokedToken{}
+	err := s.conn.Where("jti = ?", jti).First(rt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("auth/store: check revocation: %w", err)
+	}
+	return rt.RevokedAt != nil, nil
+}
@@ -0,0 +1,283 @@
+// Package config implements a pluggable, hot-reloadable configuration
+// handler for platform.Server: JSON/YAML (de)serialization of the whole
+// document, partial reads/writes by RFC 6901 JSON pointer path, a content
+// fingerprint for optimistic-concurrency updates, and a subscription hook
+// so subsystems can react to a config change without restarting.
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tdb-plus/cluster/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrFingerprintMismatch is returned by DoLockedAction when fingerprint no
+// longer matches the handler's current config - someone else already
+// changed it since the caller last read Fingerprint().
+var ErrFingerprintMismatch = errors.New("platform/config: fingerprint mismatch, config changed since it was read")
+
+// ConfigHandler is the pluggable interface platform.Server's config routes
+// go through. Handler is the default implementation.
+type ConfigHandler interface {
+	// Marshal encodes the current config as JSON.
+	Marshal() ([]byte, error)
+	// Unmarshal replaces the current config wholesale from data, which
+	// may be JSON or YAML (YAML is attempted if JSON decoding fails).
+	Unmarshal(data []byte) error
+
+	// MarshalJSONPath returns the JSON-encoded value at the RFC 6901
+	// JSON pointer path (e.g. "/tiering/hot_policy/enabled"), relative to
+	// the current config.
+	MarshalJSONPath(path string) ([]byte, error)
+	// UnmarshalJSONPath sets the value at path to the JSON-encoded data,
+	// creating intermediate objects as needed.
+	UnmarshalJSONPath(path string, data []byte) error
+
+	// Fingerprint returns a stable hash of the current config, to be
+	// passed back into DoLockedAction.
+	Fingerprint() string
+	// DoLockedAction runs cb against the live config only if fingerprint
+	// still matches Fingerprint()'s current value, guaranteeing cb
+	// observes and mutates a config no one else changed in between.
+	// Returns ErrFingerprintMismatch otherwise.
+	DoLockedAction(fingerprint string, cb func(cfg *config.Config) error) error
+
+	// Subscribe registers fn to run after every successful mutation
+	// (Unmarshal, UnmarshalJSONPath, or a non-erroring DoLockedAction).
+	Subscribe(fn func(cfg *config.Config))
+}
+
+// Handler is the default ConfigHandler: an in-memory *config.Config
+// guarded by a mutex.
+type Handler struct {
+	mu          sync.RWMutex
+	cfg         *config.Config
+	subscribers []func(cfg *config.Config)
+}
+
+var _ ConfigHandler = (*Handler)(nil)
+
+// NewHandler wraps cfg for hot-reloadable access. Callers should go
+// through Handler's methods rather than mutating cfg directly once it's
+// been handed to NewHandler, so Subscribe callbacks fire on every change.
+func NewHandler(cfg *config.Config) *Handler {
+	return &Handler{cfg: cfg}
+}
+
+// Config returns the current config. Treat the result as read-only
+// outside of a DoLockedAction callback.
+func (h *Handler) Config() *config.Config {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.cfg
+}
+
+func (h *Handler) Marshal() ([]byte, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return json.Marshal(h.cfg)
+}
+
+func (h *Handler) Unmarshal(data []byte) error {
+	var next config.Config
+	if err := json.Unmarshal(data, &next); err != nil {
+		if yerr := yaml.Unmarshal(data, &next); yerr != nil {
+			return fmt.Errorf("platform/config: data is neither valid JSON (%v) nor YAML (%v)", err, yerr)
+		}
+	}
+
+	h.mu.Lock()
+	h.cfg = &next
+	h.mu.Unlock()
+
+	h.notify()
+	return nil
+}
+
+func (h *Handler) MarshalJSONPath(path string) ([]byte, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	doc, err := h.documentLocked()
+	if err != nil {
+		return nil, err
+	}
+	val, err := getPath(doc, splitPointer(path))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(val)
+}
+
+func (h *Handler) UnmarshalJSONPath(path string, data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("platform/config: decode value: %w", err)
+	}
+	segments := splitPointer(path)
+	if len(segments) == 0 {
+		return fmt.Errorf("platform/config: UnmarshalJSONPath requires a non-empty path")
+	}
+
+	h.mu.Lock()
+	doc, err := h.documentLocked()
+	if err != nil {
+		h.mu.Unlock()
+		return err
+	}
+	if err := setPath(doc, segments, value); err != nil {
+		h.mu.Unlock()
+		return err
+	}
+	next, err := documentToConfig(doc)
+	if err != nil {
+		h.mu.Unlock()
+		return err
+	}
+	h.cfg = next
+	h.mu.Unlock()
+
+	h.notify()
+	return nil
+}
+
+func (h *Handler) Fingerprint() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return fingerprintLocked(h.cfg)
+}
+
+func (h *Handler) DoLockedAction(fingerprint string, cb func(cfg *config.Config) error) error {
+	h.mu.Lock()
+	if fingerprintLocked(h.cfg) != fingerprint {
+		h.mu.Unlock()
+		return ErrFingerprintMismatch
+	}
+	err := cb(h.cfg)
+	h.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	h.notify()
+	return nil
+}
+
+func (h *Handler) Subscribe(fn func(cfg *config.Config)) {
+	h.mu.Lock()
+	h.subscribers = append(h.subscribers, fn)
+	h.mu.Unlock()
+}
+
+func (h *Handler) notify() {
+	h.mu.RLock()
+	cfg := h.cfg
+	subs := append([]func(cfg *config.Config){}, h.subscribers...)
+	h.mu.RUnlock()
+	for _, fn := range subs {
+		fn(cfg)
+	}
+}
+
+func fingerprintLocked(cfg *config.Config) string {
+	raw, _ := json.Marshal(cfg)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// documentLocked round-trips h.cfg through JSON into a generic document,
+// the same way cluster.NewNode passes config.Config to core.Open - the
+// established pattern in this tree for treating the typed config as
+// arbitrary JSON. Must be called with h.mu held.
+func (h *Handler) documentLocked() (map[string]interface{}, error) {
+	raw, err := json.Marshal(h.cfg)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func documentToConfig(doc map[string]interface{}) (*config.Config, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config.Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("platform/config: patched document doesn't fit config.Config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// splitPointer parses an RFC 6901 JSON pointer ("/a/b/0") into unescaped
+// segments; "" and "/" both mean the whole document.
+func splitPointer(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil
+	}
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		p = strings.ReplaceAll(p, "~1", "/")
+		p = strings.ReplaceAll(p, "~0", "~")
+		parts[i] = p
+	}
+	return parts
+}
+
+func getPath(doc interface{}, segments []string) (interface{}, error) {
+	cur := doc
+	for i, seg := range segments {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[seg]
+			if !ok {
+				return nil, fmt.Errorf("platform/config: no such path \"/%s\"", strings.Join(segments[:i+1], "/"))
+			}
+			cur = next
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, fmt.Errorf("platform/config: invalid array index %q", seg)
+			}
+			cur = v[idx]
+		default:
+			return nil, fmt.Errorf("platform/config: \"/%s\" is not an object or array", strings.Join(segments[:i], "/"))
+		}
+	}
+	return cur, nil
+}
+
+// setPath walks doc by segments, creating intermediate objects as needed,
+// and sets the final segment to value.
+func setPath(doc map[string]interface{}, segments []string, value interface{}) error {
+	cur := doc
+	for _, seg := range segments[:len(segments)-1] {
+		next, ok := cur[seg]
+		if !ok {
+			m := make(map[string]interface{})
+			cur[seg] = m
+			cur = m
+			continue
+		}
+		m, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("platform/config: %q is not an object", seg)
+		}
+		cur = m
+	}
+	cur[segments[len(segments)-1]] = value
+	return nil
+}
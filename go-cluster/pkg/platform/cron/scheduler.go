@@ -1,16 +1,47 @@
 package cron
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"sync"
 	"time"
 
-	"github.com/lumadb/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/ai"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/platform/auth"
+	gql "github.com/tdb-plus/cluster/pkg/platform/graphql"
+	"github.com/tdb-plus/cluster/pkg/query"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
 
+// cronJobsCollection and cronHistoryCollection are the system collections
+// AddJob/RemoveJob and executeJob persist to via the owning Node, the
+// same way every other durable state in this codebase is a regular
+// collection rather than a bespoke store - which also means both are
+// automatically queryable through the dynamic GraphQL schema (see
+// platform/graphql's BuildSchema, which builds a type per
+// ListCollections entry) with no extra wiring.
+const (
+	cronJobsCollection    = "_cron_jobs"
+	cronHistoryCollection = "_cron_job_history"
+)
+
+// maxScheduledJobs bounds how many persisted jobs Start's replay will
+// load in one query.
+const maxScheduledJobs = 10000
+
+// defaultJobTimeout bounds executeJob's context when a job's Payload
+// doesn't set its own "timeout_seconds".
+const defaultJobTimeout = 10 * time.Second
+
 // Job represents a scheduled task
 type Job struct {
 	Name     string                 `json:"name"`
@@ -18,28 +49,120 @@ type Job struct {
 	Payload  map[string]interface{} `json:"payload"`
 }
 
+// JobRun is one execution attempt of a Job, persisted to
+// cronHistoryCollection so operators can see missed or failed runs
+// instead of only whatever made it into the logs.
+type JobRun struct {
+	Job       string                 `json:"job"`
+	StartedAt time.Time              `json:"started_at"`
+	EndedAt   time.Time              `json:"ended_at"`
+	Status    string                 `json:"status"` // "success" or "error"
+	Error     string                 `json:"error,omitempty"`
+	Result    map[string]interface{} `json:"result,omitempty"`
+}
+
+// JobHandler runs a Job's Payload for a given payload type and reports a
+// structured result to record alongside the JobRun. Implementations
+// should respect ctx's deadline.
+type JobHandler interface {
+	Handle(ctx context.Context, job Job) (map[string]interface{}, error)
+}
+
+// JobHandlerFunc adapts a plain function to a JobHandler, the same way
+// http.HandlerFunc adapts a function to an http.Handler.
+type JobHandlerFunc func(ctx context.Context, job Job) (map[string]interface{}, error)
+
+func (f JobHandlerFunc) Handle(ctx context.Context, job Job) (map[string]interface{}, error) {
+	return f(ctx, job)
+}
+
 // Scheduler manages cron jobs
 type Scheduler struct {
 	node   *cluster.Node
 	logger *zap.Logger
 	cron   *cron.Cron
 	jobs   map[string]Job
-	mu     sync.RWMutex
+	// entryIDs tracks each job's robfig/cron entry, so RemoveJob can stop
+	// it without restarting the whole Scheduler.
+	entryIDs map[string]cron.EntryID
+	mu       sync.RWMutex
+
+	handlersMu sync.RWMutex
+	handlers   map[string]JobHandler
+
+	// gqlEngine, authEngine and ragService back the built-in "graphql",
+	// "webhook" and "rag_ingest" handlers respectively. Each is optional
+	// (set via SetGraphQLEngine/SetAuthEngine/SetRAGService once platform
+	// construction has them ready) - a job whose type needs one that's
+	// unset fails with a descriptive error rather than panicking.
+	gqlEngine  *gql.GraphQLEngine
+	authEngine *auth.AuthEngine
+	ragService *ai.RAGService
+	httpClient *http.Client
 }
 
 // NewScheduler creates a new cron scheduler
 func NewScheduler(node *cluster.Node, logger *zap.Logger) *Scheduler {
-	return &Scheduler{
-		node:   node,
-		logger: logger,
-		cron:   cron.New(cron.WithSeconds()), // Support seconds for precision
-		jobs:   make(map[string]Job),
+	s := &Scheduler{
+		node:       node,
+		logger:     logger,
+		cron:       cron.New(cron.WithSeconds()), // Support seconds for precision
+		jobs:       make(map[string]Job),
+		entryIDs:   make(map[string]cron.EntryID),
+		handlers:   make(map[string]JobHandler),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
 	}
+
+	s.RegisterHandler("sql", JobHandlerFunc(s.handleSQL))
+	s.RegisterHandler("graphql", JobHandlerFunc(s.handleGraphQL))
+	s.RegisterHandler("webhook", JobHandlerFunc(s.handleWebhook))
+	s.RegisterHandler("rag_ingest", JobHandlerFunc(s.handleRAGIngest))
+
+	return s
 }
 
-// Start begins the scheduler
+// RegisterHandler wires handler to run for every Job whose
+// Payload["type"] equals payloadType, replacing the built-in handler (if
+// any) already registered for it. Callers outside this package use this
+// to teach the Scheduler about payload types of their own.
+func (s *Scheduler) RegisterHandler(payloadType string, handler JobHandler) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	s.handlers[payloadType] = handler
+}
+
+// SetGraphQLEngine wires e as the executor behind the built-in "graphql"
+// payload type.
+func (s *Scheduler) SetGraphQLEngine(e *gql.GraphQLEngine) {
+	s.gqlEngine = e
+}
+
+// SetAuthEngine wires e as the source of the HMAC secret the built-in
+// "webhook" payload type signs its requests with.
+func (s *Scheduler) SetAuthEngine(e *auth.AuthEngine) {
+	s.authEngine = e
+}
+
+// SetRAGService wires r as the executor behind the built-in "rag_ingest"
+// payload type.
+func (s *Scheduler) SetRAGService(r *ai.RAGService) {
+	s.ragService = r
+}
+
+// Start replays every job persisted in cronJobsCollection (so jobs
+// survive a restart) and begins the underlying cron.Cron timer. Every
+// node in the cluster calls Start and schedules the same jobs locally;
+// executeJob is what keeps only the current leader from actually firing
+// a job's trigger.
 func (s *Scheduler) Start() {
 	s.logger.Info("Starting Cron Scheduler")
+
+	if err := s.loadPersistedJobs(); err != nil {
+		s.logger.Error("Failed to load persisted cron jobs", zap.Error(err))
+		// Continue anyway - an empty schedule is better than refusing to
+		// start the server over it.
+	}
+
 	s.cron.Start()
 }
 
@@ -48,50 +171,411 @@ func (s *Scheduler) Stop() {
 	s.cron.Stop()
 }
 
-// AddJob registers a new cron job
+// loadPersistedJobs reschedules every job found in cronJobsCollection,
+// without re-persisting it (it's already there).
+func (s *Scheduler) loadPersistedJobs() error {
+	docs, err := s.node.RunQuery(cronJobsCollection, map[string]interface{}{"limit": maxScheduledJobs}, cluster.Stale)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range docs {
+		var job Job
+		if err := docToStruct(doc, &job); err != nil {
+			s.logger.Error("Failed to decode persisted cron job", zap.Error(err))
+			continue
+		}
+		if err := s.scheduleJob(job); err != nil {
+			s.logger.Error("Failed to reschedule persisted cron job", zap.String("name", job.Name), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// AddJob registers a new cron job and persists it to cronJobsCollection
+// so it survives a restart and is replayed by every node's Start.
 func (s *Scheduler) AddJob(job Job) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	if err := s.scheduleJob(job); err != nil {
+		return err
+	}
 
-	// Validate cron syntax
-	_, err := cron.ParseStandard(job.Schedule)
-	if err != nil {
-		// Try with seconds parser if standard fails, but v3 parser is flexible
-		// Actually, we instantiated WithSeconds, so we should test that specific parser
-		// For now, let the AddFunc handle validation implicitly or catch panic
+	if err := s.persistJob(job); err != nil {
+		s.logger.Error("Failed to persist cron job", zap.String("name", job.Name), zap.Error(err))
 	}
+	return nil
+}
+
+// scheduleJob registers job with the underlying cron.Cron and the
+// in-memory job/entry maps, without touching persistence - the part
+// AddJob and loadPersistedJobs share.
+func (s *Scheduler) scheduleJob(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	id, err := s.cron.AddFunc(job.Schedule, func() {
 		s.executeJob(job)
 	})
 	if err != nil {
-		return fmt.Errorf("invalid schedule: %v", err)
+		return fmt.Errorf("invalid schedule: %w", err)
 	}
 
 	s.jobs[job.Name] = job
-	s.logger.Info("Added cron job", zap.String("name", job.Name), zap.String("schedule", job.Schedule), zap.Int("id", int(id)))
+	s.entryIDs[job.Name] = id
+	s.logger.Info("Scheduled cron job", zap.String("name", job.Name), zap.String("schedule", job.Schedule), zap.Int("id", int(id)))
 	return nil
 }
 
+// RemoveJob stops job's future executions and deletes its persisted
+// record.
+func (s *Scheduler) RemoveJob(name string) error {
+	s.mu.Lock()
+	id, ok := s.entryIDs[name]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("no such job: %q", name)
+	}
+	delete(s.jobs, name)
+	delete(s.entryIDs, name)
+	s.mu.Unlock()
+
+	s.cron.Remove(id)
+
+	return s.node.DeleteDocument(cronJobsCollection, name)
+}
+
+// persistJob upserts job into cronJobsCollection, keyed by its Name.
+func (s *Scheduler) persistJob(job Job) error {
+	doc, err := structToDoc(job)
+	if err != nil {
+		return err
+	}
+	doc["_id"] = job.Name
+
+	if _, err := s.node.GetDocument(cronJobsCollection, job.Name, cluster.Stale); err == nil {
+		return s.node.UpdateDocument(cronJobsCollection, job.Name, doc)
+	}
+	_, err = s.node.InsertDocument(cronJobsCollection, doc)
+	return err
+}
+
+// jobTimeout reads job.Payload["timeout_seconds"], falling back to
+// defaultJobTimeout if it's absent or not a number.
+func jobTimeout(job Job) time.Duration {
+	if secs, ok := job.Payload["timeout_seconds"].(float64); ok && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	return defaultJobTimeout
+}
+
+// executeJob fires job's trigger only if this node is the current Raft
+// leader (IsLeader checks shard 0, the coordination group every
+// key-less leader decision elsewhere in this codebase treats as
+// authoritative - see Node.IsLeader). Every node's cron.Cron still ticks
+// this func on schedule; gating here, rather than in scheduleJob, is
+// what keeps an N-node deployment from firing a job's webhook or query N
+// times. The handler for job.Payload["type"] does the actual work; start
+// and end time, status, any error, and the handler's result are recorded
+// to cronHistoryCollection so operators can see missed or failed runs.
 func (s *Scheduler) executeJob(job Job) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if !s.node.IsLeader() {
+		return
+	}
+
+	run := JobRun{Job: job.Name, StartedAt: time.Now()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout(job))
 	defer cancel()
 
 	s.logger.Info("Executing cron job", zap.String("name", job.Name))
 
-	// Determine what to do based on Payload
-	// MVP: Just log it or execute a dummy query if specified
-	if query, ok := job.Payload["query"].(string); ok {
-		// Ensure we have a DB reference
-		// s.node.GetDatabase()... but Node execution path might be different
-		// For now, we just log "Would execute query: " + query
-		s.logger.Info("Cron Trigger Query", zap.String("query", query))
-	} else if url, ok := job.Payload["url"].(string); ok {
-		// Webhook
-		s.logger.Info("Cron Trigger Webhook", zap.String("url", url))
+	result, err := s.dispatch(ctx, job)
+	if err != nil {
+		run.Status = "error"
+		run.Error = err.Error()
+		s.logger.Error("Cron job failed", zap.String("name", job.Name), zap.Error(err))
+	} else {
+		run.Status = "success"
+		run.Result = result
+	}
+	run.EndedAt = time.Now()
+
+	s.recordRun(run)
+}
+
+// dispatch looks up the JobHandler registered for job.Payload["type"]
+// and runs it.
+func (s *Scheduler) dispatch(ctx context.Context, job Job) (map[string]interface{}, error) {
+	payloadType, _ := job.Payload["type"].(string)
+	if payloadType == "" {
+		return nil, fmt.Errorf("payload missing \"type\"")
+	}
+
+	s.handlersMu.RLock()
+	handler, ok := s.handlers[payloadType]
+	s.handlersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no handler registered for payload type %q", payloadType)
+	}
+
+	return handler.Handle(ctx, job)
+}
+
+// handleSQL runs job.Payload["query"] (LQL, the same dialect pkg/query
+// parses for the HTTP/gRPC query endpoints) directly against this node.
+// Only SELECT is supported - a cron job that wants to write runs an
+// INSERT/UPDATE/DELETE through the webhook type against the regular API
+// instead, so it goes through the same validation/auth path a human
+// caller would.
+func (s *Scheduler) handleSQL(ctx context.Context, job Job) (map[string]interface{}, error) {
+	q, _ := job.Payload["query"].(string)
+	if q == "" {
+		return nil, fmt.Errorf("sql payload missing \"query\"")
+	}
+
+	stmt, err := query.Parse(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+	if stmt.Select == nil {
+		return nil, fmt.Errorf("sql payload must be a SELECT")
+	}
+
+	sel := stmt.Select
+	filter := map[string]interface{}{}
+	if sel.Limit != nil {
+		filter["limit"] = *sel.Limit
+	}
+	if sel.Where != nil && sel.Where.Condition != nil && sel.Where.Condition.Operator == "=" {
+		filter[sel.Where.Condition.Left] = queryValue(sel.Where.Condition.Right)
 	}
 
-	_ = ctx // avoid unused
+	consistency := cluster.Stale
+	if sel.WantsLinearizable() {
+		consistency = cluster.Linearizable
+	}
+
+	docs, err := s.node.RunQueryContext(ctx, sel.From, filter, consistency)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"rows": docs, "count": len(docs)}, nil
+}
+
+// queryValue unwraps a query.Value's one set field into a plain
+// interface{}, the shape RunQuery's filter map expects.
+func queryValue(v *query.Value) interface{} {
+	switch {
+	case v == nil:
+		return nil
+	case v.String != nil:
+		return *v.String
+	case v.Number != nil:
+		return *v.Number
+	case v.Bool != nil:
+		return *v.Bool
+	default:
+		return nil
+	}
+}
+
+// handleGraphQL runs job.Payload["query"] (and optional
+// job.Payload["variables"]) through the platform's GraphQLEngine.
+func (s *Scheduler) handleGraphQL(ctx context.Context, job Job) (map[string]interface{}, error) {
+	if s.gqlEngine == nil {
+		return nil, fmt.Errorf("graphql payload type requires a GraphQLEngine, none configured")
+	}
+	q, _ := job.Payload["query"].(string)
+	if q == "" {
+		return nil, fmt.Errorf("graphql payload missing \"query\"")
+	}
+	variables, _ := job.Payload["variables"].(map[string]interface{})
+
+	result := s.gqlEngine.Execute(ctx, q, variables)
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("graphql execution failed: %s", result.Errors[0].Message)
+	}
+	data, ok := result.Data.(map[string]interface{})
+	if !ok {
+		data = map[string]interface{}{"data": result.Data}
+	}
+	return data, nil
+}
+
+const (
+	webhookMaxAttempts    = 3
+	webhookInitialBackoff = 500 * time.Millisecond
+)
+
+// handleWebhook POSTs job.Payload["body"] (arbitrary JSON) to
+// job.Payload["url"], signing the request body with the auth engine's
+// secret (if one is configured) the same way an inbound webhook receiver
+// would verify it, and retrying transient failures with exponential
+// backoff.
+func (s *Scheduler) handleWebhook(ctx context.Context, job Job) (map[string]interface{}, error) {
+	url, _ := job.Payload["url"].(string)
+	if url == "" {
+		return nil, fmt.Errorf("webhook payload missing \"url\"")
+	}
+
+	body, err := json.Marshal(job.Payload["body"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook body: %w", err)
+	}
+
+	status, respBody, err := s.postWebhook(ctx, url, body)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"status": status, "response": string(respBody)}, nil
+}
+
+// postWebhook POSTs body to url, making up to webhookMaxAttempts attempts
+// in all and retrying with doubling backoff on request or 5xx failures.
+func (s *Scheduler) postWebhook(ctx context.Context, url string, body []byte) (int, []byte, error) {
+	backoff := webhookInitialBackoff
+	var lastErr error
+
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		status, respBody, err := s.doWebhookRequest(ctx, url, body)
+		if err == nil && status < 500 {
+			return status, respBody, nil
+		}
+		if err == nil {
+			lastErr = fmt.Errorf("webhook returned status %d", status)
+		} else {
+			lastErr = err
+		}
+
+		if attempt == webhookMaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return 0, nil, fmt.Errorf("webhook request failed after %d attempts: %w", webhookMaxAttempts, lastErr)
+}
+
+func (s *Scheduler) doWebhookRequest(ctx context.Context, url string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.authEngine != nil {
+		req.Header.Set("X-LumaDB-Signature", signBody(s.authEngine.Secret(), body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+// signBody returns the hex-encoded HMAC-SHA256 of body under secret.
+func signBody(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// handleRAGIngest fetches job.Payload["url"] and ingests the response
+// body into job.Payload["collection"] via the RAGService, refreshing a
+// collection from a source URL on whatever schedule the job was given.
+func (s *Scheduler) handleRAGIngest(ctx context.Context, job Job) (map[string]interface{}, error) {
+	if s.ragService == nil {
+		return nil, fmt.Errorf("rag_ingest payload type requires a RAGService, none configured")
+	}
+	url, _ := job.Payload["url"].(string)
+	collection, _ := job.Payload["collection"].(string)
+	if url == "" || collection == "" {
+		return nil, fmt.Errorf("rag_ingest payload requires \"url\" and \"collection\"")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+
+	result, err := s.ragService.Ingest(collection, string(text), map[string]interface{}{"source_url": url})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ingest %s: %w", url, err)
+	}
+	return map[string]interface{}{"document_id": result.DocumentID}, nil
+}
+
+// recordRun appends run to cronHistoryCollection. A failure to persist
+// it is logged, not returned - the job itself already ran (or failed)
+// by the time this is called, so there's nothing left to roll back.
+func (s *Scheduler) recordRun(run JobRun) {
+	doc, err := structToDoc(run)
+	if err != nil {
+		s.logger.Error("Failed to encode cron job run", zap.String("name", run.Job), zap.Error(err))
+		return
+	}
+	if _, err := s.node.InsertDocument(cronHistoryCollection, doc); err != nil {
+		s.logger.Error("Failed to persist cron job run", zap.String("name", run.Job), zap.Error(err))
+	}
+}
+
+// structToDoc round-trips v through JSON into the map[string]interface{}
+// shape Node's document methods expect.
+func structToDoc(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// docToStruct is structToDoc's inverse, decoding a document retrieved
+// from Node back into v.
+func docToStruct(doc map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// AddFunc schedules fn to run on schedule (robfig/cron/v3 syntax, with
+// seconds since this Scheduler was built via cron.WithSeconds()), under
+// name for logging. Unlike AddJob, fn runs directly rather than going
+// through the Payload/JobHandler convention - the escape hatch
+// subsystems with their own domain logic (e.g. platform/replication) use
+// instead of teaching the Scheduler about every caller's payload shape.
+func (s *Scheduler) AddFunc(name, schedule string, fn func()) (cron.EntryID, error) {
+	id, err := s.cron.AddFunc(schedule, fn)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schedule: %w", err)
+	}
+	s.logger.Info("Scheduled function", zap.String("name", name), zap.String("schedule", schedule), zap.Int("id", int(id)))
+	return id, nil
 }
 
 // ListJobs returns all registered jobs
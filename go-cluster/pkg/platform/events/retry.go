@@ -0,0 +1,246 @@
+package events
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.etcd.io/bbolt"
+)
+
+var retryBucketName = []byte("retries")
+
+var (
+	triggerAttemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lumadb_trigger_delivery_attempts_total",
+		Help: "Total trigger delivery attempts, including retries.",
+	}, []string{"trigger"})
+	triggerSuccessesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lumadb_trigger_delivery_successes_total",
+		Help: "Total trigger deliveries that succeeded, on the first attempt or a retry.",
+	}, []string{"trigger"})
+	triggerDLQTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lumadb_trigger_delivery_dlq_total",
+		Help: "Total trigger deliveries that exhausted retries and were routed to the dead-letter sink.",
+	}, []string{"trigger"})
+)
+
+// RetryConfig controls how a trigger's failed deliveries are retried and,
+// once retries are exhausted, routed to a dead-letter sink.
+type RetryConfig struct {
+	// MaxAttempts bounds total delivery attempts (the initial one plus
+	// retries) before an event is sent to DLQ. <= 0 uses
+	// defaultRetryConfig's value.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; each
+	// subsequent retry doubles it (capped at MaxBackoff) and jitters by
+	// up to 50% to avoid retry storms lining back up in lockstep.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// DLQ is executeTrigger's sink for an event that exhausted retries -
+	// another webhook URL, or a Redpanda topic (conventionally
+	// dlq_<collection>). Events is ignored. Collection defaults to the
+	// trigger's own and only matters for a Redpanda DLQ whose Config has
+	// no "topic", which then goes to events_<Collection>. Nil drops the
+	// event after logging.
+	DLQ *TriggerConfig
+}
+
+func defaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}
+}
+
+// resolved fills in defaultRetryConfig's values for any zero field, so
+// callers can leave a TriggerConfig's Retry nil or partially set.
+func (c RetryConfig) resolved() RetryConfig {
+	d := defaultRetryConfig()
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = d.MaxAttempts
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = d.InitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = d.MaxBackoff
+	}
+	return c
+}
+
+// backoff returns the jittered exponential delay before retry number
+// attempt (1-indexed: the delay before the first retry, after the
+// initial delivery, is backoff(cfg, 1)).
+func backoff(cfg RetryConfig, attempt int) time.Duration {
+	d := cfg.InitialBackoff
+	for i := 1; i < attempt && d < cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > cfg.MaxBackoff {
+		d = cfg.MaxBackoff
+	}
+	// Full jitter: anywhere from 50% to 150% of d, so a burst of
+	// simultaneously-failing deliveries doesn't retry in lockstep.
+	jittered := time.Duration(float64(d) * (0.5 + rand.Float64()))
+	return jittered
+}
+
+// retryEntry is one queued, not-yet-exhausted failed delivery.
+type retryEntry struct {
+	seq     uint64
+	trigger TriggerConfig
+	event   Event
+	attempt int
+	nextTry time.Time
+}
+
+// persistedRetryEntry is retryEntry's on-disk form in the optional
+// BoltDB backing store.
+type persistedRetryEntry struct {
+	Trigger TriggerConfig
+	Event   Event
+	Attempt int
+	NextTry time.Time
+}
+
+// retryQueue holds failed trigger deliveries awaiting their next retry,
+// as a bounded in-memory ring: pushing past capacity drops the oldest
+// entry (the caller routes it to DLQ). It's optionally mirrored to a
+// BoltDB file so queued retries survive a process restart - entries are
+// written on push and removed on pop, so the file only ever reflects
+// what's still outstanding.
+type retryQueue struct {
+	entries  []*retryEntry
+	capacity int
+	nextSeq  uint64
+
+	db *bbolt.DB // nil disables persistence
+}
+
+const defaultRetryQueueCapacity = 10000
+
+func newRetryQueue(capacity int, db *bbolt.DB) *retryQueue {
+	if capacity <= 0 {
+		capacity = defaultRetryQueueCapacity
+	}
+	return &retryQueue{capacity: capacity, db: db}
+}
+
+// push enqueues entry, assigning it a sequence number. If the queue is
+// at capacity, the oldest entry is evicted and returned so the caller
+// can route it to DLQ instead of silently dropping it.
+func (q *retryQueue) push(trigger TriggerConfig, event Event, attempt int, nextTry time.Time) (evicted *retryEntry) {
+	q.nextSeq++
+	entry := &retryEntry{
+		seq:     q.nextSeq,
+		trigger: trigger,
+		event:   event,
+		attempt: attempt,
+		nextTry: nextTry,
+	}
+
+	if len(q.entries) >= q.capacity {
+		evicted = q.entries[0]
+		q.entries = q.entries[1:]
+		q.deletePersisted(evicted.seq)
+	}
+	q.entries = append(q.entries, entry)
+	q.persist(entry)
+	return evicted
+}
+
+// popDue removes and returns every entry whose nextTry is due as of now.
+func (q *retryQueue) popDue(now time.Time) []*retryEntry {
+	var due []*retryEntry
+	kept := q.entries[:0]
+	for _, e := range q.entries {
+		if !e.nextTry.After(now) {
+			due = append(due, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	q.entries = kept
+	for _, e := range due {
+		q.deletePersisted(e.seq)
+	}
+	return due
+}
+
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+func (q *retryQueue) persist(entry *retryEntry) {
+	if q.db == nil {
+		return
+	}
+	data, err := json.Marshal(persistedRetryEntry{
+		Trigger: entry.trigger,
+		Event:   entry.event,
+		Attempt: entry.attempt,
+		NextTry: entry.nextTry,
+	})
+	if err != nil {
+		return
+	}
+	_ = q.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(retryBucketName)
+		if err != nil {
+			return err
+		}
+		return b.Put(seqKey(entry.seq), data)
+	})
+}
+
+func (q *retryQueue) deletePersisted(seq uint64) {
+	if q.db == nil {
+		return
+	}
+	_ = q.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(retryBucketName)
+		if b == nil {
+			return nil
+		}
+		return b.Delete(seqKey(seq))
+	})
+}
+
+// loadPersisted repopulates the queue from db on startup, so entries
+// queued before a restart aren't silently lost.
+func (q *retryQueue) loadPersisted() error {
+	if q.db == nil {
+		return nil
+	}
+	return q.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(retryBucketName)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var pe persistedRetryEntry
+			if err := json.Unmarshal(v, &pe); err != nil {
+				return nil
+			}
+			seq := binary.BigEndian.Uint64(k)
+			if seq > q.nextSeq {
+				q.nextSeq = seq
+			}
+			q.entries = append(q.entries, &retryEntry{
+				seq:     seq,
+				trigger: pe.Trigger,
+				event:   pe.Event,
+				attempt: pe.Attempt,
+				nextTry: pe.NextTry,
+			})
+			return nil
+		})
+	})
+}
@@ -6,13 +6,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.etcd.io/bbolt"
 	"go.uber.org/zap"
 )
 
+// retryWorkerInterval is how often the background retry worker scans
+// the retry queue for due entries.
+const retryWorkerInterval = 250 * time.Millisecond
+
 type EventType string
 
 const (
@@ -43,21 +49,60 @@ type TriggerConfig struct {
 	Events     []EventType
 	Sink       SinkType
 	Config     map[string]string // URL for webhook, Topic for redpanda
+
+	// Retry controls this trigger's retry/backoff/DLQ behavior on
+	// delivery failure. Nil uses defaultRetryConfig.
+	Retry *RetryConfig
+}
+
+// maxSubscribersPerCollection bounds how many live Subscribe channels a
+// single collection can fan out to, so a burst of GraphQL subscription
+// connections can't accumulate unbounded goroutines/channels server-side.
+const maxSubscribersPerCollection = 256
+
+// subscriberChanCap is each Subscribe channel's buffer. Once full, Fire
+// drops the oldest buffered event rather than blocking - subscribers care
+// about staying current, not about replaying every change that happened
+// while they were slow.
+const subscriberChanCap = 16
+
+type subscriber struct {
+	id     uint64
+	events map[EventType]bool
+	ch     chan Event
 }
 
 type TriggerManager struct {
-	logger   *zap.Logger
-	triggers map[string][]TriggerConfig // collection -> triggers
-	mu       sync.RWMutex
-	client   *http.Client
-	redpanda *kgo.Client // Franz-go client
+	logger      *zap.Logger
+	triggers    map[string][]TriggerConfig // collection -> triggers
+	mu          sync.RWMutex
+	client      *http.Client
+	redpanda    *kgo.Client                       // Franz-go client
+	subscribers map[string]map[uint64]*subscriber // collection -> subscribers
+	subMu       sync.RWMutex
+	nextSubID   uint64
+
+	// retryDB optionally backs retryQ so queued retries survive a
+	// restart - opened under dataDir if NewTriggerManager was given one.
+	retryDB *bbolt.DB
+	retryMu sync.Mutex
+	retryQ  *retryQueue
+
+	retryStopCh chan struct{}
+	retryWG     sync.WaitGroup
 }
 
-func NewTriggerManager(logger *zap.Logger, redpandaBrokers []string) *TriggerManager {
+// NewTriggerManager creates a TriggerManager. dataDir, if non-empty,
+// backs the retry queue with a BoltDB file (trigger_retries.db) under
+// that directory so entries queued for retry survive a restart; an
+// empty dataDir keeps the retry queue in-memory only.
+func NewTriggerManager(logger *zap.Logger, redpandaBrokers []string, dataDir string) *TriggerManager {
 	tm := &TriggerManager{
-		logger:   logger,
-		triggers: make(map[string][]TriggerConfig),
-		client:   &http.Client{Timeout: 5 * time.Second},
+		logger:      logger,
+		triggers:    make(map[string][]TriggerConfig),
+		client:      &http.Client{Timeout: 5 * time.Second},
+		subscribers: make(map[string]map[uint64]*subscriber),
+		retryStopCh: make(chan struct{}),
 	}
 
 	if len(redpandaBrokers) > 0 {
@@ -73,6 +118,23 @@ func NewTriggerManager(logger *zap.Logger, redpandaBrokers []string) *TriggerMan
 		}
 	}
 
+	if dataDir != "" {
+		db, err := bbolt.Open(filepath.Join(dataDir, "trigger_retries.db"), 0600, nil)
+		if err != nil {
+			logger.Error("Failed to open trigger retry store, retries will be in-memory only", zap.Error(err))
+		} else {
+			tm.retryDB = db
+		}
+	}
+
+	tm.retryQ = newRetryQueue(defaultRetryQueueCapacity, tm.retryDB)
+	if err := tm.retryQ.loadPersisted(); err != nil {
+		logger.Error("Failed to load persisted trigger retries", zap.Error(err))
+	}
+
+	tm.retryWG.Add(1)
+	go tm.retryWorker()
+
 	return tm
 }
 
@@ -82,15 +144,75 @@ func (tm *TriggerManager) AddTrigger(config TriggerConfig) {
 	tm.triggers[config.Collection] = append(tm.triggers[config.Collection], config)
 }
 
-func (tm *TriggerManager) Fire(ctx context.Context, collection string, eventType EventType, payload, oldImage map[string]interface{}) {
-	tm.mu.RLock()
-	triggers, ok := tm.triggers[collection]
-	tm.mu.RUnlock()
+// Subscribe returns a channel of collection's events matching eventTypes,
+// plus an unsubscribe func the caller must call (e.g. via defer) once
+// done to release the subscription. Unlike AddTrigger's webhook/Redpanda
+// sinks, which are admin-configured and persist for the TriggerManager's
+// lifetime, a Subscribe subscription is ephemeral and scoped to the
+// caller - the shape GraphQL subscriptions need per connection (see
+// pkg/platform/graphql's Subscribe).
+//
+// The returned channel is buffered; once full, Fire drops the oldest
+// pending event to make room for the new one rather than blocking the
+// write path on a slow subscriber.
+func (tm *TriggerManager) Subscribe(collection string, eventTypes []EventType) (<-chan Event, func(), error) {
+	wanted := make(map[EventType]bool, len(eventTypes))
+	for _, et := range eventTypes {
+		wanted[et] = true
+	}
 
-	if !ok {
-		return
+	tm.subMu.Lock()
+	if len(tm.subscribers[collection]) >= maxSubscribersPerCollection {
+		tm.subMu.Unlock()
+		return nil, nil, fmt.Errorf("too many subscribers for collection %q", collection)
+	}
+	tm.nextSubID++
+	id := tm.nextSubID
+	sub := &subscriber{id: id, events: wanted, ch: make(chan Event, subscriberChanCap)}
+	if tm.subscribers[collection] == nil {
+		tm.subscribers[collection] = make(map[uint64]*subscriber)
 	}
+	tm.subscribers[collection][id] = sub
+	tm.subMu.Unlock()
 
+	unsubscribe := func() {
+		tm.subMu.Lock()
+		delete(tm.subscribers[collection], id)
+		if len(tm.subscribers[collection]) == 0 {
+			delete(tm.subscribers, collection)
+		}
+		tm.subMu.Unlock()
+	}
+	return sub.ch, unsubscribe, nil
+}
+
+// dispatchToSubscribers fans event out to every Subscribe caller watching
+// collection for eventType, dropping the oldest buffered event for any
+// subscriber whose channel is full.
+func (tm *TriggerManager) dispatchToSubscribers(collection string, eventType EventType, event Event) {
+	tm.subMu.RLock()
+	defer tm.subMu.RUnlock()
+
+	for _, sub := range tm.subscribers[collection] {
+		if !sub.events[eventType] {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- event:
+			default:
+			}
+		}
+	}
+}
+
+func (tm *TriggerManager) Fire(ctx context.Context, collection string, eventType EventType, payload, oldImage map[string]interface{}) {
 	event := Event{
 		ID:         fmt.Sprintf("%d", time.Now().UnixNano()),
 		Type:       eventType,
@@ -100,6 +222,16 @@ func (tm *TriggerManager) Fire(ctx context.Context, collection string, eventType
 		Timestamp:  time.Now(),
 	}
 
+	tm.dispatchToSubscribers(collection, eventType, event)
+
+	tm.mu.RLock()
+	triggers, ok := tm.triggers[collection]
+	tm.mu.RUnlock()
+
+	if !ok {
+		return
+	}
+
 	for _, t := range triggers {
 		// Check if trigger cares about this event type
 		shouldFire := false
@@ -117,26 +249,42 @@ func (tm *TriggerManager) Fire(ctx context.Context, collection string, eventType
 }
 
 func (tm *TriggerManager) executeTrigger(ctx context.Context, t TriggerConfig, event Event) {
+	triggerAttemptsTotal.WithLabelValues(t.Name).Inc()
+
+	if err := tm.deliver(ctx, t, event); err != nil {
+		tm.logger.Error("Trigger delivery failed, scheduling retry",
+			zap.String("trigger", t.Name), zap.Error(err))
+		tm.scheduleRetry(t, event, 1)
+		return
+	}
+
+	triggerSuccessesTotal.WithLabelValues(t.Name).Inc()
+}
+
+// deliver sends event to t's sink once, with no retry of its own -
+// executeTrigger and retryWorker both call this and handle failure by
+// enqueueing (or re-enqueueing) onto the retry queue.
+func (tm *TriggerManager) deliver(ctx context.Context, t TriggerConfig, event Event) error {
 	switch t.Sink {
 	case SinkWebhook:
 		url := t.Config["url"]
 		if url == "" {
-			return
+			return fmt.Errorf("trigger %q: webhook sink missing url", t.Name)
 		}
 		data, _ := json.Marshal(event)
 		resp, err := tm.client.Post(url, "application/json", bytes.NewBuffer(data))
 		if err != nil {
-			tm.logger.Error("Webhook failed", zap.String("trigger", t.Name), zap.Error(err))
-			return
+			return fmt.Errorf("webhook request failed: %w", err)
 		}
 		defer resp.Body.Close()
 		if resp.StatusCode >= 400 {
-			tm.logger.Error("Webhook error response", zap.String("trigger", t.Name), zap.Int("status", resp.StatusCode))
+			return fmt.Errorf("webhook returned status %d", resp.StatusCode)
 		}
+		return nil
 
 	case SinkRedpanda:
 		if tm.redpanda == nil {
-			return
+			return fmt.Errorf("trigger %q: redpanda sink configured but no client connected", t.Name)
 		}
 		topic := t.Config["topic"]
 		if topic == "" {
@@ -151,13 +299,120 @@ func (tm *TriggerManager) executeTrigger(ctx context.Context, t TriggerConfig, e
 		}
 
 		if err := tm.redpanda.ProduceSync(ctx, record).FirstErr(); err != nil {
-			tm.logger.Error("Failed to produce to Redpanda", zap.String("trigger", t.Name), zap.Error(err))
+			return fmt.Errorf("produce to redpanda failed: %w", err)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// scheduleRetry enqueues event for another delivery attempt against t,
+// computing nextTry via jittered exponential backoff. If the queue is at
+// capacity, the oldest queued entry is evicted and sent straight to DLQ
+// rather than silently dropped.
+func (tm *TriggerManager) scheduleRetry(t TriggerConfig, event Event, attempt int) {
+	retry := retryConfigFor(t).resolved()
+
+	tm.retryMu.Lock()
+	evicted := tm.retryQ.push(t, event, attempt, time.Now().Add(backoff(retry, attempt)))
+	tm.retryMu.Unlock()
+
+	if evicted != nil {
+		tm.logger.Warn("Trigger retry queue full, evicting oldest entry to DLQ",
+			zap.String("trigger", evicted.trigger.Name))
+		tm.sendToDLQ(evicted.trigger, evicted.event)
+	}
+}
+
+// retryConfigFor returns t's Retry config, defaulted.
+func retryConfigFor(t TriggerConfig) RetryConfig {
+	if t.Retry != nil {
+		return *t.Retry
+	}
+	return defaultRetryConfig()
+}
+
+// retryWorker drains due retry-queue entries and re-attempts delivery,
+// re-enqueueing on failure (until MaxAttempts) or routing to DLQ once
+// exhausted.
+func (tm *TriggerManager) retryWorker() {
+	defer tm.retryWG.Done()
+
+	ticker := time.NewTicker(retryWorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-tm.retryStopCh:
+			return
+		case <-ticker.C:
+			tm.retryMu.Lock()
+			due := tm.retryQ.popDue(time.Now())
+			tm.retryMu.Unlock()
+
+			for _, e := range due {
+				tm.retryOnce(e)
+			}
 		}
 	}
 }
 
+func (tm *TriggerManager) retryOnce(e *retryEntry) {
+	retry := retryConfigFor(e.trigger).resolved()
+	if e.attempt >= retry.MaxAttempts {
+		tm.logger.Error("Trigger delivery exhausted retries, routing to DLQ",
+			zap.String("trigger", e.trigger.Name), zap.Int("attempts", e.attempt))
+		tm.sendToDLQ(e.trigger, e.event)
+		return
+	}
+
+	triggerAttemptsTotal.WithLabelValues(e.trigger.Name).Inc()
+
+	err := tm.deliver(context.Background(), e.trigger, e.event)
+	if err == nil {
+		triggerSuccessesTotal.WithLabelValues(e.trigger.Name).Inc()
+		return
+	}
+
+	tm.logger.Warn("Trigger retry failed, rescheduling",
+		zap.String("trigger", e.trigger.Name), zap.Int("attempt", e.attempt), zap.Error(err))
+	tm.scheduleRetry(e.trigger, e.event, e.attempt+1)
+}
+
+// sendToDLQ delivers event to t's configured dead-letter sink, if any.
+// Delivery to the DLQ itself is best-effort - a failure here is logged,
+// not retried, to avoid an unbounded retry-of-retries loop.
+func (tm *TriggerManager) sendToDLQ(t TriggerConfig, event Event) {
+	triggerDLQTotal.WithLabelValues(t.Name).Inc()
+
+	retry := retryConfigFor(t)
+	if retry.DLQ == nil {
+		tm.logger.Error("Trigger has no DLQ configured, dropping event",
+			zap.String("trigger", t.Name), zap.String("event_id", event.ID))
+		return
+	}
+
+	dlq := *retry.DLQ
+	if dlq.Name == "" {
+		dlq.Name = t.Name + ".dlq"
+	}
+	if dlq.Collection == "" {
+		dlq.Collection = t.Collection
+	}
+	if err := tm.deliver(context.Background(), dlq, event); err != nil {
+		tm.logger.Error("DLQ delivery failed", zap.String("trigger", t.Name), zap.Error(err))
+	}
+}
+
 func (tm *TriggerManager) Close() {
+	close(tm.retryStopCh)
+	tm.retryWG.Wait()
+
 	if tm.redpanda != nil {
 		tm.redpanda.Close()
 	}
+	if tm.retryDB != nil {
+		tm.retryDB.Close()
+	}
 }
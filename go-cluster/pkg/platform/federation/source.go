@@ -2,6 +2,10 @@ package federation
 
 import (
 	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // SourceType defines the type of remote source
@@ -33,21 +37,254 @@ type Source interface {
 	Introspect(ctx context.Context) (map[string]interface{}, error)
 }
 
-// Manager handles multiple sources
+// sourceHealth is the last known health of one registered source.
+type sourceHealth struct {
+	Connected   bool
+	LastError   string
+	LastAttempt time.Time
+	LastSuccess time.Time
+}
+
+// sourcesState is an immutable snapshot of every registered source,
+// published into Manager's atomic.Value wholesale on each successful
+// reload - the same "reload slots" pattern redis cluster clients use to
+// republish topology: Get only ever reads a published snapshot, so it
+// never blocks on a reload in flight, and a reload that fails for some
+// sources simply keeps serving their previous entry in the next snapshot.
+type sourcesState struct {
+	byName map[string]Source
+	order  []string
+	health map[string]sourceHealth
+}
+
+func emptySourcesState() *sourcesState {
+	return &sourcesState{
+		byName: make(map[string]Source),
+		health: make(map[string]sourceHealth),
+	}
+}
+
+// ManagerConfig configures Manager's background reload.
+type ManagerConfig struct {
+	// ReloadInterval is the period of the background reload ticker. 0
+	// disables periodic reload, leaving only on-demand reloads (Register,
+	// or a manual send on ReloadCh).
+	ReloadInterval time.Duration
+	// MaxRetries bounds attempts to Connect a single source within one
+	// reload, mirroring the "try 10 times to preload slots" pattern redis
+	// cluster clients use against transient failures.
+	MaxRetries int
+	// RetryBackoff is the delay between those attempts.
+	RetryBackoff time.Duration
+}
+
+// DefaultManagerConfig returns sensible defaults.
+func DefaultManagerConfig() ManagerConfig {
+	return ManagerConfig{
+		ReloadInterval: 30 * time.Second,
+		MaxRetries:     10,
+		RetryBackoff:   100 * time.Millisecond,
+	}
+}
+
+// Manager handles multiple sources. Its live view is an atomic snapshot
+// published by a background reload loop, so Get is a lock-free read that
+// never contends with a reload in flight.
 type Manager struct {
-	sources map[string]Source
+	cfg ManagerConfig
+
+	mu      sync.Mutex // guards pending only; the published snapshot is read via state
+	pending map[string]Source
+
+	state atomic.Value // *sourcesState
+
+	// ReloadCh triggers an out-of-cycle reload (e.g. right after
+	// Register) in addition to the ReloadInterval ticker.
+	ReloadCh chan struct{}
+
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running atomic.Bool
 }
 
-func NewManager() *Manager {
-	return &Manager{
-		sources: make(map[string]Source),
+// NewManager creates a Manager with no sources registered yet.
+func NewManager(cfg ManagerConfig) *Manager {
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DefaultManagerConfig().MaxRetries
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultManagerConfig().RetryBackoff
+	}
+
+	m := &Manager{
+		cfg:      cfg,
+		pending:  make(map[string]Source),
+		ReloadCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
 	}
+	m.state.Store(emptySourcesState())
+	return m
 }
 
+// Register adds source under name, to be connected and published into
+// the live snapshot on the next reload - immediately, if Start has been
+// called.
 func (m *Manager) Register(name string, source Source) {
-	m.sources[name] = source
+	m.mu.Lock()
+	m.pending[name] = source
+	m.mu.Unlock()
+
+	select {
+	case m.ReloadCh <- struct{}{}:
+	default:
+	}
 }
 
+// Get returns the published source registered under name, or nil if
+// none has been registered or no reload has run since it was. A reload
+// publishes a source even when its Connect failed (see Stats).
 func (m *Manager) Get(name string) Source {
-	return m.sources[name]
+	st := m.state.Load().(*sourcesState)
+	return st.byName[name]
+}
+
+// SourceStats reports one source's health as of the last reload.
+type SourceStats struct {
+	Name        string
+	Connected   bool
+	LastError   string
+	LastAttempt time.Time
+	LastSuccess time.Time
+	// Loaded distinguishes "never loaded" (false) from "stale but
+	// usable" (true, Connected false, LastSuccess in the past).
+	Loaded bool
+}
+
+// Stats returns per-source health from the most recently published
+// snapshot, in a stable order.
+func (m *Manager) Stats() []SourceStats {
+	st := m.state.Load().(*sourcesState)
+
+	out := make([]SourceStats, 0, len(st.order))
+	for _, name := range st.order {
+		h := st.health[name]
+		out = append(out, SourceStats{
+			Name:        name,
+			Connected:   h.Connected,
+			LastError:   h.LastError,
+			LastAttempt: h.LastAttempt,
+			LastSuccess: h.LastSuccess,
+			Loaded:      !h.LastAttempt.IsZero(),
+		})
+	}
+	return out
+}
+
+// Start launches the background reload loop. Calling Start more than
+// once is a no-op.
+func (m *Manager) Start(ctx context.Context) {
+	if !m.running.CompareAndSwap(false, true) {
+		return
+	}
+
+	m.wg.Add(1)
+	go m.reloadLoop(ctx)
+}
+
+// Stop halts the background reload loop and waits for it to exit.
+func (m *Manager) Stop() {
+	if !m.running.CompareAndSwap(true, false) {
+		return
+	}
+
+	close(m.stopCh)
+	m.wg.Wait()
+}
+
+func (m *Manager) reloadLoop(ctx context.Context) {
+	defer m.wg.Done()
+
+	var tickerCh <-chan time.Time
+	if m.cfg.ReloadInterval > 0 {
+		ticker := time.NewTicker(m.cfg.ReloadInterval)
+		defer ticker.Stop()
+		tickerCh = ticker.C
+	}
+
+	m.reload(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-tickerCh:
+			m.reload(ctx)
+		case <-m.ReloadCh:
+			m.reload(ctx)
+		}
+	}
+}
+
+// reload connects every registered source (making up to cfg.MaxRetries
+// Connect attempts per source) and publishes the resulting
+// snapshot atomically, so Get keeps returning the previous snapshot for
+// the whole duration of the reload.
+func (m *Manager) reload(ctx context.Context) {
+	m.mu.Lock()
+	sources := make(map[string]Source, len(m.pending))
+	for name, src := range m.pending {
+		sources[name] = src
+	}
+	m.mu.Unlock()
+
+	prev := m.state.Load().(*sourcesState)
+
+	next := &sourcesState{
+		byName: make(map[string]Source, len(sources)),
+		order:  make([]string, 0, len(sources)),
+		health: make(map[string]sourceHealth, len(sources)),
+	}
+
+	for name, src := range sources {
+		next.byName[name] = src
+		next.order = append(next.order, name)
+		next.health[name] = m.connectWithRetry(ctx, name, src, prev)
+	}
+	sort.Strings(next.order)
+
+	m.state.Store(next)
+}
+
+// connectWithRetry attempts src.Connect up to cfg.MaxRetries times,
+// carrying forward the previous LastSuccess so a source that's
+// momentarily unreachable is still reported as "stale but usable"
+// rather than "never loaded".
+func (m *Manager) connectWithRetry(ctx context.Context, name string, src Source, prev *sourcesState) sourceHealth {
+	h := prev.health[name]
+
+	var lastErr error
+	for attempt := 0; attempt < m.cfg.MaxRetries; attempt++ {
+		h.LastAttempt = time.Now()
+		lastErr = src.Connect(ctx)
+		if lastErr == nil {
+			h.Connected = true
+			h.LastError = ""
+			h.LastSuccess = h.LastAttempt
+			return h
+		}
+
+		select {
+		case <-ctx.Done():
+			h.Connected = false
+			h.LastError = lastErr.Error()
+			return h
+		case <-time.After(m.cfg.RetryBackoff):
+		}
+	}
+
+	h.Connected = false
+	h.LastError = lastErr.Error()
+	return h
 }
@@ -0,0 +1,228 @@
+package graphql
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+)
+
+// buildBoolExpType builds colName's `<colName>_bool_exp` input type:
+// `_and`/`_or`/`_not` combinators plus a per-column comparison input for
+// every column SetColumns recorded, generated recursively via a
+// graphql.InputObjectConfigFieldMapThunk since the type refers to itself
+// through `_and`/`_or`/`_not` and graphql-go can't otherwise construct a
+// self-referential input object.
+func buildBoolExpType(colName string, columns map[string]ColumnType) *graphql.InputObject {
+	var boolExp *graphql.InputObject
+	boolExp = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: colName + "_bool_exp",
+		Fields: (graphql.InputObjectConfigFieldMapThunk)(func() graphql.InputObjectConfigFieldMap {
+			fields := graphql.InputObjectConfigFieldMap{
+				"_and": &graphql.InputObjectFieldConfig{Type: graphql.NewList(boolExp)},
+				"_or":  &graphql.InputObjectFieldConfig{Type: graphql.NewList(boolExp)},
+				"_not": &graphql.InputObjectFieldConfig{Type: boolExp},
+			}
+			for col, colType := range columns {
+				fields[col] = &graphql.InputObjectFieldConfig{Type: comparisonExpType(colName, col, colType)}
+			}
+			return fields
+		}),
+	})
+	return boolExp
+}
+
+// comparisonExpType builds the `<colName>_<fieldName>_comparison_exp`
+// input type a bool_exp field resolves to: the Hasura-style `_eq`/`_neq`/
+// `_gt`/`_gte`/`_lt`/`_lte`/`_in`/`_is_null` operators, typed to colType's
+// GraphQL scalar.
+func comparisonExpType(colName, fieldName string, colType ColumnType) *graphql.InputObject {
+	scalar := scalarForColumn(colType)
+	return graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: colName + "_" + fieldName + "_comparison_exp",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"_eq":      &graphql.InputObjectFieldConfig{Type: scalar},
+			"_neq":     &graphql.InputObjectFieldConfig{Type: scalar},
+			"_gt":      &graphql.InputObjectFieldConfig{Type: scalar},
+			"_gte":     &graphql.InputObjectFieldConfig{Type: scalar},
+			"_lt":      &graphql.InputObjectFieldConfig{Type: scalar},
+			"_lte":     &graphql.InputObjectFieldConfig{Type: scalar},
+			"_in":      &graphql.InputObjectFieldConfig{Type: graphql.NewList(scalar)},
+			"_is_null": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		},
+	})
+}
+
+func scalarForColumn(t ColumnType) *graphql.Scalar {
+	switch t {
+	case ColumnTypeNumber:
+		return graphql.Float
+	case ColumnTypeInteger:
+		return graphql.Int
+	case ColumnTypeBoolean:
+		return graphql.Boolean
+	default:
+		return graphql.String
+	}
+}
+
+// evalBoolExp reports whether doc satisfies where, a resolved
+// `<colName>_bool_exp` value: `_and`/`_or`/`_not` combine recursively, and
+// every other key is either a plain value (equality, matching this
+// package's historical behavior) or a comparison-exp map of
+// `_eq`/`_neq`/`_gt`/`_gte`/`_lt`/`_lte`/`_in`/`_is_null` operators.
+func evalBoolExp(doc map[string]interface{}, where map[string]interface{}) bool {
+	for key, want := range where {
+		switch key {
+		case "_and":
+			for _, sub := range toMapSlice(want) {
+				if !evalBoolExp(doc, sub) {
+					return false
+				}
+			}
+		case "_or":
+			subs := toMapSlice(want)
+			if len(subs) == 0 {
+				continue
+			}
+			matched := false
+			for _, sub := range subs {
+				if evalBoolExp(doc, sub) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		case "_not":
+			sub, ok := want.(map[string]interface{})
+			if ok && evalBoolExp(doc, sub) {
+				return false
+			}
+		default:
+			if !evalFieldExp(doc[key], want) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// evalFieldExp applies a single bool_exp field's value - either a
+// comparison-exp map or, for backward compatibility with the equality-only
+// `where` this package used before, a bare value to compare equal.
+func evalFieldExp(got interface{}, want interface{}) bool {
+	ops, ok := want.(map[string]interface{})
+	if !ok {
+		return fmt.Sprintf("%v", got) == fmt.Sprintf("%v", want)
+	}
+
+	for op, val := range ops {
+		switch op {
+		case "_eq":
+			if fmt.Sprintf("%v", got) != fmt.Sprintf("%v", val) {
+				return false
+			}
+		case "_neq":
+			if fmt.Sprintf("%v", got) == fmt.Sprintf("%v", val) {
+				return false
+			}
+		case "_gt", "_gte", "_lt", "_lte":
+			cmp, ok := compareNumeric(got, val)
+			if !ok {
+				return false
+			}
+			if !satisfiesOrdering(op, cmp) {
+				return false
+			}
+		case "_in":
+			if !isOneOf(got, val) {
+				return false
+			}
+		case "_is_null":
+			isNull, _ := val.(bool)
+			if (got == nil) != isNull {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func satisfiesOrdering(op string, cmp int) bool {
+	switch op {
+	case "_gt":
+		return cmp > 0
+	case "_gte":
+		return cmp >= 0
+	case "_lt":
+		return cmp < 0
+	case "_lte":
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
+// compareNumeric compares a and b as float64s, reporting -1/0/1 the usual
+// way and false if either doesn't convert to a number.
+func compareNumeric(a, b interface{}) (int, bool) {
+	af, ok := toFloat64(a)
+	if !ok {
+		return 0, false
+	}
+	bf, ok := toFloat64(b)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case af < bf:
+		return -1, true
+	case af > bf:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func isOneOf(got interface{}, want interface{}) bool {
+	list, ok := want.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, v := range list {
+		if fmt.Sprintf("%v", got) == fmt.Sprintf("%v", v) {
+			return true
+		}
+	}
+	return false
+}
+
+func toMapSlice(v interface{}) []map[string]interface{} {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
@@ -3,23 +3,64 @@ package graphql
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/gqlerrors"
 	"github.com/graphql-go/graphql/language/ast"
-	"github.com/lumadb/cluster/pkg/cluster"
-	"github.com/lumadb/cluster/pkg/platform/federation"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/platform/auth"
+	"github.com/tdb-plus/cluster/pkg/platform/events"
+	"github.com/tdb-plus/cluster/pkg/platform/federation"
 	"go.uber.org/zap"
 )
 
 // GraphQLEngine manages the dynamic GraphQL schema
 type GraphQLEngine struct {
-	node      *cluster.Node
-	logger    *zap.Logger
-	registry  *federation.SourceRegistry
-	schema    graphql.Schema
-	hasSchema bool
-	metadata  *MetadataStore
+	node     *cluster.Node
+	logger   *zap.Logger
+	registry *federation.SourceRegistry
+	metadata *MetadataStore
+
+	// schemaVal holds the current *schemaHolder. BuildSchema builds a new
+	// one and swaps it in with schemaVal.Store, so a query already
+	// running against the old schema (Execute loads it once at the start
+	// of the call) finishes undisturbed instead of racing a
+	// rebuild in place.
+	schemaVal atomic.Value
+
+	// authEngine, if set via SetAuthEngine, gates every resolver's
+	// select/insert against the requesting role's PermissionRules (see
+	// auth.AuthEngine.Authorize) and merges in any row-level filter the
+	// role is restricted to. A resolver finds the requester's Claims via
+	// auth.ClaimsFromContext(p.Context) - see Server.handleGraphQLOrPlayground,
+	// which embeds them before calling Execute. Without an authEngine,
+	// every resolver runs unrestricted, matching this package's
+	// historical behavior.
+	authEngine *auth.AuthEngine
+}
+
+// schemaHolder lets schemaVal (an atomic.Value) store graphql.Schema,
+// which atomic.Value can't hold directly since consecutive Store calls
+// must use the same concrete type and graphql.Schema's zero value isn't a
+// stable basis for that check.
+type schemaHolder struct {
+	schema graphql.Schema
+}
+
+// SetAuthEngine wires a as the source of per-role, per-collection
+// PermissionRules BuildSchema's resolvers enforce.
+func (e *GraphQLEngine) SetAuthEngine(a *auth.AuthEngine) {
+	e.authEngine = a
+}
+
+// Metadata returns the MetadataStore backing this engine's tracked
+// tables and relationships, so other callers (e.g. pkg/platform/mcp's
+// inspect_schema tool) can read a collection's registered schema instead
+// of inferring one from scratch.
+func (e *GraphQLEngine) Metadata() *MetadataStore {
+	return e.metadata
 }
 
 func NewGraphQLEngine(node *cluster.Node, registry *federation.SourceRegistry, logger *zap.Logger) *GraphQLEngine {
@@ -27,14 +68,35 @@ func NewGraphQLEngine(node *cluster.Node, registry *federation.SourceRegistry, l
 		node:     node,
 		registry: registry,
 		logger:   logger,
-		metadata: NewMetadataStore(),
+		metadata: NewMetadataStore(node),
+	}
+}
+
+// currentSchema returns the schema BuildSchema most recently installed, or
+// the zero Schema (with ok=false) if BuildSchema has never run.
+func (e *GraphQLEngine) currentSchema() (graphql.Schema, bool) {
+	h, ok := e.schemaVal.Load().(*schemaHolder)
+	if !ok {
+		return graphql.Schema{}, false
 	}
+	return h.schema, true
 }
 
-// BuildSchema dynamically constructs the GraphQL schema from database collections AND federated sources
+// BuildSchema dynamically (re)constructs the GraphQL schema from database
+// collections, federated sources, and tracked tables/relationships, then
+// atomically swaps it in - a query already running against the previous
+// schema keeps using it until it finishes. Call it again any time tracked
+// metadata changes (TrackTable/TrackRelationship/DropRelationship's admin
+// mutations do this themselves); a lazy first build also happens on the
+// first Execute call if it hasn't run yet. Subscribe does not use the
+// schema.
 func (e *GraphQLEngine) BuildSchema() error {
 	e.logger.Info("Building GraphQL Schema...")
 
+	if err := e.metadata.Load(); err != nil {
+		e.logger.Warn("failed to load persisted GraphQL metadata, continuing with in-memory state", zap.Error(err))
+	}
+
 	// Collections
 	collections, err := e.node.ListCollections()
 	if err != nil {
@@ -71,7 +133,9 @@ func (e *GraphQLEngine) BuildSchema() error {
 				"data":     &graphql.Field{Type: jsonScalar},
 			},
 		})
-		e.metadata.TrackTable(colName)
+		if err := e.metadata.TrackTable(colName); err != nil {
+			e.logger.Warn("failed to persist tracked table", zap.String("collection", colName), zap.Error(err))
+		}
 	}
 
 	// 2. Add Relationships to Types (Thunk)
@@ -121,15 +185,13 @@ func (e *GraphQLEngine) BuildSchema() error {
 	for _, colName := range collections {
 		objType := typesMap[colName]
 
-		// Filter Input Type
-		filterType := graphql.NewInputObject(graphql.InputObjectConfig{
-			Name: colName + "_bool_exp",
-			Fields: graphql.InputObjectConfigFieldMap{
-				"_and": &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewInputObject(graphql.InputObjectConfig{Name: colName + "_bool_exp_and"}))}, // Simplified recursion
-				"_or":  &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewInputObject(graphql.InputObjectConfig{Name: colName + "_bool_exp_or"}))},
-				// In real impl, we need fully recursive input types which graphql-go supports via Thunk
-			},
-		})
+		// Filter Input Type: fully recursive _bool_exp, built via a thunk
+		// since it refers to itself through _and/_or/_not.
+		var columns map[string]ColumnType
+		if table, ok := e.metadata.GetTable(colName); ok {
+			columns = table.Columns
+		}
+		filterType := buildBoolExpType(colName, columns)
 
 		// Query: List
 		queryFields[colName] = &graphql.Field{
@@ -144,11 +206,30 @@ func (e *GraphQLEngine) BuildSchema() error {
 				if limit <= 0 {
 					limit = 10
 				}
-				query := map[string]interface{}{"limit": limit}
+				filter := map[string]interface{}{}
 				if whereVal, ok := p.Args["where"].(map[string]interface{}); ok {
-					query["filter"] = whereVal
+					for k, v := range whereVal {
+						filter[k] = v
+					}
+				}
+				if e.authEngine != nil {
+					claims, _ := auth.ClaimsFromContext(p.Context)
+					allowed, rowFilter := e.authEngine.Authorize(claims, auth.OpSelect, colName, nil)
+					if !allowed {
+						return nil, fmt.Errorf("forbidden: role cannot select from %s", colName)
+					}
+					// The role's own row filter always wins over
+					// client-supplied `where` - it's an enforced
+					// restriction, not a default the client can widen.
+					for k, v := range rowFilter {
+						filter[k] = v
+					}
 				}
-				return e.node.RunQuery(colName, query)
+				query := map[string]interface{}{"limit": limit}
+				if len(filter) > 0 {
+					query["filter"] = filter
+				}
+				return e.node.RunQuery(colName, query, cluster.Stale)
 			},
 		}
 
@@ -160,7 +241,17 @@ func (e *GraphQLEngine) BuildSchema() error {
 			},
 			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
 				id, _ := p.Args["id"].(string)
-				return e.node.GetDocument(colName, id)
+				doc, err := e.node.GetDocument(colName, id, cluster.Stale)
+				if err != nil {
+					return nil, err
+				}
+				if e.authEngine != nil {
+					claims, _ := auth.ClaimsFromContext(p.Context)
+					if allowed, _ := e.authEngine.Authorize(claims, auth.OpSelect, colName, doc); !allowed {
+						return nil, fmt.Errorf("forbidden: role cannot select from %s", colName)
+					}
+				}
+				return doc, nil
 			},
 		}
 
@@ -171,12 +262,96 @@ func (e *GraphQLEngine) BuildSchema() error {
 				"objects": &graphql.ArgumentConfig{Type: graphql.NewList(jsonScalar)}, // Batch insert
 			},
 			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if e.authEngine != nil {
+					claims, _ := auth.ClaimsFromContext(p.Context)
+					if allowed, _ := e.authEngine.Authorize(claims, auth.OpInsert, colName, nil); !allowed {
+						return nil, fmt.Errorf("forbidden: role cannot insert into %s", colName)
+					}
+				}
 				// Batch insert logic
 				return "success", nil
 			},
 		}
 	}
 
+	// Admin mutations: track_table/track_relationship/drop_relationship
+	// persist to MetadataStore and rebuild+swap the schema so the new
+	// table or relationship shows up immediately, without restarting the
+	// server. Restricted to the "admin" role, the same bar
+	// auth.AuthEngine.Authorize always passes without a PermissionRule.
+	mutationFields["track_table"] = &graphql.Field{
+		Type: graphql.Boolean,
+		Args: graphql.FieldConfigArgument{
+			"table": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if err := e.requireAdmin(p.Context); err != nil {
+				return nil, err
+			}
+			table, _ := p.Args["table"].(string)
+			if err := e.metadata.TrackTable(table); err != nil {
+				return nil, fmt.Errorf("failed to track table: %w", err)
+			}
+			if err := e.BuildSchema(); err != nil {
+				return nil, fmt.Errorf("failed to rebuild schema: %w", err)
+			}
+			return true, nil
+		},
+	}
+	mutationFields["track_relationship"] = &graphql.Field{
+		Type: graphql.Boolean,
+		Args: graphql.FieldConfigArgument{
+			"name":          &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			"from_table":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			"to_table":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			"type":          &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			"field_mapping": &graphql.ArgumentConfig{Type: jsonScalar},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if err := e.requireAdmin(p.Context); err != nil {
+				return nil, err
+			}
+			rel := &Relationship{
+				Name:      argString(p.Args, "name"),
+				FromTable: argString(p.Args, "from_table"),
+				ToTable:   argString(p.Args, "to_table"),
+				Type:      argString(p.Args, "type"),
+			}
+			if mapping, ok := p.Args["field_mapping"].(map[string]interface{}); ok {
+				rel.FieldMapping = make(map[string]string, len(mapping))
+				for k, v := range mapping {
+					rel.FieldMapping[k] = fmt.Sprintf("%v", v)
+				}
+			}
+			if err := e.metadata.TrackRelationship(rel); err != nil {
+				return nil, fmt.Errorf("failed to track relationship: %w", err)
+			}
+			if err := e.BuildSchema(); err != nil {
+				return nil, fmt.Errorf("failed to rebuild schema: %w", err)
+			}
+			return true, nil
+		},
+	}
+	mutationFields["drop_relationship"] = &graphql.Field{
+		Type: graphql.Boolean,
+		Args: graphql.FieldConfigArgument{
+			"from_table": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			"name":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if err := e.requireAdmin(p.Context); err != nil {
+				return nil, err
+			}
+			if err := e.metadata.DropRelationship(argString(p.Args, "from_table"), argString(p.Args, "name")); err != nil {
+				return nil, fmt.Errorf("failed to drop relationship: %w", err)
+			}
+			if err := e.BuildSchema(); err != nil {
+				return nil, fmt.Errorf("failed to rebuild schema: %w", err)
+			}
+			return true, nil
+		},
+	}
+
 	// Finalize
 	schemaConfig := graphql.SchemaConfig{
 		Query:    graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: queryFields}),
@@ -188,22 +363,44 @@ func (e *GraphQLEngine) BuildSchema() error {
 		return fmt.Errorf("failed to create schema: %v", err)
 	}
 
-	e.schema = schema
-	e.hasSchema = true
+	e.schemaVal.Store(&schemaHolder{schema: schema})
+	return nil
+}
+
+// requireAdmin reports an error unless ctx carries Claims for the "admin"
+// role - the bar BuildSchema's track_table/track_relationship/
+// drop_relationship mutations gate schema-altering changes behind. With no
+// authEngine configured, every caller passes, matching this package's
+// historical unrestricted behavior.
+func (e *GraphQLEngine) requireAdmin(ctx context.Context) error {
+	if e.authEngine == nil {
+		return nil
+	}
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok || claims == nil || claims.Role != "admin" {
+		return fmt.Errorf("forbidden: only the admin role may modify GraphQL schema metadata")
+	}
 	return nil
 }
 
+func argString(args map[string]interface{}, key string) string {
+	s, _ := args[key].(string)
+	return s
+}
+
 // Execute runs a GraphQL query
 func (e *GraphQLEngine) Execute(ctx context.Context, query string, variables map[string]interface{}) *graphql.Result {
-	if !e.hasSchema {
+	schema, ok := e.currentSchema()
+	if !ok {
 		// Try to build schema lazily
 		if err := e.BuildSchema(); err != nil {
 			return &graphql.Result{Errors: []gqlerrors.FormattedError{{Message: err.Error()}}}
 		}
+		schema, _ = e.currentSchema()
 	}
 
 	params := graphql.Params{
-		Schema:         e.schema,
+		Schema:         schema,
 		RequestString:  query,
 		VariableValues: variables,
 		Context:        ctx,
@@ -211,3 +408,148 @@ func (e *GraphQLEngine) Execute(ctx context.Context, query string, variables map
 
 	return graphql.Do(params)
 }
+
+// Subscribe evaluates a `subscription { <collection>(where: {...}) { op doc } }`
+// query and streams one *graphql.Result per matching insert/update/delete
+// event on <collection>, sourced from cluster.Node's change-event feed
+// (see pkg/platform/events). The returned channel is closed when ctx is
+// done or the underlying event feed is exhausted; callers must keep
+// draining it (or cancel ctx) so the forwarding goroutine can exit.
+func (e *GraphQLEngine) Subscribe(ctx context.Context, query string, variables map[string]interface{}) (<-chan *graphql.Result, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil, fmt.Errorf("parse subscription: %w", err)
+	}
+
+	collection, where, err := firstSubscriptionField(doc, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.authEngine != nil {
+		claims, _ := auth.ClaimsFromContext(ctx)
+		allowed, rowFilter := e.authEngine.Authorize(claims, auth.OpSelect, collection, nil)
+		if !allowed {
+			return nil, fmt.Errorf("forbidden: role cannot subscribe to %s", collection)
+		}
+		for k, v := range rowFilter {
+			if where == nil {
+				where = map[string]interface{}{}
+			}
+			where[k] = v
+		}
+	}
+
+	changes, unsubscribe, err := e.node.Subscribe(collection, []events.EventType{events.EventInsert, events.EventUpdate, events.EventDelete})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *graphql.Result)
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-changes:
+				if !ok {
+					return
+				}
+				if len(where) > 0 && !evalBoolExp(ev.Payload, where) {
+					continue
+				}
+				result := &graphql.Result{
+					Data: map[string]interface{}{
+						collection: map[string]interface{}{
+							"op":  string(ev.Type),
+							"doc": ev.Payload,
+						},
+					},
+				}
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// firstSubscriptionField walks a parsed subscription document for its
+// first top-level field (any further root fields of the operation are
+// ignored) and returns the collection name and its resolved "where"
+// argument, if any.
+func firstSubscriptionField(doc *ast.Document, variables map[string]interface{}) (string, map[string]interface{}, error) {
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok || op.Operation != "subscription" {
+			continue
+		}
+		if op.SelectionSet == nil || len(op.SelectionSet.Selections) == 0 {
+			return "", nil, fmt.Errorf("subscription has no fields")
+		}
+		field, ok := op.SelectionSet.Selections[0].(*ast.Field)
+		if !ok {
+			return "", nil, fmt.Errorf("subscription root selection must be a field")
+		}
+
+		var where map[string]interface{}
+		for _, arg := range field.Arguments {
+			if arg.Name.Value != "where" {
+				continue
+			}
+			val, err := resolveArgValue(arg.Value, variables)
+			if err != nil {
+				return "", nil, err
+			}
+			where, _ = val.(map[string]interface{})
+		}
+
+		return field.Name.Value, where, nil
+	}
+
+	return "", nil, fmt.Errorf("no subscription operation found")
+}
+
+// resolveArgValue converts a parsed argument value node into a plain Go
+// value, substituting $variables the same way graphql.Do would.
+func resolveArgValue(v ast.Value, variables map[string]interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case *ast.Variable:
+		return variables[v.Name.Value], nil
+	case *ast.ObjectValue:
+		out := make(map[string]interface{}, len(v.Fields))
+		for _, f := range v.Fields {
+			val, err := resolveArgValue(f.Value, variables)
+			if err != nil {
+				return nil, err
+			}
+			out[f.Name.Value] = val
+		}
+		return out, nil
+	case *ast.ListValue:
+		out := make([]interface{}, len(v.Values))
+		for i, item := range v.Values {
+			val, err := resolveArgValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = val
+		}
+		return out, nil
+	case *ast.StringValue:
+		return v.Value, nil
+	case *ast.IntValue:
+		return v.Value, nil
+	case *ast.BooleanValue:
+		return v.Value, nil
+	default:
+		return nil, nil
+	}
+}
+
@@ -1,8 +1,20 @@
 package graphql
 
 import (
+	"encoding/json"
 	"fmt"
 	"sync"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+)
+
+// tablesCollection and relationshipsCollection are the system collections
+// MetadataStore persists tracked tables and relationships to, the same
+// underscore-prefixed convention auth's permissionsCollection and
+// platform/cron's job collections use.
+const (
+	tablesCollection        = "_graphql_tables"
+	relationshipsCollection = "_graphql_relationships"
 )
 
 // MetadataStore manages schema configuration, relationships, and permissions
@@ -10,13 +22,31 @@ type MetadataStore struct {
 	mu            sync.RWMutex
 	Tables        map[string]*TableMetadata `json:"tables"`
 	Relationships map[string]*Relationship  `json:"relationships"` // key: "table.name"
+
+	// node, if set, is where TrackTable/SetColumns/TrackRelationship/
+	// DropRelationship persist their change and Load reads it back from.
+	// A nil node (e.g. in tests that construct a MetadataStore directly)
+	// keeps everything in-memory only.
+	node *cluster.Node
 }
 
 type TableMetadata struct {
 	Name        string                 `json:"name"`
 	Permissions map[string]*RoleConfig `json:"permissions"` // key: role name
+	Columns     map[string]ColumnType  `json:"columns,omitempty"`
 }
 
+// ColumnType is the JSON Schema primitive type recorded for a tracked
+// table column (e.g. for REST request-body validation).
+type ColumnType string
+
+const (
+	ColumnTypeString  ColumnType = "string"
+	ColumnTypeNumber  ColumnType = "number"
+	ColumnTypeInteger ColumnType = "integer"
+	ColumnTypeBoolean ColumnType = "boolean"
+)
+
 type RoleConfig struct {
 	Role   string            `json:"role"`
 	Select *PermissionConfig `json:"select"`
@@ -39,41 +69,166 @@ type Relationship struct {
 	FieldMapping map[string]string `json:"field_mapping"` // "foreign_key": "private_key"
 }
 
-func NewMetadataStore() *MetadataStore {
+func (r *Relationship) id() string { return fmt.Sprintf("%s.%s", r.FromTable, r.Name) }
+
+// NewMetadataStore creates a MetadataStore. node may be nil, in which case
+// TrackTable/SetColumns/TrackRelationship/DropRelationship only update the
+// in-memory maps and Load is a no-op - the historical behavior before
+// this store persisted anything.
+func NewMetadataStore(node *cluster.Node) *MetadataStore {
 	return &MetadataStore{
 		Tables:        make(map[string]*TableMetadata),
 		Relationships: make(map[string]*Relationship),
+		node:          node,
 	}
 }
 
-// Save persists the metadata to the internal storage (mocked for MVP)
-func (ms *MetadataStore) Save() error {
-	// In real impl: write to _schema_metadata collection
+// Load replaces Tables and Relationships with the contents of
+// tablesCollection and relationshipsCollection. Call it once at startup,
+// the same way auth.AuthEngine.LoadPermissions and cron.Scheduler's
+// loadPersistedJobs seed their in-memory state from prior runs.
+func (ms *MetadataStore) Load() error {
+	if ms.node == nil {
+		return nil
+	}
+
+	tableDocs, err := ms.node.RunQuery(tablesCollection, map[string]interface{}{"limit": 10000}, cluster.Stale)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", tablesCollection, err)
+	}
+	tables := make(map[string]*TableMetadata, len(tableDocs))
+	for _, doc := range tableDocs {
+		var t TableMetadata
+		if err := docToValue(doc, &t); err != nil {
+			return fmt.Errorf("failed to decode table metadata: %w", err)
+		}
+		tables[t.Name] = &t
+	}
+
+	relDocs, err := ms.node.RunQuery(relationshipsCollection, map[string]interface{}{"limit": 10000}, cluster.Stale)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", relationshipsCollection, err)
+	}
+	rels := make(map[string]*Relationship, len(relDocs))
+	for _, doc := range relDocs {
+		var r Relationship
+		if err := docToValue(doc, &r); err != nil {
+			return fmt.Errorf("failed to decode relationship: %w", err)
+		}
+		rels[r.id()] = &r
+	}
+
+	ms.mu.Lock()
+	ms.Tables = tables
+	ms.Relationships = rels
+	ms.mu.Unlock()
 	return nil
 }
 
-// Load loads metadata from storage
-func (ms *MetadataStore) Load() error {
-	// In real impl: read from _schema_metadata collection
-	return nil
+// persistTable upserts t into tablesCollection, keyed by its Name.
+func (ms *MetadataStore) persistTable(t *TableMetadata) error {
+	if ms.node == nil {
+		return nil
+	}
+	doc, err := valueToDoc(t)
+	if err != nil {
+		return err
+	}
+	doc["_id"] = t.Name
+	if _, err := ms.node.GetDocument(tablesCollection, t.Name, cluster.Stale); err == nil {
+		return ms.node.UpdateDocument(tablesCollection, t.Name, doc)
+	}
+	_, err = ms.node.InsertDocument(tablesCollection, doc)
+	return err
 }
 
-func (ms *MetadataStore) TrackTable(tableName string) {
+func (ms *MetadataStore) TrackTable(tableName string) error {
 	ms.mu.Lock()
-	defer ms.mu.Unlock()
-	if _, exists := ms.Tables[tableName]; !exists {
-		ms.Tables[tableName] = &TableMetadata{
+	t, exists := ms.Tables[tableName]
+	if !exists {
+		t = &TableMetadata{
 			Name:        tableName,
 			Permissions: make(map[string]*RoleConfig),
 		}
+		ms.Tables[tableName] = t
 	}
+	ms.mu.Unlock()
+	if exists {
+		return nil
+	}
+	return ms.persistTable(t)
 }
 
-func (ms *MetadataStore) AddRelationship(rel *Relationship) {
+// SetColumns records the column types used to generate a per-table JSON
+// Schema, tracking the table first if it isn't already known.
+func (ms *MetadataStore) SetColumns(tableName string, columns map[string]ColumnType) error {
 	ms.mu.Lock()
-	defer ms.mu.Unlock()
-	key := fmt.Sprintf("%s.%s", rel.FromTable, rel.Name)
-	ms.Relationships[key] = rel
+	t, ok := ms.Tables[tableName]
+	if !ok {
+		t = &TableMetadata{Name: tableName, Permissions: make(map[string]*RoleConfig)}
+		ms.Tables[tableName] = t
+	}
+	t.Columns = columns
+	ms.mu.Unlock()
+	return ms.persistTable(t)
+}
+
+// GetTable returns the tracked metadata for tableName, if any.
+func (ms *MetadataStore) GetTable(tableName string) (*TableMetadata, bool) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	t, ok := ms.Tables[tableName]
+	return t, ok
+}
+
+// ListTables returns every tracked table's metadata, in no particular
+// order.
+func (ms *MetadataStore) ListTables() []*TableMetadata {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	tables := make([]*TableMetadata, 0, len(ms.Tables))
+	for _, t := range ms.Tables {
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+// TrackRelationship adds rel (or replaces the relationship previously
+// tracked under the same FromTable+Name), persisting it to
+// relationshipsCollection.
+func (ms *MetadataStore) TrackRelationship(rel *Relationship) error {
+	ms.mu.Lock()
+	ms.Relationships[rel.id()] = rel
+	ms.mu.Unlock()
+
+	if ms.node == nil {
+		return nil
+	}
+	doc, err := valueToDoc(rel)
+	if err != nil {
+		return err
+	}
+	doc["_id"] = rel.id()
+	if _, err := ms.node.GetDocument(relationshipsCollection, rel.id(), cluster.Stale); err == nil {
+		return ms.node.UpdateDocument(relationshipsCollection, rel.id(), doc)
+	}
+	_, err = ms.node.InsertDocument(relationshipsCollection, doc)
+	return err
+}
+
+// DropRelationship removes the relationship named name on fromTable, from
+// both the in-memory cache and relationshipsCollection.
+func (ms *MetadataStore) DropRelationship(fromTable, name string) error {
+	key := fmt.Sprintf("%s.%s", fromTable, name)
+	ms.mu.Lock()
+	_, existed := ms.Relationships[key]
+	delete(ms.Relationships, key)
+	ms.mu.Unlock()
+
+	if !existed || ms.node == nil {
+		return nil
+	}
+	return ms.node.DeleteDocument(relationshipsCollection, key)
 }
 
 func (ms *MetadataStore) GetRelationships(tableName string) []*Relationship {
@@ -87,3 +242,23 @@ func (ms *MetadataStore) GetRelationships(tableName string) []*Relationship {
 	}
 	return rels
 }
+
+func valueToDoc(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func docToValue(doc map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
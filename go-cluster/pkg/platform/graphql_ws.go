@@ -0,0 +1,165 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+	"github.com/tdb-plus/cluster/pkg/platform/auth"
+	"go.uber.org/zap"
+)
+
+// maxSubscriptionsPerConn caps how many `subscribe` messages a single
+// graphql-transport-ws connection may have open at once, so one slow or
+// malicious client can't accumulate unbounded goroutines/channels on the
+// server (see events.TriggerManager's own per-collection cap).
+const maxSubscriptionsPerConn = 32
+
+var gqlWSUpgrader = websocket.Upgrader{
+	Subprotocols: []string{"graphql-transport-ws"},
+	CheckOrigin:  func(r *http.Request) bool { return true },
+}
+
+// gqlWSMessage is the graphql-transport-ws envelope: ConnectionInit/
+// ConnectionAck/Ping/Pong/Subscribe/Next/Error/Complete all use it.
+type gqlWSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type gqlSubscribePayload struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// handleGraphQLWS upgrades GET /graphql to a graphql-transport-ws
+// connection (https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md)
+// and serves `subscription { ... }` operations over it via
+// gqlEngine.Subscribe. It's only reached through s.HandlerMap["graphql.playground"],
+// which authMiddleware already ran for, so claims reflect the same bearer
+// token REST/GraphQL requests use.
+func (s *Server) handleGraphQLWS(c *gin.Context) {
+	claims, _ := c.MustGet("claims").(*auth.Claims)
+
+	conn, err := gqlWSUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		s.logger.Warn("graphql-transport-ws upgrade failed", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	writeJSON := func(msg gqlWSMessage) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteJSON(msg)
+	}
+
+	var subsMu sync.Mutex
+	subs := make(map[string]context.CancelFunc)
+	defer func() {
+		subsMu.Lock()
+		for _, cancel := range subs {
+			cancel()
+		}
+		subsMu.Unlock()
+	}()
+
+	for {
+		var msg gqlWSMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		switch msg.Type {
+		case "connection_init":
+			if err := writeJSON(gqlWSMessage{Type: "connection_ack"}); err != nil {
+				return
+			}
+
+		case "ping":
+			if err := writeJSON(gqlWSMessage{Type: "pong"}); err != nil {
+				return
+			}
+
+		case "subscribe":
+			if claims == nil {
+				_ = writeJSON(gqlWSMessage{ID: msg.ID, Type: "error", Payload: gqlWSErrorPayload("unauthorized")})
+				continue
+			}
+
+			var payload gqlSubscribePayload
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				_ = writeJSON(gqlWSMessage{ID: msg.ID, Type: "error", Payload: gqlWSErrorPayload("invalid subscribe payload")})
+				continue
+			}
+
+			subsMu.Lock()
+			if _, exists := subs[msg.ID]; exists {
+				subsMu.Unlock()
+				continue
+			}
+			if len(subs) >= maxSubscriptionsPerConn {
+				subsMu.Unlock()
+				_ = writeJSON(gqlWSMessage{ID: msg.ID, Type: "error", Payload: gqlWSErrorPayload("too many concurrent subscriptions")})
+				continue
+			}
+			ctx, cancel := context.WithCancel(auth.WithClaims(c.Request.Context(), claims))
+			subs[msg.ID] = cancel
+			subsMu.Unlock()
+
+			results, err := s.gqlEngine.Subscribe(ctx, payload.Query, payload.Variables)
+			if err != nil {
+				cancel()
+				subsMu.Lock()
+				delete(subs, msg.ID)
+				subsMu.Unlock()
+				_ = writeJSON(gqlWSMessage{ID: msg.ID, Type: "error", Payload: gqlWSErrorPayload(err.Error())})
+				continue
+			}
+
+			go s.forwardGraphQLSubscription(msg.ID, results, writeJSON, func() {
+				subsMu.Lock()
+				delete(subs, msg.ID)
+				subsMu.Unlock()
+			})
+
+		case "complete":
+			subsMu.Lock()
+			if cancel, ok := subs[msg.ID]; ok {
+				cancel()
+				delete(subs, msg.ID)
+			}
+			subsMu.Unlock()
+		}
+	}
+}
+
+// forwardGraphQLSubscription relays results onto the websocket as "next"
+// messages under id until the channel closes (cursor exhausted or ctx
+// cancelled via a client "complete"/disconnect), then sends "complete".
+// done is called once the goroutine exits, so the caller can forget id.
+func (s *Server) forwardGraphQLSubscription(id string, results <-chan *graphql.Result, writeJSON func(gqlWSMessage) error, done func()) {
+	defer done()
+	for result := range results {
+		payload, err := json.Marshal(result)
+		if err != nil {
+			continue
+		}
+		if err := writeJSON(gqlWSMessage{ID: id, Type: "next", Payload: payload}); err != nil {
+			return
+		}
+	}
+	_ = writeJSON(gqlWSMessage{ID: id, Type: "complete"})
+}
+
+func gqlWSErrorPayload(msg string) json.RawMessage {
+	b, _ := json.Marshal([]gin.H{{"message": msg}})
+	return b
+}
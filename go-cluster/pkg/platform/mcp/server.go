@@ -4,24 +4,44 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
-	"github.com/lumadb/cluster/pkg/cluster"
-	"github.com/lumadb/cluster/pkg/platform/graphql"
-	"github.com/lumadb/cluster/pkg/query"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/platform/graphql"
+	"github.com/tdb-plus/cluster/pkg/query"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"go.uber.org/zap"
 )
 
+// schemaSampleSize bounds how many documents handleInspectSchema scans
+// to infer a collection's schema when no registered schema is tracked.
+const schemaSampleSize = 100
+
 // MCPServer implements the Model Context Protocol
 type MCPServer struct {
-	node      *cluster.Node
-	gqlEngine *graphql.GraphQLEngine
-	server    *server.MCPServer
-	logger    *zap.Logger
+	node         *cluster.Node
+	gqlEngine    *graphql.GraphQLEngine
+	server       *server.MCPServer
+	logger       *zap.Logger
+	writeEnabled bool
+}
+
+// Option configures an MCPServer built by NewMCPServer, the same
+// functional-options convention platform.Server uses (see
+// pkg/platform/options.go).
+type Option func(*MCPServer)
+
+// WithWriteEnabled controls whether insert_documents, update_documents,
+// delete_documents, and query_luma's INSERT/UPDATE/DELETE statements are
+// available at all. Defaults to false: an MCPServer's tools are meant to
+// be driven by an LLM agent, and a read-only default is the safer one
+// to hand such an agent without an explicit opt-in.
+func WithWriteEnabled(enabled bool) Option {
+	return func(s *MCPServer) { s.writeEnabled = enabled }
 }
 
-func NewMCPServer(node *cluster.Node, gqlEngine *graphql.GraphQLEngine, logger *zap.Logger) *MCPServer {
+func NewMCPServer(node *cluster.Node, gqlEngine *graphql.GraphQLEngine, logger *zap.Logger, opts ...Option) *MCPServer {
 	s := server.NewMCPServer(
 		"LumaDB",
 		"1.0.0",
@@ -33,6 +53,9 @@ func NewMCPServer(node *cluster.Node, gqlEngine *graphql.GraphQLEngine, logger *
 		server:    s,
 		logger:    logger,
 	}
+	for _, opt := range opts {
+		opt(ms)
+	}
 
 	ms.registerTools()
 	ms.registerResources()
@@ -68,11 +91,138 @@ func (s *MCPServer) registerTools() {
 		mcp.WithDescription("Get the schema of a collection"),
 		mcp.WithString("collection", mcp.Required(), mcp.Description("The collection name")),
 	), s.handleInspectSchema)
+
+	if !s.writeEnabled {
+		return
+	}
+
+	// Tool: insert_documents
+	s.server.AddTool(mcp.NewTool(
+		"insert_documents",
+		mcp.WithDescription("Insert one or more documents into a collection"),
+		mcp.WithString("collection", mcp.Required(), mcp.Description("The collection name")),
+		mcp.WithString("documents", mcp.Required(), mcp.Description("JSON array of documents to insert")),
+		mcp.WithString("dry_run", mcp.Description(`"true" to preview the insert without writing anything (default "false")`)),
+	), s.handleInsertDocuments)
+
+	// Tool: update_documents
+	s.server.AddTool(mcp.NewTool(
+		"update_documents",
+		mcp.WithDescription("Update every document in a collection matching a filter"),
+		mcp.WithString("collection", mcp.Required(), mcp.Description("The collection name")),
+		mcp.WithString("filter", mcp.Required(), mcp.Description("JSON filter object selecting documents to update")),
+		mcp.WithString("updates", mcp.Required(), mcp.Description("JSON object of fields to set on every matched document")),
+		mcp.WithString("dry_run", mcp.Description(`"true" to preview the matched documents without writing anything (default "false")`)),
+	), s.handleUpdateDocuments)
+
+	// Tool: delete_documents
+	s.server.AddTool(mcp.NewTool(
+		"delete_documents",
+		mcp.WithDescription("Delete every document in a collection matching a filter"),
+		mcp.WithString("collection", mcp.Required(), mcp.Description("The collection name")),
+		mcp.WithString("filter", mcp.Required(), mcp.Description("JSON filter object selecting documents to delete")),
+		mcp.WithString("dry_run", mcp.Description(`"true" to preview the matched documents without deleting anything (default "false")`)),
+	), s.handleDeleteDocuments)
 }
 
+// clusterStatusURI is the one fixed resource this server always exposes,
+// alongside one luma://collection/{name} resource per collection
+// ListCollections reports at construction time.
+const clusterStatusURI = "luma://cluster/status"
+
 func (s *MCPServer) registerResources() {
-	// Dynamic resource listing would go here.
-	// For now, we rely on tools.
+	s.server.AddResource(mcp.NewResource(
+		clusterStatusURI,
+		"Cluster status",
+		mcp.WithResourceDescription("Leadership, peers, and shard placement for this cluster node"),
+		mcp.WithMIMEType("application/json"),
+	), s.handleClusterStatusResource)
+
+	collections, err := s.node.ListCollections()
+	if err != nil {
+		s.logger.Warn("failed to list collections for MCP resource registration", zap.Error(err))
+		return
+	}
+	for _, name := range collections {
+		s.server.AddResource(mcp.NewResource(
+			collectionURI(name),
+			name,
+			mcp.WithResourceDescription(fmt.Sprintf("Recent documents and inferred schema for %s", name)),
+			mcp.WithMIMEType("application/json"),
+		), s.handleCollectionResource(name))
+	}
+}
+
+// collectionURI builds the luma://collection/{name} URI a collection's
+// resource is registered and read back under.
+func collectionURI(name string) string {
+	return "luma://collection/" + name
+}
+
+// clusterStatus is luma://cluster/status's JSON body.
+type clusterStatus struct {
+	IsLeader   bool                          `json:"is_leader"`
+	LeaderAddr string                        `json:"leader_addr"`
+	NumShards  uint32                        `json:"num_shards"`
+	Peers      map[string]string             `json:"peers"`
+	Shards     map[uint32]*cluster.ShardInfo `json:"shards"`
+}
+
+func (s *MCPServer) handleClusterStatusResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	data, err := json.Marshal(clusterStatus{
+		IsLeader:   s.node.IsLeader(),
+		LeaderAddr: s.node.LeaderAddr(),
+		NumShards:  s.node.NumShards(),
+		Peers:      s.node.GetPeers(),
+		Shards:     s.node.GetShards(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      clusterStatusURI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
+
+// collectionResource is a collection resource's JSON body: a sample of
+// its recent documents plus the same inferred/registered schema
+// inspect_schema would report.
+type collectionResource struct {
+	Documents []map[string]interface{} `json:"documents"`
+	Schema    collectionSchema          `json:"schema"`
+}
+
+// handleCollectionResource returns a ResourceHandlerFunc bound to a
+// specific collection name, since mcp-go resource handlers take no
+// argument identifying which resource's URI was read.
+func (s *MCPServer) handleCollectionResource(name string) func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		docs, err := s.node.RunQuery(name, map[string]interface{}{"limit": schemaSampleSize}, cluster.Stale)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample %s: %w", name, err)
+		}
+
+		schema, err := s.resolveSchema(name)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(collectionResource{Documents: docs, Schema: schema})
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      collectionURI(name),
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		}, nil
+	}
 }
 
 // handleGraphQLQuery executes a GraphQL query
@@ -131,14 +281,120 @@ func (s *MCPServer) handleQueryLayer(ctx context.Context, request mcp.CallToolRe
 		return mcp.NewToolResultText(fmt.Sprintf("%v", results)), nil
 	}
 
+	if stmt.Insert != nil {
+		if err := s.requireWriteEnabled(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		doc := map[string]interface{}{}
+		for i, key := range stmt.Insert.Keys {
+			if i < len(stmt.Insert.Values) {
+				doc[key] = valueToInterface(&stmt.Insert.Values[i])
+			}
+		}
+		id, err := s.node.InsertDocument(stmt.Insert.Collection, doc)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("insert failed: %v", err)), nil
+		}
+		return jsonResult(map[string]interface{}{"_id": id, "status": "inserted"})
+	}
+
+	if stmt.Update != nil {
+		if err := s.requireWriteEnabled(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		updates := map[string]interface{}{}
+		for _, set := range stmt.Update.Sets {
+			updates[set.Field] = valueToInterface(set.Value)
+		}
+		docs, err := s.matchingDocs(stmt.Update.Collection, stmt.Update.Where)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find matching documents: %v", err)), nil
+		}
+		for _, doc := range docs {
+			id, _ := doc["_id"].(string)
+			if err := s.node.UpdateDocument(stmt.Update.Collection, id, updates); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("update failed: %v", err)), nil
+			}
+		}
+		return jsonResult(writeResult{AffectedCount: len(docs)})
+	}
+
+	if stmt.Delete != nil {
+		if err := s.requireWriteEnabled(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		docs, err := s.matchingDocs(stmt.Delete.Collection, stmt.Delete.Where)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find matching documents: %v", err)), nil
+		}
+		for _, doc := range docs {
+			id, _ := doc["_id"].(string)
+			if err := s.node.DeleteDocument(stmt.Delete.Collection, id); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("delete failed: %v", err)), nil
+			}
+		}
+		return jsonResult(writeResult{AffectedCount: len(docs)})
+	}
+
 	return mcp.NewToolResultText("Query type not supported yet via MCP"), nil
 }
 
+// valueToInterface unwraps an LQL Value into the plain Go value
+// InsertDocument/UpdateDocument expect in a document map.
+func valueToInterface(v *query.Value) interface{} {
+	switch {
+	case v == nil:
+		return nil
+	case v.Number != nil:
+		return *v.Number
+	case v.String != nil:
+		return *v.String
+	case v.Bool != nil:
+		return *v.Bool
+	default:
+		return nil
+	}
+}
+
+// matchingDocs resolves an LQL WHERE clause's single condition to a
+// Hasura-style filter and queries collection for the matching documents -
+// the same "find then act by _id" approach handleUpdateDocuments and
+// handleDeleteDocuments use for their JSON-filter variants.
+func (s *MCPServer) matchingDocs(collection string, where *query.Where) ([]map[string]interface{}, error) {
+	queryMap := map[string]interface{}{}
+	if where != nil && where.Condition != nil && where.Condition.Left != "" {
+		queryMap["filter"] = map[string]interface{}{
+			where.Condition.Left: valueToInterface(where.Condition.Right),
+		}
+	}
+	return s.node.RunQuery(collection, queryMap, cluster.Stale)
+}
+
 func (s *MCPServer) handleListCollections(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// MVP: Mock response or query system table
-	// In real impl: s.node.ListCollections()
-	collections := []string{"users", "products", "orders", "system.events"}
-	return mcp.NewToolResultText(fmt.Sprintf("%v", collections)), nil
+	collections, err := s.node.ListCollections()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list collections: %v", err)), nil
+	}
+	return jsonResult(collections)
+}
+
+// collectionSchema is handleInspectSchema's result: either a registered
+// schema tracked by the GraphQL engine's MetadataStore, or one inferred
+// by sampling documents.
+type collectionSchema struct {
+	Collection string                 `json:"collection"`
+	Source     string                 `json:"source"` // "registered" or "inferred"
+	SampleSize int                    `json:"sample_size,omitempty"`
+	Fields     map[string]fieldSchema `json:"fields"`
+}
+
+// fieldSchema describes the types a field was observed to hold. Types
+// has more than one entry when a field is a union across sampled
+// documents (e.g. both "string" and "number"); Nullable is set when the
+// field was absent or explicitly null in at least one sampled document.
+type fieldSchema struct {
+	Types    []string `json:"types"`
+	Nullable bool     `json:"nullable,omitempty"`
 }
 
 func (s *MCPServer) handleInspectSchema(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -146,16 +402,311 @@ func (s *MCPServer) handleInspectSchema(ctx context.Context, request mcp.CallToo
 	if err != nil {
 		return mcp.NewToolResultError("collection argument is required"), nil
 	}
-	// MVP: Mock schema
-	schema := map[string]string{
-		"id":         "string",
-		"created_at": "datetime",
-		"updated_at": "datetime",
+
+	schema, err := s.resolveSchema(collection)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	return mcp.NewToolResultText(fmt.Sprintf("Schema for %s: %v", collection, schema)), nil
+	return jsonResult(schema)
+}
+
+// resolveSchema reports collection's registered schema if the GraphQL
+// engine's MetadataStore tracks one, else infers one by sampling
+// schemaSampleSize documents - the same lookup handleInspectSchema and
+// the luma://collection/{name} resource handler both need.
+func (s *MCPServer) resolveSchema(collection string) (collectionSchema, error) {
+	if s.gqlEngine != nil {
+		if table, ok := s.gqlEngine.Metadata().GetTable(collection); ok && len(table.Columns) > 0 {
+			fields := make(map[string]fieldSchema, len(table.Columns))
+			for col, colType := range table.Columns {
+				fields[col] = fieldSchema{Types: []string{string(colType)}}
+			}
+			return collectionSchema{
+				Collection: collection,
+				Source:     "registered",
+				Fields:     fields,
+			}, nil
+		}
+	}
+
+	docs, err := s.node.RunQuery(collection, map[string]interface{}{"limit": schemaSampleSize}, cluster.Stale)
+	if err != nil {
+		return collectionSchema{}, fmt.Errorf("failed to sample %s: %w", collection, err)
+	}
+	return collectionSchema{
+		Collection: collection,
+		Source:     "inferred",
+		SampleSize: len(docs),
+		Fields:     inferSchema(docs),
+	}, nil
+}
+
+// inferSchema unifies the types observed for each field across docs,
+// marking a field Nullable if it's absent or null in at least one
+// document.
+func inferSchema(docs []map[string]interface{}) map[string]fieldSchema {
+	typeSets := make(map[string]map[string]struct{})
+	presence := make(map[string]int)
+
+	for _, doc := range docs {
+		for field, value := range doc {
+			if typeSets[field] == nil {
+				typeSets[field] = make(map[string]struct{})
+			}
+			typeSets[field][jsonValueType(value)] = struct{}{}
+			presence[field]++
+		}
+	}
+
+	fields := make(map[string]fieldSchema, len(typeSets))
+	for field, types := range typeSets {
+		typeList := make([]string, 0, len(types))
+		for t := range types {
+			typeList = append(typeList, t)
+		}
+		sort.Strings(typeList)
+		_, hasNull := types["null"]
+		fields[field] = fieldSchema{
+			Types:    typeList,
+			Nullable: presence[field] < len(docs) || hasNull,
+		}
+	}
+	return fields
+}
+
+// jsonValueType classifies v as one of the types a document field can
+// hold after a JSON round trip through the storage engine.
+func jsonValueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int32, int64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// jsonResult marshals v to JSON and wraps it as a tool result, so MCP
+// clients get structured data instead of having to parse Go's %v
+// formatting.
+func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// writeSampleSize caps how many documents a dry-run write tool previews,
+// the same way schemaSampleSize caps handleInspectSchema's sampling.
+const writeSampleSize = 5
+
+// writeResult is the shared result shape for insert_documents,
+// update_documents, and delete_documents: how many documents were (or,
+// for a dry run, would be) affected, plus a capped preview sample.
+type writeResult struct {
+	DryRun        bool                     `json:"dry_run"`
+	AffectedCount int                      `json:"affected_count"`
+	Sample        []map[string]interface{} `json:"sample,omitempty"`
+}
+
+// requireWriteEnabled returns an error identifying the caller's MCPServer
+// as read-only if it wasn't constructed with WithWriteEnabled(true). Tool
+// registration already keeps these tools out of a read-only server's tool
+// list, but query_luma's INSERT/UPDATE/DELETE branches reuse this as a
+// second line of defense since query_luma itself is always registered.
+func (s *MCPServer) requireWriteEnabled() error {
+	if !s.writeEnabled {
+		return fmt.Errorf("writes are disabled on this MCP server; construct it with mcp.WithWriteEnabled(true) to allow them")
+	}
+	return nil
+}
+
+// parseDryRun reads the "dry_run" argument, which is a string ("true"/
+// "false") rather than a bool: this file already models other
+// JSON-ish arguments (e.g. handleGraphQLQuery's "variables") as strings,
+// and the mcp-go version vendored here has no confirmed boolean-argument
+// helper to match against.
+func parseDryRun(request mcp.CallToolRequest) bool {
+	return request.GetString("dry_run", "false") == "true"
+}
+
+// sampleDocs caps docs to at most writeSampleSize entries, for previewing
+// a dry run or reporting what a write just affected without echoing back
+// an unbounded result set.
+func sampleDocs(docs []map[string]interface{}) []map[string]interface{} {
+	if len(docs) > writeSampleSize {
+		return docs[:writeSampleSize]
+	}
+	return docs
+}
+
+// handleInsertDocuments inserts every document in the "documents" JSON
+// array argument into collection. A dry_run previews the parsed
+// documents without calling InsertDocument.
+func (s *MCPServer) handleInsertDocuments(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	collection, err := request.RequireString("collection")
+	if err != nil {
+		return mcp.NewToolResultError("collection argument is required"), nil
+	}
+	docsArg, err := request.RequireString("documents")
+	if err != nil {
+		return mcp.NewToolResultError("documents argument is required"), nil
+	}
+	var docs []map[string]interface{}
+	if err := json.Unmarshal([]byte(docsArg), &docs); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid documents JSON: %v", err)), nil
+	}
+
+	dryRun := parseDryRun(request)
+	if dryRun {
+		return jsonResult(writeResult{
+			DryRun:        true,
+			AffectedCount: len(docs),
+			Sample:        sampleDocs(docs),
+		})
+	}
+
+	inserted := make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		id, err := s.node.InsertDocument(collection, doc)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("insert failed after %d of %d documents: %v", len(inserted), len(docs), err)), nil
+		}
+		doc["_id"] = id
+		inserted = append(inserted, doc)
+	}
+
+	return jsonResult(writeResult{
+		AffectedCount: len(inserted),
+		Sample:        sampleDocs(inserted),
+	})
+}
+
+// handleUpdateDocuments applies the "updates" JSON object to every
+// document in collection matching the "filter" JSON object. A dry_run
+// previews the matched documents without calling UpdateDocument.
+func (s *MCPServer) handleUpdateDocuments(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	collection, err := request.RequireString("collection")
+	if err != nil {
+		return mcp.NewToolResultError("collection argument is required"), nil
+	}
+	filter, err := parseJSONObjectArg(request, "filter")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	updates, err := parseJSONObjectArg(request, "updates")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	docs, err := s.node.RunQuery(collection, map[string]interface{}{"filter": filter}, cluster.Stale)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to find matching documents: %v", err)), nil
+	}
+
+	dryRun := parseDryRun(request)
+	if dryRun {
+		return jsonResult(writeResult{
+			DryRun:        true,
+			AffectedCount: len(docs),
+			Sample:        sampleDocs(docs),
+		})
+	}
+
+	for _, doc := range docs {
+		id, _ := doc["_id"].(string)
+		if err := s.node.UpdateDocument(collection, id, updates); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("update failed: %v", err)), nil
+		}
+	}
+
+	return jsonResult(writeResult{
+		AffectedCount: len(docs),
+		Sample:        sampleDocs(docs),
+	})
+}
+
+// handleDeleteDocuments deletes every document in collection matching
+// the "filter" JSON object. A dry_run previews the matched documents
+// without calling DeleteDocument.
+func (s *MCPServer) handleDeleteDocuments(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	collection, err := request.RequireString("collection")
+	if err != nil {
+		return mcp.NewToolResultError("collection argument is required"), nil
+	}
+	filter, err := parseJSONObjectArg(request, "filter")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	docs, err := s.node.RunQuery(collection, map[string]interface{}{"filter": filter}, cluster.Stale)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to find matching documents: %v", err)), nil
+	}
+
+	dryRun := parseDryRun(request)
+	if dryRun {
+		return jsonResult(writeResult{
+			DryRun:        true,
+			AffectedCount: len(docs),
+			Sample:        sampleDocs(docs),
+		})
+	}
+
+	for _, doc := range docs {
+		id, _ := doc["_id"].(string)
+		if err := s.node.DeleteDocument(collection, id); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("delete failed: %v", err)), nil
+		}
+	}
+
+	return jsonResult(writeResult{
+		AffectedCount: len(docs),
+		Sample:        sampleDocs(docs),
+	})
+}
+
+// parseJSONObjectArg reads and unmarshals request's required string
+// argument name as a JSON object.
+func parseJSONObjectArg(request mcp.CallToolRequest, name string) (map[string]interface{}, error) {
+	raw, err := request.RequireString(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s argument is required", name)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &obj); err != nil {
+		return nil, fmt.Errorf("invalid %s JSON: %v", name, err)
+	}
+	return obj, nil
 }
 
 // ServeStdio serves MCP over standard input/output
 func (s *MCPServer) ServeStdio() error {
 	return server.ServeStdio(s.server)
 }
+
+// ServeSSE serves MCP over HTTP using the Server-Sent Events transport,
+// blocking until the listener returns an error. Use this (or ServeHTTP)
+// to mount an MCPServer alongside platform.Server's GraphQL/REST
+// endpoints instead of spawning a separate stdio child process per
+// tenant.
+func (s *MCPServer) ServeSSE(addr string) error {
+	return server.NewSSEServer(s.server).Start(addr)
+}
+
+// ServeHTTP serves MCP over the streamable-HTTP transport, blocking
+// until the listener returns an error. See ServeSSE for why this exists
+// alongside ServeStdio.
+func (s *MCPServer) ServeHTTP(addr string) error {
+	return server.NewStreamableHTTPServer(s.server).Start(addr)
+}
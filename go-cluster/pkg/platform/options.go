@@ -0,0 +1,128 @@
+package platform
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tdb-plus/cluster/pkg/ai"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/platform/auth"
+	authstore "github.com/tdb-plus/cluster/pkg/platform/auth/store"
+	pconfig "github.com/tdb-plus/cluster/pkg/platform/config"
+	"github.com/tdb-plus/cluster/pkg/platform/cron"
+	gql "github.com/tdb-plus/cluster/pkg/platform/graphql"
+	"go.uber.org/zap"
+)
+
+// Option configures a Server built by New. Options that aren't supplied
+// fall back to the historical NewServer defaults, derived from the node
+// and logger.
+type Option func(*Server)
+
+// WithNode sets the cluster node the server reads/writes through.
+func WithNode(node *cluster.Node) Option {
+	return func(s *Server) { s.node = node }
+}
+
+// WithLogger sets the server's logger. Defaults to zap.NewNop() if unset.
+func WithLogger(logger *zap.Logger) Option {
+	return func(s *Server) { s.logger = logger }
+}
+
+// WithAuthEngine overrides the default auth.NewAuthEngine(node, logger),
+// e.g. to inject one pre-configured with a non-default JWT secret or
+// signing method.
+func WithAuthEngine(engine *auth.AuthEngine) Option {
+	return func(s *Server) { s.authEngine = engine }
+}
+
+// WithGQLEngine overrides the default gql.NewGraphQLEngine(node, nil, logger).
+func WithGQLEngine(engine *gql.GraphQLEngine) Option {
+	return func(s *Server) { s.gqlEngine = engine }
+}
+
+// WithCron overrides the default cron.NewScheduler(node, logger).
+func WithCron(scheduler *cron.Scheduler) Option {
+	return func(s *Server) { s.cron = scheduler }
+}
+
+// WithAuthStore wires s2 into the server's auth.AuthEngine as its
+// persistence layer (see pkg/platform/auth/store), enabling
+// database-backed login, POST /api/auth/users, POST /api/auth/apikeys,
+// and token revocation via POST /api/auth/logout.
+func WithAuthStore(s2 *authstore.Store) Option {
+	return func(s *Server) { s.authStore = s2 }
+}
+
+// WithRAGService wires r into the server's cron.Scheduler as the
+// executor behind the built-in "rag_ingest" cron payload type. Without
+// this option, a "rag_ingest" job fails with a descriptive error instead
+// of panicking.
+func WithRAGService(r *ai.RAGService) Option {
+	return func(s *Server) { s.ragService = r }
+}
+
+// WithConfigHandler overrides the default
+// pconfig.NewHandler(node.GetConfig()), e.g. to share a ConfigHandler
+// across multiple servers.
+func WithConfigHandler(handler *pconfig.Handler) Option {
+	return func(s *Server) { s.configHandler = handler }
+}
+
+// WithMiddleware appends gin middleware, applied in order after the
+// built-in CORS middleware and rate limiter (see WithRateLimiter).
+func WithMiddleware(mw ...gin.HandlerFunc) Option {
+	return func(s *Server) { s.middleware = append(s.middleware, mw...) }
+}
+
+// WithRateLimiter installs mw as global rate-limiting middleware, run
+// right after the built-in CORS middleware and before any WithMiddleware
+// entries.
+func WithRateLimiter(mw gin.HandlerFunc) Option {
+	return func(s *Server) { s.rateLimiter = mw }
+}
+
+// WithTLS serves HTTPS using certFile/keyFile instead of plaintext HTTP.
+func WithTLS(certFile, keyFile string) Option {
+	return func(s *Server) {
+		s.tlsCertFile = certFile
+		s.tlsKeyFile = keyFile
+	}
+}
+
+// WithRegionManager overrides the default
+// cluster.NewRegionManager(cluster.NewMemoryStorage()), e.g. to share a
+// RegionManager backed by durable storage across multiple servers.
+func WithRegionManager(rm *cluster.RegionManager) Option {
+	return func(s *Server) { s.regions = rm }
+}
+
+// WithKeyring wires k into the server's auth.AuthEngine as its signing
+// keyring (see auth.AuthEngine.SetKeyring), enabling RS256/EdDSA signing
+// and KID-based key rotation. Without this option, AuthEngine keeps
+// signing/verifying HS256 with its configured secret key, as before.
+func WithKeyring(k *auth.Keyring) Option {
+	return func(s *Server) { s.keyring = k }
+}
+
+// WithAdminToken sets the static token AdminGuard's GuardToken mode
+// checks for in the X-Admin-Token header, e.g. for an operator script
+// that can't carry a user's JWT.
+func WithAdminToken(token string) Option {
+	return func(s *Server) { s.adminToken = token }
+}
+
+// WithAdminIPAllowlist sets the CIDRs AdminGuard's GuardIPAllowlist mode
+// restricts matching routes to. Entries that fail to parse are dropped
+// (and logged) rather than rejected outright, so one typo doesn't lock
+// every admin route.
+func WithAdminIPAllowlist(cidrs ...string) Option {
+	return func(s *Server) { s.adminIPAllowlist = cidrs }
+}
+
+// WithRouteHandler registers an additional route, e.g. a new
+// collection-scoped verb, alongside the built-in ones. Unlike an entry in
+// HandlerMap, this adds a route rather than replacing one.
+func WithRouteHandler(method, path string, handler gin.HandlerFunc) Option {
+	return func(s *Server) {
+		s.extraRoutes = append(s.extraRoutes, routeHandler{method: method, path: path, handler: handler})
+	}
+}
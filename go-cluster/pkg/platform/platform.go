@@ -1,10 +1,10 @@
 package platform
 
 import (
-	"github.com/lumadb/cluster/pkg/cluster"
-	"github.com/lumadb/cluster/pkg/platform/auth"
-	"github.com/lumadb/cluster/pkg/platform/graphql"
-	"github.com/lumadb/cluster/pkg/platform/mcp"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/platform/auth"
+	"github.com/tdb-plus/cluster/pkg/platform/graphql"
+	"github.com/tdb-plus/cluster/pkg/platform/mcp"
 	"go.uber.org/zap"
 )
 
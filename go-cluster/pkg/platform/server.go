@@ -1,46 +1,214 @@
 package platform
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lumadb/cluster/pkg/cluster"
-	"github.com/lumadb/cluster/pkg/platform/auth"
-	"github.com/lumadb/cluster/pkg/platform/cron"
-	gql "github.com/lumadb/cluster/pkg/platform/graphql"
+	"github.com/gofrs/uuid"
+	"github.com/tdb-plus/cluster/pkg/ai"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/config"
+	"github.com/tdb-plus/cluster/pkg/platform/auth"
+	authstore "github.com/tdb-plus/cluster/pkg/platform/auth/store"
+	pconfig "github.com/tdb-plus/cluster/pkg/platform/config"
+	"github.com/tdb-plus/cluster/pkg/platform/cron"
+	gql "github.com/tdb-plus/cluster/pkg/platform/graphql"
+	"github.com/tdb-plus/cluster/pkg/platform/replication"
 	"go.uber.org/zap"
 )
 
+// autoSplitInterval is how often RunAutoSplitter re-checks every region
+// against its size/write-rate thresholds.
+const autoSplitInterval = 30 * time.Second
+
 // Server serves REST and GraphQL APIs
 type Server struct {
-	node       *cluster.Node
-	logger     *zap.Logger
-	gqlEngine  *gql.GraphQLEngine
-	authEngine *auth.AuthEngine
-	cron       *cron.Scheduler
-	router     *gin.Engine
+	node          *cluster.Node
+	logger        *zap.Logger
+	gqlEngine     *gql.GraphQLEngine
+	authEngine    *auth.AuthEngine
+	cron          *cron.Scheduler
+	replication   *replication.Manager
+	configHandler *pconfig.Handler
+	regions       *cluster.RegionManager
+	router        *gin.Engine
+
+	// HandlerMap holds the gin.HandlerFunc backing every built-in route,
+	// keyed by a stable logical name (e.g. "rest.list"). Embedders using
+	// New can overwrite an entry before calling Start to replace a
+	// built-in handler, or add their own via WithRouteHandler.
+	HandlerMap HandlerMap
+
+	middleware  []gin.HandlerFunc
+	rateLimiter gin.HandlerFunc
+	extraRoutes []routeHandler
+	tlsCertFile string
+	tlsKeyFile  string
+	authStore   *authstore.Store
+	ragService  *ai.RAGService
+
+	// adminToken and adminIPAllowlist back AdminGuard's GuardToken and
+	// GuardIPAllowlist modes respectively (see WithAdminToken,
+	// WithAdminIPAllowlist). Neither is set by default, and AdminGuard
+	// skips a mode whose option is unset, so those modes enforce nothing
+	// until the matching option is given.
+	adminToken       string
+	adminIPAllowlist []string
+
+	// keyring, if set via WithKeyring, is wired into authEngine right
+	// after it's constructed (see New) so RS256/EdDSA signing and KID
+	// rotation are available from the first request.
+	keyring *auth.Keyring
+}
+
+// HandlerMap maps a logical route name to the gin.HandlerFunc that serves
+// it. See Server.HandlerMap.
+type HandlerMap map[string]gin.HandlerFunc
+
+type routeHandler struct {
+	method  string
+	path    string
+	handler gin.HandlerFunc
 }
 
+// NewServer builds a Server the same way platform has always constructed
+// one: a cluster node and logger, with every other dependency derived
+// from them. Equivalent to New(WithNode(node), WithLogger(logger)).
 func NewServer(node *cluster.Node, logger *zap.Logger) *Server {
-	return &Server{
-		node:       node,
-		logger:     logger,
-		gqlEngine:  gql.NewGraphQLEngine(node, logger),
-		authEngine: auth.NewAuthEngine(node, logger),
-		cron:       cron.NewScheduler(node, logger),
-		router:     gin.Default(),
+	return New(WithNode(node), WithLogger(logger))
+}
+
+// New builds a Server from opts, the functional-options constructor that
+// makes platform embeddable as a library rather than only a monolithic
+// binary. Any dependency not supplied via an option (authEngine,
+// gqlEngine, cron, replication, configHandler) is derived from the node
+// and logger, matching NewServer's historical defaults.
+func New(opts ...Option) *Server {
+	s := &Server{router: gin.Default()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.logger == nil {
+		s.logger = zap.NewNop()
+	}
+	if s.node != nil {
+		if s.cron == nil {
+			s.cron = cron.NewScheduler(s.node, s.logger)
+		}
+		if s.authEngine == nil {
+			s.authEngine = auth.NewAuthEngine(s.node, s.logger)
+		}
+		if s.gqlEngine == nil {
+			s.gqlEngine = gql.NewGraphQLEngine(s.node, nil, s.logger)
+		}
+		if s.replication == nil {
+			s.replication = replication.NewManager(s.node, s.cron, s.logger)
+		}
+		if s.configHandler == nil {
+			s.configHandler = pconfig.NewHandler(s.node.GetConfig())
+		}
+		if s.regions == nil {
+			// cluster.Node has no StorageEngine of its own to share (see
+			// replication.Manager's comment on why it doesn't use
+			// RegionManager either), so the default RegionManager
+			// persists its ID allocator to a private in-memory store.
+			// Pass WithRegionManager a Badger-backed one for durability.
+			s.regions = cluster.NewRegionManager(cluster.NewMemoryStorage())
+		}
+	}
+
+	if s.configHandler != nil && s.authEngine != nil {
+		// Keep the JWT signing key in sync with config hot-reloads
+		// (GET/PATCH /api/v1/config) instead of requiring a restart;
+		// corsMiddleware reads the config on every request by itself.
+		s.configHandler.Subscribe(func(cfg *config.Config) {
+			if cfg.JWTSecret != "" {
+				s.authEngine.SetSecretKey([]byte(cfg.JWTSecret))
+			}
+		})
+	}
+
+	if s.authStore != nil && s.authEngine != nil {
+		s.authEngine.SetStore(s.authStore)
+	}
+
+	if s.keyring != nil && s.authEngine != nil {
+		s.authEngine.SetKeyring(s.keyring)
+	}
+
+	if s.cron != nil {
+		s.cron.SetGraphQLEngine(s.gqlEngine)
+		s.cron.SetAuthEngine(s.authEngine)
+		if s.ragService != nil {
+			s.cron.SetRAGService(s.ragService)
+		}
+	}
+
+	if s.gqlEngine != nil && s.authEngine != nil {
+		s.gqlEngine.SetAuthEngine(s.authEngine)
+	}
+
+	s.HandlerMap = s.defaultHandlerMap()
+	return s
+}
+
+// defaultHandlerMap returns the built-in HandlerMap, bound to s. Callers
+// of New can overwrite individual entries before Start to override a
+// built-in handler (e.g. replace "rest.list" with a custom implementation).
+func (s *Server) defaultHandlerMap() HandlerMap {
+	return HandlerMap{
+		"auth.login":               s.handleLogin,
+		"auth.refresh":             s.handleRefreshToken,
+		"auth.createUser":          s.handleCreateUser,
+		"auth.createAPIKey":        s.handleCreateAPIKey,
+		"auth.logout":              s.handleLogout,
+		"graphql.query":            s.handleGraphQL,
+		"graphql.playground":       s.handleGraphQLOrPlayground,
+		"stats":                    s.handleStats,
+		"rest.list":                s.handleRestList,
+		"rest.insert":              s.handleRestInsert,
+		"rest.get":                 s.handleRestGet,
+		"replication.createTarget": s.handleCreateReplicationTarget,
+		"replication.listTargets":  s.handleListReplicationTargets,
+		"replication.deleteTarget": s.handleDeleteReplicationTarget,
+		"replication.createPolicy": s.handleCreateReplicationPolicy,
+		"replication.listPolicies": s.handleListReplicationPolicies,
+		"replication.runPolicy":    s.handleRunReplicationPolicy,
+		"replication.listJobs":     s.handleListReplicationJobs,
+		"config.get":               s.handleGetConfig,
+		"config.getPath":           s.handleGetConfigPath,
+		"config.patch":             s.handlePatchConfig,
+		"regions.list":             s.handleListRegions,
+		"regions.split":            s.handleSplitRegion,
 	}
 }
 
 func (s *Server) Start(addr string) error {
 	s.logger.Info("Starting LumaDB Platform Server", zap.String("addr", addr))
 
+	// Load per-role, per-collection ACLs (see pkg/platform/auth's
+	// PermissionRule) before anything starts serving requests.
+	if s.authEngine != nil {
+		if err := s.authEngine.LoadPermissions(); err != nil {
+			s.logger.Error("Failed to load permission rules", zap.Error(err))
+		}
+	}
+
 	// Start Cron
 	s.cron.Start()
 	defer s.cron.Stop()
 
+	// Start the region auto-splitter; it runs until Start returns.
+	autoSplitCtx, cancelAutoSplit := context.WithCancel(context.Background())
+	defer cancelAutoSplit()
+	go s.regions.RunAutoSplitter(autoSplitCtx, autoSplitInterval)
+
 	// Initialize Schema
 	if err := s.gqlEngine.BuildSchema(); err != nil {
 		s.logger.Error("Failed to build GraphQL schema", zap.Error(err))
@@ -48,17 +216,29 @@ func (s *Server) Start(addr string) error {
 	}
 
 	// Middleware
-	s.router.Use(corsMiddleware())
+	s.router.Use(s.corsMiddleware())
+	if s.rateLimiter != nil {
+		s.router.Use(s.rateLimiter)
+	}
+	for _, mw := range s.middleware {
+		s.router.Use(mw)
+	}
 
 	// Public Auth Routes
-	s.router.POST("/api/auth/login", s.handleLogin)
+	s.router.POST("/api/auth/login", s.HandlerMap["auth.login"])
+	s.router.POST("/api/auth/refresh", s.HandlerMap["auth.refresh"])
 
 	// Protected Routes Group
 	protected := s.router.Group("/", s.authMiddleware())
 
 	// GraphQL Endpoint (Protected)
-	protected.POST("/graphql", s.handleGraphQL)
-	protected.GET("/graphql", s.handleGraphQLOrPlayground)
+	protected.POST("/graphql", s.HandlerMap["graphql.query"])
+	protected.GET("/graphql", s.HandlerMap["graphql.playground"])
+
+	// User/API-key management and logout (see pkg/platform/auth/store)
+	protected.POST("/api/auth/users", s.HandlerMap["auth.createUser"])
+	protected.POST("/api/auth/apikeys", s.HandlerMap["auth.createAPIKey"])
+	protected.POST("/api/auth/logout", s.HandlerMap["auth.logout"])
 
 	// REST API - Auto-generated routes
 	api := s.router.Group("/api")
@@ -71,17 +251,48 @@ func (s *Server) Start(addr string) error {
 		v1 := api.Group("/v1", s.authMiddleware())
 		{
 			// Stats
-			v1.GET("/stats", s.handleStats)
+			v1.GET("/stats", s.HandlerMap["stats"])
 			// Dynamic REST endpoints
 			// GET /api/v1/:collection -> List
 			// POST /api/v1/:collection -> Insert
 			// GET /api/v1/:collection/:id -> Get
-			v1.GET("/:collection", s.handleRestList)
-			v1.POST("/:collection", s.handleRestInsert)
-			v1.GET("/:collection/:id", s.handleRestGet)
+			v1.GET("/:collection", s.HandlerMap["rest.list"])
+			v1.POST("/:collection", s.HandlerMap["rest.insert"])
+			v1.GET("/:collection/:id", s.HandlerMap["rest.get"])
+
+			// Cross-region replication
+			v1.POST("/replication/targets", s.HandlerMap["replication.createTarget"])
+			v1.GET("/replication/targets", s.HandlerMap["replication.listTargets"])
+			v1.DELETE("/replication/targets/:id", s.HandlerMap["replication.deleteTarget"])
+			v1.POST("/replication/policies", s.HandlerMap["replication.createPolicy"])
+			v1.GET("/replication/policies", s.HandlerMap["replication.listPolicies"])
+			v1.POST("/replication/policies/:name/run", s.HandlerMap["replication.runPolicy"])
+			v1.GET("/replication/jobs", s.HandlerMap["replication.listJobs"])
+
+			// Hot-reloadable config (see pkg/platform/config). PATCH
+			// changes cluster-wide behavior, so it goes through AdminGuard
+			// on top of the handler's own role check.
+			v1.GET("/config", s.HandlerMap["config.get"])
+			v1.PATCH("/config", s.AdminGuard(GuardIPAllowlist|GuardToken|GuardRole), s.HandlerMap["config.patch"])
+			v1.GET("/config/*path", s.HandlerMap["config.getPath"])
+
+			// Region placement (see pkg/cluster/region.go, autosplit.go).
+			// Splitting reshapes live shard placement, so it's guarded the
+			// same way config PATCH is.
+			v1.GET("/regions", s.HandlerMap["regions.list"])
+			v1.POST("/regions/:id/split", s.AdminGuard(GuardIPAllowlist|GuardToken|GuardRole), s.HandlerMap["regions.split"])
 		}
 	}
 
+	// Routes registered via WithRouteHandler, e.g. embedder-defined
+	// collection-scoped verbs.
+	for _, rh := range s.extraRoutes {
+		s.router.Handle(rh.method, rh.path, rh.handler)
+	}
+
+	if s.tlsCertFile != "" || s.tlsKeyFile != "" {
+		return s.router.RunTLS(addr, s.tlsCertFile, s.tlsKeyFile)
+	}
 	return s.router.Run(addr)
 }
 
@@ -97,15 +308,32 @@ func (s *Server) handleGraphQL(c *gin.Context) {
 		return
 	}
 
-	result := s.gqlEngine.Execute(c.Request.Context(), body.Query, body.Variables)
+	ctx := auth.WithClaims(c.Request.Context(), claimsFromGinContext(c))
+	result := s.gqlEngine.Execute(ctx, body.Query, body.Variables)
 	c.JSON(http.StatusOK, result)
 }
 
+// claimsFromGinContext retrieves the Claims authMiddleware set on c, or
+// nil for a route that doesn't run authMiddleware (auth.WithClaims/
+// auth.ClaimsFromContext treat a nil Claims as "no claims", the same as
+// never calling WithClaims).
+func claimsFromGinContext(c *gin.Context) *auth.Claims {
+	claims, _ := c.Get("claims")
+	cl, _ := claims.(*auth.Claims)
+	return cl
+}
+
 func (s *Server) handleGraphQLOrPlayground(c *gin.Context) {
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		s.handleGraphQLWS(c)
+		return
+	}
+
 	// If query params present, execute
 	query := c.Query("query")
 	if query != "" {
-		result := s.gqlEngine.Execute(c.Request.Context(), query, nil)
+		ctx := auth.WithClaims(c.Request.Context(), claimsFromGinContext(c))
+		result := s.gqlEngine.Execute(ctx, query, nil)
 		c.JSON(http.StatusOK, result)
 		return
 	}
@@ -177,6 +405,224 @@ func (s *Server) handleRestGet(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"collection": collection, "id": id, "data": nil})
 }
 
+func (s *Server) handleCreateReplicationTarget(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	var target replication.Target
+	if err := c.BindJSON(&target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	s.replication.AddTarget(target)
+	c.JSON(http.StatusCreated, target)
+}
+
+func (s *Server) handleListReplicationTargets(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"targets": s.replication.ListTargets()})
+}
+
+func (s *Server) handleDeleteReplicationTarget(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	s.replication.RemoveTarget(c.Param("id"))
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (s *Server) handleCreateReplicationPolicy(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	var policy replication.Policy
+	if err := c.BindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if policy.TriggeredBy == "" {
+		policy.TriggeredBy = replication.TriggerManual
+	}
+	if err := s.replication.AddPolicy(policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, policy)
+}
+
+func (s *Server) handleListReplicationPolicies(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policies": s.replication.ListPolicies()})
+}
+
+func (s *Server) handleRunReplicationPolicy(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	if err := s.replication.Run(c.Param("name"), replication.TriggerManual); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "completed"})
+}
+
+func (s *Server) handleListReplicationJobs(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": s.replication.Jobs(c.Query("policy"))})
+}
+
+// handleGetConfig returns the whole config document plus a fingerprint
+// callers must echo back in PATCH /api/v1/config to detect concurrent
+// changes.
+func (s *Server) handleGetConfig(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	raw, err := s.configHandler.Marshal()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "application/json")
+	c.Writer.Write(raw)
+	c.Header("X-Config-Fingerprint", s.configHandler.Fingerprint())
+}
+
+// handleGetConfigPath returns the value at an RFC 6901 JSON pointer path,
+// e.g. GET /api/v1/config/tiering/hot_policy/enabled.
+func (s *Server) handleGetConfigPath(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	raw, err := s.configHandler.MarshalJSONPath(c.Param("path"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "application/json")
+	c.Writer.Write(raw)
+}
+
+// handlePatchConfig applies a partial update at path if provided, or
+// replaces the whole document, guarded by an optimistic-concurrency
+// fingerprint so two concurrent PATCHes can't silently clobber each other.
+func (s *Server) handlePatchConfig(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	var body struct {
+		Fingerprint string          `json:"fingerprint"`
+		Path        string          `json:"path"`
+		Data        json.RawMessage `json:"data"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if body.Fingerprint != "" && body.Fingerprint != s.configHandler.Fingerprint() {
+		c.JSON(http.StatusConflict, gin.H{"error": pconfig.ErrFingerprintMismatch.Error()})
+		return
+	}
+
+	var err error
+	if body.Path != "" {
+		err = s.configHandler.UnmarshalJSONPath(body.Path, body.Data)
+	} else {
+		err = s.configHandler.Unmarshal(body.Data)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"fingerprint": s.configHandler.Fingerprint()})
+}
+
+// handleListRegions returns every region RegionManager knows about, along
+// with the auto-splitter's configured thresholds - the scheduler
+// decisions the auto-splitter goroutine (see pkg/cluster/autosplit.go)
+// acts on.
+func (s *Server) handleListRegions(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"regions": s.regions.ListRegions(),
+		"thresholds": gin.H{
+			"max_region_size_bytes": s.regions.MaxRegionSizeBytes,
+			"max_writes_per_minute": s.regions.MaxWritesPerMinute,
+		},
+	})
+}
+
+// handleSplitRegion manually splits a region at splitKey, the same
+// operation the auto-splitter performs at its sampled mid-key.
+func (s *Server) handleSplitRegion(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	regionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid region id"})
+		return
+	}
+
+	var body struct {
+		SplitKey string `json:"split_key"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	left, right, err := s.regions.SplitRegion(regionID, []byte(body.SplitKey))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"left": left, "right": right})
+}
+
+// handleLogin authenticates against s.authEngine.Store() when one is
+// configured (see platform/auth/store and WithAuthStore), falling back to
+// the historical hardcoded admin/password pair when it isn't, so
+// embedders that haven't wired up a database yet don't lose login
+// entirely.
 func (s *Server) handleLogin(c *gin.Context) {
 	var creds struct {
 		Username string `json:"username"`
@@ -188,20 +634,134 @@ func (s *Server) handleLogin(c *gin.Context) {
 		return
 	}
 
-	// Mock User Validation (In real world, check DB)
+	if st := s.authEngine.Store(); st != nil {
+		user, err := st.Authenticate(creds.Username, creds.Password)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+			return
+		}
+		access, refresh, err := s.authEngine.GenerateTokenPair(user.ID.String(), user.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"token": access, "refresh_token": refresh})
+		return
+	}
+
+	// Mock User Validation (no store configured)
 	if creds.Username == "admin" && creds.Password == "password" {
-		token, err := s.authEngine.GenerateToken("admin-user-id", "admin")
+		access, refresh, err := s.authEngine.GenerateTokenPair("admin-user-id", "admin")
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"token": token})
+		c.JSON(http.StatusOK, gin.H{"token": access, "refresh_token": refresh})
 		return
 	}
 
 	c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 }
 
+// handleRefreshToken exchanges a refresh token (from handleLogin or a
+// prior call to this handler) for a new access/refresh pair, rotating
+// out the presented refresh token so it can't be reused.
+func (s *Server) handleRefreshToken(c *gin.Context) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.BindJSON(&body); err != nil || body.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+
+	access, refresh, err := s.authEngine.Refresh(body.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": access, "refresh_token": refresh})
+}
+
+// handleCreateUser creates a new auth_users row. Requires
+// platform/auth/store to be configured (see WithAuthStore).
+func (s *Server) handleCreateUser(c *gin.Context) {
+	role := c.GetString("role")
+	if !s.authEngine.IsAuthorized(role, auth.ActionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	st := s.authEngine.Store()
+	if st == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No auth store configured; pass --database-url"})
+		return
+	}
+
+	var body struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := st.CreateUser(body.Username, body.Password, body.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
+
+// handleCreateAPIKey mints a new API key for the caller's own account
+// (the user_id authMiddleware put in context from the bearer token).
+func (s *Server) handleCreateAPIKey(c *gin.Context) {
+	st := s.authEngine.Store()
+	if st == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No auth store configured; pass --database-url"})
+		return
+	}
+
+	userID, err := uuid.FromString(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Caller has no database-backed user ID"})
+		return
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plaintext, rec, err := st.CreateAPIKey(userID, body.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"key": plaintext, "api_key": rec})
+}
+
+// handleLogout revokes the jti of the token used to authenticate this
+// request, so it's rejected by ValidateToken on any later request even
+// though it hasn't expired yet.
+func (s *Server) handleLogout(c *gin.Context) {
+	claims, ok := c.MustGet("claims").(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No claims in context"})
+		return
+	}
+	if err := s.authEngine.Revoke(claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
+
 func (s *Server) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -226,14 +786,23 @@ func (s *Server) authMiddleware() gin.HandlerFunc {
 		// Inject user info into context
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
 
-func corsMiddleware() gin.HandlerFunc {
+// corsMiddleware reads allowed origins from s.configHandler on every
+// request, so a hot-reloaded config (PATCH /api/v1/config) takes effect
+// without restarting the server.
+func (s *Server) corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		origins := s.configHandler.Config().CORSAllowedOrigins
+		origin := strings.Join(origins, ", ")
+		if len(origins) == 1 {
+			origin = origins[0]
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, PATCH")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
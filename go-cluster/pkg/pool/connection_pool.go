@@ -33,6 +33,12 @@ type PoolConfig struct {
 	MaxLifetime     time.Duration // Max lifetime of a connection
 	AcquireTimeout  time.Duration // Timeout for acquiring a connection
 	HealthCheckPeriod time.Duration // Period between health checks
+
+	// FactoryKey identifies the logical resource a factory call would
+	// dial for ctx, so concurrent misses for the same resource coalesce
+	// into one factory call instead of one per caller (see Pool.Acquire).
+	// Nil uses a single key shared by every call.
+	FactoryKey func(ctx context.Context) string
 }
 
 // DefaultPoolConfig returns sensible defaults
@@ -66,6 +72,11 @@ type Pool struct {
 	size     int32  // Current number of connections
 	closed   int32
 
+	// sf coalesces concurrent factory calls made for the same
+	// FactoryKey, so a burst of misses opens one new connection instead
+	// of one per caller (see Acquire).
+	sf singleflightGroup
+
 	stopCh   chan struct{}
 	wg       sync.WaitGroup
 }
@@ -141,18 +152,24 @@ func (p *Pool) Acquire(ctx context.Context) (Connection, error) {
 		atomic.AddInt32(&p.size, -1)
 	}
 
-	// No available connections, try to create new one
+	// No available connections: coalesce concurrent factory calls for the
+	// same FactoryKey behind a singleflight, so a burst of misses opens
+	// one new connection instead of one per caller. Do only decides
+	// whether to dial - the resulting connection goes back into the idle
+	// pool (or straight to a waiter) rather than to every caller that
+	// shared the call, so each caller still takes its own independent
+	// handle via the retry below.
 	currentSize := atomic.LoadInt32(&p.size)
 	if int(currentSize) < p.config.MaxSize {
-		atomic.AddInt32(&p.size, 1)
 		p.mu.Unlock()
 
-		conn, err := p.factory(ctx)
+		_, err, _ := p.sf.Do(p.factoryKey(ctx), func() (interface{}, error) {
+			return nil, p.createConn(ctx)
+		})
 		if err != nil {
-			atomic.AddInt32(&p.size, -1)
 			return nil, err
 		}
-		return conn, nil
+		return p.Acquire(ctx)
 	}
 
 	// Pool is at capacity, wait for a connection
@@ -285,6 +302,49 @@ type PoolStats struct {
 	Closed  bool
 }
 
+// createConn dials a new connection via the factory and hands it to the
+// first waiter if one is queued, otherwise adds it to the idle pool. It
+// reserves the connection's size slot before dialing so the capacity
+// check in Acquire stays honest across the (singleflighted) concurrent
+// callers that reach this point.
+func (p *Pool) createConn(ctx context.Context) error {
+	atomic.AddInt32(&p.size, 1)
+
+	conn, err := p.factory(ctx)
+	if err != nil {
+		atomic.AddInt32(&p.size, -1)
+		return err
+	}
+
+	pc := &pooledConn{
+		conn:      conn,
+		createdAt: time.Now(),
+		lastUsed:  time.Now(),
+	}
+
+	p.mu.Lock()
+	if len(p.waiting) > 0 {
+		waitCh := p.waiting[0]
+		p.waiting = p.waiting[1:]
+		p.mu.Unlock()
+		waitCh <- pc
+		return nil
+	}
+	p.conns = append(p.conns, pc)
+	p.mu.Unlock()
+	return nil
+}
+
+// factoryKey returns the singleflight key for a factory call made on
+// behalf of ctx, defaulting to a single shared key when the pool wasn't
+// configured with one.
+func (p *Pool) factoryKey(ctx context.Context) string {
+	if p.config.FactoryKey != nil {
+		return p.config.FactoryKey(ctx)
+	}
+	return "default"
+}
+
 func (p *Pool) isValidConnection(pc *pooledConn) bool {
 	// Check lifetime
 	if p.config.MaxLifetime > 0 && time.Since(pc.createdAt) > p.config.MaxLifetime {
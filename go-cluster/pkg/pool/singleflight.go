@@ -0,0 +1,50 @@
+package pool
+
+import "sync"
+
+// singleflightGroup coalesces concurrent calls for the same key into a
+// single call, handing every waiter that call's result - the same
+// pattern go-redis's internal singleflight package uses to dedupe
+// concurrent dials for the same logical connection. It's deliberately
+// the minimal subset of golang.org/x/sync/singleflight.Group that Pool
+// needs, rather than a new module dependency.
+type singleflightGroup struct {
+	mu sync.Mutex
+	m  map[string]*singleflightCall
+}
+
+type singleflightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// Do executes fn for key, unless a call for key is already in flight, in
+// which case it waits for that call instead of running fn again and
+// returns its result. The third return reports whether the result came
+// from such a shared, in-flight call rather than this caller's own fn.
+func (g *singleflightGroup) Do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*singleflightCall)
+	}
+	if c, ok := g.m[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+
+	c := new(singleflightCall)
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.m, key)
+	g.mu.Unlock()
+
+	return c.val, c.err, false
+}
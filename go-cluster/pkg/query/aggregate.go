@@ -0,0 +1,255 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mergeOp is how one aggregated output field combines the partial
+// values returned by each shard.
+type mergeOp int
+
+const (
+	// mergeAdd sums the partial values across shards. COUNT and SUM
+	// both reduce to this: a shard-local COUNT is just a partial sum
+	// the gather step adds up like any other SUM.
+	mergeAdd mergeOp = iota
+	mergeMin
+	mergeMax
+	// mergeAvg combines a SumField/CountField pair produced by splitting
+	// AVG(x) into SUM(x) and COUNT(x) on the scatter phase, dividing
+	// their merged totals once all shards have reported.
+	mergeAvg
+)
+
+// mergeField describes how to recombine one output column of an
+// aggregation query from the partial values every shard returns.
+type mergeField struct {
+	// Output is the field name the merged document carries this
+	// aggregator's value under.
+	Output string
+	Op     mergeOp
+	// PartialField is the field holding this aggregator's shard-local
+	// value, for mergeAdd/mergeMin/mergeMax.
+	PartialField string
+	// SumField/CountField are the partial field names for mergeAvg.
+	SumField   string
+	CountField string
+}
+
+// mergeDescriptor is the Planner's recipe for recombining the partial
+// aggregates each shard returns into one row per group. It travels on
+// the Plan alongside the rewritten scatter-phase Query.
+type mergeDescriptor struct {
+	GroupBy []string
+	Fields  []mergeField
+}
+
+// rewriteAggregatePlan turns sel into the partial-aggregate form each
+// shard runs locally, plus the mergeDescriptor the gather step needs to
+// recombine their results: COUNT(*) and SUM(x) stay as-is (a shard's
+// partial count/sum merges by summing), MIN/MAX stay as-is (merging by
+// min/max), and AVG(x) splits into SUM(x) and COUNT(x) so the true
+// average can be computed from their merged totals rather than
+// averaging shard-local averages.
+func rewriteAggregatePlan(sel *Select) (*Select, *mergeDescriptor) {
+	partial := &Select{
+		From:    sel.From,
+		Where:   sel.Where,
+		GroupBy: sel.GroupBy,
+	}
+	desc := &mergeDescriptor{GroupBy: sel.GroupBy}
+
+	for _, f := range sel.Fields {
+		if f.Aggregate == nil {
+			// A plain column (e.g. a GROUP BY field also projected)
+			// passes through untouched; it's part of the group key.
+			partial.Fields = append(partial.Fields, f)
+			continue
+		}
+
+		agg := f.Aggregate
+		output := f.Alias
+		if output == "" {
+			output = defaultAggregateAlias(agg)
+		}
+
+		switch strings.ToUpper(agg.Func) {
+		case "AVG":
+			sumField := output + "__sum"
+			countField := output + "__count"
+			partial.Fields = append(partial.Fields,
+				Field{Aggregate: &Aggregate{Func: "SUM", Field: agg.Field}, Alias: sumField},
+				Field{Aggregate: &Aggregate{Func: "COUNT", Field: agg.Field}, Alias: countField},
+			)
+			desc.Fields = append(desc.Fields, mergeField{
+				Output: output, Op: mergeAvg, SumField: sumField, CountField: countField,
+			})
+		case "MIN":
+			partial.Fields = append(partial.Fields, Field{Aggregate: agg, Alias: output})
+			desc.Fields = append(desc.Fields, mergeField{Output: output, Op: mergeMin, PartialField: output})
+		case "MAX":
+			partial.Fields = append(partial.Fields, Field{Aggregate: agg, Alias: output})
+			desc.Fields = append(desc.Fields, mergeField{Output: output, Op: mergeMax, PartialField: output})
+		default: // COUNT, SUM
+			partial.Fields = append(partial.Fields, Field{Aggregate: agg, Alias: output})
+			desc.Fields = append(desc.Fields, mergeField{Output: output, Op: mergeAdd, PartialField: output})
+		}
+	}
+
+	return partial, desc
+}
+
+func defaultAggregateAlias(agg *Aggregate) string {
+	field := agg.Field
+	if field == "*" {
+		field = "star"
+	}
+	return fmt.Sprintf("%s_%s", strings.ToLower(agg.Func), field)
+}
+
+// nullGroupKey is the key every document missing one of the GROUP BY
+// fields (or holding an explicit NULL there) collapses onto, so NULL
+// group values form one group instead of one per shard.
+const nullGroupKey = "\x00"
+
+// groupKeySeparator joins per-field key parts; chosen to be vanishingly
+// unlikely to appear in an actual field value.
+const groupKeySeparator = "\x1f"
+
+func groupKey(doc map[string]interface{}, groupBy []string) string {
+	parts := make([]string, len(groupBy))
+	for i, field := range groupBy {
+		v, ok := doc[field]
+		if !ok || v == nil {
+			parts[i] = nullGroupKey
+		} else {
+			parts[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	return strings.Join(parts, groupKeySeparator)
+}
+
+// groupState accumulates one group's merged aggregates across shards.
+type groupState struct {
+	groupValues map[string]interface{}
+	sums        map[string]float64
+	mins        map[string]interface{}
+	maxes       map[string]interface{}
+}
+
+// mergeAggregates combines the partial-aggregate documents every shard
+// returned (docs) into one document per group, per desc. A shard that
+// returned no rows for a group simply contributes nothing, so an empty
+// shard result can't manufacture a phantom group.
+func mergeAggregates(docs []interface{}, desc *mergeDescriptor) *Result {
+	groups := make(map[string]*groupState)
+	order := make([]string, 0)
+
+	for _, raw := range docs {
+		doc, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		key := groupKey(doc, desc.GroupBy)
+		g, exists := groups[key]
+		if !exists {
+			g = &groupState{
+				groupValues: make(map[string]interface{}, len(desc.GroupBy)),
+				sums:        make(map[string]float64),
+				mins:        make(map[string]interface{}),
+				maxes:       make(map[string]interface{}),
+			}
+			for _, field := range desc.GroupBy {
+				g.groupValues[field] = doc[field]
+			}
+			groups[key] = g
+			order = append(order, key)
+		}
+
+		for _, mf := range desc.Fields {
+			switch mf.Op {
+			case mergeAdd:
+				g.sums[mf.Output] += toFloat(doc[mf.PartialField])
+			case mergeMin:
+				g.mins[mf.Output] = minValue(g.mins[mf.Output], doc[mf.PartialField])
+			case mergeMax:
+				g.maxes[mf.Output] = maxValue(g.maxes[mf.Output], doc[mf.PartialField])
+			case mergeAvg:
+				g.sums[mf.SumField] += toFloat(doc[mf.SumField])
+				g.sums[mf.CountField] += toFloat(doc[mf.CountField])
+			}
+		}
+	}
+
+	documents := make([]interface{}, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		out := make(map[string]interface{}, len(desc.GroupBy)+len(desc.Fields))
+		for field, v := range g.groupValues {
+			out[field] = v
+		}
+		for _, mf := range desc.Fields {
+			switch mf.Op {
+			case mergeAdd:
+				out[mf.Output] = g.sums[mf.Output]
+			case mergeMin:
+				out[mf.Output] = g.mins[mf.Output]
+			case mergeMax:
+				out[mf.Output] = g.maxes[mf.Output]
+			case mergeAvg:
+				count := g.sums[mf.CountField]
+				if count == 0 {
+					out[mf.Output] = nil
+				} else {
+					out[mf.Output] = g.sums[mf.SumField] / count
+				}
+			}
+		}
+		documents = append(documents, out)
+	}
+
+	return &Result{Documents: documents, Count: len(documents)}
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	default:
+		return 0
+	}
+}
+
+func minValue(current, v interface{}) interface{} {
+	if current == nil {
+		return v
+	}
+	if v == nil {
+		return current
+	}
+	if toFloat(v) < toFloat(current) {
+		return v
+	}
+	return current
+}
+
+func maxValue(current, v interface{}) interface{} {
+	if current == nil {
+		return v
+	}
+	if v == nil {
+		return current
+	}
+	if toFloat(v) > toFloat(current) {
+		return v
+	}
+	return current
+}
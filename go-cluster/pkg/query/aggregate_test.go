@@ -0,0 +1,138 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestRewriteAggregatePlan_AvgSplitsIntoSumAndCount(t *testing.T) {
+	sel := &Select{
+		Fields:  []Field{{Aggregate: &Aggregate{Func: "AVG", Field: "price"}, Alias: "avg_price"}},
+		From:    "orders",
+		GroupBy: []string{"region"},
+	}
+
+	partial, desc := rewriteAggregatePlan(sel)
+
+	if len(partial.Fields) != 2 {
+		t.Fatalf("expected AVG to split into 2 partial fields, got %d", len(partial.Fields))
+	}
+	if partial.Fields[0].Aggregate.Func != "SUM" || partial.Fields[1].Aggregate.Func != "COUNT" {
+		t.Errorf("expected SUM then COUNT, got %s then %s", partial.Fields[0].Aggregate.Func, partial.Fields[1].Aggregate.Func)
+	}
+
+	if len(desc.Fields) != 1 || desc.Fields[0].Op != mergeAvg {
+		t.Fatalf("expected one mergeAvg field, got %+v", desc.Fields)
+	}
+	if desc.Fields[0].SumField != partial.Fields[0].Alias || desc.Fields[0].CountField != partial.Fields[1].Alias {
+		t.Error("mergeField Sum/CountField must match the partial SELECT's aliases")
+	}
+}
+
+func TestMergeAggregates_SumAndCount(t *testing.T) {
+	desc := &mergeDescriptor{
+		GroupBy: []string{"region"},
+		Fields: []mergeField{
+			{Output: "total", Op: mergeAdd, PartialField: "total"},
+			{Output: "cnt", Op: mergeAdd, PartialField: "cnt"},
+		},
+	}
+
+	docs := []interface{}{
+		map[string]interface{}{"region": "east", "total": float64(10), "cnt": float64(2)},
+		map[string]interface{}{"region": "east", "total": float64(5), "cnt": float64(1)},
+		map[string]interface{}{"region": "west", "total": float64(7), "cnt": float64(1)},
+	}
+
+	res := mergeAggregates(docs, desc)
+	if res.Count != 2 {
+		t.Fatalf("expected 2 groups, got %d", res.Count)
+	}
+
+	byRegion := map[string]map[string]interface{}{}
+	for _, d := range res.Documents {
+		m := d.(map[string]interface{})
+		byRegion[m["region"].(string)] = m
+	}
+
+	if byRegion["east"]["total"] != float64(15) || byRegion["east"]["cnt"] != float64(3) {
+		t.Errorf("expected east total=15 cnt=3, got %+v", byRegion["east"])
+	}
+	if byRegion["west"]["total"] != float64(7) {
+		t.Errorf("expected west total=7, got %+v", byRegion["west"])
+	}
+}
+
+func TestMergeAggregates_Avg(t *testing.T) {
+	desc := &mergeDescriptor{
+		GroupBy: []string{"region"},
+		Fields: []mergeField{
+			{Output: "avg_price", Op: mergeAvg, SumField: "avg_price__sum", CountField: "avg_price__count"},
+		},
+	}
+
+	docs := []interface{}{
+		map[string]interface{}{"region": "east", "avg_price__sum": float64(100), "avg_price__count": float64(2)},
+		map[string]interface{}{"region": "east", "avg_price__sum": float64(50), "avg_price__count": float64(1)},
+	}
+
+	res := mergeAggregates(docs, desc)
+	m := res.Documents[0].(map[string]interface{})
+	if m["avg_price"] != float64(50) {
+		t.Errorf("expected avg_price=50 (150/3), got %v", m["avg_price"])
+	}
+}
+
+func TestMergeAggregates_MinMax(t *testing.T) {
+	desc := &mergeDescriptor{
+		Fields: []mergeField{
+			{Output: "min_age", Op: mergeMin, PartialField: "min_age"},
+			{Output: "max_age", Op: mergeMax, PartialField: "max_age"},
+		},
+	}
+
+	docs := []interface{}{
+		map[string]interface{}{"min_age": float64(30), "max_age": float64(30)},
+		map[string]interface{}{"min_age": float64(18), "max_age": float64(65)},
+	}
+
+	res := mergeAggregates(docs, desc)
+	m := res.Documents[0].(map[string]interface{})
+	if m["min_age"] != float64(18) {
+		t.Errorf("expected min_age=18, got %v", m["min_age"])
+	}
+	if m["max_age"] != float64(65) {
+		t.Errorf("expected max_age=65, got %v", m["max_age"])
+	}
+}
+
+func TestMergeAggregates_NullGroupValuesCollapseToOneGroup(t *testing.T) {
+	desc := &mergeDescriptor{
+		GroupBy: []string{"region"},
+		Fields:  []mergeField{{Output: "cnt", Op: mergeAdd, PartialField: "cnt"}},
+	}
+
+	docs := []interface{}{
+		map[string]interface{}{"cnt": float64(1)}, // region missing entirely
+		map[string]interface{}{"region": nil, "cnt": float64(2)},
+	}
+
+	res := mergeAggregates(docs, desc)
+	if res.Count != 1 {
+		t.Fatalf("expected NULL/missing region to collapse into 1 group, got %d", res.Count)
+	}
+	if res.Documents[0].(map[string]interface{})["cnt"] != float64(3) {
+		t.Errorf("expected merged cnt=3, got %+v", res.Documents[0])
+	}
+}
+
+func TestMergeAggregates_EmptyShardResultNoPhantomGroups(t *testing.T) {
+	desc := &mergeDescriptor{
+		GroupBy: []string{"region"},
+		Fields:  []mergeField{{Output: "cnt", Op: mergeAdd, PartialField: "cnt"}},
+	}
+
+	res := mergeAggregates([]interface{}{}, desc)
+	if res.Count != 0 {
+		t.Errorf("expected no groups from an empty shard result, got %d", res.Count)
+	}
+}
@@ -2,7 +2,10 @@ package query
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"strings"
 	"sync"
 )
 
@@ -17,16 +20,29 @@ type Result struct {
 type ClusterClient interface {
 	ExecuteRemote(ctx context.Context, nodeAddr string, stmt *Statement) (*Result, error)
 	ExecuteLocal(ctx context.Context, stmt *Statement) (*Result, error)
+
+	// Peers returns the addresses of every node fanOut should broadcast
+	// to when a plan's Shards is the "*" wildcard.
+	Peers(ctx context.Context) ([]string, error)
+
+	// ShardPeers returns the Raft-group replica addresses (including
+	// addr itself) for whichever shard addr serves, so fanOut can retry
+	// a transient failure against a different replica of that shard.
+	ShardPeers(ctx context.Context, addr string) ([]string, error)
 }
 
 // Executor executes a query plan
 type Executor struct {
-	client ClusterClient
+	client     ClusterClient
+	embeddings EmbeddingProvider
 }
 
-// NewExecutor creates a new executor
-func NewExecutor(client ClusterClient) *Executor {
-	return &Executor{client: client}
+// NewExecutor creates a new executor. embeddings may be nil if the
+// caller never plans to run a VECTOR_SEARCH/SIMILAR_TO query; doing so
+// against a nil embeddings then fails with a clear error instead of a
+// panic (see executeVectorSearch).
+func NewExecutor(client ClusterClient, embeddings EmbeddingProvider) *Executor {
+	return &Executor{client: client, embeddings: embeddings}
 }
 
 // Execute runs the plan
@@ -40,6 +56,8 @@ func (e *Executor) Execute(ctx context.Context, plan *Plan) (*Result, error) {
 		return e.executeAggregation(ctx, plan)
 	case PlanTypeJoin:
 		return e.executeJoin(ctx, plan)
+	case PlanTypeVectorSearch:
+		return e.executeVectorSearch(ctx, plan)
 	}
 	return nil, fmt.Errorf("unknown plan type")
 }
@@ -58,165 +76,341 @@ func (e *Executor) executePointLookup(ctx context.Context, plan *Plan) (*Result,
 }
 
 func (e *Executor) executeScatterGather(ctx context.Context, plan *Plan) (*Result, error) {
-	// Broadcast to all shards (simulated by plan.Shards containing "*")
-	// Real implementation: resolve "*" to actual addresses, or rely on client to know broadcast peers
+	return e.fanOut(ctx, plan.Shards, plan.Query)
+}
 
-	// For MVP, we assume client knows how to Broadcast if we pass specific flag or list
-	// Or we iterate here if we had the list.
-	// Let's assume we get a list of addresses from the plan (populated by Planner in real world)
-	// Since Planner put "*", we need to resolve it or let client handle.
-	// Let's assume strict separation and say Planner should have populated actual IPs.
-	// Since it didn't (MVP), we'll assume client.Broadcast() exists or similar.
-	// Let's abstract this:
+func (e *Executor) executeAggregation(ctx context.Context, plan *Plan) (*Result, error) {
+	// Scatter: each shard runs plan.Query, which the Planner already
+	// rewrote into its partial-aggregate form (see rewriteAggregatePlan).
+	results, err := e.fanOut(ctx, plan.Shards, plan.Query)
+	if err != nil {
+		return nil, err
+	}
 
-	// We will perform naive scatter-gather here assuming plan.Shards has real addresses
-	// If it has "*", we fail for now, or update Planner to provide IDs.
+	// Gather: combine the partials per group. plan.Merge is nil only if
+	// CreatePlan somehow produced a PlanTypeAggregation without it, in
+	// which case there's nothing to merge.
+	if plan.Merge == nil {
+		return results, nil
+	}
+	return mergeAggregates(results.Documents, plan.Merge), nil
+}
 
-	// Update: Planner provided "*". Let's assume Planner injects "localhost" and other peers.
-	// Since we don't have that yet, let's just make it compilable.
+// executeVectorSearch embeds plan.Vector's query text once on the
+// coordinator, ships the resulting vector (not the text) to every shard
+// as part of the gob-encoded Statement, and merges each shard's
+// self-reported top-k (see vectorScoreField) into the global top-k.
+func (e *Executor) executeVectorSearch(ctx context.Context, plan *Plan) (*Result, error) {
+	if plan.Vector == nil {
+		return nil, fmt.Errorf("vector search plan missing its VectorSearch spec")
+	}
+	if e.embeddings == nil {
+		return nil, fmt.Errorf("vector search requires an embedding provider")
+	}
 
-	return &Result{Count: 0, Documents: []interface{}{}}, nil
-}
+	vec, err := e.embeddings.GetEmbedding(plan.Vector.QueryText)
+	if err != nil {
+		return nil, fmt.Errorf("embedding query text: %w", err)
+	}
+	applyQueryVector(plan.Query, vec)
 
-func (e *Executor) executeAggregation(ctx context.Context, plan *Plan) (*Result, error) {
-	// 1. Scatter: Broadcast query to all nodes
-	// Assume shards=["*"] means all nodes
-	// In MVP, we use a fixed list of peers or let fanOut handle discovery
+	targetNodes := plan.Shards
+	if len(targetNodes) > 0 && targetNodes[0] == "*" {
+		peers, err := e.client.Peers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolving peers for vector search: %w", err)
+		}
+		targetNodes = peers
+	}
 
-	// Create a modified query for the shards if needed (e.g., partial aggregates)
-	// For MVP, we send the full GROUP BY query. Each shard returns groups.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	results, err := e.fanOut(ctx, plan.Shards, plan.Query)
-	if err != nil {
-		return nil, err
+	type shardOutcome struct {
+		res *Result
+		err error
 	}
+	outcomes := make(chan shardOutcome, fanOutChanCapacity)
 
-	// 2. Gather & Merge
-	// We need to merge results with same Group Key.
-	// Map[GroupKey] -> PartialAgg
+	var wg sync.WaitGroup
+	for _, node := range targetNodes {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			res, err := e.executeOnShard(ctx, addr, plan.Query)
+			select {
+			case outcomes <- shardOutcome{res: res, err: err}:
+			case <-ctx.Done():
+			}
+		}(node)
+	}
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	var results []*Result
+	for o := range outcomes {
+		if o.err != nil {
+			cancel()
+			return nil, o.err
+		}
+		results = append(results, o.res)
+	}
 
-	// Simply concat all docs for now if no real merge logic
-	// Real impl: Look at plan.Query.Select.Fields to determine Aggregation Func
+	return mergeTopK(results, vectorScoreField, plan.Vector.K), nil
+}
 
-	return results, nil
+// joinBuildEntry is one row on the hash-table (build) side of a join,
+// tracking whether it has been matched so LEFT/RIGHT/FULL can still emit
+// it (with nulls for the other side) if it never was.
+type joinBuildEntry struct {
+	doc     map[string]interface{}
+	matched bool
 }
 
+// executeJoin runs plan's two SubPlans and combines them via a classic
+// hash join: the smaller side (per RowCountEstimate, when known) builds
+// an in-memory hash table keyed by its join column, and the other side
+// probes it. plan.Join's Type controls which side's unmatched rows still
+// appear in the output (with nulls for the other side) per standard
+// LEFT/RIGHT/FULL OUTER semantics.
 func (e *Executor) executeJoin(ctx context.Context, plan *Plan) (*Result, error) {
-	if len(plan.SubPlans) < 2 {
-		return nil, fmt.Errorf("join requires at least two subplans")
+	if len(plan.SubPlans) != 2 {
+		return nil, fmt.Errorf("join requires exactly two subplans")
+	}
+	if plan.Join == nil {
+		return nil, fmt.Errorf("join plan missing its JoinSpec")
+	}
+
+	leftPlan, rightPlan := plan.SubPlans[0], plan.SubPlans[1]
+	buildPlan, probePlan, buildIsLeft := leftPlan, rightPlan, true
+	if leftPlan.RowCountEstimate > 0 && rightPlan.RowCountEstimate > 0 &&
+		rightPlan.RowCountEstimate < leftPlan.RowCountEstimate {
+		buildPlan, probePlan, buildIsLeft = rightPlan, leftPlan, false
+	}
+
+	buildKey, probeKey := plan.Join.LeftKey, plan.Join.RightKey
+	if !buildIsLeft {
+		buildKey, probeKey = plan.Join.RightKey, plan.Join.LeftKey
 	}
 
-	// 1. Build Phase (Left Table - should be the smaller one ideally)
-	leftPlan := plan.SubPlans[0]
-	leftRes, err := e.Execute(ctx, leftPlan)
+	buildRes, err := e.Execute(ctx, buildPlan)
+	if err != nil {
+		return nil, err
+	}
+	probeRes, err := e.Execute(ctx, probePlan)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build Hash Table: JoinKey -> []Document
-	// Assumption: Join Key is "id" for MVP, or specified in plan
-	// joinKey := plan.Query.Select.Joins[0].On.Left
-	joinKey := "id" // MVP Hardcode
-
-	hashTable := make(map[string][]interface{})
-	for _, doc := range leftRes.Documents {
-		if docMap, ok := doc.(map[string]interface{}); ok {
-			if keyVal, ok := docMap[joinKey]; ok {
-				keyStr := fmt.Sprintf("%v", keyVal)
-				hashTable[keyStr] = append(hashTable[keyStr], doc)
-			}
+	// Build phase: hash every build-side row by its join key, keeping
+	// rows missing that key too (so they can still surface as unmatched).
+	entries := make([]*joinBuildEntry, 0, len(buildRes.Documents))
+	hashTable := make(map[string][]*joinBuildEntry)
+	for _, doc := range buildRes.Documents {
+		docMap, ok := doc.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entry := &joinBuildEntry{doc: docMap}
+		entries = append(entries, entry)
+		if keyVal, ok := docMap[buildKey]; ok {
+			k := fmt.Sprintf("%v", keyVal)
+			hashTable[k] = append(hashTable[k], entry)
 		}
 	}
 
-	// 2. Probe Phase (Right Table)
-	rightPlan := plan.SubPlans[1]
-	rightRes, err := e.Execute(ctx, rightPlan)
-	if err != nil {
-		return nil, err
+	// rowPair resolves a (build-side doc, probe-side doc) match - either
+	// may be nil, for an outer join's unmatched row - into the
+	// (leftDoc, rightDoc) order projectJoinRow expects.
+	rowPair := func(buildDoc, probeDoc map[string]interface{}) (left, right map[string]interface{}) {
+		if buildIsLeft {
+			return buildDoc, probeDoc
+		}
+		return probeDoc, buildDoc
 	}
 
 	finalDocs := []interface{}{}
-	rightJoinKey := "user_id" // MVP: Assumes joining on user_id foreign key
 
-	for _, rDoc := range rightRes.Documents {
-		rDocMap, ok := rDoc.(map[string]interface{})
+	// Probe phase.
+	for _, raw := range probeRes.Documents {
+		probeDoc, ok := raw.(map[string]interface{})
 		if !ok {
 			continue
 		}
 
-		if keyVal, ok := rDocMap[rightJoinKey]; ok {
-			keyStr := fmt.Sprintf("%v", keyVal)
-			// Look up in Hash Table
-			if matches, found := hashTable[keyStr]; found {
-				for _, match := range matches {
-					// Merge match (Left) and rDoc (Right)
-					merged := make(map[string]interface{})
-					if lMap, ok := match.(map[string]interface{}); ok {
-						for k, v := range lMap {
-							merged["left_"+k] = v // Prefix to avoid collision
-						}
-					}
-					for k, v := range rDocMap {
-						merged["right_"+k] = v
-					}
-					finalDocs = append(finalDocs, merged)
-				}
+		var matches []*joinBuildEntry
+		if keyVal, ok := probeDoc[probeKey]; ok {
+			matches = hashTable[fmt.Sprintf("%v", keyVal)]
+		}
+
+		if len(matches) == 0 {
+			// The probe side is right when build is left, and vice versa.
+			keepUnmatched := keepsUnmatchedLeft(plan.Join.Type)
+			if buildIsLeft {
+				keepUnmatched = keepsUnmatchedRight(plan.Join.Type)
+			}
+			if keepUnmatched {
+				left, right := rowPair(nil, probeDoc)
+				finalDocs = append(finalDocs, projectJoinRow(plan.Join.Projection, left, right))
+			}
+			continue
+		}
+
+		for _, m := range matches {
+			m.matched = true
+			left, right := rowPair(m.doc, probeDoc)
+			finalDocs = append(finalDocs, projectJoinRow(plan.Join.Projection, left, right))
+		}
+	}
+
+	// Emit unmatched build-side rows for LEFT/RIGHT/FULL.
+	var keepBuildUnmatched bool
+	if buildIsLeft {
+		keepBuildUnmatched = keepsUnmatchedLeft(plan.Join.Type)
+	} else {
+		keepBuildUnmatched = keepsUnmatchedRight(plan.Join.Type)
+	}
+	if keepBuildUnmatched {
+		for _, entry := range entries {
+			if entry.matched {
+				continue
 			}
+			left, right := rowPair(entry.doc, nil)
+			finalDocs = append(finalDocs, projectJoinRow(plan.Join.Projection, left, right))
 		}
 	}
 
 	return &Result{Documents: finalDocs, Count: len(finalDocs)}, nil
 }
 
-// ScatterHelper could go here (fan-out, fan-in)
+// fanOutChanCapacity bounds the fan-in result channel so a slow
+// coordinator applies backpressure to producer goroutines instead of
+// every shard's full result set piling up in memory at once.
+const fanOutChanCapacity = 32
+
+// fanOut broadcasts stmt to nodes (resolving the "*" wildcard via
+// e.client.Peers) and gathers their results. The first non-retryable
+// error cancels every other in-flight shard RPC and is returned as the
+// aggregate error; a retryable error (see isRetryableError) is retried
+// against the failing shard's other replicas (see ShardPeers) before
+// being treated as non-retryable.
 func (e *Executor) fanOut(ctx context.Context, nodes []string, stmt *Statement) (*Result, error) {
-	// If nodes contains "*", replace with actual peer list
-	// For MVP, if "*", we assume client knows how to handle it or we use placeholder
 	targetNodes := nodes
 	if len(nodes) > 0 && nodes[0] == "*" {
-		// e.client.GetPeers() ??
-		// Fallback: Just execute locally for test
-		targetNodes = []string{"localhost"}
+		peers, err := e.client.Peers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolving peers for scatter-gather: %w", err)
+		}
+		targetNodes = peers
 	}
 
-	var wg sync.WaitGroup
-	resultChan := make(chan *Result, len(targetNodes))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resultChan := make(chan *Result, fanOutChanCapacity)
+	errChan := make(chan error, 1)
 
+	var wg sync.WaitGroup
 	for _, node := range targetNodes {
 		wg.Add(1)
 		go func(addr string) {
 			defer wg.Done()
-			var res *Result
-			var err error
-
-			if addr == "localhost" {
-				res, err = e.client.ExecuteLocal(ctx, stmt)
-			} else {
-				res, err = e.client.ExecuteRemote(ctx, addr, stmt)
-			}
-
+			res, err := e.executeOnShard(ctx, addr, stmt)
 			if err != nil {
-				// Log error
-				fmt.Printf("Error exec on %s: %v\n", addr, err)
-				resultChan <- &Result{Error: err}
+				select {
+				case errChan <- err:
+					cancel()
+				default:
+				}
 				return
 			}
-			resultChan <- res
+			select {
+			case resultChan <- res:
+			case <-ctx.Done():
+			}
 		}(node)
 	}
 
-	wg.Wait()
-	close(resultChan)
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
 
-	// Aggregation (Fan-in)
 	finalRes := &Result{Documents: []interface{}{}}
 	for res := range resultChan {
-		if res.Error != nil {
-			continue
-		}
 		finalRes.Count += res.Count
 		finalRes.Documents = append(finalRes.Documents, res.Documents...)
 	}
 
+	select {
+	case err := <-errChan:
+		return nil, err
+	default:
+	}
+
 	return finalRes, nil
 }
+
+// executeOnShard runs stmt against addr, retrying against the shard's
+// other replicas (via ShardPeers) on a retryable error. It returns the
+// first non-retryable error encountered, or the last retryable error if
+// every replica is exhausted.
+func (e *Executor) executeOnShard(ctx context.Context, addr string, stmt *Statement) (*Result, error) {
+	res, err := e.executeOne(ctx, addr, stmt)
+	if err == nil {
+		return res, nil
+	}
+	if !isRetryableError(err) {
+		return nil, err
+	}
+
+	replicas, perr := e.client.ShardPeers(ctx, addr)
+	if perr != nil {
+		return nil, err
+	}
+
+	lastErr := err
+	for _, replica := range replicas {
+		if replica == addr {
+			continue
+		}
+		res, err := e.executeOne(ctx, replica, stmt)
+		if err == nil {
+			return res, nil
+		}
+		if !isRetryableError(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+func (e *Executor) executeOne(ctx context.Context, addr string, stmt *Statement) (*Result, error) {
+	if addr == "localhost" {
+		return e.client.ExecuteLocal(ctx, stmt)
+	}
+	return e.client.ExecuteRemote(ctx, addr, stmt)
+}
+
+// isRetryableError reports whether err looks transient (a deadline,
+// a dropped/refused connection) and therefore worth retrying against a
+// different replica of the same shard, as opposed to something that
+// will fail the same way everywhere (a schema mismatch, auth failure).
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset") || strings.Contains(msg, "connection refused")
+}
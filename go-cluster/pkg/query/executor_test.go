@@ -0,0 +1,195 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// fakeClusterClient is a test double for ClusterClient. execFns maps a
+// node address to the behavior ExecuteRemote/ExecuteLocal should have
+// for that address; an address missing from the map succeeds with an
+// empty Result.
+type fakeClusterClient struct {
+	peers      []string
+	shardPeers map[string][]string
+	execFns    map[string]func() (*Result, error)
+	calls      []string
+}
+
+func (f *fakeClusterClient) Peers(ctx context.Context) ([]string, error) {
+	return f.peers, nil
+}
+
+func (f *fakeClusterClient) ShardPeers(ctx context.Context, addr string) ([]string, error) {
+	replicas, ok := f.shardPeers[addr]
+	if !ok {
+		return nil, fmt.Errorf("no shard found serving %s", addr)
+	}
+	return replicas, nil
+}
+
+func (f *fakeClusterClient) ExecuteLocal(ctx context.Context, stmt *Statement) (*Result, error) {
+	return f.execute("localhost")
+}
+
+func (f *fakeClusterClient) ExecuteRemote(ctx context.Context, nodeAddr string, stmt *Statement) (*Result, error) {
+	return f.execute(nodeAddr)
+}
+
+func (f *fakeClusterClient) execute(addr string) (*Result, error) {
+	f.calls = append(f.calls, addr)
+	fn, ok := f.execFns[addr]
+	if !ok {
+		return &Result{Documents: []interface{}{}}, nil
+	}
+	return fn()
+}
+
+func TestFanOut_ResolvesWildcardViaPeers(t *testing.T) {
+	client := &fakeClusterClient{
+		peers: []string{"node1", "node2"},
+		execFns: map[string]func() (*Result, error){
+			"node1": func() (*Result, error) { return &Result{Count: 1, Documents: []interface{}{"a"}}, nil },
+			"node2": func() (*Result, error) { return &Result{Count: 1, Documents: []interface{}{"b"}}, nil },
+		},
+	}
+	e := NewExecutor(client, nil)
+
+	res, err := e.fanOut(context.Background(), []string{"*"}, &Statement{})
+	if err != nil {
+		t.Fatalf("fanOut failed: %v", err)
+	}
+	if res.Count != 2 {
+		t.Errorf("expected 2 documents total, got %d", res.Count)
+	}
+}
+
+func TestFanOut_NonRetryableErrorFailsWholeFanOut(t *testing.T) {
+	client := &fakeClusterClient{
+		peers: []string{"node1", "node2"},
+		execFns: map[string]func() (*Result, error){
+			"node1": func() (*Result, error) { return nil, fmt.Errorf("schema mismatch") },
+		},
+	}
+	e := NewExecutor(client, nil)
+
+	_, err := e.fanOut(context.Background(), []string{"*"}, &Statement{})
+	if err == nil {
+		t.Fatal("expected a non-retryable error to fail the whole fan-out")
+	}
+}
+
+func TestFanOut_RetryableErrorRetriesAgainstShardReplica(t *testing.T) {
+	attempts := 0
+	client := &fakeClusterClient{
+		shardPeers: map[string][]string{
+			"node1": {"node1", "node1-replica"},
+		},
+		execFns: map[string]func() (*Result, error){
+			"node1": func() (*Result, error) {
+				attempts++
+				return nil, context.DeadlineExceeded
+			},
+			"node1-replica": func() (*Result, error) {
+				return &Result{Count: 1, Documents: []interface{}{"ok"}}, nil
+			},
+		},
+	}
+	e := NewExecutor(client, nil)
+
+	res, err := e.fanOut(context.Background(), []string{"node1"}, &Statement{})
+	if err != nil {
+		t.Fatalf("expected retry against replica to succeed, got err: %v", err)
+	}
+	if res.Count != 1 {
+		t.Errorf("expected 1 document from the replica, got %d", res.Count)
+	}
+	if attempts != 1 {
+		t.Errorf("expected exactly 1 attempt against the failing node, got %d", attempts)
+	}
+}
+
+// fakeEmbeddingProvider is a test double for EmbeddingProvider.
+type fakeEmbeddingProvider struct {
+	vec   []float32
+	err   error
+	calls int
+}
+
+func (f *fakeEmbeddingProvider) GetEmbedding(text string) ([]float32, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.vec, nil
+}
+
+func TestExecuteVectorSearch_EmbedsOnceAndMergesTopK(t *testing.T) {
+	embeddings := &fakeEmbeddingProvider{vec: []float32{1, 0}}
+	client := &fakeClusterClient{
+		peers: []string{"node1", "node2"},
+		execFns: map[string]func() (*Result, error){
+			"node1": func() (*Result, error) {
+				return &Result{Documents: []interface{}{
+					map[string]interface{}{"id": "a", vectorScoreField: float64(0.9)},
+					map[string]interface{}{"id": "b", vectorScoreField: float64(0.1)},
+				}}, nil
+			},
+			"node2": func() (*Result, error) {
+				return &Result{Documents: []interface{}{
+					map[string]interface{}{"id": "c", vectorScoreField: float64(0.95)},
+				}}, nil
+			},
+		},
+	}
+	e := NewExecutor(client, embeddings)
+
+	plan := &Plan{
+		Type:   PlanTypeVectorSearch,
+		Shards: []string{"*"},
+		Query:  &Statement{Select: &Select{From: "docs"}},
+		Vector: &vectorSearchSpec{QueryText: "hello", K: 2},
+	}
+
+	res, err := e.executeVectorSearch(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("executeVectorSearch failed: %v", err)
+	}
+	if embeddings.calls != 1 {
+		t.Errorf("expected GetEmbedding to be called exactly once, got %d", embeddings.calls)
+	}
+	if res.Count != 2 {
+		t.Fatalf("expected top-2 results, got %d", res.Count)
+	}
+	first := res.Documents[0].(map[string]interface{})
+	if first["id"] != "c" {
+		t.Errorf("expected highest-scored doc first, got %v", first["id"])
+	}
+}
+
+func TestExecuteVectorSearch_RequiresEmbeddingProvider(t *testing.T) {
+	e := NewExecutor(&fakeClusterClient{}, nil)
+	plan := &Plan{
+		Type:   PlanTypeVectorSearch,
+		Shards: []string{"*"},
+		Query:  &Statement{Select: &Select{From: "docs"}},
+		Vector: &vectorSearchSpec{QueryText: "hello", K: 2},
+	}
+
+	if _, err := e.executeVectorSearch(context.Background(), plan); err == nil {
+		t.Fatal("expected an error when no EmbeddingProvider is configured")
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	if !isRetryableError(context.DeadlineExceeded) {
+		t.Error("DeadlineExceeded should be retryable")
+	}
+	if !isRetryableError(fmt.Errorf("dial tcp: connection refused")) {
+		t.Error("connection refused should be retryable")
+	}
+	if isRetryableError(fmt.Errorf("schema mismatch")) {
+		t.Error("schema mismatch should not be retryable")
+	}
+}
@@ -0,0 +1,133 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JoinType controls which side's unmatched rows executeJoin keeps (with
+// nulls standing in for the missing side) versus drops entirely.
+type JoinType string
+
+const (
+	JoinInner JoinType = "INNER"
+	JoinLeft  JoinType = "LEFT"
+	JoinRight JoinType = "RIGHT"
+	JoinFull  JoinType = "FULL"
+)
+
+// ProjectionField names one column of a join's merged output: which side
+// of the join it comes from, the column's name on that side, and the
+// alias it surfaces under in the result so callers don't have to
+// un-prefix a mangled "left_"/"right_" key themselves.
+type ProjectionField struct {
+	Side  string // "left" or "right"
+	Field string
+	Alias string
+}
+
+// JoinSpec is the Planner's recipe for executeJoin: which fields the two
+// subplans join on, which rows survive when one side has no match, and
+// how to project the merged columns into the result documents.
+type JoinSpec struct {
+	Type       JoinType
+	LeftKey    string
+	RightKey   string
+	Projection []ProjectionField
+}
+
+// buildJoinSpec derives a JoinSpec from a parsed Join clause's ON
+// condition, which compares two (optionally table-qualified) columns,
+// e.g. "users.id = orders.user_id".
+func buildJoinSpec(join Join, sel *Select) (*JoinSpec, error) {
+	if join.On == nil || join.On.Right == nil || join.On.Right.String == nil {
+		return nil, joinConditionError
+	}
+
+	return &JoinSpec{
+		Type:       joinTypeOf(join.Type),
+		LeftKey:    unqualifyColumn(join.On.Left),
+		RightKey:   unqualifyColumn(*join.On.Right.String),
+		Projection: buildProjection(sel, join.Table),
+	}, nil
+}
+
+var joinConditionError = fmt.Errorf("query: join ON condition must compare two columns")
+
+func joinTypeOf(raw string) JoinType {
+	switch strings.ToUpper(raw) {
+	case "LEFT":
+		return JoinLeft
+	case "RIGHT":
+		return JoinRight
+	case "FULL":
+		return JoinFull
+	default:
+		return JoinInner
+	}
+}
+
+// unqualifyColumn strips a "table." prefix from a column reference, e.g.
+// "users.id" -> "id", leaving an already-bare column untouched.
+func unqualifyColumn(col string) string {
+	if idx := strings.LastIndex(col, "."); idx >= 0 {
+		return col[idx+1:]
+	}
+	return col
+}
+
+// buildProjection derives each selected field's join side (left = sel.From,
+// right = joinTable), column name, and output alias (the field's own
+// "AS" alias if given, else its bare column name) from sel.Fields.
+// Aggregates aren't supported through a join's projection in this chunk.
+func buildProjection(sel *Select, joinTable string) []ProjectionField {
+	proj := make([]ProjectionField, 0, len(sel.Fields))
+	for _, f := range sel.Fields {
+		if f.Name == nil {
+			continue
+		}
+		side, col := sideAndColumn(*f.Name, joinTable)
+		alias := f.Alias
+		if alias == "" {
+			alias = col
+		}
+		proj = append(proj, ProjectionField{Side: side, Field: col, Alias: alias})
+	}
+	return proj
+}
+
+// sideAndColumn splits a (possibly table-qualified) field reference into
+// which join side it belongs to and its bare column name. An unqualified
+// reference is assumed to belong to the left (sel.From) table.
+func sideAndColumn(qualified, rightTable string) (side, col string) {
+	idx := strings.Index(qualified, ".")
+	if idx < 0 {
+		return "left", qualified
+	}
+	table, col := qualified[:idx], qualified[idx+1:]
+	if table == rightTable {
+		return "right", col
+	}
+	return "left", col
+}
+
+// keepsUnmatchedLeft/keepsUnmatchedRight report whether a JoinType keeps
+// a null-filled row for a left/right-side document with no match.
+func keepsUnmatchedLeft(t JoinType) bool  { return t == JoinLeft || t == JoinFull }
+func keepsUnmatchedRight(t JoinType) bool { return t == JoinRight || t == JoinFull }
+
+// projectJoinRow merges a matched (or partially nil, for an outer join's
+// unmatched row) left/right document pair into one output document per
+// spec's Projection. A nil document simply contributes nulls for its side's
+// projected fields, since a nil map read in Go returns the zero value.
+func projectJoinRow(projection []ProjectionField, leftDoc, rightDoc map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(projection))
+	for _, p := range projection {
+		if p.Side == "right" {
+			out[p.Alias] = rightDoc[p.Field]
+		} else {
+			out[p.Alias] = leftDoc[p.Field]
+		}
+	}
+	return out
+}
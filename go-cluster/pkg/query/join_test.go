@@ -0,0 +1,186 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// joinFixtureClient drives the two join SubPlans through
+// executePointLookup -> ExecuteRemote so executeJoin's e.Execute(ctx,
+// subPlan) calls return canned per-collection document sets.
+type joinFixtureClient struct {
+	byCollection map[string][]interface{}
+}
+
+func (c *joinFixtureClient) ExecuteLocal(ctx context.Context, stmt *Statement) (*Result, error) {
+	return c.ExecuteRemote(ctx, "localhost", stmt)
+}
+
+func (c *joinFixtureClient) ExecuteRemote(ctx context.Context, nodeAddr string, stmt *Statement) (*Result, error) {
+	docs := c.byCollection[stmt.Select.From]
+	return &Result{Documents: docs, Count: len(docs)}, nil
+}
+
+func (c *joinFixtureClient) Peers(ctx context.Context) ([]string, error) { return nil, nil }
+func (c *joinFixtureClient) ShardPeers(ctx context.Context, addr string) ([]string, error) {
+	return nil, nil
+}
+
+// joinPlan builds a PlanTypeJoin plan for "users JOIN orders ON
+// users.id = orders.user_id", so each test only has to supply the
+// fixture rows and the JoinType under test.
+func joinPlan(joinType JoinType) *Plan {
+	return &Plan{
+		Type: PlanTypeJoin,
+		SubPlans: []*Plan{
+			{Type: PlanTypePointLookup, Shards: []string{"localhost"}, Query: &Statement{Select: &Select{From: "users"}}},
+			{Type: PlanTypePointLookup, Shards: []string{"localhost"}, Query: &Statement{Select: &Select{From: "orders"}}},
+		},
+		Join: &JoinSpec{
+			Type:     joinType,
+			LeftKey:  "id",
+			RightKey: "user_id",
+			Projection: []ProjectionField{
+				{Side: "left", Field: "id", Alias: "user_id"},
+				{Side: "left", Field: "name", Alias: "name"},
+				{Side: "right", Field: "amount", Alias: "amount"},
+			},
+		},
+	}
+}
+
+func sortByUserID(docs []interface{}) {
+	sort.Slice(docs, func(i, j int) bool {
+		return fmt.Sprintf("%v", docs[i].(map[string]interface{})["user_id"]) <
+			fmt.Sprintf("%v", docs[j].(map[string]interface{})["user_id"])
+	})
+}
+
+func TestExecuteJoin_Inner(t *testing.T) {
+	client := &joinFixtureClient{byCollection: map[string][]interface{}{
+		"users": {
+			map[string]interface{}{"id": "1", "name": "alice"},
+			map[string]interface{}{"id": "2", "name": "bob"},
+		},
+		"orders": {
+			map[string]interface{}{"user_id": "1", "amount": float64(10)},
+		},
+	}}
+	e := NewExecutor(client, nil)
+
+	res, err := e.executeJoin(context.Background(), joinPlan(JoinInner))
+	if err != nil {
+		t.Fatalf("executeJoin failed: %v", err)
+	}
+	if res.Count != 1 {
+		t.Fatalf("expected 1 matched row, got %d", res.Count)
+	}
+	row := res.Documents[0].(map[string]interface{})
+	if row["name"] != "alice" || row["amount"] != float64(10) {
+		t.Errorf("unexpected row: %+v", row)
+	}
+}
+
+func TestExecuteJoin_Left(t *testing.T) {
+	client := &joinFixtureClient{byCollection: map[string][]interface{}{
+		"users": {
+			map[string]interface{}{"id": "1", "name": "alice"},
+			map[string]interface{}{"id": "2", "name": "bob"}, // no matching order
+		},
+		"orders": {
+			map[string]interface{}{"user_id": "1", "amount": float64(10)},
+		},
+	}}
+	e := NewExecutor(client, nil)
+
+	res, err := e.executeJoin(context.Background(), joinPlan(JoinLeft))
+	if err != nil {
+		t.Fatalf("executeJoin failed: %v", err)
+	}
+	if res.Count != 2 {
+		t.Fatalf("expected 2 rows (bob unmatched kept with null amount), got %d", res.Count)
+	}
+
+	sortByUserID(res.Documents)
+	bob := res.Documents[1].(map[string]interface{})
+	if bob["name"] != "bob" || bob["amount"] != nil {
+		t.Errorf("expected bob's unmatched row to have a nil amount, got %+v", bob)
+	}
+}
+
+func TestExecuteJoin_Right(t *testing.T) {
+	client := &joinFixtureClient{byCollection: map[string][]interface{}{
+		"users": {
+			map[string]interface{}{"id": "1", "name": "alice"},
+		},
+		"orders": {
+			map[string]interface{}{"user_id": "1", "amount": float64(10)},
+			map[string]interface{}{"user_id": "9", "amount": float64(99)}, // no matching user
+		},
+	}}
+	e := NewExecutor(client, nil)
+
+	res, err := e.executeJoin(context.Background(), joinPlan(JoinRight))
+	if err != nil {
+		t.Fatalf("executeJoin failed: %v", err)
+	}
+	if res.Count != 2 {
+		t.Fatalf("expected 2 rows (orphan order kept with null name), got %d", res.Count)
+	}
+
+	var sawOrphan bool
+	for _, raw := range res.Documents {
+		row := raw.(map[string]interface{})
+		if row["amount"] == float64(99) {
+			sawOrphan = true
+			if row["name"] != nil {
+				t.Errorf("expected orphan order's name to be nil, got %v", row["name"])
+			}
+		}
+	}
+	if !sawOrphan {
+		t.Error("expected the orphan order to appear in a RIGHT JOIN")
+	}
+}
+
+func TestExecuteJoin_FullOuter_NoMatchOnEitherSide(t *testing.T) {
+	client := &joinFixtureClient{byCollection: map[string][]interface{}{
+		"users": {
+			map[string]interface{}{"id": "1", "name": "alice"}, // no matching order
+		},
+		"orders": {
+			map[string]interface{}{"user_id": "9", "amount": float64(99)}, // no matching user
+		},
+	}}
+	e := NewExecutor(client, nil)
+
+	res, err := e.executeJoin(context.Background(), joinPlan(JoinFull))
+	if err != nil {
+		t.Fatalf("executeJoin failed: %v", err)
+	}
+	if res.Count != 2 {
+		t.Fatalf("expected both unmatched rows kept under FULL OUTER, got %d", res.Count)
+	}
+
+	var sawAlice, sawOrphanOrder bool
+	for _, raw := range res.Documents {
+		row := raw.(map[string]interface{})
+		switch {
+		case row["name"] == "alice":
+			sawAlice = true
+			if row["amount"] != nil {
+				t.Errorf("expected alice's row to have a nil amount, got %v", row["amount"])
+			}
+		case row["amount"] == float64(99):
+			sawOrphanOrder = true
+			if row["name"] != nil {
+				t.Errorf("expected the orphan order's row to have a nil name, got %v", row["name"])
+			}
+		}
+	}
+	if !sawAlice || !sawOrphanOrder {
+		t.Error("expected both sides' unmatched rows to appear")
+	}
+}
@@ -1,6 +1,8 @@
 package query
 
 import (
+	"strings"
+
 	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
 )
@@ -15,17 +17,25 @@ type Statement struct {
 }
 
 type Select struct {
-	Fields  []Field  ` "SELECT" @@ { "," @@ }`
-	From    string   ` "FROM" @Ident`
-	Joins   []Join   ` { @@ }`
-	Where   *Where   ` [ "WHERE" @@ ]`
-	GroupBy []string ` [ "GROUP" "BY" @Ident { "," @Ident } ]`
-	OrderBy []Order  ` [ "ORDER" "BY" @@ { "," @@ } ]`
-	Limit   *int     ` [ "LIMIT" @Number ]`
+	Fields      []Field  ` "SELECT" @@ { "," @@ }`
+	From        string   ` "FROM" @Ident`
+	Joins       []Join   ` { @@ }`
+	Where       *Where   ` [ "WHERE" @@ ]`
+	GroupBy     []string ` [ "GROUP" "BY" @Ident { "," @Ident } ]`
+	OrderBy     []Order  ` [ "ORDER" "BY" @@ { "," @@ } ]`
+	Limit       *int     ` [ "LIMIT" @Number ]`
+	Consistency string   ` [ "CONSISTENCY" @( "STRONG" | "WEAK" ) ]`
+}
+
+// WantsLinearizable reports whether this Select's CONSISTENCY clause
+// requested STRONG (read-index/lease-read) consistency. Omitting the
+// clause, or writing WEAK, keeps the historical stale-read behavior.
+func (s *Select) WantsLinearizable() bool {
+	return strings.EqualFold(s.Consistency, "STRONG")
 }
 
 type Join struct {
-	Type  string     ` ( @( "LEFT" | "RIGHT" | "INNER" | "OUTER" ) )? "JOIN"`
+	Type  string     ` ( @( "LEFT" | "RIGHT" | "INNER" | "FULL" ) )? [ "OUTER" ] "JOIN"`
 	Table string     ` @Ident`
 	On    *Condition ` "ON" @@`
 }
@@ -73,9 +83,32 @@ type Where struct {
 }
 
 type Condition struct {
-	Left     string `@Ident`
-	Operator string `@( "=" | "<>" | "<" | ">" | "<=" | ">=" )`
-	Right    *Value `@@`
+	VectorSearch *VectorSearch ` @@`
+	SimilarTo    *SimilarTo    ` | @@`
+	Left         string        ` | @Ident`
+	Operator     string        ` @( "=" | "<>" | "<" | ">" | "<=" | ">=" )`
+	Right        *Value        ` @@`
+}
+
+// VectorSearch is the explicit-field form of a vector similarity
+// condition: VECTOR_SEARCH(field, 'query text', k). QueryVector is not
+// part of the grammar (it has no struct tag) - the Executor fills it in
+// with the coordinator's ai.Client.GetEmbedding(Text) result before
+// shipping the Statement to each shard, so shards never re-embed the
+// same literal themselves.
+type VectorSearch struct {
+	Field       string `"VECTOR_SEARCH" "(" @Ident ","`
+	Text        string `@String ","`
+	K           int    `@Number ")"`
+	QueryVector []float32
+}
+
+// SimilarTo is sugar for VectorSearch that omits the field (defaulting
+// to defaultVectorField) and k (defaulting to defaultVectorK); see
+// vectorSearchSpecFor in planner.go.
+type SimilarTo struct {
+	Text        string `"SIMILAR_TO" "(" @String ")"`
+	QueryVector []float32
 }
 
 type Value struct {
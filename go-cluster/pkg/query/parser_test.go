@@ -27,3 +27,29 @@ func TestParseSelect(t *testing.T) {
 		t.Error("Expected LIMIT 10")
 	}
 }
+
+func TestParseSelectConsistency(t *testing.T) {
+	stmt, err := Parse("SELECT id FROM users CONSISTENCY STRONG")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if !stmt.Select.WantsLinearizable() {
+		t.Error("Expected CONSISTENCY STRONG to request a linearizable read")
+	}
+
+	stmt, err = Parse("SELECT id FROM users CONSISTENCY WEAK")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if stmt.Select.WantsLinearizable() {
+		t.Error("Expected CONSISTENCY WEAK to not request a linearizable read")
+	}
+
+	stmt, err = Parse("SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if stmt.Select.WantsLinearizable() {
+		t.Error("Expected omitted CONSISTENCY clause to default to stale reads")
+	}
+}
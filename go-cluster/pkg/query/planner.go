@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/lumadb/cluster/pkg/router"
+	"github.com/tdb-plus/cluster/pkg/router"
 )
 
 // PlanType defines the execution strategy
@@ -15,6 +15,7 @@ const (
 	PlanTypeScatterGather
 	PlanTypeAggregation
 	PlanTypeJoin
+	PlanTypeVectorSearch
 )
 
 // Plan represents a distributed execution plan
@@ -25,6 +26,24 @@ type Plan struct {
 	Local    bool     // If true, execute locally
 	Query    *Statement
 	SubPlans []*Plan // For Joins or complex stages
+
+	// Merge describes how the Executor recombines partial-aggregate
+	// results from each shard; only set for PlanTypeAggregation.
+	Merge *mergeDescriptor
+
+	// Vector describes the VECTOR_SEARCH/SIMILAR_TO condition driving
+	// this plan; only set for PlanTypeVectorSearch.
+	Vector *vectorSearchSpec
+
+	// Join describes how executeJoin combines this plan's two SubPlans;
+	// only set for PlanTypeJoin.
+	Join *JoinSpec
+
+	// RowCountEstimate is a best-effort row count for this plan, used by
+	// a parent PlanTypeJoin plan to decide which of its two SubPlans to
+	// build the hash table from. Zero means unknown; executeJoin then
+	// falls back to always building from the left side.
+	RowCountEstimate int64
 }
 
 // Planner creates execution plans
@@ -49,15 +68,29 @@ func (p *Planner) CreatePlan(ctx context.Context, stmt *Statement) (*Plan, error
 }
 
 func (p *Planner) planSelect(ctx context.Context, sel *Select) (*Plan, error) {
+	// 0. VECTOR_SEARCH/SIMILAR_TO: always a broadcast - every shard scores
+	// its own documents against the query vector and the Executor keeps
+	// only the global top-k (see executeVectorSearch).
+	if spec := vectorSearchSpecFor(sel); spec != nil {
+		return &Plan{
+			Type:   PlanTypeVectorSearch,
+			Shards: []string{"*"},
+			Query:  &Statement{Select: sel},
+			Vector: spec,
+		}, nil
+	}
+
 	// 1. Check for Joins (Highest Complexity)
 	if len(sel.Joins) > 0 {
-		// MVP: We only support a simple Left Deep Join for now (Planner Logic)
-		// We treat the "From" as the primary and join others.
-		// Real implementation would optimize order.
+		// MVP: only the first join is planned; chained multi-way joins
+		// are future work (same scope limit the old code had).
+		join := sel.Joins[0]
+		joinSpec, err := buildJoinSpec(join, sel)
+		if err != nil {
+			return nil, err
+		}
 
-		// Create a plan for the primary table
-		// Recursively plan the base table scan
-		basePlan, err := p.planSimpleSelect(ctx, &Select{
+		leftPlan, err := p.planSimpleSelect(ctx, &Select{
 			Fields: sel.Fields, // TODO: Split fields by table
 			From:   sel.From,
 			Where:  sel.Where, // TODO: Split where by table
@@ -66,13 +99,20 @@ func (p *Planner) planSelect(ctx context.Context, sel *Select) (*Plan, error) {
 			return nil, err
 		}
 
-		// For now, we wrap it in a Join Plan
-		// Real impl would need detailed Join Node logic
+		rightPlan, err := p.planSimpleSelect(ctx, &Select{From: join.Table})
+		if err != nil {
+			return nil, err
+		}
+		// TODO: populate leftPlan/rightPlan.RowCountEstimate from shard
+		// stats once the router exposes them, so executeJoin can build
+		// the hash table from the smaller side instead of always the left.
+
 		return &Plan{
 			Type:     PlanTypeJoin,
 			Shards:   []string{"*"}, // Distributed Join usually involves all nodes
 			Query:    &Statement{Select: sel},
-			SubPlans: []*Plan{basePlan},
+			SubPlans: []*Plan{leftPlan, rightPlan},
+			Join:     joinSpec,
 		}, nil
 	}
 
@@ -89,13 +129,15 @@ func (p *Planner) planSelect(ctx context.Context, sel *Select) (*Plan, error) {
 	}
 
 	if isAgg {
-		// Aggregation Plan: Scatter (collect partials) -> Gather (Merge)
-		// 1. Create the detailed scatter plan (execute same query on all nodes)
-		// 2. The executor will need to know to "merge" results
+		// Aggregation Plan: each shard runs a partial form of sel (see
+		// rewriteAggregatePlan) and the Executor merges the partials
+		// per Merge once every shard has reported.
+		partialSel, merge := rewriteAggregatePlan(sel)
 		return &Plan{
 			Type:   PlanTypeAggregation,
 			Shards: []string{"*"},
-			Query:  &Statement{Select: sel},
+			Query:  &Statement{Select: partialSel},
+			Merge:  merge,
 		}, nil
 	}
 
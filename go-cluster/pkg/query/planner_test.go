@@ -59,8 +59,49 @@ func TestPlanner_Join(t *testing.T) {
 	if plan.Type != PlanTypeJoin {
 		t.Errorf("Expected PlanTypeJoin, got %v", plan.Type)
 	}
-	if len(plan.SubPlans) != 1 {
-		t.Errorf("Expected 1 SubPlan (base table scan), got %d", len(plan.SubPlans))
+	if len(plan.SubPlans) != 2 {
+		t.Errorf("Expected 2 SubPlans (left + right table scan), got %d", len(plan.SubPlans))
+	}
+	if plan.Join == nil {
+		t.Fatal("Expected a JoinSpec")
+	}
+	if plan.Join.Type != JoinInner || plan.Join.LeftKey != "id" || plan.Join.RightKey != "user_id" {
+		t.Errorf("Expected JoinSpec{Type: INNER, LeftKey: id, RightKey: user_id}, got %+v", plan.Join)
+	}
+	wantProjection := []ProjectionField{
+		{Side: "left", Field: "name", Alias: "name"},
+		{Side: "right", Field: "amount", Alias: "amount"},
+	}
+	if len(plan.Join.Projection) != len(wantProjection) {
+		t.Fatalf("expected %d projection fields, got %d", len(wantProjection), len(plan.Join.Projection))
+	}
+	for i, want := range wantProjection {
+		if plan.Join.Projection[i] != want {
+			t.Errorf("projection[%d] = %+v, want %+v", i, plan.Join.Projection[i], want)
+		}
+	}
+}
+
+func TestPlanner_VectorSearch(t *testing.T) {
+	stmt, err := Parse(`SELECT id FROM docs WHERE VECTOR_SEARCH(embedding, 'hello', 3)`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	planner := NewPlanner(nil)
+	plan, err := planner.CreatePlan(context.Background(), stmt)
+	if err != nil {
+		t.Fatalf("CreatePlan failed: %v", err)
+	}
+
+	if plan.Type != PlanTypeVectorSearch {
+		t.Errorf("Expected PlanTypeVectorSearch, got %v", plan.Type)
+	}
+	if len(plan.Shards) != 1 || plan.Shards[0] != "*" {
+		t.Errorf("Expected Shards=['*'], got %v", plan.Shards)
+	}
+	if plan.Vector == nil || plan.Vector.QueryText != "hello" || plan.Vector.K != 3 {
+		t.Errorf("Expected Vector={QueryText: hello, K: 3}, got %+v", plan.Vector)
 	}
 }
 
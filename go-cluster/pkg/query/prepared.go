@@ -0,0 +1,241 @@
+package query
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RoutingMode describes how a PreparedStatement's target shards are
+// determined at execute time.
+type RoutingMode int
+
+const (
+	// RoutingModeAllShards means the statement must fan out to every shard.
+	RoutingModeAllShards RoutingMode = iota
+	// RoutingModePointLookup means the statement has an equality
+	// WHERE pk = ? condition and its target shard can be resolved from a
+	// single key, the same shape planSimpleSelect already recognizes.
+	RoutingModePointLookup
+)
+
+// RoutingPlan is the compiled routing decision for a PreparedStatement,
+// computed once at Prepare time so repeated executions don't need to
+// re-walk the AST to find out which shards to hit.
+type RoutingPlan struct {
+	Mode       RoutingMode
+	Collection string
+	PKColumn   string // set when Mode == RoutingModePointLookup
+}
+
+// PreparedStatement is a parsed LQL statement plus its compiled routing
+// plan, returned by Prepare and reused across executions of the same
+// query shape.
+type PreparedStatement struct {
+	Query string // normalized query string this was parsed from
+	Stmt  *Statement
+	Plan  *RoutingPlan
+}
+
+// DefaultPreparedCacheSize is the capacity of the package-level prepared
+// statement cache used by Prepare.
+const DefaultPreparedCacheSize = 256
+
+var defaultCache = NewPreparedCache(DefaultPreparedCacheSize)
+
+// Prepare parses lql and compiles its routing plan, consulting (and
+// populating) the package-level prepared statement cache. Callers that
+// want an isolated or differently-sized cache should use NewPreparedCache
+// directly instead.
+func Prepare(lql string) (*PreparedStatement, error) {
+	return defaultCache.Prepare(lql)
+}
+
+// compileRoutingPlan inspects a parsed Statement for the equality
+// WHERE pk = ? shape that planSimpleSelect (see planner.go) already
+// treats as a point lookup, recording it so RoutePrepared can skip
+// re-walking the AST on every execution.
+func compileRoutingPlan(stmt *Statement) *RoutingPlan {
+	switch {
+	case stmt.Select != nil:
+		return compileWherePlan(stmt.Select.From, stmt.Select.Where)
+	case stmt.Update != nil:
+		return compileWherePlan(stmt.Update.Collection, stmt.Update.Where)
+	case stmt.Delete != nil:
+		return compileWherePlan(stmt.Delete.Collection, stmt.Delete.Where)
+	default:
+		return &RoutingPlan{Mode: RoutingModeAllShards}
+	}
+}
+
+func compileWherePlan(collection string, where *Where) *RoutingPlan {
+	if where != nil && where.Condition != nil {
+		cond := where.Condition
+		if (cond.Left == "id" || cond.Left == "_id") && cond.Operator == "=" {
+			return &RoutingPlan{
+				Mode:       RoutingModePointLookup,
+				Collection: collection,
+				PKColumn:   cond.Left,
+			}
+		}
+	}
+	return &RoutingPlan{Mode: RoutingModeAllShards, Collection: collection}
+}
+
+// normalizeQuery collapses whitespace so equivalent queries (differing
+// only in spacing) share one cache entry and one parse.
+func normalizeQuery(lql string) string {
+	return strings.Join(strings.Fields(lql), " ")
+}
+
+func queryHash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// PreparedCache is an LRU cache of PreparedStatements keyed by a hash of
+// the normalized query string, sized at construction time.
+type PreparedCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type preparedCacheEntry struct {
+	key string
+	ps  *PreparedStatement
+}
+
+// NewPreparedCache creates a PreparedCache holding at most capacity
+// entries, evicting the least-recently-used one once full.
+func NewPreparedCache(capacity int) *PreparedCache {
+	if capacity <= 0 {
+		capacity = DefaultPreparedCacheSize
+	}
+	return &PreparedCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Prepare returns the cached PreparedStatement for lql, parsing and
+// compiling its routing plan on a cache miss.
+func (c *PreparedCache) Prepare(lql string) (*PreparedStatement, error) {
+	normalized := normalizeQuery(lql)
+	key := queryHash(normalized)
+
+	if ps, ok := c.lookup(key); ok {
+		return ps, nil
+	}
+
+	stmt, err := Parse(lql)
+	if err != nil {
+		return nil, fmt.Errorf("query: failed to prepare statement: %w", err)
+	}
+
+	ps := &PreparedStatement{
+		Query: normalized,
+		Stmt:  stmt,
+		Plan:  compileRoutingPlan(stmt),
+	}
+	return c.store(key, ps), nil
+}
+
+func (c *PreparedCache) lookup(key string) (*PreparedStatement, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return elem.Value.(*preparedCacheEntry).ps, true
+}
+
+func (c *PreparedCache) store(key string, ps *PreparedStatement) *PreparedStatement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another goroutine may have raced us to parse the same statement.
+	if elem, ok := c.items[key]; ok {
+		c.ll.MoveToFront(elem)
+		return elem.Value.(*preparedCacheEntry).ps
+	}
+
+	elem := c.ll.PushFront(&preparedCacheEntry{key: key, ps: ps})
+	c.items[key] = elem
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*preparedCacheEntry).key)
+		}
+	}
+	return ps
+}
+
+// RoutePrepared resolves the minimal set of shard addresses a prepared
+// statement's execution must reach, using its precompiled RoutingPlan
+// instead of re-walking the AST. For RoutingModePointLookup, the key
+// comes from args[0] if provided (the usual prepared-statement flow,
+// where the caller supplies the current bind value), falling back to the
+// literal already present in the parsed WHERE clause — the LQL grammar
+// does not yet support `?` placeholders, so a literal is always present
+// at Prepare time.
+func (p *Planner) RoutePrepared(ctx context.Context, ps *PreparedStatement, args []Value) ([]string, error) {
+	if ps.Plan.Mode == RoutingModeAllShards {
+		return []string{"*"}, nil
+	}
+
+	key, err := preparedKeyArg(ps, args)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := p.router.RouteRead(ctx, ps.Plan.Collection, key)
+	if err != nil {
+		return nil, err
+	}
+	return []string{target}, nil
+}
+
+func preparedKeyArg(ps *PreparedStatement, args []Value) ([]byte, error) {
+	if len(args) > 0 {
+		return valueToKeyBytes(args[0])
+	}
+
+	var cond *Condition
+	switch {
+	case ps.Stmt.Select != nil && ps.Stmt.Select.Where != nil:
+		cond = ps.Stmt.Select.Where.Condition
+	case ps.Stmt.Update != nil && ps.Stmt.Update.Where != nil:
+		cond = ps.Stmt.Update.Where.Condition
+	case ps.Stmt.Delete != nil && ps.Stmt.Delete.Where != nil:
+		cond = ps.Stmt.Delete.Where.Condition
+	}
+	if cond == nil || cond.Right == nil {
+		return nil, fmt.Errorf("query: point lookup plan has no key literal or bind argument")
+	}
+	return valueToKeyBytes(*cond.Right)
+}
+
+func valueToKeyBytes(v Value) ([]byte, error) {
+	switch {
+	case v.String != nil:
+		return []byte(*v.String), nil
+	case v.Number != nil:
+		return []byte(fmt.Sprintf("%v", *v.Number)), nil
+	case v.Bool != nil:
+		return []byte(fmt.Sprintf("%v", *v.Bool)), nil
+	default:
+		return nil, fmt.Errorf("query: empty key value")
+	}
+}
@@ -0,0 +1,122 @@
+package query
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/config"
+	"github.com/tdb-plus/cluster/pkg/router"
+	"go.uber.org/zap"
+)
+
+func TestPrepare_PointLookupPlan(t *testing.T) {
+	ps, err := Prepare("SELECT * FROM users WHERE id = 'u1'")
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if ps.Plan.Mode != RoutingModePointLookup {
+		t.Fatalf("expected RoutingModePointLookup, got %v", ps.Plan.Mode)
+	}
+	if ps.Plan.Collection != "users" {
+		t.Errorf("expected collection 'users', got %q", ps.Plan.Collection)
+	}
+}
+
+func TestPrepare_AllShardsPlanForScan(t *testing.T) {
+	ps, err := Prepare("SELECT * FROM users WHERE age > 21")
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if ps.Plan.Mode != RoutingModeAllShards {
+		t.Fatalf("expected RoutingModeAllShards, got %v", ps.Plan.Mode)
+	}
+}
+
+func TestPrepare_CachesByNormalizedQuery(t *testing.T) {
+	a, err := Prepare("SELECT * FROM users   WHERE id = 'u1'")
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	b, err := Prepare("SELECT  *  FROM  users WHERE id = 'u1'")
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if a != b {
+		t.Error("expected equivalent queries to share one cached PreparedStatement")
+	}
+}
+
+func TestPreparedCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewPreparedCache(2)
+	a, _ := cache.Prepare("SELECT * FROM a WHERE id = '1'")
+	_, _ = cache.Prepare("SELECT * FROM b WHERE id = '1'")
+	_, _ = cache.Prepare("SELECT * FROM c WHERE id = '1'") // evicts a
+
+	again, err := cache.Prepare("SELECT * FROM a WHERE id = '1'")
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if again == a {
+		t.Error("expected 'a' to have been evicted and re-parsed")
+	}
+}
+
+func createTestNode(t *testing.T) *cluster.Node {
+	tmpDir, err := os.MkdirTemp("", "lumadb-query-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	cfg := config.DefaultConfig()
+	cfg.DataDir = tmpDir
+	cfg.NodeID = "node1"
+	cfg.RaftAddr = "127.0.0.1:0"
+
+	node, err := cluster.NewNode(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	t.Cleanup(func() { node.Shutdown() })
+	return node
+}
+
+func TestPlanner_RoutePrepared_AllShards(t *testing.T) {
+	planner := NewPlanner(nil) // router not needed: plan never dereferences it
+
+	ps, err := Prepare("SELECT * FROM users WHERE age > 21")
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	shards, err := planner.RoutePrepared(context.Background(), ps, nil)
+	if err != nil {
+		t.Fatalf("RoutePrepared failed: %v", err)
+	}
+	if len(shards) != 1 || shards[0] != "*" {
+		t.Errorf("expected ['*'], got %v", shards)
+	}
+}
+
+func TestPlanner_RoutePrepared_PointLookupUsesLiteral(t *testing.T) {
+	node := createTestNode(t)
+	r := router.NewRouter(node, zap.NewNop())
+	t.Cleanup(r.Close)
+
+	planner := NewPlanner(r)
+
+	ps, err := Prepare("SELECT * FROM users WHERE id = 'u1'")
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	shards, err := planner.RoutePrepared(context.Background(), ps, nil)
+	if err != nil {
+		t.Fatalf("RoutePrepared failed: %v", err)
+	}
+	if len(shards) != 1 {
+		t.Fatalf("expected exactly one target shard, got %v", shards)
+	}
+}
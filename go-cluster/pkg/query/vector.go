@@ -0,0 +1,202 @@
+package query
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// defaultVectorField is the document field SIMILAR_TO searches when the
+// caller doesn't name one explicitly via VECTOR_SEARCH(field, text, k).
+const defaultVectorField = "embedding"
+
+// defaultVectorK is the number of nearest neighbors SIMILAR_TO returns
+// when the caller doesn't specify one via VECTOR_SEARCH(field, text, k).
+const defaultVectorK = 10
+
+// vectorScoreField is the key each shard is expected to report its
+// computed similarity score under on a document it returns from a
+// PlanTypeVectorSearch query; mergeTopK reads it to rank across shards.
+const vectorScoreField = "__score"
+
+// EmbeddingProvider gets the vector embedding for a piece of text.
+// ai.Client already satisfies this.
+type EmbeddingProvider interface {
+	GetEmbedding(text string) ([]float32, error)
+}
+
+// vectorSearchSpec is how the Planner tells the Executor which
+// VECTOR_SEARCH/SIMILAR_TO condition drives a PlanTypeVectorSearch plan.
+type vectorSearchSpec struct {
+	QueryText string
+	K         int
+	// Distance names a DistanceFunc in distanceFuncs; "" defaults to
+	// cosine similarity.
+	Distance string
+}
+
+// vectorSearchSpecFor returns sel's vectorSearchSpec if its WHERE clause
+// is a VECTOR_SEARCH(...) or SIMILAR_TO(...) condition, or nil otherwise.
+func vectorSearchSpecFor(sel *Select) *vectorSearchSpec {
+	if sel.Where == nil || sel.Where.Condition == nil {
+		return nil
+	}
+
+	switch cond := sel.Where.Condition; {
+	case cond.VectorSearch != nil:
+		k := cond.VectorSearch.K
+		if k <= 0 {
+			k = defaultVectorK
+		}
+		return &vectorSearchSpec{QueryText: cond.VectorSearch.Text, K: k}
+	case cond.SimilarTo != nil:
+		return &vectorSearchSpec{QueryText: cond.SimilarTo.Text, K: defaultVectorK}
+	default:
+		return nil
+	}
+}
+
+// applyQueryVector fills in the embedded query vector on stmt's
+// VECTOR_SEARCH/SIMILAR_TO condition (if any) so it travels to each
+// shard as part of the gob-encoded Statement instead of the raw text.
+func applyQueryVector(stmt *Statement, vec []float32) {
+	if stmt == nil || stmt.Select == nil || stmt.Select.Where == nil || stmt.Select.Where.Condition == nil {
+		return
+	}
+	cond := stmt.Select.Where.Condition
+	if cond.VectorSearch != nil {
+		cond.VectorSearch.QueryVector = vec
+	}
+	if cond.SimilarTo != nil {
+		cond.SimilarTo.QueryVector = vec
+	}
+}
+
+// DistanceFunc scores how similar two embeddings are. Every DistanceFunc
+// - even NegativeL2Distance, whose underlying metric is naturally a
+// distance - returns a value where higher means "more similar", so
+// mergeTopK can always keep the top-k by taking the max.
+type DistanceFunc func(a, b []float32) float64
+
+var distanceFuncs = map[string]DistanceFunc{
+	"cosine": CosineSimilarity,
+	"l2":     NegativeL2Distance,
+	"dot":    DotProduct,
+}
+
+// DistanceFuncByName looks up a built-in DistanceFunc by name, defaulting
+// to CosineSimilarity when name is empty.
+func DistanceFuncByName(name string) (DistanceFunc, error) {
+	if name == "" {
+		name = "cosine"
+	}
+	fn, ok := distanceFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("query: unknown vector distance function %q", name)
+	}
+	return fn, nil
+}
+
+// CosineSimilarity is the default DistanceFunc: 1 for identical
+// direction, -1 for opposite, 0 for orthogonal.
+func CosineSimilarity(a, b []float32) float64 {
+	var dot, magA, magB float64
+	for i := 0; i < minInt(len(a), len(b)); i++ {
+		dot += float64(a[i]) * float64(b[i])
+		magA += float64(a[i]) * float64(a[i])
+		magB += float64(b[i]) * float64(b[i])
+	}
+	if magA == 0 || magB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(magA) * math.Sqrt(magB))
+}
+
+// DotProduct is a cheaper DistanceFunc for embeddings that are already
+// normalized, where it ranks the same as CosineSimilarity.
+func DotProduct(a, b []float32) float64 {
+	var dot float64
+	for i := 0; i < minInt(len(a), len(b)); i++ {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+// NegativeL2Distance is a DistanceFunc based on Euclidean distance,
+// negated so a higher score still means "more similar".
+func NegativeL2Distance(a, b []float32) float64 {
+	var sum float64
+	for i := 0; i < minInt(len(a), len(b)); i++ {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return -math.Sqrt(sum)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// scoredDoc pairs a shard result document with its similarity score.
+type scoredDoc struct {
+	doc   interface{}
+	score float64
+}
+
+// topKHeap is a min-heap of scoredDoc keyed by score, so mergeTopK can
+// evict the current lowest-scoring candidate in O(log k) instead of
+// sorting every document every shard returned.
+type topKHeap []scoredDoc
+
+func (h topKHeap) Len() int            { return len(h) }
+func (h topKHeap) Less(i, j int) bool  { return h[i].score < h[j].score }
+func (h topKHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *topKHeap) Push(x interface{}) { *h = append(*h, x.(scoredDoc)) }
+func (h *topKHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// mergeTopK combines the per-shard results of a PlanTypeVectorSearch
+// query into the global top-k by scoreField, using a size-bounded
+// min-heap so the coordinator never holds more than k candidates at once
+// regardless of how many documents the shards returned in total.
+func mergeTopK(shardResults []*Result, scoreField string, k int) *Result {
+	h := &topKHeap{}
+	heap.Init(h)
+
+	for _, res := range shardResults {
+		for _, raw := range res.Documents {
+			doc, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			score := toFloat(doc[scoreField])
+			if h.Len() < k {
+				heap.Push(h, scoredDoc{doc: doc, score: score})
+			} else if h.Len() > 0 && score > (*h)[0].score {
+				heap.Pop(h)
+				heap.Push(h, scoredDoc{doc: doc, score: score})
+			}
+		}
+	}
+
+	// heap.Pop yields ascending score order; reverse so the caller sees
+	// the best match first, as a top-k search result should read.
+	ordered := make([]scoredDoc, h.Len())
+	for i := len(ordered) - 1; i >= 0; i-- {
+		ordered[i] = heap.Pop(h).(scoredDoc)
+	}
+
+	docs := make([]interface{}, len(ordered))
+	for i, sd := range ordered {
+		docs[i] = sd.doc
+	}
+	return &Result{Documents: docs, Count: len(docs)}
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := CosineSimilarity([]float32{1, 0}, []float32{1, 0}); got != 1 {
+		t.Errorf("expected identical vectors to score 1, got %v", got)
+	}
+	if got := CosineSimilarity([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Errorf("expected orthogonal vectors to score 0, got %v", got)
+	}
+	if got := CosineSimilarity([]float32{1, 0}, []float32{-1, 0}); got != -1 {
+		t.Errorf("expected opposite vectors to score -1, got %v", got)
+	}
+}
+
+func TestDistanceFuncByName(t *testing.T) {
+	if _, err := DistanceFuncByName(""); err != nil {
+		t.Errorf("expected empty name to default to cosine, got err: %v", err)
+	}
+	if _, err := DistanceFuncByName("l2"); err != nil {
+		t.Errorf("expected l2 to be a known DistanceFunc, got err: %v", err)
+	}
+	if _, err := DistanceFuncByName("dot"); err != nil {
+		t.Errorf("expected dot to be a known DistanceFunc, got err: %v", err)
+	}
+	if _, err := DistanceFuncByName("manhattan"); err == nil {
+		t.Error("expected an unknown distance function name to error")
+	}
+}
+
+func TestMergeTopK_KeepsHighestScoringAcrossShards(t *testing.T) {
+	shardResults := []*Result{
+		{Documents: []interface{}{
+			map[string]interface{}{"id": "a", vectorScoreField: float64(0.5)},
+			map[string]interface{}{"id": "b", vectorScoreField: float64(0.2)},
+		}},
+		{Documents: []interface{}{
+			map[string]interface{}{"id": "c", vectorScoreField: float64(0.9)},
+			map[string]interface{}{"id": "d", vectorScoreField: float64(0.1)},
+		}},
+	}
+
+	res := mergeTopK(shardResults, vectorScoreField, 2)
+	if res.Count != 2 {
+		t.Fatalf("expected top-2, got %d", res.Count)
+	}
+	if res.Documents[0].(map[string]interface{})["id"] != "c" {
+		t.Errorf("expected c (score 0.9) first, got %v", res.Documents[0])
+	}
+	if res.Documents[1].(map[string]interface{})["id"] != "a" {
+		t.Errorf("expected a (score 0.5) second, got %v", res.Documents[1])
+	}
+}
+
+func TestMergeTopK_FewerThanKDocumentsReturnsAll(t *testing.T) {
+	shardResults := []*Result{
+		{Documents: []interface{}{map[string]interface{}{"id": "a", vectorScoreField: float64(0.5)}}},
+	}
+
+	res := mergeTopK(shardResults, vectorScoreField, 10)
+	if res.Count != 1 {
+		t.Errorf("expected 1 result when fewer than k are available, got %d", res.Count)
+	}
+}
+
+func TestVectorSearchSpecFor(t *testing.T) {
+	stmt, err := Parse(`SELECT id FROM docs WHERE VECTOR_SEARCH(embedding, 'find me', 5)`)
+	if err != nil {
+		t.Fatalf("failed to parse VECTOR_SEARCH query: %v", err)
+	}
+	spec := vectorSearchSpecFor(stmt.Select)
+	if spec == nil {
+		t.Fatal("expected a vectorSearchSpec for a VECTOR_SEARCH condition")
+	}
+	if spec.QueryText != "find me" || spec.K != 5 {
+		t.Errorf("expected QueryText=%q K=5, got QueryText=%q K=%d", "find me", spec.QueryText, spec.K)
+	}
+
+	stmt, err = Parse(`SELECT id FROM docs WHERE SIMILAR_TO('find me too')`)
+	if err != nil {
+		t.Fatalf("failed to parse SIMILAR_TO query: %v", err)
+	}
+	spec = vectorSearchSpecFor(stmt.Select)
+	if spec == nil {
+		t.Fatal("expected a vectorSearchSpec for a SIMILAR_TO condition")
+	}
+	if spec.QueryText != "find me too" || spec.K != defaultVectorK {
+		t.Errorf("expected QueryText=%q K=%d, got QueryText=%q K=%d", "find me too", defaultVectorK, spec.QueryText, spec.K)
+	}
+}
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Statement, Select, Insert, Join, Condition, Value, Aggregate, and
+// Field each implement encoding.BinaryMarshaler/BinaryUnmarshaler via
+// gob, the same way InfluxDB's meta package gives RetentionPolicyInfo
+// its own MarshalBinary/UnmarshalBinary pair. This lets a *Statement
+// cross a node boundary as the AST it already is (see
+// APIClusterClient.ExecuteRemote) instead of being reconstructed into
+// SQL text and re-parsed on the other side, which loses information
+// (e.g. an Insert's values) the reconstruction never handled.
+//
+// Each type gob-encodes through an unexported alias of itself rather
+// than the type directly: gob treats a type implementing
+// encoding.BinaryMarshaler as opaque and calls that method to encode
+// it, so encoding the receiver itself would call MarshalBinary again
+// and recurse forever. The alias has the same fields but none of the
+// methods, so gob falls back to its normal struct encoding for it.
+type (
+	statementWire Statement
+	selectWire    Select
+	insertWire    Insert
+	joinWire      Join
+	conditionWire Condition
+	valueWire     Value
+	aggregateWire Aggregate
+	fieldWire     Field
+)
+
+func marshalGob(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalGob(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (s *Statement) MarshalBinary() ([]byte, error)    { return marshalGob((*statementWire)(s)) }
+func (s *Statement) UnmarshalBinary(data []byte) error { return unmarshalGob(data, (*statementWire)(s)) }
+
+func (s *Select) MarshalBinary() ([]byte, error)    { return marshalGob((*selectWire)(s)) }
+func (s *Select) UnmarshalBinary(data []byte) error { return unmarshalGob(data, (*selectWire)(s)) }
+
+func (i *Insert) MarshalBinary() ([]byte, error)    { return marshalGob((*insertWire)(i)) }
+func (i *Insert) UnmarshalBinary(data []byte) error { return unmarshalGob(data, (*insertWire)(i)) }
+
+func (j *Join) MarshalBinary() ([]byte, error)    { return marshalGob((*joinWire)(j)) }
+func (j *Join) UnmarshalBinary(data []byte) error { return unmarshalGob(data, (*joinWire)(j)) }
+
+func (c *Condition) MarshalBinary() ([]byte, error)    { return marshalGob((*conditionWire)(c)) }
+func (c *Condition) UnmarshalBinary(data []byte) error { return unmarshalGob(data, (*conditionWire)(c)) }
+
+func (v *Value) MarshalBinary() ([]byte, error)    { return marshalGob((*valueWire)(v)) }
+func (v *Value) UnmarshalBinary(data []byte) error { return unmarshalGob(data, (*valueWire)(v)) }
+
+func (a *Aggregate) MarshalBinary() ([]byte, error)    { return marshalGob((*aggregateWire)(a)) }
+func (a *Aggregate) UnmarshalBinary(data []byte) error { return unmarshalGob(data, (*aggregateWire)(a)) }
+
+func (f *Field) MarshalBinary() ([]byte, error)    { return marshalGob((*fieldWire)(f)) }
+func (f *Field) UnmarshalBinary(data []byte) error { return unmarshalGob(data, (*fieldWire)(f)) }
@@ -0,0 +1,65 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestStatementMarshalBinaryRoundtrip(t *testing.T) {
+	stmt, err := Parse("SELECT id, name FROM users WHERE age > 18 LIMIT 10")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	data, err := stmt.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	var got Statement
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	if got.Select == nil {
+		t.Fatal("Expected Select statement")
+	}
+	if got.Select.From != "users" {
+		t.Errorf("Expected FROM users, got %s", got.Select.From)
+	}
+	if len(got.Select.Fields) != 2 {
+		t.Errorf("Expected 2 fields, got %d", len(got.Select.Fields))
+	}
+	if got.Select.Where == nil || got.Select.Where.Condition.Left != "age" {
+		t.Error("Expected WHERE age > 18 to survive the roundtrip")
+	}
+}
+
+func TestInsertMarshalBinaryRoundtrip(t *testing.T) {
+	stmt, err := Parse(`INSERT INTO users (id, name) VALUES (1, 'alice')`)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	data, err := stmt.Insert.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	var got Insert
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	if got.Collection != "users" {
+		t.Errorf("Expected INTO users, got %s", got.Collection)
+	}
+	if len(got.Values) != 2 {
+		t.Fatalf("Expected 2 values, got %d", len(got.Values))
+	}
+	if got.Values[0].Number == nil || *got.Values[0].Number != 1 {
+		t.Error("Expected first value to be 1")
+	}
+	if got.Values[1].String == nil || *got.Values[1].String != "alice" {
+		t.Error("Expected second value to be 'alice'")
+	}
+}
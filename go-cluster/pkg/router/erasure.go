@@ -0,0 +1,165 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/config"
+)
+
+// Tier names the storage tier a key's data belongs to, mirroring
+// config.TieringConfig's HotPolicy/WarmPolicy/ColdPolicy.
+type Tier string
+
+const (
+	TierHot  Tier = "hot"
+	TierWarm Tier = "warm"
+	TierCold Tier = "cold"
+)
+
+// ShardEndpoint is one placement decision for an erasure-coded (or
+// replicated) object: which node holds it, at which stripe index, and
+// whether that stripe is data or parity.
+type ShardEndpoint struct {
+	Addr       string
+	ShardIndex int
+	IsParity   bool
+}
+
+// RouteEC computes the placement for a key stored under tier's
+// redundancy strategy. For "Replication" tiers it returns Factor plain
+// replicas (no parity). For "ErasureCoding" tiers it returns
+// DataShards+ParityShards endpoints, deterministically placed around the
+// node ring starting from the key's hash, Reed-Solomon-stripe style: the
+// caller writes all of them, and on read needs any DataShards of them to
+// reconstruct.
+func (r *Router) RouteEC(ctx context.Context, collection string, key []byte, tier Tier) ([]ShardEndpoint, error) {
+	policy, err := r.tierPolicy(tier)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.Enabled {
+		return nil, fmt.Errorf("router: tier %q is not enabled", tier)
+	}
+
+	ring := r.nodeRing()
+	if len(ring) == 0 {
+		return nil, fmt.Errorf("router: no nodes known to place shards on")
+	}
+
+	strategy := policy.Strategy
+	if strategy.Type == "ErasureCoding" {
+		total := strategy.DataShards + strategy.ParityShards
+		if total == 0 {
+			return nil, fmt.Errorf("router: tier %q has no data/parity shards configured", tier)
+		}
+		return placeOnRing(ring, key, total, strategy.DataShards), nil
+	}
+
+	// Plain replication: Factor plain copies, no parity.
+	factor := strategy.Factor
+	if factor <= 0 {
+		factor = 1
+	}
+	return placeOnRing(ring, key, factor, factor), nil
+}
+
+// tierPolicy resolves the TierPolicy for a named tier from the node's
+// current tiering config.
+func (r *Router) tierPolicy(tier Tier) (*config.TierPolicy, error) {
+	tiering := r.node.GetConfig().Tiering
+	switch tier {
+	case TierHot:
+		return &tiering.HotPolicy, nil
+	case TierWarm:
+		return &tiering.WarmPolicy, nil
+	case TierCold:
+		return &tiering.ColdPolicy, nil
+	default:
+		return nil, fmt.Errorf("router: unknown tier %q", tier)
+	}
+}
+
+// nodeRing returns a stable, sorted list of every node address this
+// cluster currently knows about (self + peers), used as the ring for
+// erasure-coded placement.
+func (r *Router) nodeRing() []string {
+	peers := r.node.GetPeers()
+	ring := make([]string, 0, len(peers)+1)
+	for _, addr := range peers {
+		ring = append(ring, addr)
+	}
+	// Shard leaders are addressed by RaftAddr (see cluster.Node's
+	// monitorLeadership), so use the same identity for self.
+	if self := r.node.GetConfig().RaftAddr; self != "" {
+		ring = append(ring, self)
+	}
+	ring = dedupe(ring)
+	sort.Strings(ring)
+	return ring
+}
+
+func dedupe(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return out
+}
+
+// placeOnRing walks the ring starting at the key's hash and assigns
+// `total` distinct nodes to shard indices, wrapping around if the ring
+// is smaller than total (nodes then hold more than one stripe, which is
+// only safe for test/single-node setups).
+func placeOnRing(ring []string, key []byte, total, dataShards int) []ShardEndpoint {
+	start := int(cluster.HashKey(key) % uint64(len(ring)))
+
+	endpoints := make([]ShardEndpoint, total)
+	for i := 0; i < total; i++ {
+		endpoints[i] = ShardEndpoint{
+			Addr:       ring[(start+i)%len(ring)],
+			ShardIndex: i,
+			IsParity:   i >= dataShards,
+		}
+	}
+	return endpoints
+}
+
+// TierMigrationHint describes a batch placement decision for moving a
+// key from one tier to another, so a background tier-migration worker
+// can issue writes to the new placement and clean up the old one.
+type TierMigrationHint struct {
+	Collection string
+	Key        []byte
+	FromTier   Tier
+	ToTier     Tier
+	Endpoints  []ShardEndpoint
+}
+
+// RoutePlan computes migration hints for a batch of keys moving from
+// fromTier to toTier, so tier-migration can issue writes to the new
+// placement in bulk instead of one RouteEC call per key.
+func (r *Router) RoutePlan(ctx context.Context, collection string, keys [][]byte, fromTier, toTier Tier) ([]TierMigrationHint, error) {
+	hints := make([]TierMigrationHint, 0, len(keys))
+	for _, key := range keys {
+		endpoints, err := r.RouteEC(ctx, collection, key, toTier)
+		if err != nil {
+			return nil, fmt.Errorf("router: failed to plan migration for key: %w", err)
+		}
+		hints = append(hints, TierMigrationHint{
+			Collection: collection,
+			Key:        key,
+			FromTier:   fromTier,
+			ToTier:     toTier,
+			Endpoints:  endpoints,
+		})
+	}
+	return hints, nil
+}
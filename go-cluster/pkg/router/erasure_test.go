@@ -0,0 +1,76 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRouteEC_ErasureCoded(t *testing.T) {
+	node := createTestNode(t)
+	defer node.Shutdown()
+
+	cfg := node.GetConfig()
+	cfg.Tiering.WarmPolicy.Enabled = true
+
+	r := NewRouter(node, zap.NewNop())
+	defer r.Close()
+
+	endpoints, err := r.RouteEC(context.Background(), "events", []byte("key1"), TierWarm)
+	if err != nil {
+		t.Fatalf("RouteEC failed: %v", err)
+	}
+
+	want := cfg.Tiering.WarmPolicy.Strategy.DataShards + cfg.Tiering.WarmPolicy.Strategy.ParityShards
+	if len(endpoints) != want {
+		t.Fatalf("expected %d endpoints, got %d", want, len(endpoints))
+	}
+
+	parityCount := 0
+	for _, ep := range endpoints {
+		if ep.IsParity {
+			parityCount++
+		}
+	}
+	if parityCount != cfg.Tiering.WarmPolicy.Strategy.ParityShards {
+		t.Errorf("expected %d parity shards, got %d", cfg.Tiering.WarmPolicy.Strategy.ParityShards, parityCount)
+	}
+}
+
+func TestRouteEC_DisabledTier(t *testing.T) {
+	node := createTestNode(t)
+	defer node.Shutdown()
+
+	r := NewRouter(node, zap.NewNop())
+	defer r.Close()
+
+	// ColdPolicy is disabled by default.
+	if _, err := r.RouteEC(context.Background(), "events", []byte("key1"), TierCold); err == nil {
+		t.Error("expected error for disabled tier")
+	}
+}
+
+func TestRoutePlan_BatchesMigrationHints(t *testing.T) {
+	node := createTestNode(t)
+	defer node.Shutdown()
+
+	node.GetConfig().Tiering.WarmPolicy.Enabled = true
+
+	r := NewRouter(node, zap.NewNop())
+	defer r.Close()
+
+	keys := [][]byte{[]byte("k1"), []byte("k2"), []byte("k3")}
+	hints, err := r.RoutePlan(context.Background(), "events", keys, TierHot, TierWarm)
+	if err != nil {
+		t.Fatalf("RoutePlan failed: %v", err)
+	}
+	if len(hints) != len(keys) {
+		t.Fatalf("expected %d hints, got %d", len(keys), len(hints))
+	}
+	for _, h := range hints {
+		if h.ToTier != TierWarm || h.FromTier != TierHot {
+			t.Errorf("unexpected tier transition: %+v", h)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package router
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrNoHealthyReplica is returned when every candidate replica for a key
+// has a tripped circuit breaker.
+var ErrNoHealthyReplica = errors.New("router: no healthy replica available")
+
+// breakerState is the classic circuit breaker state machine: closed
+// (healthy, passing traffic), open (tripped, rejecting traffic), and
+// half-open (cooldown elapsed, letting one probe through to decide
+// whether to close again).
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker trips after consecutive failures and resets itself once
+// the cooldown has elapsed and a subsequent probe succeeds.
+type circuitBreaker struct {
+	mu               sync.Mutex
+	state            breakerState
+	consecutiveFails int
+	openedAt         time.Time
+	threshold        int
+	cooldown         time.Duration
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a request should be let through. A half-open
+// breaker allows exactly one in-flight probe at a time.
+func (cb *circuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case breakerClosed:
+		return true
+	case breakerOpen:
+		if time.Since(cb.openedAt) >= cb.cooldown {
+			cb.state = breakerHalfOpen
+			return true
+		}
+		return false
+	case breakerHalfOpen:
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess closes the breaker and resets the failure count.
+func (cb *circuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.consecutiveFails = 0
+	cb.state = breakerClosed
+}
+
+// RecordFailure trips the breaker once consecutive failures reach the
+// threshold, or re-opens it immediately if a half-open probe fails.
+func (cb *circuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == breakerHalfOpen {
+		cb.state = breakerOpen
+		cb.openedAt = time.Now()
+		return
+	}
+
+	cb.consecutiveFails++
+	if cb.consecutiveFails >= cb.threshold {
+		cb.state = breakerOpen
+		cb.openedAt = time.Now()
+	}
+}
+
+// Tripped reports whether the breaker is currently rejecting traffic.
+func (cb *circuitBreaker) Tripped() bool {
+	return !cb.Allow()
+}
+
+// EndpointStatus summarizes the health of a single routed endpoint, used
+// by /health/endpoints.
+type EndpointStatus struct {
+	Addr    string `json:"addr"`
+	Healthy bool   `json:"healthy"`
+	State   string `json:"state"`
+}
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// HealthCheck dials addr over TCP as a lightweight liveness probe and
+// updates its circuit breaker accordingly. It returns the probe result.
+func (r *Router) HealthCheck(addr string) bool {
+	pool := r.getOrCreatePool(addr)
+
+	conn, err := net.DialTimeout("tcp", addr, r.healthCheckTimeout())
+	if err != nil {
+		pool.breaker.RecordFailure()
+		return false
+	}
+	conn.Close()
+
+	pool.breaker.RecordSuccess()
+	return true
+}
+
+func (r *Router) healthCheckTimeout() time.Duration {
+	return 2 * time.Second
+}
+
+// startHealthChecker runs a background prober for addr at the configured
+// interval until stopCh is closed. One goroutine runs per known endpoint.
+func (r *Router) startHealthChecker(addr string, interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.HealthCheck(addr)
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// EndpointHealth reports the circuit breaker state for every endpoint the
+// router currently knows about (i.e. has a connection pool for).
+func (r *Router) EndpointHealth() []EndpointStatus {
+	r.poolsMu.RLock()
+	defer r.poolsMu.RUnlock()
+
+	statuses := make([]EndpointStatus, 0, len(r.connPools))
+	for addr, pool := range r.connPools {
+		pool.breaker.mu.Lock()
+		state := pool.breaker.state
+		pool.breaker.mu.Unlock()
+
+		statuses = append(statuses, EndpointStatus{
+			Addr:    addr,
+			Healthy: state != breakerOpen,
+			State:   state.String(),
+		})
+	}
+	return statuses
+}
+
+// filterHealthy drops candidates whose circuit breaker is tripped.
+func (r *Router) filterHealthy(candidates []string) []string {
+	healthy := make([]string, 0, len(candidates))
+	for _, addr := range candidates {
+		pool := r.getOrCreatePool(addr)
+		if !pool.breaker.Tripped() {
+			healthy = append(healthy, addr)
+		}
+	}
+	return healthy
+}
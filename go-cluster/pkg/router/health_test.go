@@ -0,0 +1,87 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestCircuitBreaker_TripsAfterThreshold(t *testing.T) {
+	cb := newCircuitBreaker(3, 50*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		cb.RecordFailure()
+		if cb.Tripped() {
+			t.Fatalf("breaker tripped early after %d failures", i+1)
+		}
+	}
+
+	cb.RecordFailure()
+	if !cb.Tripped() {
+		t.Error("expected breaker to be tripped after reaching threshold")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAfterCooldown(t *testing.T) {
+	cb := newCircuitBreaker(1, 10*time.Millisecond)
+	cb.RecordFailure()
+	if !cb.Tripped() {
+		t.Fatal("expected breaker to trip immediately")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !cb.Allow() {
+		t.Error("expected breaker to allow a half-open probe after cooldown")
+	}
+
+	cb.RecordSuccess()
+	if cb.Tripped() {
+		t.Error("expected breaker to close after a successful probe")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	cb := newCircuitBreaker(1, 10*time.Millisecond)
+	cb.RecordFailure()
+	time.Sleep(20 * time.Millisecond)
+
+	if !cb.Allow() {
+		t.Fatal("expected half-open probe to be allowed")
+	}
+	cb.RecordFailure()
+
+	if !cb.Tripped() {
+		t.Error("expected breaker to remain open after a failed half-open probe")
+	}
+}
+
+func TestRouter_EndpointHealth_Empty(t *testing.T) {
+	node := createTestNode(t)
+	defer node.Shutdown()
+
+	r := NewRouter(node, zap.NewNop())
+	defer r.Close()
+
+	if statuses := r.EndpointHealth(); len(statuses) != 0 {
+		t.Errorf("expected no tracked endpoints yet, got %v", statuses)
+	}
+}
+
+func TestRouter_FilterHealthy_DropsTrippedEndpoints(t *testing.T) {
+	node := createTestNode(t)
+	defer node.Shutdown()
+
+	r := NewRouter(node, zap.NewNop())
+	defer r.Close()
+
+	pool := r.getOrCreatePool("127.0.0.1:1")
+	for i := 0; i < pool.breaker.threshold; i++ {
+		pool.breaker.RecordFailure()
+	}
+
+	healthy := r.filterHealthy([]string{"127.0.0.1:1", "127.0.0.1:2"})
+	if len(healthy) != 1 || healthy[0] != "127.0.0.1:2" {
+		t.Errorf("expected only the untripped endpoint to remain, got %v", healthy)
+	}
+}
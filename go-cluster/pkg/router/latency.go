@@ -0,0 +1,59 @@
+package router
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyHistogram is a fixed-size moving window of recent latency
+// samples. It trades exact percentile accuracy for O(1) inserts and
+// bounded memory, which is all LatencyAwarePolicy needs to rank replicas.
+type latencyHistogram struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	filled  bool
+}
+
+func newLatencyHistogram(size int) *latencyHistogram {
+	return &latencyHistogram{samples: make([]time.Duration, size)}
+}
+
+// Observe records a new latency sample, overwriting the oldest one once
+// the window is full.
+func (h *latencyHistogram) Observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.samples[h.next] = d
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.filled = true
+	}
+}
+
+// P99 returns the 99th percentile latency over the current window, and
+// false if no samples have been recorded yet.
+func (h *latencyHistogram) P99() (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := h.next
+	if h.filled {
+		n = len(h.samples)
+	}
+	if n == 0 {
+		return 0, false
+	}
+
+	sorted := make([]time.Duration, n)
+	copy(sorted, h.samples[:n])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(float64(n) * 0.99)
+	if idx >= n {
+		idx = n - 1
+	}
+	return sorted[idx], true
+}
@@ -0,0 +1,194 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+)
+
+// RoutingPolicy selects a target endpoint from a set of candidate replicas
+// for a given key. Implementations may consult cluster topology, latency
+// history, or other signals to make the decision.
+type RoutingPolicy interface {
+	// Name identifies the policy for logging/config purposes.
+	Name() string
+	// Select picks one endpoint from candidates. It returns an error if it
+	// cannot make a decision, in which case the caller should fall back to
+	// the next policy in the chain.
+	Select(ctx context.Context, candidates []string, key []byte) (string, error)
+}
+
+// PolicyChain tries each policy in order, falling back to the next one
+// whenever the current policy fails to produce a candidate.
+type PolicyChain struct {
+	policies []RoutingPolicy
+}
+
+// NewPolicyChain builds a chain that tries policies in the given order.
+func NewPolicyChain(policies ...RoutingPolicy) *PolicyChain {
+	return &PolicyChain{policies: policies}
+}
+
+func (c *PolicyChain) Name() string {
+	return "chain"
+}
+
+func (c *PolicyChain) Select(ctx context.Context, candidates []string, key []byte) (string, error) {
+	var lastErr error
+	for _, p := range c.policies {
+		addr, err := p.Select(ctx, candidates, key)
+		if err == nil && addr != "" {
+			return addr, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no routing policy produced a candidate")
+	}
+	return "", lastErr
+}
+
+// RoundRobinPolicy cycles through candidates in order.
+type RoundRobinPolicy struct {
+	counter uint64
+}
+
+func NewRoundRobinPolicy() *RoundRobinPolicy {
+	return &RoundRobinPolicy{}
+}
+
+func (p *RoundRobinPolicy) Name() string { return "round_robin" }
+
+func (p *RoundRobinPolicy) Select(ctx context.Context, candidates []string, key []byte) (string, error) {
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("round_robin: no candidates")
+	}
+	idx := atomic.AddUint64(&p.counter, 1) % uint64(len(candidates))
+	return candidates[idx], nil
+}
+
+// TokenAwarePolicy picks the replica that owns the token range containing
+// the key, ScyllaDB/Cassandra-style. It falls back to reporting an error
+// when the ring has no owner for the key among the candidates, letting the
+// chain move on to the next policy.
+type TokenAwarePolicy struct {
+	ring func() *TokenRing
+}
+
+// NewTokenAwarePolicy takes a ring provider rather than a fixed ring so
+// that every Select sees the current shard map, not one frozen at
+// construction time.
+func NewTokenAwarePolicy(ring func() *TokenRing) *TokenAwarePolicy {
+	return &TokenAwarePolicy{ring: ring}
+}
+
+func (p *TokenAwarePolicy) Name() string { return "token_aware" }
+
+func (p *TokenAwarePolicy) Select(ctx context.Context, candidates []string, key []byte) (string, error) {
+	owner, ok := p.ring().Owner(key)
+	if !ok {
+		return "", fmt.Errorf("token_aware: no owner for key")
+	}
+	for _, c := range candidates {
+		if c == owner {
+			return owner, nil
+		}
+	}
+	return "", fmt.Errorf("token_aware: owner %s not among candidates", owner)
+}
+
+// LatencyAwarePolicy prefers the candidate with the lowest tracked p99
+// latency, skipping endpoints with no samples yet (so the chain falls
+// through to round robin on a cold start).
+type LatencyAwarePolicy struct {
+	pools *poolRegistry
+}
+
+// poolRegistry is the minimal surface LatencyAwarePolicy needs from Router,
+// kept separate so the policy doesn't import the concrete Router type.
+type poolRegistry interface {
+	latencyP99(addr string) (time.Duration, bool)
+}
+
+func NewLatencyAwarePolicy(pools poolRegistry) *LatencyAwarePolicy {
+	return &LatencyAwarePolicy{pools: pools}
+}
+
+func (p *LatencyAwarePolicy) Name() string { return "latency_aware" }
+
+func (p *LatencyAwarePolicy) Select(ctx context.Context, candidates []string, key []byte) (string, error) {
+	type scored struct {
+		addr string
+		p99  time.Duration
+	}
+	var scoredCandidates []scored
+	for _, c := range candidates {
+		if p99, ok := p.pools.latencyP99(c); ok {
+			scoredCandidates = append(scoredCandidates, scored{addr: c, p99: p99})
+		}
+	}
+	if len(scoredCandidates) == 0 {
+		return "", fmt.Errorf("latency_aware: no latency samples for candidates")
+	}
+	sort.Slice(scoredCandidates, func(i, j int) bool {
+		return scoredCandidates[i].p99 < scoredCandidates[j].p99
+	})
+	return scoredCandidates[0].addr, nil
+}
+
+// DCAwarePolicy restricts candidates to a preferred datacenter, falling
+// back to any candidate if none match.
+type DCAwarePolicy struct {
+	localDC  string
+	dcOfNode func(addr string) string
+}
+
+func NewDCAwarePolicy(localDC string, dcOfNode func(addr string) string) *DCAwarePolicy {
+	return &DCAwarePolicy{localDC: localDC, dcOfNode: dcOfNode}
+}
+
+func (p *DCAwarePolicy) Name() string { return "dc_aware" }
+
+func (p *DCAwarePolicy) Select(ctx context.Context, candidates []string, key []byte) (string, error) {
+	if p.localDC == "" || p.dcOfNode == nil {
+		return "", fmt.Errorf("dc_aware: no local DC configured")
+	}
+	for _, c := range candidates {
+		if p.dcOfNode(c) == p.localDC {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("dc_aware: no candidate in DC %s", p.localDC)
+}
+
+// TokenRing maps hashed key ranges to the replica set that owns them,
+// built from cluster.ShardInfo the same way cluster.Node shards keys
+// (see cluster.Node.GetShardForKey), so routing decisions agree with
+// shard ownership.
+type TokenRing struct {
+	shards    map[uint32]*cluster.ShardInfo
+	numShards uint32
+}
+
+// BuildTokenRing snapshots the node's current shard map into a ring that
+// RoutingPolicy implementations can consult without holding cluster locks.
+func BuildTokenRing(shards map[uint32]*cluster.ShardInfo, numShards uint32) *TokenRing {
+	return &TokenRing{shards: shards, numShards: numShards}
+}
+
+// Owner returns the leader of the shard owning key, if known.
+func (t *TokenRing) Owner(key []byte) (string, bool) {
+	if t.numShards == 0 {
+		return "", false
+	}
+	shardID := uint32(cluster.HashKey(key) % uint64(t.numShards))
+	shard, ok := t.shards[shardID]
+	if !ok || shard.Leader == "" {
+		return "", false
+	}
+	return shard.Leader, true
+}
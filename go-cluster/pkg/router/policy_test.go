@@ -0,0 +1,133 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+)
+
+func TestRoundRobinPolicy_CyclesCandidates(t *testing.T) {
+	p := NewRoundRobinPolicy()
+	candidates := []string{"a", "b", "c"}
+
+	seen := make(map[string]bool)
+	for i := 0; i < len(candidates); i++ {
+		addr, err := p.Select(context.Background(), candidates, nil)
+		if err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+		seen[addr] = true
+	}
+
+	if len(seen) != len(candidates) {
+		t.Errorf("expected round robin to visit all candidates, got %v", seen)
+	}
+}
+
+func TestRoundRobinPolicy_NoCandidates(t *testing.T) {
+	p := NewRoundRobinPolicy()
+	if _, err := p.Select(context.Background(), nil, nil); err == nil {
+		t.Error("expected error for empty candidate list")
+	}
+}
+
+func TestTokenAwarePolicy_PicksRingOwner(t *testing.T) {
+	shards := map[uint32]*cluster.ShardInfo{
+		0: {ID: 0, Leader: "node-a"},
+	}
+	ring := BuildTokenRing(shards, 1)
+	p := NewTokenAwarePolicy(func() *TokenRing { return ring })
+
+	addr, err := p.Select(context.Background(), []string{"node-a", "node-b"}, []byte("key1"))
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if addr != "node-a" {
+		t.Errorf("expected node-a, got %s", addr)
+	}
+}
+
+func TestTokenAwarePolicy_OwnerNotInCandidates(t *testing.T) {
+	shards := map[uint32]*cluster.ShardInfo{
+		0: {ID: 0, Leader: "node-a"},
+	}
+	ring := BuildTokenRing(shards, 1)
+	p := NewTokenAwarePolicy(func() *TokenRing { return ring })
+
+	if _, err := p.Select(context.Background(), []string{"node-b"}, []byte("key1")); err == nil {
+		t.Error("expected error when ring owner is not among candidates")
+	}
+}
+
+type fakePoolRegistry map[string]time.Duration
+
+func (f fakePoolRegistry) latencyP99(addr string) (time.Duration, bool) {
+	d, ok := f[addr]
+	return d, ok
+}
+
+func TestLatencyAwarePolicy_PicksLowestP99(t *testing.T) {
+	pools := fakePoolRegistry{
+		"slow": 100 * time.Millisecond,
+		"fast": 5 * time.Millisecond,
+	}
+	p := NewLatencyAwarePolicy(pools)
+
+	addr, err := p.Select(context.Background(), []string{"slow", "fast"}, nil)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if addr != "fast" {
+		t.Errorf("expected fast, got %s", addr)
+	}
+}
+
+func TestLatencyAwarePolicy_NoSamples(t *testing.T) {
+	p := NewLatencyAwarePolicy(fakePoolRegistry{})
+	if _, err := p.Select(context.Background(), []string{"a", "b"}, nil); err == nil {
+		t.Error("expected error when no latency samples are tracked")
+	}
+}
+
+func TestPolicyChain_FallsThroughOnError(t *testing.T) {
+	shards := map[uint32]*cluster.ShardInfo{
+		0: {ID: 0, Leader: "node-a"},
+	}
+	ring := BuildTokenRing(shards, 1)
+
+	chain := NewPolicyChain(
+		NewTokenAwarePolicy(func() *TokenRing { return ring }),
+		NewRoundRobinPolicy(),
+	)
+
+	// node-a is not a candidate, so TokenAwarePolicy fails and round robin
+	// should take over.
+	addr, err := chain.Select(context.Background(), []string{"node-b", "node-c"}, []byte("key1"))
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if addr != "node-b" && addr != "node-c" {
+		t.Errorf("unexpected fallback address: %s", addr)
+	}
+}
+
+func TestLatencyHistogram_P99(t *testing.T) {
+	h := newLatencyHistogram(8)
+	if _, ok := h.P99(); ok {
+		t.Error("expected no samples initially")
+	}
+
+	for i := 1; i <= 8; i++ {
+		h.Observe(time.Duration(i) * time.Millisecond)
+	}
+
+	p99, ok := h.P99()
+	if !ok {
+		t.Fatal("expected a sample after observations")
+	}
+	if p99 < time.Millisecond || p99 > 8*time.Millisecond {
+		t.Errorf("p99 out of expected range: %v", p99)
+	}
+}
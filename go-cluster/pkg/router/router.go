@@ -4,21 +4,137 @@ package router
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/peering"
 	"go.uber.org/zap"
 )
 
 // Router handles request routing to appropriate shards/nodes
 type Router struct {
-	node       *cluster.Node
-	logger     *zap.Logger
-	connPools  map[string]*ConnectionPool
-	poolsMu    sync.RWMutex
-	roundRobin uint64
+	node      *cluster.Node
+	logger    *zap.Logger
+	connPools map[string]*ConnectionPool
+	poolsMu   sync.RWMutex
+
+	// readPolicy/writePolicy select the target replica among the
+	// candidates returned by shard lookup. Writes are DCAware-free by
+	// default since they must land on the leader, but both chains can be
+	// reconfigured via SetReadPolicy/SetWritePolicy.
+	readPolicy  RoutingPolicy
+	writePolicy RoutingPolicy
+
+	peers *peering.Manager
+}
+
+// SetPeeringManager attaches a peering.Manager so RoutePeered can resolve
+// keys against imported remote shard maps.
+func (r *Router) SetPeeringManager(m *peering.Manager) {
+	r.peers = m
+}
+
+// PeeredRoute is the result of resolving a key against a remote cluster's
+// imported shard map: the target address within that cluster, tunneled
+// through one of its mesh gateways.
+type PeeredRoute struct {
+	GatewayAddr string
+	TargetAddr  string
+}
+
+// RoutePeered resolves a key against peerName's imported shard map and
+// returns the gateway to tunnel the request through. It does not require
+// the two clusters to share a Raft group; see pkg/peering for how the
+// shard map is imported.
+func (r *Router) RoutePeered(ctx context.Context, peerName, collection string, key []byte) (*PeeredRoute, error) {
+	if r.peers == nil {
+		return nil, fmt.Errorf("router: no peering manager configured")
+	}
+
+	state, ok := r.peers.Get(peerName)
+	if !ok {
+		return nil, fmt.Errorf("router: unknown peer %q", peerName)
+	}
+	if len(state.GatewayAddrs) == 0 {
+		return nil, fmt.Errorf("router: peer %q has no gateway addresses", peerName)
+	}
+
+	leader, ok := r.peers.ShardOwner(peerName, key, r.node.NumShards())
+	if !ok {
+		return nil, fmt.Errorf("router: no known shard owner for key on peer %q", peerName)
+	}
+
+	return &PeeredRoute{
+		GatewayAddr: state.GatewayAddrs[0],
+		TargetAddr:  leader,
+	}, nil
+}
+
+// NewDefaultReadPolicy builds the read-path policy chain from
+// cfg.RoutingPolicy (a comma-separated list, e.g.
+// "token_aware,latency_aware,round_robin"), falling back to that same
+// default order if the config value is empty or unrecognized.
+func NewDefaultReadPolicy(r *Router) RoutingPolicy {
+	names := strings.Split(r.node.GetConfig().RoutingPolicy, ",")
+	var policies []RoutingPolicy
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case "token_aware":
+			policies = append(policies, NewTokenAwarePolicy(r.TokenRing))
+		case "latency_aware":
+			policies = append(policies, NewLatencyAwarePolicy(r))
+		case "dc_aware":
+			policies = append(policies, NewDCAwarePolicy(r.node.GetConfig().DataCenter, r.dcOfNode))
+		case "round_robin":
+			policies = append(policies, NewRoundRobinPolicy())
+		}
+	}
+	if len(policies) == 0 {
+		policies = []RoutingPolicy{
+			NewTokenAwarePolicy(r.TokenRing),
+			NewLatencyAwarePolicy(r),
+			NewRoundRobinPolicy(),
+		}
+	}
+	return NewPolicyChain(policies...)
+}
+
+// dcOfNode resolves an endpoint's datacenter. The cluster package does
+// not track per-node DC tags yet, so this is currently a no-op hook for
+// DCAwarePolicy until that metadata exists.
+func (r *Router) dcOfNode(addr string) string {
+	return ""
+}
+
+// TokenRing builds a fresh snapshot of the current shard map for
+// token-aware routing decisions.
+func (r *Router) TokenRing() *TokenRing {
+	return BuildTokenRing(r.node.GetShards(), r.node.NumShards())
+}
+
+// SetReadPolicy overrides the routing policy used by RouteRead.
+func (r *Router) SetReadPolicy(p RoutingPolicy) {
+	r.readPolicy = p
+}
+
+// SetWritePolicy overrides the routing policy used by RouteWrite when a
+// shard has no known leader (writes otherwise always target the leader).
+func (r *Router) SetWritePolicy(p RoutingPolicy) {
+	r.writePolicy = p
+}
+
+// latencyP99 implements poolRegistry for LatencyAwarePolicy.
+func (r *Router) latencyP99(addr string) (time.Duration, bool) {
+	r.poolsMu.RLock()
+	pool, ok := r.connPools[addr]
+	r.poolsMu.RUnlock()
+	if !ok {
+		return 0, false
+	}
+	return pool.latencies.P99()
 }
 
 // ConnectionPool manages connections to a node
@@ -27,6 +143,9 @@ type ConnectionPool struct {
 	connections chan *Connection
 	maxSize     int
 	activeCount int32
+	latencies   *latencyHistogram
+	breaker     *circuitBreaker
+	stopHealth  chan struct{}
 }
 
 // Connection represents a connection to a storage node
@@ -38,11 +157,14 @@ type Connection struct {
 
 // NewRouter creates a new router
 func NewRouter(node *cluster.Node, logger *zap.Logger) *Router {
-	return &Router{
+	r := &Router{
 		node:      node,
 		logger:    logger,
 		connPools: make(map[string]*ConnectionPool),
 	}
+	r.readPolicy = NewDefaultReadPolicy(r)
+	r.writePolicy = NewRoundRobinPolicy()
+	return r
 }
 
 // Route determines the target node for a request
@@ -57,37 +179,57 @@ func (r *Router) Route(ctx context.Context, collection string, key []byte) (stri
 	return shard.Leader, nil
 }
 
-// RouteRead routes a read request (can go to any replica)
+// RouteRead routes a read request to a replica, preferring the
+// token-owning replica and falling back through the configured read
+// policy chain (see NewDefaultReadPolicy).
 func (r *Router) RouteRead(ctx context.Context, collection string, key []byte) (string, error) {
 	shard := r.node.GetShardForKey(key)
 	if shard == nil {
 		return "localhost", nil
 	}
 
-	// Load balance across replicas
-	replicas := append([]string{shard.Leader}, shard.Replicas...)
+	replicas := r.filterHealthy(candidateReplicas(shard))
 	if len(replicas) == 0 {
-		return "localhost", nil
+		return "", ErrNoHealthyReplica
 	}
 
-	idx := atomic.AddUint64(&r.roundRobin, 1) % uint64(len(replicas))
-	return replicas[idx], nil
+	addr, err := r.readPolicy.Select(ctx, replicas, key)
+	if err != nil {
+		return "", err
+	}
+	return addr, nil
 }
 
 // RouteWrite routes a write request (must go to leader)
 func (r *Router) RouteWrite(ctx context.Context, collection string, key []byte) (string, error) {
 	if !r.node.IsLeader() {
-		return r.node.LeaderAddr(), nil
+		leader := r.node.LeaderAddr()
+		if leader != "" && r.getOrCreatePool(leader).breaker.Tripped() {
+			return "", ErrNoHealthyReplica
+		}
+		return leader, nil
 	}
 
 	shard := r.node.GetShardForKey(key)
 	if shard == nil || shard.Leader == "" {
 		return "localhost", nil
 	}
+	if r.getOrCreatePool(shard.Leader).breaker.Tripped() {
+		return "", ErrNoHealthyReplica
+	}
 
 	return shard.Leader, nil
 }
 
+// candidateReplicas returns the leader followed by its replicas, the
+// ordering RoutingPolicy implementations see as "all holders of this key".
+func candidateReplicas(shard *cluster.ShardInfo) []string {
+	if shard.Leader == "" {
+		return shard.Replicas
+	}
+	return append([]string{shard.Leader}, shard.Replicas...)
+}
+
 // GetConnection gets a connection from the pool
 func (r *Router) GetConnection(addr string) (*Connection, error) {
 	pool := r.getOrCreatePool(addr)
@@ -135,15 +277,29 @@ func (r *Router) getOrCreatePool(addr string) *ConnectionPool {
 		return pool
 	}
 
+	cfg := r.node.GetConfig()
 	pool = &ConnectionPool{
 		addr:        addr,
 		connections: make(chan *Connection, 100),
 		maxSize:     100,
+		latencies:   newLatencyHistogram(256),
+		breaker:     newCircuitBreaker(cfg.CircuitBreakerThreshold, time.Duration(cfg.CircuitBreakerCooldownMs)*time.Millisecond),
+		stopHealth:  make(chan struct{}),
 	}
 	r.connPools[addr] = pool
+	go r.startHealthChecker(addr, time.Duration(cfg.HealthCheckIntervalMs)*time.Millisecond, pool.stopHealth)
 	return pool
 }
 
+// RecordLatency feeds an observed round-trip latency for addr into its
+// pool's moving histogram, so LatencyAwarePolicy can route around slow
+// replicas. Callers (e.g. the gRPC client) should call this after every
+// request/response cycle.
+func (r *Router) RecordLatency(addr string, d time.Duration) {
+	pool := r.getOrCreatePool(addr)
+	pool.latencies.Observe(d)
+}
+
 func (r *Router) createConnection(addr string) (*Connection, error) {
 	return &Connection{
 		addr:    addr,
@@ -152,10 +308,14 @@ func (r *Router) createConnection(addr string) (*Connection, error) {
 	}, nil
 }
 
-// HealthCheck checks the health of a node
-func (r *Router) HealthCheck(addr string) bool {
-	// TODO: Implement actual health check
-	return true
+// Close stops all background health checkers. It should be called when
+// the router is no longer needed (e.g. alongside cluster.Node.Shutdown).
+func (r *Router) Close() {
+	r.poolsMu.RLock()
+	defer r.poolsMu.RUnlock()
+	for _, pool := range r.connPools {
+		close(pool.stopHealth)
+	}
 }
 
 // GetClusterTopology returns the current cluster topology
@@ -5,8 +5,8 @@ import (
 	"os"
 	"testing"
 
-	"github.com/lumadb/cluster/pkg/cluster"
-	"github.com/lumadb/cluster/pkg/config"
+	"github.com/tdb-plus/cluster/pkg/cluster"
+	"github.com/tdb-plus/cluster/pkg/config"
 	"go.uber.org/zap"
 )
 
@@ -133,3 +133,16 @@ func TestRouter_ConnectionPool(t *testing.T) {
 
 	r.ReleaseConnection(conn)
 }
+
+func TestRouter_RoutePeered_NoManagerConfigured(t *testing.T) {
+	node := createTestNode(t)
+	defer node.Shutdown()
+	defer os.RemoveAll(node.GetConfig().DataDir)
+
+	r := NewRouter(node, zap.NewNop())
+	defer r.Close()
+
+	if _, err := r.RoutePeered(context.Background(), "peer-a", "users", []byte("key1")); err == nil {
+		t.Error("expected error when no peering manager is configured")
+	}
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"context"
+
+	"github.com/tdb-plus/cluster/pkg/cluster"
+)
+
+// WatchShards long-polls for a change to collection's routing-relevant
+// state (shard leadership/membership), returning as soon as the node's
+// watch index for collection advances past lastIndex, or ctx is done.
+// Callers (e.g. the meilisearch API layer or graphql resolvers) can cache
+// the shard map keyed by the returned index and only call this again
+// instead of polling GetClusterTopology on every request.
+//
+// Pass lastIndex 0 on the first call to get the current shards and index
+// immediately.
+func (r *Router) WatchShards(ctx context.Context, collection string, lastIndex uint64) (map[uint32]*cluster.ShardInfo, uint64, error) {
+	if lastIndex == 0 {
+		return r.node.GetShards(), r.node.WatchIndex(collection), nil
+	}
+
+	newIndex, err := r.node.WaitWatchIndex(ctx, collection, lastIndex)
+	if err != nil {
+		return nil, newIndex, err
+	}
+	return r.node.GetShards(), newIndex, nil
+}
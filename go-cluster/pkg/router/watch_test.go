@@ -0,0 +1,74 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRouter_WatchShards_ZeroIndexReturnsImmediately(t *testing.T) {
+	node := createTestNode(t)
+	defer node.Shutdown()
+
+	r := NewRouter(node, zap.NewNop())
+	defer r.Close()
+
+	shards, index, err := r.WatchShards(context.Background(), "events", 0)
+	if err != nil {
+		t.Fatalf("WatchShards failed: %v", err)
+	}
+	if shards == nil {
+		t.Fatal("expected non-nil shards")
+	}
+	_ = index
+}
+
+func TestRouter_WatchShards_BlocksUntilShardChange(t *testing.T) {
+	node := createTestNode(t)
+	defer node.Shutdown()
+
+	r := NewRouter(node, zap.NewNop())
+	defer r.Close()
+
+	// Establish a non-zero baseline index so the next WatchShards call
+	// actually takes the blocking path rather than the "lastIndex==0"
+	// immediate-return shortcut.
+	node.UpdateShardStatus(0, "", "active")
+	lastIndex := node.WatchIndex("events")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		node.UpdateShardStatus(0, "127.0.0.1:10000", "active")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, newIndex, err := r.WatchShards(ctx, "events", lastIndex)
+	if err != nil {
+		t.Fatalf("WatchShards failed: %v", err)
+	}
+	if newIndex <= lastIndex {
+		t.Fatalf("expected index to advance past %d, got %d", lastIndex, newIndex)
+	}
+}
+
+func TestRouter_WatchShards_ContextCancellation(t *testing.T) {
+	node := createTestNode(t)
+	defer node.Shutdown()
+
+	r := NewRouter(node, zap.NewNop())
+	defer r.Close()
+
+	node.UpdateShardStatus(0, "", "active")
+	lastIndex := node.WatchIndex("events")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, _, err := r.WatchShards(ctx, "events", lastIndex); err == nil {
+		t.Fatal("expected context deadline error")
+	}
+}
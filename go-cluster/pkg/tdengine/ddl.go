@@ -0,0 +1,240 @@
+package tdengine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tdb-plus/cluster/pkg/tdengine/parser"
+)
+
+// columnDefDDL renders col the way it appeared (or would have appeared)
+// in a CREATE STABLE/TABLE column or tag list, e.g. "value DOUBLE",
+// "name BINARY(32)", or "price DECIMAL(18,4)".
+func columnDefDDL(col Column) string {
+	t := typeToString(col.Type)
+	switch col.Type {
+	case TSDB_DATA_TYPE_BINARY, TSDB_DATA_TYPE_NCHAR:
+		if col.Length > 0 {
+			t = fmt.Sprintf("%s(%d)", t, col.Length)
+		}
+	case TSDB_DATA_TYPE_DECIMAL:
+		t = fmt.Sprintf("%s(%d,%d)", t, col.Precision, col.Scale)
+	}
+	return col.Name + " " + t
+}
+
+// createSTableDDL renders a supertable's definition as the
+// "CREATE STABLE ... (cols) TAGS (tags)" statement that created it.
+func createSTableDDL(db string, stable *SuperTable) string {
+	cols := make([]string, len(stable.Schema))
+	for i, c := range stable.Schema {
+		cols[i] = columnDefDDL(c)
+	}
+	tags := make([]string, len(stable.Tags))
+	for i, t := range stable.Tags {
+		tags[i] = columnDefDDL(t)
+	}
+	return fmt.Sprintf("CREATE STABLE %s.%s (%s) TAGS (%s)",
+		db, stable.Name, strings.Join(cols, ", "), strings.Join(tags, ", "))
+}
+
+// createTableDDL renders a plain table's "CREATE TABLE ... (cols)"
+// statement.
+func createTableDDL(db string, table *Table) string {
+	cols := make([]string, len(table.Schema))
+	for i, c := range table.Schema {
+		cols[i] = columnDefDDL(c)
+	}
+	return fmt.Sprintf("CREATE TABLE %s.%s (%s)", db, table.Name, strings.Join(cols, ", "))
+}
+
+// createSubTableDDL renders a subtable's
+// "CREATE TABLE ... USING ... TAGS (...)" statement, reconstructing each
+// tag's literal text from its stored, already-typed value.
+func createSubTableDDL(db string, table *Table, stableName string, tagDefs []Column) string {
+	vals := make([]string, 0, len(tagDefs))
+	for _, def := range tagDefs {
+		vals = append(vals, sqlLiteral(table.Tags[def.Name], def.Type))
+	}
+	return fmt.Sprintf("CREATE TABLE %s.%s USING %s.%s TAGS (%s)",
+		db, table.Name, db, stableName, strings.Join(vals, ", "))
+}
+
+// createDatabaseDDL renders the "CREATE DATABASE" statement that created
+// database, including "WITH HINTED HANDOFF" if the engine still has a
+// hinted-handoff queue registered for it. Table-level retention/compression
+// options aren't modeled anywhere in the parser or Database yet, so they
+// have nothing to round-trip.
+func (e *Engine) createDatabaseDDL(database *Database) string {
+	ddl := fmt.Sprintf("CREATE DATABASE %s PRECISION '%s'", database.Name, database.Precision)
+	if _, ok := e.hintedHandoff[database.Name]; ok {
+		ddl += " WITH HINTED HANDOFF"
+	}
+	return ddl
+}
+
+// sqlLiteral renders an already-typed engine value (as stored on
+// Table.Tags or returned by a query) back to SQL literal text, the
+// inverse of convertLiteral.
+func sqlLiteral(v interface{}, colType int) string {
+	switch colType {
+	case TSDB_DATA_TYPE_BOOL:
+		if b, ok := v.(bool); ok {
+			if b {
+				return "TRUE"
+			}
+			return "FALSE"
+		}
+	case TSDB_DATA_TYPE_BINARY, TSDB_DATA_TYPE_NCHAR, TSDB_DATA_TYPE_JSON, TSDB_DATA_TYPE_DECIMAL:
+		if s, ok := v.(string); ok {
+			return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+		}
+	}
+	switch val := v.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case bool:
+		if val {
+			return "TRUE"
+		}
+		return "FALSE"
+	case string:
+		return "'" + strings.ReplaceAll(val, "'", "''") + "'"
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// showCreateTable implements SHOW CREATE TABLE name, returning a
+// single-row, single-column ("Create Table", BINARY) result containing
+// DDL that recreates the table byte-identically.
+func (e *Engine) showCreateTable(db string, stmt *parser.ShowCreateTableStmt) (*Response, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if stmt.Database != "" {
+		db = stmt.Database
+	}
+	database := e.getDatabase(db)
+	if database == nil {
+		return nil, fmt.Errorf("database not found: %s", db)
+	}
+
+	if table, ok := database.Tables[stmt.Name]; ok {
+		return createTableResponse(createTableDDL(db, table)), nil
+	}
+	for _, stable := range database.STables {
+		if table, ok := stable.SubTables[stmt.Name]; ok {
+			return createTableResponse(createSubTableDDL(db, table, stable.Name, stable.Tags)), nil
+		}
+	}
+	return nil, fmt.Errorf("table not found: %s", stmt.Name)
+}
+
+// showCreateSTable implements SHOW CREATE STABLE name.
+func (e *Engine) showCreateSTable(db string, stmt *parser.ShowCreateSTableStmt) (*Response, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if stmt.Database != "" {
+		db = stmt.Database
+	}
+	database := e.getDatabase(db)
+	if database == nil {
+		return nil, fmt.Errorf("database not found: %s", db)
+	}
+	stable, ok := database.STables[stmt.Name]
+	if !ok {
+		return nil, fmt.Errorf("supertable not found: %s", stmt.Name)
+	}
+	return createTableResponse(createSTableDDL(db, stable)), nil
+}
+
+func createTableResponse(ddl string) *Response {
+	return &Response{
+		Code: TSDB_CODE_SUCCESS,
+		ColumnMeta: [][]interface{}{
+			{"Create Table", TSDB_DATA_TYPE_BINARY, len(ddl)},
+		},
+		Data: [][]interface{}{{ddl}},
+		Rows: 1,
+	}
+}
+
+// Dump streams a mysqldump-style logical backup of dbName to w: the
+// CREATE DATABASE statement, every CREATE STABLE, every CREATE TABLE
+// (including subtables, via their USING ... TAGS form), and one batched
+// INSERT per table covering every stored row. It is built entirely from
+// metadata already held in memory, so it holds the engine's read lock for
+// its full duration.
+func (e *Engine) Dump(w io.Writer, dbName string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	database := e.databases[dbName]
+	if database == nil {
+		return fmt.Errorf("database not found: %s", dbName)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s;\n", e.createDatabaseDDL(database))
+
+	for _, stable := range database.STables {
+		fmt.Fprintf(bw, "%s;\n", createSTableDDL(dbName, stable))
+		for _, table := range stable.SubTables {
+			fmt.Fprintf(bw, "%s;\n", createSubTableDDL(dbName, table, stable.Name, stable.Tags))
+			if err := e.dumpRows(bw, dbName, table); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, table := range database.Tables {
+		fmt.Fprintf(bw, "%s;\n", createTableDDL(dbName, table))
+		if err := e.dumpRows(bw, dbName, table); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// dumpRows writes one batched INSERT statement covering every row stored
+// for table, or nothing if the table is empty.
+func (e *Engine) dumpRows(w io.Writer, db string, table *Table) error {
+	rows, err := e.store.Query(db, table.Name, math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	nonTS := make([]Column, 0, len(table.Schema))
+	for _, c := range table.Schema {
+		if c.Type != TSDB_DATA_TYPE_TIMESTAMP {
+			nonTS = append(nonTS, c)
+		}
+	}
+
+	groups := make([]string, len(rows))
+	for i, r := range rows {
+		vals := make([]string, 0, len(nonTS)+1)
+		vals = append(vals, strconv.FormatInt(r.Ts, 10))
+		for j, c := range nonTS {
+			if j < len(r.Values) {
+				vals = append(vals, sqlLiteral(r.Values[j], c.Type))
+			}
+		}
+		groups[i] = "(" + strings.Join(vals, ", ") + ")"
+	}
+
+	fmt.Fprintf(w, "INSERT INTO %s.%s VALUES %s;\n", db, table.Name, strings.Join(groups, " "))
+	return nil
+}
@@ -0,0 +1,152 @@
+package tdengine
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrDecimalOverflow is returned when a literal has more significant
+// digits than its DECIMAL/NUMERIC column's declared precision allows.
+var ErrDecimalOverflow = errors.New("tdengine: decimal value exceeds declared precision")
+
+// Decimal is an exact base-10 value, Coef*10^-Scale, stored as an
+// arbitrary-precision integer coefficient rather than a float64 so
+// round-tripping through INSERT/SELECT never loses or rounds digits.
+// Decimal values are persisted through the storage layer as their
+// canonical String() form (see storageColumnType's TSDB_DATA_TYPE_DECIMAL
+// case), so this type only needs to exist at the engine layer.
+type Decimal struct {
+	Coef  *big.Int
+	Scale int
+}
+
+// ParseDecimal parses s (e.g. "123.45", "-0.5") into a Decimal with
+// exactly scale fractional digits, returning ErrDecimalOverflow if the
+// total digit count would exceed precision. precision <= 0 disables the
+// check.
+func ParseDecimal(s string, precision, scale int) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	if len(fracPart) > scale {
+		return Decimal{}, fmt.Errorf("tdengine: decimal %q has more than %d fractional digits", s, scale)
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+
+	coef, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("tdengine: invalid decimal literal %q", s)
+	}
+	if neg {
+		coef.Neg(coef)
+	}
+
+	d := Decimal{Coef: coef, Scale: scale}
+	if precision > 0 && d.digitCount() > precision {
+		return Decimal{}, ErrDecimalOverflow
+	}
+	return d, nil
+}
+
+// digitCount returns the number of base-10 digits in the coefficient's
+// absolute value (at least 1, for zero).
+func (d Decimal) digitCount() int {
+	abs := new(big.Int).Abs(d.Coef)
+	if abs.Sign() == 0 {
+		return 1
+	}
+	return len(abs.String())
+}
+
+// String renders d back to its canonical "[-]ddd[.ddd]" form.
+func (d Decimal) String() string {
+	if d.Coef == nil {
+		return "0"
+	}
+	neg := d.Coef.Sign() < 0
+	digits := new(big.Int).Abs(d.Coef).String()
+	if d.Scale == 0 {
+		if neg {
+			return "-" + digits
+		}
+		return digits
+	}
+
+	for len(digits) <= d.Scale {
+		digits = "0" + digits
+	}
+	intPart, fracPart := digits[:len(digits)-d.Scale], digits[len(digits)-d.Scale:]
+	out := intPart + "." + fracPart
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
+
+// rescale returns a copy of d expressed at newScale, which must be >= d.Scale.
+func (d Decimal) rescale(newScale int) Decimal {
+	if newScale == d.Scale {
+		return d
+	}
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(newScale-d.Scale)), nil)
+	return Decimal{Coef: new(big.Int).Mul(d.Coef, factor), Scale: newScale}
+}
+
+// AddDecimal returns a+b, rescaling both operands to the wider of the two
+// scales first so the result stays exact.
+func AddDecimal(a, b Decimal) Decimal {
+	scale := a.Scale
+	if b.Scale > scale {
+		scale = b.Scale
+	}
+	a, b = a.rescale(scale), b.rescale(scale)
+	return Decimal{Coef: new(big.Int).Add(a.Coef, b.Coef), Scale: scale}
+}
+
+// CompareDecimal returns -1, 0, or 1 as a is less than, equal to, or
+// greater than b, rescaling both to the same scale first.
+func CompareDecimal(a, b Decimal) int {
+	scale := a.Scale
+	if b.Scale > scale {
+		scale = b.Scale
+	}
+	return a.rescale(scale).Coef.Cmp(b.rescale(scale).Coef)
+}
+
+// DecimalFromInt64 converts a BIGINT/UBIGINT operand to a Decimal at the
+// given scale, for query engine operators that mix integer columns with
+// DECIMAL ones.
+func DecimalFromInt64(v int64, scale int) Decimal {
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	return Decimal{Coef: new(big.Int).Mul(big.NewInt(v), factor), Scale: scale}
+}
+
+// DecimalFromFloat64 converts a FLOAT/DOUBLE operand to a Decimal at the
+// given scale for mixed-type arithmetic. The conversion is inherently
+// lossy, same as any SQL engine's float-to-decimal CAST, since float64
+// isn't an exact base-10 representation to begin with.
+func DecimalFromFloat64(v float64, scale int) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(v, 'f', scale, 64), 0, scale)
+	if err != nil {
+		// FormatFloat with an explicit scale always yields a literal
+		// ParseDecimal can parse at that same scale.
+		return Decimal{Coef: big.NewInt(0), Scale: scale}
+	}
+	return d
+}
@@ -0,0 +1,96 @@
+package tdengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tdb-plus/cluster/pkg/tdengine/parser"
+	"github.com/tdb-plus/cluster/pkg/tdengine/storage"
+)
+
+func TestParseDecimal_RoundTrip(t *testing.T) {
+	d, err := ParseDecimal("123.45", 5, 2)
+	if err != nil {
+		t.Fatalf("ParseDecimal failed: %v", err)
+	}
+	if got := d.String(); got != "123.45" {
+		t.Fatalf("String() = %q, want 123.45", got)
+	}
+}
+
+func TestParseDecimal_Overflow(t *testing.T) {
+	_, err := ParseDecimal("123.45", 4, 2)
+	if !errors.Is(err, ErrDecimalOverflow) {
+		t.Fatalf("expected ErrDecimalOverflow for 5 digits against precision 4, got %v", err)
+	}
+}
+
+func TestAddDecimal_MixedScale(t *testing.T) {
+	a, _ := ParseDecimal("1.5", 0, 1)
+	b, _ := ParseDecimal("2.25", 0, 2)
+	sum := AddDecimal(a, b)
+	if got := sum.String(); got != "3.75" {
+		t.Fatalf("AddDecimal(1.5, 2.25) = %q, want 3.75", got)
+	}
+}
+
+func TestCompareDecimal_MixedScale(t *testing.T) {
+	a, _ := ParseDecimal("1.50", 0, 2)
+	b, _ := ParseDecimal("1.5", 0, 1)
+	if CompareDecimal(a, b) != 0 {
+		t.Fatalf("expected 1.50 == 1.5 across scales")
+	}
+}
+
+func TestDecimalFromInt64(t *testing.T) {
+	d := DecimalFromInt64(42, 2)
+	if got := d.String(); got != "42.00" {
+		t.Fatalf("DecimalFromInt64(42, 2) = %q, want 42.00", got)
+	}
+}
+
+func TestAggregateDecimalField_SumAvgMinMax(t *testing.T) {
+	rows := []storage.Row{
+		{Values: []interface{}{"10.00"}},
+		{Values: []interface{}{"20.50"}},
+		{Values: []interface{}{"5.25"}},
+	}
+
+	cases := []struct {
+		fn   string
+		want string
+	}{
+		{"SUM", "35.75"},
+		{"MIN", "5.25"},
+		{"MAX", "20.50"},
+	}
+	for _, c := range cases {
+		got, err := aggregateDecimalField(rows, parser.SelectField{Func: c.fn, Name: "price"}, 0, 2)
+		if err != nil {
+			t.Fatalf("%s: %v", c.fn, err)
+		}
+		if got != c.want {
+			t.Fatalf("%s = %v, want %s", c.fn, got, c.want)
+		}
+	}
+}
+
+func TestFilterDecimalRows_CoercesBigintOperand(t *testing.T) {
+	rows := []storage.Row{
+		{Values: []interface{}{"9.99"}},
+		{Values: []interface{}{"10.00"}},
+		{Values: []interface{}{"15.00"}},
+	}
+
+	// "price > 10" is a BIGINT literal compared against a DECIMAL(.,2)
+	// column; decimalOperand must coerce it via DecimalFromInt64 rather
+	// than failing to parse it as a decimal string.
+	cond := parser.WhereCond{Left: "price", Operator: ">", Right: "10"}
+	out, err := filterDecimalRows(rows, 0, cond, 2)
+	if err != nil {
+		t.Fatalf("filterDecimalRows failed: %v", err)
+	}
+	if len(out) != 1 || out[0].Values[0] != "15.00" {
+		t.Fatalf("filterDecimalRows(> 10) = %v, want only 15.00", out)
+	}
+}
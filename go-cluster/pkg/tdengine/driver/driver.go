@@ -0,0 +1,336 @@
+// Package driver wires a database/sql/driver.Driver on top of a
+// tdengine.Engine, so an embedded engine can be used through the standard
+// library's *sql.DB/*sql.Rows instead of the engine's own Response shape.
+//
+// Because an Engine is an in-process Go object rather than something
+// reachable over a network address, a driver.Conn needs a way to find the
+// Engine a DSN refers to. RegisterEngine associates a name with an Engine;
+// sql.Open("tdb", dsn) then resolves dsn of the form "name/database" (the
+// database segment is optional) back to that Engine and database, the same
+// way database/sql drivers for embedded stores (e.g. sqlite's ":memory:"
+// connections) keep engine state out of the DSN string itself.
+package driver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tdb-plus/cluster/pkg/tdengine"
+)
+
+// DefaultEngineName is the engine name RegisterEngine uses when the DSN
+// passed to sql.Open omits a "name/" prefix.
+const DefaultEngineName = "default"
+
+func init() {
+	sql.Register("tdb", &Driver{})
+}
+
+var (
+	enginesMu sync.RWMutex
+	engines   = make(map[string]*tdengine.Engine)
+)
+
+// RegisterEngine makes e reachable as sql.Open("tdb", dsn) for any dsn
+// whose "name/" prefix is name (or for any dsn with no prefix, when name
+// is DefaultEngineName).
+func RegisterEngine(name string, e *tdengine.Engine) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	engines[name] = e
+}
+
+func lookupEngine(name string) (*tdengine.Engine, bool) {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	e, ok := engines[name]
+	return e, ok
+}
+
+// parseDSN splits "name/database" into its engine name and database; the
+// "name/" prefix is optional and defaults to DefaultEngineName.
+func parseDSN(dsn string) (engineName, db string) {
+	if i := strings.IndexByte(dsn, '/'); i >= 0 {
+		return dsn[:i], dsn[i+1:]
+	}
+	return DefaultEngineName, dsn
+}
+
+// Driver is a database/sql/driver.Driver backed by a registered
+// tdengine.Engine. Register engines with RegisterEngine before calling
+// sql.Open("tdb", dsn).
+type Driver struct{}
+
+// Open implements driver.Driver.
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	engineName, db := parseDSN(dsn)
+	e, ok := lookupEngine(engineName)
+	if !ok {
+		return nil, fmt.Errorf("tdengine/driver: no engine registered under %q; call RegisterEngine first", engineName)
+	}
+	return &conn{engine: e, db: db}, nil
+}
+
+// conn is a database/sql/driver.Conn over a single (engine, database) pair.
+type conn struct {
+	engine *tdengine.Engine
+	db     string
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+// Begin implements driver.Conn. The engine has no transaction support, so
+// Commit/Rollback are no-ops rather than errors, matching how analytic
+// engines without multi-statement transactions (e.g. ClickHouse) expose a
+// degenerate driver.Tx instead of refusing database/sql's Begin entirely.
+func (c *conn) Begin() (driver.Tx, error) {
+	return noopTx{}, nil
+}
+
+type noopTx struct{}
+
+func (noopTx) Commit() error   { return nil }
+func (noopTx) Rollback() error { return nil }
+
+// stmt is a prepared statement. The engine's SQL has no bind-parameter
+// syntax, so NumInput is always 0 and query is executed as-is.
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return 0 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	resp, err := s.conn.engine.Execute(s.conn.db, s.query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return result{affected: int64(resp.AffectedRows)}, nil
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	resp, err := s.conn.engine.Execute(s.conn.db, s.query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(resp), nil
+}
+
+// result implements driver.Result. The engine doesn't assign
+// auto-increment IDs, so LastInsertId is unsupported.
+type result struct {
+	affected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return 0, fmt.Errorf("tdengine/driver: LastInsertId is not supported")
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+// colMeta is one column's descriptor as found in Response.ColumnMeta,
+// which the engine represents positionally as
+// [name, TSDB_DATA_TYPE_*, length, precision, scale]; the trailing two
+// are only meaningful for TSDB_DATA_TYPE_DECIMAL and are absent from
+// ColumnMeta rows built before DECIMAL existed.
+type colMeta struct {
+	name      string
+	typ       int
+	length    int
+	precision int
+	scale     int
+}
+
+// rows implements driver.Rows plus the optional
+// RowsColumnTypeDatabaseTypeName/RowsColumnTypeLength/RowsColumnTypeScanType/
+// RowsColumnTypeNullable interfaces so database/sql's *sql.ColumnType
+// reporting works against the engine's own type system.
+type rows struct {
+	cols []colMeta
+	data [][]interface{}
+	pos  int
+}
+
+func newRows(resp *tdengine.Response) *rows {
+	cols := make([]colMeta, 0, len(resp.ColumnMeta))
+	for _, m := range resp.ColumnMeta {
+		cm := colMeta{}
+		if len(m) > 0 {
+			cm.name, _ = m[0].(string)
+		}
+		if len(m) > 1 {
+			if t, ok := m[1].(int); ok {
+				cm.typ = t
+			}
+		}
+		if len(m) > 2 {
+			if l, ok := m[2].(int); ok {
+				cm.length = l
+			}
+		}
+		if len(m) > 3 {
+			if p, ok := m[3].(int); ok {
+				cm.precision = p
+			}
+		}
+		if len(m) > 4 {
+			if s, ok := m[4].(int); ok {
+				cm.scale = s
+			}
+		}
+		cols = append(cols, cm)
+	}
+	return &rows{cols: cols, data: resp.Data}
+}
+
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.cols))
+	for i, c := range r.cols {
+		names[i] = c.name
+	}
+	return names
+}
+
+func (r *rows) Close() error { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.data) {
+		return io.EOF
+	}
+	row := r.data[r.pos]
+	r.pos++
+
+	for i := range dest {
+		if i >= len(row) {
+			dest[i] = nil
+			continue
+		}
+		dest[i] = toDriverValue(row[i], r.cols[i].typ)
+	}
+	return nil
+}
+
+// toDriverValue converts one of the engine's result values into one of the
+// types driver.Value allows (int64, float64, bool, []byte, string,
+// time.Time, or nil), using colType to disambiguate representations the
+// engine itself stores loosely (e.g. TIMESTAMP as an int64 of Unix millis).
+func toDriverValue(v interface{}, colType int) driver.Value {
+	if v == nil {
+		return nil
+	}
+	if colType == tdengine.TSDB_DATA_TYPE_TIMESTAMP {
+		switch ts := v.(type) {
+		case int64:
+			return time.UnixMilli(ts)
+		case time.Time:
+			return ts
+		}
+	}
+	switch colType {
+	case tdengine.TSDB_DATA_TYPE_BINARY:
+		if s, ok := v.(string); ok {
+			return []byte(s)
+		}
+	case tdengine.TSDB_DATA_TYPE_JSON:
+		if s, ok := v.(string); ok {
+			return driver.Value(json.RawMessage(s))
+		}
+	}
+	switch val := v.(type) {
+	case int64, float64, bool, []byte, string, time.Time:
+		return val
+	case int:
+		return int64(val)
+	case uint64:
+		// driver.Value has no unsigned integer type; render as the
+		// nearest representable int64, same widening convertInfluxValue
+		// already performs for storage.
+		return int64(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName.
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return tdengine.TypeToString(r.cols[index].typ)
+}
+
+// ColumnTypeLength implements driver.RowsColumnTypeLength. Only the
+// variable-length BINARY/NCHAR types have a meaningful length.
+func (r *rows) ColumnTypeLength(index int) (int64, bool) {
+	switch r.cols[index].typ {
+	case tdengine.TSDB_DATA_TYPE_BINARY, tdengine.TSDB_DATA_TYPE_NCHAR:
+		return int64(r.cols[index].length), true
+	default:
+		return 0, false
+	}
+}
+
+// ColumnTypePrecisionScale implements driver.RowsColumnTypePrecisionScale.
+// Only DECIMAL/NUMERIC columns declare a precision and scale.
+func (r *rows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if r.cols[index].typ != tdengine.TSDB_DATA_TYPE_DECIMAL {
+		return 0, 0, false
+	}
+	return int64(r.cols[index].precision), int64(r.cols[index].scale), true
+}
+
+// ColumnTypeNullable implements driver.RowsColumnTypeNullable. The engine
+// doesn't model NULL for declared columns (missing fields fall back to a
+// zero value, see zeroValueForType), so every column reports as non-nullable.
+func (r *rows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return false, true
+}
+
+// ColumnTypeScanType implements driver.RowsColumnTypeScanType.
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	switch r.cols[index].typ {
+	case tdengine.TSDB_DATA_TYPE_BOOL:
+		return reflect.TypeOf(false)
+	case tdengine.TSDB_DATA_TYPE_TINYINT:
+		return reflect.TypeOf(int8(0))
+	case tdengine.TSDB_DATA_TYPE_SMALLINT:
+		return reflect.TypeOf(int16(0))
+	case tdengine.TSDB_DATA_TYPE_INT:
+		return reflect.TypeOf(int32(0))
+	case tdengine.TSDB_DATA_TYPE_BIGINT:
+		return reflect.TypeOf(int64(0))
+	case tdengine.TSDB_DATA_TYPE_UBIGINT:
+		return reflect.TypeOf(uint64(0))
+	case tdengine.TSDB_DATA_TYPE_FLOAT:
+		return reflect.TypeOf(float32(0))
+	case tdengine.TSDB_DATA_TYPE_DOUBLE:
+		return reflect.TypeOf(float64(0))
+	case tdengine.TSDB_DATA_TYPE_TIMESTAMP:
+		return reflect.TypeOf(time.Time{})
+	case tdengine.TSDB_DATA_TYPE_BINARY:
+		return reflect.TypeOf([]byte(nil))
+	case tdengine.TSDB_DATA_TYPE_NCHAR:
+		return reflect.TypeOf("")
+	case tdengine.TSDB_DATA_TYPE_JSON:
+		return reflect.TypeOf(json.RawMessage(nil))
+	case tdengine.TSDB_DATA_TYPE_DECIMAL:
+		// Emitted as its canonical decimal string (see
+		// storageColumnType's TSDB_DATA_TYPE_DECIMAL case); scanning into
+		// a string preserves every digit, unlike float64.
+		return reflect.TypeOf("")
+	default:
+		return reflect.TypeOf("")
+	}
+}
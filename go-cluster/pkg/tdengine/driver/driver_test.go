@@ -0,0 +1,85 @@
+package driver
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/tdb-plus/cluster/pkg/tdengine"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	engine := tdengine.NewEngine()
+	RegisterEngine(t.Name(), engine)
+
+	if _, err := engine.Execute("", "CREATE DATABASE testdb", nil); err != nil {
+		t.Fatalf("CREATE DATABASE: %v", err)
+	}
+	if _, err := engine.Execute("testdb", "CREATE TABLE readings (ts TIMESTAMP, value DOUBLE, label BINARY(16))", nil); err != nil {
+		t.Fatalf("CREATE TABLE: %v", err)
+	}
+
+	db, err := sql.Open("tdb", t.Name()+"/testdb")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestQueryRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := db.Exec("INSERT INTO readings VALUES (1000, 3.5, 'ok')"); err != nil {
+		t.Fatalf("Exec INSERT: %v", err)
+	}
+
+	rows, err := db.Query("SELECT ts, value, label FROM readings")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("ColumnTypes: %v", err)
+	}
+	if got, want := cols[0].DatabaseTypeName(), "TIMESTAMP"; got != want {
+		t.Errorf("column 0 DatabaseTypeName = %q, want %q", got, want)
+	}
+	if got, want := cols[2].DatabaseTypeName(), "BINARY"; got != want {
+		t.Errorf("column 2 DatabaseTypeName = %q, want %q", got, want)
+	}
+	if length, ok := cols[2].Length(); !ok || length != 16 {
+		t.Errorf("column 2 Length = (%d, %v), want (16, true)", length, ok)
+	}
+
+	if !rows.Next() {
+		t.Fatalf("expected one row, got none: %v", rows.Err())
+	}
+	var ts time.Time
+	var value float64
+	var label []byte
+	if err := rows.Scan(&ts, &value, &label); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if ts.UnixMilli() != 1000 || value != 3.5 || string(label) != "ok" {
+		t.Errorf("got (%d, %v, %q), want (1000, 3.5, \"ok\")", ts.UnixMilli(), value, label)
+	}
+	if rows.Next() {
+		t.Fatalf("expected exactly one row")
+	}
+}
+
+func TestOpenUnregisteredEngine(t *testing.T) {
+	db, err := sql.Open("tdb", "does-not-exist/testdb")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err == nil {
+		t.Fatalf("expected Ping against an unregistered engine to fail")
+	}
+}
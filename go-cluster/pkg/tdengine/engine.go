@@ -2,36 +2,60 @@
 package tdengine
 
 import (
+	"encoding/json"
 	"fmt"
-	"regexp"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
-)
 
-var (
-	reCreateDB       = regexp.MustCompile(`(?i)CREATE\s+DATABASE\s+(IF\s+NOT\s+EXISTS\s+)?(\w+)`)
-	reDropDB         = regexp.MustCompile(`(?i)DROP\s+DATABASE\s+(IF\s+EXISTS\s+)?(\w+)`)
-	reUseDB          = regexp.MustCompile(`(?i)USE\s+(\w+)`)
-	rePrecision      = regexp.MustCompile(`(?i)PRECISION\s+'(\w+)'`)
-	reCreateStable   = regexp.MustCompile(`(?i)CREATE\s+(?:STABLE|TABLE)\s+(IF\s+NOT\s+EXISTS\s+)?(?:(\w+)\.)?(\w+)\s*\((.*?)\)\s*TAGS\s*\((.*?)\)`)
-	reCreateTable    = regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(IF\s+NOT\s+EXISTS\s+)?(?:(\w+)\.)?(\w+)\s*\((.*?)\)`)
-	reCreateSubTable = regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(IF\s+NOT\s+EXISTS\s+)?(?:(\w+)\.)?(\w+)\s+USING\s+(?:(\w+)\.)?(\w+)\s+TAGS\s*\((.*?)\)`)
-	reDropTable      = regexp.MustCompile(`(?i)DROP\s+TABLE\s+(IF\s+EXISTS\s+)?(?:(\w+)\.)?(\w+)`)
-	reShowTables     = regexp.MustCompile(`(?i)SHOW\s+TABLES\s*(FROM\s+(\w+))?`)
-	reDescribe       = regexp.MustCompile(`(?i)DESCRIBE\s+(?:(\w+)\.)?(\w+)`)
-	reCreateStream   = regexp.MustCompile(`(?i)CREATE\s+STREAM\s+(IF\s+NOT\s+EXISTS\s+)?(\w+)\s+(?:TRIGGER\s+(\w+)\s+)?(?:WATERMARK\s+(\w+)\s+)?INTO\s+(\w+)\s+AS\s+(.+)`)
-	reDropStream     = regexp.MustCompile(`(?i)DROP\s+STREAM\s+(IF\s+EXISTS\s+)?(\w+)`)
+	"github.com/tdb-plus/cluster/pkg/tdengine/hintedhandoff"
+	"github.com/tdb-plus/cluster/pkg/tdengine/parser"
+	"github.com/tdb-plus/cluster/pkg/tdengine/storage"
 )
 
+// defaultHintedHandoffDir is the base directory under which per-database
+// hinted-handoff segment queues are created, mirroring pkg/config's
+// "./data" default data directory.
+const defaultHintedHandoffDir = "data/hintedhandoff"
+
+// SchemaChangeEvent records one automatic supertable schema change made by
+// evolveSchema in response to an InfluxDB line protocol write, e.g. widening
+// a column's type or adding a new field as a column. SHOW SCHEMA CHANGES
+// reports these so operators can audit implicit schema drift.
+type SchemaChangeEvent struct {
+	Database string
+	STable   string
+	Field    string
+	OldType  string // empty if Field is a newly added column
+	NewType  string
+	At       time.Time
+}
+
 // Engine is the TDengine SQL execution engine
 type Engine struct {
-	databases map[string]*Database
-	users     map[string]*UserDefinition
-	streams   map[string]*StreamDefinition
-	topics    map[string]*TopicDefinition
-	mu        sync.RWMutex
+	databases      map[string]*Database
+	users          map[string]*UserDefinition
+	streams        map[string]*StreamDefinition
+	streamRuntimes map[string]*streamRuntime
+	topics         map[string]*TopicDefinition
+	topicRuntimes  map[string]*topicRuntime
+	topicLogs      map[string]*topicLog
+	consumerGroups map[consumerGroupKey]*consumerGroup
+	store          *storage.Store
+	queryCache     QueryCache // nil disables result caching
+
+	hintedHandoffDir string // base dir new per-database queues are created under
+	hintedHandoff    map[string]*hintedhandoff.Queue
+
+	schemaChanges []SchemaChangeEvent // append-only audit log of evolveSchema's widenings
+
+	mu sync.RWMutex
 
 	// Default database for session
 	currentDB string
@@ -40,10 +64,17 @@ type Engine struct {
 // NewEngine creates a new TDengine engine
 func NewEngine() *Engine {
 	e := &Engine{
-		databases: make(map[string]*Database),
-		users:     make(map[string]*UserDefinition),
-		streams:   make(map[string]*StreamDefinition),
-		topics:    make(map[string]*TopicDefinition),
+		databases:        make(map[string]*Database),
+		users:            make(map[string]*UserDefinition),
+		streams:          make(map[string]*StreamDefinition),
+		streamRuntimes:   make(map[string]*streamRuntime),
+		topics:           make(map[string]*TopicDefinition),
+		topicRuntimes:    make(map[string]*topicRuntime),
+		topicLogs:        make(map[string]*topicLog),
+		consumerGroups:   make(map[consumerGroupKey]*consumerGroup),
+		store:            storage.NewStore(),
+		hintedHandoffDir: defaultHintedHandoffDir,
+		hintedHandoff:    make(map[string]*hintedhandoff.Queue),
 	}
 
 	// Create default user
@@ -59,57 +90,74 @@ func NewEngine() *Engine {
 
 // Execute executes a TDengine SQL statement
 func (e *Engine) Execute(db, sql string, opts *ExecuteOptions) (*Response, error) {
-	sql = strings.TrimSpace(sql)
-	upperSQL := strings.ToUpper(sql)
-
-	// Parse and route SQL
-	switch {
-	case strings.HasPrefix(upperSQL, "CREATE DATABASE"):
-		return e.createDatabase(sql)
-	case strings.HasPrefix(upperSQL, "DROP DATABASE"):
-		return e.dropDatabase(sql)
-	case strings.HasPrefix(upperSQL, "USE "):
-		return e.useDatabase(sql)
-	case strings.HasPrefix(upperSQL, "SHOW DATABASES"):
+	stmt, err := parser.Parse(sql)
+	if err != nil {
+		return nil, fmt.Errorf("tdengine: %w", err)
+	}
+
+	switch stmt := stmt.(type) {
+	case *parser.CreateDatabaseStmt:
+		return e.createDatabase(stmt)
+	case *parser.DropDatabaseStmt:
+		return e.dropDatabase(stmt)
+	case *parser.UseStmt:
+		return e.useDatabase(stmt)
+	case *parser.ShowDatabasesStmt:
 		return e.showDatabases()
-	case strings.HasPrefix(upperSQL, "CREATE STABLE") || strings.HasPrefix(upperSQL, "CREATE TABLE") && strings.Contains(upperSQL, "TAGS"):
-		return e.createSuperTable(db, sql)
-	case strings.HasPrefix(upperSQL, "CREATE TABLE"):
-		return e.createTable(db, sql)
-	case strings.HasPrefix(upperSQL, "DROP TABLE"):
-		return e.dropTable(db, sql)
-	case strings.HasPrefix(upperSQL, "SHOW TABLES"):
-		return e.showTables(db, sql)
-	case strings.HasPrefix(upperSQL, "SHOW STABLES"):
+	case *parser.CreateSTableStmt:
+		return e.createSuperTable(db, stmt)
+	case *parser.CreateTableStmt:
+		return e.createTable(db, stmt)
+	case *parser.CreateSubTableStmt:
+		return e.createSubTable(db, stmt)
+	case *parser.DropTableStmt:
+		return e.dropTable(db, stmt)
+	case *parser.ShowTablesStmt:
+		return e.showTables(db, stmt)
+	case *parser.ShowSTablesStmt:
 		return e.showSuperTables(db)
-	case strings.HasPrefix(upperSQL, "DESCRIBE"):
-		return e.describeTable(db, sql)
-	case strings.HasPrefix(upperSQL, "INSERT"):
-		return e.insert(db, sql)
-	case strings.HasPrefix(upperSQL, "SELECT"):
-		return e.query(db, sql)
-	case strings.HasPrefix(upperSQL, "CREATE STREAM"):
-		return e.createStream(db, sql)
-	case strings.HasPrefix(upperSQL, "DROP STREAM"):
-		return e.dropStream(sql)
-	case strings.HasPrefix(upperSQL, "SHOW STREAMS"):
+	case *parser.DescribeStmt:
+		return e.describeTable(db, stmt)
+	case *parser.InsertStmt:
+		return e.insert(db, stmt)
+	case *parser.SelectStmt:
+		return e.query(db, sql, stmt)
+	case *parser.CreateStreamStmt:
+		return e.createStream(db, stmt)
+	case *parser.DropStreamStmt:
+		return e.dropStream(stmt)
+	case *parser.ShowStreamsStmt:
 		return e.showStreams()
-	case strings.HasPrefix(upperSQL, "CREATE TOPIC"):
-		return e.createTopic(db, sql)
-	case strings.HasPrefix(upperSQL, "DROP TOPIC"):
-		return e.dropTopic(sql)
-	case strings.HasPrefix(upperSQL, "SHOW TOPICS"):
+	case *parser.CreateTopicStmt:
+		return e.createTopic(db, stmt)
+	case *parser.DropTopicStmt:
+		return e.dropTopic(stmt)
+	case *parser.ShowTopicsStmt:
 		return e.showTopics()
-	case strings.HasPrefix(upperSQL, "ALTER"):
-		return e.alterTable(db, sql)
-	case strings.HasPrefix(upperSQL, "SHOW VGROUPS"):
+	case *parser.AlterTableStmt:
+		return e.alterTable(db, stmt)
+	case *parser.ShowVgroupsStmt:
 		return e.showVgroups(db)
-	case strings.HasPrefix(upperSQL, "SHOW DNODES"):
+	case *parser.ShowDnodesStmt:
 		return e.showDnodes()
-	case strings.HasPrefix(upperSQL, "SHOW MNODES"):
+	case *parser.ShowMnodesStmt:
 		return e.showMnodes()
-	case strings.HasPrefix(upperSQL, "SHOW USERS"):
+	case *parser.ShowUsersStmt:
 		return e.showUsers()
+	case *parser.ShowConsumersStmt:
+		return e.showConsumers()
+	case *parser.ShowSubscriptionsStmt:
+		return e.showSubscriptions()
+	case *parser.ShowQueryCacheStmt:
+		return e.showQueryCache()
+	case *parser.ShowHintedHandoffStmt:
+		return e.showHintedHandoff()
+	case *parser.ShowSchemaChangesStmt:
+		return e.showSchemaChanges()
+	case *parser.ShowCreateTableStmt:
+		return e.showCreateTable(db, stmt)
+	case *parser.ShowCreateSTableStmt:
+		return e.showCreateSTable(db, stmt)
 	default:
 		return nil, fmt.Errorf("unsupported SQL: %s", sql)
 	}
@@ -128,6 +176,32 @@ func (e *Engine) Authenticate(username, password string) bool {
 	return user.Password == password
 }
 
+// SetQueryCache installs cache as the engine's SELECT result cache.
+// Passing nil (the default) disables caching.
+func (e *Engine) SetQueryCache(cache QueryCache) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.queryCache = cache
+}
+
+// GetQueryCache returns the engine's current QueryCache, or nil if
+// caching is disabled.
+func (e *Engine) GetQueryCache() QueryCache {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.queryCache
+}
+
+// SetHintedHandoffDir overrides the base directory new per-database
+// hinted-handoff queues are created under. It only affects databases
+// created afterward; call it before any CREATE DATABASE ... WITH HINTED
+// HANDOFF.
+func (e *Engine) SetHintedHandoffDir(dir string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.hintedHandoffDir = dir
+}
+
 // ValidateToken validates a session token
 func (e *Engine) ValidateToken(token string) bool {
 	// For now, accept any non-empty token
@@ -135,8 +209,43 @@ func (e *Engine) ValidateToken(token string) bool {
 	return token != ""
 }
 
-// WriteInfluxDB writes data using InfluxDB line protocol
+// WriteInfluxDB writes data using InfluxDB line protocol. If db has
+// hinted handoff enabled (CREATE DATABASE ... WITH HINTED HANDOFF) and
+// the direct write fails, the line is durably queued instead of losing
+// the write, and a background drainer retries it with backoff.
 func (e *Engine) WriteInfluxDB(db string, line *InfluxDBLineProtocol) error {
+	e.mu.RLock()
+	q := e.hintedHandoff[db]
+	e.mu.RUnlock()
+
+	if q == nil {
+		return e.writeInfluxDBDirect(db, line)
+	}
+
+	if err := e.writeInfluxDBDirect(db, line); err != nil {
+		payload, encErr := json.Marshal(line)
+		if encErr != nil {
+			return fmt.Errorf("write influxdb: hinted handoff: encode payload: %w", encErr)
+		}
+		return q.Enqueue(payload)
+	}
+	return nil
+}
+
+// replayInfluxDBPayload decodes a hinted-handoff payload produced by
+// WriteInfluxDB and re-applies it; it's the Queue's WriteFunc for db.
+func (e *Engine) replayInfluxDBPayload(db string, payload []byte) error {
+	var line InfluxDBLineProtocol
+	if err := json.Unmarshal(payload, &line); err != nil {
+		return fmt.Errorf("hinted handoff: decode payload: %w", err)
+	}
+	return e.writeInfluxDBDirect(db, &line)
+}
+
+// writeInfluxDBDirect applies line straight to the storage layer,
+// bypassing hinted handoff; WriteInfluxDB and the hinted-handoff
+// drainer both funnel through here.
+func (e *Engine) writeInfluxDBDirect(db string, line *InfluxDBLineProtocol) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -148,14 +257,17 @@ func (e *Engine) WriteInfluxDB(db string, line *InfluxDBLineProtocol) error {
 	// Auto-create supertable if needed
 	stableName := line.Measurement
 	if _, ok := database.STables[stableName]; !ok {
-		// Create supertable from first data point
+		// Create supertable from first data point, inferring each
+		// field's column type from its actual Go value (as produced by
+		// ParseInfluxDBLine's type-suffix handling) instead of blindly
+		// assuming DOUBLE.
 		schema := []Column{
 			{Name: "ts", Type: TSDB_DATA_TYPE_TIMESTAMP},
 		}
-		for fieldName := range line.Fields {
+		for fieldName, v := range line.Fields {
 			schema = append(schema, Column{
 				Name: fieldName,
-				Type: TSDB_DATA_TYPE_DOUBLE,
+				Type: inferColumnType(v),
 			})
 		}
 
@@ -175,6 +287,8 @@ func (e *Engine) WriteInfluxDB(db string, line *InfluxDBLineProtocol) error {
 			SubTables: make(map[string]*Table),
 			CreatedAt: time.Now(),
 		}
+	} else if err := e.evolveSchema(db, database.STables[stableName], line.Fields); err != nil {
+		return err
 	}
 
 	// Generate subtable name from tags
@@ -196,13 +310,196 @@ func (e *Engine) WriteInfluxDB(db string, line *InfluxDBLineProtocol) error {
 			CreatedAt:  time.Now(),
 		}
 	}
+	e.store.CreateTable(db, tableName, storageSchema(stable.Schema))
 
-	// TODO: Actually store the data point
-	// For now, just validate that we can create the structures
+	values := make([]interface{}, 0, len(stable.Schema))
+	nonTSIdx := make(map[string]int, len(stable.Schema))
+	nonTSSchema := make([]Column, 0, len(stable.Schema))
+	for _, col := range stable.Schema {
+		if col.Type == TSDB_DATA_TYPE_TIMESTAMP {
+			continue
+		}
+		v, ok := line.Fields[col.Name]
+		if !ok {
+			values = append(values, zeroValueForType(col.Type))
+			nonTSIdx[col.Name] = len(nonTSIdx)
+			nonTSSchema = append(nonTSSchema, col)
+			continue
+		}
+		coerced, err := coerceInfluxValue(v, col.Type)
+		if err != nil {
+			return fmt.Errorf("write influxdb: field %q: %w", col.Name, err)
+		}
+		values = append(values, coerced)
+		nonTSIdx[col.Name] = len(nonTSIdx)
+		nonTSSchema = append(nonTSSchema, col)
+	}
 
+	row := storage.Row{Ts: line.Timestamp, Values: values}
+	if err := e.store.Insert(db, tableName, row); err != nil {
+		return err
+	}
+	e.bumpTableVersionLocked(db, tableName)
+	e.fireStreams(db, tableName, []storage.Row{row}, nonTSIdx)
+	e.publishToTopics(db, tableName, []storage.Row{row}, nonTSSchema)
 	return nil
 }
 
+// inferColumnType picks the narrowest TSDB_DATA_TYPE_* that can hold v, as
+// produced by ParseInfluxDBLine's field-value type-suffix handling
+// (int64 for "i", uint64 for "u", bool for "t"/"f"/"true"/"false", string
+// for quoted text, float64 for a bare numeric literal).
+func inferColumnType(v interface{}) int {
+	switch v.(type) {
+	case int64:
+		return TSDB_DATA_TYPE_BIGINT
+	case uint64:
+		return TSDB_DATA_TYPE_UBIGINT
+	case bool:
+		return TSDB_DATA_TYPE_BOOL
+	case string:
+		return TSDB_DATA_TYPE_NCHAR
+	default:
+		return TSDB_DATA_TYPE_DOUBLE
+	}
+}
+
+// numericRank orders the numeric TSDB_DATA_TYPE_* values from narrowest to
+// widest so evolveSchema can decide whether widening a column is safe;
+// ok is false for non-numeric types (BOOL, NCHAR, ...), which never widen.
+func numericRank(t int) (int, bool) {
+	switch t {
+	case TSDB_DATA_TYPE_TINYINT:
+		return 0, true
+	case TSDB_DATA_TYPE_SMALLINT:
+		return 1, true
+	case TSDB_DATA_TYPE_INT:
+		return 2, true
+	case TSDB_DATA_TYPE_BIGINT:
+		return 3, true
+	case TSDB_DATA_TYPE_UBIGINT:
+		return 4, true
+	case TSDB_DATA_TYPE_FLOAT:
+		return 5, true
+	case TSDB_DATA_TYPE_DOUBLE:
+		return 6, true
+	default:
+		return 0, false
+	}
+}
+
+// evolveSchema reconciles stable's schema against an incoming line
+// protocol point's fields: new fields become new columns, and a field
+// whose inferred type is numerically wider than its existing column
+// widens that column in place. Incompatible changes (e.g. a field that
+// used to be numeric now arriving as a string, or vice versa) are
+// rejected rather than silently coercing data. Every successful widening
+// or addition is appended to e.schemaChanges for SHOW SCHEMA CHANGES.
+// Callers must already hold e.mu.
+func (e *Engine) evolveSchema(db string, stable *SuperTable, fields map[string]interface{}) error {
+	byName := make(map[string]int, len(stable.Schema))
+	for i, col := range stable.Schema {
+		byName[col.Name] = i
+	}
+
+	for fieldName, v := range fields {
+		wantType := inferColumnType(v)
+		idx, exists := byName[fieldName]
+		if !exists {
+			stable.Schema = append(stable.Schema, Column{Name: fieldName, Type: wantType})
+			e.schemaChanges = append(e.schemaChanges, SchemaChangeEvent{
+				Database: db,
+				STable:   stable.Name,
+				Field:    fieldName,
+				NewType:  typeToString(wantType),
+				At:       time.Now(),
+			})
+			byName[fieldName] = len(stable.Schema) - 1
+			continue
+		}
+
+		haveType := stable.Schema[idx].Type
+		if haveType == wantType {
+			continue
+		}
+
+		haveRank, haveNumeric := numericRank(haveType)
+		wantRank, wantNumeric := numericRank(wantType)
+		if !haveNumeric || !wantNumeric {
+			return fmt.Errorf("schema evolution: field %q: incompatible type change from %s to %s",
+				fieldName, typeToString(haveType), typeToString(wantType))
+		}
+		if wantRank <= haveRank {
+			// Narrower (or equal-rank, different-representation) value
+			// for an already-wider column; the existing column wins.
+			continue
+		}
+
+		stable.Schema[idx].Type = wantType
+		e.schemaChanges = append(e.schemaChanges, SchemaChangeEvent{
+			Database: db,
+			STable:   stable.Name,
+			Field:    fieldName,
+			OldType:  typeToString(haveType),
+			NewType:  typeToString(wantType),
+			At:       time.Now(),
+		})
+
+		// Table.Schema was copied from stable.Schema at subtable-creation
+		// time (a slice header, not a shared backing array once
+		// append grows it), so every existing subtable needs the
+		// evolved schema reassigned explicitly.
+		for _, t := range stable.SubTables {
+			t.Schema = stable.Schema
+		}
+	}
+	return nil
+}
+
+// coerceInfluxValue converts v (as produced by ParseInfluxDBLine) into the
+// Go value storage.Row expects for colType, widening or narrowing numeric
+// representations as needed. It returns an error for combinations
+// evolveSchema should have already prevented from reaching storage, such
+// as a bool value for a string column.
+func coerceInfluxValue(v interface{}, colType int) (interface{}, error) {
+	switch colType {
+	case TSDB_DATA_TYPE_BOOL:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected bool, got %T", v)
+		}
+		return b, nil
+	case TSDB_DATA_TYPE_BINARY, TSDB_DATA_TYPE_NCHAR, TSDB_DATA_TYPE_JSON:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", v)
+		}
+		return s, nil
+	case TSDB_DATA_TYPE_FLOAT, TSDB_DATA_TYPE_DOUBLE:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case int64:
+			return float64(n), nil
+		case uint64:
+			return float64(n), nil
+		default:
+			return nil, fmt.Errorf("expected numeric value, got %T", v)
+		}
+	case TSDB_DATA_TYPE_TINYINT, TSDB_DATA_TYPE_SMALLINT, TSDB_DATA_TYPE_INT, TSDB_DATA_TYPE_BIGINT, TSDB_DATA_TYPE_UBIGINT:
+		switch n := v.(type) {
+		case int64:
+			return n, nil
+		case uint64:
+			return int64(n), nil
+		default:
+			return nil, fmt.Errorf("expected integer value, got %T", v)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported column type %d", colType)
+	}
+}
+
 // WriteOpenTSDBJSON writes data using OpenTSDB JSON format
 func (e *Engine) WriteOpenTSDBJSON(db string, point *OpenTSDBPoint) error {
 	line := &InfluxDBLineProtocol{
@@ -244,86 +541,88 @@ func (e *Engine) WriteOpenTSDBTelnet(db, line string) error {
 	return e.WriteOpenTSDBJSON(db, point)
 }
 
+// WriteInfluxDBLine parses line as a single InfluxDB line protocol record
+// (e.g. "cpu,host=a value=1i 1630000000000000000") and writes it. Fields
+// with no explicit timestamp use defaultTimestamp (typically time.Now(),
+// in the same unit the caller's storage expects).
+func (e *Engine) WriteInfluxDBLine(db, line string, defaultTimestamp int64) error {
+	parsed, err := ParseInfluxDBLine(line, defaultTimestamp)
+	if err != nil {
+		return fmt.Errorf("write influxdb line: %w", err)
+	}
+	return e.WriteInfluxDB(db, parsed)
+}
+
 // Database operations
-func (e *Engine) createDatabase(sql string) (*Response, error) {
+func (e *Engine) createDatabase(stmt *parser.CreateDatabaseStmt) (*Response, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	// Parse: CREATE DATABASE [IF NOT EXISTS] <name> [options...]
-	matches := reCreateDB.FindStringSubmatch(sql)
-	if len(matches) < 3 {
-		return nil, fmt.Errorf("invalid CREATE DATABASE syntax")
-	}
-
-	ifNotExists := matches[1] != ""
-	name := matches[2]
-
-	if _, exists := e.databases[name]; exists {
-		if ifNotExists {
+	if _, exists := e.databases[stmt.Name]; exists {
+		if stmt.IfNotExists {
 			return &Response{Code: TSDB_CODE_SUCCESS}, nil
 		}
-		return nil, fmt.Errorf("database already exists: %s", name)
+		return nil, fmt.Errorf("database already exists: %s", stmt.Name)
 	}
 
-	// Parse options
-	precision := "ms"
-	if strings.Contains(strings.ToUpper(sql), "PRECISION") {
-		if m := rePrecision.FindStringSubmatch(sql); len(m) > 1 {
-			precision = m[1]
-		}
+	precision := stmt.Precision
+	if precision == "" {
+		precision = "ms"
 	}
 
-	e.databases[name] = &Database{
-		Name:      name,
+	e.databases[stmt.Name] = &Database{
+		Name:      stmt.Name,
 		Precision: precision,
 		STables:   make(map[string]*SuperTable),
 		Tables:    make(map[string]*Table),
 		CreatedAt: time.Now(),
 	}
 
+	if stmt.HintedHandoff {
+		dbName := stmt.Name
+		q, err := hintedhandoff.NewQueue(
+			filepath.Join(e.hintedHandoffDir, dbName),
+			hintedhandoff.Config{},
+			func(payload []byte) error { return e.replayInfluxDBPayload(dbName, payload) },
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create database: hinted handoff: %w", err)
+		}
+		e.hintedHandoff[dbName] = q
+	}
+
 	return &Response{Code: TSDB_CODE_SUCCESS}, nil
 }
 
-func (e *Engine) dropDatabase(sql string) (*Response, error) {
+func (e *Engine) dropDatabase(stmt *parser.DropDatabaseStmt) (*Response, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	matches := reDropDB.FindStringSubmatch(sql)
-	if len(matches) < 3 {
-		return nil, fmt.Errorf("invalid DROP DATABASE syntax")
-	}
-
-	ifExists := matches[1] != ""
-	name := matches[2]
-
-	if _, exists := e.databases[name]; !exists {
-		if ifExists {
+	if _, exists := e.databases[stmt.Name]; !exists {
+		if stmt.IfExists {
 			return &Response{Code: TSDB_CODE_SUCCESS}, nil
 		}
-		return nil, fmt.Errorf("database not found: %s", name)
+		return nil, fmt.Errorf("database not found: %s", stmt.Name)
 	}
 
-	delete(e.databases, name)
+	delete(e.databases, stmt.Name)
+	if q, ok := e.hintedHandoff[stmt.Name]; ok {
+		q.Close()
+		delete(e.hintedHandoff, stmt.Name)
+	}
 	return &Response{Code: TSDB_CODE_SUCCESS}, nil
 }
 
-func (e *Engine) useDatabase(sql string) (*Response, error) {
-	matches := reUseDB.FindStringSubmatch(sql)
-	if len(matches) < 2 {
-		return nil, fmt.Errorf("invalid USE syntax")
-	}
-
-	name := matches[1]
-
+func (e *Engine) useDatabase(stmt *parser.UseStmt) (*Response, error) {
 	e.mu.RLock()
-	_, exists := e.databases[name]
+	_, exists := e.databases[stmt.Name]
 	e.mu.RUnlock()
 
 	if !exists {
-		return nil, fmt.Errorf("database not found: %s", name)
+		return nil, fmt.Errorf("database not found: %s", stmt.Name)
 	}
 
-	e.currentDB = name
+	e.currentDB = stmt.Name
 	return &Response{Code: TSDB_CODE_SUCCESS}, nil
 }
 
@@ -354,49 +653,29 @@ func (e *Engine) showDatabases() (*Response, error) {
 	}, nil
 }
 
-func (e *Engine) createSuperTable(db, sql string) (*Response, error) {
+func (e *Engine) createSuperTable(db string, stmt *parser.CreateSTableStmt) (*Response, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if stmt.Database != "" {
+		db = stmt.Database
+	}
 	database := e.getDatabase(db)
 	if database == nil {
 		return nil, fmt.Errorf("database not found: %s", db)
 	}
 
-	// Parse CREATE STABLE syntax
-	// CREATE STABLE [IF NOT EXISTS] [db.]name (columns) TAGS (tags)
-	// Create SuperTable - reCreateStable
-	matches := reCreateStable.FindStringSubmatch(sql)
-	if len(matches) < 6 {
-		return nil, fmt.Errorf("invalid CREATE STABLE syntax")
-	}
-
-	ifNotExists := matches[1] != ""
-	if matches[2] != "" {
-		db = matches[2]
-		database = e.databases[db]
-		if database == nil {
-			return nil, fmt.Errorf("database not found: %s", db)
-		}
-	}
-	name := matches[3]
-	columnsStr := matches[4]
-	tagsStr := matches[5]
-
-	if _, exists := database.STables[name]; exists {
-		if ifNotExists {
+	if _, exists := database.STables[stmt.Name]; exists {
+		if stmt.IfNotExists {
 			return &Response{Code: TSDB_CODE_SUCCESS}, nil
 		}
-		return nil, fmt.Errorf("supertable already exists: %s", name)
+		return nil, fmt.Errorf("supertable already exists: %s", stmt.Name)
 	}
 
-	schema := parseColumns(columnsStr, false)
-	tags := parseColumns(tagsStr, true)
-
-	database.STables[name] = &SuperTable{
-		Name:      name,
-		Schema:    schema,
-		Tags:      tags,
+	database.STables[stmt.Name] = &SuperTable{
+		Name:      stmt.Name,
+		Schema:    columnsToSchema(stmt.Columns, false),
+		Tags:      columnsToSchema(stmt.Tags, true),
 		SubTables: make(map[string]*Table),
 		CreatedAt: time.Now(),
 	}
@@ -404,149 +683,124 @@ func (e *Engine) createSuperTable(db, sql string) (*Response, error) {
 	return &Response{Code: TSDB_CODE_SUCCESS}, nil
 }
 
-func (e *Engine) createTable(db, sql string) (*Response, error) {
+func (e *Engine) createTable(db string, stmt *parser.CreateTableStmt) (*Response, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if stmt.Database != "" {
+		db = stmt.Database
+	}
 	database := e.getDatabase(db)
 	if database == nil {
 		return nil, fmt.Errorf("database not found: %s", db)
 	}
 
-	upperSQL := strings.ToUpper(sql)
-
-	// Check if it's a subtable creation: CREATE TABLE name USING stable TAGS (values)
-	if strings.Contains(upperSQL, "USING") {
-		matches := reCreateSubTable.FindStringSubmatch(sql)
-		if len(matches) < 7 {
-			return nil, fmt.Errorf("invalid CREATE TABLE ... USING syntax")
-		}
-
-		ifNotExists := matches[1] != ""
-		if matches[2] != "" {
-			db = matches[2]
-			database = e.databases[db]
-		}
-		tableName := matches[3]
-		stableDB := matches[4]
-		if stableDB == "" {
-			stableDB = db
-		}
-		stableName := matches[5]
-		tagValuesStr := matches[6]
-
-		stableDatabase := e.databases[stableDB]
-		if stableDatabase == nil {
-			return nil, fmt.Errorf("database not found: %s", stableDB)
-		}
-
-		stable, ok := stableDatabase.STables[stableName]
-		if !ok {
-			return nil, fmt.Errorf("supertable not found: %s", stableName)
+	if _, exists := database.Tables[stmt.Name]; exists {
+		if stmt.IfNotExists {
+			return &Response{Code: TSDB_CODE_SUCCESS}, nil
 		}
+		return nil, fmt.Errorf("table already exists: %s", stmt.Name)
+	}
 
-		if _, exists := stable.SubTables[tableName]; exists {
-			if ifNotExists {
-				return &Response{Code: TSDB_CODE_SUCCESS}, nil
-			}
-			return nil, fmt.Errorf("table already exists: %s", tableName)
-		}
+	schema := columnsToSchema(stmt.Columns, false)
+	database.Tables[stmt.Name] = &Table{
+		Name:      stmt.Name,
+		Schema:    schema,
+		CreatedAt: time.Now(),
+	}
+	e.store.CreateTable(db, stmt.Name, storageSchema(schema))
 
-		tagValues := parseTagValues(tagValuesStr, stable.Tags)
+	return &Response{Code: TSDB_CODE_SUCCESS}, nil
+}
 
-		stable.SubTables[tableName] = &Table{
-			Name:       tableName,
-			Schema:     stable.Schema,
-			Tags:       tagValues,
-			SuperTable: stableName,
-			CreatedAt:  time.Now(),
-		}
+func (e *Engine) createSubTable(db string, stmt *parser.CreateSubTableStmt) (*Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-		return &Response{Code: TSDB_CODE_SUCCESS}, nil
+	if stmt.Database != "" {
+		db = stmt.Database
 	}
 
-	// Regular table (not subtable)
-	matches := reCreateTable.FindStringSubmatch(sql)
-	if len(matches) < 5 {
-		return nil, fmt.Errorf("invalid CREATE TABLE syntax")
+	stableDB := stmt.STableDatabase
+	if stableDB == "" {
+		stableDB = db
+	}
+	stableDatabase := e.databases[stableDB]
+	if stableDatabase == nil {
+		return nil, fmt.Errorf("database not found: %s", stableDB)
 	}
 
-	ifNotExists := matches[1] != ""
-	if matches[2] != "" {
-		db = matches[2]
-		database = e.databases[db]
+	stable, ok := stableDatabase.STables[stmt.STableName]
+	if !ok {
+		return nil, fmt.Errorf("supertable not found: %s", stmt.STableName)
 	}
-	name := matches[3]
-	columnsStr := matches[4]
 
-	if _, exists := database.Tables[name]; exists {
-		if ifNotExists {
+	if _, exists := stable.SubTables[stmt.Name]; exists {
+		if stmt.IfNotExists {
 			return &Response{Code: TSDB_CODE_SUCCESS}, nil
 		}
-		return nil, fmt.Errorf("table already exists: %s", name)
+		return nil, fmt.Errorf("table already exists: %s", stmt.Name)
 	}
 
-	schema := parseColumns(columnsStr, false)
-
-	database.Tables[name] = &Table{
-		Name:      name,
-		Schema:    schema,
-		CreatedAt: time.Now(),
+	tags, err := tagValuesToMap(stmt.TagValues, stable.Tags)
+	if err != nil {
+		return nil, err
+	}
+	stable.SubTables[stmt.Name] = &Table{
+		Name:       stmt.Name,
+		Schema:     stable.Schema,
+		Tags:       tags,
+		SuperTable: stmt.STableName,
+		CreatedAt:  time.Now(),
 	}
+	e.store.CreateTable(db, stmt.Name, storageSchema(stable.Schema))
 
 	return &Response{Code: TSDB_CODE_SUCCESS}, nil
 }
 
-func (e *Engine) dropTable(db, sql string) (*Response, error) {
+func (e *Engine) dropTable(db string, stmt *parser.DropTableStmt) (*Response, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if stmt.Database != "" {
+		db = stmt.Database
+	}
 	database := e.getDatabase(db)
 	if database == nil {
 		return nil, fmt.Errorf("database not found: %s", db)
 	}
 
-	matches := reDropTable.FindStringSubmatch(sql)
-	if len(matches) < 4 {
-		return nil, fmt.Errorf("invalid DROP TABLE syntax")
-	}
-
-	ifExists := matches[1] != ""
-	if matches[2] != "" {
-		db = matches[2]
-		database = e.databases[db]
-	}
-	name := matches[3]
-
 	// Check regular tables
-	if _, exists := database.Tables[name]; exists {
-		delete(database.Tables, name)
+	if _, exists := database.Tables[stmt.Name]; exists {
+		delete(database.Tables, stmt.Name)
+		e.store.DropTable(db, stmt.Name)
+		e.bumpTableVersionLocked(db, stmt.Name)
 		return &Response{Code: TSDB_CODE_SUCCESS}, nil
 	}
 
 	// Check subtables in all supertables
 	for _, stable := range database.STables {
-		if _, exists := stable.SubTables[name]; exists {
-			delete(stable.SubTables, name)
+		if _, exists := stable.SubTables[stmt.Name]; exists {
+			delete(stable.SubTables, stmt.Name)
+			e.store.DropTable(db, stmt.Name)
+			e.bumpTableVersionLocked(db, stmt.Name)
 			return &Response{Code: TSDB_CODE_SUCCESS}, nil
 		}
 	}
 
-	if ifExists {
+	if stmt.IfExists {
 		return &Response{Code: TSDB_CODE_SUCCESS}, nil
 	}
 
-	return nil, fmt.Errorf("table not found: %s", name)
+	return nil, fmt.Errorf("table not found: %s", stmt.Name)
 }
 
-func (e *Engine) showTables(db, sql string) (*Response, error) {
+func (e *Engine) showTables(db string, stmt *parser.ShowTablesStmt) (*Response, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	// Parse optional FROM clause
-	matches := reShowTables.FindStringSubmatch(sql)
-	if len(matches) > 2 && matches[2] != "" {
-		db = matches[2]
+	if stmt.Database != "" {
+		db = stmt.Database
 	}
 
 	database := e.getDatabase(db)
@@ -625,19 +879,14 @@ func (e *Engine) showSuperTables(db string) (*Response, error) {
 	}, nil
 }
 
-func (e *Engine) describeTable(db, sql string) (*Response, error) {
+func (e *Engine) describeTable(db string, stmt *parser.DescribeStmt) (*Response, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	matches := reDescribe.FindStringSubmatch(sql)
-	if len(matches) < 3 {
-		return nil, fmt.Errorf("invalid DESCRIBE syntax")
+	if stmt.Database != "" {
+		db = stmt.Database
 	}
-
-	if matches[1] != "" {
-		db = matches[1]
-	}
-	name := matches[2]
+	name := stmt.Name
 
 	database := e.getDatabase(db)
 	if database == nil {
@@ -698,92 +947,586 @@ func (e *Engine) describeColumns(schema, tags []Column) *Response {
 	}
 }
 
-func (e *Engine) insert(db, sql string) (*Response, error) {
-	// For now, just parse and count affected rows
-	// TODO: Actual data storage integration
+func (e *Engine) insert(db string, stmt *parser.InsertStmt) (*Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	affected := 0
+	for _, target := range stmt.Targets {
+		targetDB := db
+		if target.Database != "" {
+			targetDB = target.Database
+		}
 
-	// Count VALUES clauses to determine affected rows
-	valuesCount := strings.Count(strings.ToUpper(sql), "(") - 1 // Subtract table definition
-	if valuesCount < 0 {
-		valuesCount = 0
-	}
-	if valuesCount == 0 {
-		valuesCount = 1
+		schema, err := e.resolveTableSchema(targetDB, target.Table)
+		if err != nil {
+			return nil, err
+		}
+
+		columns := target.Columns
+		if len(columns) == 0 {
+			columns = make([]string, len(schema))
+			for i, col := range schema {
+				columns[i] = col.Name
+			}
+		}
+
+		colByName := make(map[string]Column, len(schema))
+		for _, col := range schema {
+			colByName[col.Name] = col
+		}
+
+		nonTSIdx := make(map[string]int, len(schema))
+		nonTSSchema := make([]Column, 0, len(schema))
+		for _, col := range schema {
+			if col.Type != TSDB_DATA_TYPE_TIMESTAMP {
+				nonTSIdx[col.Name] = len(nonTSIdx)
+				nonTSSchema = append(nonTSSchema, col)
+			}
+		}
+
+		for _, row := range target.Rows {
+			if len(row.Values) != len(columns) {
+				return nil, fmt.Errorf("insert into %s: %d values for %d columns", target.Table, len(row.Values), len(columns))
+			}
+
+			var ts int64
+			valueByCol := make(map[string]interface{}, len(columns))
+			for i, name := range columns {
+				col, ok := colByName[name]
+				if !ok {
+					return nil, fmt.Errorf("insert into %s: unknown column %q", target.Table, name)
+				}
+				v, err := convertLiteral(row.Values[i], col)
+				if err != nil {
+					return nil, fmt.Errorf("insert into %s: column %q: %w", target.Table, name, err)
+				}
+				if col.Type == TSDB_DATA_TYPE_TIMESTAMP {
+					ts = v.(int64)
+					continue
+				}
+				valueByCol[name] = v
+			}
+
+			values := make([]interface{}, 0, len(schema))
+			for _, col := range schema {
+				if col.Type == TSDB_DATA_TYPE_TIMESTAMP {
+					continue
+				}
+				v, ok := valueByCol[col.Name]
+				if !ok {
+					v = zeroValueForType(col.Type)
+				}
+				values = append(values, v)
+			}
+
+			storedRow := storage.Row{Ts: ts, Values: values}
+			if err := e.store.Insert(targetDB, target.Table, storedRow); err != nil {
+				return nil, err
+			}
+			e.bumpTableVersionLocked(targetDB, target.Table)
+			e.fireStreams(targetDB, target.Table, []storage.Row{storedRow}, nonTSIdx)
+			e.publishToTopics(targetDB, target.Table, []storage.Row{storedRow}, nonTSSchema)
+			affected++
+		}
 	}
 
 	return &Response{
 		Code:         TSDB_CODE_SUCCESS,
-		AffectedRows: valuesCount,
+		AffectedRows: affected,
 	}, nil
 }
 
-func (e *Engine) query(db, sql string) (*Response, error) {
-	// Simplified query implementation
-	// TODO: Full SQL parsing and execution
+func (e *Engine) query(db, sql string, stmt *parser.SelectStmt) (*Response, error) {
+	e.mu.RLock()
+	schema, err := e.resolveTableSchema(db, stmt.From)
+	cache := e.queryCache
+	e.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
 
-	// For now, return empty result set
-	return &Response{
-		Code: TSDB_CODE_SUCCESS,
-		ColumnMeta: [][]interface{}{
-			{"ts", TSDB_DATA_TYPE_TIMESTAMP, 8},
-			{"value", TSDB_DATA_TYPE_DOUBLE, 8},
-		},
-		Data: [][]interface{}{},
-		Rows: 0,
-	}, nil
+	var cacheKey string
+	if cache != nil {
+		cacheKey = queryCacheKey(db, sql)
+		if resp, ok := cache.Get(cacheKey); ok {
+			return resp, nil
+		}
+	}
+
+	loTs, hiTs := int64(math.MinInt64), int64(math.MaxInt64)
+	for _, cond := range stmt.Where {
+		if cond.Left != "ts" {
+			continue
+		}
+		v, err := strconv.ParseInt(cond.Right, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("query: invalid ts literal %q: %w", cond.Right, err)
+		}
+		switch cond.Operator {
+		case ">=":
+			loTs = v
+		case ">":
+			loTs = v + 1
+		case "<=":
+			hiTs = v
+		case "<":
+			hiTs = v - 1
+		case "=":
+			loTs, hiTs = v, v
+		}
+	}
+
+	rows, err := e.store.Query(db, stmt.From, loTs, hiTs)
+	if err != nil {
+		return nil, err
+	}
+
+	nonTS := make([]Column, 0, len(schema))
+	for _, col := range schema {
+		if col.Type != TSDB_DATA_TYPE_TIMESTAMP {
+			nonTS = append(nonTS, col)
+		}
+	}
+	colIndex := make(map[string]int, len(nonTS))
+	for i, col := range nonTS {
+		colIndex[col.Name] = i
+	}
+
+	// ts is pushed down to the store above; a WHERE condition against a
+	// DECIMAL column is the one other case evaluated here, since it needs
+	// exact (not float64) comparison - see filterDecimalRows.
+	for _, cond := range stmt.Where {
+		if cond.Left == "ts" {
+			continue
+		}
+		idx, ok := colIndex[cond.Left]
+		if !ok || nonTS[idx].Type != TSDB_DATA_TYPE_DECIMAL {
+			continue
+		}
+		rows, err = filterDecimalRows(rows, idx, cond, nonTS[idx].Scale)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fields := stmt.Fields
+	if len(fields) == 0 {
+		fields = make([]parser.SelectField, 0, len(nonTS)+1)
+		fields = append(fields, parser.SelectField{Name: "ts"})
+		for _, col := range nonTS {
+			fields = append(fields, parser.SelectField{Name: col.Name})
+		}
+	}
+
+	colMeta := make([][]interface{}, 0, len(fields))
+	for _, f := range fields {
+		name := f.Alias
+		if name == "" {
+			if f.Func != "" {
+				name = f.Func + "(" + f.Name + ")"
+			} else {
+				name = f.Name
+			}
+		}
+		dtype := TSDB_DATA_TYPE_DOUBLE
+		length := 8
+		precision, scale := 0, 0
+		srcIdx, hasSrc := colIndex[f.Name]
+		switch {
+		case f.Func != "" && hasSrc && nonTS[srcIdx].Type == TSDB_DATA_TYPE_DECIMAL:
+			// SUM/AVG/MIN/MAX over a DECIMAL column go through
+			// aggregateDecimalField and come back as canonical decimal
+			// strings, not float64 - ColumnMeta needs to say DECIMAL (with
+			// the source column's precision/scale) or callers reading
+			// RowsColumnTypePrecisionScale/ColumnTypeScanType would treat
+			// an exact aggregate as a lossy float.
+			dtype = TSDB_DATA_TYPE_DECIMAL
+			precision, scale = nonTS[srcIdx].Precision, nonTS[srcIdx].Scale
+		case f.Func != "":
+			dtype = TSDB_DATA_TYPE_DOUBLE
+		case f.Name == "ts":
+			dtype = TSDB_DATA_TYPE_TIMESTAMP
+		case hasSrc:
+			dtype = nonTS[srcIdx].Type
+			if nonTS[srcIdx].Length > 0 {
+				length = nonTS[srcIdx].Length
+			}
+			precision, scale = nonTS[srcIdx].Precision, nonTS[srcIdx].Scale
+		}
+		// Every ColumnMeta row carries precision/scale alongside length,
+		// even though only DECIMAL columns populate them with anything
+		// nonzero, so driver.RowsColumnTypePrecisionScale has a fixed
+		// position to read regardless of the column's type.
+		colMeta = append(colMeta, []interface{}{name, dtype, length, precision, scale})
+	}
+
+	hasAgg := false
+	for _, f := range fields {
+		if f.Func != "" {
+			hasAgg = true
+			break
+		}
+	}
+
+	var data [][]interface{}
+	if !hasAgg {
+		data = make([][]interface{}, 0, len(rows))
+		for _, r := range rows {
+			out := make([]interface{}, 0, len(fields))
+			for _, f := range fields {
+				if f.Name == "ts" {
+					out = append(out, r.Ts)
+					continue
+				}
+				idx, ok := colIndex[f.Name]
+				if !ok {
+					return nil, fmt.Errorf("query: unknown column %q", f.Name)
+				}
+				out = append(out, r.Values[idx])
+			}
+			data = append(data, out)
+		}
+	} else {
+		var intervalMs int64
+		if stmt.Window != nil {
+			intervalMs, err = parseIntervalMs(stmt.Window.Interval)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// GROUP BY tag is not applied here: a query's FROM names a single
+		// subtable, so there are no sibling tags to group across yet.
+		data, err = aggregateRows(rows, fields, colIndex, nonTS, intervalMs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &Response{
+		Code:       TSDB_CODE_SUCCESS,
+		ColumnMeta: colMeta,
+		Data:       data,
+		Rows:       len(data),
+	}
+
+	if cache != nil {
+		// Version is filled in by the cache itself (it owns the
+		// per-table version counters); only DB/Table identify the
+		// dependency here.
+		cache.Put(cacheKey, resp, []TableVersion{{DB: db, Table: stmt.From}})
+	}
+
+	return resp, nil
 }
 
-func (e *Engine) createStream(db, sql string) (*Response, error) {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+// resolveTableSchema returns the column schema for a regular table or a
+// supertable's subtable, searching every supertable in the database for
+// the latter.
+func (e *Engine) resolveTableSchema(db, table string) ([]Column, error) {
+	database := e.getDatabase(db)
+	if database == nil {
+		return nil, fmt.Errorf("database not found: %s", db)
+	}
+	if t, ok := database.Tables[table]; ok {
+		return t.Schema, nil
+	}
+	for _, stable := range database.STables {
+		if t, ok := stable.SubTables[table]; ok {
+			return t.Schema, nil
+		}
+	}
+	return nil, fmt.Errorf("table not found: %s", table)
+}
+
+// aggregateRows groups rows into fixed-width time buckets (the whole
+// result set is one bucket when intervalMs is 0, i.e. no INTERVAL
+// clause) and evaluates each aggregate field per bucket.
+func aggregateRows(rows []storage.Row, fields []parser.SelectField, colIndex map[string]int, schema []Column, intervalMs int64) ([][]interface{}, error) {
+	type bucket struct {
+		ts   int64
+		rows []storage.Row
+	}
+
+	byTs := make(map[int64]*bucket)
+	var buckets []*bucket
+	for _, r := range rows {
+		bucketTs := r.Ts
+		if intervalMs > 0 {
+			bucketTs = (r.Ts / intervalMs) * intervalMs
+		}
+		b, ok := byTs[bucketTs]
+		if !ok {
+			b = &bucket{ts: bucketTs}
+			byTs[bucketTs] = b
+			buckets = append(buckets, b)
+		}
+		b.rows = append(b.rows, r)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].ts < buckets[j].ts })
 
-	// Parse CREATE STREAM syntax
-	matches := reCreateStream.FindStringSubmatch(sql)
-	if len(matches) < 7 {
-		return nil, fmt.Errorf("invalid CREATE STREAM syntax")
+	out := make([][]interface{}, 0, len(buckets))
+	for _, b := range buckets {
+		row := make([]interface{}, 0, len(fields))
+		for _, f := range fields {
+			if f.Func == "" && f.Name == "ts" {
+				row = append(row, b.ts)
+				continue
+			}
+			v, err := aggregateField(b.rows, f, colIndex, schema)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, v)
+		}
+		out = append(out, row)
 	}
+	return out, nil
+}
 
-	name := matches[2]
-	trigger := matches[3]
-	if trigger == "" {
-		trigger = "at_once"
+// aggregateField evaluates one aggregate SELECT field (AVG/SUM/MIN/MAX/
+// COUNT/FIRST/LAST) over a bucket's rows.
+func aggregateField(rows []storage.Row, f parser.SelectField, colIndex map[string]int, schema []Column) (interface{}, error) {
+	if strings.EqualFold(f.Func, "COUNT") {
+		if f.Name != "*" {
+			if _, ok := colIndex[f.Name]; !ok {
+				return nil, fmt.Errorf("query: unknown column %q", f.Name)
+			}
+		}
+		// Nulls aren't modeled yet, so every stored row counts.
+		return int64(len(rows)), nil
 	}
-	watermark := matches[4]
-	targetTable := matches[5]
-	selectSQL := matches[6]
 
-	e.streams[name] = &StreamDefinition{
-		Name:        name,
-		TargetTable: targetTable,
-		SQL:         selectSQL,
-		Trigger:     trigger,
-		Watermark:   watermark,
-		CreatedAt:   time.Now(),
+	idx, ok := colIndex[f.Name]
+	if !ok {
+		return nil, fmt.Errorf("query: unknown column %q", f.Name)
 	}
 
-	return &Response{Code: TSDB_CODE_SUCCESS}, nil
+	switch strings.ToUpper(f.Func) {
+	case "FIRST":
+		return rows[0].Values[idx], nil
+	case "LAST":
+		return rows[len(rows)-1].Values[idx], nil
+	}
+
+	if idx < len(schema) && schema[idx].Type == TSDB_DATA_TYPE_DECIMAL {
+		return aggregateDecimalField(rows, f, idx, schema[idx].Scale)
+	}
+
+	sum, lo, hi := 0.0, math.Inf(1), math.Inf(-1)
+	for _, r := range rows {
+		v, err := toFloat64(r.Values[idx])
+		if err != nil {
+			return nil, err
+		}
+		sum += v
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+
+	switch strings.ToUpper(f.Func) {
+	case "SUM":
+		return sum, nil
+	case "AVG":
+		return sum / float64(len(rows)), nil
+	case "MIN":
+		return lo, nil
+	case "MAX":
+		return hi, nil
+	default:
+		return nil, fmt.Errorf("query: unsupported aggregate function %s", f.Func)
+	}
+}
+
+// aggregateDecimalField evaluates SUM/AVG/MIN/MAX over a DECIMAL column
+// using exact Decimal arithmetic instead of aggregateField's float64 path,
+// so financial columns don't accumulate rounding error across a bucket.
+func aggregateDecimalField(rows []storage.Row, f parser.SelectField, idx, scale int) (interface{}, error) {
+	values := make([]Decimal, 0, len(rows))
+	for _, r := range rows {
+		s, ok := r.Values[idx].(string)
+		if !ok {
+			return nil, fmt.Errorf("query: expected decimal string, got %T", r.Values[idx])
+		}
+		d, err := ParseDecimal(s, 0, scale)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, d)
+	}
+
+	sum := Decimal{Coef: big.NewInt(0), Scale: scale}
+	lo, hi := values[0], values[0]
+	for _, d := range values {
+		sum = AddDecimal(sum, d)
+		if CompareDecimal(d, lo) < 0 {
+			lo = d
+		}
+		if CompareDecimal(d, hi) > 0 {
+			hi = d
+		}
+	}
+
+	switch strings.ToUpper(f.Func) {
+	case "SUM":
+		return sum.String(), nil
+	case "AVG":
+		// A quotient of two exact decimals isn't generally representable
+		// with finitely many base-10 digits (e.g. 1/3), so AVG rounds
+		// through float64 rather than claiming false exactness.
+		avg, err := strconv.ParseFloat(sum.String(), 64)
+		if err != nil {
+			return nil, err
+		}
+		return DecimalFromFloat64(avg/float64(len(values)), scale).String(), nil
+	case "MIN":
+		return lo.String(), nil
+	case "MAX":
+		return hi.String(), nil
+	default:
+		return nil, fmt.Errorf("query: unsupported aggregate function %s", f.Func)
+	}
+}
+
+// filterDecimalRows keeps only the rows whose column idx - a DECIMAL
+// column stored at the given scale - satisfies cond, comparing with exact
+// Decimal arithmetic instead of toFloat64's lossy float64 path.
+func filterDecimalRows(rows []storage.Row, idx int, cond parser.WhereCond, scale int) ([]storage.Row, error) {
+	right, err := decimalOperand(cond.Right, scale)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]storage.Row, 0, len(rows))
+	for _, r := range rows {
+		s, ok := r.Values[idx].(string)
+		if !ok {
+			return nil, fmt.Errorf("query: expected decimal string, got %T", r.Values[idx])
+		}
+		left, err := ParseDecimal(s, 0, scale)
+		if err != nil {
+			return nil, err
+		}
+
+		cmp := CompareDecimal(left, right)
+		var keep bool
+		switch cond.Operator {
+		case "=":
+			keep = cmp == 0
+		case "<>":
+			keep = cmp != 0
+		case ">":
+			keep = cmp > 0
+		case ">=":
+			keep = cmp >= 0
+		case "<":
+			keep = cmp < 0
+		case "<=":
+			keep = cmp <= 0
+		default:
+			return nil, fmt.Errorf("query: unsupported operator %q against DECIMAL column", cond.Operator)
+		}
+		if keep {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// decimalOperand parses a WHERE literal being compared against a DECIMAL
+// column, coercing a plain BIGINT literal to Decimal via DecimalFromInt64
+// rather than routing it through ParseDecimal's string-splitting path -
+// the same mixed-type coercion the request asked for, just applied to the
+// one place this engine evaluates comparisons against a DECIMAL column.
+// A literal that already has a decimal point is parsed directly, since
+// ParseDecimal is exact where DecimalFromFloat64 would round-trip through
+// float64 needlessly.
+func decimalOperand(literal string, scale int) (Decimal, error) {
+	if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return DecimalFromInt64(i, scale), nil
+	}
+	return ParseDecimal(literal, 0, scale)
+}
+
+// toFloat64 coerces a stored column value to float64 for aggregation.
+func toFloat64(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	default:
+		return 0, fmt.Errorf("query: cannot aggregate non-numeric value %v", v)
+	}
 }
 
-func (e *Engine) dropStream(sql string) (*Response, error) {
+// parseIntervalMs parses a duration literal like "10s"/"5m"/"1h" into
+// milliseconds.
+func parseIntervalMs(s string) (int64, error) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("query: invalid interval %q", s)
+	}
+	n, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("query: invalid interval %q: %w", s, err)
+	}
+
+	switch s[i:] {
+	case "a", "ms":
+		return n, nil
+	case "s":
+		return n * 1000, nil
+	case "m":
+		return n * 60 * 1000, nil
+	case "h":
+		return n * 60 * 60 * 1000, nil
+	case "d":
+		return n * 24 * 60 * 60 * 1000, nil
+	case "w":
+		return n * 7 * 24 * 60 * 60 * 1000, nil
+	default:
+		return 0, fmt.Errorf("query: unsupported interval unit %q", s[i:])
+	}
+}
+
+func (e *Engine) createStream(db string, stmt *parser.CreateStreamStmt) (*Response, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	matches := reDropStream.FindStringSubmatch(sql)
-	if len(matches) < 3 {
-		return nil, fmt.Errorf("invalid DROP STREAM syntax")
+	e.streams[stmt.Name] = &StreamDefinition{
+		Name:        stmt.Name,
+		TargetTable: stmt.TargetTable,
+		SQL:         stmt.SelectText,
+		Trigger:     stmt.Trigger,
+		Watermark:   stmt.Watermark,
+		CreatedAt:   time.Now(),
 	}
+	e.streamRuntimes[stmt.Name] = newStreamRuntime(stmt)
 
-	ifExists := matches[1] != ""
-	name := matches[2]
+	return &Response{Code: TSDB_CODE_SUCCESS}, nil
+}
+
+func (e *Engine) dropStream(stmt *parser.DropStreamStmt) (*Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	if _, exists := e.streams[name]; !exists {
-		if ifExists {
+	if _, exists := e.streams[stmt.Name]; !exists {
+		if stmt.IfExists {
 			return &Response{Code: TSDB_CODE_SUCCESS}, nil
 		}
-		return nil, fmt.Errorf("stream not found: %s", name)
+		return nil, fmt.Errorf("stream not found: %s", stmt.Name)
 	}
 
-	delete(e.streams, name)
+	delete(e.streams, stmt.Name)
+	delete(e.streamRuntimes, stmt.Name)
 	return &Response{Code: TSDB_CODE_SUCCESS}, nil
 }
 
@@ -814,52 +1557,41 @@ func (e *Engine) showStreams() (*Response, error) {
 	}, nil
 }
 
-func (e *Engine) createTopic(db, sql string) (*Response, error) {
+func (e *Engine) createTopic(db string, stmt *parser.CreateTopicStmt) (*Response, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	re := regexp.MustCompile(`(?i)CREATE\s+TOPIC\s+(IF\s+NOT\s+EXISTS\s+)?(\w+)\s+(?:WITH\s+META\s+)?AS\s+(.+)`)
-	matches := re.FindStringSubmatch(sql)
-	if len(matches) < 4 {
-		return nil, fmt.Errorf("invalid CREATE TOPIC syntax")
-	}
-
-	name := matches[2]
-	selectSQL := matches[3]
-	withMeta := strings.Contains(strings.ToUpper(sql), "WITH META")
-
-	e.topics[name] = &TopicDefinition{
-		Name:      name,
+	e.topics[stmt.Name] = &TopicDefinition{
+		Name:      stmt.Name,
 		Database:  db,
-		SQL:       selectSQL,
-		WithMeta:  withMeta,
+		SQL:       stmt.SelectText,
+		WithMeta:  stmt.WithMeta,
 		CreatedAt: time.Now(),
 	}
+	e.topicRuntimes[stmt.Name] = newTopicRuntime(stmt)
 
 	return &Response{Code: TSDB_CODE_SUCCESS}, nil
 }
 
-func (e *Engine) dropTopic(sql string) (*Response, error) {
+func (e *Engine) dropTopic(stmt *parser.DropTopicStmt) (*Response, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	re := regexp.MustCompile(`(?i)DROP\s+TOPIC\s+(IF\s+EXISTS\s+)?(\w+)`)
-	matches := re.FindStringSubmatch(sql)
-	if len(matches) < 3 {
-		return nil, fmt.Errorf("invalid DROP TOPIC syntax")
-	}
-
-	ifExists := matches[1] != ""
-	name := matches[2]
-
-	if _, exists := e.topics[name]; !exists {
-		if ifExists {
+	if _, exists := e.topics[stmt.Name]; !exists {
+		if stmt.IfExists {
 			return &Response{Code: TSDB_CODE_SUCCESS}, nil
 		}
-		return nil, fmt.Errorf("topic not found: %s", name)
+		return nil, fmt.Errorf("topic not found: %s", stmt.Name)
 	}
 
-	delete(e.topics, name)
+	delete(e.topics, stmt.Name)
+	delete(e.topicRuntimes, stmt.Name)
+	delete(e.topicLogs, stmt.Name)
+	for key := range e.consumerGroups {
+		if key.topic == stmt.Name {
+			delete(e.consumerGroups, key)
+		}
+	}
 	return &Response{Code: TSDB_CODE_SUCCESS}, nil
 }
 
@@ -888,8 +1620,15 @@ func (e *Engine) showTopics() (*Response, error) {
 	}, nil
 }
 
-func (e *Engine) alterTable(db, sql string) (*Response, error) {
+func (e *Engine) alterTable(db string, stmt *parser.AlterTableStmt) (*Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if stmt.Database != "" {
+		db = stmt.Database
+	}
 	// TODO: Implement ALTER TABLE
+	e.bumpTableVersionLocked(db, stmt.Name)
 	return &Response{Code: TSDB_CODE_SUCCESS}, nil
 }
 
@@ -965,6 +1704,111 @@ func (e *Engine) showUsers() (*Response, error) {
 	}, nil
 }
 
+// bumpTableVersionLocked invalidates any cached query results depending
+// on (db, table), after a write to it. Callers must already hold e.mu.
+func (e *Engine) bumpTableVersionLocked(db, table string) {
+	if e.queryCache != nil {
+		e.queryCache.InvalidateTable(db, table)
+	}
+}
+
+// queryCacheKey derives a QueryCache key from a SELECT's database and
+// source SQL text, normalized so equivalent statements that only differ
+// in whitespace or keyword casing share a cache entry.
+func queryCacheKey(db, sql string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(db + "|" + normalizeSQL(sql)))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func normalizeSQL(sql string) string {
+	return strings.Join(strings.Fields(strings.ToLower(sql)), " ")
+}
+
+func (e *Engine) showQueryCache() (*Response, error) {
+	e.mu.RLock()
+	cache := e.queryCache
+	e.mu.RUnlock()
+
+	var hits, misses, evictions int64
+	if lru, ok := cache.(*LRUQueryCache); ok {
+		hits, misses, evictions = lru.Stats()
+	}
+
+	return &Response{
+		Code: TSDB_CODE_SUCCESS,
+		ColumnMeta: [][]interface{}{
+			{"enabled", TSDB_DATA_TYPE_BOOL, 1},
+			{"hits", TSDB_DATA_TYPE_BIGINT, 8},
+			{"misses", TSDB_DATA_TYPE_BIGINT, 8},
+			{"evictions", TSDB_DATA_TYPE_BIGINT, 8},
+		},
+		Data: [][]interface{}{{cache != nil, hits, misses, evictions}},
+		Rows: 1,
+	}, nil
+}
+
+func (e *Engine) showHintedHandoff() (*Response, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	data := make([][]interface{}, 0, len(e.hintedHandoff))
+	for db, q := range e.hintedHandoff {
+		stats := q.Stats()
+		data = append(data, []interface{}{
+			db,
+			stats.QueueSize,
+			stats.BytesWritten,
+			stats.WriteBlocked,
+			stats.WriteDropped,
+		})
+	}
+
+	return &Response{
+		Code: TSDB_CODE_SUCCESS,
+		ColumnMeta: [][]interface{}{
+			{"database", TSDB_DATA_TYPE_BINARY, 64},
+			{"queue_size", TSDB_DATA_TYPE_BIGINT, 8},
+			{"bytes_written", TSDB_DATA_TYPE_BIGINT, 8},
+			{"write_blocked", TSDB_DATA_TYPE_BIGINT, 8},
+			{"write_dropped", TSDB_DATA_TYPE_BIGINT, 8},
+		},
+		Data: data,
+		Rows: len(data),
+	}, nil
+}
+
+func (e *Engine) showSchemaChanges() (*Response, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	data := make([][]interface{}, 0, len(e.schemaChanges))
+	for _, ev := range e.schemaChanges {
+		data = append(data, []interface{}{
+			ev.Database,
+			ev.STable,
+			ev.Field,
+			ev.OldType,
+			ev.NewType,
+			ev.At.UnixMilli(),
+		})
+	}
+
+	return &Response{
+		Code: TSDB_CODE_SUCCESS,
+		ColumnMeta: [][]interface{}{
+			{"database", TSDB_DATA_TYPE_BINARY, 64},
+			{"stable", TSDB_DATA_TYPE_BINARY, 64},
+			{"field", TSDB_DATA_TYPE_BINARY, 64},
+			{"old_type", TSDB_DATA_TYPE_BINARY, 16},
+			{"new_type", TSDB_DATA_TYPE_BINARY, 16},
+			{"at", TSDB_DATA_TYPE_TIMESTAMP, 8},
+		},
+		Data: data,
+		Rows: len(data),
+	}, nil
+}
+
 // Helper functions
 
 func (e *Engine) getDatabase(name string) *Database {
@@ -986,56 +1830,119 @@ func generateTableName(stable string, tags map[string]string) string {
 	return stable + "_" + strings.Join(parts, "_")
 }
 
-func parseColumns(columnsStr string, isTags bool) []Column {
-	columns := []Column{}
-	parts := strings.Split(columnsStr, ",")
-
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		fields := strings.Fields(part)
-		if len(fields) < 2 {
-			continue
-		}
-
-		name := fields[0]
-		typeStr := strings.ToUpper(fields[1])
-		length := 0
-
-		// Parse length for BINARY/NCHAR
-		if strings.Contains(typeStr, "(") {
-			re := regexp.MustCompile(`(\w+)\((\d+)\)`)
-			matches := re.FindStringSubmatch(typeStr)
-			if len(matches) >= 3 {
-				typeStr = matches[1]
-				length, _ = strconv.Atoi(matches[2])
-			}
-		}
-
+// columnsToSchema converts the parser's column/tag definitions into the
+// engine's internal Column representation.
+func columnsToSchema(defs []parser.ColumnDef, isTags bool) []Column {
+	columns := make([]Column, 0, len(defs))
+	for _, def := range defs {
 		columns = append(columns, Column{
-			Name:   name,
-			Type:   stringToType(typeStr),
-			Length: length,
-			IsTag:  isTags,
+			Name:      def.Name,
+			Type:      stringToType(def.Type),
+			Length:    def.Length,
+			IsTag:     isTags,
+			Precision: def.Precision,
+			Scale:     def.Scale,
 		})
 	}
-
 	return columns
 }
 
-func parseTagValues(valuesStr string, tagDefs []Column) map[string]interface{} {
-	values := make(map[string]interface{})
-	parts := strings.Split(valuesStr, ",")
-
-	for i, part := range parts {
+// tagValuesToMap pairs parsed literal tag values positionally against a
+// supertable's tag definitions, same as CREATE TABLE ... USING ... TAGS(...),
+// converting each literal through convertLiteral against its tag's declared
+// type so e.g. an INT tag comes back as an int64 rather than its source text.
+func tagValuesToMap(values []string, tagDefs []Column) (map[string]interface{}, error) {
+	out := make(map[string]interface{})
+	for i, v := range values {
 		if i >= len(tagDefs) {
 			break
 		}
-		part = strings.TrimSpace(part)
-		part = strings.Trim(part, "'\"")
-		values[tagDefs[i].Name] = part
+		tv, err := convertLiteral(v, tagDefs[i])
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", tagDefs[i].Name, err)
+		}
+		out[tagDefs[i].Name] = tv
+	}
+	return out, nil
+}
+
+// storageSchema converts a table's column definitions into the storage
+// layer's column schema, dropping the implicit ts column (carried as
+// storage.Row.Ts instead) and any column type the column store can't
+// represent.
+func storageSchema(schema []Column) []storage.ColumnSchema {
+	out := make([]storage.ColumnSchema, 0, len(schema))
+	for _, col := range schema {
+		ct, ok := storageColumnType(col.Type)
+		if !ok {
+			continue
+		}
+		out = append(out, storage.ColumnSchema{Name: col.Name, Type: ct})
+	}
+	return out
+}
+
+// storageColumnType maps an engine column type to its storage.ColumnType;
+// ok is false for TSDB_DATA_TYPE_TIMESTAMP, which the storage layer
+// tracks as Row.Ts rather than a regular column.
+func storageColumnType(t int) (storage.ColumnType, bool) {
+	switch t {
+	case TSDB_DATA_TYPE_FLOAT, TSDB_DATA_TYPE_DOUBLE:
+		return storage.ColFloat64, true
+	case TSDB_DATA_TYPE_TINYINT, TSDB_DATA_TYPE_SMALLINT, TSDB_DATA_TYPE_INT, TSDB_DATA_TYPE_BIGINT, TSDB_DATA_TYPE_UBIGINT:
+		return storage.ColInt64, true
+	case TSDB_DATA_TYPE_BOOL:
+		return storage.ColBool, true
+	case TSDB_DATA_TYPE_BINARY, TSDB_DATA_TYPE_NCHAR, TSDB_DATA_TYPE_JSON, TSDB_DATA_TYPE_DECIMAL:
+		return storage.ColString, true
+	default:
+		return 0, false
+	}
+}
+
+// convertLiteral parses an INSERT value's unevaluated source text (as
+// produced by parser.ParseValueToken) into the Go value the storage layer
+// expects for col's declared type.
+func convertLiteral(lit string, col Column) (interface{}, error) {
+	switch col.Type {
+	case TSDB_DATA_TYPE_TIMESTAMP:
+		if strings.EqualFold(lit, "NOW()") {
+			return time.Now().UnixMilli(), nil
+		}
+		return strconv.ParseInt(lit, 10, 64)
+	case TSDB_DATA_TYPE_BOOL:
+		return strings.EqualFold(lit, "TRUE"), nil
+	case TSDB_DATA_TYPE_TINYINT, TSDB_DATA_TYPE_SMALLINT, TSDB_DATA_TYPE_INT, TSDB_DATA_TYPE_BIGINT, TSDB_DATA_TYPE_UBIGINT:
+		return strconv.ParseInt(lit, 10, 64)
+	case TSDB_DATA_TYPE_FLOAT, TSDB_DATA_TYPE_DOUBLE:
+		return strconv.ParseFloat(lit, 64)
+	case TSDB_DATA_TYPE_DECIMAL:
+		d, err := ParseDecimal(lit, col.Precision, col.Scale)
+		if err != nil {
+			return nil, err
+		}
+		return d.String(), nil
+	default:
+		return lit, nil
 	}
+}
 
-	return values
+// zeroValueForType is the value stored for a column an INSERT's explicit
+// column list omitted; NULL isn't modeled yet, so omitted columns fall
+// back to their type's zero value.
+func zeroValueForType(t int) interface{} {
+	switch t {
+	case TSDB_DATA_TYPE_FLOAT, TSDB_DATA_TYPE_DOUBLE:
+		return float64(0)
+	case TSDB_DATA_TYPE_TINYINT, TSDB_DATA_TYPE_SMALLINT, TSDB_DATA_TYPE_INT, TSDB_DATA_TYPE_BIGINT, TSDB_DATA_TYPE_UBIGINT:
+		return int64(0)
+	case TSDB_DATA_TYPE_BOOL:
+		return false
+	case TSDB_DATA_TYPE_DECIMAL:
+		return Decimal{}.String()
+	default:
+		return ""
+	}
 }
 
 func stringToType(s string) int {
@@ -1052,6 +1959,8 @@ func stringToType(s string) int {
 		return TSDB_DATA_TYPE_INT
 	case "BIGINT":
 		return TSDB_DATA_TYPE_BIGINT
+	case "UBIGINT":
+		return TSDB_DATA_TYPE_UBIGINT
 	case "FLOAT":
 		return TSDB_DATA_TYPE_FLOAT
 	case "DOUBLE":
@@ -1062,11 +1971,20 @@ func stringToType(s string) int {
 		return TSDB_DATA_TYPE_NCHAR
 	case "JSON":
 		return TSDB_DATA_TYPE_JSON
+	case "DECIMAL", "NUMERIC":
+		return TSDB_DATA_TYPE_DECIMAL
 	default:
 		return TSDB_DATA_TYPE_BINARY
 	}
 }
 
+// TypeToString is the exported form of typeToString, for callers outside
+// the package (e.g. pkg/tdengine/driver) that need to render a
+// TSDB_DATA_TYPE_* constant the same way DESCRIBE does.
+func TypeToString(t int) string {
+	return typeToString(t)
+}
+
 func typeToString(t int) string {
 	switch t {
 	case TSDB_DATA_TYPE_TIMESTAMP:
@@ -1081,6 +1999,8 @@ func typeToString(t int) string {
 		return "INT"
 	case TSDB_DATA_TYPE_BIGINT:
 		return "BIGINT"
+	case TSDB_DATA_TYPE_UBIGINT:
+		return "UBIGINT"
 	case TSDB_DATA_TYPE_FLOAT:
 		return "FLOAT"
 	case TSDB_DATA_TYPE_DOUBLE:
@@ -1091,6 +2011,8 @@ func typeToString(t int) string {
 		return "NCHAR"
 	case TSDB_DATA_TYPE_JSON:
 		return "JSON"
+	case TSDB_DATA_TYPE_DECIMAL:
+		return "DECIMAL"
 	default:
 		return "UNKNOWN"
 	}
@@ -0,0 +1,426 @@
+// Package hintedhandoff buffers writes a destination couldn't absorb
+// the moment they arrived, handing them off to a background drainer
+// that retries with exponential backoff — the same shape as InfluxDB's
+// hinted-handoff queue, but backed by a segment-file queue on disk
+// instead of hinted-handoff's BoltDB store.
+package hintedhandoff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WriteFunc applies one buffered payload to the real destination. The
+// drainer calls it with retry/backoff until it returns nil.
+type WriteFunc func(payload []byte) error
+
+// Config controls a Queue's retry, sizing, and purge behavior.
+type Config struct {
+	// RetryInterval is the initial delay between drain attempts after a
+	// WriteFunc failure.
+	RetryInterval time.Duration
+	// RetryMaxInterval caps the exponential backoff between retries.
+	RetryMaxInterval time.Duration
+	// SegmentSize is the max size in bytes of one on-disk segment file
+	// before the queue rotates to a new one. 0 uses defaultSegmentSize.
+	SegmentSize int64
+	// MaxSize bounds the queue's total on-disk size in bytes. 0 means
+	// unbounded. Enqueue calls that would exceed it are dropped after a
+	// short grace period spent waiting for the drainer to catch up.
+	MaxSize int64
+	// MaxAge purges a whole segment once its oldest record is older than
+	// this, counting every record it held as dropped. 0 disables.
+	MaxAge time.Duration
+}
+
+const defaultSegmentSize = 8 << 20 // 8MiB
+
+func (c Config) withDefaults() Config {
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = 500 * time.Millisecond
+	}
+	if c.RetryMaxInterval <= 0 {
+		c.RetryMaxInterval = 30 * time.Second
+	}
+	if c.SegmentSize <= 0 {
+		c.SegmentSize = defaultSegmentSize
+	}
+	return c
+}
+
+// Stats are the cumulative counters SHOW HINTED HANDOFF surfaces.
+type Stats struct {
+	QueueSize    int64 // records currently buffered, not yet drained
+	BytesWritten int64 // total payload bytes ever enqueued
+	WriteBlocked int64 // times Enqueue had to wait for the drainer to free MaxSize headroom
+	WriteDropped int64 // records dropped: MaxSize still exceeded after waiting, or purged by MaxAge
+}
+
+// recordHeader is the fixed-size prefix written before every record's
+// payload: length, CRC32 of the payload, and a monotonic sequence
+// number used to detect and skip partially-written records after a
+// crash.
+const recordHeaderSize = 4 + 4 + 8
+
+// Queue is a durable, on-disk FIFO of pending writes for one
+// destination, drained by a background goroutine that retries failed
+// writes with exponential backoff.
+type Queue struct {
+	dir     string
+	cfg     Config
+	writeFn WriteFunc
+
+	mu      sync.Mutex
+	nextSeq uint64
+	seg     *os.File
+	segPath string
+	segSize int64
+
+	pending   int64 // records in segments not yet drained
+	stats     Stats
+	wakeCh    chan struct{}
+	stopCh    chan struct{}
+	drainDone chan struct{}
+}
+
+// NewQueue opens (or creates) a segment-file queue under dir and starts
+// its background drainer, which replays any segments left over from a
+// previous run before serving newly enqueued writes.
+func NewQueue(dir string, cfg Config, writeFn WriteFunc) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("hintedhandoff: create dir: %w", err)
+	}
+
+	q := &Queue{
+		dir:       dir,
+		cfg:       cfg.withDefaults(),
+		writeFn:   writeFn,
+		wakeCh:    make(chan struct{}, 1),
+		stopCh:    make(chan struct{}),
+		drainDone: make(chan struct{}),
+	}
+
+	segments, err := q.listSegments()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range segments {
+		n, err := countRecords(path)
+		if err != nil {
+			return nil, err
+		}
+		q.pending += n
+	}
+	if last, ok := lastSeq(segments); ok {
+		q.nextSeq = last + 1
+	}
+
+	if err := q.rotateLocked(); err != nil {
+		return nil, err
+	}
+
+	go q.drain()
+	return q, nil
+}
+
+// listSegments returns every segment file under dir, oldest first.
+func (q *Queue) listSegments() ([]string, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("hintedhandoff: list segments: %w", err)
+	}
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".seg" {
+			out = append(out, filepath.Join(q.dir, e.Name()))
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// lastSeq scans every segment (the trailing active one is typically
+// empty, so the highest sequence actually belongs to an earlier file)
+// and returns the greatest record sequence number found across all of
+// them.
+func lastSeq(segments []string) (uint64, bool) {
+	var last uint64
+	found := false
+	for _, path := range segments {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		for {
+			_, seq, _, err := readRecord(f)
+			if err != nil {
+				break
+			}
+			last, found = seq, true
+		}
+		f.Close()
+	}
+	return last, found
+}
+
+func countRecords(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("hintedhandoff: open segment: %w", err)
+	}
+	defer f.Close()
+
+	var n int64
+	for {
+		_, _, _, err := readRecord(f)
+		if err != nil {
+			break
+		}
+		n++
+	}
+	return n, nil
+}
+
+// rotateLocked closes the current segment (if any) and opens a fresh
+// one named after the next sequence number it will hold. Callers must
+// hold q.mu.
+func (q *Queue) rotateLocked() error {
+	if q.seg != nil {
+		if err := q.seg.Close(); err != nil {
+			return fmt.Errorf("hintedhandoff: close segment: %w", err)
+		}
+	}
+
+	path := filepath.Join(q.dir, fmt.Sprintf("%020d.seg", q.nextSeq))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("hintedhandoff: open segment: %w", err)
+	}
+	q.seg, q.segPath, q.segSize = f, path, 0
+	return nil
+}
+
+// Enqueue durably appends payload to the queue for later draining. It
+// waits briefly for headroom if MaxSize is already exceeded, and drops
+// the write (counted in Stats.WriteDropped) if headroom never frees up.
+func (q *Queue) Enqueue(payload []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.cfg.MaxSize > 0 {
+		for attempt := 0; q.currentSizeLocked() >= q.cfg.MaxSize; attempt++ {
+			if attempt >= 3 {
+				atomic.AddInt64(&q.stats.WriteDropped, 1)
+				return nil
+			}
+			atomic.AddInt64(&q.stats.WriteBlocked, 1)
+			q.mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			q.mu.Lock()
+		}
+	}
+
+	seq := q.nextSeq
+	q.nextSeq++
+
+	if err := writeRecord(q.seg, seq, payload); err != nil {
+		return fmt.Errorf("hintedhandoff: write record: %w", err)
+	}
+	q.segSize += recordHeaderSize + int64(len(payload))
+	q.pending++
+	atomic.AddInt64(&q.stats.QueueSize, 1)
+	atomic.AddInt64(&q.stats.BytesWritten, int64(len(payload)))
+
+	if q.segSize >= q.cfg.SegmentSize {
+		if err := q.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case q.wakeCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *Queue) currentSizeLocked() int64 {
+	segments, err := q.listSegments()
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, path := range segments {
+		if fi, err := os.Stat(path); err == nil {
+			total += fi.Size()
+		}
+	}
+	return total
+}
+
+// Stats returns the queue's cumulative counters.
+func (q *Queue) Stats() Stats {
+	return Stats{
+		QueueSize:    atomic.LoadInt64(&q.stats.QueueSize),
+		BytesWritten: atomic.LoadInt64(&q.stats.BytesWritten),
+		WriteBlocked: atomic.LoadInt64(&q.stats.WriteBlocked),
+		WriteDropped: atomic.LoadInt64(&q.stats.WriteDropped),
+	}
+}
+
+// Close stops the drainer and flushes the active segment to disk.
+func (q *Queue) Close() error {
+	close(q.stopCh)
+	<-q.drainDone
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.seg != nil {
+		return q.seg.Close()
+	}
+	return nil
+}
+
+// drain is the background goroutine that replays every segment except
+// the one currently open for writes, oldest first, retrying each
+// record with exponential backoff until writeFn accepts it.
+func (q *Queue) drain() {
+	defer close(q.drainDone)
+
+	backoff := q.cfg.RetryInterval
+	for {
+		drainedAny, err := q.drainOnce()
+		if err != nil {
+			if backoff < q.cfg.RetryMaxInterval {
+				backoff *= 2
+				if backoff > q.cfg.RetryMaxInterval {
+					backoff = q.cfg.RetryMaxInterval
+				}
+			}
+		} else {
+			backoff = q.cfg.RetryInterval
+		}
+
+		if drainedAny {
+			continue // more might be waiting; keep draining without sleeping
+		}
+
+		select {
+		case <-q.stopCh:
+			return
+		case <-q.wakeCh:
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// drainOnce replays the oldest rotated-out segment's records through
+// writeFn, purging the segment (fully, on success, or via MaxAge) when
+// done. It returns drainedAny=true if it made forward progress, so the
+// caller can keep looping without an inter-attempt sleep.
+func (q *Queue) drainOnce() (drainedAny bool, err error) {
+	q.mu.Lock()
+	segments, listErr := q.listSegments()
+	active := q.segPath
+	q.mu.Unlock()
+	if listErr != nil {
+		return false, listErr
+	}
+
+	for _, path := range segments {
+		if path == active {
+			continue // never drain the segment still open for appends
+		}
+
+		if q.cfg.MaxAge > 0 {
+			if fi, statErr := os.Stat(path); statErr == nil && time.Since(fi.ModTime()) > q.cfg.MaxAge {
+				n, _ := countRecords(path)
+				atomic.AddInt64(&q.stats.WriteDropped, n)
+				atomic.AddInt64(&q.stats.QueueSize, -n)
+				os.Remove(path)
+				continue
+			}
+		}
+
+		ok, drainErr := q.drainSegment(path)
+		if ok {
+			drainedAny = true
+		}
+		if drainErr != nil {
+			return drainedAny, drainErr
+		}
+	}
+	return drainedAny, nil
+}
+
+// drainSegment replays one segment file fully and removes it once every
+// record has been accepted by writeFn.
+func (q *Queue) drainSegment(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("hintedhandoff: open segment: %w", err)
+	}
+	defer f.Close()
+
+	any := false
+	for {
+		payload, _, _, err := readRecord(f)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			break // truncated/corrupt tail; stop here, file still gets removed below
+		}
+		if err := q.writeFn(payload); err != nil {
+			return any, err
+		}
+		any = true
+		atomic.AddInt64(&q.stats.QueueSize, -1)
+	}
+	return any, os.Remove(path)
+}
+
+// writeRecord appends one length+CRC+seq-framed record to f.
+func writeRecord(f *os.File, seq uint64, payload []byte) error {
+	header := make([]byte, recordHeaderSize)
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+	binary.BigEndian.PutUint64(header[8:16], seq)
+
+	if _, err := f.Write(header); err != nil {
+		return err
+	}
+	if _, err := f.Write(payload); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// readRecord reads one record from r, validating its CRC. It returns
+// io.EOF once r is exhausted at a record boundary.
+func readRecord(r io.Reader) (payload []byte, seq uint64, ok bool, err error) {
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, false, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	wantCRC := binary.BigEndian.Uint32(header[4:8])
+	seq = binary.BigEndian.Uint64(header[8:16])
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, false, io.ErrUnexpectedEOF
+	}
+	if crc32.ChecksumIEEE(payload) != wantCRC {
+		return nil, 0, false, fmt.Errorf("hintedhandoff: corrupt record seq %d: crc mismatch", seq)
+	}
+	return payload, seq, true, nil
+}
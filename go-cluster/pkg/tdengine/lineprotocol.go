@@ -0,0 +1,154 @@
+package tdengine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseInfluxDBLine parses a single InfluxDB line protocol record, e.g.
+//
+//	cpu,host=server01,region=us-west value=64i,idle=true 1630000000000000000
+//
+// into an InfluxDBLineProtocol. Field values are typed according to their
+// suffix: a trailing "i" is an int64, "u" an uint64, "t"/"f"/"true"/"false"
+// a bool, a double-quoted value a string, and anything else a float64. If
+// line has no trailing timestamp, defaultTimestamp is used instead.
+func ParseInfluxDBLine(line string, defaultTimestamp int64) (*InfluxDBLineProtocol, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, fmt.Errorf("empty or comment line")
+	}
+
+	// The three space-separated top-level sections (measurement+tags,
+	// fields, timestamp) are themselves separated on unescaped spaces;
+	// spaces inside quoted field values or escaped with '\' don't split.
+	sections := splitUnescaped(line, ' ')
+	if len(sections) < 2 || len(sections) > 3 {
+		return nil, fmt.Errorf("invalid line protocol: expected \"measurement[,tags] fields [timestamp]\", got %q", line)
+	}
+
+	identParts := splitUnescaped(sections[0], ',')
+	if len(identParts) == 0 || identParts[0] == "" {
+		return nil, fmt.Errorf("invalid line protocol: missing measurement")
+	}
+	measurement := unescapeLP(identParts[0])
+
+	tags := make(map[string]string, len(identParts)-1)
+	for _, part := range identParts[1:] {
+		k, v, err := splitKV(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %w", part, err)
+		}
+		tags[unescapeLP(k)] = unescapeLP(v)
+	}
+
+	fieldParts := splitUnescaped(sections[1], ',')
+	if len(fieldParts) == 0 {
+		return nil, fmt.Errorf("invalid line protocol: missing field set")
+	}
+	fields := make(map[string]interface{}, len(fieldParts))
+	for _, part := range fieldParts {
+		k, v, err := splitKV(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %q: %w", part, err)
+		}
+		value, err := parseFieldValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %q: %w", part, err)
+		}
+		fields[unescapeLP(k)] = value
+	}
+
+	timestamp := defaultTimestamp
+	if len(sections) == 3 {
+		ts, err := strconv.ParseInt(sections[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %w", sections[2], err)
+		}
+		timestamp = ts
+	}
+
+	return &InfluxDBLineProtocol{
+		Measurement: measurement,
+		Tags:        tags,
+		Fields:      fields,
+		Timestamp:   timestamp,
+	}, nil
+}
+
+// splitUnescaped splits s on every occurrence of sep that isn't inside a
+// double-quoted span and isn't preceded by a backslash escape.
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	var cur strings.Builder
+	inQuotes := false
+	escaped := false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			cur.WriteByte(c)
+			escaped = false
+		case c == '\\':
+			cur.WriteByte(c)
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+			cur.WriteByte(c)
+		case c == sep && !inQuotes:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	parts = append(parts, cur.String())
+	return parts
+}
+
+// splitKV splits a "key=value" token on its first unescaped '='.
+func splitKV(s string) (key, value string, err error) {
+	kv := splitUnescaped(s, '=')
+	if len(kv) != 2 {
+		return "", "", fmt.Errorf("expected exactly one unescaped '='")
+	}
+	return kv[0], kv[1], nil
+}
+
+// unescapeLP removes line protocol's backslash-escaping of commas, spaces,
+// and equals signs in measurement names, tag keys/values, and field keys.
+func unescapeLP(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		out.WriteByte(s[i])
+	}
+	return out.String()
+}
+
+// parseFieldValue parses one field's raw text according to its type
+// suffix: "i" -> int64, "u" -> uint64, "t"/"f"/"true"/"false" -> bool, a
+// double-quoted span -> string, anything else -> float64.
+func parseFieldValue(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) && len(s) >= 2:
+		return unescapeLP(s[1 : len(s)-1]), nil
+	case strings.EqualFold(s, "t") || strings.EqualFold(s, "true"):
+		return true, nil
+	case strings.EqualFold(s, "f") || strings.EqualFold(s, "false"):
+		return false, nil
+	case strings.HasSuffix(s, "i"):
+		return strconv.ParseInt(s[:len(s)-1], 10, 64)
+	case strings.HasSuffix(s, "u"):
+		return strconv.ParseUint(s[:len(s)-1], 10, 64)
+	default:
+		return strconv.ParseFloat(s, 64)
+	}
+}
@@ -0,0 +1,296 @@
+package parser
+
+// Stmt is implemented by every statement the parser can produce.
+// Engine.Execute type-switches on the concrete type to route execution,
+// the same way go/ast's Node works for callers that walk a Go AST.
+type Stmt interface {
+	stmtNode()
+}
+
+// ColumnDef is one column (or tag) definition inside a CREATE
+// STABLE/TABLE's column/tag list, e.g. "value DOUBLE", "name BINARY(32)",
+// or "price DECIMAL(18,4)".
+type ColumnDef struct {
+	Name      string
+	Type      string
+	Length    int // 0 if the type has no length qualifier
+	Precision int // DECIMAL/NUMERIC total digit count; 0 if not a decimal type
+	Scale     int // DECIMAL/NUMERIC fractional digit count
+}
+
+// CreateDatabaseStmt is
+// "CREATE DATABASE [IF NOT EXISTS] name [PRECISION 'ms'] [WITH HINTED HANDOFF]".
+type CreateDatabaseStmt struct {
+	IfNotExists   bool
+	Name          string
+	Precision     string // empty if not specified
+	HintedHandoff bool
+}
+
+func (*CreateDatabaseStmt) stmtNode() {}
+
+// DropDatabaseStmt is "DROP DATABASE [IF EXISTS] name".
+type DropDatabaseStmt struct {
+	IfExists bool
+	Name     string
+}
+
+func (*DropDatabaseStmt) stmtNode() {}
+
+// UseStmt is "USE name".
+type UseStmt struct {
+	Name string
+}
+
+func (*UseStmt) stmtNode() {}
+
+// ShowDatabasesStmt is "SHOW DATABASES".
+type ShowDatabasesStmt struct{}
+
+func (*ShowDatabasesStmt) stmtNode() {}
+
+// CreateSTableStmt is "CREATE STABLE [IF NOT EXISTS] [db.]name (cols) TAGS (tags)".
+type CreateSTableStmt struct {
+	IfNotExists bool
+	Database    string // empty if unqualified
+	Name        string
+	Columns     []ColumnDef
+	Tags        []ColumnDef
+}
+
+func (*CreateSTableStmt) stmtNode() {}
+
+// CreateTableStmt is "CREATE TABLE [IF NOT EXISTS] [db.]name (cols)", i.e.
+// a plain (non-super) table.
+type CreateTableStmt struct {
+	IfNotExists bool
+	Database    string
+	Name        string
+	Columns     []ColumnDef
+}
+
+func (*CreateTableStmt) stmtNode() {}
+
+// CreateSubTableStmt is
+// "CREATE TABLE [IF NOT EXISTS] [db.]name USING [sdb.]stable TAGS (values)".
+type CreateSubTableStmt struct {
+	IfNotExists    bool
+	Database       string
+	Name           string
+	STableDatabase string
+	STableName     string
+	TagValues      []string // literal text, positional against the stable's Tags
+}
+
+func (*CreateSubTableStmt) stmtNode() {}
+
+// DropTableStmt is "DROP TABLE [IF EXISTS] [db.]name".
+type DropTableStmt struct {
+	IfExists bool
+	Database string
+	Name     string
+}
+
+func (*DropTableStmt) stmtNode() {}
+
+// ShowTablesStmt is "SHOW TABLES [FROM db]".
+type ShowTablesStmt struct {
+	Database string
+}
+
+func (*ShowTablesStmt) stmtNode() {}
+
+// ShowSTablesStmt is "SHOW STABLES".
+type ShowSTablesStmt struct{}
+
+func (*ShowSTablesStmt) stmtNode() {}
+
+// DescribeStmt is "DESCRIBE [db.]name".
+type DescribeStmt struct {
+	Database string
+	Name     string
+}
+
+func (*DescribeStmt) stmtNode() {}
+
+// InsertRow is one "(v1, v2, ...)" value list; Values holds each value's
+// literal source text so the engine (or storage layer) can parse it
+// according to the target column's declared type.
+type InsertRow struct {
+	Values []string
+}
+
+// InsertTarget is one "[db.]table [(cols)] VALUES (...)... " clause. A
+// single InsertStmt can have several, e.g.
+// "INSERT INTO t1 VALUES(...) t2 VALUES(...)".
+type InsertTarget struct {
+	Database string
+	Table    string
+	Columns  []string // explicit column list; nil if omitted
+	Rows     []InsertRow
+}
+
+// InsertStmt is "INSERT INTO target [target...]".
+type InsertStmt struct {
+	Targets []InsertTarget
+}
+
+func (*InsertStmt) stmtNode() {}
+
+// SelectField is one projected column, e.g. "ts", "AVG(value)", or
+// "AVG(value) AS avg_value".
+type SelectField struct {
+	Func  string // aggregate function name, empty for a plain column
+	Name  string // column name, or "*" for COUNT(*)
+	Alias string
+}
+
+// WhereCond is a single "left op right" predicate; conjunctions (AND) are
+// flattened into SelectStmt.Where as a slice.
+type WhereCond struct {
+	Left     string
+	Operator string
+	Right    string
+}
+
+// WindowClause is a SELECT's "INTERVAL(i) [SLIDING(s)]" aggregation window.
+type WindowClause struct {
+	Interval string
+	Sliding  string // empty if not specified
+}
+
+// SelectStmt is
+// "SELECT fields FROM table [WHERE conds] [INTERVAL(...) [SLIDING(...)]] [GROUP BY tags]".
+type SelectStmt struct {
+	Fields  []SelectField
+	From    string
+	Where   []WhereCond
+	Window  *WindowClause
+	GroupBy []string
+}
+
+func (*SelectStmt) stmtNode() {}
+
+// CreateStreamStmt is
+// "CREATE STREAM [IF NOT EXISTS] name [TRIGGER t] [WATERMARK w] [MAX_DELAY d] INTO target AS select".
+type CreateStreamStmt struct {
+	IfNotExists bool
+	Name        string
+	Trigger     string
+	Watermark   string
+	MaxDelay    string
+	TargetTable string
+	Select      *SelectStmt
+	SelectText  string // verbatim source text of the AS SELECT clause, for display/storage
+}
+
+func (*CreateStreamStmt) stmtNode() {}
+
+// DropStreamStmt is "DROP STREAM [IF EXISTS] name".
+type DropStreamStmt struct {
+	IfExists bool
+	Name     string
+}
+
+func (*DropStreamStmt) stmtNode() {}
+
+// ShowStreamsStmt is "SHOW STREAMS".
+type ShowStreamsStmt struct{}
+
+func (*ShowStreamsStmt) stmtNode() {}
+
+// CreateTopicStmt is "CREATE TOPIC [IF NOT EXISTS] name [WITH META] AS select".
+type CreateTopicStmt struct {
+	IfNotExists bool
+	Name        string
+	WithMeta    bool
+	Select      *SelectStmt
+	SelectText  string // verbatim source text of the AS SELECT clause, for display/storage
+}
+
+func (*CreateTopicStmt) stmtNode() {}
+
+// DropTopicStmt is "DROP TOPIC [IF EXISTS] name".
+type DropTopicStmt struct {
+	IfExists bool
+	Name     string
+}
+
+func (*DropTopicStmt) stmtNode() {}
+
+// ShowTopicsStmt is "SHOW TOPICS".
+type ShowTopicsStmt struct{}
+
+func (*ShowTopicsStmt) stmtNode() {}
+
+// AlterTableStmt is "ALTER TABLE [db.]name ...". The engine's ALTER TABLE
+// support is still a stub, so Rest retains everything after the table
+// name verbatim until real ADD COLUMN/DROP COLUMN/SET TAG handling lands.
+type AlterTableStmt struct {
+	Database string
+	Name     string
+	Rest     string
+}
+
+func (*AlterTableStmt) stmtNode() {}
+
+// ShowVgroupsStmt is "SHOW VGROUPS".
+type ShowVgroupsStmt struct{}
+
+func (*ShowVgroupsStmt) stmtNode() {}
+
+// ShowDnodesStmt is "SHOW DNODES".
+type ShowDnodesStmt struct{}
+
+func (*ShowDnodesStmt) stmtNode() {}
+
+// ShowMnodesStmt is "SHOW MNODES".
+type ShowMnodesStmt struct{}
+
+func (*ShowMnodesStmt) stmtNode() {}
+
+// ShowUsersStmt is "SHOW USERS".
+type ShowUsersStmt struct{}
+
+func (*ShowUsersStmt) stmtNode() {}
+
+// ShowConsumersStmt is "SHOW CONSUMERS".
+type ShowConsumersStmt struct{}
+
+func (*ShowConsumersStmt) stmtNode() {}
+
+// ShowSubscriptionsStmt is "SHOW SUBSCRIPTIONS".
+type ShowSubscriptionsStmt struct{}
+
+func (*ShowSubscriptionsStmt) stmtNode() {}
+
+// ShowQueryCacheStmt is "SHOW QUERY CACHE".
+type ShowQueryCacheStmt struct{}
+
+func (*ShowQueryCacheStmt) stmtNode() {}
+
+// ShowHintedHandoffStmt is "SHOW HINTED HANDOFF".
+type ShowHintedHandoffStmt struct{}
+
+func (*ShowHintedHandoffStmt) stmtNode() {}
+
+// ShowSchemaChangesStmt is "SHOW SCHEMA CHANGES".
+type ShowSchemaChangesStmt struct{}
+
+func (*ShowSchemaChangesStmt) stmtNode() {}
+
+// ShowCreateTableStmt is "SHOW CREATE TABLE [db.]name".
+type ShowCreateTableStmt struct {
+	Database string
+	Name     string
+}
+
+func (*ShowCreateTableStmt) stmtNode() {}
+
+// ShowCreateSTableStmt is "SHOW CREATE STABLE [db.]name".
+type ShowCreateSTableStmt struct {
+	Database string
+	Name     string
+}
+
+func (*ShowCreateSTableStmt) stmtNode() {}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lexer turns TDengine SQL text into a stream of Tokens. It tolerates the
+// things the old regexp-based dispatch couldn't: line (--) and block
+// (/* */) comments, backtick-quoted identifiers, and single- or
+// double-quoted strings with doubled-quote escaping.
+type Lexer struct {
+	src string
+	pos int
+}
+
+// NewLexer creates a Lexer over src.
+func NewLexer(src string) *Lexer {
+	return &Lexer{src: src}
+}
+
+// Next returns the next token, or a TokEOF token once the input is
+// exhausted.
+func (l *Lexer) Next() (Token, error) {
+	l.skipWhitespaceAndComments()
+
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return Token{Type: TokEOF, Pos: start}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case c == '`':
+		return l.lexQuotedIdent(start)
+	case c == '\'' || c == '"':
+		return l.lexString(start, c)
+	case isIdentStart(c):
+		return l.lexIdent(start), nil
+	case isDigit(c):
+		return l.lexNumber(start), nil
+	default:
+		l.pos++
+		return Token{Type: TokPunct, Value: string(c), Pos: start}, nil
+	}
+}
+
+func (l *Lexer) skipWhitespaceAndComments() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "--"):
+			if idx := strings.IndexByte(l.src[l.pos:], '\n'); idx >= 0 {
+				l.pos += idx + 1
+			} else {
+				l.pos = len(l.src)
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			if idx := strings.Index(l.src[l.pos+2:], "*/"); idx >= 0 {
+				l.pos += 2 + idx + 2
+			} else {
+				l.pos = len(l.src)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *Lexer) lexQuotedIdent(start int) (Token, error) {
+	l.pos++ // skip opening backtick
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		if l.src[l.pos] == '`' {
+			l.pos++
+			return Token{Type: TokIdent, Value: sb.String(), Pos: start}, nil
+		}
+		sb.WriteByte(l.src[l.pos])
+		l.pos++
+	}
+	return Token{}, fmt.Errorf("tdengine/parser: unterminated quoted identifier at position %d", start)
+}
+
+func (l *Lexer) lexString(start int, quote byte) (Token, error) {
+	l.pos++ // skip opening quote
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == quote {
+			// A doubled quote ('' or "") is an escaped literal quote.
+			if l.pos+1 < len(l.src) && l.src[l.pos+1] == quote {
+				sb.WriteByte(quote)
+				l.pos += 2
+				continue
+			}
+			l.pos++
+			return Token{Type: TokString, Value: sb.String(), Pos: start}, nil
+		}
+		if c == '\\' && l.pos+1 < len(l.src) {
+			sb.WriteByte(l.src[l.pos+1])
+			l.pos += 2
+			continue
+		}
+		sb.WriteByte(c)
+		l.pos++
+	}
+	return Token{}, fmt.Errorf("tdengine/parser: unterminated string literal at position %d", start)
+}
+
+func (l *Lexer) lexIdent(start int) Token {
+	for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+		l.pos++
+	}
+	return Token{Type: TokIdent, Value: l.src[start:l.pos], Pos: start}
+}
+
+func (l *Lexer) lexNumber(start int) Token {
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.pos++
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	// A duration literal like "10s" or "500a" glues unit letters directly
+	// onto the number with no separating whitespace; treat the whole thing
+	// as one identifier-shaped token so callers can read it with a single
+	// parseIdent() rather than special-casing durations everywhere.
+	if l.pos < len(l.src) && isIdentStart(l.src[l.pos]) {
+		for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+			l.pos++
+		}
+		return Token{Type: TokIdent, Value: l.src[start:l.pos], Pos: start}
+	}
+	return Token{Type: TokNumber, Value: l.src[start:l.pos], Pos: start}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,46 @@
+package parser
+
+import "testing"
+
+func TestLexer_QuotedIdentAndComma(t *testing.T) {
+	l := NewLexer("`col,1` BINARY(16) DEFAULT 'a,b'")
+	var got []Token
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if tok.Type == TokEOF {
+			break
+		}
+		got = append(got, tok)
+	}
+	if len(got) == 0 || got[0].Value != "col,1" {
+		t.Fatalf("got %+v, want first token value %q", got, "col,1")
+	}
+}
+
+// FuzzLexer feeds arbitrary byte strings through the lexer to make sure
+// malformed input (unterminated strings, stray backticks, lone '-') is
+// reported as an error rather than a panic.
+func FuzzLexer(f *testing.F) {
+	f.Add("CREATE STABLE t (ts TIMESTAMP) TAGS (loc BINARY(16))")
+	f.Add("'unterminated")
+	f.Add("`unterminated")
+	f.Add("-")
+	f.Add("/* unterminated")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, src string) {
+		l := NewLexer(src)
+		for i := 0; i < len(src)+1; i++ {
+			tok, err := l.Next()
+			if err != nil {
+				return
+			}
+			if tok.Type == TokEOF {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,1070 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parser turns a token stream from a Lexer into a Stmt via recursive
+// descent, one statement keyword at a time.
+type Parser struct {
+	lex *Lexer
+	tok Token
+}
+
+func newParser(sql string) (*Parser, error) {
+	p := &Parser{lex: NewLexer(sql)}
+	return p, p.advance()
+}
+
+// Parse parses a single TDengine SQL statement into its typed AST.
+func Parse(sql string) (Stmt, error) {
+	p, err := newParser(sql)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.Type == TokEOF {
+		return nil, p.errorf("empty statement")
+	}
+
+	switch {
+	case p.atKeyword("CREATE"):
+		return p.parseCreate()
+	case p.atKeyword("DROP"):
+		return p.parseDrop()
+	case p.atKeyword("USE"):
+		return p.parseUse()
+	case p.atKeyword("SHOW"):
+		return p.parseShow()
+	case p.atKeyword("DESCRIBE") || p.atKeyword("DESC"):
+		return p.parseDescribe()
+	case p.atKeyword("INSERT"):
+		return p.parseInsert()
+	case p.atKeyword("SELECT"):
+		return p.parseSelect()
+	case p.atKeyword("ALTER"):
+		return p.parseAlter()
+	default:
+		return nil, p.errorf("unsupported statement starting at %s", p.tok)
+	}
+}
+
+func (p *Parser) advance() error {
+	tok, err := p.lex.Next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *Parser) atKeyword(word string) bool {
+	return p.tok.isKeyword(word)
+}
+
+func (p *Parser) expectKeyword(word string) error {
+	if !p.atKeyword(word) {
+		return p.errorf("expected %q, got %s", word, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *Parser) acceptKeyword(word string) (bool, error) {
+	if !p.atKeyword(word) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *Parser) expectPunct(s string) error {
+	if p.tok.Type != TokPunct || p.tok.Value != s {
+		return p.errorf("expected %q, got %s", s, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *Parser) acceptPunct(s string) (bool, error) {
+	if p.tok.Type != TokPunct || p.tok.Value != s {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *Parser) parseIdent() (string, error) {
+	if p.tok.Type != TokIdent {
+		return "", p.errorf("expected identifier, got %s", p.tok)
+	}
+	name := p.tok.Value
+	return name, p.advance()
+}
+
+// parseQualifiedName parses "name" or "db.name", returning an empty db
+// for the unqualified form.
+func (p *Parser) parseQualifiedName() (db, name string, err error) {
+	first, err := p.parseIdent()
+	if err != nil {
+		return "", "", err
+	}
+	if ok, err := p.acceptPunct("."); err != nil {
+		return "", "", err
+	} else if ok {
+		second, err := p.parseIdent()
+		if err != nil {
+			return "", "", err
+		}
+		return first, second, nil
+	}
+	return "", first, nil
+}
+
+func (p *Parser) parseIfNotExists() (bool, error) {
+	ok, err := p.acceptKeyword("IF")
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := p.expectKeyword("NOT"); err != nil {
+		return false, err
+	}
+	return true, p.expectKeyword("EXISTS")
+}
+
+func (p *Parser) parseIfExists() (bool, error) {
+	ok, err := p.acceptKeyword("IF")
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, p.expectKeyword("EXISTS")
+}
+
+// parseColumnList parses "(name type[(len[,len2])], ...)", used for both
+// CREATE STABLE's column list and its TAGS list.
+func (p *Parser) parseColumnList() ([]ColumnDef, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var cols []ColumnDef
+	for {
+		col, err := p.parseColumnDef()
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+		if ok, err := p.acceptPunct(","); err != nil {
+			return nil, err
+		} else if ok {
+			continue
+		}
+		break
+	}
+	return cols, p.expectPunct(")")
+}
+
+func (p *Parser) parseColumnDef() (ColumnDef, error) {
+	name, err := p.parseIdent()
+	if err != nil {
+		return ColumnDef{}, err
+	}
+	typeName, err := p.parseIdent()
+	if err != nil {
+		return ColumnDef{}, err
+	}
+
+	col := ColumnDef{Name: name, Type: strings.ToUpper(typeName)}
+
+	if ok, err := p.acceptPunct("("); err != nil {
+		return ColumnDef{}, err
+	} else if ok {
+		if p.tok.Type != TokNumber {
+			return ColumnDef{}, p.errorf("expected length, got %s", p.tok)
+		}
+		first, _ := strconv.Atoi(p.tok.Value)
+		if err := p.advance(); err != nil {
+			return ColumnDef{}, err
+		}
+
+		// DECIMAL(precision, scale) / NUMERIC(precision, scale) take a
+		// second arg; every other typed-length type (e.g. BINARY(n)) takes
+		// only the one.
+		haveSecond, second := false, 0
+		if ok, err := p.acceptPunct(","); err != nil {
+			return ColumnDef{}, err
+		} else if ok {
+			if p.tok.Type != TokNumber {
+				return ColumnDef{}, p.errorf("expected scale, got %s", p.tok)
+			}
+			second, _ = strconv.Atoi(p.tok.Value)
+			haveSecond = true
+			if err := p.advance(); err != nil {
+				return ColumnDef{}, err
+			}
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return ColumnDef{}, err
+		}
+
+		if haveSecond {
+			col.Precision, col.Scale = first, second
+		} else {
+			col.Length = first
+		}
+	}
+
+	return col, nil
+}
+
+// parseValueToken parses one scalar literal: a quoted string, a number
+// (including a leading '-'), TRUE/FALSE/NULL, or a zero-arg function call
+// like NOW(). It returns the literal's source text unevaluated; callers
+// that need a typed value convert it against the target column's type.
+func (p *Parser) parseValueToken() (string, error) {
+	switch {
+	case p.tok.Type == TokString:
+		v := p.tok.Value
+		return v, p.advance()
+	case p.tok.Type == TokNumber:
+		v := p.tok.Value
+		return v, p.advance()
+	case p.tok.Type == TokPunct && p.tok.Value == "-":
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		if p.tok.Type != TokNumber {
+			return "", p.errorf("expected number after '-', got %s", p.tok)
+		}
+		v := "-" + p.tok.Value
+		return v, p.advance()
+	case p.tok.isKeyword("TRUE") || p.tok.isKeyword("FALSE") || p.tok.isKeyword("NULL"):
+		v := strings.ToUpper(p.tok.Value)
+		return v, p.advance()
+	case p.tok.Type == TokIdent:
+		name := p.tok.Value
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		if ok, err := p.acceptPunct("("); err != nil {
+			return "", err
+		} else if ok {
+			if err := p.expectPunct(")"); err != nil {
+				return "", err
+			}
+			return name + "()", nil
+		}
+		return name, nil
+	default:
+		return "", p.errorf("expected value, got %s", p.tok)
+	}
+}
+
+func (p *Parser) parseParenValues() ([]string, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var vals []string
+	for {
+		v, err := p.parseValueToken()
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+		if ok, err := p.acceptPunct(","); err != nil {
+			return nil, err
+		} else if ok {
+			continue
+		}
+		break
+	}
+	return vals, p.expectPunct(")")
+}
+
+func (p *Parser) parseCreate() (Stmt, error) {
+	if err := p.expectKeyword("CREATE"); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.atKeyword("DATABASE"):
+		return p.parseCreateDatabase()
+	case p.atKeyword("STABLE"):
+		return p.parseCreateSTable()
+	case p.atKeyword("TABLE"):
+		return p.parseCreateTableOrSubTable()
+	case p.atKeyword("STREAM"):
+		return p.parseCreateStream()
+	case p.atKeyword("TOPIC"):
+		return p.parseCreateTopic()
+	default:
+		return nil, p.errorf("unsupported CREATE statement at %s", p.tok)
+	}
+}
+
+func (p *Parser) parseCreateDatabase() (Stmt, error) {
+	if err := p.expectKeyword("DATABASE"); err != nil {
+		return nil, err
+	}
+	ifNotExists, err := p.parseIfNotExists()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &CreateDatabaseStmt{IfNotExists: ifNotExists, Name: name}
+	for p.tok.Type != TokEOF {
+		if p.atKeyword("PRECISION") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if p.tok.Type != TokString {
+				return nil, p.errorf("expected quoted precision, got %s", p.tok)
+			}
+			stmt.Precision = p.tok.Value
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if p.atKeyword("WITH") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("HINTED"); err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("HANDOFF"); err != nil {
+				return nil, err
+			}
+			stmt.HintedHandoff = true
+			continue
+		}
+		// Any other (not-yet-modeled) database option is skipped token by
+		// token, same leniency the old regex-based parsing had.
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseCreateSTable() (Stmt, error) {
+	if err := p.expectKeyword("STABLE"); err != nil {
+		return nil, err
+	}
+	ifNotExists, err := p.parseIfNotExists()
+	if err != nil {
+		return nil, err
+	}
+	db, name, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	columns, err := p.parseColumnList()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("TAGS"); err != nil {
+		return nil, err
+	}
+	tags, err := p.parseColumnList()
+	if err != nil {
+		return nil, err
+	}
+	return &CreateSTableStmt{
+		IfNotExists: ifNotExists,
+		Database:    db,
+		Name:        name,
+		Columns:     columns,
+		Tags:        tags,
+	}, nil
+}
+
+func (p *Parser) parseCreateTableOrSubTable() (Stmt, error) {
+	if err := p.expectKeyword("TABLE"); err != nil {
+		return nil, err
+	}
+	ifNotExists, err := p.parseIfNotExists()
+	if err != nil {
+		return nil, err
+	}
+	db, name, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.atKeyword("USING") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		sdb, sname, err := p.parseQualifiedName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("TAGS"); err != nil {
+			return nil, err
+		}
+		values, err := p.parseParenValues()
+		if err != nil {
+			return nil, err
+		}
+		return &CreateSubTableStmt{
+			IfNotExists:    ifNotExists,
+			Database:       db,
+			Name:           name,
+			STableDatabase: sdb,
+			STableName:     sname,
+			TagValues:      values,
+		}, nil
+	}
+
+	columns, err := p.parseColumnList()
+	if err != nil {
+		return nil, err
+	}
+	return &CreateTableStmt{IfNotExists: ifNotExists, Database: db, Name: name, Columns: columns}, nil
+}
+
+func (p *Parser) parseCreateStream() (Stmt, error) {
+	if err := p.expectKeyword("STREAM"); err != nil {
+		return nil, err
+	}
+	ifNotExists, err := p.parseIfNotExists()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &CreateStreamStmt{IfNotExists: ifNotExists, Name: name, Trigger: "at_once"}
+	for {
+		switch {
+		case p.atKeyword("TRIGGER"):
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			trigger, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Trigger = trigger
+			continue
+		case p.atKeyword("WATERMARK"):
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			watermark, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Watermark = watermark
+			continue
+		case p.atKeyword("MAX_DELAY"):
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			delay, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.MaxDelay = delay
+			continue
+		}
+		break
+	}
+
+	if err := p.expectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+	target, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.TargetTable = target
+
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	selectStart := p.tok.Pos
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	sel, err := p.parseSelectBody()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Select = sel
+	stmt.SelectText = strings.TrimSpace(p.lex.src[selectStart:])
+	return stmt, nil
+}
+
+func (p *Parser) parseCreateTopic() (Stmt, error) {
+	if err := p.expectKeyword("TOPIC"); err != nil {
+		return nil, err
+	}
+	ifNotExists, err := p.parseIfNotExists()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	withMeta := false
+	if ok, err := p.acceptKeyword("WITH"); err != nil {
+		return nil, err
+	} else if ok {
+		if err := p.expectKeyword("META"); err != nil {
+			return nil, err
+		}
+		withMeta = true
+	}
+
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	selectStart := p.tok.Pos
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	sel, err := p.parseSelectBody()
+	if err != nil {
+		return nil, err
+	}
+	selectText := strings.TrimSpace(p.lex.src[selectStart:])
+
+	return &CreateTopicStmt{
+		IfNotExists: ifNotExists,
+		Name:        name,
+		WithMeta:    withMeta,
+		Select:      sel,
+		SelectText:  selectText,
+	}, nil
+}
+
+func (p *Parser) parseDrop() (Stmt, error) {
+	if err := p.expectKeyword("DROP"); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.atKeyword("DATABASE"):
+		return p.parseDropDatabase()
+	case p.atKeyword("TABLE"):
+		return p.parseDropTable()
+	case p.atKeyword("STREAM"):
+		return p.parseDropStream()
+	case p.atKeyword("TOPIC"):
+		return p.parseDropTopic()
+	default:
+		return nil, p.errorf("unsupported DROP statement at %s", p.tok)
+	}
+}
+
+func (p *Parser) parseDropDatabase() (Stmt, error) {
+	if err := p.expectKeyword("DATABASE"); err != nil {
+		return nil, err
+	}
+	ifExists, err := p.parseIfExists()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	return &DropDatabaseStmt{IfExists: ifExists, Name: name}, nil
+}
+
+func (p *Parser) parseDropTable() (Stmt, error) {
+	if err := p.expectKeyword("TABLE"); err != nil {
+		return nil, err
+	}
+	ifExists, err := p.parseIfExists()
+	if err != nil {
+		return nil, err
+	}
+	db, name, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	return &DropTableStmt{IfExists: ifExists, Database: db, Name: name}, nil
+}
+
+func (p *Parser) parseDropStream() (Stmt, error) {
+	if err := p.expectKeyword("STREAM"); err != nil {
+		return nil, err
+	}
+	ifExists, err := p.parseIfExists()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	return &DropStreamStmt{IfExists: ifExists, Name: name}, nil
+}
+
+func (p *Parser) parseDropTopic() (Stmt, error) {
+	if err := p.expectKeyword("TOPIC"); err != nil {
+		return nil, err
+	}
+	ifExists, err := p.parseIfExists()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	return &DropTopicStmt{IfExists: ifExists, Name: name}, nil
+}
+
+func (p *Parser) parseUse() (Stmt, error) {
+	if err := p.expectKeyword("USE"); err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	return &UseStmt{Name: name}, nil
+}
+
+func (p *Parser) parseShow() (Stmt, error) {
+	if err := p.expectKeyword("SHOW"); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.atKeyword("DATABASES"):
+		return &ShowDatabasesStmt{}, p.advance()
+	case p.atKeyword("TABLES"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		stmt := &ShowTablesStmt{}
+		if ok, err := p.acceptKeyword("FROM"); err != nil {
+			return nil, err
+		} else if ok {
+			db, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Database = db
+		}
+		return stmt, nil
+	case p.atKeyword("STABLES"):
+		return &ShowSTablesStmt{}, p.advance()
+	case p.atKeyword("STREAMS"):
+		return &ShowStreamsStmt{}, p.advance()
+	case p.atKeyword("TOPICS"):
+		return &ShowTopicsStmt{}, p.advance()
+	case p.atKeyword("VGROUPS"):
+		return &ShowVgroupsStmt{}, p.advance()
+	case p.atKeyword("DNODES"):
+		return &ShowDnodesStmt{}, p.advance()
+	case p.atKeyword("MNODES"):
+		return &ShowMnodesStmt{}, p.advance()
+	case p.atKeyword("USERS"):
+		return &ShowUsersStmt{}, p.advance()
+	case p.atKeyword("CONSUMERS"):
+		return &ShowConsumersStmt{}, p.advance()
+	case p.atKeyword("SUBSCRIPTIONS"):
+		return &ShowSubscriptionsStmt{}, p.advance()
+	case p.atKeyword("QUERY"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("CACHE"); err != nil {
+			return nil, err
+		}
+		return &ShowQueryCacheStmt{}, nil
+	case p.atKeyword("HINTED"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("HANDOFF"); err != nil {
+			return nil, err
+		}
+		return &ShowHintedHandoffStmt{}, nil
+	case p.atKeyword("SCHEMA"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("CHANGES"); err != nil {
+			return nil, err
+		}
+		return &ShowSchemaChangesStmt{}, nil
+	case p.atKeyword("CREATE"):
+		return p.parseShowCreate()
+	default:
+		return nil, p.errorf("unsupported SHOW statement at %s", p.tok)
+	}
+}
+
+// parseShowCreate parses "CREATE TABLE [db.]name" or "CREATE STABLE
+// [db.]name" after the leading SHOW has already been consumed.
+func (p *Parser) parseShowCreate() (Stmt, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.atKeyword("STABLE"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		db, name, err := p.parseQualifiedName()
+		if err != nil {
+			return nil, err
+		}
+		return &ShowCreateSTableStmt{Database: db, Name: name}, nil
+	case p.atKeyword("TABLE"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		db, name, err := p.parseQualifiedName()
+		if err != nil {
+			return nil, err
+		}
+		return &ShowCreateTableStmt{Database: db, Name: name}, nil
+	default:
+		return nil, p.errorf("expected TABLE or STABLE after SHOW CREATE, got %s", p.tok)
+	}
+}
+
+func (p *Parser) parseDescribe() (Stmt, error) {
+	if p.atKeyword("DESC") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	} else if err := p.expectKeyword("DESCRIBE"); err != nil {
+		return nil, err
+	}
+	db, name, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	return &DescribeStmt{Database: db, Name: name}, nil
+}
+
+func (p *Parser) parseInsert() (Stmt, error) {
+	if err := p.expectKeyword("INSERT"); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+
+	stmt := &InsertStmt{}
+	for {
+		target, err := p.parseInsertTarget()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Targets = append(stmt.Targets, target)
+		if p.tok.Type != TokIdent {
+			break
+		}
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseInsertTarget() (InsertTarget, error) {
+	db, name, err := p.parseQualifiedName()
+	if err != nil {
+		return InsertTarget{}, err
+	}
+	target := InsertTarget{Database: db, Table: name}
+
+	if p.tok.Type == TokPunct && p.tok.Value == "(" {
+		if err := p.advance(); err != nil {
+			return InsertTarget{}, err
+		}
+		for {
+			col, err := p.parseIdent()
+			if err != nil {
+				return InsertTarget{}, err
+			}
+			target.Columns = append(target.Columns, col)
+			if ok, err := p.acceptPunct(","); err != nil {
+				return InsertTarget{}, err
+			} else if ok {
+				continue
+			}
+			break
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return InsertTarget{}, err
+		}
+	}
+
+	if err := p.expectKeyword("VALUES"); err != nil {
+		return InsertTarget{}, err
+	}
+
+	for p.tok.Type == TokPunct && p.tok.Value == "(" {
+		vals, err := p.parseParenValues()
+		if err != nil {
+			return InsertTarget{}, err
+		}
+		target.Rows = append(target.Rows, InsertRow{Values: vals})
+	}
+	if len(target.Rows) == 0 {
+		return InsertTarget{}, p.errorf("expected at least one VALUES row, got %s", p.tok)
+	}
+
+	return target, nil
+}
+
+func (p *Parser) parseSelect() (Stmt, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	return p.parseSelectBody()
+}
+
+func (p *Parser) parseSelectBody() (*SelectStmt, error) {
+	fields, err := p.parseSelectFields()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	from, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &SelectStmt{Fields: fields, From: from}
+
+	if ok, err := p.acceptKeyword("WHERE"); err != nil {
+		return nil, err
+	} else if ok {
+		conds, err := p.parseWhere()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Where = conds
+	}
+
+	if p.atKeyword("INTERVAL") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		interval, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		window := &WindowClause{Interval: interval}
+
+		if p.atKeyword("SLIDING") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct("("); err != nil {
+				return nil, err
+			}
+			sliding, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(")"); err != nil {
+				return nil, err
+			}
+			window.Sliding = sliding
+		}
+		stmt.Window = window
+	}
+
+	if ok, err := p.acceptKeyword("GROUP"); err != nil {
+		return nil, err
+	} else if ok {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			tag, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.GroupBy = append(stmt.GroupBy, tag)
+			if ok, err := p.acceptPunct(","); err != nil {
+				return nil, err
+			} else if ok {
+				continue
+			}
+			break
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseSelectFields() ([]SelectField, error) {
+	var fields []SelectField
+	for {
+		field, err := p.parseSelectField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+		if ok, err := p.acceptPunct(","); err != nil {
+			return nil, err
+		} else if ok {
+			continue
+		}
+		break
+	}
+	return fields, nil
+}
+
+func (p *Parser) parseSelectField() (SelectField, error) {
+	if ok, err := p.acceptPunct("*"); err != nil {
+		return SelectField{}, err
+	} else if ok {
+		return SelectField{Name: "*"}, nil
+	}
+
+	name, err := p.parseIdent()
+	if err != nil {
+		return SelectField{}, err
+	}
+	field := SelectField{Name: name}
+
+	if ok, err := p.acceptPunct("("); err != nil {
+		return SelectField{}, err
+	} else if ok {
+		field.Func = name
+		if ok, err := p.acceptPunct("*"); err != nil {
+			return SelectField{}, err
+		} else if ok {
+			field.Name = "*"
+		} else {
+			arg, err := p.parseIdent()
+			if err != nil {
+				return SelectField{}, err
+			}
+			field.Name = arg
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return SelectField{}, err
+		}
+	}
+
+	if ok, err := p.acceptKeyword("AS"); err != nil {
+		return SelectField{}, err
+	} else if ok {
+		alias, err := p.parseIdent()
+		if err != nil {
+			return SelectField{}, err
+		}
+		field.Alias = alias
+	}
+	return field, nil
+}
+
+func (p *Parser) parseWhere() ([]WhereCond, error) {
+	var conds []WhereCond
+	for {
+		more, err := p.parseWhereCond()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, more...)
+		if ok, err := p.acceptKeyword("AND"); err != nil {
+			return nil, err
+		} else if ok {
+			continue
+		}
+		break
+	}
+	return conds, nil
+}
+
+func (p *Parser) parseWhereCond() ([]WhereCond, error) {
+	left, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.atKeyword("BETWEEN") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		low, err := p.parseValueToken()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseValueToken()
+		if err != nil {
+			return nil, err
+		}
+		return []WhereCond{
+			{Left: left, Operator: ">=", Right: low},
+			{Left: left, Operator: "<=", Right: high},
+		}, nil
+	}
+
+	op, err := p.parseComparisonOperator()
+	if err != nil {
+		return nil, err
+	}
+	right, err := p.parseValueToken()
+	if err != nil {
+		return nil, err
+	}
+	return []WhereCond{{Left: left, Operator: op, Right: right}}, nil
+}
+
+func (p *Parser) parseComparisonOperator() (string, error) {
+	if p.tok.Type != TokPunct {
+		return "", p.errorf("expected comparison operator, got %s", p.tok)
+	}
+	switch p.tok.Value {
+	case "=":
+		return p.tok.Value, p.advance()
+	case "<", ">":
+		op := p.tok.Value
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		if p.tok.Type == TokPunct && (p.tok.Value == "=" || (op == "<" && p.tok.Value == ">")) {
+			op += p.tok.Value
+			return op, p.advance()
+		}
+		return op, nil
+	default:
+		return "", p.errorf("expected comparison operator, got %s", p.tok)
+	}
+}
+
+func (p *Parser) parseAlter() (Stmt, error) {
+	if err := p.expectKeyword("ALTER"); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("TABLE"); err != nil {
+		return nil, err
+	}
+	db, name, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	return &AlterTableStmt{Database: db, Name: name, Rest: strings.TrimSpace(p.lex.src[p.tok.Pos:])}, nil
+}
+
+func (p *Parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("tdengine/parser: "+format, args...)
+}
@@ -0,0 +1,197 @@
+package parser
+
+import "testing"
+
+func TestParse_CreateDatabase(t *testing.T) {
+	stmt, err := Parse("CREATE DATABASE IF NOT EXISTS mydb PRECISION 'ms'")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, ok := stmt.(*CreateDatabaseStmt)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *CreateDatabaseStmt", stmt)
+	}
+	if !got.IfNotExists || got.Name != "mydb" || got.Precision != "ms" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestParse_CreateSTable(t *testing.T) {
+	stmt, err := Parse("CREATE STABLE meters (ts TIMESTAMP, value DOUBLE) TAGS (location BINARY(32), groupid INT)")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, ok := stmt.(*CreateSTableStmt)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *CreateSTableStmt", stmt)
+	}
+	if got.Name != "meters" || len(got.Columns) != 2 || len(got.Tags) != 2 {
+		t.Fatalf("got %+v", got)
+	}
+	if got.Columns[1].Name != "value" || got.Columns[1].Type != "DOUBLE" {
+		t.Errorf("Columns[1] = %+v", got.Columns[1])
+	}
+	if got.Tags[0].Name != "location" || got.Tags[0].Length != 32 {
+		t.Errorf("Tags[0] = %+v", got.Tags[0])
+	}
+}
+
+func TestParse_CreateSubTable(t *testing.T) {
+	stmt, err := Parse("CREATE TABLE d1001 USING meters TAGS ('california.sf', 2)")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, ok := stmt.(*CreateSubTableStmt)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *CreateSubTableStmt", stmt)
+	}
+	if got.Name != "d1001" || got.STableName != "meters" {
+		t.Fatalf("got %+v", got)
+	}
+	if len(got.TagValues) != 2 || got.TagValues[0] != "california.sf" || got.TagValues[1] != "2" {
+		t.Errorf("TagValues = %v", got.TagValues)
+	}
+}
+
+func TestParse_InsertMultiTable(t *testing.T) {
+	stmt, err := Parse("INSERT INTO d1001 (ts, value) VALUES (1600000000000, 1.2) (1600000001000, 1.3) d1002 VALUES (1600000000000, 9.9)")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, ok := stmt.(*InsertStmt)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *InsertStmt", stmt)
+	}
+	if len(got.Targets) != 2 {
+		t.Fatalf("len(Targets) = %d, want 2", len(got.Targets))
+	}
+	first := got.Targets[0]
+	if first.Table != "d1001" || len(first.Columns) != 2 || len(first.Rows) != 2 {
+		t.Fatalf("Targets[0] = %+v", first)
+	}
+	if first.Rows[1].Values[1] != "1.3" {
+		t.Errorf("Targets[0].Rows[1] = %v", first.Rows[1])
+	}
+	second := got.Targets[1]
+	if second.Table != "d1002" || len(second.Rows) != 1 {
+		t.Fatalf("Targets[1] = %+v", second)
+	}
+}
+
+func TestParse_SelectWithWhereIntervalGroupBy(t *testing.T) {
+	stmt, err := Parse("SELECT AVG(value) AS avg_value FROM meters WHERE ts BETWEEN 1600000000000 AND 1600003600000 INTERVAL(10m) SLIDING(5m) GROUP BY location")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, ok := stmt.(*SelectStmt)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *SelectStmt", stmt)
+	}
+	if len(got.Fields) != 1 || got.Fields[0].Func != "AVG" || got.Fields[0].Alias != "avg_value" {
+		t.Fatalf("Fields = %+v", got.Fields)
+	}
+	if got.From != "meters" {
+		t.Errorf("From = %q", got.From)
+	}
+	if len(got.Where) != 2 || got.Where[0].Operator != ">=" || got.Where[1].Operator != "<=" {
+		t.Fatalf("Where = %+v", got.Where)
+	}
+	if got.Window == nil || got.Window.Interval != "10m" || got.Window.Sliding != "5m" {
+		t.Fatalf("Window = %+v", got.Window)
+	}
+	if len(got.GroupBy) != 1 || got.GroupBy[0] != "location" {
+		t.Errorf("GroupBy = %v", got.GroupBy)
+	}
+}
+
+func TestParse_CreateStream(t *testing.T) {
+	stmt, err := Parse("CREATE STREAM IF NOT EXISTS avg_stream TRIGGER window_close WATERMARK 10s INTO avg_results AS SELECT AVG(value) FROM meters INTERVAL(1m)")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, ok := stmt.(*CreateStreamStmt)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *CreateStreamStmt", stmt)
+	}
+	if !got.IfNotExists || got.Name != "avg_stream" || got.Trigger != "window_close" || got.Watermark != "10s" {
+		t.Fatalf("got %+v", got)
+	}
+	if got.TargetTable != "avg_results" || got.Select == nil || got.Select.From != "meters" {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestParse_QuotedIdentifierAndComments(t *testing.T) {
+	sql := "-- comment\nCREATE /* inline */ DATABASE `my-db`\n"
+	stmt, err := Parse(sql)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, ok := stmt.(*CreateDatabaseStmt)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *CreateDatabaseStmt", stmt)
+	}
+	if got.Name != "my-db" {
+		t.Errorf("Name = %q, want my-db", got.Name)
+	}
+}
+
+func TestLexer_EscapedString(t *testing.T) {
+	lex := NewLexer(`'it''s a test'`)
+	tok, err := lex.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if tok.Type != TokString || tok.Value != "it's a test" {
+		t.Errorf("got %+v, want TokString \"it's a test\"", tok)
+	}
+}
+
+func TestLexer_UnterminatedStringErrors(t *testing.T) {
+	lex := NewLexer(`'unterminated`)
+	if _, err := lex.Next(); err == nil {
+		t.Error("Next on unterminated string literal = nil error, want error")
+	}
+}
+
+func TestParse_DropTableIfExists(t *testing.T) {
+	stmt, err := Parse("DROP TABLE IF EXISTS mydb.d1001")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, ok := stmt.(*DropTableStmt)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *DropTableStmt", stmt)
+	}
+	if !got.IfExists || got.Database != "mydb" || got.Name != "d1001" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestParse_ShowCreateTable(t *testing.T) {
+	stmt, err := Parse("SHOW CREATE TABLE mydb.d1001")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, ok := stmt.(*ShowCreateTableStmt)
+	if !ok {
+		t.Fatalf("Parse returned %T, want *ShowCreateTableStmt", stmt)
+	}
+	if got.Database != "mydb" || got.Name != "d1001" {
+		t.Errorf("got %+v", got)
+	}
+
+	stmt, err = Parse("SHOW CREATE STABLE meters")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, ok := stmt.(*ShowCreateSTableStmt); !ok || got.Name != "meters" {
+		t.Fatalf("got %T %+v, want *ShowCreateSTableStmt{Name: meters}", stmt, stmt)
+	}
+}
+
+func TestParse_UnsupportedStatementErrors(t *testing.T) {
+	if _, err := Parse("EXPLAIN SELECT * FROM t"); err == nil {
+		t.Error("Parse of unsupported statement = nil error, want error")
+	}
+}
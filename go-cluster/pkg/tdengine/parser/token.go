@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenType classifies a lexed token.
+type TokenType int
+
+const (
+	TokEOF TokenType = iota
+	TokIdent
+	TokNumber
+	TokString
+	TokPunct
+)
+
+// Token is a single lexed unit. Value holds the token's text with quoting
+// already stripped (for TokString) or exactly as written (for everything
+// else), so the parser never has to re-inspect raw source.
+type Token struct {
+	Type  TokenType
+	Value string
+	Pos   int
+}
+
+func (t Token) String() string {
+	switch t.Type {
+	case TokEOF:
+		return "EOF"
+	case TokString:
+		return fmt.Sprintf("%q", t.Value)
+	default:
+		return t.Value
+	}
+}
+
+// isKeyword reports whether tok is an identifier equal to word, ignoring
+// case (TDengine keywords are case-insensitive, same as table/column names
+// used unquoted).
+func (t Token) isKeyword(word string) bool {
+	return t.Type == TokIdent && strings.EqualFold(t.Value, word)
+}
@@ -0,0 +1,200 @@
+package tdengine
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// TableVersion names the version of one table's data a cached query
+// result depends on. A cache entry is only valid while every one of its
+// TableVersions still matches the engine's current version for that
+// table.
+type TableVersion struct {
+	DB      string
+	Table   string
+	Version int64
+}
+
+// QueryCache caches SELECT results keyed by an opaque cache key (the
+// engine derives one from (db, sql)), invalidated by table version
+// rather than by a fixed TTL: InvalidateTable bumps a table's version so
+// every entry depending on it misses on its next Get.
+type QueryCache interface {
+	Get(key string) (*Response, bool)
+	Put(key string, r *Response, deps []TableVersion)
+	InvalidateTable(db, table string)
+}
+
+func tableVersionKey(db, table string) string {
+	return db + "." + table
+}
+
+// cacheEntry is one cached response plus the table versions it was
+// computed against.
+type cacheEntry struct {
+	response *Response
+	deps     []TableVersion
+	expires  time.Time // zero means no TTL
+}
+
+// CacheStore is the pluggable backing store an LRUQueryCache reads and
+// writes entries through; the default is an in-memory map, but callers
+// can supply one backed by an external cache.
+type CacheStore interface {
+	Get(key string) (*cacheEntry, bool)
+	Set(key string, entry *cacheEntry)
+	Delete(key string)
+}
+
+// memCacheStore is the default in-memory CacheStore.
+type memCacheStore struct {
+	mu   sync.RWMutex
+	data map[string]*cacheEntry
+}
+
+func newMemCacheStore() *memCacheStore {
+	return &memCacheStore{data: make(map[string]*cacheEntry)}
+}
+
+func (s *memCacheStore) Get(key string) (*cacheEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.data[key]
+	return e, ok
+}
+
+func (s *memCacheStore) Set(key string, entry *cacheEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = entry
+}
+
+func (s *memCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+}
+
+// LRUQueryCache is a QueryCache bounded to maxElements entries (evicting
+// least-recently-used ones) with an optional TTL, backed by a pluggable
+// CacheStore.
+type LRUQueryCache struct {
+	mu          sync.Mutex
+	store       CacheStore
+	ttl         time.Duration
+	maxElements int
+	order       *list.List
+	elements    map[string]*list.Element
+
+	tableVersions map[string]int64 // tableVersionKey(db, table) -> version
+
+	hits, misses, evictions int64
+}
+
+// NewLRUQueryCache builds an LRUQueryCache over store, evicting entries
+// after ttl (0 disables TTL expiry) or once maxElements is exceeded (0
+// disables the size bound). A nil store defaults to an in-memory map.
+func NewLRUQueryCache(store CacheStore, ttl time.Duration, maxElements int) *LRUQueryCache {
+	if store == nil {
+		store = newMemCacheStore()
+	}
+	return &LRUQueryCache{
+		store:         store,
+		ttl:           ttl,
+		maxElements:   maxElements,
+		order:         list.New(),
+		elements:      make(map[string]*list.Element),
+		tableVersions: make(map[string]int64),
+	}
+}
+
+// Get returns the cached response for key, or (nil, false) if it's
+// absent, expired, or stale against a dependency's current table
+// version.
+func (c *LRUQueryCache) Get(key string) (*Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.store.Get(key)
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		c.removeLocked(key)
+		c.misses++
+		return nil, false
+	}
+	for _, dep := range entry.deps {
+		if c.tableVersions[tableVersionKey(dep.DB, dep.Table)] != dep.Version {
+			c.removeLocked(key)
+			c.misses++
+			return nil, false
+		}
+	}
+
+	if el, ok := c.elements[key]; ok {
+		c.order.MoveToFront(el)
+	}
+	c.hits++
+	return entry.response, true
+}
+
+// Put stores r under key, stamping each of deps with the table's current
+// version so a later InvalidateTable can tell this entry went stale.
+func (c *LRUQueryCache) Put(key string, r *Response, deps []TableVersion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resolved := make([]TableVersion, len(deps))
+	for i, d := range deps {
+		resolved[i] = TableVersion{DB: d.DB, Table: d.Table, Version: c.tableVersions[tableVersionKey(d.DB, d.Table)]}
+	}
+
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = time.Now().Add(c.ttl)
+	}
+	c.store.Set(key, &cacheEntry{response: r, deps: resolved, expires: expires})
+
+	if el, ok := c.elements[key]; ok {
+		c.order.MoveToFront(el)
+	} else {
+		c.elements[key] = c.order.PushFront(key)
+	}
+
+	for c.maxElements > 0 && c.order.Len() > c.maxElements {
+		back := c.order.Back()
+		if back == nil {
+			break
+		}
+		c.removeLocked(back.Value.(string))
+		c.evictions++
+	}
+}
+
+// removeLocked drops key from both the backing store and the LRU order;
+// callers must already hold c.mu.
+func (c *LRUQueryCache) removeLocked(key string) {
+	c.store.Delete(key)
+	if el, ok := c.elements[key]; ok {
+		c.order.Remove(el)
+		delete(c.elements, key)
+	}
+}
+
+// InvalidateTable bumps (db, table)'s version, so every cached entry
+// that depends on it misses on its next Get.
+func (c *LRUQueryCache) InvalidateTable(db, table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tableVersions[tableVersionKey(db, table)]++
+}
+
+// Stats reports cumulative hit/miss/eviction counts, for SHOW QUERY CACHE.
+func (c *LRUQueryCache) Stats() (hits, misses, evictions int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses, c.evictions
+}
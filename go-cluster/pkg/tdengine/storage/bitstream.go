@@ -0,0 +1,71 @@
+package storage
+
+// bitWriter accumulates bits MSB-first into a byte slice, the layout the
+// Gorilla timestamp/float encoders below are written against.
+type bitWriter struct {
+	buf  []byte
+	bits uint8 // number of valid bits already written into buf's last byte
+}
+
+func (w *bitWriter) writeBit(bit bool) {
+	if w.bits == 0 {
+		w.buf = append(w.buf, 0)
+	}
+	if bit {
+		w.buf[len(w.buf)-1] |= 1 << (7 - w.bits)
+	}
+	w.bits++
+	if w.bits == 8 {
+		w.bits = 0
+	}
+}
+
+// writeBits writes the low nBits bits of v, most-significant bit first.
+func (w *bitWriter) writeBits(v uint64, nBits int) {
+	for i := nBits - 1; i >= 0; i-- {
+		w.writeBit((v>>uint(i))&1 == 1)
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	return w.buf
+}
+
+// bitReader reads back a stream written by bitWriter.
+type bitReader struct {
+	buf     []byte
+	bytePos int
+	bitPos  uint8 // next bit to read within buf[bytePos], 0 = MSB
+}
+
+func newBitReader(buf []byte) *bitReader {
+	return &bitReader{buf: buf}
+}
+
+func (r *bitReader) readBit() (bool, bool) {
+	if r.bytePos >= len(r.buf) {
+		return false, false
+	}
+	bit := (r.buf[r.bytePos]>>(7-r.bitPos))&1 == 1
+	r.bitPos++
+	if r.bitPos == 8 {
+		r.bitPos = 0
+		r.bytePos++
+	}
+	return bit, true
+}
+
+func (r *bitReader) readBits(nBits int) (uint64, bool) {
+	var v uint64
+	for i := 0; i < nBits; i++ {
+		bit, ok := r.readBit()
+		if !ok {
+			return 0, false
+		}
+		v <<= 1
+		if bit {
+			v |= 1
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,182 @@
+package storage
+
+import "fmt"
+
+// ColumnType is the storage-level type of one non-timestamp column.
+type ColumnType int
+
+const (
+	ColFloat64 ColumnType = iota
+	ColInt64
+	ColBool
+	ColString
+)
+
+// ColumnSchema describes one non-timestamp column of a subtable; Ts itself
+// is implicit and always present.
+type ColumnSchema struct {
+	Name string
+	Type ColumnType
+}
+
+// Row is one decoded data point: Ts is the row's timestamp, Values holds
+// one entry per column in schema order (float64/int64/bool/string).
+type Row struct {
+	Ts     int64
+	Values []interface{}
+}
+
+// Block is an immutable, column-encoded chunk of rows for a single
+// subtable, sorted by Ts ascending: delta-of-delta timestamps, Gorilla XOR
+// floats, dictionary-encoded strings, and plain slices for everything
+// else.
+type Block struct {
+	schema []ColumnSchema
+	minTs  int64
+	maxTs  int64
+	count  int
+
+	tsData []byte
+
+	floatData map[int][]byte
+	intData   map[int][]int64
+	boolData  map[int][]bool
+	strDict   map[int][]string
+	strIDs    map[int][]uint32
+}
+
+// newBlock encodes rows, which must already be sorted by Ts ascending,
+// into a Block.
+func newBlock(schema []ColumnSchema, rows []Row) (*Block, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("storage: cannot build a block from zero rows")
+	}
+
+	ts := make([]int64, len(rows))
+	for i, r := range rows {
+		if len(r.Values) != len(schema) {
+			return nil, fmt.Errorf("storage: row %d has %d values, want %d", i, len(r.Values), len(schema))
+		}
+		ts[i] = r.Ts
+	}
+
+	b := &Block{
+		schema: schema,
+		minTs:  ts[0],
+		maxTs:  ts[len(ts)-1],
+		count:  len(rows),
+		tsData: encodeTimestamps(ts),
+
+		floatData: make(map[int][]byte),
+		intData:   make(map[int][]int64),
+		boolData:  make(map[int][]bool),
+		strDict:   make(map[int][]string),
+		strIDs:    make(map[int][]uint32),
+	}
+
+	for col, cs := range schema {
+		switch cs.Type {
+		case ColFloat64:
+			vals := make([]float64, len(rows))
+			for i, r := range rows {
+				v, ok := r.Values[col].(float64)
+				if !ok {
+					return nil, fmt.Errorf("storage: column %q: want float64, got %T", cs.Name, r.Values[col])
+				}
+				vals[i] = v
+			}
+			b.floatData[col] = encodeFloats(vals)
+		case ColInt64:
+			vals := make([]int64, len(rows))
+			for i, r := range rows {
+				v, ok := r.Values[col].(int64)
+				if !ok {
+					return nil, fmt.Errorf("storage: column %q: want int64, got %T", cs.Name, r.Values[col])
+				}
+				vals[i] = v
+			}
+			b.intData[col] = vals
+		case ColBool:
+			vals := make([]bool, len(rows))
+			for i, r := range rows {
+				v, ok := r.Values[col].(bool)
+				if !ok {
+					return nil, fmt.Errorf("storage: column %q: want bool, got %T", cs.Name, r.Values[col])
+				}
+				vals[i] = v
+			}
+			b.boolData[col] = vals
+		case ColString:
+			vals := make([]string, len(rows))
+			for i, r := range rows {
+				v, ok := r.Values[col].(string)
+				if !ok {
+					return nil, fmt.Errorf("storage: column %q: want string, got %T", cs.Name, r.Values[col])
+				}
+				vals[i] = v
+			}
+			dict, ids := encodeStrings(vals)
+			b.strDict[col] = dict
+			b.strIDs[col] = ids
+		default:
+			return nil, fmt.Errorf("storage: column %q: unsupported column type %d", cs.Name, cs.Type)
+		}
+	}
+
+	return b, nil
+}
+
+// rows decodes the full block back into Row form.
+func (b *Block) rows() []Row {
+	ts := decodeTimestamps(b.tsData, b.count)
+
+	columns := make([][]interface{}, len(b.schema))
+	for col, cs := range b.schema {
+		columns[col] = make([]interface{}, b.count)
+		switch cs.Type {
+		case ColFloat64:
+			for i, v := range decodeFloats(b.floatData[col], b.count) {
+				columns[col][i] = v
+			}
+		case ColInt64:
+			for i, v := range b.intData[col] {
+				columns[col][i] = v
+			}
+		case ColBool:
+			for i, v := range b.boolData[col] {
+				columns[col][i] = v
+			}
+		case ColString:
+			for i, v := range decodeStrings(b.strDict[col], b.strIDs[col]) {
+				columns[col][i] = v
+			}
+		}
+	}
+
+	out := make([]Row, b.count)
+	for i := 0; i < b.count; i++ {
+		values := make([]interface{}, len(b.schema))
+		for col := range b.schema {
+			values[col] = columns[col][i]
+		}
+		out[i] = Row{Ts: ts[i], Values: values}
+	}
+	return out
+}
+
+// queryRange decodes only the rows whose Ts falls within [loTs, hiTs],
+// after a cheap min/max check that lets callers skip the block entirely
+// without touching its encoded bytes.
+func (b *Block) queryRange(loTs, hiTs int64) []Row {
+	if hiTs < b.minTs || loTs > b.maxTs {
+		return nil
+	}
+	all := b.rows()
+	out := all[:0:0]
+	for _, r := range all {
+		if r.Ts >= loTs && r.Ts <= hiTs {
+			out = append(out, r)
+		}
+	}
+	return out
+}
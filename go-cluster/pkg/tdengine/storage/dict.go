@@ -0,0 +1,29 @@
+package storage
+
+// encodeStrings dictionary-encodes a column of string values: each distinct
+// value is assigned an id in first-seen order, so low-cardinality columns
+// (tag values, status strings) cost one int per row plus the dictionary
+// itself instead of repeating the full string.
+func encodeStrings(vals []string) (dict []string, ids []uint32) {
+	index := make(map[string]uint32, len(vals))
+	ids = make([]uint32, len(vals))
+	for i, v := range vals {
+		id, ok := index[v]
+		if !ok {
+			id = uint32(len(dict))
+			index[v] = id
+			dict = append(dict, v)
+		}
+		ids[i] = id
+	}
+	return dict, ids
+}
+
+// decodeStrings reverses encodeStrings.
+func decodeStrings(dict []string, ids []uint32) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = dict[id]
+	}
+	return out
+}
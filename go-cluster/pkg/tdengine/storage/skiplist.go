@@ -0,0 +1,100 @@
+package storage
+
+import "math/rand"
+
+const skiplistMaxLevel = 16
+const skiplistP = 0.25
+
+// skiplistNode is one entry in a tsSkiplist, ordered by row.Ts ascending.
+type skiplistNode struct {
+	row  Row
+	next []*skiplistNode
+}
+
+// tsSkiplist holds recently-inserted, not-yet-flushed rows for a subtable
+// in timestamp order, so SubTableStore can serve queries over the tail of
+// a series before it has accumulated enough points to flush into a Block.
+type tsSkiplist struct {
+	head  *skiplistNode
+	level int
+	count int
+}
+
+func newTSSkiplist() *tsSkiplist {
+	return &tsSkiplist{
+		head:  &skiplistNode{next: make([]*skiplistNode, skiplistMaxLevel)},
+		level: 1,
+	}
+}
+
+func (s *tsSkiplist) randomLevel() int {
+	level := 1
+	for level < skiplistMaxLevel && rand.Float64() < skiplistP {
+		level++
+	}
+	return level
+}
+
+// insert adds row in timestamp order. If a row with the same Ts already
+// exists, row replaces it.
+func (s *tsSkiplist) insert(row Row) {
+	update := make([]*skiplistNode, skiplistMaxLevel)
+	node := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && node.next[i].row.Ts < row.Ts {
+			node = node.next[i]
+		}
+		update[i] = node
+	}
+
+	if next := node.next[0]; next != nil && next.row.Ts == row.Ts {
+		next.row = row
+		return
+	}
+
+	level := s.randomLevel()
+	if level > s.level {
+		for i := s.level; i < level; i++ {
+			update[i] = s.head
+		}
+		s.level = level
+	}
+
+	newNode := &skiplistNode{row: row, next: make([]*skiplistNode, level)}
+	for i := 0; i < level; i++ {
+		newNode.next[i] = update[i].next[i]
+		update[i].next[i] = newNode
+	}
+	s.count++
+}
+
+// rows returns every buffered row in Ts order.
+func (s *tsSkiplist) rows() []Row {
+	out := make([]Row, 0, s.count)
+	for node := s.head.next[0]; node != nil; node = node.next[0] {
+		out = append(out, node.row)
+	}
+	return out
+}
+
+// queryRange returns buffered rows with Ts in [loTs, hiTs], in Ts order.
+func (s *tsSkiplist) queryRange(loTs, hiTs int64) []Row {
+	node := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && node.next[i].row.Ts < loTs {
+			node = node.next[i]
+		}
+	}
+
+	var out []Row
+	for node = node.next[0]; node != nil && node.row.Ts <= hiTs; node = node.next[0] {
+		out = append(out, node.row)
+	}
+	return out
+}
+
+func (s *tsSkiplist) reset() {
+	s.head = &skiplistNode{next: make([]*skiplistNode, skiplistMaxLevel)}
+	s.level = 1
+	s.count = 0
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestTimestampRoundTrip(t *testing.T) {
+	ts := []int64{1000, 1010, 1020, 1030, 1030, 1031, 1200, 5000, 4999, 0, -100}
+	enc := encodeTimestamps(ts)
+	got := decodeTimestamps(enc, len(ts))
+	if !reflect.DeepEqual(got, ts) {
+		t.Fatalf("round trip mismatch: got %v, want %v", got, ts)
+	}
+}
+
+func TestTimestampRoundTrip_RandomWalk(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	ts := make([]int64, 500)
+	cur := int64(1700000000000)
+	for i := range ts {
+		cur += int64(r.Intn(4000)) - 2000
+		ts[i] = cur
+	}
+	enc := encodeTimestamps(ts)
+	got := decodeTimestamps(enc, len(ts))
+	if !reflect.DeepEqual(got, ts) {
+		t.Fatalf("round trip mismatch on random walk")
+	}
+}
+
+func TestFloatRoundTrip(t *testing.T) {
+	vals := []float64{1.5, 1.5, 1.5, 2.25, 2.25, -3.75, 0, 0, 100.125, -100.125}
+	enc := encodeFloats(vals)
+	got := decodeFloats(enc, len(vals))
+	if !reflect.DeepEqual(got, vals) {
+		t.Fatalf("round trip mismatch: got %v, want %v", got, vals)
+	}
+}
+
+func TestStringDictRoundTrip(t *testing.T) {
+	vals := []string{"ok", "ok", "error", "ok", "timeout", "error", ""}
+	dict, ids := encodeStrings(vals)
+	got := decodeStrings(dict, ids)
+	if !reflect.DeepEqual(got, vals) {
+		t.Fatalf("round trip mismatch: got %v, want %v", got, vals)
+	}
+	if len(dict) != 4 {
+		t.Fatalf("expected 4 distinct values in dictionary, got %d", len(dict))
+	}
+}
+
+func testSchema() []ColumnSchema {
+	return []ColumnSchema{
+		{Name: "value", Type: ColFloat64},
+		{Name: "count", Type: ColInt64},
+		{Name: "ok", Type: ColBool},
+		{Name: "status", Type: ColString},
+	}
+}
+
+func testRows(n int, startTs int64) []Row {
+	rows := make([]Row, n)
+	for i := 0; i < n; i++ {
+		rows[i] = Row{
+			Ts: startTs + int64(i)*1000,
+			Values: []interface{}{
+				float64(i) * 1.5,
+				int64(i),
+				i%2 == 0,
+				"ok",
+			},
+		}
+	}
+	return rows
+}
+
+func TestBlockRoundTrip(t *testing.T) {
+	schema := testSchema()
+	rows := testRows(10, 1000)
+
+	block, err := newBlock(schema, rows)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+	got := block.rows()
+	if !reflect.DeepEqual(got, rows) {
+		t.Fatalf("round trip mismatch: got %+v, want %+v", got, rows)
+	}
+}
+
+func TestBlockQueryRangePushdown(t *testing.T) {
+	schema := testSchema()
+	rows := testRows(10, 1000)
+	block, err := newBlock(schema, rows)
+	if err != nil {
+		t.Fatalf("newBlock: %v", err)
+	}
+
+	if got := block.queryRange(0, 500); got != nil {
+		t.Fatalf("expected nil for out-of-range query, got %v", got)
+	}
+
+	got := block.queryRange(3000, 6000)
+	if !reflect.DeepEqual(got, rows[2:6]) {
+		t.Fatalf("queryRange mismatch: got %+v, want %+v", got, rows[2:6])
+	}
+}
+
+func TestSubTableStoreFlushAndQuery(t *testing.T) {
+	schema := testSchema()
+	store := NewSubTableStore(schema)
+	store.threshold = 5
+
+	rows := testRows(12, 1000)
+	for _, r := range rows {
+		if err := store.Insert(r); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+
+	if len(store.blocks) != 2 {
+		t.Fatalf("expected 2 flushed blocks, got %d", len(store.blocks))
+	}
+
+	got := store.Query(1000, 12000)
+	if !reflect.DeepEqual(got, rows) {
+		t.Fatalf("query across flushed+unflushed mismatch: got %+v, want %+v", got, rows)
+	}
+
+	got = store.Query(4000, 7000)
+	if !reflect.DeepEqual(got, rows[3:7]) {
+		t.Fatalf("narrow query mismatch: got %+v, want %+v", got, rows[3:7])
+	}
+}
+
+func TestStoreMultiTableIsolation(t *testing.T) {
+	store := NewStore()
+	schema := testSchema()
+	store.CreateTable("db1", "t1", schema)
+	store.CreateTable("db1", "t2", schema)
+
+	rowsT1 := testRows(3, 1000)
+	rowsT2 := testRows(3, 5000)
+	for _, r := range rowsT1 {
+		if err := store.Insert("db1", "t1", r); err != nil {
+			t.Fatalf("Insert t1: %v", err)
+		}
+	}
+	for _, r := range rowsT2 {
+		if err := store.Insert("db1", "t2", r); err != nil {
+			t.Fatalf("Insert t2: %v", err)
+		}
+	}
+
+	got, err := store.Query("db1", "t1", 0, 10000)
+	if err != nil {
+		t.Fatalf("Query t1: %v", err)
+	}
+	if !reflect.DeepEqual(got, rowsT1) {
+		t.Fatalf("t1 query mismatch: got %+v, want %+v", got, rowsT1)
+	}
+
+	got, err = store.Query("db1", "t2", 0, 10000)
+	if err != nil {
+		t.Fatalf("Query t2: %v", err)
+	}
+	if !reflect.DeepEqual(got, rowsT2) {
+		t.Fatalf("t2 query mismatch: got %+v, want %+v", got, rowsT2)
+	}
+
+	if _, err := store.Query("db1", "missing", 0, 10000); err == nil {
+		t.Fatal("expected error querying unknown table")
+	}
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Store is the top-level handle to every subtable's column storage,
+// keyed by "<database>.<table>".
+type Store struct {
+	mu     sync.RWMutex
+	tables map[string]*SubTableStore
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{tables: make(map[string]*SubTableStore)}
+}
+
+func tableKey(db, table string) string {
+	return db + "." + table
+}
+
+// CreateTable registers the column schema for a subtable. It is a no-op
+// if the table is already registered.
+func (s *Store) CreateTable(db, table string, schema []ColumnSchema) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := tableKey(db, table)
+	if _, ok := s.tables[key]; ok {
+		return
+	}
+	s.tables[key] = NewSubTableStore(schema)
+}
+
+// Insert appends row to the named subtable, which must already exist via
+// CreateTable.
+func (s *Store) Insert(db, table string, row Row) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tables[tableKey(db, table)]
+	if !ok {
+		return fmt.Errorf("storage: table %s.%s not found", db, table)
+	}
+	return t.Insert(row)
+}
+
+// Query returns rows from the named subtable with Ts in [loTs, hiTs].
+func (s *Store) Query(db, table string, loTs, hiTs int64) ([]Row, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.tables[tableKey(db, table)]
+	if !ok {
+		return nil, fmt.Errorf("storage: table %s.%s not found", db, table)
+	}
+	return t.Query(loTs, hiTs), nil
+}
+
+// DropTable removes a subtable and all of its stored data.
+func (s *Store) DropTable(db, table string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tables, tableKey(db, table))
+}
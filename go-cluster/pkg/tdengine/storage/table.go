@@ -0,0 +1,73 @@
+package storage
+
+import "sort"
+
+// defaultFlushThreshold is the number of buffered points a SubTableStore
+// accumulates before compacting them into a new Block.
+const defaultFlushThreshold = 1024
+
+// SubTableStore holds all rows for one subtable: older data lives in a
+// time-ordered sequence of immutable Blocks, while the most recent,
+// not-yet-flushed points sit in a skiplist so they can still be queried.
+type SubTableStore struct {
+	schema []ColumnSchema
+
+	blocks    []*Block
+	buffer    *tsSkiplist
+	threshold int
+}
+
+// NewSubTableStore creates a store for a subtable with the given
+// non-timestamp column schema.
+func NewSubTableStore(schema []ColumnSchema) *SubTableStore {
+	return &SubTableStore{
+		schema:    schema,
+		buffer:    newTSSkiplist(),
+		threshold: defaultFlushThreshold,
+	}
+}
+
+// Insert buffers row, flushing the buffer into a new Block once it
+// reaches the flush threshold.
+func (s *SubTableStore) Insert(row Row) error {
+	s.buffer.insert(row)
+	if s.buffer.count >= s.threshold {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush compacts every buffered row into a new Block and empties the
+// buffer.
+func (s *SubTableStore) flush() error {
+	if s.buffer.count == 0 {
+		return nil
+	}
+	block, err := newBlock(s.schema, s.buffer.rows())
+	if err != nil {
+		return err
+	}
+	s.blocks = append(s.blocks, block)
+	s.buffer.reset()
+	return nil
+}
+
+// Query returns every row with Ts in [loTs, hiTs], merging flushed blocks
+// (skipped via min/max pushdown when they fall outside the range) with
+// the unflushed buffer, sorted by Ts ascending.
+func (s *SubTableStore) Query(loTs, hiTs int64) []Row {
+	var out []Row
+	for _, b := range s.blocks {
+		out = append(out, b.queryRange(loTs, hiTs)...)
+	}
+	out = append(out, s.buffer.queryRange(loTs, hiTs)...)
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Ts < out[j].Ts })
+	return out
+}
+
+// Flush forces any buffered points into a Block, e.g. before a clean
+// shutdown.
+func (s *SubTableStore) Flush() error {
+	return s.flush()
+}
@@ -0,0 +1,115 @@
+package storage
+
+// encodeTimestamps compresses a strictly-increasing slice of millisecond
+// timestamps using the delta-of-delta scheme from the Gorilla paper: the
+// first timestamp is stored raw, the second as a plain delta, and every
+// timestamp after that as a delta-of-delta whose bit width is chosen from
+// the value's magnitude (most real-world series have a constant sampling
+// interval, so the common case costs a single bit per point).
+func encodeTimestamps(ts []int64) []byte {
+	w := &bitWriter{}
+	if len(ts) == 0 {
+		return w.bytes()
+	}
+
+	w.writeBits(uint64(ts[0]), 64)
+	if len(ts) == 1 {
+		return w.bytes()
+	}
+
+	prevDelta := ts[1] - ts[0]
+	w.writeBits(zigzag(prevDelta), 64)
+
+	prev := ts[1]
+	for _, t := range ts[2:] {
+		delta := t - prev
+		dod := delta - prevDelta
+		writeDoD(w, dod)
+		prev = t
+		prevDelta = delta
+	}
+	return w.bytes()
+}
+
+func writeDoD(w *bitWriter, dod int64) {
+	switch {
+	case dod == 0:
+		w.writeBit(false)
+	case dod >= -64 && dod <= 63:
+		w.writeBits(0b10, 2)
+		w.writeBits(zigzagN(dod, 7), 7)
+	case dod >= -256 && dod <= 255:
+		w.writeBits(0b110, 3)
+		w.writeBits(zigzagN(dod, 9), 9)
+	case dod >= -2048 && dod <= 2047:
+		w.writeBits(0b1110, 4)
+		w.writeBits(zigzagN(dod, 12), 12)
+	default:
+		w.writeBits(0b1111, 4)
+		w.writeBits(zigzag(dod), 64)
+	}
+}
+
+// decodeTimestamps reverses encodeTimestamps, returning exactly n values.
+func decodeTimestamps(buf []byte, n int) []int64 {
+	if n == 0 {
+		return nil
+	}
+	r := newBitReader(buf)
+	out := make([]int64, 0, n)
+
+	first, _ := r.readBits(64)
+	out = append(out, int64(first))
+	if n == 1 {
+		return out
+	}
+
+	deltaBits, _ := r.readBits(64)
+	delta := unzigzag(deltaBits)
+	out = append(out, out[0]+delta)
+
+	for len(out) < n {
+		dod := readDoD(r)
+		delta += dod
+		out = append(out, out[len(out)-1]+delta)
+	}
+	return out
+}
+
+func readDoD(r *bitReader) int64 {
+	bit, _ := r.readBit()
+	if !bit {
+		return 0
+	}
+	bit, _ = r.readBit()
+	if !bit {
+		v, _ := r.readBits(7)
+		return unzigzag(v)
+	}
+	bit, _ = r.readBit()
+	if !bit {
+		v, _ := r.readBits(9)
+		return unzigzag(v)
+	}
+	bit, _ = r.readBit()
+	if !bit {
+		v, _ := r.readBits(12)
+		return unzigzag(v)
+	}
+	v, _ := r.readBits(64)
+	return unzigzag(v)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -(int64(v) & 1)
+}
+
+// zigzagN zigzag-encodes v into an nBits-wide unsigned field; callers only
+// use this for deltas already known to fit within nBits.
+func zigzagN(v int64, nBits int) uint64 {
+	return zigzag(v) & ((1 << uint(nBits)) - 1)
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"math"
+	"math/bits"
+)
+
+// encodeFloats compresses a slice of float64 samples using the Gorilla XOR
+// scheme: each value is XORed against the previous one; an all-zero XOR
+// (the common case for slow-changing metrics) costs a single bit, and a
+// changed value reuses the previous block's leading/trailing zero-run
+// lengths when they still cover the new XOR, so most points only pay for
+// their "meaningful" middle bits.
+func encodeFloats(vals []float64) []byte {
+	w := &bitWriter{}
+	if len(vals) == 0 {
+		return w.bytes()
+	}
+
+	prev := math.Float64bits(vals[0])
+	w.writeBits(prev, 64)
+
+	prevLeading, prevTrailing := -1, -1
+	for _, f := range vals[1:] {
+		cur := math.Float64bits(f)
+		xor := prev ^ cur
+		if xor == 0 {
+			w.writeBit(false)
+		} else {
+			w.writeBit(true)
+			leading := bits.LeadingZeros64(xor)
+			trailing := bits.TrailingZeros64(xor)
+
+			if prevLeading >= 0 && leading >= prevLeading && trailing >= prevTrailing {
+				w.writeBit(false)
+				meaningful := 64 - prevLeading - prevTrailing
+				w.writeBits(xor>>uint(prevTrailing), meaningful)
+			} else {
+				w.writeBit(true)
+				w.writeBits(uint64(leading), 6)
+				meaningful := 64 - leading - trailing
+				w.writeBits(uint64(meaningful), 6)
+				w.writeBits(xor>>uint(trailing), meaningful)
+				prevLeading, prevTrailing = leading, trailing
+			}
+		}
+		prev = cur
+	}
+	return w.bytes()
+}
+
+// decodeFloats reverses encodeFloats, returning exactly n values.
+func decodeFloats(buf []byte, n int) []float64 {
+	if n == 0 {
+		return nil
+	}
+	r := newBitReader(buf)
+	out := make([]float64, 0, n)
+
+	bits, _ := r.readBits(64)
+	prev := bits
+	out = append(out, math.Float64frombits(prev))
+	if n == 1 {
+		return out
+	}
+
+	prevLeading, prevTrailing := 0, 0
+	for len(out) < n {
+		zeroXOR, ok := r.readBit()
+		if !ok {
+			break
+		}
+		if !zeroXOR {
+			out = append(out, math.Float64frombits(prev))
+			continue
+		}
+
+		controlBit, _ := r.readBit()
+		var leading, trailing, meaningful int
+		if !controlBit {
+			leading, trailing = prevLeading, prevTrailing
+			meaningful = 64 - leading - trailing
+		} else {
+			l, _ := r.readBits(6)
+			m, _ := r.readBits(6)
+			leading = int(l)
+			meaningful = int(m)
+			trailing = 64 - leading - meaningful
+			prevLeading, prevTrailing = leading, trailing
+		}
+
+		bits, _ := r.readBits(meaningful)
+		xor := bits << uint(trailing)
+		cur := prev ^ xor
+		out = append(out, math.Float64frombits(cur))
+		prev = cur
+	}
+	return out
+}
@@ -0,0 +1,241 @@
+package tdengine
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tdb-plus/cluster/pkg/tdengine/parser"
+	"github.com/tdb-plus/cluster/pkg/tdengine/storage"
+)
+
+// streamTrigger is the parsed form of a CREATE STREAM's TRIGGER option:
+// "AT_ONCE", "WINDOW_CLOSE", or "MAX_DELAY <duration>".
+type streamTrigger struct {
+	policy   string
+	maxDelay int64 // milliseconds, only meaningful for MAX_DELAY
+}
+
+func parseStreamTrigger(s string) streamTrigger {
+	fields := strings.Fields(strings.ToUpper(s))
+	if len(fields) == 0 {
+		return streamTrigger{policy: "AT_ONCE"}
+	}
+	switch fields[0] {
+	case "MAX_DELAY":
+		var delay int64
+		if len(fields) > 1 {
+			if ms, err := parseIntervalMs(strings.ToLower(fields[1])); err == nil {
+				delay = ms
+			}
+		}
+		return streamTrigger{policy: "MAX_DELAY", maxDelay: delay}
+	case "WINDOW_CLOSE":
+		return streamTrigger{policy: "WINDOW_CLOSE"}
+	default:
+		return streamTrigger{policy: "AT_ONCE"}
+	}
+}
+
+// windowState accumulates one source subtable's aggregate over one time
+// window, so late-arriving rows (inside the watermark) can update an
+// already-fired window instead of starting a new one.
+type windowState struct {
+	start, end int64
+	count      int64
+	sum        float64
+	min, max   float64
+	last       float64
+	lastTs     int64
+	twaArea    float64 // time-weighted sum of value * duration since the previous point
+	hasPrev    bool
+	prevTs     int64
+	prevVal    float64
+	maxSeenTs  int64
+	closed     bool
+	opensAt    time.Time
+}
+
+func newWindowState(start, end int64) *windowState {
+	return &windowState{start: start, end: end, min: math.Inf(1), max: math.Inf(-1), opensAt: time.Now()}
+}
+
+func (w *windowState) add(ts int64, v float64) {
+	if w.hasPrev {
+		w.twaArea += w.prevVal * float64(ts-w.prevTs)
+	}
+	w.prevTs, w.prevVal, w.hasPrev = ts, v, true
+
+	w.count++
+	w.sum += v
+	if v < w.min {
+		w.min = v
+	}
+	if v > w.max {
+		w.max = v
+	}
+	if ts >= w.lastTs {
+		w.last, w.lastTs = v, ts
+	}
+	if ts > w.maxSeenTs {
+		w.maxSeenTs = ts
+	}
+}
+
+func (w *windowState) value(fn string) float64 {
+	switch strings.ToUpper(fn) {
+	case "SUM":
+		return w.sum
+	case "AVG":
+		return w.sum / float64(w.count)
+	case "MIN":
+		return w.min
+	case "MAX":
+		return w.max
+	case "COUNT":
+		return float64(w.count)
+	case "TWA":
+		span := w.lastTs - w.start
+		if span <= 0 {
+			return w.last
+		}
+		return w.twaArea / float64(span)
+	default:
+		return w.last
+	}
+}
+
+// streamRuntime is the evaluable form of a StreamDefinition: everything
+// createStream extracts once from its parsed SELECT so every insert
+// doesn't have to re-parse the stored SQL text.
+type streamRuntime struct {
+	name        string
+	sourceTable string
+	targetTable string
+	field       parser.SelectField // the single aggregated projection, e.g. AVG(value)
+	intervalMs  int64
+	watermarkMs int64
+	trigger     streamTrigger
+
+	windows       map[string]*windowState // keyed by "<subtable>|<window start>"
+	subtableMaxTs map[string]int64
+	droppedLate   int64
+}
+
+func newStreamRuntime(stmt *parser.CreateStreamStmt) *streamRuntime {
+	rt := &streamRuntime{
+		name:          stmt.Name,
+		targetTable:   stmt.TargetTable,
+		trigger:       parseStreamTrigger(stmt.Trigger),
+		windows:       make(map[string]*windowState),
+		subtableMaxTs: make(map[string]int64),
+	}
+	if stmt.Watermark != "" {
+		if ms, err := parseIntervalMs(stmt.Watermark); err == nil {
+			rt.watermarkMs = ms
+		}
+	}
+	if stmt.Select != nil {
+		rt.sourceTable = stmt.Select.From
+		if stmt.Select.Window != nil {
+			if ms, err := parseIntervalMs(stmt.Select.Window.Interval); err == nil {
+				rt.intervalMs = ms
+			}
+		}
+		for _, f := range stmt.Select.Fields {
+			if f.Func != "" {
+				rt.field = f
+				break
+			}
+		}
+	}
+	return rt
+}
+
+func (rt *streamRuntime) windowBounds(ts int64) (start, end int64) {
+	if rt.intervalMs <= 0 {
+		return ts, ts + 1
+	}
+	start = (ts / rt.intervalMs) * rt.intervalMs
+	return start, start + rt.intervalMs
+}
+
+// onAppend evaluates newly-inserted rows against the stream's window
+// state and returns the rows that should be appended to the target table
+// per the stream's trigger policy. colIndex maps a non-ts column name to
+// its position in each row's Values slice.
+func (rt *streamRuntime) onAppend(subtable string, rows []storage.Row, colIndex map[string]int) []storage.Row {
+	idx, hasField := -1, false
+	if rt.field.Name != "" {
+		if i, ok := colIndex[rt.field.Name]; ok {
+			idx, hasField = i, true
+		}
+	}
+
+	var out []storage.Row
+	for _, r := range rows {
+		start, end := rt.windowBounds(r.Ts)
+		key := fmt.Sprintf("%s|%d", subtable, start)
+
+		w, ok := rt.windows[key]
+		if !ok {
+			watermarkFloor := rt.subtableMaxTs[subtable] - rt.watermarkMs
+			if r.Ts < watermarkFloor {
+				rt.droppedLate++
+				continue
+			}
+			w = newWindowState(start, end)
+			rt.windows[key] = w
+		} else if w.closed {
+			rt.droppedLate++
+			continue
+		}
+
+		if r.Ts > rt.subtableMaxTs[subtable] {
+			rt.subtableMaxTs[subtable] = r.Ts
+		}
+
+		var v float64
+		if hasField && idx < len(r.Values) {
+			v, _ = toFloat64(r.Values[idx])
+		}
+		w.add(r.Ts, v)
+
+		fire := false
+		switch rt.trigger.policy {
+		case "WINDOW_CLOSE":
+			if w.maxSeenTs-rt.watermarkMs >= w.end {
+				fire, w.closed = true, true
+			}
+		case "MAX_DELAY":
+			if rt.trigger.maxDelay > 0 && time.Since(w.opensAt).Milliseconds() >= rt.trigger.maxDelay {
+				fire = true
+			}
+		default: // AT_ONCE
+			fire = true
+		}
+
+		if fire {
+			out = append(out, storage.Row{Ts: w.start, Values: []interface{}{w.value(rt.field.Func)}})
+		}
+	}
+	return out
+}
+
+// fireStreams evaluates every stream whose FROM clause names table
+// against the rows just appended to it, and writes any produced output
+// rows into each stream's target table (which must already exist; a
+// stream into a not-yet-created target is silently skipped, matching how
+// TDengine requires the destination STable to pre-exist).
+func (e *Engine) fireStreams(db, table string, rows []storage.Row, colIndex map[string]int) {
+	for _, rt := range e.streamRuntimes {
+		if rt.sourceTable != table {
+			continue
+		}
+		for _, out := range rt.onAppend(table, rows, colIndex) {
+			_ = e.store.Insert(db, rt.targetTable, out)
+			e.bumpTableVersionLocked(db, rt.targetTable)
+		}
+	}
+}